@@ -0,0 +1,107 @@
+package notion
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// UserContent is the account of the user the client is authenticated as.
+type UserContent struct {
+	User *notiontypes.User
+	// Spaces are the workspaces the user belongs to, by name.
+	Spaces []*notiontypes.Space
+}
+
+type accountRecordMap struct {
+	notiontypes.RecordMap
+	UserRoot map[string]json.RawMessage `json:"user_root"`
+}
+
+type loadUserContentResponse struct {
+	RecordMap accountRecordMap `json:"recordMap"`
+}
+
+// LoadUserContent returns the account of the user the client is
+// authenticated as, and their workspaces.
+func (c *Client) LoadUserContent() (*UserContent, error) {
+	b, err := c.post(struct{}{}, "loadUserContent")
+	if err != nil {
+		return nil, err
+	}
+	r := &loadUserContentResponse{}
+	if err := json.Unmarshal(b, r); err != nil {
+		return nil, errors.Wrap(err, "unmarshaling loadUserContentResponse")
+	}
+	rm := r.RecordMap
+	uc := &UserContent{Spaces: spaces(rm.RecordMap)}
+	for id, u := range rm.Users {
+		if u.Value == nil {
+			continue
+		}
+		// other users, e.g. of shared pages, may be included
+		if _, ok := rm.UserRoot[id]; ok || len(rm.Users) == 1 {
+			uc.User = u.Value
+		}
+	}
+	if uc.User == nil {
+		return nil, errors.New("notion: user content without user")
+	}
+	return uc, nil
+}
+
+// GetSpaces returns the workspaces of all users the client is logged in
+// as, by name.
+func (c *Client) GetSpaces() ([]*notiontypes.Space, error) {
+	b, err := c.post(struct{}{}, "getSpaces")
+	if err != nil {
+		return nil, err
+	}
+	var r map[string]notiontypes.RecordMap
+	if err := json.Unmarshal(b, &r); err != nil {
+		return nil, errors.Wrap(err, "unmarshaling getSpacesResponse")
+	}
+	all := notiontypes.RecordMap{Space: make(map[string]*notiontypes.SpaceWithRole)}
+	for _, rm := range r {
+		for id, s := range rm.Space {
+			all.Space[id] = s
+		}
+	}
+	return spaces(all), nil
+}
+
+// spaces returns the spaces of rm by name, then id.
+func spaces(rm notiontypes.RecordMap) []*notiontypes.Space {
+	var res []*notiontypes.Space
+	for _, s := range rm.Space {
+		if s.Value != nil {
+			res = append(res, s.Value)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Name != res[j].Name {
+			return res[i].Name < res[j].Name
+		}
+		return res[i].ID < res[j].ID
+	})
+	return res
+}
+
+type getSubscriptionDataRequest struct {
+	SpaceID string `json:"spaceId"`
+}
+
+// GetSubscriptionData returns the plan and members of the workspace spaceID.
+func (c *Client) GetSubscriptionData(spaceID string) (*notiontypes.Subscription, error) {
+	b, err := c.post(getSubscriptionDataRequest{SpaceID: spaceID}, "getSubscriptionData")
+	if err != nil {
+		return nil, err
+	}
+	s := &notiontypes.Subscription{}
+	if err := json.Unmarshal(b, s); err != nil {
+		return nil, errors.Wrap(err, "unmarshaling getSubscriptionDataResponse")
+	}
+	return s, nil
+}
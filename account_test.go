@@ -0,0 +1,66 @@
+package notion_test
+
+import (
+	"testing"
+
+	"github.com/tmc/notion/notiontest"
+	"github.com/tmc/notion/notiontypes"
+)
+
+func TestAccount(t *testing.T) {
+	const (
+		userID  = "4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+		guestID = "5b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+		spaceID = "6b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+		otherID = "7b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+	)
+	s := notiontest.NewServer()
+	defer s.Close()
+	s.AddRecord("notion_user", userID, map[string]interface{}{"id": userID, "email": "ada@example.com"})
+	s.AddRecord(notiontypes.TableSpace, spaceID, map[string]interface{}{
+		"id": spaceID, "name": "Work", "plan_type": "team", "disable_export": true,
+		"permissions": []map[string]interface{}{{"type": "user_permission", "role": "editor", "user_id": userID}},
+	})
+	s.AddRecord(notiontypes.TableSpace, otherID, map[string]interface{}{"id": otherID, "name": "Home"})
+	s.AddRecord("subscription", spaceID, map[string]interface{}{
+		"type":       "team",
+		"blockUsage": 1234,
+		"members": []map[string]interface{}{
+			{"userId": userID, "role": "editor"},
+			{"userId": guestID, "role": "read_and_write", "guestPageIds": []string{"8b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"}},
+		},
+	})
+	s.SetUser(userID)
+	c := s.Client()
+
+	uc, err := c.LoadUserContent()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uc.User.Email != "ada@example.com" || len(uc.Spaces) != 2 || uc.Spaces[0].Name != "Home" {
+		t.Errorf("unexpected user content %+v", uc)
+	}
+	work := uc.Spaces[1]
+	if !work.DisableExport || work.PlanType != "team" || work.Members()[userID] != "editor" {
+		t.Errorf("unexpected space %+v", work)
+	}
+
+	spaces, err := c.GetSpaces()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(spaces) != 2 || spaces[1].ID != spaceID {
+		t.Errorf("got spaces %v", spaces)
+	}
+
+	sub, err := c.GetSubscriptionData(spaceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sub.Type != "team" || sub.BlockUsage != 1234 || len(sub.Members) != 2 || sub.Members[0].IsGuest() || !sub.Members[1].IsGuest() {
+		t.Errorf("unexpected subscription %+v", sub)
+	}
+	if _, err := c.GetSubscriptionData(otherID); err == nil {
+		t.Error("expected error for space without subscription data")
+	}
+}
@@ -0,0 +1,60 @@
+package notion
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// activityLogPageSize is the number of activities requested at a time.
+const activityLogPageSize = 100
+
+type getActivityLogRequest struct {
+	SpaceID          string `json:"spaceId"`
+	NavigableBlockID string `json:"navigableBlockId,omitempty"`
+	Limit            int    `json:"limit"`
+	StartingAfterID  string `json:"startingAfterId,omitempty"`
+}
+
+type getActivityLogResponse struct {
+	ActivityIDs []string              `json:"activityIds"`
+	RecordMap   notiontypes.RecordMap `json:"recordMap"`
+}
+
+// GetActivityLog returns the activities in the workspace spaceID that
+// ended after since, most recent first. If blockID is not empty, only
+// activities on that page and its sub-pages are returned.
+func (c *Client) GetActivityLog(spaceID, blockID string, since time.Time) ([]*notiontypes.Activity, error) {
+	req := getActivityLogRequest{
+		SpaceID:          spaceID,
+		NavigableBlockID: blockID,
+		Limit:            activityLogPageSize,
+	}
+	var activities []*notiontypes.Activity
+	for {
+		b, err := c.post(req, "getActivityLog")
+		if err != nil {
+			return nil, err
+		}
+		r := &getActivityLogResponse{}
+		if err := json.Unmarshal(b, r); err != nil {
+			return nil, errors.Wrap(err, "unmarshaling getActivityLogResponse")
+		}
+		for _, id := range r.ActivityIDs {
+			a, ok := r.RecordMap.Activities[id]
+			if !ok || a.Value == nil {
+				continue
+			}
+			if a.Value.EndedOn().Before(since) {
+				return activities, nil
+			}
+			activities = append(activities, a.Value)
+		}
+		if len(r.ActivityIDs) < activityLogPageSize {
+			return activities, nil
+		}
+		req.StartingAfterID = r.ActivityIDs[len(r.ActivityIDs)-1]
+	}
+}
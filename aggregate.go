@@ -0,0 +1,269 @@
+package notion
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion/notiontypes"
+)
+
+type getCollectionViewValuesResponse struct {
+	Results []*notiontypes.CollectionViewWithRole `json:"results"`
+}
+
+// GetCollectionView returns the collection view with the given id.
+func (c *Client) GetCollectionView(viewID string) (*notiontypes.CollectionView, error) {
+	b, err := c.post(getRecordValuesRequest{
+		Requests: []Record{{ID: viewID, Table: notiontypes.TableCollectionView}},
+	}, "getRecordValues")
+	if err != nil {
+		return nil, err
+	}
+	r := &getCollectionViewValuesResponse{}
+	if err := json.Unmarshal(b, r); err != nil {
+		return nil, errors.Wrap(err, "unmarshaling getRecordValuesResponse")
+	}
+	if len(r.Results) == 0 || r.Results[0].Value == nil {
+		return nil, errors.Errorf("notion: collection view %v not found", viewID)
+	}
+	return r.Results[0].Value, nil
+}
+
+// ViewResult is the rows of a collection as shown by a view, with the
+// view's groups and aggregations computed as notion does.
+type ViewResult struct {
+	View       *notiontypes.CollectionView
+	Collection *notiontypes.Collection
+	Rows       []*notiontypes.Block
+	// Groups are the rows by value of the property grouping the view,
+	// or the columns of a board; nil if the view isn't grouped.
+	Groups []*RowGroup
+	// Aggregations summarize all rows.
+	Aggregations []*Aggregation
+}
+
+// RowGroup is a group of rows sharing the value of a property. Rows with
+// several options of a multi-select property are in several groups.
+type RowGroup struct {
+	// Value is the option, or the empty string for rows without a value.
+	Value        string
+	Rows         []*notiontypes.Block
+	Aggregations []*Aggregation
+}
+
+// Aggregation is the result of a QueryAggregation. Percentages go from 0
+// to 100.
+type Aggregation struct {
+	Property   string
+	Aggregator string
+	Value      float64
+}
+
+// QueryView returns the rows of the collection collectionID in the order
+// of the view viewID, grouped and aggregated as in the view.
+func (c *Client) QueryView(collectionID, viewID string) (*ViewResult, error) {
+	view, err := c.GetCollectionView(viewID)
+	if err != nil {
+		return nil, err
+	}
+	collection, err := c.GetCollection(collectionID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := c.QueryCollection(collectionID, viewID)
+	if err != nil {
+		return nil, err
+	}
+	res := &ViewResult{View: view, Collection: collection, Rows: rows}
+	aggs := viewAggregations(view)
+	if res.Aggregations, err = aggregateAll(collection, rows, aggs); err != nil {
+		return nil, err
+	}
+	if by := viewGroupBy(view); by != nil {
+		res.Groups = groupRows(collection, rows, by.Property)
+		for _, g := range res.Groups {
+			if g.Aggregations, err = aggregateAll(collection, g.Rows, aggs); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return res, nil
+}
+
+// viewAggregations returns the aggregations of view, from its query2 or
+// the older query.
+func viewAggregations(view *notiontypes.CollectionView) []*notiontypes.QueryAggregation {
+	if view.Query2 != nil && len(view.Query2.Aggregations) > 0 {
+		return view.Query2.Aggregations
+	}
+	var res []*notiontypes.QueryAggregation
+	if view.Query != nil {
+		for _, a := range view.Query.Aggregate {
+			res = append(res, &notiontypes.QueryAggregation{Property: a.Property, Aggregator: a.AggregationType})
+		}
+	}
+	return res
+}
+
+func viewGroupBy(view *notiontypes.CollectionView) *notiontypes.ViewGroupBy {
+	if view.Format == nil {
+		return nil
+	}
+	if view.Type == notiontypes.ViewBoard && view.Format.BoardColumnsBy != nil {
+		return view.Format.BoardColumnsBy
+	}
+	return view.Format.CollectionGroupBy
+}
+
+// groupRows groups rows by the value of the property id: the options of
+// select properties in the order of the schema first, then the rows
+// without a value, then other values in alphabetical order.
+func groupRows(collection *notiontypes.Collection, rows []*notiontypes.Block, id string) []*RowGroup {
+	groups := map[string]*RowGroup{}
+	var order []string
+	if info, ok := collection.CollectionSchema[id]; ok {
+		for _, o := range info.Options {
+			if groups[o.Value] == nil {
+				groups[o.Value] = &RowGroup{Value: o.Value}
+				order = append(order, o.Value)
+			}
+		}
+	}
+	var other []string
+	for _, row := range rows {
+		values := []string{""}
+		if p := collection.Property(row, id); p != nil && len(p.Values()) > 0 {
+			values = p.Values()
+		}
+		for _, v := range values {
+			g := groups[v]
+			if g == nil {
+				g = &RowGroup{Value: v}
+				groups[v] = g
+				if v != "" {
+					other = append(other, v)
+				}
+			}
+			g.Rows = append(g.Rows, row)
+		}
+	}
+	if groups[""] != nil {
+		order = append(order, "")
+	}
+	sort.Strings(other)
+	res := make([]*RowGroup, 0, len(groups))
+	for _, v := range append(order, other...) {
+		res = append(res, groups[v])
+	}
+	return res
+}
+
+func aggregateAll(collection *notiontypes.Collection, rows []*notiontypes.Block, aggs []*notiontypes.QueryAggregation) ([]*Aggregation, error) {
+	var res []*Aggregation
+	for _, a := range aggs {
+		v, err := aggregate(collection, rows, a)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, &Aggregation{Property: a.Property, Aggregator: a.Aggregator, Value: v})
+	}
+	return res, nil
+}
+
+// aggregate computes a over rows. Aggregations of numbers ignore values
+// that aren't numbers, and are 0 when there are none.
+func aggregate(collection *notiontypes.Collection, rows []*notiontypes.Block, a *notiontypes.QueryAggregation) (float64, error) {
+	if a.Aggregator == "count" {
+		return float64(len(rows)), nil
+	}
+	var values [][]string
+	var numbers []float64
+	empty, checked := 0, 0
+	for _, row := range rows {
+		p := collection.Property(row, a.Property)
+		if p == nil {
+			return 0, errors.Errorf("notion: collection %v has no property %q", collection.ID, a.Property)
+		}
+		v := p.Values()
+		values = append(values, v)
+		if len(v) == 0 {
+			empty++
+		}
+		if p.Checked() {
+			checked++
+		}
+		if n, err := strconv.ParseFloat(p.Text(), 64); err == nil {
+			numbers = append(numbers, n)
+		}
+	}
+	percent := func(n int) float64 {
+		if len(rows) == 0 {
+			return 0
+		}
+		return 100 * float64(n) / float64(len(rows))
+	}
+	sort.Float64s(numbers)
+	switch a.Aggregator {
+	case "count_values":
+		n := 0
+		for _, v := range values {
+			n += len(v)
+		}
+		return float64(n), nil
+	case "unique":
+		seen := map[string]bool{}
+		for _, v := range values {
+			for _, s := range v {
+				seen[s] = true
+			}
+		}
+		return float64(len(seen)), nil
+	case "empty":
+		return float64(empty), nil
+	case "not_empty":
+		return float64(len(rows) - empty), nil
+	case "percent_empty":
+		return percent(empty), nil
+	case "percent_not_empty":
+		return percent(len(rows) - empty), nil
+	case "checked":
+		return float64(checked), nil
+	case "unchecked":
+		return float64(len(rows) - checked), nil
+	case "percent_checked":
+		return percent(checked), nil
+	case "percent_unchecked":
+		return percent(len(rows) - checked), nil
+	case "sum", "average":
+		sum := 0.0
+		for _, n := range numbers {
+			sum += n
+		}
+		if a.Aggregator == "average" && len(numbers) > 0 {
+			sum /= float64(len(numbers))
+		}
+		return sum, nil
+	}
+	if len(numbers) == 0 {
+		switch a.Aggregator {
+		case "median", "min", "max", "range":
+			return 0, nil
+		}
+	}
+	switch a.Aggregator {
+	case "median":
+		if n := len(numbers); n%2 == 0 {
+			return (numbers[n/2-1] + numbers[n/2]) / 2, nil
+		}
+		return numbers[len(numbers)/2], nil
+	case "min":
+		return numbers[0], nil
+	case "max":
+		return numbers[len(numbers)-1], nil
+	case "range":
+		return numbers[len(numbers)-1] - numbers[0], nil
+	}
+	return 0, errors.Errorf("notion: unsupported aggregator %q", a.Aggregator)
+}
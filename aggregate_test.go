@@ -0,0 +1,90 @@
+package notion_test
+
+import (
+	"testing"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/notiontest"
+	"github.com/tmc/notion/notiontypes"
+)
+
+func TestQueryView(t *testing.T) {
+	s := notiontest.NewServer()
+	defer s.Close()
+	s.AddRecord(notiontypes.TableCollection, "db", map[string]interface{}{
+		"schema": map[string]interface{}{
+			"title": map[string]interface{}{"name": "Name", "type": "title"},
+			"st": map[string]interface{}{"name": "Status", "type": "select", "options": []map[string]string{
+				{"id": "1", "value": "Todo"}, {"id": "2", "value": "Doing"}, {"id": "3", "value": "Done"},
+			}},
+			"pt": map[string]interface{}{"name": "Points", "type": "number"},
+			"ok": map[string]interface{}{"name": "Reviewed", "type": "checkbox"},
+		},
+	})
+	s.AddRecord(notiontypes.TableCollectionView, "board", map[string]interface{}{
+		"type": "board",
+		"format": map[string]interface{}{
+			"board_columns_by": map[string]string{"type": "select", "property": "st"},
+		},
+		"query2": map[string]interface{}{
+			"aggregations": []map[string]string{
+				{"property": "title", "aggregator": "count"},
+				{"property": "pt", "aggregator": "sum"},
+				{"property": "ok", "aggregator": "percent_checked"},
+			},
+		},
+	})
+	for i, row := range []map[string]interface{}{
+		{"title": [][]string{{"a"}}, "st": [][]string{{"Done"}}, "pt": [][]string{{"3"}}, "ok": [][]string{{"Yes"}}},
+		{"title": [][]string{{"b"}}, "st": [][]string{{"Done"}}, "pt": [][]string{{"2.5"}}},
+		{"title": [][]string{{"c"}}, "st": [][]string{{"Todo"}}, "ok": [][]string{{"Yes"}}},
+		{"title": [][]string{{"d"}}, "pt": [][]string{{"1"}}},
+	} {
+		s.AddBlock(&notiontypes.Block{
+			ID:          string(rune('a' + i)),
+			Type:        notiontypes.BlockPage,
+			ParentID:    "db",
+			ParentTable: notiontypes.TableCollection,
+			CreatedTime: int64(i),
+			Properties:  row,
+		})
+	}
+	c := s.Client()
+
+	res, err := c.QueryView("db", "board")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Rows) != 4 {
+		t.Fatalf("got %d rows, want 4", len(res.Rows))
+	}
+	if got := values(res.Aggregations); got != [3]float64{4, 6.5, 50} {
+		t.Errorf("got aggregations %v", got)
+	}
+	want := []struct {
+		value string
+		aggs  [3]float64
+	}{
+		{"Todo", [3]float64{1, 0, 100}},
+		{"Doing", [3]float64{0, 0, 0}},
+		{"Done", [3]float64{2, 5.5, 50}},
+		{"", [3]float64{1, 1, 0}},
+	}
+	if len(res.Groups) != len(want) {
+		t.Fatalf("got %d groups, want %d", len(res.Groups), len(want))
+	}
+	for i, w := range want {
+		g := res.Groups[i]
+		if g.Value != w.value || values(g.Aggregations) != w.aggs {
+			t.Errorf("group %d: got %q with %v, want %q with %v", i, g.Value, values(g.Aggregations), w.value, w.aggs)
+		}
+	}
+}
+
+func values(aggs []*notion.Aggregation) [3]float64 {
+	var res [3]float64
+	for i, a := range aggs {
+		res[i] = a.Value
+	}
+	return res
+}
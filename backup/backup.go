@@ -0,0 +1,149 @@
+// Package backup stores snapshots of notion pages on disk and loads them back.
+package backup
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion"
+)
+
+// Option configures a Store.
+type Option func(*Store)
+
+// WithEncryptionKey encrypts the pages written by the store with AES-GCM
+// using key, which must be 16, 24 or 32 bytes long. See ParseKey.
+func WithEncryptionKey(key []byte) Option {
+	return func(s *Store) {
+		s.key = key
+	}
+}
+
+// Store keeps the pages of a backup in a directory, one file per page.
+type Store struct {
+	dir       string
+	key       []byte
+	format    Format
+	gzip      bool
+	gzipLevel int
+}
+
+// NewStore returns a store of the pages in dir.
+func NewStore(dir string, opts ...Option) (*Store, error) {
+	s := &Store{dir: dir}
+	for _, o := range opts {
+		o(s)
+	}
+	if s.key != nil {
+		if _, err := newAEAD(s.key); err != nil {
+			return nil, errors.Wrap(err, "invalid encryption key")
+		}
+	}
+	if s.gzip {
+		if _, err := gzip.NewWriterLevel(nil, s.gzipLevel); err != nil {
+			return nil, errors.Wrap(err, "invalid compression level")
+		}
+	}
+	return s, nil
+}
+
+// Put stores page, replacing a previously stored version.
+func (s *Store) Put(page *notion.Page) error {
+	data, err := encodePage(page, s.format)
+	if err != nil {
+		return errors.Wrapf(err, "encoding page %v", page.ID)
+	}
+	if s.gzip {
+		if data, err = compress(data, s.gzipLevel); err != nil {
+			return errors.Wrapf(err, "compressing page %v", page.ID)
+		}
+	}
+	if s.key != nil {
+		if data, err = seal(s.key, page.ID, data); err != nil {
+			return errors.Wrapf(err, "encrypting page %v", page.ID)
+		}
+	}
+	return writeFile(s.path(page.ID), addChecksum(data))
+}
+
+// Get returns the stored page id. Encrypted pages can only be read by a
+// store with the key they were written with.
+func (s *Store) Get(id string) (*notion.Page, error) {
+	data, err := ioutil.ReadFile(s.path(id))
+	if err != nil {
+		return nil, err
+	}
+	if data, err = verifyChecksum(data); err != nil {
+		return nil, errors.Wrapf(ErrCorrupt, "page %v: %v", id, err)
+	}
+	if isSealed(data) {
+		if s.key == nil {
+			return nil, errors.Errorf("backup: page %v is encrypted, but no key was given", id)
+		}
+		if data, err = open(s.key, id, data); err != nil {
+			return nil, errors.Wrapf(err, "decrypting page %v", id)
+		}
+	}
+	if data, err = decompress(data); err != nil {
+		return nil, errors.Wrapf(ErrCorrupt, "page %v: decompressing: %v", id, err)
+	}
+	page, err := decodePage(data)
+	if err != nil {
+		return nil, errors.Wrapf(ErrCorrupt, "page %v: decoding: %v", id, err)
+	}
+	return page, nil
+}
+
+// IDs returns the ids of the stored pages, sorted.
+func (s *Store) IDs() ([]string, error) {
+	files, err := ioutil.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, f := range files {
+		if strings.HasSuffix(f.Name(), pageExt) {
+			ids = append(ids, strings.TrimSuffix(f.Name(), pageExt))
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+const pageExt = ".page"
+
+func (s *Store) path(id string) string {
+	return filepath.Join(s.dir, id+pageExt)
+}
+
+// Backup stores the page rootID and its sub-pages selected by filter in s.
+// The manifest of s is replaced once all pages are stored, so an interrupted
+// backup leaves the manifest of the previous one in place. Pages and blocks
+// that can't be read are skipped and listed in the manifest.
+func Backup(c *notion.Client, rootID string, filter *notion.Filter, s *Store) error {
+	m := &Manifest{RootID: rootID, Time: time.Now(), Pages: make(map[string]string)}
+	r, err := c.CrawlWithOptions(rootID, notion.CrawlOptions{Filter: filter}, func(p *notion.Page, ancestors []string) error {
+		if err := s.Put(p); err != nil {
+			return err
+		}
+		sum, err := s.fileSum(p.ID)
+		m.Pages[p.ID] = sum
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	for _, b := range r.Skipped {
+		m.Skipped = append(m.Skipped, b.ID)
+	}
+	return s.writeManifest(m)
+}
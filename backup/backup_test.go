@@ -0,0 +1,175 @@
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/corpus"
+	"github.com/tmc/notion/notiontest"
+	"github.com/tmc/notion/notiontypes"
+)
+
+const pageID = "4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+
+func TestEncryptedStore(t *testing.T) {
+	srv := notiontest.NewServer()
+	defer srv.Close()
+	srv.AddBlock(&notiontypes.Block{
+		ID:         pageID,
+		Type:       notiontypes.BlockPage,
+		Properties: map[string]interface{}{"title": [][]string{{"Secret plans"}}},
+	})
+	key, err := ParseKey("000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	s, err := NewStore(dir, WithEncryptionKey(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Backup(srv.Client(), pageID, nil, s); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(s.path(pageID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("Secret plans")) {
+		t.Error("stored page is not encrypted")
+	}
+	page, err := s.Get(pageID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Title != "Secret plans" {
+		t.Errorf("got title %q", page.Title)
+	}
+
+	plain, _ := NewStore(dir)
+	if _, err := plain.Get(pageID); err == nil {
+		t.Error("expected error reading encrypted page without key")
+	}
+	key[0] ^= 1
+	other, _ := NewStore(dir, WithEncryptionKey(key))
+	if _, err := other.Get(pageID); err == nil {
+		t.Error("expected error reading encrypted page with wrong key")
+	}
+}
+
+func TestFormats(t *testing.T) {
+	cases, err := corpus.Cases()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range cases {
+		b, err := c.Page()
+		if err != nil {
+			t.Fatal(err)
+		}
+		page := &notion.Page{Block: b}
+		want, _ := json.Marshal(page)
+		for _, f := range []Format{JSON, Gob} {
+			data, err := encodePage(page, f)
+			if err != nil {
+				t.Fatalf("%v: %v", c.Name, err)
+			}
+			decoded, err := decodePage(data)
+			if err != nil {
+				t.Fatalf("%v: %v", c.Name, err)
+			}
+			if got, _ := json.Marshal(decoded); !bytes.Equal(got, want) {
+				t.Errorf("%v: format %d doesn't round trip", c.Name, f)
+			}
+		}
+	}
+}
+
+func TestCompressedStore(t *testing.T) {
+	page := &notion.Page{Block: &notiontypes.Block{
+		ID:         pageID,
+		Type:       notiontypes.BlockPage,
+		Properties: map[string]interface{}{"title": []interface{}{[]interface{}{strings.Repeat("compressible ", 100)}}},
+	}}
+	uncompressed, err := encodePage(page, JSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	s, err := NewStore(dir, WithGzip(gzip.BestCompression))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Put(page); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(s.path(pageID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) > len(uncompressed)/4 {
+		t.Errorf("compressed page is %d bytes, uncompressed %d", len(data), len(uncompressed))
+	}
+	// reading doesn't depend on the options of the store
+	plain, _ := NewStore(dir)
+	got, err := plain.Get(pageID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Properties, page.Properties) {
+		t.Errorf("got properties %v", got.Properties)
+	}
+}
+
+func TestLoadQuarantinesCorruptPages(t *testing.T) {
+	srv := notiontest.NewServer()
+	defer srv.Close()
+	srv.AddBlock(&notiontypes.Block{
+		ID:         pageID,
+		Type:       notiontypes.BlockPage,
+		Properties: map[string]interface{}{"title": [][]string{{"Plans"}}},
+	})
+	dir := t.TempDir()
+	s, err := NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Backup(srv.Client(), pageID, nil, s); err != nil {
+		t.Fatal(err)
+	}
+	m, err := s.Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m == nil || m.RootID != pageID || m.Pages[pageID] == "" {
+		t.Fatalf("got manifest %+v", m)
+	}
+
+	const corruptID = "9c1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+	data, err := ioutil.ReadFile(s.path(pageID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(s.path(corruptID), data[:len(data)-1], 0600); err != nil {
+		t.Fatal(err)
+	}
+	pages, corrupt, err := s.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != 1 || pages[0].Title != "Plans" {
+		t.Errorf("got pages %v", pages)
+	}
+	if !reflect.DeepEqual(corrupt, []string{corruptID}) {
+		t.Errorf("got corrupt pages %v", corrupt)
+	}
+	if ids, _ := s.IDs(); !reflect.DeepEqual(ids, []string{pageID}) {
+		t.Errorf("corrupt page was not quarantined, store has %v", ids)
+	}
+}
@@ -0,0 +1,74 @@
+package backup
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// Format is the encoding of the pages in a Store.
+type Format int
+
+const (
+	// JSON encodes pages as JSON, as returned by the notion API.
+	JSON Format = iota
+	// Gob encodes pages with encoding/gob, which takes about half the space
+	// of JSON and decodes faster. Unlike JSON, which sorts the keys of
+	// maps, it doesn't write pages byte for byte the same every time.
+	Gob
+)
+
+// WithFormat sets the format pages are written in. Pages are read in
+// whichever format they were written in.
+func WithFormat(f Format) Option {
+	return func(s *Store) {
+		s.format = f
+	}
+}
+
+// gobMagic starts pages encoded with Gob, followed by gobVersion. The
+// version changes whenever notiontypes.Block changes in a way gob can't
+// decode older pages with.
+var gobMagic = []byte("NOTIONGOB")
+
+const gobVersion = 1
+
+func init() {
+	// the dynamic types of Block.Properties values
+	gob.Register([]interface{}{})
+	gob.Register(map[string]interface{}{})
+	gob.Register([][]string{})
+	gob.Register([]string{})
+	gob.Register(&notiontypes.Date{})
+}
+
+func encodePage(page *notion.Page, f Format) ([]byte, error) {
+	switch f {
+	case JSON:
+		return json.Marshal(page)
+	case Gob:
+		buf := bytes.NewBuffer(append(gobMagic, gobVersion))
+		if err := gob.NewEncoder(buf).Encode(page); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	return nil, fmt.Errorf("backup: unknown format %d", f)
+}
+
+// decodePage decodes a page in any format.
+func decodePage(data []byte) (*notion.Page, error) {
+	page := &notion.Page{}
+	if !bytes.HasPrefix(data, gobMagic) {
+		return page, json.Unmarshal(data, page)
+	}
+	data = data[len(gobMagic):]
+	if len(data) == 0 || data[0] != gobVersion {
+		return nil, fmt.Errorf("backup: unsupported gob encoding version")
+	}
+	return page, gob.NewDecoder(bytes.NewReader(data[1:])).Decode(page)
+}
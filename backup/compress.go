@@ -0,0 +1,47 @@
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+)
+
+// WithGzip compresses the pages written by the store with gzip at the given
+// level, e.g. gzip.BestCompression. Pages are read whether or not they are
+// compressed.
+func WithGzip(level int) Option {
+	return func(s *Store) {
+		s.gzip = true
+		s.gzipLevel = level
+	}
+}
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+func compress(data []byte, level int) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := gzip.NewWriterLevel(&buf, level)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decompress returns data uncompressed, or unchanged if it isn't compressed.
+func decompress(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, gzipMagic) {
+		return data, nil
+	}
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
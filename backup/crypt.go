@@ -0,0 +1,74 @@
+package backup
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// sealedMagic starts every encrypted payload, so that encrypted and plain
+// payloads can be told apart when reading.
+var sealedMagic = []byte("NOTIONAES1")
+
+// ParseKey decodes a hex or base64 encoded AES key, e.g. from an environment
+// variable. Keys can be generated with openssl rand -hex 32.
+func ParseKey(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	key, err := hex.DecodeString(s)
+	if err != nil {
+		if key, err = base64.StdEncoding.DecodeString(s); err != nil {
+			return nil, errors.New("backup: key is neither hex nor base64 encoded")
+		}
+	}
+	switch len(key) {
+	case 16, 24, 32:
+		return key, nil
+	}
+	return nil, errors.New("backup: key must be 16, 24 or 32 bytes long")
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts data with key. The id of the record is authenticated along
+// with data, so that encrypted files can't be swapped undetected.
+func seal(key []byte, id string, data []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out := append(append([]byte{}, sealedMagic...), nonce...)
+	return aead.Seal(out, nonce, data, []byte(id)), nil
+}
+
+func isSealed(data []byte) bool {
+	return bytes.HasPrefix(data, sealedMagic)
+}
+
+// open decrypts data sealed for id with key.
+func open(key []byte, id string, data []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	data = data[len(sealedMagic):]
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("backup: encrypted payload is truncated")
+	}
+	nonce, data := data[:aead.NonceSize()], data[aead.NonceSize():]
+	return aead.Open(nil, nonce, data, []byte(id))
+}
@@ -0,0 +1,156 @@
+package backup
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion"
+)
+
+// ErrCorrupt is the cause of errors reading pages whose files are damaged,
+// e.g. truncated by a crash.
+var ErrCorrupt = errors.New("backup: corrupt page")
+
+// checksumMagic starts every page file, followed by the SHA-256 sum of the
+// rest of the file.
+var checksumMagic = []byte("NOTIONSUM1")
+
+func addChecksum(data []byte) []byte {
+	sum := sha256.Sum256(data)
+	out := make([]byte, 0, len(checksumMagic)+len(sum)+len(data))
+	out = append(append(append(out, checksumMagic...), sum[:]...), data...)
+	return out
+}
+
+// verifyChecksum returns data without its checksum. Files written before
+// checksums were added are returned unchanged.
+func verifyChecksum(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, checksumMagic) {
+		return data, nil
+	}
+	data = data[len(checksumMagic):]
+	if len(data) < sha256.Size {
+		return nil, errors.New("checksum is truncated")
+	}
+	sum, data := data[:sha256.Size], data[sha256.Size:]
+	if got := sha256.Sum256(data); !bytes.Equal(got[:], sum) {
+		return nil, errors.New("checksum mismatch")
+	}
+	return data, nil
+}
+
+// writeFile writes data to dst through a temporary file, so that interrupted
+// writes never leave a partial dst behind.
+func writeFile(dst string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(filepath.Dir(dst), ".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), dst)
+}
+
+// Manifest describes a completed backup.
+type Manifest struct {
+	RootID string    `json:"root_id"`
+	Time   time.Time `json:"time"`
+	// Pages maps the ids of the backed up pages to the SHA-256 sums of their
+	// files.
+	Pages map[string]string `json:"pages"`
+	// Skipped lists the ids of the pages and blocks that couldn't be read,
+	// and weren't backed up.
+	Skipped []string `json:"skipped,omitempty"`
+}
+
+const manifestFile = "manifest.json"
+
+// Manifest returns the manifest of the last completed backup in s, or nil if
+// there is none.
+func (s *Store) Manifest() (*Manifest, error) {
+	data, err := ioutil.ReadFile(filepath.Join(s.dir, manifestFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	m := &Manifest{}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, errors.Wrap(err, "unmarshaling manifest")
+	}
+	return m, nil
+}
+
+func (s *Store) writeManifest(m *Manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(filepath.Join(s.dir, manifestFile), data)
+}
+
+// fileSum returns the SHA-256 sum of the file of page id.
+func (s *Store) fileSum(id string) (string, error) {
+	data, err := ioutil.ReadFile(s.path(id))
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+const quarantineDir = "quarantine"
+
+// Load returns all stored pages. Corrupt pages are moved to the quarantine
+// subdirectory of the store and their ids returned instead of failing the
+// whole load.
+func (s *Store) Load() (pages []*notion.Page, corrupt []string, err error) {
+	ids, err := s.IDs()
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, id := range ids {
+		page, err := s.Get(id)
+		if errors.Cause(err) == ErrCorrupt {
+			if err := s.quarantine(id); err != nil {
+				return nil, nil, errors.Wrapf(err, "quarantining page %v", id)
+			}
+			corrupt = append(corrupt, id)
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		pages = append(pages, page)
+	}
+	return pages, corrupt, nil
+}
+
+func (s *Store) quarantine(id string) error {
+	dir := filepath.Join(s.dir, quarantineDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return os.Rename(s.path(id), filepath.Join(dir, filepath.Base(s.path(id))))
+}
@@ -0,0 +1,186 @@
+package notion
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion/notiontypes"
+)
+
+var _ notiontypes.BlockGetter = (*Client)(nil)
+
+// getRecordValuesBatchSize is the maximum number of records requested at a time.
+const getRecordValuesBatchSize = 100
+
+// BlockOptions controls how blocks are fetched and resolved.
+type BlockOptions struct {
+	// Content resolves the content of the blocks, with a loadPageChunk
+	// request for every block that has content.
+	Content bool
+	// MaxDepth, if positive, resolves only the first MaxDepth levels of
+	// content, e.g. 1 for the top-level blocks of pages. The levels are
+	// fetched one at a time with getRecordValues instead of loading whole
+	// pages. The content of the blocks on the last level is not resolved,
+	// but can be loaded with Block.Children. Content is implied.
+	MaxDepth int
+	// Partial keeps the ids of content missing from the loaded pages, e.g.
+	// because it can't be read, in the MissingIDs of their parents instead
+	// of dropping them, see notiontypes.ResolveOptions. They can be fetched
+	// with Block.ResolveMissing.
+	Partial bool
+}
+
+// GetBlockWithOptions returns the block with the given id, resolved according to opts.
+func (c *Client) GetBlockWithOptions(id string, opts BlockOptions) (*notiontypes.Block, error) {
+	blocks, err := c.GetBlocksWithOptions([]string{id}, opts)
+	if err != nil {
+		return nil, err
+	}
+	if blocks[0] == nil {
+		return nil, errors.Errorf("notion: block %v not found", id)
+	}
+	return blocks[0], nil
+}
+
+// GetBlocks returns the blocks with the given ids, in the same order, using
+// as few requests as possible. Blocks that don't exist or can't be read are
+// nil. The properties and format of the blocks are parsed, but their content
+// is not resolved: ContentIDs is set, Content is nil.
+func (c *Client) GetBlocks(ids ...string) ([]*notiontypes.Block, error) {
+	return c.GetBlocksWithOptions(ids, BlockOptions{})
+}
+
+// GetBlocksWithOptions is like GetBlocks, but resolves the blocks according to opts.
+func (c *Client) GetBlocksWithOptions(ids []string, opts BlockOptions) ([]*notiontypes.Block, error) {
+	fetched := make(map[string]*notiontypes.Block, len(ids))
+	var records []Record
+	for _, id := range ids {
+		if _, ok := fetched[id]; ok {
+			continue
+		}
+		fetched[id] = nil
+		records = append(records, Record{ID: id, Table: notiontypes.TableBlock})
+	}
+	for len(records) > 0 {
+		n := len(records)
+		if n > getRecordValuesBatchSize {
+			n = getRecordValuesBatchSize
+		}
+		results, err := c.GetRecordValues(records[:n]...)
+		if err != nil {
+			return nil, err
+		}
+		for i, r := range results {
+			if i < n && r != nil && r.Value != nil {
+				fetched[records[i].ID] = r.Value
+			}
+		}
+		records = records[n:]
+	}
+	synced := make(map[string]*notiontypes.Block)
+	for id, b := range fetched {
+		if b == nil {
+			continue
+		}
+		if opts.Content && opts.MaxDepth <= 0 && len(b.ContentIDs) > 0 {
+			full, err := c.getBlock(id, synced, notiontypes.ResolveOptions{Partial: opts.Partial})
+			if err != nil {
+				return nil, errors.Wrapf(err, "fetching content of block %v", id)
+			}
+			fetched[id] = full
+			continue
+		}
+		if err := notiontypes.ParseBlock(b); err != nil {
+			return nil, errors.Wrapf(err, "parsing block %v", id)
+		}
+	}
+	blocks := make([]*notiontypes.Block, len(ids))
+	var roots []*notiontypes.Block
+	for i, id := range ids {
+		blocks[i] = fetched[id]
+		if blocks[i] != nil {
+			roots = append(roots, blocks[i])
+		}
+	}
+	if opts.MaxDepth > 0 {
+		if err := c.resolveLevels(roots, opts.MaxDepth); err != nil {
+			return nil, err
+		}
+	}
+	return blocks, nil
+}
+
+// resolveLevels resolves depth levels of the content of roots, fetching the
+// blocks of each level with as few requests as possible. Sub-pages within
+// the content are not descended into.
+func (c *Client) resolveLevels(roots []*notiontypes.Block, depth int) error {
+	ctx := c.context()
+	level := roots
+	for ; depth > 0 && len(level) > 0; depth-- {
+		var ids []string
+		for _, b := range level {
+			ids = append(ids, b.ContentIDs...)
+		}
+		g := &prefetchGetter{c: c, blocks: make(map[string]*notiontypes.Block)}
+		if err := g.prefetch(ctx, ids); err != nil {
+			return err
+		}
+		var next []*notiontypes.Block
+		for _, b := range level {
+			children, err := b.Children(ctx, g)
+			if err != nil {
+				return errors.Wrapf(err, "resolving content of block %v", b.ID)
+			}
+			for _, child := range children {
+				if !child.IsPage() {
+					next = append(next, child)
+				}
+			}
+		}
+		level = next
+	}
+	return nil
+}
+
+// prefetchGetter is a notiontypes.BlockGetter that returns prefetched
+// blocks and fetches the others.
+type prefetchGetter struct {
+	c      *Client
+	blocks map[string]*notiontypes.Block
+}
+
+func (g *prefetchGetter) prefetch(ctx context.Context, ids []string) error {
+	blocks, err := g.c.GetBlocksContext(ctx, ids...)
+	if err != nil {
+		return err
+	}
+	for i, b := range blocks {
+		g.blocks[ids[i]] = b
+	}
+	return nil
+}
+
+func (g *prefetchGetter) GetBlocksContext(ctx context.Context, ids ...string) ([]*notiontypes.Block, error) {
+	var missing []string
+	for _, id := range ids {
+		if _, ok := g.blocks[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		if err := g.prefetch(ctx, missing); err != nil {
+			return nil, err
+		}
+	}
+	blocks := make([]*notiontypes.Block, len(ids))
+	for i, id := range ids {
+		blocks[i] = g.blocks[id]
+	}
+	return blocks, nil
+}
+
+// GetBlocksContext is like GetBlocks, but uses ctx for its requests. It
+// makes the client a notiontypes.BlockGetter, see Block.Children.
+func (c *Client) GetBlocksContext(ctx context.Context, ids ...string) ([]*notiontypes.Block, error) {
+	return c.WithContext(ctx).GetBlocks(ids...)
+}
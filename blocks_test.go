@@ -0,0 +1,114 @@
+package notion_test
+
+import (
+	"testing"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/notiontest"
+	"github.com/tmc/notion/notiontypes"
+	"github.com/tmc/notion/pagination"
+)
+
+func TestGetBlocks(t *testing.T) {
+	s := notiontest.NewServer()
+	defer s.Close()
+	title := func(s string) map[string]interface{} {
+		return map[string]interface{}{"title": [][]string{{s}}}
+	}
+	s.AddBlock(&notiontypes.Block{ID: "a", Type: notiontypes.BlockToggle, Properties: title("A"), Content: []*notiontypes.Block{
+		{ID: "a1", Type: notiontypes.BlockText, Properties: title("A1")},
+	}})
+	s.AddBlock(&notiontypes.Block{ID: "b", Type: notiontypes.BlockText, Properties: title("B")})
+	c := s.Client()
+
+	blocks, err := c.GetBlocks("b", "missing", "a", "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) != 4 || blocks[1] != nil || blocks[0] != blocks[3] {
+		t.Fatalf("unexpected blocks %v", blocks)
+	}
+	if blocks[2].InlineContent[0].Text != "A" || blocks[2].Content != nil || len(blocks[2].ContentIDs) != 1 {
+		t.Errorf("block a not parsed shallowly: %+v", blocks[2])
+	}
+
+	blocks, err = c.GetBlocksWithOptions([]string{"a"}, notion.BlockOptions{Content: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks[0].Content) != 1 || blocks[0].Content[0].InlineContent[0].Text != "A1" {
+		t.Errorf("content of block a not resolved")
+	}
+}
+
+func TestGetBlockWithMaxDepth(t *testing.T) {
+	s := notiontest.NewServer()
+	defer s.Close()
+	s.AddBlock(&notiontypes.Block{ID: "page", Type: notiontypes.BlockPage, Content: []*notiontypes.Block{
+		{ID: "toggle", Type: notiontypes.BlockToggle, Content: []*notiontypes.Block{
+			{ID: "text", Type: notiontypes.BlockText, Content: []*notiontypes.Block{
+				{ID: "nested", Type: notiontypes.BlockText},
+			}},
+		}},
+		{ID: "sub", Type: notiontypes.BlockPage, Content: []*notiontypes.Block{
+			{ID: "subtext", Type: notiontypes.BlockText},
+		}},
+	}})
+	page, err := s.Client().GetBlockWithOptions("page", notion.BlockOptions{MaxDepth: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Content) != 2 || len(page.Content[0].Content) != 1 {
+		t.Fatalf("first two levels not resolved")
+	}
+	text := page.Content[0].Content[0]
+	if text.Content != nil || len(text.ContentIDs) != 1 {
+		t.Errorf("third level resolved")
+	}
+	if sub := page.Content[1]; sub.Content != nil {
+		t.Errorf("content of sub-page resolved")
+	}
+}
+
+func TestLoadPageChunk(t *testing.T) {
+	s := notiontest.NewServer()
+	defer s.Close()
+	s.AddBlock(&notiontypes.Block{
+		ID:      "4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e",
+		Type:    notiontypes.BlockPage,
+		Content: []*notiontypes.Block{{ID: "5b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e", Type: notiontypes.BlockText}},
+	})
+	rm, next, err := s.Client().LoadPageChunk("4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e", pagination.Start(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rm.Blocks) != 2 || !next.Done() {
+		t.Errorf("got %d blocks and cursor %v, want 2 blocks and the last chunk", len(rm.Blocks), next)
+	}
+}
+
+func TestPublicClient(t *testing.T) {
+	s := notiontest.NewServer()
+	defer s.Close()
+	s.AddBlock(&notiontypes.Block{ID: "4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e", Type: notiontypes.BlockPage, Properties: map[string]interface{}{"title": [][]string{{"Public"}}}})
+	c, err := notion.NewPublicClient(notion.WithBaseURL(s.URL+"/api/v3/"), notion.WithToken("ignored"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := c.GetBlock("4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Title != "Public" {
+		t.Errorf("got title %q", b.Title)
+	}
+	if err := c.UpdateBlock("4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e", "properties.title", "x"); err != notion.ErrPublicClient {
+		t.Errorf("got error %v, want ErrPublicClient", err)
+	}
+
+	// the anonymous client sends no token
+	s.Token = "secret"
+	if _, err := c.GetBlock("4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"); err == nil {
+		t.Error("expected unauthorized error from server requiring a token")
+	}
+}
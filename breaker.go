@@ -0,0 +1,125 @@
+package notion
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned for API calls rejected by an open CircuitBreaker.
+var ErrCircuitOpen = errors.New("notion: circuit breaker is open")
+
+// BreakerState is the state of a CircuitBreaker.
+type BreakerState int
+
+const (
+	// BreakerClosed lets all calls through.
+	BreakerClosed BreakerState = iota
+	// BreakerOpen rejects all calls.
+	BreakerOpen
+	// BreakerHalfOpen lets a single trial call through, which closes the
+	// breaker if it succeeds and opens it again otherwise.
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// CircuitBreaker stops a client from calling the API after consecutive
+// failures, i.e. network errors, server errors and rate limiting, and lets
+// a trial call through after a cooldown period.
+//
+// A CircuitBreaker may be shared by several clients.
+type CircuitBreaker struct {
+	// Threshold is the number of consecutive failures that open the breaker.
+	// It defaults to 5.
+	Threshold int
+	// Cooldown is how long the breaker stays open before it lets a trial
+	// call through. It defaults to 30 seconds.
+	Cooldown time.Duration
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+}
+
+// State returns the current state of the breaker.
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// allow reports whether a call may be made at now, and whether that changed
+// the state of the breaker.
+func (b *CircuitBreaker) allow(now time.Time) (changed bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case BreakerOpen:
+		cooldown := b.Cooldown
+		if cooldown == 0 {
+			cooldown = 30 * time.Second
+		}
+		if now.Sub(b.openedAt) < cooldown {
+			return false, ErrCircuitOpen
+		}
+		b.state = BreakerHalfOpen
+		return true, nil
+	case BreakerHalfOpen:
+		// the trial call is in flight
+		return false, ErrCircuitOpen
+	}
+	return false, nil
+}
+
+// record records the outcome of a call made at now and reports whether that
+// changed the state of the breaker.
+func (b *CircuitBreaker) record(failed bool, now time.Time) (changed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	prev := b.state
+	if !failed {
+		b.failures = 0
+		b.state = BreakerClosed
+		return prev != b.state
+	}
+	b.failures++
+	threshold := b.Threshold
+	if threshold == 0 {
+		threshold = 5
+	}
+	if b.state == BreakerHalfOpen || b.failures >= threshold {
+		b.state = BreakerOpen
+		b.openedAt = now
+	}
+	return prev != b.state
+}
+
+// isFailure reports whether a call that ended with status and err counts as
+// a failure of the API rather than of the request.
+func isFailure(status int, err error) bool {
+	if err != nil && status == 0 {
+		return true
+	}
+	return status >= 500 || status == http.StatusTooManyRequests
+}
+
+// WithCircuitBreaker makes the client reject API calls with ErrCircuitOpen
+// while b is open.
+func WithCircuitBreaker(b *CircuitBreaker) ClientOption {
+	return func(c *Client) {
+		c.breaker = b
+	}
+}
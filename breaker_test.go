@@ -0,0 +1,82 @@
+package notion_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/notiontest"
+	"github.com/tmc/notion/notiontypes"
+)
+
+type manualClock struct {
+	now time.Time
+}
+
+func (c *manualClock) Now() time.Time {
+	return c.now
+}
+
+type breakerMetrics struct {
+	calls  int
+	states []notion.BreakerState
+}
+
+func (m *breakerMetrics) APICall(endpoint string, status int, d time.Duration) {
+	m.calls++
+}
+
+func (m *breakerMetrics) BreakerStateChanged(state notion.BreakerState) {
+	m.states = append(m.states, state)
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	s := notiontest.NewServer()
+	defer s.Close()
+	pageID := "4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+	s.AddBlock(&notiontypes.Block{ID: pageID, Type: notiontypes.BlockPage})
+	clock := &manualClock{now: time.Unix(0, 0)}
+	metrics := &breakerMetrics{}
+	b := &notion.CircuitBreaker{Threshold: 2, Cooldown: time.Minute}
+	c := s.Client(notion.WithClock(clock), notion.WithCircuitBreaker(b), notion.WithMetrics(metrics))
+
+	s.Fail("loadPageChunk", 3)
+	for i := 0; i < 2; i++ {
+		if _, err := c.GetBlock(pageID); err == nil || err == notion.ErrCircuitOpen {
+			t.Fatalf("call %d: got %v, want server error", i, err)
+		}
+	}
+	if _, err := c.GetBlock(pageID); err != notion.ErrCircuitOpen {
+		t.Fatalf("got %v, want ErrCircuitOpen", err)
+	}
+
+	// the trial call fails and opens the breaker again
+	clock.now = clock.now.Add(time.Minute)
+	if _, err := c.GetBlock(pageID); err == nil || err == notion.ErrCircuitOpen {
+		t.Fatalf("got %v, want server error", err)
+	}
+	if _, err := c.GetBlock(pageID); err != notion.ErrCircuitOpen {
+		t.Fatalf("got %v, want ErrCircuitOpen", err)
+	}
+
+	clock.now = clock.now.Add(time.Minute)
+	if _, err := c.GetBlock(pageID); err != nil {
+		t.Fatal(err)
+	}
+	if b.State() != notion.BreakerClosed {
+		t.Errorf("got state %v, want closed", b.State())
+	}
+	want := []notion.BreakerState{notion.BreakerOpen, notion.BreakerHalfOpen, notion.BreakerOpen, notion.BreakerHalfOpen, notion.BreakerClosed}
+	if len(metrics.states) != len(want) {
+		t.Fatalf("got state changes %v, want %v", metrics.states, want)
+	}
+	for i := range want {
+		if metrics.states[i] != want[i] {
+			t.Errorf("got state changes %v, want %v", metrics.states, want)
+			break
+		}
+	}
+	if metrics.calls != 4 {
+		t.Errorf("got %d calls, want 4", metrics.calls)
+	}
+}
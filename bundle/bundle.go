@@ -0,0 +1,217 @@
+// Package bundle writes a page and its sub-pages into a single-file
+// .notionpkg bundle, and restores bundles elsewhere.
+//
+// A bundle is a zip archive holding a manifest (manifest.json), the JSON of
+// every page as returned by Client.GetPage (pages/<id>.json) and the files
+// hosted by notion that the pages reference (assets/<block id><ext>).
+package bundle
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// Ext is the file extension of bundles.
+const Ext = ".notionpkg"
+
+// Version is the version of the bundle format written by Write.
+const Version = 1
+
+// ManifestFile is the name of the manifest in bundles.
+const ManifestFile = "manifest.json"
+
+// Manifest describes the content of a bundle.
+type Manifest struct {
+	Version int       `json:"version"`
+	RootID  string    `json:"root_id"`
+	Created time.Time `json:"created"`
+	// Pages are in the order they were crawled in, parents first.
+	Pages  []*Page  `json:"pages"`
+	Assets []*Asset `json:"assets,omitempty"`
+	// Skipped lists the ids of the pages and blocks that couldn't be read.
+	Skipped []string `json:"skipped,omitempty"`
+}
+
+// Page is a page of a bundle.
+type Page struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	// Path is the path of the JSON of the page in the bundle.
+	Path string `json:"path"`
+	// Ancestors holds the ids of the ancestors of the page in the bundle,
+	// starting with the root.
+	Ancestors []string `json:"ancestors,omitempty"`
+}
+
+// Asset is a file of a bundle.
+type Asset struct {
+	// BlockID is the id of the block referencing the file at URL.
+	BlockID     string `json:"block_id"`
+	URL         string `json:"url"`
+	Path        string `json:"path"`
+	ContentType string `json:"content_type,omitempty"`
+	Size        int64  `json:"size"`
+	SHA256      string `json:"sha256"`
+}
+
+// Options configures Write.
+type Options struct {
+	// Filter selects the pages and blocks written.
+	Filter *notion.Filter
+	// AllAssets also bundles the files referenced by pages that aren't
+	// hosted by notion, e.g. images linked from other sites.
+	AllAssets bool
+	// SkipAssets bundles no files: restored pages reference the original
+	// ones, which members of other workspaces can't read.
+	SkipAssets bool
+	// HTTPClient downloads assets; it defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// Write crawls the page rootID and writes it, its sub-pages and their
+// assets as a bundle to w. Pages and blocks that can't be read are listed
+// in the manifest as skipped.
+func Write(c *notion.Client, rootID string, w io.Writer, opts Options) (*Manifest, error) {
+	rootID, err := notion.FormatID(rootID)
+	if err != nil {
+		return nil, err
+	}
+	m := &Manifest{Version: Version, RootID: rootID, Created: time.Now().UTC()}
+	zw := zip.NewWriter(w)
+	r, err := c.CrawlWithOptions(rootID, notion.CrawlOptions{Filter: opts.Filter}, func(p *notion.Page, ancestors []string) error {
+		entry := &Page{ID: p.ID, Title: p.Title, Path: "pages/" + p.ID + ".json", Ancestors: ancestors}
+		if err := writeJSON(zw, entry.Path, p); err != nil {
+			return errors.Wrapf(err, "writing page %v", p.ID)
+		}
+		m.Pages = append(m.Pages, entry)
+		if opts.SkipAssets {
+			return nil
+		}
+		return writeAssets(c, zw, m, p.Content, &opts)
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range r.Skipped {
+		m.Skipped = append(m.Skipped, b.ID)
+	}
+	if err := writeJSON(zw, ManifestFile, m); err != nil {
+		return nil, err
+	}
+	return m, zw.Close()
+}
+
+func writeJSON(zw *zip.Writer, name string, v interface{}) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// writeAssets adds the files referenced by blocks, not descending into
+// sub-pages, to the bundle.
+func writeAssets(c *notion.Client, zw *zip.Writer, m *Manifest, blocks []*notiontypes.Block, opts *Options) error {
+	for _, b := range blocks {
+		if b.IsPage() {
+			continue
+		}
+		if u := assetURL(b); u != "" && (isNotionHosted(u) || opts.AllAssets) {
+			a, err := writeAsset(c, zw, b, u, opts)
+			if err != nil {
+				return errors.Wrapf(err, "bundling the file of block %v", b.ID)
+			}
+			m.Assets = append(m.Assets, a)
+		}
+		if err := writeAssets(c, zw, m, b.Content, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeAsset(c *notion.Client, zw *zip.Writer, b *notiontypes.Block, u string, opts *Options) (*Asset, error) {
+	get := u
+	if strings.HasPrefix(get, "/") {
+		get = "https://www.notion.so" + get
+	}
+	if isNotionHosted(get) {
+		urls, err := c.GetSignedFileURLs(b.ID, get)
+		if err != nil {
+			return nil, errors.Wrap(err, "signing file url")
+		}
+		get = urls[0]
+	}
+	client := opts.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Get(get)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("bundle: downloading %v: %v", u, resp.Status)
+	}
+	ext := ""
+	if parsed, err := url.Parse(u); err == nil {
+		ext = path.Ext(parsed.Path)
+	}
+	a := &Asset{
+		BlockID:     b.ID,
+		URL:         u,
+		Path:        "assets/" + strings.Replace(b.ID, "-", "", -1) + ext,
+		ContentType: resp.Header.Get("Content-Type"),
+	}
+	f, err := zw.Create(a.Path)
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	if a.Size, err = io.Copy(io.MultiWriter(f, h), resp.Body); err != nil {
+		return nil, err
+	}
+	a.SHA256 = hex.EncodeToString(h.Sum(nil))
+	return a, nil
+}
+
+// assetURL returns the location of the file referenced by b, if any.
+func assetURL(b *notiontypes.Block) string {
+	switch b.Type {
+	case notiontypes.BlockImage:
+		if b.Source != "" {
+			return b.Source
+		}
+		if b.FormatImage != nil {
+			return b.FormatImage.DisplaySource
+		}
+	case notiontypes.BlockFile, notiontypes.BlockAudio, notiontypes.BlockPDF:
+		return b.Source
+	case notiontypes.BlockVideo:
+		// other videos are embedded from their site
+		if isNotionHosted(b.Source) {
+			return b.Source
+		}
+	}
+	return ""
+}
+
+func isNotionHosted(u string) bool {
+	return strings.HasPrefix(u, "/") || strings.Contains(u, "secure.notion-static.com")
+}
@@ -0,0 +1,84 @@
+package bundle
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tmc/notion/notiontest"
+	"github.com/tmc/notion/notiontypes"
+)
+
+func TestWriteRestore(t *testing.T) {
+	const (
+		rootID  = "4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d60"
+		textID  = "4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d61"
+		imageID = "4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d62"
+		subID   = "4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d63"
+		destID  = "4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d64"
+	)
+	image := []byte("\x89PNG image")
+	files := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(image)
+	}))
+	defer files.Close()
+	imageURL := files.URL + "/logo.png"
+
+	s := notiontest.NewServer()
+	defer s.Close()
+	s.AddBlock(&notiontypes.Block{ID: rootID, Type: notiontypes.BlockPage, Properties: map[string]interface{}{"title": []interface{}{[]interface{}{"Handbook"}}}, Content: []*notiontypes.Block{
+		{ID: textID, Type: notiontypes.BlockText, Properties: map[string]interface{}{"title": []interface{}{
+			[]interface{}{"see "},
+			[]interface{}{"‣", []interface{}{[]interface{}{"p", subID}}},
+			[]interface{}{" and ", []interface{}{[]interface{}{"a", "/" + "4b1e8f5c9a0e4b6e8d3c1f2a3b4c5d62"}}},
+		}}},
+		{ID: imageID, Type: notiontypes.BlockImage, Properties: map[string]interface{}{"source": []interface{}{[]interface{}{imageURL}}}},
+		{ID: subID, Type: notiontypes.BlockPage, Properties: map[string]interface{}{"title": []interface{}{[]interface{}{"Onboarding"}}}},
+	}})
+	s.AddBlock(&notiontypes.Block{ID: destID, Type: notiontypes.BlockPage})
+	c := s.Client()
+
+	buf := new(bytes.Buffer)
+	m, err := Write(c, rootID, buf, Options{AllAssets: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Pages) != 2 || len(m.Assets) != 1 || m.Assets[0].Size != int64(len(image)) {
+		t.Fatalf("got manifest with %d pages and assets %v", len(m.Pages), m.Assets)
+	}
+
+	b, err := NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids, err := b.Restore(c, destID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(s.Transactions()); n != 1 {
+		t.Errorf("restored in %d transactions, want 1", n)
+	}
+	if got := s.Block(destID).ContentIDs; len(got) != 1 || got[0] != ids[rootID] {
+		t.Fatalf("destination content = %v, want the copy %v", got, ids[rootID])
+	}
+	root := s.Block(ids[rootID])
+	if want := []string{ids[textID], ids[imageID], ids[subID]}; fmt.Sprint(root.ContentIDs) != fmt.Sprint(want) {
+		t.Fatalf("restored root content = %v, want %v", root.ContentIDs, want)
+	}
+	text := fmt.Sprint(s.Block(ids[textID]).Properties["title"])
+	imageLink := "/" + strings.Replace(ids[imageID], "-", "", -1)
+	if want := fmt.Sprintf("[[see ] [‣ [[p %v]]] [ and  [[a %v]]]]", ids[subID], imageLink); text != want {
+		t.Errorf("restored text %v, want %v", text, want)
+	}
+	source := s.Block(ids[imageID]).Source
+	if source == imageURL || !bytes.Equal(s.File(source), image) {
+		t.Errorf("restored image source %v doesn't hold the bundled file", source)
+	}
+	if s.Block(ids[subID]).Title != "Onboarding" {
+		t.Errorf("sub-page wasn't restored")
+	}
+}
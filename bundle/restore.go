@@ -0,0 +1,263 @@
+package bundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// Bundle is a bundle opened for reading.
+type Bundle struct {
+	Manifest *Manifest
+
+	closer io.Closer
+	files  map[string]*zip.File
+}
+
+// Open opens the bundle file name.
+func Open(name string) (*Bundle, error) {
+	rc, err := zip.OpenReader(name)
+	if err != nil {
+		return nil, err
+	}
+	b, err := newBundle(&rc.Reader)
+	if err != nil {
+		rc.Close()
+		return nil, errors.Wrapf(err, "opening %v", name)
+	}
+	b.closer = rc
+	return b, nil
+}
+
+// NewReader reads a bundle from r, which holds size bytes.
+func NewReader(r io.ReaderAt, size int64) (*Bundle, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	return newBundle(zr)
+}
+
+func newBundle(zr *zip.Reader) (*Bundle, error) {
+	b := &Bundle{files: make(map[string]*zip.File)}
+	for _, f := range zr.File {
+		b.files[f.Name] = f
+	}
+	b.Manifest = &Manifest{}
+	if err := b.readJSON(ManifestFile, b.Manifest); err != nil {
+		return nil, errors.Wrap(err, "reading manifest")
+	}
+	if b.Manifest.Version > Version {
+		return nil, errors.Errorf("bundle: unsupported version %d", b.Manifest.Version)
+	}
+	return b, nil
+}
+
+// Close closes the bundle file opened by Open.
+func (b *Bundle) Close() error {
+	if b.closer == nil {
+		return nil
+	}
+	return b.closer.Close()
+}
+
+func (b *Bundle) open(name string) (io.ReadCloser, error) {
+	f, ok := b.files[name]
+	if !ok {
+		return nil, errors.Errorf("bundle: no file %v", name)
+	}
+	return f.Open()
+}
+
+func (b *Bundle) readJSON(name string, v interface{}) error {
+	rc, err := b.open(name)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return json.NewDecoder(rc).Decode(v)
+}
+
+// Page returns the page id of the bundle.
+func (b *Bundle) Page(id string) (*notion.Page, error) {
+	for _, p := range b.Manifest.Pages {
+		if p.ID == id {
+			page := &notion.Page{}
+			if err := b.readJSON(p.Path, page); err != nil {
+				return nil, errors.Wrapf(err, "reading page %v", id)
+			}
+			return page, nil
+		}
+	}
+	return nil, errors.Errorf("bundle: no page %v", id)
+}
+
+// Restore creates a copy of the pages of the bundle at the end of the page
+// parentID, in a single transaction, and returns the ids of the copies of
+// the blocks by original id.
+//
+// Blocks get new ids, and the mentions of and links to the pages and
+// blocks of the bundle are remapped to their copies; references to
+// anything else are kept as is. Bundled files are uploaded again.
+// Databases are not restored: copies of their blocks show the original
+// database, if it's readable.
+func (b *Bundle) Restore(c *notion.Client, parentID string) (map[string]string, error) {
+	pages := make(map[string]*notiontypes.Block)
+	ids := make(map[string]string)
+	for _, entry := range b.Manifest.Pages {
+		p, err := b.Page(entry.ID)
+		if err != nil {
+			return nil, err
+		}
+		pages[p.ID] = p.Block
+		assignIDs(p.Block, ids)
+	}
+	root := pages[b.Manifest.RootID]
+	if root == nil {
+		return nil, errors.Errorf("bundle: no root page %v", b.Manifest.RootID)
+	}
+
+	r := &restorer{ids: ids, urls: make(map[string]string), pages: pages}
+	for _, a := range b.Manifest.Assets {
+		u, err := b.upload(c, a)
+		if err != nil {
+			return nil, errors.Wrapf(err, "uploading the file of block %v", a.BlockID)
+		}
+		r.urls[a.URL] = u
+	}
+	if err := c.AppendBlocks(parentID, r.copy(root)); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (b *Bundle) upload(c *notion.Client, a *Asset) (string, error) {
+	rc, err := b.open(a.Path)
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+	data, err := ioutil.ReadAll(rc)
+	if err != nil {
+		return "", err
+	}
+	name := path.Base(a.URL)
+	if i := strings.IndexAny(name, "?#"); i >= 0 {
+		name = name[:i]
+	}
+	return c.UploadFile(name, a.ContentType, bytes.NewReader(data))
+}
+
+// assignIDs assigns new ids to b and its content, not descending into
+// sub-pages.
+func assignIDs(b *notiontypes.Block, ids map[string]string) {
+	if _, ok := ids[b.ID]; !ok {
+		ids[b.ID] = notion.NewBlockID()
+	}
+	for _, child := range b.Content {
+		if !child.IsPage() {
+			assignIDs(child, ids)
+		}
+	}
+}
+
+// idPattern matches block ids, with or without dashes.
+var idPattern = regexp.MustCompile(`[0-9a-f]{8}-?[0-9a-f]{4}-?[0-9a-f]{4}-?[0-9a-f]{4}-?[0-9a-f]{12}`)
+
+type restorer struct {
+	// ids maps the ids of the blocks of the bundle to those of their copies
+	ids map[string]string
+	// urls maps the URLs of bundled files to those of the uploaded copies
+	urls  map[string]string
+	pages map[string]*notiontypes.Block
+	// ids of the pages copied so far
+	copied map[string]bool
+}
+
+// copy returns a copy of b for AppendBlocks, with the content of the
+// bundled sub-pages.
+func (r *restorer) copy(b *notiontypes.Block) *notiontypes.Block {
+	dup := &notiontypes.Block{
+		ID:         r.ids[b.ID],
+		Type:       b.Type,
+		Properties: make(map[string]interface{}, len(b.Properties)),
+	}
+	for k, v := range b.Properties {
+		dup.Properties[k] = r.remapValue(v)
+	}
+	if len(b.FormatRaw) > 0 {
+		var format interface{}
+		if err := json.Unmarshal(b.FormatRaw, &format); err == nil {
+			dup.FormatRaw, _ = json.Marshal(r.remapValue(format))
+		} else {
+			dup.FormatRaw = b.FormatRaw
+		}
+	}
+	if r.copied == nil {
+		r.copied = make(map[string]bool)
+	}
+	r.copied[b.ID] = true
+	for _, child := range b.Content {
+		if child.IsPage() {
+			page, ok := r.pages[child.ID]
+			if !ok || r.copied[child.ID] {
+				// filtered out, or a link to a page already copied
+				continue
+			}
+			child = page
+		}
+		dup.Content = append(dup.Content, r.copy(child))
+	}
+	return dup
+}
+
+// remapValue returns a copy of the property or format value v with the
+// bundled files and ids in its strings remapped.
+func (r *restorer) remapValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string:
+		return r.remap(v)
+	case []interface{}:
+		res := make([]interface{}, len(v))
+		for i, e := range v {
+			res[i] = r.remapValue(e)
+		}
+		return res
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			res[k] = r.remapValue(e)
+		}
+		return res
+	}
+	return v
+}
+
+func (r *restorer) remap(s string) string {
+	if u, ok := r.urls[s]; ok {
+		return u
+	}
+	return idPattern.ReplaceAllStringFunc(s, func(id string) string {
+		formatted, err := notion.FormatID(id)
+		if err != nil {
+			return id
+		}
+		dup, ok := r.ids[formatted]
+		if !ok {
+			return id
+		}
+		if !strings.Contains(id, "-") {
+			return strings.Replace(dup, "-", "", -1)
+		}
+		return dup
+	})
+}
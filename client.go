@@ -2,6 +2,7 @@ package notion
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -12,6 +13,8 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/tmc/notion/notiontypes"
+	"github.com/tmc/notion/pagination"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const defaultBaseURL = "https://www.notion.so/api/v3/"
@@ -22,6 +25,19 @@ type Client struct {
 	token   string
 	client  *http.Client
 	logger  Logger
+	clock   Clock
+	newID   func() string
+
+	redactedProperties map[string]bool
+	logBodyLimit       int
+
+	ctx     context.Context
+	tracer  trace.Tracer
+	metrics Metrics
+	breaker *CircuitBreaker
+
+	// public is set for anonymous clients of NewPublicClient
+	public bool
 }
 
 // NewClient initializes a new Client.
@@ -29,6 +45,8 @@ func NewClient(opts ...ClientOption) (*Client, error) {
 	c := &Client{
 		baseURL: defaultBaseURL,
 		logger:  &WrapLogrus{logrus.New()},
+		clock:   systemClock{},
+		newID:   NewBlockID,
 	}
 	for _, o := range opts {
 		o(c)
@@ -39,6 +57,39 @@ func NewClient(opts ...ClientOption) (*Client, error) {
 	return c, nil
 }
 
+// NewPublicClient returns an anonymous client, which can read pages that
+// are shared to the web without a token. Pages are loaded and resolved as
+// with other clients, but only public pages and their public sub-pages can
+// be read, and submitting changes fails with ErrPublicClient. A token set
+// by opts is ignored.
+func NewPublicClient(opts ...ClientOption) (*Client, error) {
+	c, err := NewClient(opts...)
+	if err != nil {
+		return nil, err
+	}
+	c.token, c.public = "", true
+	return c, nil
+}
+
+// ErrPublicClient is returned by clients of NewPublicClient for requests
+// that need authentication, such as changes.
+var ErrPublicClient = errors.New("notion: public client can't make changes")
+
+// WithContext returns a copy of c whose API calls use ctx, e.g. to cancel
+// them or to make their trace spans children of the span in ctx.
+func (c *Client) WithContext(ctx context.Context) *Client {
+	c2 := *c
+	c2.ctx = ctx
+	return &c2
+}
+
+func (c *Client) context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
 func (c *Client) url(path string) string {
 	return fmt.Sprintf("%s%s", c.baseURL, path)
 }
@@ -52,41 +103,75 @@ func (c *Client) post(payload interface{}, pattern string, args ...interface{})
 	if err := json.NewEncoder(buf).Encode(payload); err != nil {
 		return nil, err
 	}
-	c.logger.WithField("fn", "post").Debugln(buf.String())
+	c.logger.WithField("fn", "post").Debugln(c.logBody(buf.Bytes()))
 	return c.do("POST", buf, pattern, args...)
 }
 
 func (c *Client) do(method string, body io.Reader, pattern string, args ...interface{}) ([]byte, error) {
-	path := c.url(fmt.Sprintf(pattern, args...))
+	endpoint := fmt.Sprintf(pattern, args...)
+	if c.breaker != nil {
+		changed, err := c.breaker.allow(c.clock.Now())
+		if err != nil {
+			return nil, err
+		}
+		c.breakerStateChanged(changed)
+	}
+	ctx, span := c.startSpan(method, endpoint)
+	start := c.clock.Now()
+	buf, status, err := c.roundTrip(ctx, method, body, endpoint)
+	if span != nil {
+		endSpan(span, status, buf, err)
+	}
+	if c.metrics != nil {
+		c.metrics.APICall(endpoint, status, c.clock.Now().Sub(start))
+	}
+	if c.breaker != nil {
+		c.breakerStateChanged(c.breaker.record(isFailure(status, err), c.clock.Now()))
+	}
+	return buf, err
+}
+
+func (c *Client) breakerStateChanged(changed bool) {
+	if changed && c.metrics != nil {
+		c.metrics.BreakerStateChanged(c.breaker.State())
+	}
+}
+
+// roundTrip performs a request and returns the response body and status code.
+func (c *Client) roundTrip(ctx context.Context, method string, body io.Reader, endpoint string) ([]byte, int, error) {
+	path := c.url(endpoint)
 	req, err := http.NewRequest(method, path, body)
 	if err != nil {
-		return nil, errors.Wrap(err, "creating request")
+		return nil, 0, errors.Wrap(err, "creating request")
+	}
+	req = req.WithContext(ctx)
+	if c.token != "" {
+		req.Header.Set("cookie", fmt.Sprintf("token=%v", c.token))
 	}
-	req.Header.Set("cookie", fmt.Sprintf("token=%v", c.token))
 	if body != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "performing request")
+		return nil, 0, errors.Wrap(err, "performing request")
 	}
 	defer resp.Body.Close()
 	logger := c.logger.WithField("method", method).WithField("path", path).WithField("status_code", resp.StatusCode)
 	buf, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		logger.Warnln("error reading body")
-		return nil, err
+		return nil, resp.StatusCode, err
 	}
-	logger.WithField("body", string(buf)).Debugln("api call finished")
+	logger.WithField("body", c.logBody(buf)).Debugln("api call finished")
 	if resp.StatusCode != http.StatusOK {
-		return buf, &Error{
+		return buf, resp.StatusCode, &Error{
 			URL:        path,
 			StatusCode: resp.StatusCode,
 			Body:       string(buf),
 		}
 	}
-	return buf, nil
+	return buf, resp.StatusCode, nil
 }
 
 type getRecordValuesRequest struct {
@@ -115,7 +200,7 @@ func (c *Client) GetRecordValues(records ...Record) ([]*notiontypes.BlockWithRol
 	if err != nil {
 		return nil, err
 	}
-	c.logger.Debugln(string(b))
+	c.logger.Debugln(c.logBody(b))
 	if err := json.Unmarshal(b, r); err != nil {
 		return nil, errors.Wrap(err, "unmarshaling getRecordValuesResponse")
 	}
@@ -123,51 +208,141 @@ func (c *Client) GetRecordValues(records ...Record) ([]*notiontypes.BlockWithRol
 }
 
 type loadPageChunkRequest struct {
-	PageID          string `json:"pageId"`
-	Limit           int64  `json:"limit,omitempty"`
-	Cursor          Cursor `json:"cursor"`
-	VerticalColumns bool   `json:"verticalColumns"`
+	PageID          string            `json:"pageId"`
+	Limit           int64             `json:"limit,omitempty"`
+	Cursor          pagination.Cursor `json:"cursor"`
+	VerticalColumns bool              `json:"verticalColumns"`
 }
 
 type loadPageChunkResponse struct {
 	RecordMap notiontypes.RecordMap `json:"recordMap"`
-	Cursor    Cursor                `json:"cursor"`
+	Cursor    pagination.Cursor     `json:"cursor"`
+}
+
+// pageChunkLimit is the number of blocks GetBlock loads per chunk.
+const pageChunkLimit = 50
+
+// LoadPageChunk loads a chunk of at most limit records of the page pageID,
+// starting at cursor (pagination.Start for the first one). It returns the
+// records and the cursor of the next chunk, which is Done after the last
+// one. GetBlock loads pages this way, chunk by chunk; LoadPageChunk allows
+// other strategies, e.g. loading large pages across several runs.
+func (c *Client) LoadPageChunk(pageID string, cursor pagination.Cursor, limit int) (notiontypes.RecordMap, pagination.Cursor, error) {
+	lp := loadPageChunkRequest{
+		PageID: pageID,
+		Limit:  int64(limit),
+		Cursor: cursor,
+	}
+	r := &loadPageChunkResponse{}
+	b, err := c.post(lp, "loadPageChunk")
+	if err != nil {
+		return r.RecordMap, r.Cursor, err
+	}
+	c.logger.WithField("blockID", pageID).Debugln(c.logBody(b))
+	if err := json.Unmarshal(b, r); err != nil {
+		return r.RecordMap, r.Cursor, errors.Wrap(err, "unmarshaling loadPageChunkResponse")
+	}
+	return r.RecordMap, r.Cursor, nil
 }
 
-// GetPage returns a Page given an id.
+// GetPage returns a Page given an id. Pages that are database rows have no
+// PageProperties if their database can't be read.
 func (c *Client) GetPage(pageId string) (*Page, error) {
-	b, err := c.GetBlock(pageId)
-	return &Page{Block: b}, err
+	return c.getPage(pageId, notiontypes.ResolveOptions{}, make(map[string]*notiontypes.Collection))
+}
+
+// getPage fetches a page. collections holds the collections fetched so far,
+// or nil for those that couldn't be, so that each is fetched only once.
+func (c *Client) getPage(pageId string, opts notiontypes.ResolveOptions, collections map[string]*notiontypes.Collection) (*Page, error) {
+	b, role, err := c.loadBlock(pageId, make(map[string]*notiontypes.Block), opts)
+	if err != nil {
+		return &Page{Block: b}, err
+	}
+	page := &Page{Block: b, Role: role}
+	if b.ParentTable == notiontypes.TableCollection {
+		collection, ok := collections[b.ParentID]
+		if !ok {
+			collection, err = c.GetCollection(b.ParentID)
+			if err != nil {
+				c.logger.WithError(err).WithField("collectionID", b.ParentID).Warnln("unable to fetch collection of page")
+			}
+			collections[b.ParentID] = collection
+		}
+		if collection != nil {
+			page.PageProperties = collection.PageProperties(b)
+		}
+	}
+	return page, nil
 }
 
 // GetBlock returns a Block given an id.
 func (c *Client) GetBlock(blockID string) (*notiontypes.Block, error) {
-	lp := loadPageChunkRequest{
-		PageID: blockID,
-		Limit:  50,
-		Cursor: Cursor{
-			Stack: [][]StackPosition{},
-		},
-	}
+	return c.getBlock(blockID, make(map[string]*notiontypes.Block), notiontypes.ResolveOptions{})
+}
+
+// getBlock fetches and resolves a block. synced holds the originals of
+// synced blocks fetched so far so that each is fetched only once.
+func (c *Client) getBlock(blockID string, synced map[string]*notiontypes.Block, opts notiontypes.ResolveOptions) (*notiontypes.Block, error) {
+	block, _, err := c.loadBlock(blockID, synced, opts)
+	return block, err
+}
+
+// loadBlock is getBlock, also returning the role of the user on the block.
+func (c *Client) loadBlock(blockID string, synced map[string]*notiontypes.Block, opts notiontypes.ResolveOptions) (*notiontypes.Block, string, error) {
 	results := []notiontypes.RecordMap{}
+	cursor := pagination.Start()
 	for {
-		r := &loadPageChunkResponse{}
-		b, err := c.post(lp, "loadPageChunk")
+		rm, next, err := c.LoadPageChunk(blockID, cursor, pageChunkLimit)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
-		c.logger.WithField("blockID", blockID).Debugln(string(b))
-		if err := json.Unmarshal(b, r); err != nil {
-			return nil, errors.Wrap(err, "unmarshaling loadPageChunkResponse")
+		results = append(results, rm)
+		if next.Done() {
+			break
 		}
+		cursor = next
+	}
+	block, err := c.parseBlockFromRecordMaps(blockID, results, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	c.resolveSyncedBlocks(block, synced, opts)
+	role := ""
+	for _, rm := range results {
+		if r := rm.Blocks[blockID]; r != nil && r.Role != "" {
+			role = r.Role
+		}
+	}
+	return block, role, nil
+}
 
-		results = append(results, r.RecordMap)
-		lp.Cursor = r.Cursor
-		if len(r.Cursor.Stack) == 0 {
-			break
+// resolveSyncedBlocks fetches the originals of synced block copies within b
+// that were not part of b's record map, e.g. because they live on another page.
+func (c *Client) resolveSyncedBlocks(b *notiontypes.Block, synced map[string]*notiontypes.Block, opts notiontypes.ResolveOptions) {
+	for _, child := range b.Content {
+		if child.IsPage() {
+			continue
+		}
+		if child.Type != notiontypes.BlockSyncedBlockCopy || child.Content != nil || child.OriginalID == "" {
+			c.resolveSyncedBlocks(child, synced, opts)
+			continue
+		}
+		original, ok := synced[child.OriginalID]
+		if !ok {
+			// a nil entry stops synced blocks that (indirectly) contain themselves
+			synced[child.OriginalID] = nil
+			var err error
+			original, err = c.getBlock(child.OriginalID, synced, opts)
+			if err != nil {
+				c.logger.WithError(err).WithField("blockID", child.OriginalID).Warnln("unable to fetch original of synced block")
+				continue
+			}
+			synced[child.OriginalID] = original
+		}
+		if original != nil {
+			child.CopyContent(original)
 		}
 	}
-	return c.parseBlockFromRecordMaps(blockID, results)
 }
 
 func mergeRecordMaps(rms ...notiontypes.RecordMap) (notiontypes.RecordMap, error) {
@@ -200,7 +375,7 @@ func mergeRecordMaps(rms ...notiontypes.RecordMap) (notiontypes.RecordMap, error
 	return result, nil
 }
 
-func (c *Client) parseBlockFromRecordMaps(blockID string, responses []notiontypes.RecordMap) (*notiontypes.Block, error) {
+func (c *Client) parseBlockFromRecordMaps(blockID string, responses []notiontypes.RecordMap, opts notiontypes.ResolveOptions) (*notiontypes.Block, error) {
 	rm, err := mergeRecordMaps(responses...)
 	if err != nil {
 		return nil, err
@@ -210,50 +385,59 @@ func (c *Client) parseBlockFromRecordMaps(blockID string, responses []notiontype
 		return nil, fmt.Errorf("notion: missing block id in block list")
 	}
 	block := blockBlock.Value
+	if block == nil {
+		return nil, &AccessError{ID: blockID, Role: blockBlock.Role}
+	}
 	blocks := make(map[string]*notiontypes.Block, len(rm.Blocks))
 	for k, v := range rm.Blocks {
 		blocks[k] = v.Value
 	}
-	if err := notiontypes.ResolveBlock(block, blocks); err != nil {
+	if err := notiontypes.ResolveBlockWithOptions(block, blocks, opts); err != nil {
 		return nil, errors.Wrap(err, "resolveBlock failed")
 	}
 	return block, nil
 }
 
 type operation struct {
-	ID      string     `json:"id"`
-	Table   string     `json:"table"`
-	Path    []string   `json:"path"`
-	Command string     `json:"command"`
-	Args    [][]string `json:"args"`
+	ID      string      `json:"id"`
+	Table   string      `json:"table"`
+	Path    []string    `json:"path"`
+	Command string      `json:"command"`
+	Args    interface{} `json:"args"`
 }
 
 type submitTransactionRequest struct {
 	Operations []*operation `json:"operations"`
 }
-type submitTransactionResponse map[string]interface{}
 
-// UpdateBlock returns a Block given an id.
-func (c *Client) UpdateBlock(blockID string, path string, value string) error {
+func (c *Client) submitTransaction(ops ...*operation) error {
+	if c.public {
+		return ErrPublicClient
+	}
 	lp := submitTransactionRequest{
-		Operations: []*operation{
-			&operation{
-				ID:      blockID,
-				Table:   "block",
-				Path:    strings.Split(path, "."),
-				Command: "set",
-				Args: [][]string{
-					[]string{value},
-				},
-			},
-		},
+		Operations: ops,
 	}
-	r := &submitTransactionResponse{}
 	b, err := c.post(lp, "submitTransaction")
 	if err != nil {
 		return err
 	}
-	c.logger.WithField("blockID", blockID).Debugln(string(b))
-	c.logger.Debugln("resp:", r)
+	c.logger.WithField("operations", len(ops)).Debugln(c.logBody(b))
 	return nil
 }
+
+// UpdateBlock sets the value at path (e.g. "properties.title") on the block with the given id.
+func (c *Client) UpdateBlock(blockID string, path string, value string) error {
+	blockID, err := FormatID(blockID)
+	if err != nil {
+		return err
+	}
+	return c.submitTransaction(&operation{
+		ID:      blockID,
+		Table:   "block",
+		Path:    strings.Split(path, "."),
+		Command: "set",
+		Args: [][]string{
+			[]string{value},
+		},
+	})
+}
@@ -0,0 +1,77 @@
+// Command notion-backup stores a page and its sub-pages in a backup directory.
+//
+// If NOTION_BACKUP_KEY is set to a hex or base64 encoded AES key, the pages
+// are encrypted with it.
+package main
+
+import (
+	"compress/gzip"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/backup"
+)
+
+var (
+	flagVerbose = flag.Bool("v", false, "verbose")
+	flagOutput  = flag.String("o", "backup", "backup directory")
+	flagGob     = flag.Bool("gob", false, "store pages with encoding/gob instead of JSON")
+	flagGzip    = flag.Bool("z", false, "compress pages with gzip")
+)
+
+func main() {
+	flag.Parse()
+	if len(flag.Args()) != 1 {
+		flag.Usage()
+		fmt.Fprintln(os.Stderr, "please provide root page id as parameter")
+		os.Exit(1)
+	}
+	if err := run(flag.Args()[0]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(id string) error {
+	opts := []notion.ClientOption{
+		notion.WithToken(os.Getenv("NOTION_TOKEN")),
+	}
+	if *flagVerbose {
+		opts = append(opts, notion.WithDebugLogging())
+	}
+	c, err := notion.NewClient(opts...)
+	if err != nil {
+		return err
+	}
+	var storeOpts []backup.Option
+	if *flagGob {
+		storeOpts = append(storeOpts, backup.WithFormat(backup.Gob))
+	}
+	if *flagGzip {
+		storeOpts = append(storeOpts, backup.WithGzip(gzip.DefaultCompression))
+	}
+	if k := os.Getenv("NOTION_BACKUP_KEY"); k != "" {
+		key, err := backup.ParseKey(k)
+		if err != nil {
+			return err
+		}
+		storeOpts = append(storeOpts, backup.WithEncryptionKey(key))
+	}
+	s, err := backup.NewStore(*flagOutput, storeOpts...)
+	if err != nil {
+		return err
+	}
+	if err := backup.Backup(c, id, nil, s); err != nil {
+		return err
+	}
+	m, err := s.Manifest()
+	if err != nil {
+		return err
+	}
+	for _, id := range m.Skipped {
+		fmt.Fprintf(os.Stderr, "skipped %v, which can't be read\n", id)
+	}
+	return nil
+}
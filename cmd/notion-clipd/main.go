@@ -0,0 +1,276 @@
+// Command notion-clipd runs a local daemon that creates notion pages from clips.
+//
+// Clips are submitted with a single POST to /clip carrying a JSON body with
+// exactly one of "url", "markdown" or "text", plus an optional "title",
+// "icon" (an emoji or a shortcode such as :rocket:) and "parent" page id
+// (defaulting to -parent):
+//
+//	curl -d '{"url":"https://golang.org"}' localhost:7433/clip
+//
+// Markdown may start with YAML front-matter setting the title and icon.
+// Clipped content is sanitized: HTML is stripped (unless -allow-html),
+// links and images with unsafe URLs such as javascript: ones are removed,
+// and the size and nesting of the content are limited (-max-blocks and
+// -max-depth).
+//
+// Clips are queued and created asynchronously; failed creations are retried
+// with exponential backoff.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/emoji"
+	"github.com/tmc/notion/importer"
+	"github.com/tmc/notion/notiontypes"
+)
+
+var (
+	flagVerbose = flag.Bool("v", false, "verbose")
+	flagAddr    = flag.String("addr", "localhost:7433", "address to listen on")
+	flagParent  = flag.String("parent", "", "default parent page id for clips")
+	flagQueue   = flag.Int("queue", 100, "maximum number of pending clips")
+	flagRetries = flag.Int("retries", 5, "number of attempts for each clip")
+	flagFetch   = flag.Bool("fetch-metadata", true, "fetch the title, description, icon and image of clipped urls for their bookmarks")
+	flagHTML    = flag.Bool("allow-html", false, "keep HTML markup in clipped markdown and text instead of stripping it")
+	flagBlocks  = flag.Int("max-blocks", 1000, "maximum number of blocks of a clip, further blocks are dropped (0 for no limit)")
+	flagDepth   = flag.Int("max-depth", 8, "maximum nesting depth of the blocks of a clip, deeper blocks are moved up (0 for no limit)")
+)
+
+func main() {
+	flag.Parse()
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	opts := []notion.ClientOption{
+		notion.WithToken(os.Getenv("NOTION_TOKEN")),
+	}
+	if *flagVerbose {
+		opts = append(opts, notion.WithDebugLogging())
+	}
+	c, err := notion.NewClient(opts...)
+	if err != nil {
+		return err
+	}
+
+	d := &daemon{
+		client: c,
+		queue:  make(chan *clip, *flagQueue),
+	}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		d.work()
+	}()
+
+	srv := &http.Server{Addr: *flagAddr, Handler: d}
+	// shutdown is closed once srv.Shutdown returned, i.e. once no handler
+	// can send to the queue anymore
+	shutdown := make(chan struct{})
+	go func() {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt)
+		<-sig
+		srv.Shutdown(context.Background())
+		close(shutdown)
+	}()
+	log.Println("listening on", *flagAddr)
+	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+	<-shutdown
+	// finish clips that were already accepted
+	close(d.queue)
+	wg.Wait()
+	return nil
+}
+
+type clip struct {
+	URL      string `json:"url,omitempty"`
+	Markdown string `json:"markdown,omitempty"`
+	Text     string `json:"text,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Icon     string `json:"icon,omitempty"`
+	Parent   string `json:"parent,omitempty"`
+}
+
+// fetcher fetches the metadata of clipped urls.
+var fetcher = &importer.HTTPFetcher{Client: &http.Client{Timeout: 10 * time.Second}}
+
+// blocks returns the title, icon and content of the page to create for c.
+func (c *clip) blocks() (string, string, []*notiontypes.Block) {
+	var (
+		content []*notiontypes.Block
+		report  *importer.SanitizeReport
+	)
+	policy := &importer.SanitizePolicy{KeepHTML: *flagHTML, MaxBlocks: *flagBlocks, MaxDepth: *flagDepth}
+	title, icon := c.Title, emoji.Icon(c.Icon)
+	switch {
+	case c.URL != "":
+		b := importer.Bookmark(c.URL)
+		if *flagFetch {
+			if err := importer.EnrichBookmark(b, fetcher); err != nil {
+				log.Printf("fetching metadata of %v: %v", c.URL, err)
+			}
+		}
+		content, report = policy.Sanitize([]*notiontypes.Block{b})
+		if title == "" {
+			title = first(b.Title, c.URL)
+		}
+	case c.Markdown != "":
+		var p *importer.MarkdownPage
+		p, report = policy.MarkdownWithFrontMatter([]byte(c.Markdown))
+		content = p.Content
+		if title == "" {
+			title = p.Title
+		}
+		if icon == "" {
+			icon = p.Icon
+		}
+		if title == "" && len(content) > 0 && content[0].Type == notiontypes.BlockHeader {
+			title = plainText(content[0].InlineContent)
+			content = content[1:]
+		}
+		if title == "" && len(p.Params) > 0 && len(content) > 0 {
+			// the first line of the markdown is that of the front-matter
+			title = plainText(content[0].InlineContent)
+		}
+	default:
+		content, report = policy.Sanitize(importer.Text(c.Text))
+	}
+	if report.Changed() {
+		log.Printf("sanitized clip %q: %+v", first(title, c.URL), *report)
+	}
+	if title == "" {
+		title = firstLine(c.Markdown + c.Text)
+	}
+	return title, icon, content
+}
+
+type daemon struct {
+	client *notion.Client
+	queue  chan *clip
+}
+
+func (d *daemon) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/clip" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	c := &clip{}
+	if err := json.NewDecoder(r.Body).Decode(c); err != nil {
+		http.Error(w, "invalid clip: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	n := 0
+	for _, s := range []string{c.URL, c.Markdown, c.Text} {
+		if s != "" {
+			n++
+		}
+	}
+	if n != 1 {
+		http.Error(w, "exactly one of url, markdown or text is required", http.StatusBadRequest)
+		return
+	}
+	if u := strings.ToLower(c.URL); u != "" && !strings.HasPrefix(u, "http://") && !strings.HasPrefix(u, "https://") {
+		http.Error(w, "url must be an http or https url", http.StatusBadRequest)
+		return
+	}
+	if c.Parent == "" {
+		c.Parent = *flagParent
+	}
+	if c.Parent == "" {
+		http.Error(w, "no parent given and no default parent configured", http.StatusBadRequest)
+		return
+	}
+	select {
+	case d.queue <- c:
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		http.Error(w, "clip queue is full", http.StatusServiceUnavailable)
+	}
+}
+
+func (d *daemon) work() {
+	for c := range d.queue {
+		title, icon, content := c.blocks()
+		backoff := time.Second
+		for attempt := 1; ; attempt++ {
+			id, err := d.client.CreatePage(c.Parent, title, content...)
+			if err == nil {
+				log.Printf("created page %v %q", id, title)
+				if icon != "" {
+					if err := d.client.SetPageIcon(id, icon); err != nil {
+						log.Printf("setting the icon of page %v: %v", id, err)
+					}
+				}
+				break
+			}
+			if attempt >= *flagRetries || !retryable(err) {
+				log.Printf("giving up on clip %q after %d attempts: %v", title, attempt, err)
+				break
+			}
+			log.Printf("clip %q failed (attempt %d), retrying in %v: %v", title, attempt, backoff, err)
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+}
+
+func first(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// retryable reports whether err may succeed on another attempt.
+func retryable(err error) bool {
+	if e, ok := err.(*notion.Error); ok {
+		return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+	}
+	return true
+}
+
+func plainText(inline []*notiontypes.InlineBlock) string {
+	var s []string
+	for _, b := range inline {
+		s = append(s, b.Text)
+	}
+	return strings.Join(s, "")
+}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	s = strings.TrimLeft(s, "#>-* ")
+	if r := []rune(s); len(r) > 80 {
+		s = string(r[:80])
+	}
+	return s
+}
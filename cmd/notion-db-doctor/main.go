@@ -0,0 +1,109 @@
+// Command notion-db-doctor checks the rows of databases for empty titles,
+// relations to deleted pages, invalid URLs, numbers out of range and
+// duplicate select options, and with -fix fixes them as directed by a
+// policy file, such as policy.yaml in this directory. It exits with status
+// 1 if any finding is left unfixed.
+//
+// Usage:
+//
+//	notion-db-doctor [-policy policy.yaml] [-fix] [-format text|json] <collection id>...
+//
+// See package doctor for the checks and fixes.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/doctor"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	flagVerbose = flag.Bool("v", false, "verbose")
+	flagPolicy  = flag.String("policy", "", "YAML file of ranges and fixes")
+	flagFix     = flag.Bool("fix", false, "apply the fixes of the policy")
+	flagFormat  = flag.String("format", "text", "output format (text or json)")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: notion-db-doctor [flags] <collection id>...")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	unfixed, err := run()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if unfixed > 0 {
+		os.Exit(1)
+	}
+}
+
+func run() (int, error) {
+	if flag.NArg() == 0 {
+		return 0, fmt.Errorf("please provide database (collection) ids as parameters")
+	}
+	if *flagFormat != "text" && *flagFormat != "json" {
+		return 0, fmt.Errorf("unknown format %q", *flagFormat)
+	}
+	policy := &doctor.Policy{}
+	if *flagPolicy != "" {
+		var err error
+		if policy, err = readPolicy(*flagPolicy); err != nil {
+			return 0, err
+		}
+	}
+	opts := []notion.ClientOption{
+		notion.WithToken(os.Getenv("NOTION_TOKEN")),
+	}
+	if *flagVerbose {
+		opts = append(opts, notion.WithDebugLogging())
+	}
+	c, err := notion.NewClient(opts...)
+	if err != nil {
+		return 0, err
+	}
+	check := doctor.Check
+	if *flagFix {
+		check = doctor.Fix
+	}
+	unfixed := 0
+	for _, id := range flag.Args() {
+		r, err := check(c, id, policy)
+		if err != nil {
+			return 0, err
+		}
+		if *flagFormat == "json" {
+			err = r.WriteJSON(os.Stdout)
+		} else {
+			if flag.NArg() > 1 {
+				fmt.Printf("%s:\n", id)
+			}
+			err = r.WriteText(os.Stdout)
+		}
+		if err != nil {
+			return 0, err
+		}
+		unfixed += r.Unfixed()
+	}
+	return unfixed, nil
+}
+
+func readPolicy(path string) (*doctor.Policy, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	policy := &doctor.Policy{}
+	if err := yaml.Unmarshal(b, policy); err != nil {
+		return nil, errors.Wrap(err, "reading policy")
+	}
+	return policy, nil
+}
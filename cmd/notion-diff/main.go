@@ -0,0 +1,178 @@
+// Command notion-diff compares two pages, or a page and its snapshot in a
+// backup made with notion-backup.
+//
+// If the backup is encrypted, NOTION_BACKUP_KEY must be set to its key.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/backup"
+	"github.com/tmc/notion/diff"
+	"github.com/tmc/notion/notiontypes"
+)
+
+var (
+	flagVerbose = flag.Bool("v", false, "verbose")
+	flagBackup  = flag.String("backup", "", "backup directory to compare the page with its snapshot in")
+	flagFormat  = flag.String("format", "unified", "output format (unified, jsonpatch or html)")
+	flagColor   = flag.String("color", "auto", "colorize unified output (auto, always or never)")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: notion-diff [flags] old-page-id new-page-id\n")
+		fmt.Fprintf(os.Stderr, "       notion-diff [flags] -backup dir page-id\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	want := 2
+	if *flagBackup != "" {
+		want = 1
+	}
+	if len(flag.Args()) != want {
+		flag.Usage()
+		os.Exit(2)
+	}
+	changed, err := run(flag.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if changed {
+		os.Exit(1)
+	}
+}
+
+// run writes the differences and reports whether there are any.
+func run(args []string) (bool, error) {
+	format, err := diff.ParseFormat(*flagFormat)
+	if err != nil {
+		return false, err
+	}
+	opts := []notion.ClientOption{
+		notion.WithToken(os.Getenv("NOTION_TOKEN")),
+	}
+	if *flagVerbose {
+		opts = append(opts, notion.WithDebugLogging())
+	}
+	c, err := notion.NewClient(opts...)
+	if err != nil {
+		return false, err
+	}
+	var old, new *notiontypes.Block
+	if *flagBackup != "" {
+		if old, err = snapshot(*flagBackup, args[0]); err != nil {
+			return false, err
+		}
+	} else if old, err = c.GetBlock(args[0]); err != nil {
+		return false, err
+	}
+	if new, err = c.GetBlock(args[len(args)-1]); err != nil {
+		return false, err
+	}
+	n := diff.Compare(old, new)
+	var w io.Writer = os.Stdout
+	if format == diff.Unified && colorize() {
+		cw := &colorWriter{w: os.Stdout}
+		defer cw.Flush()
+		w = cw
+	}
+	return n.Changed(), diff.Write(w, n, format)
+}
+
+func snapshot(dir, id string) (*notiontypes.Block, error) {
+	var opts []backup.Option
+	if k := os.Getenv("NOTION_BACKUP_KEY"); k != "" {
+		key, err := backup.ParseKey(k)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, backup.WithEncryptionKey(key))
+	}
+	s, err := backup.NewStore(dir, opts...)
+	if err != nil {
+		return nil, err
+	}
+	id, err = notion.FormatID(id)
+	if err != nil {
+		return nil, err
+	}
+	page, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	return page.Block, nil
+}
+
+func colorize() bool {
+	switch *flagColor {
+	case "always":
+		return true
+	case "never":
+		return false
+	}
+	fi, err := os.Stdout.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0 && os.Getenv("TERM") != "dumb"
+}
+
+// colorWriter colors the lines of a unified diff with ANSI escapes: removed
+// lines red, added lines green and hunk headers cyan.
+type colorWriter struct {
+	w    io.Writer
+	line []byte
+}
+
+func (cw *colorWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			cw.line = append(cw.line, p...)
+			break
+		}
+		cw.line = append(cw.line, p[:i+1]...)
+		p = p[i+1:]
+		if err := cw.Flush(); err != nil {
+			return 0, err
+		}
+	}
+	return n, nil
+}
+
+func (cw *colorWriter) Flush() error {
+	if len(cw.line) == 0 {
+		return nil
+	}
+	line := bytes.TrimSuffix(cw.line, []byte("\n"))
+	color := ""
+	switch {
+	case bytes.HasPrefix(line, []byte("---")), bytes.HasPrefix(line, []byte("+++")):
+		color = "\x1b[1m"
+	case bytes.HasPrefix(line, []byte("@@")):
+		color = "\x1b[36m"
+	case bytes.HasPrefix(line, []byte("-")):
+		color = "\x1b[31m"
+	case bytes.HasPrefix(line, []byte("+")):
+		color = "\x1b[32m"
+	}
+	bw := bufio.NewWriter(cw.w)
+	if color != "" {
+		bw.WriteString(color)
+		bw.Write(line)
+		bw.WriteString("\x1b[0m")
+	} else {
+		bw.Write(line)
+	}
+	if len(line) < len(cw.line) {
+		bw.WriteByte('\n')
+	}
+	cw.line = cw.line[:0]
+	return bw.Flush()
+}
@@ -0,0 +1,397 @@
+// Command notion-export exports a page and its sub-pages as Markdown or HTML files,
+// or with -table the rows of a database as CSV, JSON Lines, SQL or Excel.
+// With -bundle it writes them into a single .notionpkg file instead, which
+// notion-import restores elsewhere.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/bundle"
+	"github.com/tmc/notion/collation"
+	"github.com/tmc/notion/export"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	flagVerbose       = flag.Bool("v", false, "verbose")
+	flagOutput        = flag.String("o", ".", "output directory")
+	flagFormat        = flag.String("format", "markdown", "output format (markdown, html, obsidian, a vault with wikilinks and assets downloaded into attachments, logseq or roam outlines, anki, a tab-separated flashcard file per page with a card per toggle, or json, the widget JSON of export/widget.schema.json)")
+	flagSkipDatabases = flag.Bool("skip-databases", false, "skip databases")
+	flagSkipImages    = flag.Bool("skip-images", false, "skip images")
+	flagIncludeTypes  = flag.String("include-types", "", "comma separated list of the only block types to export")
+	flagExcludeTypes  = flag.String("exclude-types", "", "comma separated list of block types to skip")
+	flagUnder         = flag.String("under", "", "comma separated list of page ids; only pages under these are exported")
+	flagExcludeTitle  = flag.String("exclude-title", "", "skip pages (and their sub-pages) with titles matching this regular expression")
+	flagAssets        = flag.String("assets", "hotlink", "asset policy: hotlink, download or upload")
+	flagUploadURL     = flag.String("upload-url", "", "base URL assets are PUT to with -assets=upload")
+	flagPublicURL     = flag.String("public-url", "", "base URL uploaded assets are served from, if different from -upload-url")
+	flagSyncedLinks   = flag.Bool("synced-links", false, "render copies of synced blocks as links to the original instead of repeating its content")
+	flagColumnsHTML   = flag.Bool("columns-html", false, "preserve column layouts as HTML in Markdown output")
+	flagFrontMatter   = flag.Bool("front-matter", false, "emit YAML front-matter with the properties of database rows in Markdown output")
+	flagFrontKeys     = flag.String("front-matter-keys", "", "comma separated property=key pairs renaming front-matter keys; an empty key drops the property")
+	flagBaseURL       = flag.String("base-url", "", "make links between exported pages absolute, prefixed with this URL")
+	flagUnlink        = flag.Bool("unlink-external", false, "remove links to notion pages outside of the export, keeping their text")
+	flagImageWidths   = flag.String("image-widths", "", "comma separated widths of resized image variants to generate with -assets=download")
+	flagPublic        = flag.Bool("public", false, "read the pages anonymously, as shared to the web, without NOTION_TOKEN")
+	flagIncremental   = flag.Bool("incremental", false, "skip pages that are unchanged since the export whose manifest is in the output directory")
+	flagLocale        = flag.String("locale", "", "order the navigation of -template layouts by title, collated for this BCP 47 locale (e.g. de or sv), instead of export order; \"root\" suits most languages")
+	flagHistory       = flag.Bool("history", false, "also export the saved versions of every page, into <page>.history directories")
+	flagTable         = flag.String("table", "", "export the rows of the database given as parameter instead, as csv, jsonl, sqlite (an SQL script), xlsx or anki (flashcards)")
+	flagWorkers       = flag.Int("workers", 4, "number of pages of rows loaded concurrently with -table")
+	flagAnkiDeck      = flag.String("anki-deck", "", "property of pages or rows holding the Anki deck of their cards, with -format anki or -table anki")
+	flagAnkiTags      = flag.String("anki-tags", "", "property of pages or rows holding the Anki tags of their cards")
+	flagAnkiAnswer    = flag.String("anki-answer", "Answer", "property of rows holding the answer of their cards, with -table anki")
+	flagDiagrams      = flag.String("diagrams", "", "render mermaid and plantuml code blocks as SVG in HTML output: local (with mmdc and plantuml), kroki (with kroki.io) or the URL of a Kroki server")
+	flagDBTables      = flag.Bool("database-tables", false, "render embedded databases and linked database views as tables of the rows and properties of their view")
+	flagMindMap       = flag.String("mindmap", "", "also write the page and heading hierarchy of the export, with notion URLs and edit times, to this .opml or .graphml file")
+	flagRedact        = flag.String("redact", "", "YAML file of redaction rules masking or removing matching text, properties and tagged pages (see export.RedactionConfig)")
+	flagRedactions    = flag.String("redactions", "", "write the audit list of the redactions made, as JSON, to this file")
+	flagBundle        = flag.String("bundle", "", "write the pages and their files into this single-file .notionpkg bundle instead")
+	flagTemplate      = flag.String("template", "", "glob of html/template files theming HTML output, defining the templates page, head, nav, breadcrumbs or footer (see export.HTMLPage)")
+)
+
+func main() {
+	flag.Parse()
+	if len(flag.Args()) != 1 {
+		flag.Usage()
+		fmt.Fprintln(os.Stderr, "please provide root page id or url as parameter")
+		os.Exit(1)
+	}
+	if err := run(flag.Args()[0]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(arg string) error {
+	id, err := notion.ParsePageURL(arg)
+	if err != nil {
+		return err
+	}
+	var opts []notion.ClientOption
+	if *flagVerbose {
+		opts = append(opts, notion.WithDebugLogging())
+	}
+	var c *notion.Client
+	if *flagPublic {
+		c, err = notion.NewPublicClient(opts...)
+	} else {
+		c, err = notion.NewClient(append(opts, notion.WithToken(os.Getenv("NOTION_TOKEN")))...)
+	}
+	if err != nil {
+		return err
+	}
+	if *flagTable != "" {
+		return exportTable(c, id)
+	}
+	filter, err := filterFromFlags()
+	if err != nil {
+		return err
+	}
+	if *flagBundle != "" {
+		return writeBundle(c, id, filter)
+	}
+	var renderer export.Renderer
+	switch *flagFormat {
+	case "markdown", "md":
+		keys, err := frontMatterKeys()
+		if err != nil {
+			return err
+		}
+		renderer = &export.Markdown{ColumnsAsHTML: *flagColumnsHTML, FrontMatter: *flagFrontMatter, FrontMatterKeys: keys}
+	case "html":
+		h := &export.HTML{}
+		switch {
+		case *flagDiagrams == "":
+		case *flagDiagrams == "local":
+			h.Diagrams = export.DefaultDiagramCommands
+		case *flagDiagrams == "kroki":
+			h.Diagrams = &export.Kroki{}
+		case strings.HasPrefix(*flagDiagrams, "http://"), strings.HasPrefix(*flagDiagrams, "https://"):
+			h.Diagrams = &export.Kroki{URL: *flagDiagrams}
+		default:
+			return fmt.Errorf("unknown diagram renderer %q", *flagDiagrams)
+		}
+		if *flagTemplate != "" {
+			if h.Template, err = export.NewHTMLTemplate().ParseGlob(*flagTemplate); err != nil {
+				return err
+			}
+		}
+		if *flagLocale != "" {
+			locale := *flagLocale
+			if locale == "root" {
+				locale = ""
+			}
+			if h.Collator, err = collation.New(locale); err != nil {
+				return err
+			}
+		}
+		renderer = h
+	case "obsidian":
+		keys, err := frontMatterKeys()
+		if err != nil {
+			return err
+		}
+		renderer = &export.Obsidian{FrontMatterKeys: keys}
+		if !flagSet("assets") {
+			*flagAssets = "download"
+		}
+	case "logseq", "roam":
+		renderer = &export.Outline{Roam: *flagFormat == "roam"}
+	case "anki":
+		renderer = &export.Anki{DeckProperty: *flagAnkiDeck, TagsProperty: *flagAnkiTags}
+	case "json":
+		renderer = &export.Widget{}
+	default:
+		return fmt.Errorf("unknown format %q", *flagFormat)
+	}
+	var assets export.AssetPolicy
+	switch *flagAssets {
+	case "hotlink":
+		assets = export.Hotlink{}
+	case "download":
+		d := &export.Download{}
+		if *flagFormat == "obsidian" {
+			d.Dir = export.ObsidianAttachments
+		}
+		if *flagImageWidths != "" {
+			resizer := &export.Resizer{}
+			for _, s := range splitList(*flagImageWidths) {
+				w, err := strconv.Atoi(s)
+				if err != nil {
+					return fmt.Errorf("invalid image width %q", s)
+				}
+				resizer.Widths = append(resizer.Widths, w)
+			}
+			d.Images = resizer
+		}
+		assets = d
+	case "upload":
+		if *flagUploadURL == "" {
+			return fmt.Errorf("-upload-url is required with -assets=upload")
+		}
+		assets = &export.Upload{Store: &export.HTTPBlobStore{BaseURL: *flagUploadURL, PublicURL: *flagPublicURL}}
+	default:
+		return fmt.Errorf("unknown asset policy %q", *flagAssets)
+	}
+	exportOpts := []export.Option{
+		export.WithFilter(filter),
+		export.WithRenderer(renderer),
+		export.WithAssetPolicy(assets),
+		export.WithLinkRewriter(&export.LinkRewriter{BaseURL: *flagBaseURL, Unlink: *flagUnlink}),
+	}
+	if *flagSyncedLinks {
+		exportOpts = append(exportOpts, export.WithSyncedBlockLinks())
+	}
+	if *flagIncremental {
+		m, err := export.ReadManifest(*flagOutput)
+		if err != nil {
+			return err
+		}
+		exportOpts = append(exportOpts, export.WithPreviousManifest(m))
+	}
+	if *flagHistory {
+		exportOpts = append(exportOpts, export.WithHistory())
+	}
+	if *flagDBTables {
+		exportOpts = append(exportOpts, export.WithDatabaseTables())
+	}
+	if *flagRedact != "" {
+		r, err := readRedactor(*flagRedact)
+		if err != nil {
+			return err
+		}
+		exportOpts = append(exportOpts, export.WithRedactor(r))
+	}
+	e := export.NewExporter(c, *flagOutput, exportOpts...)
+	if err := e.Export(id); err != nil {
+		return err
+	}
+	for _, l := range e.ExternalLinks() {
+		fmt.Fprintf(os.Stderr, "page %v links to %v outside of the export\n", l.Page, l.URL)
+	}
+	r := e.CrawlResult()
+	for _, b := range r.Skipped {
+		fmt.Fprintf(os.Stderr, "skipped %v (role %q) within %v\n", b.ID, b.Role, strings.Join(b.Ancestors, " > "))
+	}
+	if len(r.Skipped) > 0 || len(r.CommentOnly) > 0 {
+		fmt.Fprintln(os.Stderr, r)
+	}
+	if *flagMindMap != "" {
+		if err := writeMindMap(e); err != nil {
+			return err
+		}
+	}
+	if *flagRedactions != "" {
+		b, err := json.MarshalIndent(e.Redactions(), "", "  ")
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(*flagRedactions, append(b, '\n'), 0644)
+	}
+	return nil
+}
+
+func readRedactor(path string) (*export.Redactor, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var config export.RedactionConfig
+	if err := yaml.Unmarshal(b, &config); err != nil {
+		return nil, fmt.Errorf("reading redaction rules: %v", err)
+	}
+	return config.Compile()
+}
+
+// writeMindMap writes the structure of the export to the -mindmap file, as
+// OPML or GraphML depending on its extension.
+func writeMindMap(e *export.Exporter) error {
+	roots := e.MindMap()
+	buf := new(bytes.Buffer)
+	switch ext := strings.ToLower(filepath.Ext(*flagMindMap)); ext {
+	case ".opml":
+		title := "notion export"
+		if len(roots) > 0 {
+			title = roots[0].Title
+		}
+		if err := export.WriteOPML(buf, title, roots); err != nil {
+			return err
+		}
+	case ".graphml":
+		if err := export.WriteGraphML(buf, roots); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown mind map format %q, want .opml or .graphml", ext)
+	}
+	return ioutil.WriteFile(*flagMindMap, buf.Bytes(), 0644)
+}
+
+// writeBundle writes the page id and its sub-pages into the -bundle file.
+func writeBundle(c *notion.Client, id string, filter *notion.Filter) error {
+	f, err := os.Create(*flagBundle)
+	if err != nil {
+		return err
+	}
+	m, err := bundle.Write(c, id, f, bundle.Options{Filter: filter, SkipAssets: *flagSkipImages})
+	if err != nil {
+		f.Close()
+		return err
+	}
+	for _, id := range m.Skipped {
+		fmt.Fprintf(os.Stderr, "skipped %v\n", id)
+	}
+	return f.Close()
+}
+
+// exportTable exports the rows of the database id to the output directory.
+func exportTable(c *notion.Client, id string) error {
+	var tw export.TableWriter
+	switch *flagTable {
+	case "csv":
+		tw = &export.CSV{}
+	case "jsonl":
+		tw = &export.JSONL{}
+	case "sqlite":
+		tw = &export.SQLite{}
+	case "xlsx":
+		tw = &export.XLSX{}
+	case "anki":
+		tw = &export.Anki{DeckProperty: *flagAnkiDeck, TagsProperty: *flagAnkiTags, AnswerProperty: *flagAnkiAnswer}
+	default:
+		return fmt.Errorf("unknown table format %q", *flagTable)
+	}
+	db, collection, err := c.GetDatabase(id)
+	if err != nil {
+		return err
+	}
+	opts := export.CollectionOptions{Workers: *flagWorkers}
+	if len(db.ViewIDs) > 0 {
+		opts.ViewID = db.ViewIDs[0]
+	}
+	name := "database"
+	if len(collection.Name) > 0 && len(collection.Name[0]) > 0 {
+		name = export.Slug(collection.Name[0][0])
+	}
+	f, err := os.Create(filepath.Join(*flagOutput, name+tw.Ext()))
+	if err != nil {
+		return err
+	}
+	if err := export.ExportCollection(c, collection.ID, f, tw, opts); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func filterFromFlags() (*notion.Filter, error) {
+	f := &notion.Filter{
+		IncludeTypes: splitList(*flagIncludeTypes),
+		ExcludeTypes: splitList(*flagExcludeTypes),
+		Under:        splitList(*flagUnder),
+	}
+	if *flagSkipDatabases {
+		f.SkipDatabases()
+	}
+	if *flagSkipImages {
+		f.SkipImages()
+	}
+	if *flagExcludeTitle != "" {
+		re, err := regexp.Compile(*flagExcludeTitle)
+		if err != nil {
+			return nil, err
+		}
+		f.ExcludeTitle = re
+	}
+	return f, nil
+}
+
+// frontMatterKeys returns the property names to front-matter keys mapping
+// of -front-matter-keys.
+func frontMatterKeys() (map[string]string, error) {
+	if *flagFrontKeys == "" {
+		return nil, nil
+	}
+	keys := make(map[string]string)
+	for _, kv := range splitList(*flagFrontKeys) {
+		i := strings.Index(kv, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid front-matter key mapping %q", kv)
+		}
+		keys[kv[:i]] = kv[i+1:]
+	}
+	return keys, nil
+}
+
+// flagSet reports whether the flag name was given on the command line.
+func flagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+func splitList(s string) []string {
+	var res []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			res = append(res, v)
+		}
+	}
+	return res
+}
@@ -0,0 +1,124 @@
+// Command notion-hugo publishes a blog database as the posts of a Hugo or Jekyll site.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/collation"
+	"github.com/tmc/notion/publish"
+)
+
+var (
+	flagVerbose         = flag.Bool("v", false, "verbose")
+	flagDir             = flag.String("dir", ".", "root directory of the site")
+	flagJekyll          = flag.Bool("jekyll", false, "lay out posts for Jekyll instead of Hugo")
+	flagSection         = flag.String("section", "posts", "Hugo content section of posts")
+	flagSlugProperty    = flag.String("slug-property", "Slug", "name of the property holding post slugs")
+	flagStatusProperty  = flag.String("status-property", "Status", "name of the property holding post status")
+	flagPublishedStatus = flag.String("published", "Published", "status of published posts; posts with other statuses are drafts")
+	flagFrontKeys       = flag.String("front-matter-keys", "", "comma separated property=key pairs renaming front-matter keys; an empty key drops the property")
+	flagIndex           = flag.Bool("index", false, "write an index of all posts ordered by title to the site's data directory")
+	flagLocale          = flag.String("locale", "", "BCP 47 locale, e.g. de or sv, for which titles are ordered in the index; the default suits most languages")
+	flagForce           = flag.Bool("force", false, "rewrite all posts, not only those edited since the last run")
+	flagStatuses        = flag.String("statuses", "", "comma separated status=state pairs mapping statuses to draft, published or archived, instead of -published and Archived")
+	flagPublishDate     = flag.String("publish-date", "", "name of a date property holding post dates; published posts dated in the future stay drafts until then")
+	flagPublishedAt     = flag.String("published-at", "", "name of a date property set to the time posts are first published")
+	flagTZ              = flag.String("tz", "", "time zone of publish dates without one (defaults to UTC)")
+	flagDatabaseTables  = flag.Bool("database-tables", false, "render the databases embedded in posts as tables of the rows of their view")
+)
+
+func main() {
+	flag.Parse()
+	if len(flag.Args()) != 1 {
+		flag.Usage()
+		fmt.Fprintln(os.Stderr, "please provide database id as parameter")
+		os.Exit(1)
+	}
+	if err := run(flag.Args()[0]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(id string) error {
+	opts := []notion.ClientOption{
+		notion.WithToken(os.Getenv("NOTION_TOKEN")),
+	}
+	if *flagVerbose {
+		opts = append(opts, notion.WithDebugLogging())
+	}
+	c, err := notion.NewClient(opts...)
+	if err != nil {
+		return err
+	}
+	site := &publish.Site{
+		Client:          c,
+		Dir:             *flagDir,
+		Section:         *flagSection,
+		SlugProperty:    *flagSlugProperty,
+		StatusProperty:  *flagStatusProperty,
+		PublishedStatus: *flagPublishedStatus,
+		Force:           *flagForce,
+		DatabaseTables:  *flagDatabaseTables,
+
+		PublishDateProperty: *flagPublishDate,
+		PublishedAtProperty: *flagPublishedAt,
+	}
+	if *flagTZ != "" {
+		if site.Location, err = time.LoadLocation(*flagTZ); err != nil {
+			return err
+		}
+	}
+	if *flagStatuses != "" {
+		site.Statuses = make(map[string]publish.PostStatus)
+		for _, kv := range strings.Split(*flagStatuses, ",") {
+			i := strings.Index(kv, "=")
+			if i < 0 {
+				return fmt.Errorf("invalid status mapping %q", kv)
+			}
+			state, err := publish.ParseStatus(strings.TrimSpace(kv[i+1:]))
+			if err != nil {
+				return err
+			}
+			site.Statuses[strings.TrimSpace(kv[:i])] = state
+		}
+	}
+	if *flagJekyll {
+		site.Generator = publish.Jekyll
+	}
+	if *flagIndex {
+		if site.Index, err = collation.New(*flagLocale); err != nil {
+			return err
+		}
+	}
+	if *flagFrontKeys != "" {
+		site.FrontMatterKeys = make(map[string]string)
+		for _, kv := range strings.Split(*flagFrontKeys, ",") {
+			i := strings.Index(kv, "=")
+			if i < 0 {
+				return fmt.Errorf("invalid front-matter key mapping %q", kv)
+			}
+			site.FrontMatterKeys[strings.TrimSpace(kv[:i])] = strings.TrimSpace(kv[i+1:])
+		}
+	}
+	res, err := site.Publish(id)
+	if err != nil {
+		return err
+	}
+	for _, p := range res.Written {
+		fmt.Println("wrote", p)
+	}
+	for _, p := range res.Removed {
+		fmt.Println("removed", p)
+	}
+	for _, p := range res.Scheduled {
+		fmt.Println("scheduled", p)
+	}
+	fmt.Printf("%d written, %d removed, %d unchanged\n", len(res.Written), len(res.Removed), res.Unchanged)
+	return nil
+}
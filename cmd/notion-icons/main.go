@@ -0,0 +1,123 @@
+// Command notion-icons applies icon and cover conventions to the sub-pages
+// of a page, e.g. gives all pages under "Projects" the icon 📁. Pages that
+// already have an icon or cover keep it unless -overwrite is set.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/emoji"
+)
+
+var (
+	flagVerbose   = flag.Bool("v", false, "verbose")
+	flagIcon      = flag.String("icon", "", "icon to set: an emoji, a shortcode such as :file_folder: or the URL of an image")
+	flagIconFile  = flag.String("icon-file", "", "image file to upload and set as the icon")
+	flagCover     = flag.String("cover", "", "cover to set: the URL of an image or a notion gallery path such as /images/page-cover/gradients_11.jpg")
+	flagPosition  = flag.Float64("position", notion.DefaultCoverPosition, "vertical position of the cover, from 0 (top) to 1 (bottom)")
+	flagTitle     = flag.String("title", "", "only change pages with titles matching this regular expression")
+	flagRoot      = flag.Bool("root", false, "change the page itself too, not only its sub-pages")
+	flagOverwrite = flag.Bool("overwrite", false, "replace existing icons and covers")
+	flagDryRun    = flag.Bool("n", false, "print the pages that would change without changing them")
+)
+
+func main() {
+	flag.Parse()
+	if len(flag.Args()) != 1 {
+		flag.Usage()
+		fmt.Fprintln(os.Stderr, "please provide the id of the page whose sub-pages to change as parameter")
+		os.Exit(1)
+	}
+	if *flagIcon == "" && *flagIconFile == "" && *flagCover == "" {
+		fmt.Fprintln(os.Stderr, "please provide -icon, -icon-file or -cover")
+		os.Exit(1)
+	}
+	if err := run(flag.Args()[0]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(rootID string) error {
+	opts := []notion.ClientOption{
+		notion.WithToken(os.Getenv("NOTION_TOKEN")),
+	}
+	if *flagVerbose {
+		opts = append(opts, notion.WithDebugLogging())
+	}
+	c, err := notion.NewClient(opts...)
+	if err != nil {
+		return err
+	}
+	var title *regexp.Regexp
+	if *flagTitle != "" {
+		if title, err = regexp.Compile(*flagTitle); err != nil {
+			return err
+		}
+	}
+	icon := emoji.Icon(*flagIcon)
+	if *flagIconFile != "" {
+		if icon, err = upload(c, *flagIconFile); err != nil {
+			return err
+		}
+	}
+	n := 0
+	r, err := c.CrawlWithOptions(rootID, notion.CrawlOptions{}, func(p *notion.Page, ancestors []string) error {
+		if len(ancestors) == 0 && !*flagRoot || title != nil && !title.MatchString(p.Title) {
+			return nil
+		}
+		var currentIcon, currentCover string
+		if f := p.FormatPage; f != nil {
+			currentIcon, currentCover = f.PageIcon, f.PageCover
+		}
+		if icon != "" && icon != currentIcon && (currentIcon == "" || *flagOverwrite) {
+			n++
+			fmt.Printf("%v %q: icon %v\n", p.ID, p.Title, icon)
+			if !*flagDryRun {
+				if err := c.SetPageIcon(p.ID, icon); err != nil {
+					return err
+				}
+			}
+		}
+		if *flagCover != "" && *flagCover != currentCover && (currentCover == "" || *flagOverwrite) {
+			n++
+			fmt.Printf("%v %q: cover %v\n", p.ID, p.Title, *flagCover)
+			if !*flagDryRun {
+				if err := c.SetPageCover(p.ID, *flagCover, *flagPosition); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, b := range r.Skipped {
+		fmt.Fprintf(os.Stderr, "skipped %v (role %q) within %v\n", b.ID, b.Role, strings.Join(b.Ancestors, " > "))
+	}
+	fmt.Fprintf(os.Stderr, "%d changes\n", n)
+	return nil
+}
+
+// upload uploads the image file at path, unless -n is set, and returns
+// its URL.
+func upload(c *notion.Client, path string) (string, error) {
+	if *flagDryRun {
+		return path, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	name := filepath.Base(path)
+	return c.UploadFile(name, mime.TypeByExtension(filepath.Ext(name)), f)
+}
@@ -0,0 +1,202 @@
+// Command notion-import imports the rows of a CSV file or Excel workbook
+// into databases, either an existing one given with -db or new ones
+// created in a page, one per sheet of workbooks (see -sheet).
+// With -infer, the properties of a new database are typed from the values
+// of its columns, e.g. numbers, dates, checkboxes and selects, instead of
+// all being text. The first column is the title of rows.
+//
+// Given a .notionpkg bundle written by notion-export -bundle, it restores
+// its pages at the end of the page -parent instead, with new ids.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/bundle"
+	"github.com/tmc/notion/importer"
+	"github.com/tmc/notion/notiontypes"
+)
+
+var (
+	flagVerbose  = flag.Bool("v", false, "verbose")
+	flagDB       = flag.String("db", "", "id of an existing database to import rows into, instead of creating one")
+	flagParent   = flag.String("parent", "", "id of the page to create the database in")
+	flagName     = flag.String("name", "", "name of the new database, the file or sheet name by default")
+	flagSheet    = flag.String("sheet", "", "import only this sheet of an xlsx workbook")
+	flagInfer    = flag.Bool("infer", false, "infer the property types of the new database from the data")
+	flagSample   = flag.Int("sample", 0, "number of rows to infer types from, all if 0")
+	flagOptions  = flag.Int("max-options", 10, "most distinct values of columns inferred as selects")
+	flagFullPage = flag.Bool("full-page", false, "create the database as a sub-page instead of inline")
+)
+
+func main() {
+	flag.Parse()
+	if len(flag.Args()) != 1 {
+		flag.Usage()
+		fmt.Fprintln(os.Stderr, "please provide the csv or xlsx file to import as parameter")
+		os.Exit(1)
+	}
+	if (*flagDB == "") == (*flagParent == "") {
+		fmt.Fprintln(os.Stderr, "please provide either -db or -parent")
+		os.Exit(1)
+	}
+	if err := run(flag.Args()[0]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(path string) error {
+	if strings.HasSuffix(path, bundle.Ext) {
+		return restore(path)
+	}
+	tables, err := readTables(path)
+	if err != nil {
+		return err
+	}
+	if len(tables) == 0 {
+		return fmt.Errorf("%v has no rows to import", path)
+	}
+	if len(tables) > 1 && (*flagDB != "" || *flagName != "") {
+		return fmt.Errorf("%v has %d sheets, please choose one with -sheet", path, len(tables))
+	}
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	for _, table := range tables {
+		if err := importTable(c, table); err != nil {
+			return fmt.Errorf("%v: %v", table.Name, err)
+		}
+	}
+	return nil
+}
+
+func newClient() (*notion.Client, error) {
+	opts := []notion.ClientOption{
+		notion.WithToken(os.Getenv("NOTION_TOKEN")),
+	}
+	if *flagVerbose {
+		opts = append(opts, notion.WithDebugLogging())
+	}
+	return notion.NewClient(opts...)
+}
+
+// restore restores the pages of the bundle path under -parent.
+func restore(path string) error {
+	if *flagParent == "" {
+		return fmt.Errorf("please provide the page to restore %v in with -parent", path)
+	}
+	b, err := bundle.Open(path)
+	if err != nil {
+		return err
+	}
+	defer b.Close()
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	ids, err := b.Restore(c, *flagParent)
+	if err != nil {
+		return err
+	}
+	fmt.Println(ids[b.Manifest.RootID])
+	return nil
+}
+
+// readTables reads the tables of the csv or xlsx file path, named after
+// the file or their sheets.
+func readTables(path string) ([]*importer.SheetTable, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if !strings.EqualFold(filepath.Ext(path), ".xlsx") {
+		table, err := importer.ReadCSV(f)
+		if err != nil {
+			return nil, err
+		}
+		table.Name = name
+		return []*importer.SheetTable{table}, nil
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	tables, err := importer.ReadXLSX(f, fi.Size())
+	if err != nil || *flagSheet == "" {
+		return tables, err
+	}
+	for _, t := range tables {
+		if t.Name == *flagSheet {
+			return []*importer.SheetTable{t}, nil
+		}
+	}
+	return nil, fmt.Errorf("%v has no sheet %q", path, *flagSheet)
+}
+
+// importTable imports the rows of table into the database -db, or into a
+// new database named after the table.
+func importTable(c *notion.Client, table *importer.SheetTable) error {
+	collectionID := *flagDB
+	var schema []*notiontypes.CollectionColumnInfo
+	if collectionID != "" {
+		collection, err := c.GetCollection(collectionID)
+		if err != nil {
+			return err
+		}
+		if schema, err = existingSchema(table, collection); err != nil {
+			return err
+		}
+	} else {
+		schema = table.Schema()
+		if *flagInfer {
+			schema = table.InferSchema(importer.InferOptions{SampleSize: *flagSample, MaxOptions: *flagOptions})
+		}
+		name := *flagName
+		if name == "" {
+			name = table.Name
+		}
+		var err error
+		collectionID, err = c.CreateCollection(*flagParent, notion.Schema{Properties: schema, FullPage: *flagFullPage}, name)
+		if err != nil {
+			return err
+		}
+		if *flagVerbose {
+			for _, col := range schema {
+				fmt.Fprintf(os.Stderr, "%s: %s\n", col.Name, col.Type)
+			}
+		}
+	}
+	for i := range table.Rows {
+		if _, err := c.CreateRow(collectionID, table.RowValues(schema, i)); err != nil {
+			return fmt.Errorf("row %d: %v", i+1, err)
+		}
+	}
+	fmt.Printf("imported %d rows of %s into %s\n", len(table.Rows), table.Name, collectionID)
+	return nil
+}
+
+// existingSchema returns the properties of collection matching the header
+// of table by name, so that cells are converted as their properties expect.
+func existingSchema(table *importer.SheetTable, collection *notiontypes.Collection) ([]*notiontypes.CollectionColumnInfo, error) {
+	schema := make([]*notiontypes.CollectionColumnInfo, len(table.Header))
+	for i, name := range table.Header {
+		for _, col := range collection.CollectionSchema {
+			if strings.EqualFold(col.Name, name) {
+				schema[i] = col
+			}
+		}
+		if schema[i] == nil {
+			return nil, fmt.Errorf("database has no property %q", name)
+		}
+	}
+	return schema, nil
+}
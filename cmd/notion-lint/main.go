@@ -0,0 +1,106 @@
+// Command notion-lint checks pages and database rows against rules read from
+// a YAML file, such as rules.yaml in this directory, and prints the issues
+// found. It exits with status 1 if any issue has the severity error.
+//
+// Usage:
+//
+//	notion-lint [-rules rules.yaml] [-databases ids] [-format text|json] [<root page id>...]
+//
+// See package lint for the checks rules can use.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/lint"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	flagVerbose   = flag.Bool("v", false, "verbose")
+	flagRules     = flag.String("rules", "rules.yaml", "YAML file of rules")
+	flagDatabases = flag.String("databases", "", "comma separated list of database (collection) ids whose rows to check")
+	flagFormat    = flag.String("format", "text", "output format (text or json)")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: notion-lint [flags] [<root page id>...]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	r, err := run()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if r.Errors() > 0 {
+		os.Exit(1)
+	}
+}
+
+func run() (*lint.Report, error) {
+	var databases []string
+	if *flagDatabases != "" {
+		databases = strings.Split(*flagDatabases, ",")
+	}
+	if flag.NArg() == 0 && len(databases) == 0 {
+		return nil, fmt.Errorf("please provide root page ids as parameters or -databases")
+	}
+	rules, err := readRules(*flagRules)
+	if err != nil {
+		return nil, err
+	}
+	opts := []notion.ClientOption{
+		notion.WithToken(os.Getenv("NOTION_TOKEN")),
+	}
+	if *flagVerbose {
+		opts = append(opts, notion.WithDebugLogging())
+	}
+	c, err := notion.NewClient(opts...)
+	if err != nil {
+		return nil, err
+	}
+	r := &lint.Report{}
+	for _, id := range flag.Args() {
+		pages, err := lint.Pages(c, id, rules, nil)
+		if err != nil {
+			return nil, err
+		}
+		r.Merge(pages)
+	}
+	for _, id := range databases {
+		rows, err := lint.Database(c, strings.TrimSpace(id), rules, nil)
+		if err != nil {
+			return nil, err
+		}
+		r.Merge(rows)
+	}
+	switch *flagFormat {
+	case "text":
+		err = r.WriteText(os.Stdout)
+	case "json":
+		err = r.WriteJSON(os.Stdout)
+	default:
+		err = fmt.Errorf("unknown format %q", *flagFormat)
+	}
+	return r, err
+}
+
+func readRules(path string) ([]*lint.Rule, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &lint.Config{}
+	if err := yaml.Unmarshal(b, cfg); err != nil {
+		return nil, errors.Wrap(err, "reading rules")
+	}
+	return cfg.Compile()
+}
@@ -0,0 +1,122 @@
+// Command notion-merge merges two similar pages, e.g. accidentally
+// duplicated meeting notes, asking which version to keep of blocks that
+// differ. The merged page is created under -parent, or printed without it.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/diff"
+	"github.com/tmc/notion/merge"
+	"github.com/tmc/notion/notiontypes"
+)
+
+var (
+	flagVerbose   = flag.Bool("v", false, "verbose")
+	flagParent    = flag.String("parent", "", "id of the page to create the merged page under")
+	flagTitle     = flag.String("title", "", "title of the merged page (defaults to that of the first page)")
+	flagKeep      = flag.String("keep", "", "resolve all conflicts without asking: a, b or both")
+	flagThreshold = flag.Float64("threshold", merge.DefaultThreshold, "text similarity (0-1) from which blocks are considered versions of each other")
+)
+
+func main() {
+	flag.Parse()
+	if len(flag.Args()) != 2 {
+		flag.Usage()
+		fmt.Fprintln(os.Stderr, "please provide the ids of the two pages to merge as parameters")
+		os.Exit(1)
+	}
+	if err := run(flag.Args()[0], flag.Args()[1]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(aID, bID string) error {
+	opts := []notion.ClientOption{
+		notion.WithToken(os.Getenv("NOTION_TOKEN")),
+	}
+	if *flagVerbose {
+		opts = append(opts, notion.WithDebugLogging())
+	}
+	c, err := notion.NewClient(opts...)
+	if err != nil {
+		return err
+	}
+	a, err := c.GetBlock(aID)
+	if err != nil {
+		return err
+	}
+	b, err := c.GetBlock(bID)
+	if err != nil {
+		return err
+	}
+	r := merge.Merge(a, b, *flagThreshold)
+	in := bufio.NewReader(os.Stdin)
+	for i, conflict := range r.Conflicts {
+		if *flagKeep != "" {
+			if conflict.Resolution, err = parseResolution(*flagKeep); err != nil {
+				return err
+			}
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "conflict %d of %d:\n  a: %s\n  b: %s\n", i+1, len(r.Conflicts), diff.Text(conflict.A), diff.Text(conflict.B))
+		if conflict.Resolution, err = ask(in); err != nil {
+			return err
+		}
+	}
+	title := *flagTitle
+	if title == "" {
+		title = a.Title
+	}
+	blocks := r.Blocks()
+	if *flagParent == "" {
+		out, err := notion.PrintAsVim(&notiontypes.Block{Type: notiontypes.BlockPage, Title: title, Content: blocks}, "  ")
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(out)
+		return err
+	}
+	id, err := c.CreatePage(*flagParent, title, blocks...)
+	if err != nil {
+		return err
+	}
+	fmt.Println(id)
+	return nil
+}
+
+func ask(in *bufio.Reader) (merge.Resolution, error) {
+	for {
+		fmt.Fprint(os.Stderr, "keep [a], b or both? ")
+		line, err := in.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return 0, err
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			return merge.KeepA, nil
+		}
+		if r, err := parseResolution(line); err == nil {
+			return r, nil
+		}
+	}
+}
+
+func parseResolution(s string) (merge.Resolution, error) {
+	switch s {
+	case "a":
+		return merge.KeepA, nil
+	case "b":
+		return merge.KeepB, nil
+	case "both":
+		return merge.KeepBoth, nil
+	}
+	return 0, fmt.Errorf("invalid resolution %q, want a, b or both", s)
+}
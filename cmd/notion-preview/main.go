@@ -0,0 +1,107 @@
+// Command notion-preview serves the HTML export of a page and its
+// sub-pages, and updates the pages open in browsers as they're edited in
+// notion, e.g. to preview a theme while writing.
+//
+// Usage:
+//
+//	notion-preview [-addr :8080] [-interval 3s] [-template glob] <root page id or url>
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/export"
+	"github.com/tmc/notion/preview"
+	"github.com/tmc/notion/watch"
+)
+
+var (
+	flagVerbose  = flag.Bool("v", false, "verbose")
+	flagAddr     = flag.String("addr", "localhost:8080", "address to serve the preview on")
+	flagInterval = flag.Duration("interval", 3*time.Second, "how often to poll the pages for changes")
+	flagTemplate = flag.String("template", "", "glob of html/template files theming the pages, as with notion-export")
+)
+
+func main() {
+	flag.Parse()
+	if len(flag.Args()) != 1 {
+		flag.Usage()
+		fmt.Fprintln(os.Stderr, "please provide root page id or url as parameter")
+		os.Exit(1)
+	}
+	if err := run(flag.Arg(0)); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(arg string) error {
+	id, err := notion.ParsePageURL(arg)
+	if err != nil {
+		return err
+	}
+	opts := []notion.ClientOption{
+		notion.WithToken(os.Getenv("NOTION_TOKEN")),
+	}
+	if *flagVerbose {
+		opts = append(opts, notion.WithDebugLogging())
+	}
+	c, err := notion.NewClient(opts...)
+	if err != nil {
+		return err
+	}
+	h := &export.HTML{}
+	if *flagTemplate != "" {
+		if h.Template, err = export.NewHTMLTemplate().ParseGlob(*flagTemplate); err != nil {
+			return err
+		}
+	}
+	dir, err := ioutil.TempDir("", "notion-preview")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	s := preview.NewServer(c, id, dir, h)
+	if err := s.Export(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	srv := &http.Server{Addr: *flagAddr, Handler: s}
+	go func() {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt)
+		<-sig
+		cancel()
+		srv.Close()
+	}()
+	go func() {
+		w := watch.New(c, watch.WithPages(id), watch.WithInterval(*flagInterval))
+		// errors updating the preview shouldn't end it
+		sink := watch.SinkFunc(func(e *watch.Event) error {
+			if err := s.Send(e); err != nil {
+				log.Printf("updating the preview of %v: %v", e.PageID, err)
+			}
+			return nil
+		})
+		if err := w.Run(ctx, sink); err != context.Canceled {
+			log.Printf("watching pages: %v", err)
+			srv.Close()
+		}
+	}()
+	log.Printf("serving the preview on http://%v", *flagAddr)
+	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+// Command notion-reminders prints the dates due today that are mentioned in
+// a page and its sub-pages, or are properties of rows of databases on them.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/tmc/notion"
+)
+
+var (
+	flagVerbose   = flag.Bool("v", false, "verbose")
+	flagDays      = flag.Int("days", 1, "number of days, starting today, to print the reminders of")
+	flagReminders = flag.Bool("reminders-only", false, "only print dates with a reminder")
+	flagTZ        = flag.String("tz", "", "time zone of dates without one (defaults to the local time zone)")
+)
+
+func main() {
+	flag.Parse()
+	if len(flag.Args()) != 1 {
+		flag.Usage()
+		fmt.Fprintln(os.Stderr, "please provide root page id as parameter")
+		os.Exit(1)
+	}
+	if err := run(flag.Args()[0]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(id string) error {
+	opts := []notion.ClientOption{
+		notion.WithToken(os.Getenv("NOTION_TOKEN")),
+	}
+	if *flagVerbose {
+		opts = append(opts, notion.WithDebugLogging())
+	}
+	c, err := notion.NewClient(opts...)
+	if err != nil {
+		return err
+	}
+	loc := time.Local
+	if *flagTZ != "" {
+		if loc, err = time.LoadLocation(*flagTZ); err != nil {
+			return err
+		}
+	}
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	reminders, err := c.Reminders(id, notion.ReminderOptions{
+		From:          today,
+		To:            today.AddDate(0, 0, *flagDays),
+		OnlyReminders: *flagReminders,
+		Location:      loc,
+	})
+	if err != nil {
+		return err
+	}
+	for _, r := range reminders {
+		when := r.Due().In(loc).Format("Mon Jan 2 15:04")
+		if r.Property != "" {
+			fmt.Printf("%v  %v: %v\n", when, r.Property, r.Page)
+		} else {
+			fmt.Printf("%v  %v (%v)\n", when, r.Text, r.Page)
+		}
+	}
+	return nil
+}
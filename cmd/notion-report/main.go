@@ -0,0 +1,249 @@
+// Command notion-report generates reports about the pages of a workspace.
+//
+// Usage:
+//
+//	notion-report stale [-days n] [-format csv|markdown] <root page id>
+//	notion-report activity [-days n] [-format csv|json] <root page id>
+//	notion-report access [-format csv|markdown] <root page id>
+//	notion-report join [-join relations | -spec file] [-format csv|json] [<collection id>]
+//	notion-report orphans [-days n] [-candidates file] [-repair reattach|archive] [-format text|json] <root page id>
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/report"
+)
+
+var flagVerbose = flag.Bool("v", false, "verbose")
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: notion-report [-v] <stale|activity|access|join|orphans> [flags] <id>")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if len(flag.Args()) < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	var err error
+	switch cmd, args := flag.Arg(0), flag.Args()[1:]; cmd {
+	case "stale":
+		err = stale(args)
+	case "activity":
+		err = activity(args)
+	case "access":
+		err = access(args)
+	case "join":
+		err = join(args)
+	case "orphans":
+		err = orphans(args)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func newClient() (*notion.Client, error) {
+	opts := []notion.ClientOption{
+		notion.WithToken(os.Getenv("NOTION_TOKEN")),
+	}
+	if *flagVerbose {
+		opts = append(opts, notion.WithDebugLogging())
+	}
+	return notion.NewClient(opts...)
+}
+
+func stale(args []string) error {
+	fs := flag.NewFlagSet("stale", flag.ExitOnError)
+	days := fs.Int("days", 180, "report pages not edited for this many days")
+	format := fs.String("format", "markdown", "output format (csv or markdown)")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fmt.Errorf("please provide root page id as parameter")
+	}
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	r, err := report.Stale(c, fs.Arg(0), time.Duration(*days)*24*time.Hour, nil)
+	if err != nil {
+		return err
+	}
+	warnSkipped(r.Skipped)
+	switch *format {
+	case "csv":
+		return r.WriteCSV(os.Stdout)
+	case "markdown", "md":
+		return r.WriteMarkdown(os.Stdout)
+	}
+	return fmt.Errorf("unknown format %q", *format)
+}
+
+func activity(args []string) error {
+	fs := flag.NewFlagSet("activity", flag.ExitOnError)
+	days := fs.Int("days", 30, "report edits made within this many days")
+	format := fs.String("format", "json", "output format (csv or json)")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fmt.Errorf("please provide root page id as parameter")
+	}
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	to := time.Now()
+	r, err := report.Activity(c, fs.Arg(0), to.AddDate(0, 0, -*days), to)
+	if err != nil {
+		return err
+	}
+	switch *format {
+	case "csv":
+		return r.WriteCSV(os.Stdout)
+	case "json":
+		return r.WriteJSON(os.Stdout)
+	}
+	return fmt.Errorf("unknown format %q", *format)
+}
+
+func access(args []string) error {
+	fs := flag.NewFlagSet("access", flag.ExitOnError)
+	format := fs.String("format", "markdown", "output format (csv or markdown)")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fmt.Errorf("please provide root page id as parameter")
+	}
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	r, err := report.Access(c, fs.Arg(0), nil)
+	if err != nil {
+		return err
+	}
+	warnSkipped(r.Skipped)
+	switch *format {
+	case "csv":
+		return r.WriteCSV(os.Stdout)
+	case "markdown", "md":
+		return r.WriteMarkdown(os.Stdout)
+	}
+	return fmt.Errorf("unknown format %q", *format)
+}
+
+func join(args []string) error {
+	fs := flag.NewFlagSet("join", flag.ExitOnError)
+	relations := fs.String("join", "", "comma-separated relation properties of the collection to join the related rows of")
+	specFile := fs.String("spec", "", "JSON file describing the report, see report.JoinSpec")
+	format := fs.String("format", "csv", "output format (csv or json)")
+	fs.Parse(args)
+	spec := &report.JoinSpec{}
+	if *specFile != "" {
+		b, err := ioutil.ReadFile(*specFile)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(b, spec); err != nil {
+			return fmt.Errorf("reading %v: %v", *specFile, err)
+		}
+	}
+	if fs.NArg() == 1 {
+		spec.CollectionID = fs.Arg(0)
+	}
+	if spec.CollectionID == "" || fs.NArg() > 1 {
+		return fmt.Errorf("please provide the collection id as parameter or in the spec")
+	}
+	for _, rel := range strings.Split(*relations, ",") {
+		if rel = strings.TrimSpace(rel); rel != "" {
+			spec.Joins = append(spec.Joins, &report.Join{Relation: rel})
+		}
+	}
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	r, err := report.JoinRows(c, spec)
+	if err != nil {
+		return err
+	}
+	switch *format {
+	case "csv":
+		return r.WriteCSV(os.Stdout)
+	case "json":
+		return r.WriteJSON(os.Stdout)
+	}
+	return fmt.Errorf("unknown format %q", *format)
+}
+
+func orphans(args []string) error {
+	fs := flag.NewFlagSet("orphans", flag.ExitOnError)
+	days := fs.Int("days", 0, "only check blocks edited within this many days (0 checks the whole activity log)")
+	candidates := fs.String("candidates", "", "file of further block ids to check, one per line")
+	repair := fs.String("repair", "", "repair the orphans found (reattach or archive)")
+	format := fs.String("format", "text", "output format (text or json)")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fmt.Errorf("please provide root page id as parameter")
+	}
+	if *format != "text" && *format != "json" {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	opts := notion.OrphanOptions{}
+	if *days > 0 {
+		opts.Since = time.Now().AddDate(0, 0, -*days)
+	}
+	if *candidates != "" {
+		b, err := ioutil.ReadFile(*candidates)
+		if err != nil {
+			return err
+		}
+		opts.Candidates = strings.Fields(string(b))
+	}
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	found, err := c.FindOrphans(fs.Arg(0), opts)
+	if err != nil {
+		return err
+	}
+	if *format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(found); err != nil {
+			return err
+		}
+	} else {
+		for _, o := range found {
+			fmt.Printf("%s %s: parent %s (%s)\n", o.Block.Type, o.Block.ID, o.Block.ParentID, o.Reason)
+		}
+		fmt.Printf("%d orphaned blocks\n", len(found))
+	}
+	if *repair == "" || len(found) == 0 {
+		return nil
+	}
+	if err := c.RepairOrphans(found, *repair); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "repaired %d orphaned blocks (%s)\n", len(found), *repair)
+	return nil
+}
+
+// warnSkipped reports the pages and blocks a report couldn't read.
+func warnSkipped(skipped []*notion.RestrictedBlock) {
+	for _, b := range skipped {
+		fmt.Fprintf(os.Stderr, "skipped %v (role %q) within %v\n", b.ID, b.Role, strings.Join(b.Ancestors, " > "))
+	}
+}
@@ -0,0 +1,130 @@
+// Command notion-review runs a content approval workflow on a blog
+// database: rows whose status is set to "Needs review" get a comment
+// mentioning their reviewers, and approved rows, by status or checkbox, are
+// set to the published status and the database is published as the posts
+// of a Hugo or Jekyll site, as by notion-hugo.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/publish"
+	"github.com/tmc/notion/review"
+	"github.com/tmc/notion/watch"
+)
+
+var (
+	flagVerbose          = flag.Bool("v", false, "verbose")
+	flagDir              = flag.String("dir", ".", "root directory of the site")
+	flagJekyll           = flag.Bool("jekyll", false, "lay out posts for Jekyll instead of Hugo")
+	flagSection          = flag.String("section", "posts", "Hugo content section of posts")
+	flagSlugProperty     = flag.String("slug-property", "Slug", "name of the property holding post slugs")
+	flagStatusProperty   = flag.String("status-property", "Status", "name of the property holding post status")
+	flagReviewStatus     = flag.String("review", "Needs review", "status of posts needing review")
+	flagReviewerProperty = flag.String("reviewer-property", "Reviewer", "name of the person property holding reviewers")
+	flagMessage          = flag.String("message", review.DefaultMessage, "text of review comments")
+	flagApprovedStatus   = flag.String("approved", "Approved", "status of approved posts")
+	flagApprovedProperty = flag.String("approved-property", "", "name of a checkbox property approving posts")
+	flagPublishedStatus  = flag.String("published", "Published", "status approved posts are set to, and published with")
+	flagInterval         = flag.Duration("interval", watch.DefaultInterval, "polling interval")
+	flagState            = flag.String("state", ".notion-review.json", "file the watch state is saved to, so that changes made while stopped are handled")
+)
+
+func main() {
+	flag.Parse()
+	if len(flag.Args()) != 1 {
+		flag.Usage()
+		fmt.Fprintln(os.Stderr, "please provide database id as parameter")
+		os.Exit(1)
+	}
+	if err := run(flag.Args()[0]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(id string) error {
+	opts := []notion.ClientOption{
+		notion.WithToken(os.Getenv("NOTION_TOKEN")),
+	}
+	if *flagVerbose {
+		opts = append(opts, notion.WithDebugLogging())
+	}
+	c, err := notion.NewClient(opts...)
+	if err != nil {
+		return err
+	}
+	db, err := c.GetBlock(id)
+	if err != nil {
+		return err
+	}
+	if db.CollectionID == "" {
+		return fmt.Errorf("%v is not a database", id)
+	}
+	site := &publish.Site{
+		Client:          c,
+		Dir:             *flagDir,
+		Section:         *flagSection,
+		SlugProperty:    *flagSlugProperty,
+		StatusProperty:  *flagStatusProperty,
+		PublishedStatus: *flagPublishedStatus,
+	}
+	if *flagJekyll {
+		site.Generator = publish.Jekyll
+	}
+	workflow := &review.Workflow{
+		Client:           c,
+		StatusProperty:   *flagStatusProperty,
+		ReviewStatus:     *flagReviewStatus,
+		ReviewerProperty: *flagReviewerProperty,
+		Message:          *flagMessage,
+		ApprovedStatus:   *flagApprovedStatus,
+		ApprovedProperty: *flagApprovedProperty,
+		PublishStatus:    *flagPublishedStatus,
+		Approve: func(e *watch.Event) error {
+			log.Printf("%v approved", e.Title)
+			res, err := site.Publish(id)
+			if err != nil {
+				return err
+			}
+			for _, p := range res.Written {
+				log.Println("wrote", p)
+			}
+			for _, p := range res.Removed {
+				log.Println("removed", p)
+			}
+			return nil
+		},
+	}
+
+	watchOpts := []watch.Option{
+		watch.WithDatabases(db.CollectionID),
+		watch.WithInterval(*flagInterval),
+	}
+	if *flagState != "" {
+		watchOpts = append(watchOpts, watch.WithStore(watch.FileStore(*flagState)))
+	}
+	w := watch.New(c, watchOpts...)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt)
+		<-sig
+		cancel()
+	}()
+	// transient failures of comments or publishing are retried by sending
+	// the events of their poll again, other failures are logged by the
+	// workflow
+	if err := w.Run(ctx, workflow); err != context.Canceled {
+		return err
+	}
+	return nil
+}
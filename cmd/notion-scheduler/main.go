@@ -0,0 +1,240 @@
+// Command notion-scheduler runs a daemon that creates recurring pages, such
+// as meeting notes, by duplicating template pages on a schedule.
+//
+// The schedule is read from a JSON file:
+//
+//	{"jobs": [{
+//		"name": "standup",
+//		"schedule": "0 9 * * 1-5",
+//		"template": "<template page id>",
+//		"parent": "<parent page id>",
+//		"title": "Standup {{date}}",
+//		"vars": {"team": "Platform"}
+//	}]}
+//
+// Placeholders like {{date}} in the title and text of the template are
+// replaced with the values of vars, and of the variables date (2006-01-02),
+// time (15:04) and weekday of the time the page is due.
+//
+// The time each job last ran is recorded in a state file, so that pages are
+// not created twice after a restart. Pages that were due while the daemon
+// was stopped are created once when it starts.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/schedule"
+)
+
+var (
+	flagVerbose = flag.Bool("v", false, "verbose")
+	flagConfig  = flag.String("config", "schedule.json", "schedule file")
+	flagState   = flag.String("state", ".notion-scheduler.json", "state file")
+	flagRetry   = flag.Duration("retry", time.Minute, "delay before retrying a failed job")
+)
+
+type config struct {
+	Jobs []*job `json:"jobs"`
+}
+
+type job struct {
+	Name     string            `json:"name"`
+	Schedule string            `json:"schedule"`
+	Template string            `json:"template"`
+	Parent   string            `json:"parent"`
+	Title    string            `json:"title"`
+	Vars     map[string]string `json:"vars"`
+
+	cron    *schedule.Cron
+	retryAt time.Time
+}
+
+func main() {
+	flag.Parse()
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	cfg, err := readConfig(*flagConfig)
+	if err != nil {
+		return err
+	}
+	opts := []notion.ClientOption{
+		notion.WithToken(os.Getenv("NOTION_TOKEN")),
+	}
+	if *flagVerbose {
+		opts = append(opts, notion.WithDebugLogging())
+	}
+	c, err := notion.NewClient(opts...)
+	if err != nil {
+		return err
+	}
+	s := &scheduler{client: c, jobs: cfg.Jobs, start: time.Now(), state: make(map[string]time.Time)}
+	if err := s.loadState(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt)
+		<-sig
+		cancel()
+	}()
+	s.run(ctx)
+	return nil
+}
+
+func readConfig(path string) (*config, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &config{}
+	if err := json.Unmarshal(b, cfg); err != nil {
+		return nil, errors.Wrap(err, "reading schedule")
+	}
+	names := make(map[string]bool)
+	for _, j := range cfg.Jobs {
+		if j.Name == "" || names[j.Name] {
+			return nil, fmt.Errorf("jobs need a unique name, got %q", j.Name)
+		}
+		names[j.Name] = true
+		if j.Template == "" || j.Parent == "" {
+			return nil, fmt.Errorf("job %v: template and parent are required", j.Name)
+		}
+		if j.cron, err = schedule.Parse(j.Schedule); err != nil {
+			return nil, errors.Wrapf(err, "job %v", j.Name)
+		}
+	}
+	return cfg, nil
+}
+
+type scheduler struct {
+	client *notion.Client
+	jobs   []*job
+	start  time.Time
+	// the time each job last ran, by name
+	state map[string]time.Time
+}
+
+func (s *scheduler) run(ctx context.Context) {
+	for {
+		now := time.Now()
+		var wake time.Time
+		for _, j := range s.jobs {
+			last, ok := s.state[j.Name]
+			if !ok {
+				last = s.start
+			}
+			due := j.cron.Next(last)
+			if due.IsZero() {
+				continue
+			}
+			if due.After(now) {
+				wake = earliest(wake, due)
+				continue
+			}
+			if now.Before(j.retryAt) {
+				wake = earliest(wake, j.retryAt)
+				continue
+			}
+			if err := s.runJob(j, due); err != nil {
+				log.Printf("job %v due at %v: %v", j.Name, due.Format(time.RFC3339), err)
+				j.retryAt = now.Add(*flagRetry)
+				wake = earliest(wake, j.retryAt)
+				continue
+			}
+			// missed runs are not caught up on one by one
+			s.state[j.Name] = now
+			if err := s.saveState(); err != nil {
+				log.Printf("saving state: %v", err)
+			}
+			wake = earliest(wake, j.cron.Next(now))
+		}
+		if wake.IsZero() {
+			log.Println("no jobs are due anymore")
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Until(wake)):
+		}
+	}
+}
+
+func earliest(a, b time.Time) time.Time {
+	if a.IsZero() || b.Before(a) {
+		return b
+	}
+	return a
+}
+
+func (s *scheduler) runJob(j *job, due time.Time) error {
+	vars := map[string]string{
+		"date":    due.Format("2006-01-02"),
+		"time":    due.Format("15:04"),
+		"weekday": due.Weekday().String(),
+	}
+	for k, v := range j.Vars {
+		vars[k] = v
+	}
+	id, err := s.client.DuplicatePageWithOptions(j.Template, j.Parent, notion.DuplicateOptions{Title: j.Title, Vars: vars})
+	if err != nil {
+		return err
+	}
+	log.Printf("job %v: created page %v", j.Name, id)
+	return nil
+}
+
+func (s *scheduler) loadState() error {
+	b, err := ioutil.ReadFile(*flagState)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, &s.state); err != nil {
+		return errors.Wrap(err, "reading scheduler state")
+	}
+	return nil
+}
+
+// saveState writes the state through a temporary file, so that it's never
+// left half written.
+func (s *scheduler) saveState() error {
+	b, err := json.MarshalIndent(s.state, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(filepath.Dir(*flagState), ".notion-scheduler")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), *flagState)
+}
@@ -0,0 +1,234 @@
+// Command notion-serve serves the HTML export of a page and its sub-pages,
+// exported again as they're edited in notion.
+//
+// With -basic-auth or -oidc-issuer, pages are only served to the users of
+// a password file or of an OpenID Connect provider, e.g. on a company
+// intranet, and -allow restricts groups of users to some pages and their
+// sub-pages. The OIDC client secret is read from NOTION_OIDC_CLIENT_SECRET,
+// and sessions are signed with NOTION_SESSION_KEY, or a random key that
+// signs users out when the server restarts.
+//
+// With NOTION_SHARE_KEY set, pages are only served through share links
+// signed with that key, which expire, so that internal pages can be shared
+// outside the workspace for a while without making them public. Links are
+// printed by -share, with the same key:
+//
+//	notion-serve [-addr :8080] [-interval 1m] [-template glob] [-basic-auth file | -oidc-issuer url -oidc-client-id id] [-allow group=page,...] <root page id or url>
+//	notion-serve -share <page id or url> [-expires 72h] [-base-url https://example.com]
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/export"
+	"github.com/tmc/notion/serve"
+	"github.com/tmc/notion/watch"
+)
+
+var (
+	flagVerbose  = flag.Bool("v", false, "verbose")
+	flagAddr     = flag.String("addr", "localhost:8080", "address to serve the pages on")
+	flagInterval = flag.Duration("interval", watch.DefaultInterval, "how often to poll the pages for changes")
+	flagTemplate = flag.String("template", "", "glob of html/template files theming the pages, as with notion-export")
+	flagShare    = flag.String("share", "", "print a share link of this page, signed with NOTION_SHARE_KEY, instead of serving")
+	flagExpires  = flag.Duration("expires", 72*time.Hour, "with -share, how long the link is valid")
+	flagBaseURL  = flag.String("base-url", "http://localhost:8080", "URL the server is reached at, for share links and sign in redirects")
+)
+
+var (
+	flagBasicAuth    = flag.String("basic-auth", "", "file of user:password[:group,...] lines of the users allowed with basic authentication")
+	flagOIDCIssuer   = flag.String("oidc-issuer", "", "URL of an OpenID Connect provider signing users in")
+	flagOIDCClientID = flag.String("oidc-client-id", "", "client id of the server at the OpenID Connect provider")
+	flagOIDCRedirect = flag.String("oidc-redirect-url", "", "URL of "+serve.CallbackPath+" on the server, registered at the provider; defaults to -base-url")
+	flagOIDCGroups   = flag.String("oidc-groups-claim", "groups", "claim of ID tokens listing the groups of users")
+	flagAllow        = flag.String("allow", "", "comma separated group=page id pairs restricting signed in users to the pages allowed to their groups, and their sub-pages")
+)
+
+func main() {
+	flag.Parse()
+	var err error
+	switch {
+	case *flagShare != "":
+		err = share(*flagShare)
+	case len(flag.Args()) == 1:
+		err = run(flag.Arg(0))
+	default:
+		flag.Usage()
+		fmt.Fprintln(os.Stderr, "please provide root page id or url as parameter")
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func shares() *serve.Shares {
+	if key := os.Getenv("NOTION_SHARE_KEY"); key != "" {
+		return &serve.Shares{Key: []byte(key)}
+	}
+	return nil
+}
+
+func share(arg string) error {
+	s := shares()
+	if s == nil {
+		return fmt.Errorf("please set NOTION_SHARE_KEY to sign share links")
+	}
+	id, err := notion.ParsePageURL(arg)
+	if err != nil {
+		return err
+	}
+	link, err := s.URL(*flagBaseURL, id, time.Now().Add(*flagExpires))
+	if err != nil {
+		return err
+	}
+	fmt.Println(link)
+	return nil
+}
+
+// auth returns the authenticator of the flags, or nil.
+func auth() (serve.Authenticator, error) {
+	switch {
+	case *flagBasicAuth != "" && *flagOIDCIssuer != "":
+		return nil, fmt.Errorf("please provide either -basic-auth or -oidc-issuer")
+	case *flagBasicAuth != "":
+		b, err := ioutil.ReadFile(*flagBasicAuth)
+		if err != nil {
+			return nil, err
+		}
+		a := &serve.BasicAuth{Users: make(map[string]string), Groups: make(map[string][]string)}
+		for _, line := range strings.Split(string(b), "\n") {
+			if line = strings.TrimSpace(line); line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			fields := strings.SplitN(line, ":", 3)
+			if len(fields) < 2 {
+				return nil, fmt.Errorf("%v: invalid line %q", *flagBasicAuth, line)
+			}
+			a.Users[fields[0]] = fields[1]
+			if len(fields) == 3 {
+				for _, g := range strings.Split(fields[2], ",") {
+					if g = strings.TrimSpace(g); g != "" {
+						a.Groups[fields[0]] = append(a.Groups[fields[0]], g)
+					}
+				}
+			}
+		}
+		return a, nil
+	case *flagOIDCIssuer != "":
+		key := []byte(os.Getenv("NOTION_SESSION_KEY"))
+		if len(key) == 0 {
+			key = make([]byte, 32)
+			if _, err := rand.Read(key); err != nil {
+				return nil, err
+			}
+		}
+		redirect := *flagOIDCRedirect
+		if redirect == "" {
+			redirect = strings.TrimSuffix(*flagBaseURL, "/") + serve.CallbackPath
+		}
+		return &serve.OIDC{
+			Issuer:       *flagOIDCIssuer,
+			ClientID:     *flagOIDCClientID,
+			ClientSecret: os.Getenv("NOTION_OIDC_CLIENT_SECRET"),
+			RedirectURL:  redirect,
+			GroupsClaim:  *flagOIDCGroups,
+			Key:          key,
+		}, nil
+	}
+	return nil, nil
+}
+
+func run(arg string) error {
+	id, err := notion.ParsePageURL(arg)
+	if err != nil {
+		return err
+	}
+	opts := []notion.ClientOption{
+		notion.WithToken(os.Getenv("NOTION_TOKEN")),
+	}
+	if *flagVerbose {
+		opts = append(opts, notion.WithDebugLogging())
+	}
+	c, err := notion.NewClient(opts...)
+	if err != nil {
+		return err
+	}
+	h := &export.HTML{}
+	if *flagTemplate != "" {
+		if h.Template, err = export.NewHTMLTemplate().ParseGlob(*flagTemplate); err != nil {
+			return err
+		}
+	}
+	dir, err := ioutil.TempDir("", "notion-serve")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	s := serve.NewServer(c, id, dir, h)
+	s.Shares = shares()
+	if s.Auth, err = auth(); err != nil {
+		return err
+	}
+	if *flagAllow != "" {
+		s.Allow = make(map[string][]string)
+		for _, kv := range strings.Split(*flagAllow, ",") {
+			i := strings.Index(kv, "=")
+			if i < 0 {
+				return fmt.Errorf("invalid allowlist entry %q", kv)
+			}
+			page, err := notion.ParsePageURL(strings.TrimSpace(kv[i+1:]))
+			if err != nil {
+				return err
+			}
+			group := strings.TrimSpace(kv[:i])
+			s.Allow[group] = append(s.Allow[group], page)
+		}
+	}
+	if err := s.Export(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	srv := &http.Server{Addr: *flagAddr, Handler: s}
+	go func() {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt)
+		<-sig
+		cancel()
+		srv.Close()
+	}()
+	go func() {
+		w := watch.New(c, watch.WithPages(id), watch.WithInterval(*flagInterval))
+		// errors exporting the pages shouldn't stop serving them
+		sink := watch.SinkFunc(func(e *watch.Event) error {
+			if err := s.Send(e); err != nil {
+				log.Printf("exporting the pages after %v of %v: %v", e.Type, e.PageID, err)
+			}
+			return nil
+		})
+		// nor should errors watching them: the pages exported last are
+		// served until the server is stopped
+		if err := w.Run(ctx, sink); err != context.Canceled {
+			log.Printf("watching pages, no longer exporting them again: %v", err)
+		}
+	}()
+	log.Printf("serving the pages on http://%v", *flagAddr)
+	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+// Command notion-sql runs a read-only SQL SELECT statement over a notion
+// database and prints the resulting rows, e.g.
+//
+//	notion-sql -db tasks=<collection id> "SELECT Name, Points FROM tasks WHERE Status = 'Done' ORDER BY Points DESC"
+//
+// Columns are the properties of the database; see package sqlfront for the
+// supported SQL.
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/sqlfront"
+)
+
+var (
+	flagVerbose = flag.Bool("v", false, "verbose")
+	flagDB      = flag.String("db", "", "comma-separated names for databases used in FROM clauses, as name=collection-id")
+	flagFormat  = flag.String("format", "table", "output format: table, csv or json")
+)
+
+func main() {
+	flag.Parse()
+	if len(flag.Args()) != 1 {
+		flag.Usage()
+		fmt.Fprintln(os.Stderr, "please provide the SELECT statement to run as parameter")
+		os.Exit(1)
+	}
+	if err := run(flag.Args()[0]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(sql string) error {
+	opts := []notion.ClientOption{
+		notion.WithToken(os.Getenv("NOTION_TOKEN")),
+	}
+	if *flagVerbose {
+		opts = append(opts, notion.WithDebugLogging())
+	}
+	c, err := notion.NewClient(opts...)
+	if err != nil {
+		return err
+	}
+	db := &sqlfront.DB{Client: c, Collections: map[string]string{}}
+	for _, def := range strings.Split(*flagDB, ",") {
+		if def == "" {
+			continue
+		}
+		parts := strings.SplitN(def, "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("invalid -db %q, want name=collection-id", def)
+		}
+		db.Collections[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	res, err := db.Query(sql)
+	if err != nil {
+		return err
+	}
+	switch *flagFormat {
+	case "table":
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, strings.Join(res.Columns, "\t"))
+		for _, row := range res.Rows {
+			fmt.Fprintln(w, strings.Join(texts(row), "\t"))
+		}
+		return w.Flush()
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		w.Write(res.Columns)
+		for _, row := range res.Rows {
+			w.Write(texts(row))
+		}
+		w.Flush()
+		return w.Error()
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		for _, row := range res.Rows {
+			obj := make(map[string]interface{}, len(row))
+			for i, v := range row {
+				obj[res.Columns[i]] = v
+			}
+			if err := enc.Encode(obj); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown format %q", *flagFormat)
+}
+
+// texts returns the values of row as text, with NULL as an empty string.
+func texts(row []interface{}) []string {
+	s := make([]string, len(row))
+	for i, v := range row {
+		switch v := v.(type) {
+		case nil:
+		case float64:
+			s[i] = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			s[i] = fmt.Sprint(v)
+		}
+	}
+	return s
+}
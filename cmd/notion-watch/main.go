@@ -0,0 +1,129 @@
+// Command notion-watch polls pages and databases for changes and prints
+// them as JSON lines, POSTs them to webhooks, or publishes them to NATS or
+// Kafka (through a Kafka REST Proxy).
+//
+// Webhook requests are signed with the secret in NOTION_WEBHOOK_SECRET, if
+// set, as described in package webhook.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/eventbus"
+	"github.com/tmc/notion/watch"
+	"github.com/tmc/notion/webhook"
+)
+
+var (
+	flagVerbose   = flag.Bool("v", false, "verbose")
+	flagPages     = flag.String("pages", "", "comma separated list of page ids to watch with their sub-pages")
+	flagDatabases = flag.String("databases", "", "comma separated list of database (collection) ids whose rows to watch")
+	flagWebhooks  = flag.String("webhook", "", "comma separated list of URLs to POST events to instead of printing them")
+	flagFilter    = flag.String("filter", "", "only report events matching this query, e.g. 'type:row.property_changed property:Status'")
+	flagInterval  = flag.Duration("interval", watch.DefaultInterval, "polling interval")
+	flagQuiet     = flag.Duration("quiet", 0, "coalesce the changes of a page until it's unchanged for this long")
+	flagMaxWait   = flag.Duration("max-wait", 10*time.Minute, "with -quiet, report changes at most this long after a page started changing")
+	flagMaxEvents = flag.Int("max-events", 0, "with -quiet, report changes once this many are coalesced")
+	flagState     = flag.String("state", ".notion-watch.json", "file the watch state is saved to, so that changes made while stopped are reported; empty to start over on every run")
+	flagRetries   = flag.Int("retries", 3, "retries of failed webhook deliveries")
+	flagNATS      = flag.String("nats", "", "URL of a NATS server to publish events to, e.g. nats://localhost:4222")
+	flagSubject   = flag.String("nats-subject", eventbus.DefaultSubject, "subject prefix of NATS events")
+	flagKafka     = flag.String("kafka-rest", "", "URL of a Kafka REST Proxy to publish events with, e.g. http://localhost:8082")
+	flagTopic     = flag.String("kafka-topic", "notion-events", "Kafka topic of events")
+)
+
+func main() {
+	flag.Parse()
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	pages, databases := splitList(*flagPages), splitList(*flagDatabases)
+	if len(pages) == 0 && len(databases) == 0 {
+		return fmt.Errorf("please provide -pages or -databases to watch")
+	}
+	opts := []notion.ClientOption{
+		notion.WithToken(os.Getenv("NOTION_TOKEN")),
+	}
+	if *flagVerbose {
+		opts = append(opts, notion.WithDebugLogging())
+	}
+	c, err := notion.NewClient(opts...)
+	if err != nil {
+		return err
+	}
+	watchOpts := []watch.Option{
+		watch.WithPages(pages...),
+		watch.WithDatabases(databases...),
+		watch.WithInterval(*flagInterval),
+	}
+	if *flagFilter != "" {
+		q, err := watch.ParseQuery(*flagFilter)
+		if err != nil {
+			return err
+		}
+		watchOpts = append(watchOpts, watch.WithQuery(q))
+	}
+	if *flagQuiet > 0 {
+		watchOpts = append(watchOpts, watch.WithDebounce(watch.Debounce{Quiet: *flagQuiet, MaxWait: *flagMaxWait, MaxEvents: *flagMaxEvents}))
+	}
+	if *flagState != "" {
+		watchOpts = append(watchOpts, watch.WithStore(watch.FileStore(*flagState)))
+	}
+	w := watch.New(c, watchOpts...)
+
+	var sink watch.Sink
+	switch urls := splitList(*flagWebhooks); {
+	case len(urls) > 0:
+		o := &webhook.Outbound{URLs: urls, Retries: *flagRetries}
+		if s := os.Getenv("NOTION_WEBHOOK_SECRET"); s != "" {
+			o.Secret = []byte(s)
+		}
+		sink = o
+	case *flagNATS != "":
+		n := &eventbus.NATS{URL: *flagNATS, Subject: *flagSubject}
+		defer n.Close()
+		sink = n
+	case *flagKafka != "":
+		sink = &eventbus.Kafka{URL: *flagKafka, Topic: *flagTopic}
+	default:
+		enc := json.NewEncoder(os.Stdout)
+		sink = watch.SinkFunc(func(e *watch.Event) error { return enc.Encode(e) })
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt)
+		<-sig
+		cancel()
+	}()
+	// failed polls and deliveries are retried, and the state only saved
+	// once the sink took all events of a poll
+	if err := w.Run(ctx, sink); err != context.Canceled {
+		return err
+	}
+	return nil
+}
+
+func splitList(s string) []string {
+	var res []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			res = append(res, v)
+		}
+	}
+	return res
+}
@@ -0,0 +1,92 @@
+// Command notion-webhookd runs a server that creates or updates database
+// rows from the JSON payloads of webhooks, e.g. of Stripe, Typeform or
+// GitHub.
+//
+// Webhooks are mapped to databases by a JSON file:
+//
+//	{"hooks": [{
+//		"path": "/stripe",
+//		"database": "<collection id>",
+//		"key": "Payment",
+//		"token": "<shared secret>",
+//		"properties": {
+//			"Payment": "data.object.id",
+//			"Amount": "data.object.amount",
+//			"Customer": "data.object.billing_details.email"
+//		}
+//	}]}
+//
+// Payloads whose key property matches an existing row update that row.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/webhook"
+)
+
+var (
+	flagVerbose = flag.Bool("v", false, "verbose")
+	flagAddr    = flag.String("addr", "localhost:7434", "address to listen on")
+	flagConfig  = flag.String("config", "webhooks.json", "webhook mapping file")
+)
+
+type config struct {
+	Hooks []*webhook.Mapping `json:"hooks"`
+}
+
+func main() {
+	flag.Parse()
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	b, err := ioutil.ReadFile(*flagConfig)
+	if err != nil {
+		return err
+	}
+	cfg := &config{}
+	if err := json.Unmarshal(b, cfg); err != nil {
+		return errors.Wrap(err, "reading webhook mappings")
+	}
+	opts := []notion.ClientOption{
+		notion.WithToken(os.Getenv("NOTION_TOKEN")),
+	}
+	if *flagVerbose {
+		opts = append(opts, notion.WithDebugLogging())
+	}
+	c, err := notion.NewClient(opts...)
+	if err != nil {
+		return err
+	}
+	h, err := webhook.NewInbound(c, cfg.Hooks...)
+	if err != nil {
+		return err
+	}
+
+	srv := &http.Server{Addr: *flagAddr, Handler: h}
+	go func() {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt)
+		<-sig
+		srv.Shutdown(context.Background())
+	}()
+	log.Println("listening on", *flagAddr)
+	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
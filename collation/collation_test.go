@@ -0,0 +1,36 @@
+package collation
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+func TestCollator(t *testing.T) {
+	for _, tt := range []struct {
+		locale string
+		want   []string
+	}{
+		{"", []string{"Apfel", "Ärger", "Banane", "Zebra"}},
+		{"de", []string{"Apfel", "Ärger", "Banane", "Zebra"}},
+		{"sv", []string{"Apfel", "Banane", "Zebra", "Ärger"}},
+	} {
+		c, err := New(tt.locale)
+		if err != nil {
+			t.Fatal(err)
+		}
+		blocks := []*notiontypes.Block{{Title: "Zebra"}, {Title: "Ärger"}, {Title: "Banane"}, {Title: "Apfel"}}
+		c.Blocks(blocks)
+		var got []string
+		for _, b := range blocks {
+			got = append(got, b.Title)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("locale %q: got %q, want %q", tt.locale, got, tt.want)
+		}
+	}
+	if _, err := New("not a locale"); err == nil {
+		t.Error("invalid locale accepted")
+	}
+}
@@ -0,0 +1,301 @@
+package notion
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion/notiontypes"
+)
+
+type getCollectionValuesResponse struct {
+	Results []*notiontypes.CollectionWithRole `json:"results"`
+}
+
+// GetCollection returns the collection (database) with the given id.
+func (c *Client) GetCollection(collectionID string) (*notiontypes.Collection, error) {
+	b, err := c.post(getRecordValuesRequest{
+		Requests: []Record{{ID: collectionID, Table: notiontypes.TableCollection}},
+	}, "getRecordValues")
+	if err != nil {
+		return nil, err
+	}
+	r := &getCollectionValuesResponse{}
+	if err := json.Unmarshal(b, r); err != nil {
+		return nil, errors.Wrap(err, "unmarshaling getRecordValuesResponse")
+	}
+	if len(r.Results) == 0 || r.Results[0].Value == nil {
+		return nil, errors.Errorf("notion: collection %v not found", collectionID)
+	}
+	return r.Results[0].Value, nil
+}
+
+// queryCollectionLimit is the default number of rows loaded per request
+// by QueryCollection.
+const queryCollectionLimit = 1000
+
+type queryCollectionRequest struct {
+	CollectionID     string                       `json:"collectionId"`
+	CollectionViewID string                       `json:"collectionViewId"`
+	Query            *notiontypes.CollectionQuery `json:"query"`
+	Loader           queryCollectionLoader        `json:"loader"`
+}
+
+type queryCollectionLoader struct {
+	Type             string `json:"type"`
+	Limit            int    `json:"limit"`
+	Offset           int    `json:"offset,omitempty"`
+	LoadContentCover bool   `json:"loadContentCover"`
+	UserTimeZone     string `json:"userTimeZone"`
+}
+
+type queryCollectionResponse struct {
+	Result struct {
+		BlockIDs []string `json:"blockIds"`
+		Total    int      `json:"total"`
+	} `json:"result"`
+	RecordMap notiontypes.RecordMap `json:"recordMap"`
+}
+
+// QueryOptions controls how QueryCollectionWithOptions loads rows.
+type QueryOptions struct {
+	// Properties, if not empty, lists the names of the only properties
+	// kept in the rows besides the title, e.g. "Status" and "Date". The
+	// rows' content is not resolved either; use HydrateRows to load them
+	// in full.
+	Properties []string
+	// PageSize is the number of rows loaded per request, 1000 by default.
+	PageSize int
+	// Limit, if positive, is the maximum number of rows loaded.
+	Limit int
+	// Offset is the number of rows skipped, e.g. to load pages of rows
+	// concurrently.
+	Offset int
+	// Query, if not nil, filters and sorts the rows on notion's side
+	// instead of the view's filter and sort.
+	Query *notiontypes.CollectionQuery
+}
+
+// QueryCollection returns the rows of the collection collectionID in the
+// order of the view viewID. The rows are pages whose properties are resolved
+// but whose content is not loaded.
+func (c *Client) QueryCollection(collectionID, viewID string) ([]*notiontypes.Block, error) {
+	return c.QueryCollectionWithOptions(collectionID, viewID, QueryOptions{})
+}
+
+// QueryCollectionWithOptions is like QueryCollection, but loads the rows
+// according to opts. All rows are loaded, a page at a time; see
+// IterateCollection to process them as they're loaded.
+func (c *Client) QueryCollectionWithOptions(collectionID, viewID string, opts QueryOptions) ([]*notiontypes.Block, error) {
+	var rows []*notiontypes.Block
+	it := c.IterateCollection(collectionID, viewID, opts)
+	for it.Next() {
+		rows = append(rows, it.Row())
+	}
+	if it.Err() != nil {
+		return nil, it.Err()
+	}
+	if rows == nil {
+		rows = []*notiontypes.Block{}
+	}
+	return rows, nil
+}
+
+// CountRows returns the number of rows of the collection collectionID
+// shown by the view viewID, without loading them.
+func (c *Client) CountRows(collectionID, viewID string) (int, error) {
+	r, err := c.queryCollection(collectionID, viewID, nil, 0, 1)
+	if err != nil {
+		return 0, err
+	}
+	return r.Result.Total, nil
+}
+
+// RowIterator loads the rows of a collection a page at a time, as they're
+// consumed: the next page isn't requested before the rows of the previous
+// one have been read.
+type RowIterator struct {
+	c                    *Client
+	collectionID, viewID string
+	opts                 QueryOptions
+	keep                 map[string]bool
+
+	page   []*notiontypes.Block
+	row    *notiontypes.Block
+	offset int
+	read   int
+	total  int
+	done   bool
+	err    error
+}
+
+// IterateCollection returns an iterator over the rows of the collection
+// collectionID in the order of the view viewID, loaded according to opts.
+func (c *Client) IterateCollection(collectionID, viewID string, opts QueryOptions) *RowIterator {
+	if opts.PageSize <= 0 {
+		opts.PageSize = queryCollectionLimit
+	}
+	return &RowIterator{c: c, collectionID: collectionID, viewID: viewID, opts: opts, offset: opts.Offset, total: -1}
+}
+
+// Next advances to the next row, loading it if needed, and returns false
+// when there are no more rows or loading failed.
+func (it *RowIterator) Next() bool {
+	if it.err != nil || it.opts.Limit > 0 && it.read >= it.opts.Limit {
+		return false
+	}
+	for len(it.page) == 0 {
+		if it.done {
+			return false
+		}
+		if it.err = it.load(); it.err != nil {
+			return false
+		}
+	}
+	it.row, it.page = it.page[0], it.page[1:]
+	it.read++
+	return true
+}
+
+// Row returns the current row.
+func (it *RowIterator) Row() *notiontypes.Block {
+	return it.row
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *RowIterator) Err() error {
+	return it.err
+}
+
+// Total returns the number of rows of the collection shown by the view,
+// as reported by notion with the first page, or -1 before it's loaded.
+func (it *RowIterator) Total() int {
+	return it.total
+}
+
+// load loads the next page of rows.
+func (it *RowIterator) load() error {
+	limit := it.opts.PageSize
+	if it.opts.Limit > 0 && it.opts.Limit-it.read < limit {
+		limit = it.opts.Limit - it.read
+	}
+	r, err := it.c.queryCollection(it.collectionID, it.viewID, it.opts.Query, it.offset, limit)
+	if err != nil {
+		return err
+	}
+	it.total = r.Result.Total
+	it.offset += len(r.Result.BlockIDs)
+	it.done = len(r.Result.BlockIDs) < limit || it.offset >= it.total
+	if len(it.opts.Properties) > 0 && it.keep == nil {
+		collection, err := it.c.queryCollectionSchema(it.collectionID, r.RecordMap)
+		if err != nil {
+			return err
+		}
+		if it.keep, err = propertyIDs(collection, it.opts.Properties); err != nil {
+			return err
+		}
+	}
+	blocks := make(map[string]*notiontypes.Block, len(r.RecordMap.Blocks))
+	for k, v := range r.RecordMap.Blocks {
+		if v.Value != nil {
+			blocks[k] = v.Value
+		}
+	}
+	for _, id := range r.Result.BlockIDs {
+		row, ok := blocks[id]
+		if !ok {
+			continue
+		}
+		if it.keep != nil {
+			for k := range row.Properties {
+				if !it.keep[k] {
+					delete(row.Properties, k)
+				}
+			}
+			err = notiontypes.ParseBlock(row)
+		} else {
+			err = notiontypes.ResolveBlock(row, blocks)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "resolving row %v", id)
+		}
+		it.page = append(it.page, row)
+	}
+	return nil
+}
+
+// queryCollection loads limit rows of a collection from offset, filtered
+// and sorted by query if it's not nil.
+func (c *Client) queryCollection(collectionID, viewID string, query *notiontypes.CollectionQuery, offset, limit int) (*queryCollectionResponse, error) {
+	if query == nil {
+		query = &notiontypes.CollectionQuery{}
+	}
+	req := queryCollectionRequest{
+		CollectionID:     collectionID,
+		CollectionViewID: viewID,
+		Query:            query,
+		Loader: queryCollectionLoader{
+			Type:         "table",
+			Limit:        limit,
+			Offset:       offset,
+			UserTimeZone: "UTC",
+		},
+	}
+	b, err := c.post(req, "queryCollection")
+	if err != nil {
+		return nil, err
+	}
+	r := &queryCollectionResponse{}
+	if err := json.Unmarshal(b, r); err != nil {
+		return nil, errors.Wrap(err, "unmarshaling queryCollectionResponse")
+	}
+	return r, nil
+}
+
+// queryCollectionSchema returns the collection from the record map of a
+// query, or fetches it if it's missing.
+func (c *Client) queryCollectionSchema(collectionID string, rm notiontypes.RecordMap) (*notiontypes.Collection, error) {
+	if cr, ok := rm.Collections[collectionID]; ok && cr.Value != nil {
+		return cr.Value, nil
+	}
+	return c.GetCollection(collectionID)
+}
+
+// propertyIDs returns the ids of the properties of collection with the
+// given names, and of its title.
+func propertyIDs(collection *notiontypes.Collection, names []string) (map[string]bool, error) {
+	ids := map[string]bool{"title": true}
+	for _, name := range names {
+		found := false
+		for id, col := range collection.CollectionSchema {
+			if strings.EqualFold(col.Name, name) {
+				ids[id] = true
+				found = true
+			}
+		}
+		if !found {
+			return nil, errors.Errorf("notion: collection %v has no property %q", collection.ID, name)
+		}
+	}
+	return ids, nil
+}
+
+// HydrateRows replaces rows loaded with a property projection with their
+// full versions. Their content is left to Block.Children.
+func (c *Client) HydrateRows(rows ...*notiontypes.Block) error {
+	ids := make([]string, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+	}
+	full, err := c.GetBlocks(ids...)
+	if err != nil {
+		return err
+	}
+	for i, row := range rows {
+		if full[i] == nil {
+			return errors.Errorf("notion: row %v not found", row.ID)
+		}
+		*row = *full[i]
+	}
+	return nil
+}
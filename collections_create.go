@@ -0,0 +1,164 @@
+package notion
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// Schema describes a database created by CreateCollection.
+type Schema struct {
+	// Properties are the columns of the database, in order. A title
+	// property named Name is added first if none has the type
+	// notiontypes.ColumnTypeTitle. The options of select properties are
+	// given by Value, and their ids and colors are optional.
+	Properties []*notiontypes.CollectionColumnInfo
+	// FullPage creates the database as a sub-page of the parent page
+	// instead of inline in it.
+	FullPage bool
+}
+
+// CreateCollection creates the database name at the end of the page
+// parentPageID, with the given schema and a table view showing all its
+// properties, and returns the id of the database.
+func (c *Client) CreateCollection(parentPageID string, schema Schema, name string) (string, error) {
+	parentPageID, err := FormatID(parentPageID)
+	if err != nil {
+		return "", err
+	}
+	collection := &notiontypes.Collection{
+		Alive:       true,
+		Name:        [][]string{{name}},
+		ParentTable: notiontypes.TableBlock,
+		Version:     1,
+	}
+	var order []string
+	if collection.CollectionSchema, order, err = c.newSchema(schema.Properties); err != nil {
+		return "", err
+	}
+	block := &notiontypes.Block{Type: notiontypes.BlockCollectionView}
+	if schema.FullPage {
+		block.Type = notiontypes.BlockCollectionViewPage
+	}
+	now := c.clock.Now().UnixNano() / int64(time.Millisecond)
+	ops, err := c.createBlockOps(block, parentPageID, now)
+	if err != nil {
+		return "", err
+	}
+	if collection.ID, err = FormatID(c.newID()); err != nil {
+		return "", err
+	}
+	collection.ParentID = block.ID
+	view, err := newCollectionView(collection, notiontypes.ViewTable, ViewConfig{Properties: order})
+	if err != nil {
+		return "", err
+	}
+	if view.ID, err = FormatID(c.newID()); err != nil {
+		return "", err
+	}
+	args := ops[0].Args.(map[string]interface{})
+	args["collection_id"] = collection.ID
+	args["view_ids"] = []string{view.ID}
+	ops = append(ops, &operation{
+		ID:      collection.ID,
+		Table:   notiontypes.TableCollection,
+		Path:    []string{},
+		Command: "set",
+		Args:    collection,
+	}, &operation{
+		ID:      view.ID,
+		Table:   notiontypes.TableCollectionView,
+		Path:    []string{},
+		Command: "set",
+		Args:    view,
+	})
+	if err := c.submitTransaction(ops...); err != nil {
+		return "", err
+	}
+	return collection.ID, nil
+}
+
+// SetPropertyOptions replaces the options of the select or multi-select
+// property name of the database collectionID. Options without an id or a
+// color get new ids and the default color; rows keep the values of removed
+// options.
+func (c *Client) SetPropertyOptions(collectionID, name string, options []*notiontypes.CollectionColumnOption) error {
+	collection, err := c.GetCollection(collectionID)
+	if err != nil {
+		return err
+	}
+	id, col, err := schemaProperty(collection, name)
+	if err != nil {
+		return err
+	}
+	if col.Type != notiontypes.ColumnTypeSelect && col.Type != notiontypes.ColumnMultiSelect {
+		return errors.Errorf("notion: property %q isn't a select property", name)
+	}
+	var opts []*notiontypes.CollectionColumnOption
+	for _, o := range options {
+		opt := *o
+		if opt.ID == "" {
+			opt.ID = c.newID()
+		}
+		if opt.Color == "" {
+			opt.Color = "default"
+		}
+		opts = append(opts, &opt)
+	}
+	return c.submitTransaction(&operation{
+		ID:      collection.ID,
+		Table:   notiontypes.TableCollection,
+		Path:    []string{"schema", id, "options"},
+		Command: "set",
+		Args:    opts,
+	})
+}
+
+// newSchema returns the schema of a new collection with the given
+// properties, keyed by new property ids, and the ids in order.
+func (c *Client) newSchema(props []*notiontypes.CollectionColumnInfo) (map[string]*notiontypes.CollectionColumnInfo, []string, error) {
+	schema := map[string]*notiontypes.CollectionColumnInfo{}
+	names := map[string]bool{}
+	var order []string
+	for _, p := range props {
+		if p.Name == "" || p.Type == "" {
+			return nil, nil, errors.Errorf("notion: property %+v needs a name and a type", p)
+		}
+		if names[strings.ToLower(p.Name)] {
+			return nil, nil, errors.Errorf("notion: duplicate property %q", p.Name)
+		}
+		names[strings.ToLower(p.Name)] = true
+		id := "title"
+		if p.Type != notiontypes.ColumnTypeTitle {
+			// notion uses short random ids for the other properties
+			for id == "title" || schema[id] != nil {
+				id = strings.Replace(c.newID(), "-", "", -1)[:4]
+			}
+		} else if schema[id] != nil {
+			return nil, nil, errors.New("notion: a database has only one title property")
+		}
+		col := &notiontypes.CollectionColumnInfo{Name: p.Name, Type: p.Type}
+		for _, o := range p.Options {
+			opt := *o
+			if opt.ID == "" {
+				opt.ID = c.newID()
+			}
+			if opt.Color == "" {
+				opt.Color = "default"
+			}
+			col.Options = append(col.Options, &opt)
+		}
+		schema[id] = col
+		order = append(order, id)
+	}
+	if schema["title"] == nil {
+		if names["name"] {
+			return nil, nil, errors.New("notion: a property is named Name but isn't the title")
+		}
+		schema["title"] = &notiontypes.CollectionColumnInfo{Name: "Name", Type: notiontypes.ColumnTypeTitle}
+		order = append([]string{"title"}, order...)
+	}
+	return schema, order, nil
+}
@@ -0,0 +1,208 @@
+package notion_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/notiontest"
+	"github.com/tmc/notion/notiontypes"
+)
+
+func TestQueryCollectionProjection(t *testing.T) {
+	s := notiontest.NewServer()
+	defer s.Close()
+	s.AddRecord(notiontypes.TableCollection, "db", map[string]interface{}{
+		"schema": map[string]interface{}{
+			"title": map[string]string{"name": "Name", "type": "title"},
+			"st":    map[string]string{"name": "Status", "type": "select"},
+			"bd":    map[string]string{"name": "Body", "type": "text"},
+		},
+	})
+	s.AddBlock(&notiontypes.Block{
+		ID:          "row",
+		Type:        notiontypes.BlockPage,
+		ParentID:    "db",
+		ParentTable: notiontypes.TableCollection,
+		Properties: map[string]interface{}{
+			"title": [][]string{{"Row"}},
+			"st":    [][]string{{"Done"}},
+			"bd":    [][]string{{"a lot of text"}},
+		},
+		Content: []*notiontypes.Block{{ID: "text", Type: notiontypes.BlockText}},
+	})
+	c := s.Client()
+
+	rows, err := c.QueryCollectionWithOptions("db", "view", notion.QueryOptions{Properties: []string{"status"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("got %d rows, want 1", len(rows))
+	}
+	row := rows[0]
+	if row.Title != "Row" || row.Properties["st"] == nil || row.Properties["bd"] != nil {
+		t.Errorf("unexpected properties %v", row.Properties)
+	}
+	if err := c.HydrateRows(row); err != nil {
+		t.Fatal(err)
+	}
+	if row.Properties["bd"] == nil || len(row.ContentIDs) != 1 {
+		t.Errorf("row not hydrated: %v", row.Properties)
+	}
+
+	if _, err := c.QueryCollectionWithOptions("db", "view", notion.QueryOptions{Properties: []string{"Missing"}}); err == nil {
+		t.Error("expected error for unknown property")
+	}
+}
+
+func TestCreateCollection(t *testing.T) {
+	s := notiontest.NewServer()
+	defer s.Close()
+	const pageID = "4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+	s.AddBlock(&notiontypes.Block{ID: pageID, Type: notiontypes.BlockPage})
+	c := s.Client()
+
+	id, err := c.CreateCollection(pageID, notion.Schema{
+		Properties: []*notiontypes.CollectionColumnInfo{
+			{Name: "Status", Type: notiontypes.ColumnTypeSelect, Options: []*notiontypes.CollectionColumnOption{{Value: "Done"}}},
+			{Name: "Due", Type: notiontypes.ColumnTypeDate},
+		},
+		FullPage: true,
+	}, "Tasks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	collection, err := c.GetCollection(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if collection.Name[0][0] != "Tasks" || len(collection.CollectionSchema) != 3 || collection.CollectionSchema["title"].Name != "Name" {
+		t.Errorf("got collection %+v", collection)
+	}
+	page := s.Block(pageID)
+	if len(page.ContentIDs) != 1 || page.ContentIDs[0] != collection.ParentID {
+		t.Fatalf("got page content %v", page.ContentIDs)
+	}
+	block := s.Block(collection.ParentID)
+	if block.Type != notiontypes.BlockCollectionViewPage || block.CollectionID != id || len(block.ViewIDs) != 1 {
+		t.Errorf("got block %+v", block)
+	}
+	view := s.Record(notiontypes.TableCollectionView, block.ViewIDs[0])
+	props, _ := view["format"].(map[string]interface{})["table_properties"].([]interface{})
+	if view["type"] != notiontypes.ViewTable || len(props) != 3 || props[0].(map[string]interface{})["property"] != "title" {
+		t.Errorf("got view %v", view)
+	}
+	if _, err := c.CreateRow(id, map[string]string{"Name": "Ship it", "Status": "Done", "Due": "2024-05-01"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, props := range [][]*notiontypes.CollectionColumnInfo{
+		{{Name: "Name", Type: notiontypes.ColumnTypeText}},
+		{{Name: "A", Type: notiontypes.ColumnTypeText}, {Name: "a", Type: notiontypes.ColumnTypeNumber}},
+		{{Name: "A", Type: notiontypes.ColumnTypeTitle}, {Name: "B", Type: notiontypes.ColumnTypeTitle}},
+	} {
+		if _, err := c.CreateCollection(pageID, notion.Schema{Properties: props}, "Bad"); err == nil {
+			t.Errorf("expected error for schema %v", props)
+		}
+	}
+}
+
+func TestQueryCollectionPages(t *testing.T) {
+	s := notiontest.NewServer()
+	defer s.Close()
+	s.AddRecord(notiontypes.TableCollection, "db", map[string]interface{}{
+		"schema": map[string]interface{}{"title": map[string]string{"name": "Name", "type": "title"}},
+	})
+	addRows := func(from, to int) {
+		for i := from; i < to; i++ {
+			s.AddBlock(&notiontypes.Block{
+				ID:          fmt.Sprintf("row%02d", i),
+				Type:        notiontypes.BlockPage,
+				ParentID:    "db",
+				ParentTable: notiontypes.TableCollection,
+				CreatedTime: int64(i),
+			})
+		}
+	}
+	addRows(0, 25)
+	c := s.Client()
+
+	rows, err := c.QueryCollectionWithOptions("db", "view", notion.QueryOptions{PageSize: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 25 || rows[24].ID != "row24" {
+		t.Errorf("got %d rows, want 25", len(rows))
+	}
+	if rows, _ = c.QueryCollectionWithOptions("db", "view", notion.QueryOptions{PageSize: 10, Limit: 12}); len(rows) != 12 {
+		t.Errorf("got %d rows, want 12", len(rows))
+	}
+	if n, err := c.CountRows("db", "view"); n != 25 || err != nil {
+		t.Errorf("got %d rows, %v", n, err)
+	}
+
+	it := c.IterateCollection("db", "view", notion.QueryOptions{PageSize: 10})
+	if it.Total() != -1 {
+		t.Errorf("got total %d before loading", it.Total())
+	}
+	n := 0
+	for it.Next() {
+		if n == 0 && it.Total() != 25 {
+			t.Errorf("got total %d, want 25", it.Total())
+		}
+		n++
+		if n == 10 {
+			// the next page is only loaded now
+			addRows(25, 30)
+		}
+	}
+	if it.Err() != nil || n != 30 {
+		t.Errorf("iterated over %d rows, error %v", n, it.Err())
+	}
+}
+
+func TestForEachRow(t *testing.T) {
+	s := notiontest.NewServer()
+	defer s.Close()
+	s.AddRecord(notiontypes.TableCollection, "db", map[string]interface{}{
+		"schema": map[string]interface{}{
+			"title": map[string]string{"name": "Name", "type": "title"},
+			"pt":    map[string]string{"name": "Points", "type": "number"},
+		},
+	})
+	for i := 0; i < 7; i++ {
+		s.AddBlock(&notiontypes.Block{
+			ID:          fmt.Sprintf("row%d", i),
+			Type:        notiontypes.BlockPage,
+			ParentID:    "db",
+			ParentTable: notiontypes.TableCollection,
+			CreatedTime: int64(i),
+			Properties:  map[string]interface{}{"pt": [][]string{{fmt.Sprint(i)}}},
+		})
+	}
+	c := s.Client()
+
+	var got []string
+	err := c.ForEachRow("db", notion.RowQuery{QueryOptions: notion.QueryOptions{PageSize: 3}}, func(r notion.Row) error {
+		got = append(got, r.Property("points").Text())
+		return nil
+	})
+	if err != nil || strings.Join(got, "") != "0123456" {
+		t.Errorf("got %v, %v", got, err)
+	}
+
+	stop := errors.New("stop")
+	n := 0
+	err = c.ForEachRow("db", notion.RowQuery{}, func(r notion.Row) error {
+		if n++; n == 2 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop || n != 2 {
+		t.Errorf("got %v after %d rows, want stop after 2", err, n)
+	}
+}
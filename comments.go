@@ -0,0 +1,65 @@
+package notion
+
+import (
+	"time"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+// AddComment starts a discussion on the page or block blockID with a
+// comment of text, preceded by mentions of the users userIDs so that they
+// are notified, and returns the id of the discussion.
+func (c *Client) AddComment(blockID, text string, userIDs ...string) (string, error) {
+	blockID, err := FormatID(blockID)
+	if err != nil {
+		return "", err
+	}
+	discussionID, err := FormatID(c.newID())
+	if err != nil {
+		return "", err
+	}
+	commentID, err := FormatID(c.newID())
+	if err != nil {
+		return "", err
+	}
+	var runs []*notiontypes.InlineBlock
+	for _, id := range userIDs {
+		runs = append(runs, &notiontypes.InlineBlock{Text: "‣", UserID: id}, &notiontypes.InlineBlock{Text: " "})
+	}
+	runs = append(runs, &notiontypes.InlineBlock{Text: text})
+	now := c.clock.Now().UnixNano() / int64(time.Millisecond)
+	return discussionID, c.submitTransaction(&operation{
+		ID:      discussionID,
+		Table:   notiontypes.TableDiscussion,
+		Path:    []string{},
+		Command: "set",
+		Args: map[string]interface{}{
+			"id":           discussionID,
+			"parent_id":    blockID,
+			"parent_table": notiontypes.TableBlock,
+			"resolved":     false,
+			"comments":     []string{commentID},
+			"alive":        true,
+		},
+	}, &operation{
+		ID:      commentID,
+		Table:   notiontypes.TableComment,
+		Path:    []string{},
+		Command: "set",
+		Args: map[string]interface{}{
+			"id":               commentID,
+			"parent_id":        discussionID,
+			"parent_table":     notiontypes.TableDiscussion,
+			"text":             notiontypes.EncodeInlineBlocks(runs),
+			"alive":            true,
+			"created_time":     now,
+			"last_edited_time": now,
+		},
+	}, &operation{
+		ID:      blockID,
+		Table:   notiontypes.TableBlock,
+		Path:    []string{"discussion"},
+		Command: "listAfter",
+		Args:    map[string]string{"id": discussionID},
+	})
+}
@@ -0,0 +1,48 @@
+package notion_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/notiontest"
+	"github.com/tmc/notion/notiontypes"
+)
+
+func TestAddComment(t *testing.T) {
+	s := notiontest.NewServer()
+	defer s.Close()
+	const (
+		pageID = "4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+		userID = "5b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+	)
+	s.AddBlock(&notiontypes.Block{ID: pageID, Type: notiontypes.BlockPage})
+	c := s.Client(notion.WithClock(fixedClock(time.Unix(1551398400, 0))))
+	id, err := c.AddComment(pageID, "please review", userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Block(pageID).DiscussionIDs; !reflect.DeepEqual(got, []string{id}) {
+		t.Fatalf("got discussions %v, want [%v]", got, id)
+	}
+	discussion := s.Record(notiontypes.TableDiscussion, id)
+	if discussion["parent_id"] != pageID {
+		t.Errorf("got discussion %v", discussion)
+	}
+	comments, _ := discussion["comments"].([]interface{})
+	if len(comments) != 1 {
+		t.Fatalf("got comments %v", discussion["comments"])
+	}
+	comment := s.Record(notiontypes.TableComment, comments[0].(string))
+	text, err := notiontypes.ParseInlineBlocks(comment["text"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(text) != 3 || text[0].UserID != userID || text[2].Text != "please review" {
+		t.Errorf("got comment text %#v", comment["text"])
+	}
+	if comment["parent_id"] != id || comment["created_time"] != float64(1551398400000) {
+		t.Errorf("got comment %v", comment)
+	}
+}
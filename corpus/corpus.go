@@ -0,0 +1,101 @@
+// Package corpus provides recordMap fixtures of notion pages, covering every
+// supported block type, together with the golden output of the exporters.
+// Renderers can use it to check their output against real notion data.
+package corpus
+
+import (
+	"embed"
+	"encoding/json"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion/notiontypes"
+)
+
+//go:embed testdata
+var files embed.FS
+
+// Case is a fixture of the corpus.
+type Case struct {
+	// Name identifies the case, e.g. "text".
+	Name string
+	// PageID is the id of the page within RecordMap.
+	PageID string
+	// RecordMap holds the raw JSON of the loadPageChunk record map of the page.
+	RecordMap json.RawMessage
+}
+
+type fixture struct {
+	PageID    string          `json:"pageId"`
+	RecordMap json.RawMessage `json:"recordMap"`
+}
+
+// Cases returns the cases of the corpus, sorted by name.
+func Cases() ([]*Case, error) {
+	entries, err := files.ReadDir("testdata")
+	if err != nil {
+		return nil, err
+	}
+	var cases []*Case
+	for _, e := range entries {
+		// golden files of JSON renderers share the extension
+		if path.Ext(e.Name()) != ".json" || strings.Contains(e.Name(), ".golden.") {
+			continue
+		}
+		c, err := Load(strings.TrimSuffix(e.Name(), ".json"))
+		if err != nil {
+			return nil, err
+		}
+		cases = append(cases, c)
+	}
+	sort.Slice(cases, func(i, j int) bool { return cases[i].Name < cases[j].Name })
+	return cases, nil
+}
+
+// Load returns the case with the given name.
+func Load(name string) (*Case, error) {
+	b, err := files.ReadFile(path.Join("testdata", name+".json"))
+	if err != nil {
+		return nil, err
+	}
+	var f fixture
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil, errors.Wrapf(err, "unmarshaling fixture %v", name)
+	}
+	return &Case{Name: name, PageID: f.PageID, RecordMap: f.RecordMap}, nil
+}
+
+// Page parses the record map and returns the resolved page block. Every call
+// returns a fresh copy, so callers are free to modify it.
+func (c *Case) Page() (*notiontypes.Block, error) {
+	var rm notiontypes.RecordMap
+	if err := json.Unmarshal(c.RecordMap, &rm); err != nil {
+		return nil, errors.Wrapf(err, "unmarshaling record map of %v", c.Name)
+	}
+	blocks := make(map[string]*notiontypes.Block, len(rm.Blocks))
+	for id, b := range rm.Blocks {
+		blocks[id] = b.Value
+	}
+	page, ok := blocks[c.PageID]
+	if !ok {
+		return nil, errors.Errorf("corpus: page %v missing from record map of %v", c.PageID, c.Name)
+	}
+	if err := notiontypes.ResolveBlock(page, blocks); err != nil {
+		return nil, errors.Wrapf(err, "resolving page of %v", c.Name)
+	}
+	return page, nil
+}
+
+// Golden returns the expected output of the exporter with the renderer for
+// the file extension ext, e.g. ".md" or ".html".
+func (c *Case) Golden(ext string) ([]byte, error) {
+	return files.ReadFile(GoldenPath("testdata", c.Name, ext))
+}
+
+// GoldenPath returns the path of the golden file of the case name within dir,
+// for tools that update the golden files.
+func GoldenPath(dir, name, ext string) string {
+	return path.Join(dir, name+".golden"+ext)
+}
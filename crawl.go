@@ -0,0 +1,262 @@
+package notion
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// Filter selects the pages and blocks visited by Crawl.
+//
+// The zero value (and a nil *Filter) selects everything.
+type Filter struct {
+	// IncludeTypes, if non-empty, lists the only block types kept in crawled pages.
+	IncludeTypes []string
+	// ExcludeTypes lists block types removed from crawled pages.
+	ExcludeTypes []string
+	// Under, if non-empty, restricts the pages passed to the CrawlFunc to
+	// those within the subtree of one of the given page ids.
+	Under []string
+	// ExcludeTitle skips pages (and their sub-pages) with a matching title.
+	ExcludeTitle *regexp.Regexp
+}
+
+// SkipDatabases excludes databases (collection views) from f.
+func (f *Filter) SkipDatabases() {
+	f.ExcludeTypes = append(f.ExcludeTypes, notiontypes.BlockCollectionView, notiontypes.BlockCollectionViewPage)
+}
+
+// SkipImages excludes image blocks from f.
+func (f *Filter) SkipImages() {
+	f.ExcludeTypes = append(f.ExcludeTypes, notiontypes.BlockImage)
+}
+
+// KeepBlock reports whether the block b is selected by f.
+func (f *Filter) KeepBlock(b *notiontypes.Block) bool {
+	if f == nil {
+		return true
+	}
+	if len(f.IncludeTypes) > 0 && !contains(f.IncludeTypes, b.Type) {
+		return false
+	}
+	if contains(f.ExcludeTypes, b.Type) {
+		return false
+	}
+	if b.IsPage() && f.ExcludeTitle != nil && f.ExcludeTitle.MatchString(b.Title) {
+		return false
+	}
+	return true
+}
+
+// InScope reports whether the page with the given id and ancestors is within f.Under.
+func (f *Filter) InScope(id string, ancestors []string) bool {
+	if f == nil || len(f.Under) == 0 {
+		return true
+	}
+	if contains(f.Under, id) {
+		return true
+	}
+	for _, a := range ancestors {
+		if contains(f.Under, a) {
+			return true
+		}
+	}
+	return false
+}
+
+// Prune removes the content of b (recursively) that is not selected by f.
+// It sets new slices, as those of b may be shared, e.g. with synced block
+// copies.
+func (f *Filter) Prune(b *notiontypes.Block) {
+	if f == nil || len(b.Content) == 0 {
+		return
+	}
+	content := make([]*notiontypes.Block, 0, len(b.Content))
+	ids := make([]string, 0, len(b.Content))
+	for _, child := range b.Content {
+		if !f.KeepBlock(child) {
+			continue
+		}
+		if !child.IsPage() {
+			f.Prune(child)
+		}
+		content = append(content, child)
+		ids = append(ids, child.ID)
+	}
+	b.Content = content
+	b.ContentIDs = ids
+}
+
+// CrawlFunc is called for every page visited by Crawl.
+// ancestors holds the ids of the page's ancestors, starting with the crawl root.
+type CrawlFunc func(page *Page, ancestors []string) error
+
+// CrawlOptions configures CrawlWithOptions.
+type CrawlOptions struct {
+	Filter *Filter
+	// Strict fails on pages the user can't read with an *AccessError,
+	// instead of skipping them.
+	Strict bool
+	// SkipCommentOnly skips the pages the user can only comment on, and
+	// their sub-pages, instead of crawling and flagging them.
+	SkipCommentOnly bool
+}
+
+// RestrictedBlock is a block the user can't read, or can only comment on.
+type RestrictedBlock struct {
+	ID   string `json:"id"`
+	Role string `json:"role"`
+	// Ancestors are the ids of the crawled pages the block is in, starting
+	// with the crawl root.
+	Ancestors []string `json:"ancestors"`
+}
+
+// CrawlResult summarizes a crawl.
+type CrawlResult struct {
+	// Pages is the number of pages passed to the CrawlFunc.
+	Pages int `json:"pages"`
+	// Skipped are the pages and blocks, with their content and sub-pages,
+	// that the user can't read or, with SkipCommentOnly, can only comment
+	// on.
+	Skipped []*RestrictedBlock `json:"skipped,omitempty"`
+	// CommentOnly are the crawled pages the user can only comment on.
+	CommentOnly []*RestrictedBlock `json:"comment_only,omitempty"`
+}
+
+// String returns a one line summary of r.
+func (r *CrawlResult) String() string {
+	s := fmt.Sprintf("%d pages crawled", r.Pages)
+	if len(r.Skipped) > 0 {
+		s += fmt.Sprintf(", %d inaccessible pages or blocks skipped", len(r.Skipped))
+	}
+	if len(r.CommentOnly) > 0 {
+		s += fmt.Sprintf(", %d pages are comment only", len(r.CommentOnly))
+	}
+	return s
+}
+
+// Crawl visits the page rootID and, recursively, its sub-pages in depth-first
+// order, calling fn for each page selected by filter.
+// The content of each page is pruned according to filter before fn is called.
+// It fails with an *AccessError on crawled pages the user can't read, see
+// CrawlWithOptions.
+func (c *Client) Crawl(rootID string, filter *Filter, fn CrawlFunc) error {
+	_, err := c.CrawlWithOptions(rootID, CrawlOptions{Filter: filter, Strict: true}, fn)
+	return err
+}
+
+// CrawlWithOptions is like Crawl, but by default skips the pages and blocks
+// the user can't read, and reports them in the result along with the pages
+// the user can only comment on, so that crawls of shared workspaces finish.
+func (c *Client) CrawlWithOptions(rootID string, opts CrawlOptions, fn CrawlFunc) (*CrawlResult, error) {
+	r := &CrawlResult{}
+	err := c.crawl(rootID, nil, &opts, r, make(map[string]bool), make(map[string]*notiontypes.Collection), fn)
+	return r, err
+}
+
+// warnSkipped logs the pages and blocks skipped by a crawl.
+func (c *Client) warnSkipped(r *CrawlResult) {
+	for _, b := range r.Skipped {
+		c.logger.WithField("blockID", b.ID).WithField("role", b.Role).Warnln("skipping block that can't be read")
+	}
+}
+
+// crawl crawls the page id. collections holds the collections of the
+// database rows crawled so far, see getPage.
+func (c *Client) crawl(id string, ancestors []string, opts *CrawlOptions, r *CrawlResult, seen map[string]bool, collections map[string]*notiontypes.Collection, fn CrawlFunc) error {
+	if seen[id] {
+		return nil
+	}
+	seen[id] = true
+	filter := opts.Filter
+	page, err := c.getPage(id, notiontypes.ResolveOptions{Partial: !opts.Strict}, collections)
+	if e, ok := errors.Cause(err).(*AccessError); ok && !opts.Strict {
+		r.Skipped = append(r.Skipped, &RestrictedBlock{ID: id, Role: e.Role, Ancestors: ancestors})
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(ancestors) > 0 && !filter.KeepBlock(page.Block) {
+		return nil
+	}
+	if page.Role == notiontypes.RoleCommenter {
+		restricted := &RestrictedBlock{ID: id, Role: page.Role, Ancestors: ancestors}
+		if opts.SkipCommentOnly {
+			r.Skipped = append(r.Skipped, restricted)
+			return nil
+		}
+		r.CommentOnly = append(r.CommentOnly, restricted)
+	}
+	subPages := findSubPages(page.Block, nil)
+	ancestors = append(ancestors[:len(ancestors):len(ancestors)], id)
+	if !opts.Strict {
+		if err := c.skipMissing(page.Block, ancestors, r); err != nil {
+			return err
+		}
+	}
+	filter.Prune(page.Block)
+	if filter.InScope(id, ancestors[:len(ancestors)-1]) {
+		r.Pages++
+		if err := fn(page, ancestors[:len(ancestors)-1]); err != nil {
+			return err
+		}
+	}
+	for _, sub := range subPages {
+		if err := c.crawl(sub, ancestors, opts, r, seen, collections, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skipMissing adds the content of the page b that was missing from its
+// record map because the user can't read it to r.Skipped.
+func (c *Client) skipMissing(b *notiontypes.Block, ancestors []string, r *CrawlResult) error {
+	var records []Record
+	for _, parent := range notiontypes.MissingContent(b) {
+		for _, id := range parent.MissingIDs {
+			records = append(records, Record{Table: notiontypes.TableBlock, ID: id})
+		}
+	}
+	for len(records) > 0 {
+		n := len(records)
+		if n > getRecordValuesBatchSize {
+			n = getRecordValuesBatchSize
+		}
+		results, err := c.GetRecordValues(records[:n]...)
+		if err != nil {
+			return errors.Wrap(err, "getting missing content")
+		}
+		for i, res := range results {
+			if i < n && res != nil && res.Value == nil && res.Role == notiontypes.RoleNone {
+				r.Skipped = append(r.Skipped, &RestrictedBlock{ID: records[i].ID, Role: res.Role, Ancestors: ancestors})
+			}
+		}
+		records = records[n:]
+	}
+	return nil
+}
+
+// findSubPages returns the ids of pages nested in the content of b.
+func findSubPages(b *notiontypes.Block, ids []string) []string {
+	for _, child := range b.Content {
+		if child.IsPage() {
+			ids = append(ids, child.ID)
+			continue
+		}
+		ids = findSubPages(child, ids)
+	}
+	return ids
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,174 @@
+package notion_test
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/notiontest"
+	"github.com/tmc/notion/notiontypes"
+)
+
+func TestCrawlWithOptions(t *testing.T) {
+	s := notiontest.NewServer()
+	defer s.Close()
+	s.AddBlock(&notiontypes.Block{ID: "root", Type: notiontypes.BlockPage, Content: []*notiontypes.Block{
+		{ID: "text", Type: notiontypes.BlockText},
+		{ID: "shared", Type: notiontypes.BlockPage},
+		{ID: "private", Type: notiontypes.BlockPage},
+		{ID: "comments", Type: notiontypes.BlockPage, Content: []*notiontypes.Block{
+			{ID: "sub", Type: notiontypes.BlockPage},
+		}},
+	}})
+	s.SetRole("private", notiontypes.RoleNone)
+	s.SetRole("comments", notiontypes.RoleCommenter)
+	c := s.Client()
+
+	var crawled []string
+	r, err := c.CrawlWithOptions("root", notion.CrawlOptions{}, func(p *notion.Page, ancestors []string) error {
+		crawled = append(crawled, p.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"root", "shared", "comments", "sub"}; !reflect.DeepEqual(crawled, want) {
+		t.Errorf("crawled %v, want %v", crawled, want)
+	}
+	want := &notion.CrawlResult{
+		Pages:       4,
+		Skipped:     []*notion.RestrictedBlock{{ID: "private", Role: notiontypes.RoleNone, Ancestors: []string{"root"}}},
+		CommentOnly: []*notion.RestrictedBlock{{ID: "comments", Role: notiontypes.RoleCommenter, Ancestors: []string{"root"}}},
+	}
+	if !reflect.DeepEqual(r, want) {
+		t.Errorf("got result %v, want %v", r, want)
+	}
+
+	r, err = c.CrawlWithOptions("root", notion.CrawlOptions{SkipCommentOnly: true}, func(p *notion.Page, ancestors []string) error {
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Pages != 2 || len(r.Skipped) != 2 || len(r.CommentOnly) != 0 {
+		t.Errorf("with SkipCommentOnly, got %v", r)
+	}
+
+	s.SetRole("root", notiontypes.RoleNone)
+	err = c.Crawl("root", nil, func(p *notion.Page, ancestors []string) error { return nil })
+	if e, ok := errors.Cause(err).(*notion.AccessError); !ok || e.ID != "root" {
+		t.Errorf("crawling an inaccessible root: got error %v, want an AccessError", err)
+	}
+}
+
+func TestCrawlFilter(t *testing.T) {
+	s := notiontest.NewServer()
+	defer s.Close()
+	s.AddBlock(&notiontypes.Block{ID: "root", Type: notiontypes.BlockPage, Content: []*notiontypes.Block{
+		{ID: "text", Type: notiontypes.BlockText},
+		{ID: "toggle", Type: notiontypes.BlockToggle, Content: []*notiontypes.Block{
+			{ID: "image", Type: notiontypes.BlockImage},
+			{ID: "note", Type: notiontypes.BlockText},
+		}},
+		{ID: "docs", Type: notiontypes.BlockPage, Content: []*notiontypes.Block{
+			{ID: "guide", Type: notiontypes.BlockPage},
+		}},
+		{ID: "archive", Type: notiontypes.BlockPage, Properties: map[string]interface{}{"title": [][]string{{"Archive 2019"}}}, Content: []*notiontypes.Block{
+			{ID: "old", Type: notiontypes.BlockPage},
+		}},
+	}})
+	c := s.Client()
+	filter := &notion.Filter{ExcludeTitle: regexp.MustCompile(`^Archive`)}
+	filter.SkipImages()
+
+	pages := make(map[string]*notion.Page)
+	var crawled []string
+	err := c.Crawl("root", filter, func(p *notion.Page, ancestors []string) error {
+		pages[p.ID] = p
+		crawled = append(crawled, p.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"root", "docs", "guide"}; !reflect.DeepEqual(crawled, want) {
+		t.Errorf("crawled %v, want %v", crawled, want)
+	}
+	toggle := pages["root"].Content[1]
+	if len(toggle.Content) != 1 || toggle.Content[0].ID != "note" || !reflect.DeepEqual(toggle.ContentIDs, []string{"note"}) {
+		t.Errorf("got toggle content %v, want the image pruned", toggle.ContentIDs)
+	}
+
+	filter = &notion.Filter{Under: []string{"docs"}}
+	crawled = nil
+	if err := c.Crawl("root", filter, func(p *notion.Page, ancestors []string) error {
+		crawled = append(crawled, p.ID)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"docs", "guide"}; !reflect.DeepEqual(crawled, want) {
+		t.Errorf("crawled %v under docs, want %v", crawled, want)
+	}
+}
+
+func TestFilterPruneSharedContent(t *testing.T) {
+	a := &notiontypes.Block{ID: "a", Type: notiontypes.BlockImage}
+	b := &notiontypes.Block{ID: "b", Type: notiontypes.BlockText}
+	original := &notiontypes.Block{ID: "original", Type: notiontypes.BlockSyncedBlock, Content: []*notiontypes.Block{a, b, b}}
+	copied := &notiontypes.Block{ID: "copy", Type: notiontypes.BlockSyncedBlockCopy, Content: original.Content}
+	filter := &notion.Filter{}
+	filter.SkipImages()
+	filter.Prune(copied)
+	if len(copied.Content) != 2 {
+		t.Errorf("got %d blocks after pruning, want 2", len(copied.Content))
+	}
+	if original.Content[0] != a || original.Content[1] != b || original.Content[2] != b {
+		t.Errorf("pruning a block changed content it shares: %v", original.Content)
+	}
+}
+
+func TestCrawlRowProperties(t *testing.T) {
+	s := notiontest.NewServer()
+	defer s.Close()
+	s.AddRecord(notiontypes.TableCollection, "db", map[string]interface{}{
+		"schema": map[string]interface{}{
+			"title": map[string]string{"name": "Name", "type": "title"},
+			"st":    map[string]string{"name": "Status", "type": "select"},
+		},
+	})
+	row := func(id string) *notiontypes.Block {
+		return &notiontypes.Block{ID: id, Type: notiontypes.BlockPage, ParentID: "db", ParentTable: notiontypes.TableCollection,
+			Properties: map[string]interface{}{"title": [][]string{{id}}, "st": [][]string{{"Done"}}}}
+	}
+	s.AddBlock(&notiontypes.Block{ID: "root", Type: notiontypes.BlockPage, Content: []*notiontypes.Block{row("row1"), row("row2")}})
+	c := s.Client()
+
+	crawl := func() map[string]string {
+		got := map[string]string{}
+		err := c.Crawl("root", nil, func(p *notion.Page, ancestors []string) error {
+			if p.ID != "root" {
+				got[p.ID] = ""
+				for _, prop := range p.PageProperties {
+					got[p.ID] += prop.Name + "=" + prop.Text()
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+	if got, want := crawl(), map[string]string{"row1": "Status=Done", "row2": "Status=Done"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got properties %v, want %v", got, want)
+	}
+	// the collection is only fetched once per crawl, and rows have no
+	// properties if it can't be
+	s.Fail("getRecordValues", 1)
+	if got, want := crawl(), map[string]string{"row1": "", "row2": ""}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got properties %v without the collection, want %v", got, want)
+	}
+}
@@ -0,0 +1,102 @@
+package notion
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// CreatePage creates a page titled title under the page parentID with the
+// given content and returns the id of the new page.
+func (c *Client) CreatePage(parentID, title string, content ...*notiontypes.Block) (string, error) {
+	page := &notiontypes.Block{
+		Type: notiontypes.BlockPage,
+		Properties: map[string]interface{}{
+			"title": [][]string{{title}},
+		},
+		Content: content,
+	}
+	if err := c.AppendBlocks(parentID, page); err != nil {
+		return "", err
+	}
+	return page.ID, nil
+}
+
+// AppendBlocks creates blocks (and, recursively, their Content) at the end
+// of the block parentID in a single transaction.
+//
+// Only Type, Properties, FormatRaw and Content are read from the given blocks.
+// Blocks without an ID are assigned a new one, which is stored back into the block.
+// IDs given without dashes are stored back in dashed form.
+func (c *Client) AppendBlocks(parentID string, blocks ...*notiontypes.Block) error {
+	parentID, err := FormatID(parentID)
+	if err != nil {
+		return err
+	}
+	now := c.clock.Now().UnixNano() / int64(time.Millisecond)
+	var ops []*operation
+	for _, b := range blocks {
+		blockOps, err := c.createBlockOps(b, parentID, now)
+		if err != nil {
+			return err
+		}
+		ops = append(ops, blockOps...)
+	}
+	return c.submitTransaction(ops...)
+}
+
+func (c *Client) createBlockOps(b *notiontypes.Block, parentID string, now int64) ([]*operation, error) {
+	if b.ID == "" {
+		b.ID = c.newID()
+	}
+	id, err := FormatID(b.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating block")
+	}
+	b.ID = id
+	b.ParentID = parentID
+	b.ParentTable = notiontypes.TableBlock
+	args := map[string]interface{}{
+		"id":               b.ID,
+		"type":             b.Type,
+		"version":          1,
+		"alive":            true,
+		"parent_id":        parentID,
+		"parent_table":     notiontypes.TableBlock,
+		"created_time":     now,
+		"last_edited_time": now,
+	}
+	if len(b.Properties) > 0 {
+		args["properties"] = b.Properties
+	}
+	if len(b.FormatRaw) > 0 {
+		args["format"] = b.FormatRaw
+	}
+	ops := []*operation{
+		{
+			ID:      b.ID,
+			Table:   notiontypes.TableBlock,
+			Path:    []string{},
+			Command: "set",
+			Args:    args,
+		},
+		{
+			ID:      parentID,
+			Table:   notiontypes.TableBlock,
+			Path:    []string{"content"},
+			Command: "listAfter",
+			Args:    map[string]string{"id": b.ID},
+		},
+	}
+	b.ContentIDs = b.ContentIDs[:0]
+	for _, child := range b.Content {
+		childOps, err := c.createBlockOps(child, b.ID, now)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, childOps...)
+		b.ContentIDs = append(b.ContentIDs, child.ID)
+	}
+	return ops, nil
+}
@@ -0,0 +1,107 @@
+package notion_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/notiontest"
+	"github.com/tmc/notion/notiontypes"
+)
+
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
+func TestAppendBlocksDeterministic(t *testing.T) {
+	s := notiontest.NewServer()
+	defer s.Close()
+	parentID := "4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+	s.AddBlock(&notiontypes.Block{ID: parentID, Type: notiontypes.BlockPage})
+	n := 0
+	c := s.Client(
+		notion.WithClock(fixedClock(time.Unix(1551398400, 0))),
+		notion.WithIDGenerator(func() string {
+			n++
+			return fmt.Sprintf("00000000-0000-4000-8000-%012d", n)
+		}),
+	)
+	page := &notiontypes.Block{Type: notiontypes.BlockPage, Content: []*notiontypes.Block{{Type: notiontypes.BlockText}}}
+	if err := c.AppendBlocks(parentID, page); err != nil {
+		t.Fatal(err)
+	}
+	ops := s.Operations()
+	if len(ops) != 4 {
+		t.Fatalf("got %d operations, want 4", len(ops))
+	}
+	for i, want := range []string{"00000000-0000-4000-8000-000000000001", "00000000-0000-4000-8000-000000000002"} {
+		op := ops[2*i]
+		var args struct {
+			CreatedTime int64 `json:"created_time"`
+		}
+		if err := json.Unmarshal(op.Args, &args); err != nil {
+			t.Fatal(err)
+		}
+		if op.ID != want || args.CreatedTime != 1551398400000 {
+			t.Errorf("operation %d: got id %v created at %v", 2*i, op.ID, args.CreatedTime)
+		}
+	}
+}
+
+func TestDuplicatePageWithOptions(t *testing.T) {
+	s := notiontest.NewServer()
+	defer s.Close()
+	const (
+		templateID = "4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+		subPageID  = "5b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+		parentID   = "6b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+	)
+	s.AddBlock(&notiontypes.Block{ID: parentID, Type: notiontypes.BlockPage})
+	s.AddBlock(&notiontypes.Block{
+		ID:         templateID,
+		Type:       notiontypes.BlockPage,
+		Properties: map[string]interface{}{"title": [][]string{{"Sprint {{sprint}} planning"}}},
+		Content: []*notiontypes.Block{
+			{
+				ID:         "7b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e",
+				Type:       notiontypes.BlockText,
+				Properties: map[string]interface{}{"title": []interface{}{[]interface{}{"Owner: "}, []interface{}{"{{ owner }}", []interface{}{[]interface{}{"b"}}}, []interface{}{" {{unknown}}"}}},
+			},
+			{
+				ID:         subPageID,
+				Type:       notiontypes.BlockPage,
+				Properties: map[string]interface{}{"title": [][]string{{"Notes {{date}}"}}},
+				Content:    []*notiontypes.Block{{ID: "8b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e", Type: notiontypes.BlockDivider}},
+			},
+		},
+	})
+	c := s.Client()
+	id, err := c.DuplicatePageWithOptions(templateID, parentID, notion.DuplicateOptions{
+		Vars: map[string]string{"sprint": "42", "owner": "Ann", "date": "2019-03-01"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dup := s.Block(id)
+	if dup == nil || dup.Title != "Sprint 42 planning" || len(dup.Content) != 2 {
+		t.Fatalf("got copy %+v", dup)
+	}
+	text := dup.Content[0]
+	if len(text.InlineContent) != 3 || text.InlineContent[1].Text != "Ann" || text.InlineContent[1].AttrFlags == 0 || text.InlineContent[2].Text != " {{unknown}}" {
+		t.Errorf("got text %v", text.Properties)
+	}
+	sub := dup.Content[1]
+	if sub.ID == subPageID || sub.Title != "Notes 2019-03-01" {
+		t.Errorf("got sub-page %v %q", sub.ID, sub.Title)
+	}
+	if sub = s.Block(sub.ID); len(sub.Content) != 1 || sub.Content[0].Type != notiontypes.BlockDivider {
+		t.Errorf("content of sub-page was not copied")
+	}
+	if parent := s.Block(parentID); len(parent.ContentIDs) != 1 || parent.ContentIDs[0] != id {
+		t.Errorf("copy was not added to parent")
+	}
+}
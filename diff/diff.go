@@ -0,0 +1,234 @@
+// Package diff compares two versions of a notion page, e.g. a page and a
+// backup of it, and renders the differences in several formats.
+package diff
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+// Op describes how a block differs between two versions.
+type Op int
+
+const (
+	// Equal blocks are the same in both versions, though their children
+	// may differ.
+	Equal Op = iota
+	// Added blocks are only in the new version.
+	Added
+	// Removed blocks are only in the old version.
+	Removed
+	// Edited blocks are in both versions, with different content.
+	Edited
+)
+
+func (o Op) String() string {
+	switch o {
+	case Equal:
+		return "equal"
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Edited:
+		return "edited"
+	}
+	return "unknown"
+}
+
+// Node is a block of the aligned block trees of two versions.
+type Node struct {
+	Op Op
+	// Old is the block in the old version, or nil if it was added.
+	Old *notiontypes.Block
+	// New is the block in the new version, or nil if it was removed.
+	New *notiontypes.Block
+	// Children holds the aligned children of blocks in both versions.
+	// Children of added and removed blocks are not aligned.
+	Children []*Node
+}
+
+// Compare aligns the block trees old and new. Children are matched by id.
+// Blocks whose ids are in only one of the trees are matched by type and
+// text instead, so that copies of a page can be compared too.
+func Compare(old, new *notiontypes.Block) *Node {
+	return compare(old, new, 1)
+}
+
+// CompareSimilar is like Compare, but also matches blocks of the same type
+// whose texts have at least the given Similarity, e.g. to align the blocks
+// of pages that were duplicated and then edited separately.
+func CompareSimilar(old, new *notiontypes.Block, threshold float64) *Node {
+	return compare(old, new, threshold)
+}
+
+func compare(old, new *notiontypes.Block, threshold float64) *Node {
+	n := &Node{Old: old, New: new}
+	if !sameContent(old, new) {
+		n.Op = Edited
+	}
+	n.Children = align(old.Content, new.Content, threshold)
+	return n
+}
+
+// Block returns the new version of the block, or the old one if it was removed.
+func (n *Node) Block() *notiontypes.Block {
+	if n.New != nil {
+		return n.New
+	}
+	return n.Old
+}
+
+// Changed reports whether the block or any of its descendants differ.
+func (n *Node) Changed() bool {
+	if n.Op != Equal {
+		return true
+	}
+	for _, c := range n.Children {
+		if c.Changed() {
+			return true
+		}
+	}
+	return false
+}
+
+// Changes returns the added, removed and edited nodes, in document order.
+func (n *Node) Changes() []*Node {
+	var res []*Node
+	if n.Op != Equal {
+		res = append(res, n)
+	}
+	for _, c := range n.Children {
+		res = append(res, c.Changes()...)
+	}
+	return res
+}
+
+func align(old, new []*notiontypes.Block, threshold float64) []*Node {
+	oldIDs := make(map[string]bool, len(old))
+	for _, b := range old {
+		oldIDs[b.ID] = true
+	}
+	newIDs := make(map[string]bool, len(new))
+	for _, b := range new {
+		newIDs[b.ID] = true
+	}
+	match := func(a, b *notiontypes.Block) bool {
+		if a.ID == b.ID {
+			return true
+		}
+		if newIDs[a.ID] || oldIDs[b.ID] || a.Type != b.Type {
+			return false
+		}
+		ta, tb := Text(a), Text(b)
+		return ta == tb || threshold < 1 && Similarity(ta, tb) >= threshold
+	}
+
+	// longest common subsequence of old and new
+	lcs := make([][]int, len(old)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(new)+1)
+	}
+	for i := len(old) - 1; i >= 0; i-- {
+		for j := len(new) - 1; j >= 0; j-- {
+			switch {
+			case match(old[i], new[j]):
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	var res []*Node
+	i, j := 0, 0
+	for i < len(old) || j < len(new) {
+		switch {
+		case i < len(old) && j < len(new) && match(old[i], new[j]):
+			res = append(res, compare(old[i], new[j], threshold))
+			i, j = i+1, j+1
+		case i < len(old) && (j == len(new) || lcs[i+1][j] >= lcs[i][j+1]):
+			res = append(res, &Node{Op: Removed, Old: old[i]})
+			i++
+		default:
+			res = append(res, &Node{Op: Added, New: new[j]})
+			j++
+		}
+	}
+	return res
+}
+
+func sameContent(a, b *notiontypes.Block) bool {
+	return a.Type == b.Type && Text(a) == Text(b) && a.IsChecked == b.IsChecked &&
+		reflect.DeepEqual(a.Properties, b.Properties)
+}
+
+// Text returns the plain text of b: the title of pages, the text of text
+// blocks, the cells of tables and the link of media and bookmarks.
+func Text(b *notiontypes.Block) string {
+	if b.Title != "" {
+		return b.Title
+	}
+	if len(b.InlineContent) > 0 {
+		return plainText(b.InlineContent)
+	}
+	if len(b.Rows) > 0 {
+		rows := make([]string, len(b.Rows))
+		for i, row := range b.Rows {
+			cells := make([]string, len(row))
+			for j, cell := range row {
+				cells[j] = plainText(cell)
+			}
+			rows[i] = strings.Join(cells, " | ")
+		}
+		return strings.Join(rows, "\n")
+	}
+	if b.Link != "" {
+		return b.Link
+	}
+	return b.Source
+}
+
+func plainText(inline []*notiontypes.InlineBlock) string {
+	var b strings.Builder
+	for _, i := range inline {
+		b.WriteString(i.Text)
+	}
+	return b.String()
+}
+
+// Similarity returns the Sørensen–Dice coefficient of the letter pairs of a
+// and b: 1 for equal strings, 0 for strings without common pairs.
+func Similarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	pa, pb := bigrams(a), bigrams(b)
+	if len(pa) == 0 || len(pb) == 0 {
+		return 0
+	}
+	counts := make(map[string]int, len(pa))
+	for _, p := range pa {
+		counts[p]++
+	}
+	common := 0
+	for _, p := range pb {
+		if counts[p] > 0 {
+			counts[p]--
+			common++
+		}
+	}
+	return 2 * float64(common) / float64(len(pa)+len(pb))
+}
+
+func bigrams(s string) []string {
+	r := []rune(strings.ToLower(s))
+	var res []string
+	for i := 0; i+1 < len(r); i++ {
+		res = append(res, string(r[i:i+2]))
+	}
+	return res
+}
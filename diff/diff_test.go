@@ -0,0 +1,143 @@
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+func block(id, typ, text string, children ...*notiontypes.Block) *notiontypes.Block {
+	b := &notiontypes.Block{ID: id, Type: typ, Content: children}
+	if text != "" {
+		b.InlineContent = []*notiontypes.InlineBlock{{Text: text}}
+	}
+	return b
+}
+
+func versions() (old, new *notiontypes.Block) {
+	old = &notiontypes.Block{ID: "page", Type: notiontypes.BlockPage, Title: "Notes", Content: []*notiontypes.Block{
+		block("a", notiontypes.BlockText, "intro"),
+		block("b", notiontypes.BlockText, "to be removed"),
+		block("c", notiontypes.BlockBulletedList, "list", block("c1", notiontypes.BlockText, "nested")),
+		block("d", notiontypes.BlockTodo, "task"),
+	}}
+	new = &notiontypes.Block{ID: "page", Type: notiontypes.BlockPage, Title: "Notes", Content: []*notiontypes.Block{
+		block("a", notiontypes.BlockText, "intro"),
+		block("c", notiontypes.BlockBulletedList, "list", block("c1", notiontypes.BlockText, "nested, edited")),
+		block("e", notiontypes.BlockText, "added"),
+		block("d", notiontypes.BlockTodo, "task"),
+	}}
+	new.Content[3].IsChecked = true
+	return old, new
+}
+
+func TestCompare(t *testing.T) {
+	n := Compare(versions())
+	var got []string
+	for _, c := range n.Changes() {
+		got = append(got, c.Op.String()+" "+c.Block().ID)
+	}
+	want := []string{"removed b", "edited c1", "added e", "edited d"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got changes %v, want %v", got, want)
+	}
+
+	// copies of a page are aligned by content
+	old, _ := versions()
+	copied, _ := versions()
+	for _, b := range copied.Content {
+		b.ID += "-copy"
+	}
+	if n := Compare(old, copied); n.Changed() {
+		t.Errorf("copy differs: %v", n.Changes())
+	}
+}
+
+func TestWriteUnified(t *testing.T) {
+	buf := new(bytes.Buffer)
+	if err := WriteUnified(buf, Compare(versions())); err != nil {
+		t.Fatal(err)
+	}
+	want := `--- a/page
++++ b/page
+@@ -1,6 +1,6 @@
+ Notes
+   intro
+-  to be removed
+   list
+-    nested
++    nested, edited
++  added
+-  [ ] task
++  [x] task
+`
+	if buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf, want)
+	}
+}
+
+func TestPatch(t *testing.T) {
+	old, new := versions()
+	doc := toJSON(t, NewTree(old))
+	for _, op := range Patch(Compare(old, new)) {
+		doc = apply(t, doc, op)
+	}
+	if want := toJSON(t, NewTree(new)); !reflect.DeepEqual(doc, want) {
+		t.Errorf("patched tree is %v, want %v", doc, want)
+	}
+}
+
+func toJSON(t *testing.T, v interface{}) interface{} {
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var res interface{}
+	if err := json.Unmarshal(b, &res); err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+// apply applies op, one of the operations used by Patch, to doc.
+func apply(t *testing.T, doc interface{}, op *PatchOp) interface{} {
+	return applyPath(doc, strings.Split(op.Path, "/")[1:], op.Op, toJSON(t, op.Value))
+}
+
+func applyPath(doc interface{}, tokens []string, op string, value interface{}) interface{} {
+	switch d := doc.(type) {
+	case map[string]interface{}:
+		if len(tokens) > 1 {
+			d[tokens[0]] = applyPath(d[tokens[0]], tokens[1:], op, value)
+		} else if op == "remove" {
+			delete(d, tokens[0])
+		} else {
+			d[tokens[0]] = value
+		}
+		return d
+	case []interface{}:
+		i, _ := strconv.Atoi(tokens[0])
+		if len(tokens) > 1 {
+			d[i] = applyPath(d[i], tokens[1:], op, value)
+			return d
+		}
+		res := append([]interface{}{}, d[:i]...)
+		switch op {
+		case "add":
+			res = append(res, value)
+			res = append(res, d[i:]...)
+		case "remove":
+			res = append(res, d[i+1:]...)
+		case "replace":
+			res = append(res, value)
+			res = append(res, d[i+1:]...)
+		}
+		return res
+	}
+	return doc
+}
@@ -0,0 +1,46 @@
+package diff
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Format is an output format of Write.
+type Format int
+
+const (
+	// Unified is a unified diff of the plain text of the blocks.
+	Unified Format = iota
+	// JSONPatch is an RFC 6902 JSON Patch turning the Tree of the old
+	// version into that of the new one.
+	JSONPatch
+	// HTML is an HTML document showing both versions side by side.
+	HTML
+)
+
+// ParseFormat returns the format called s: unified, jsonpatch or html.
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "unified", "text":
+		return Unified, nil
+	case "jsonpatch", "json-patch":
+		return JSONPatch, nil
+	case "html":
+		return HTML, nil
+	}
+	return 0, fmt.Errorf("diff: unknown format %q", s)
+}
+
+// Write writes the differences of n in format f to w.
+func Write(w io.Writer, n *Node, f Format) error {
+	switch f {
+	case Unified:
+		return WriteUnified(w, n)
+	case JSONPatch:
+		return WriteJSONPatch(w, n)
+	case HTML:
+		return WriteHTML(w, n)
+	}
+	return fmt.Errorf("diff: unknown format %d", f)
+}
@@ -0,0 +1,79 @@
+package diff
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+const htmlStyle = `table { border-collapse: collapse; width: 100%; }
+td { vertical-align: top; width: 50%; padding: 2px 8px; white-space: pre-wrap; }
+.added { background: #e6ffec; }
+.removed { background: #ffebe9; }
+.edited { background: #fff8c5; }
+`
+
+// WriteHTML writes an HTML document to w that shows the old and new
+// versions of the blocks of n side by side, with changed blocks highlighted.
+func WriteHTML(w io.Writer, n *Node) error {
+	bw := bufio.NewWriter(w)
+	title := html.EscapeString(Text(n.New))
+	bw.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	bw.WriteString("<title>Changes to " + title + "</title>\n<style>\n" + htmlStyle + "</style>\n</head>\n<body>\n")
+	bw.WriteString("<table>\n<tr><th>" + html.EscapeString(n.Old.ID) + "</th><th>" + html.EscapeString(n.New.ID) + "</th></tr>\n")
+	htmlRows(bw, n, 0)
+	bw.WriteString("</table>\n</body>\n</html>\n")
+	return bw.Flush()
+}
+
+func htmlRows(w *bufio.Writer, n *Node, depth int) {
+	switch n.Op {
+	case Added:
+		htmlBlockRows(w, n.New, depth, false)
+		return
+	case Removed:
+		htmlBlockRows(w, n.Old, depth, true)
+		return
+	}
+	htmlRow(w, n.Op, htmlCell(n.Old, depth), htmlCell(n.New, depth))
+	for _, c := range n.Children {
+		htmlRows(w, c, depth+1)
+	}
+}
+
+// htmlBlockRows writes the rows of b and its descendants, which are only in
+// one of the versions.
+func htmlBlockRows(w *bufio.Writer, b *notiontypes.Block, depth int, removed bool) {
+	if removed {
+		htmlRow(w, Removed, htmlCell(b, depth), "")
+	} else {
+		htmlRow(w, Added, "", htmlCell(b, depth))
+	}
+	for _, c := range b.Content {
+		htmlBlockRows(w, c, depth+1, removed)
+	}
+}
+
+func htmlRow(w *bufio.Writer, op Op, old, new string) {
+	if op == Equal {
+		fmt.Fprintf(w, "<tr><td>%s</td><td>%s</td></tr>\n", old, new)
+		return
+	}
+	fmt.Fprintf(w, "<tr class=\"%v\"><td>%s</td><td>%s</td></tr>\n", op, old, new)
+}
+
+func htmlCell(b *notiontypes.Block, depth int) string {
+	text := Text(b)
+	if b.Type == notiontypes.BlockTodo {
+		if b.IsChecked {
+			text = "☑ " + text
+		} else {
+			text = "☐ " + text
+		}
+	}
+	return strings.Repeat("&nbsp;&nbsp;", depth) + html.EscapeString(text)
+}
@@ -0,0 +1,94 @@
+package diff
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+// Tree is the JSON document JSON Patches of block trees apply to.
+type Tree struct {
+	ID      string  `json:"id"`
+	Type    string  `json:"type"`
+	Text    string  `json:"text,omitempty"`
+	Checked bool    `json:"checked,omitempty"`
+	Content []*Tree `json:"content"`
+}
+
+// NewTree returns the Tree of b and its descendants.
+func NewTree(b *notiontypes.Block) *Tree {
+	t := &Tree{ID: b.ID, Type: b.Type, Text: Text(b), Checked: b.IsChecked, Content: []*Tree{}}
+	for _, c := range b.Content {
+		t.Content = append(t.Content, NewTree(c))
+	}
+	return t
+}
+
+// PatchOp is an operation of an RFC 6902 JSON Patch.
+type PatchOp struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// Patch returns the JSON Patch that turns the Tree of n.Old into that of
+// n.New. Operations are relative to the document as patched by the
+// operations before them.
+func Patch(n *Node) []*PatchOp {
+	return patch(n, "", nil)
+}
+
+func patch(n *Node, path string, ops []*PatchOp) []*PatchOp {
+	old, new := n.Old, n.New
+	if old.ID != new.ID {
+		ops = append(ops, &PatchOp{Op: "replace", Path: path + "/id", Value: new.ID})
+	}
+	if old.Type != new.Type {
+		ops = append(ops, &PatchOp{Op: "replace", Path: path + "/type", Value: new.Type})
+	}
+	ops = patchField(ops, path+"/text", Text(old), Text(new))
+	ops = patchField(ops, path+"/checked", old.IsChecked, new.IsChecked)
+	i := 0
+	for _, c := range n.Children {
+		p := path + "/content/" + strconv.Itoa(i)
+		switch c.Op {
+		case Removed:
+			ops = append(ops, &PatchOp{Op: "remove", Path: p})
+			continue
+		case Added:
+			ops = append(ops, &PatchOp{Op: "add", Path: p, Value: NewTree(c.New)})
+		default:
+			ops = patch(c, p, ops)
+		}
+		i++
+	}
+	return ops
+}
+
+// patchField changes the member at path from old to new. Members are
+// omitted from Trees when empty.
+func patchField(ops []*PatchOp, path string, old, new interface{}) []*PatchOp {
+	if old == new {
+		return ops
+	}
+	switch {
+	case new == "" || new == false:
+		return append(ops, &PatchOp{Op: "remove", Path: path})
+	case old == "" || old == false:
+		return append(ops, &PatchOp{Op: "add", Path: path, Value: new})
+	}
+	return append(ops, &PatchOp{Op: "replace", Path: path, Value: new})
+}
+
+// WriteJSONPatch writes the JSON Patch of n to w. See Patch.
+func WriteJSONPatch(w io.Writer, n *Node) error {
+	ops := Patch(n)
+	if ops == nil {
+		ops = []*PatchOp{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(ops)
+}
@@ -0,0 +1,126 @@
+package diff
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+// contextLines is the number of unchanged lines around changes in unified diffs.
+const contextLines = 3
+
+type line struct {
+	op   byte // ' ', '-' or '+'
+	text string
+}
+
+// WriteUnified writes a unified diff of the plain text of the blocks of n
+// to w, with one line per line of text, indented by depth.
+func WriteUnified(w io.Writer, n *Node) error {
+	ls := nodeLines(n, 0, nil)
+	// line numbers in the old and new version at the start of each line
+	oldNo, newNo := make([]int, len(ls)+1), make([]int, len(ls)+1)
+	for i, l := range ls {
+		oldNo[i+1], newNo[i+1] = oldNo[i], newNo[i]
+		if l.op != '+' {
+			oldNo[i+1]++
+		}
+		if l.op != '-' {
+			newNo[i+1]++
+		}
+	}
+	bw := bufio.NewWriter(w)
+	if !n.Changed() {
+		return bw.Flush()
+	}
+	fmt.Fprintf(bw, "--- a/%v\n+++ b/%v\n", n.Old.ID, n.New.ID)
+	for i := 0; i < len(ls); {
+		for i < len(ls) && ls[i].op == ' ' {
+			i++
+		}
+		if i == len(ls) {
+			break
+		}
+		start, end := i-contextLines, i
+		if start < 0 {
+			start = 0
+		}
+		for j := i; j < len(ls) && j-end <= 2*contextLines; j++ {
+			if ls[j].op != ' ' {
+				end = j
+			}
+		}
+		stop := end + contextLines + 1
+		if stop > len(ls) {
+			stop = len(ls)
+		}
+		fmt.Fprintf(bw, "@@ -%v +%v @@\n", hunkRange(oldNo[start], oldNo[stop]), hunkRange(newNo[start], newNo[stop]))
+		for _, l := range ls[start:stop] {
+			bw.WriteByte(l.op)
+			bw.WriteString(l.text)
+			bw.WriteByte('\n')
+		}
+		i = stop
+	}
+	return bw.Flush()
+}
+
+// hunkRange formats the lines from start to stop (exclusive, 0-based) of a
+// version as a hunk range.
+func hunkRange(start, stop int) string {
+	if stop == start {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if stop-start == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, stop-start)
+}
+
+func nodeLines(n *Node, depth int, out []line) []line {
+	switch n.Op {
+	case Added:
+		return blockLines(n.New, '+', depth, out)
+	case Removed:
+		return blockLines(n.Old, '-', depth, out)
+	case Edited:
+		out = textLines(n.Old, '-', depth, out)
+		out = textLines(n.New, '+', depth, out)
+	default:
+		out = textLines(n.New, ' ', depth, out)
+	}
+	for _, c := range n.Children {
+		out = nodeLines(c, depth+1, out)
+	}
+	return out
+}
+
+func blockLines(b *notiontypes.Block, op byte, depth int, out []line) []line {
+	out = textLines(b, op, depth, out)
+	for _, c := range b.Content {
+		out = blockLines(c, op, depth+1, out)
+	}
+	return out
+}
+
+func textLines(b *notiontypes.Block, op byte, depth int, out []line) []line {
+	text := Text(b)
+	if b.Type == notiontypes.BlockTodo {
+		if b.IsChecked {
+			text = "[x] " + text
+		} else {
+			text = "[ ] " + text
+		}
+	}
+	if text == "" {
+		return out
+	}
+	indent := strings.Repeat("  ", depth)
+	for _, l := range strings.Split(text, "\n") {
+		out = append(out, line{op, indent + l})
+	}
+	return out
+}
@@ -0,0 +1,564 @@
+// Package doctor checks the rows of a database for common data problems,
+// such as empty titles or relations to deleted pages, and optionally fixes
+// them as directed by a Policy.
+package doctor
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// Checks.
+const (
+	// EmptyTitle finds rows without a title.
+	EmptyTitle = "empty-title"
+	// OrphanedRelation finds relations to pages that were deleted.
+	OrphanedRelation = "orphaned-relation"
+	// UnreadableRelation finds relations to pages that can't be read,
+	// because they don't exist or aren't shared with the user. They're
+	// only reported.
+	UnreadableRelation = "unreadable-relation"
+	// InvalidURL finds url properties that aren't absolute URLs.
+	InvalidURL = "invalid-url"
+	// OutOfRange finds number properties outside their Policy.Ranges.
+	OutOfRange = "out-of-range"
+	// DuplicateOption finds options of select properties that differ only
+	// by case or surrounding spaces.
+	DuplicateOption = "duplicate-option"
+)
+
+// Fixes, by the checks they apply to.
+const (
+	// FixSetTitle sets empty titles to Policy.Title (EmptyTitle).
+	FixSetTitle = "set"
+	// FixRemove removes the orphaned pages from relations
+	// (OrphanedRelation).
+	FixRemove = "remove"
+	// FixClear clears the property (InvalidURL, OutOfRange).
+	FixClear = "clear"
+	// FixHTTPS prefixes URLs without a scheme with https:// if that makes
+	// them valid, and clears the others (InvalidURL).
+	FixHTTPS = "https"
+	// FixClamp sets numbers to the nearest bound of their range
+	// (OutOfRange).
+	FixClamp = "clamp"
+	// FixMerge replaces duplicate options by the first of them, in rows and
+	// in the schema (DuplicateOption).
+	FixMerge = "merge"
+)
+
+var fixes = map[string][]string{
+	EmptyTitle:         {FixSetTitle},
+	OrphanedRelation:   {FixRemove},
+	UnreadableRelation: nil,
+	InvalidURL:         {FixClear, FixHTTPS},
+	OutOfRange:         {FixClear, FixClamp},
+	DuplicateOption:    {FixMerge},
+}
+
+// Range bounds the values of a number property. Nil bounds are unbounded.
+type Range struct {
+	Min *float64 `json:"min,omitempty" yaml:"min,omitempty"`
+	Max *float64 `json:"max,omitempty" yaml:"max,omitempty"`
+}
+
+// Policy configures the checks and how Fix fixes what they find. It's
+// typically read from a YAML file:
+//
+//	ranges:
+//	  Estimate: {min: 0, max: 40}
+//	fixes:
+//	  empty-title: set
+//	  orphaned-relation: remove
+//	  invalid-url: https
+//	  out-of-range: clamp
+//	  duplicate-option: merge
+//	title: Untitled
+type Policy struct {
+	// Ranges are the allowed values of number properties, by name.
+	Ranges map[string]Range `json:"ranges,omitempty" yaml:"ranges,omitempty"`
+	// Fixes are the fixes applied by Fix, by check. The findings of checks
+	// without a fix are only reported.
+	Fixes map[string]string `json:"fixes,omitempty" yaml:"fixes,omitempty"`
+	// Title is the title of rows fixed by FixSetTitle, "Untitled" by
+	// default.
+	Title string `json:"title,omitempty" yaml:"title,omitempty"`
+	// Skip lists checks not run.
+	Skip []string `json:"skip,omitempty" yaml:"skip,omitempty"`
+}
+
+func (p *Policy) validate() error {
+	for check, fix := range p.Fixes {
+		valid, ok := fixes[check]
+		if !ok {
+			return errors.Errorf("doctor: unknown check %q", check)
+		}
+		if !contains(valid, fix) {
+			return errors.Errorf("doctor: unknown fix %q for %v, want one of %v", fix, check, strings.Join(valid, ", "))
+		}
+	}
+	for _, check := range p.Skip {
+		if _, ok := fixes[check]; !ok {
+			return errors.Errorf("doctor: unknown check %q", check)
+		}
+	}
+	for name, r := range p.Ranges {
+		if r.Min != nil && r.Max != nil && *r.Min > *r.Max {
+			return errors.Errorf("doctor: range of %q has min %v above max %v", name, *r.Min, *r.Max)
+		}
+	}
+	return nil
+}
+
+// Finding is a problem found by a check.
+type Finding struct {
+	Check string `json:"check"`
+	// RowID and Row are the id and title of the row, empty for problems
+	// of the schema.
+	RowID    string `json:"row_id,omitempty"`
+	Row      string `json:"row,omitempty"`
+	Property string `json:"property"`
+	Value    string `json:"value,omitempty"`
+	Message  string `json:"message"`
+	// Fixed is the fix applied, if any.
+	Fixed string `json:"fixed,omitempty"`
+}
+
+// Report lists the findings of the checks of a database.
+type Report struct {
+	CollectionID string `json:"collection_id"`
+	// Rows is the number of rows checked.
+	Rows int `json:"rows"`
+	// Findings are schema findings first, then row findings in row order.
+	Findings []*Finding `json:"findings"`
+}
+
+// Unfixed returns the number of findings that weren't fixed.
+func (r *Report) Unfixed() int {
+	n := 0
+	for _, f := range r.Findings {
+		if f.Fixed == "" {
+			n++
+		}
+	}
+	return n
+}
+
+// Check runs the checks of policy, which may be nil, on the database
+// collectionID, without fixing anything.
+func Check(c *notion.Client, collectionID string, policy *Policy) (*Report, error) {
+	return run(c, collectionID, policy, false)
+}
+
+// Fix runs the checks of policy on the database collectionID and applies
+// the fixes of policy to what they find.
+func Fix(c *notion.Client, collectionID string, policy *Policy) (*Report, error) {
+	return run(c, collectionID, policy, true)
+}
+
+func run(c *notion.Client, collectionID string, policy *Policy, fix bool) (*Report, error) {
+	if policy == nil {
+		policy = &Policy{}
+	}
+	if err := policy.validate(); err != nil {
+		return nil, err
+	}
+	if !fix {
+		policy = &Policy{Ranges: policy.Ranges, Title: policy.Title, Skip: policy.Skip}
+	}
+	collection, err := c.GetCollection(collectionID)
+	if err != nil {
+		return nil, err
+	}
+	enabled := func(check string) bool { return !contains(policy.Skip, check) }
+	r := &Report{CollectionID: collection.ID}
+
+	// the names of properties by type, in name order
+	props := map[string][]string{}
+	for _, col := range collection.CollectionSchema {
+		props[col.Type] = append(props[col.Type], col.Name)
+	}
+	for _, names := range props {
+		sort.Strings(names)
+	}
+	for name := range policy.Ranges {
+		found := false
+		for _, n := range props[notiontypes.ColumnTypeNumber] {
+			found = found || strings.EqualFold(n, name)
+		}
+		if !found {
+			return nil, errors.Errorf("doctor: collection %v has no number property %q", collection.ID, name)
+		}
+	}
+
+	// merged are the duplicate options to replace, by property, then by
+	// value
+	merged := map[string]map[string]string{}
+	var optionFindings []*Finding
+	if enabled(DuplicateOption) {
+		for _, name := range append(props[notiontypes.ColumnTypeSelect], props[notiontypes.ColumnMultiSelect]...) {
+			_, col := schemaProperty(collection, name)
+			first := map[string]string{}
+			for _, o := range col.Options {
+				k := strings.ToLower(strings.TrimSpace(o.Value))
+				canonical, ok := first[k]
+				if !ok {
+					first[k] = o.Value
+					continue
+				}
+				f := &Finding{
+					Check: DuplicateOption, Property: name, Value: o.Value,
+					Message: fmt.Sprintf("option %q duplicates %q", o.Value, canonical),
+				}
+				if policy.Fixes[DuplicateOption] == FixMerge {
+					if merged[name] == nil {
+						merged[name] = map[string]string{}
+					}
+					merged[name][o.Value] = canonical
+				}
+				optionFindings = append(optionFindings, f)
+			}
+		}
+	}
+	r.Findings = append(r.Findings, optionFindings...)
+
+	var rows []notion.Row
+	err = c.ForEachRow(collection.ID, notion.RowQuery{}, func(row notion.Row) error {
+		rows = append(rows, row)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.Rows = len(rows)
+
+	related := map[string]relatedState{}
+	if enabled(OrphanedRelation) || enabled(UnreadableRelation) {
+		var ids []string
+		for _, row := range rows {
+			for _, name := range props[notiontypes.ColumnTypeRelation] {
+				if p := row.Property(name); p != nil {
+					for _, id := range p.PageIDs() {
+						if _, ok := related[id]; !ok {
+							related[id] = relatedAlive
+							ids = append(ids, id)
+						}
+					}
+				}
+			}
+		}
+		if err := loadRelated(c, related, ids); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, row := range rows {
+		title := ""
+		if p := row.Property("title"); p != nil {
+			title = strings.TrimSpace(p.Text())
+		}
+		var found []*Finding
+		update := map[string]string{}
+		add := func(f *Finding, fix, value string) {
+			f.RowID, f.Row = row.ID, title
+			if fix != "" {
+				f.Fixed = fix
+				update[f.Property] = value
+			}
+			found = append(found, f)
+		}
+
+		if enabled(EmptyTitle) && title == "" {
+			_, col := schemaProperty(collection, "title")
+			f := &Finding{Check: EmptyTitle, Message: "row has no title"}
+			if col != nil {
+				f.Property = col.Name
+			}
+			if policy.Fixes[EmptyTitle] == FixSetTitle && col != nil {
+				t := policy.Title
+				if t == "" {
+					t = "Untitled"
+				}
+				add(f, FixSetTitle, t)
+			} else {
+				add(f, "", "")
+			}
+		}
+
+		for _, name := range props[notiontypes.ColumnTypeRelation] {
+			p := row.Property(name)
+			if p == nil {
+				continue
+			}
+			var kept, orphans, unreadable []string
+			for _, id := range p.PageIDs() {
+				switch related[id] {
+				case relatedDeleted:
+					orphans = append(orphans, id)
+					continue
+				case relatedUnreadable:
+					unreadable = append(unreadable, id)
+				}
+				kept = append(kept, id)
+			}
+			if enabled(OrphanedRelation) && len(orphans) > 0 {
+				f := &Finding{
+					Check: OrphanedRelation, Property: name, Value: strings.Join(orphans, ","),
+					Message: fmt.Sprintf("relation to %d deleted pages", len(orphans)),
+				}
+				if policy.Fixes[OrphanedRelation] == FixRemove {
+					add(f, FixRemove, strings.Join(kept, ","))
+				} else {
+					add(f, "", "")
+				}
+			}
+			if enabled(UnreadableRelation) && len(unreadable) > 0 {
+				add(&Finding{
+					Check: UnreadableRelation, Property: name, Value: strings.Join(unreadable, ","),
+					Message: fmt.Sprintf("relation to %d pages that can't be read", len(unreadable)),
+				}, "", "")
+			}
+		}
+
+		for _, name := range props[notiontypes.ColumnTypeURL] {
+			p := row.Property(name)
+			if !enabled(InvalidURL) || p == nil {
+				continue
+			}
+			v := strings.TrimSpace(p.Text())
+			if v == "" || validURL(v) {
+				continue
+			}
+			f := &Finding{Check: InvalidURL, Property: name, Value: v, Message: "not an absolute URL"}
+			switch policy.Fixes[InvalidURL] {
+			case FixHTTPS:
+				fixed := ""
+				if !strings.Contains(v, "://") && validURL("https://"+v) && strings.Contains(v, ".") {
+					fixed = "https://" + v
+				}
+				add(f, FixHTTPS, fixed)
+			case FixClear:
+				add(f, FixClear, "")
+			default:
+				add(f, "", "")
+			}
+		}
+
+		for _, name := range props[notiontypes.ColumnTypeNumber] {
+			rng, ok := policyRange(policy.Ranges, name)
+			p := row.Property(name)
+			if !ok || !enabled(OutOfRange) || p == nil {
+				continue
+			}
+			v := strings.TrimSpace(p.Text())
+			n, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			bound := n
+			if rng.Min != nil && n < *rng.Min {
+				bound = *rng.Min
+			}
+			if rng.Max != nil && n > *rng.Max {
+				bound = *rng.Max
+			}
+			if bound == n {
+				continue
+			}
+			f := &Finding{Check: OutOfRange, Property: name, Value: v, Message: "outside " + rng.String()}
+			switch policy.Fixes[OutOfRange] {
+			case FixClamp:
+				add(f, FixClamp, strconv.FormatFloat(bound, 'f', -1, 64))
+			case FixClear:
+				add(f, FixClear, "")
+			default:
+				add(f, "", "")
+			}
+		}
+
+		// rows using merged options are rewritten silently, the finding is
+		// the option
+		for name, replace := range merged {
+			p := row.Property(name)
+			if p == nil || p.Text() == "" {
+				continue
+			}
+			values := strings.Split(p.Text(), ",")
+			var kept []string
+			changed := false
+			for _, v := range values {
+				if canonical, ok := replace[v]; ok {
+					v, changed = canonical, true
+				}
+				if !contains(kept, v) {
+					kept = append(kept, v)
+				}
+			}
+			if changed {
+				update[name] = strings.Join(kept, ",")
+			}
+		}
+
+		if len(update) > 0 {
+			if err := c.UpdateRow(collection.ID, row.ID, update); err != nil {
+				return nil, errors.Wrapf(err, "fixing row %v", row.ID)
+			}
+		}
+		r.Findings = append(r.Findings, found...)
+	}
+
+	for name, replace := range merged {
+		_, col := schemaProperty(collection, name)
+		var options []*notiontypes.CollectionColumnOption
+		for _, o := range col.Options {
+			if _, ok := replace[o.Value]; !ok {
+				options = append(options, o)
+			}
+		}
+		if err := c.SetPropertyOptions(collection.ID, name, options); err != nil {
+			return nil, errors.Wrapf(err, "merging options of %q", name)
+		}
+		for _, f := range optionFindings {
+			if f.Property == name {
+				f.Fixed = FixMerge
+			}
+		}
+	}
+	return r, nil
+}
+
+// relatedState is what's known of a related page.
+type relatedState int
+
+const (
+	relatedAlive relatedState = iota
+	relatedDeleted
+	// the page doesn't exist or isn't shared with the user, which notion
+	// doesn't tell apart
+	relatedUnreadable
+)
+
+// loadRelated sets the state of the pages ids, loaded in batches, in
+// related.
+func loadRelated(c *notion.Client, related map[string]relatedState, ids []string) error {
+	const batch = 100
+	for len(ids) > 0 {
+		n := len(ids)
+		if n > batch {
+			n = batch
+		}
+		records := make([]notion.Record, n)
+		for i, id := range ids[:n] {
+			records[i] = notion.Record{Table: notiontypes.TableBlock, ID: id}
+		}
+		results, err := c.GetRecordValues(records...)
+		if err != nil {
+			return errors.Wrap(err, "getting related pages")
+		}
+		for i, id := range ids[:n] {
+			switch {
+			case i >= len(results) || results[i] == nil || results[i].Value == nil:
+				related[id] = relatedUnreadable
+			case !results[i].Value.Alive:
+				related[id] = relatedDeleted
+			}
+		}
+		ids = ids[n:]
+	}
+	return nil
+}
+
+func schemaProperty(collection *notiontypes.Collection, name string) (string, *notiontypes.CollectionColumnInfo) {
+	if col, ok := collection.CollectionSchema[name]; ok {
+		return name, col
+	}
+	for id, col := range collection.CollectionSchema {
+		if strings.EqualFold(col.Name, name) {
+			return id, col
+		}
+	}
+	return "", nil
+}
+
+func policyRange(ranges map[string]Range, name string) (Range, bool) {
+	for n, r := range ranges {
+		if strings.EqualFold(n, name) {
+			return r, true
+		}
+	}
+	return Range{}, false
+}
+
+func (r Range) String() string {
+	bound := func(b *float64) string {
+		if b == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*b, 'f', -1, 64)
+	}
+	return "[" + bound(r.Min) + ", " + bound(r.Max) + "]"
+}
+
+// validURL reports whether v is an absolute URL with a host, or a mailto
+// or tel URL.
+func validURL(v string) bool {
+	u, err := url.Parse(v)
+	if err != nil || u.Scheme == "" {
+		return false
+	}
+	switch u.Scheme {
+	case "mailto", "tel":
+		return u.Opaque != ""
+	}
+	return u.Host != "" && !strings.ContainsAny(u.Host, " \t")
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteText writes the findings one per line, followed by a summary.
+func (r *Report) WriteText(w io.Writer) error {
+	for _, f := range r.Findings {
+		where := "schema"
+		if f.RowID != "" {
+			title := f.Row
+			if title == "" {
+				title = "Untitled"
+			}
+			where = fmt.Sprintf("%s (%s)", title, f.RowID)
+		}
+		line := fmt.Sprintf("%s: %s: %s", where, f.Property, f.Message)
+		if f.Value != "" {
+			line += fmt.Sprintf(" %q", f.Value)
+		}
+		line += " [" + f.Check + "]"
+		if f.Fixed != "" {
+			line += " fixed: " + f.Fixed
+		}
+		fmt.Fprintln(w, line)
+	}
+	_, err := fmt.Fprintf(w, "%d rows checked, %d findings, %d fixed\n", r.Rows, len(r.Findings), len(r.Findings)-r.Unfixed())
+	return err
+}
+
+// WriteJSON writes the report as JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
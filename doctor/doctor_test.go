@@ -0,0 +1,141 @@
+package doctor
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tmc/notion/notiontest"
+	"github.com/tmc/notion/notiontypes"
+)
+
+const (
+	row1    = "4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6a"
+	row2    = "4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6b"
+	live    = "4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6c"
+	deleted = "4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6d"
+	missing = "4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+)
+
+func relation(ids ...string) []interface{} {
+	var v []interface{}
+	for i, id := range ids {
+		if i > 0 {
+			v = append(v, []interface{}{","})
+		}
+		v = append(v, []interface{}{notiontypes.InlineAt, []interface{}{[]interface{}{"p", id}}})
+	}
+	return v
+}
+
+func TestFix(t *testing.T) {
+	s := notiontest.NewServer()
+	defer s.Close()
+	s.AddRecord(notiontypes.TableCollection, "db", map[string]interface{}{
+		"schema": map[string]interface{}{
+			"title": map[string]interface{}{"name": "Name", "type": "title"},
+			"rel":   map[string]interface{}{"name": "Related", "type": "relation"},
+			"url":   map[string]interface{}{"name": "Link", "type": "url"},
+			"est":   map[string]interface{}{"name": "Estimate", "type": "number"},
+			"tags": map[string]interface{}{"name": "Tags", "type": "multi_select", "options": []interface{}{
+				map[string]string{"id": "1", "value": "Bug", "color": "red"},
+				map[string]string{"id": "2", "value": "bug ", "color": "blue"},
+				map[string]string{"id": "3", "value": "UI", "color": "green"},
+			}},
+		},
+	})
+	for i, r := range []struct {
+		id    string
+		props map[string]interface{}
+	}{
+		{row1, map[string]interface{}{
+			"title": [][]string{{"Fine"}},
+			"rel":   relation(live),
+			"url":   [][]string{{"https://example.com"}},
+			"est":   [][]string{{"8"}},
+			"tags":  [][]string{{"Bug,UI"}},
+		}},
+		{row2, map[string]interface{}{
+			"rel":  relation(live, deleted, missing),
+			"url":  [][]string{{"example.com/x"}},
+			"est":  [][]string{{"100"}},
+			"tags": [][]string{{"bug ,Bug"}},
+		}},
+	} {
+		s.AddBlock(&notiontypes.Block{
+			ID:          r.id,
+			Type:        notiontypes.BlockPage,
+			ParentID:    "db",
+			ParentTable: notiontypes.TableCollection,
+			CreatedTime: int64(i),
+			Properties:  r.props,
+		})
+	}
+	s.AddBlock(&notiontypes.Block{ID: live, Type: notiontypes.BlockPage})
+	s.AddRecord(notiontypes.TableBlock, deleted, map[string]interface{}{"type": notiontypes.BlockPage, "alive": false})
+	c := s.Client()
+
+	max := 40.0
+	policy := &Policy{
+		Ranges: map[string]Range{"estimate": {Max: &max}},
+		Fixes: map[string]string{
+			EmptyTitle:       FixSetTitle,
+			OrphanedRelation: FixRemove,
+			InvalidURL:       FixHTTPS,
+			OutOfRange:       FixClamp,
+			DuplicateOption:  FixMerge,
+		},
+	}
+	r, err := Check(c, "db", policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var checks []string
+	for _, f := range r.Findings {
+		if f.Fixed != "" {
+			t.Errorf("Check fixed %+v", f)
+		}
+		checks = append(checks, f.Check)
+	}
+	want := []string{DuplicateOption, EmptyTitle, OrphanedRelation, UnreadableRelation, InvalidURL, OutOfRange}
+	if !reflect.DeepEqual(checks, want) {
+		t.Fatalf("Check found %v, want %v", checks, want)
+	}
+	if len(s.Operations()) != 0 {
+		t.Fatalf("Check submitted %d operations", len(s.Operations()))
+	}
+
+	r, err = Fix(c, "db", policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the missing page may exist but not be shared with the user
+	if n := r.Unfixed(); n != 1 {
+		t.Errorf("Unfixed() = %d, want 1", n)
+	}
+	collection, err := c.GetCollection("db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := c.GetBlock(row2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	for _, name := range []string{"title", "url", "est", "tags"} {
+		got[name] = collection.Property(b, name).Text()
+	}
+	wantRow := map[string]string{"title": "Untitled", "url": "https://example.com/x", "est": "40", "tags": "Bug"}
+	if !reflect.DeepEqual(got, wantRow) {
+		t.Errorf("fixed row = %v, want %v", got, wantRow)
+	}
+	if ids := collection.Property(b, "rel").PageIDs(); !reflect.DeepEqual(ids, []string{live, missing}) {
+		t.Errorf("fixed relation = %v, want %v", ids, []string{live, missing})
+	}
+	var options []string
+	for _, o := range collection.CollectionSchema["tags"].Options {
+		options = append(options, o.Value)
+	}
+	if want := []string{"Bug", "UI"}; !reflect.DeepEqual(options, want) {
+		t.Errorf("options = %v, want %v", options, want)
+	}
+}
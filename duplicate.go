@@ -0,0 +1,134 @@
+package notion
+
+import (
+	"regexp"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// DuplicateOptions configures DuplicatePageWithOptions.
+type DuplicateOptions struct {
+	// Title is the title of the copy. It defaults to the title of the page.
+	Title string
+	// Vars holds the values of template variables by name. Placeholders of
+	// the variables, like {{date}} or {{ owner }}, are replaced with their
+	// values in the title and text of the copy. Placeholders of unknown
+	// variables are left as is.
+	Vars map[string]string
+}
+
+// DuplicatePage copies the page pageID, including its sub-pages, to the end
+// of the page parentID and returns the id of the copy.
+func (c *Client) DuplicatePage(pageID, parentID string) (string, error) {
+	return c.DuplicatePageWithOptions(pageID, parentID, DuplicateOptions{})
+}
+
+// DuplicatePageWithOptions is like DuplicatePage, but configured by opts.
+// Databases are not copied: copies of their blocks show the original
+// database.
+func (c *Client) DuplicatePageWithOptions(pageID, parentID string, opts DuplicateOptions) (string, error) {
+	page, err := c.GetBlock(pageID)
+	if err != nil {
+		return "", errors.Wrapf(err, "getting page %v", pageID)
+	}
+	d := &duplicator{c: c, vars: opts.Vars, seen: map[string]bool{page.ID: true}}
+	dup, err := d.copy(page)
+	if err != nil {
+		return "", err
+	}
+	if opts.Title != "" {
+		dup.Properties["title"] = []interface{}{[]interface{}{d.substitute(opts.Title)}}
+	}
+	if err := c.AppendBlocks(parentID, dup); err != nil {
+		return "", err
+	}
+	return dup.ID, nil
+}
+
+type duplicator struct {
+	c    *Client
+	vars map[string]string
+	// ids of the pages copied so far
+	seen map[string]bool
+}
+
+var placeholder = regexp.MustCompile(`\{\{\s*(\w+)\s*\}\}`)
+
+func (d *duplicator) substitute(s string) string {
+	if len(d.vars) == 0 {
+		return s
+	}
+	return placeholder.ReplaceAllStringFunc(s, func(m string) string {
+		if v, ok := d.vars[placeholder.FindStringSubmatch(m)[1]]; ok {
+			return v
+		}
+		return m
+	})
+}
+
+// copy returns a copy of b for AppendBlocks, fetching the content of
+// sub-pages.
+func (d *duplicator) copy(b *notiontypes.Block) (*notiontypes.Block, error) {
+	dup := &notiontypes.Block{
+		Type:       b.Type,
+		Properties: make(map[string]interface{}, len(b.Properties)),
+		FormatRaw:  b.FormatRaw,
+	}
+	for k, v := range b.Properties {
+		dup.Properties[k] = d.substituteValue(v)
+	}
+	for _, child := range b.Content {
+		if child.IsPage() {
+			if d.seen[child.ID] {
+				continue
+			}
+			d.seen[child.ID] = true
+			page, err := d.c.GetBlock(child.ID)
+			if err != nil {
+				return nil, errors.Wrapf(err, "getting sub-page %v", child.ID)
+			}
+			child = page
+		}
+		childDup, err := d.copy(child)
+		if err != nil {
+			return nil, err
+		}
+		dup.Content = append(dup.Content, childDup)
+	}
+	return dup, nil
+}
+
+// substituteValue returns a copy of the property value v with variables
+// substituted in all its strings.
+func (d *duplicator) substituteValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string:
+		return d.substitute(v)
+	case []interface{}:
+		res := make([]interface{}, len(v))
+		for i, e := range v {
+			res[i] = d.substituteValue(e)
+		}
+		return res
+	case [][]string:
+		res := make([]interface{}, len(v))
+		for i, e := range v {
+			res[i] = d.substituteValue(e)
+		}
+		return res
+	case []string:
+		res := make([]interface{}, len(v))
+		for i, e := range v {
+			res[i] = d.substitute(e)
+		}
+		return res
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			res[k] = d.substituteValue(e)
+		}
+		return res
+	}
+	return v
+}
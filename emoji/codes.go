@@ -0,0 +1,493 @@
+package emoji
+
+// codes are the shortcodes of emoji, as named by GitHub. The first shortcode
+// of an emoji is the one returned by Shortcode.
+var codes = []struct {
+	code, emoji string
+}{
+	{"grinning", "😀"},
+	{"smiley", "😃"},
+	{"smile", "😄"},
+	{"grin", "😁"},
+	{"laughing", "😆"},
+	{"satisfied", "😆"},
+	{"sweat_smile", "😅"},
+	{"joy", "😂"},
+	{"rofl", "🤣"},
+	{"slightly_smiling_face", "🙂"},
+	{"upside_down_face", "🙃"},
+	{"wink", "😉"},
+	{"blush", "😊"},
+	{"innocent", "😇"},
+	{"heart_eyes", "😍"},
+	{"star_struck", "🤩"},
+	{"kissing_heart", "😘"},
+	{"yum", "😋"},
+	{"stuck_out_tongue", "😛"},
+	{"stuck_out_tongue_winking_eye", "😜"},
+	{"zany_face", "🤪"},
+	{"thinking", "🤔"},
+	{"shushing_face", "🤫"},
+	{"neutral_face", "😐"},
+	{"expressionless", "😑"},
+	{"no_mouth", "😶"},
+	{"smirk", "😏"},
+	{"unamused", "😒"},
+	{"roll_eyes", "🙄"},
+	{"grimacing", "😬"},
+	{"relieved", "😌"},
+	{"pensive", "😔"},
+	{"sleepy", "😪"},
+	{"sleeping", "😴"},
+	{"mask", "😷"},
+	{"nerd_face", "🤓"},
+	{"sunglasses", "😎"},
+	{"confused", "😕"},
+	{"worried", "😟"},
+	{"open_mouth", "😮"},
+	{"astonished", "😲"},
+	{"flushed", "😳"},
+	{"cry", "😢"},
+	{"sob", "😭"},
+	{"scream", "😱"},
+	{"disappointed", "😞"},
+	{"sweat", "😓"},
+	{"weary", "😩"},
+	{"tired_face", "😫"},
+	{"rage", "😡"},
+	{"angry", "😠"},
+	{"exploding_head", "🤯"},
+	{"skull", "💀"},
+	{"poop", "💩"},
+	{"hankey", "💩"},
+	{"clown_face", "🤡"},
+	{"ghost", "👻"},
+	{"alien", "👽"},
+	{"robot", "🤖"},
+	{"wave", "👋"},
+	{"raised_hand", "✋"},
+	{"ok_hand", "👌"},
+	{"v", "✌\ufe0f"},
+	{"crossed_fingers", "🤞"},
+	{"point_right", "👉"},
+	{"point_left", "👈"},
+	{"point_up", "☝\ufe0f"},
+	{"point_down", "👇"},
+	{"+1", "👍"},
+	{"thumbsup", "👍"},
+	{"-1", "👎"},
+	{"thumbsdown", "👎"},
+	{"fist", "✊"},
+	{"clap", "👏"},
+	{"raised_hands", "🙌"},
+	{"open_hands", "👐"},
+	{"handshake", "🤝"},
+	{"pray", "🙏"},
+	{"muscle", "💪"},
+	{"writing_hand", "✍\ufe0f"},
+	{"eyes", "👀"},
+	{"eye", "👁\ufe0f"},
+	{"brain", "🧠"},
+	{"baby", "👶"},
+	{"man", "👨"},
+	{"woman", "👩"},
+	{"bust_in_silhouette", "👤"},
+	{"busts_in_silhouette", "👥"},
+	{"family", "👪"},
+	{"heart", "❤\ufe0f"},
+	{"orange_heart", "🧡"},
+	{"yellow_heart", "💛"},
+	{"green_heart", "💚"},
+	{"blue_heart", "💙"},
+	{"purple_heart", "💜"},
+	{"black_heart", "🖤"},
+	{"broken_heart", "💔"},
+	{"sparkling_heart", "💖"},
+	{"100", "💯"},
+	{"anger", "💢"},
+	{"boom", "💥"},
+	{"collision", "💥"},
+	{"dizzy", "💫"},
+	{"speech_balloon", "💬"},
+	{"thought_balloon", "💭"},
+	{"zzz", "💤"},
+	{"dog", "🐶"},
+	{"cat", "🐱"},
+	{"mouse", "🐭"},
+	{"rabbit", "🐰"},
+	{"fox_face", "🦊"},
+	{"bear", "🐻"},
+	{"panda_face", "🐼"},
+	{"tiger", "🐯"},
+	{"lion", "🦁"},
+	{"cow", "🐮"},
+	{"pig", "🐷"},
+	{"frog", "🐸"},
+	{"monkey_face", "🐵"},
+	{"see_no_evil", "🙈"},
+	{"chicken", "🐔"},
+	{"penguin", "🐧"},
+	{"bird", "🐦"},
+	{"owl", "🦉"},
+	{"unicorn", "🦄"},
+	{"bee", "🐝"},
+	{"honeybee", "🐝"},
+	{"bug", "🐛"},
+	{"butterfly", "🦋"},
+	{"snail", "🐌"},
+	{"turtle", "🐢"},
+	{"snake", "🐍"},
+	{"octopus", "🐙"},
+	{"fish", "🐟"},
+	{"whale", "🐳"},
+	{"dolphin", "🐬"},
+	{"shark", "🦈"},
+	{"crab", "🦀"},
+	{"cactus", "🌵"},
+	{"christmas_tree", "🎄"},
+	{"evergreen_tree", "🌲"},
+	{"deciduous_tree", "🌳"},
+	{"palm_tree", "🌴"},
+	{"seedling", "🌱"},
+	{"herb", "🌿"},
+	{"four_leaf_clover", "🍀"},
+	{"fallen_leaf", "🍂"},
+	{"maple_leaf", "🍁"},
+	{"mushroom", "🍄"},
+	{"bouquet", "💐"},
+	{"rose", "🌹"},
+	{"tulip", "🌷"},
+	{"sunflower", "🌻"},
+	{"cherry_blossom", "🌸"},
+	{"earth_africa", "🌍"},
+	{"earth_americas", "🌎"},
+	{"earth_asia", "🌏"},
+	{"globe_with_meridians", "🌐"},
+	{"new_moon", "🌑"},
+	{"full_moon", "🌕"},
+	{"crescent_moon", "🌙"},
+	{"sunny", "☀\ufe0f"},
+	{"star", "⭐"},
+	{"star2", "🌟"},
+	{"sparkles", "✨"},
+	{"zap", "⚡"},
+	{"fire", "🔥"},
+	{"rainbow", "🌈"},
+	{"cloud", "☁\ufe0f"},
+	{"umbrella", "☔"},
+	{"snowflake", "❄\ufe0f"},
+	{"snowman", "⛄"},
+	{"droplet", "💧"},
+	{"ocean", "🌊"},
+	{"apple", "🍎"},
+	{"green_apple", "🍏"},
+	{"lemon", "🍋"},
+	{"banana", "🍌"},
+	{"watermelon", "🍉"},
+	{"grapes", "🍇"},
+	{"strawberry", "🍓"},
+	{"peach", "🍑"},
+	{"cherries", "🍒"},
+	{"avocado", "🥑"},
+	{"carrot", "🥕"},
+	{"corn", "🌽"},
+	{"bread", "🍞"},
+	{"cheese", "🧀"},
+	{"egg", "🥚"},
+	{"hamburger", "🍔"},
+	{"fries", "🍟"},
+	{"pizza", "🍕"},
+	{"taco", "🌮"},
+	{"burrito", "🌯"},
+	{"sushi", "🍣"},
+	{"ramen", "🍜"},
+	{"spaghetti", "🍝"},
+	{"cake", "🍰"},
+	{"birthday", "🎂"},
+	{"cookie", "🍪"},
+	{"chocolate_bar", "🍫"},
+	{"doughnut", "🍩"},
+	{"coffee", "☕"},
+	{"tea", "🍵"},
+	{"beer", "🍺"},
+	{"beers", "🍻"},
+	{"wine_glass", "🍷"},
+	{"cocktail", "🍸"},
+	{"champagne", "🍾"},
+	{"fork_and_knife", "🍴"},
+	{"soccer", "⚽"},
+	{"basketball", "🏀"},
+	{"football", "🏈"},
+	{"baseball", "⚾"},
+	{"tennis", "🎾"},
+	{"trophy", "🏆"},
+	{"medal_sports", "🏅"},
+	{"1st_place_medal", "🥇"},
+	{"2nd_place_medal", "🥈"},
+	{"3rd_place_medal", "🥉"},
+	{"dart", "🎯"},
+	{"video_game", "🎮"},
+	{"game_die", "🎲"},
+	{"jigsaw", "🧩"},
+	{"chess_pawn", "♟\ufe0f"},
+	{"art", "🎨"},
+	{"performing_arts", "🎭"},
+	{"musical_note", "🎵"},
+	{"notes", "🎶"},
+	{"microphone", "🎤"},
+	{"headphones", "🎧"},
+	{"guitar", "🎸"},
+	{"movie_camera", "🎥"},
+	{"clapper", "🎬"},
+	{"tada", "🎉"},
+	{"confetti_ball", "🎊"},
+	{"balloon", "🎈"},
+	{"gift", "🎁"},
+	{"ribbon", "🎀"},
+	{"ticket", "🎫"},
+	{"car", "🚗"},
+	{"red_car", "🚗"},
+	{"taxi", "🚕"},
+	{"bus", "🚌"},
+	{"truck", "🚚"},
+	{"bike", "🚲"},
+	{"train", "🚋"},
+	{"airplane", "✈\ufe0f"},
+	{"rocket", "🚀"},
+	{"helicopter", "🚁"},
+	{"boat", "⛵"},
+	{"sailboat", "⛵"},
+	{"ship", "🚢"},
+	{"anchor", "⚓"},
+	{"construction", "🚧"},
+	{"rotating_light", "🚨"},
+	{"vertical_traffic_light", "🚦"},
+	{"world_map", "🗺\ufe0f"},
+	{"compass", "🧭"},
+	{"mountain", "⛰\ufe0f"},
+	{"camping", "🏕\ufe0f"},
+	{"beach_umbrella", "🏖\ufe0f"},
+	{"house", "🏠"},
+	{"house_with_garden", "🏡"},
+	{"office", "🏢"},
+	{"hospital", "🏥"},
+	{"bank", "🏦"},
+	{"school", "🏫"},
+	{"factory", "🏭"},
+	{"building_construction", "🏗\ufe0f"},
+	{"classical_building", "🏛\ufe0f"},
+	{"stadium", "🏟\ufe0f"},
+	{"watch", "⌚"},
+	{"iphone", "📱"},
+	{"computer", "💻"},
+	{"keyboard", "⌨\ufe0f"},
+	{"desktop_computer", "🖥\ufe0f"},
+	{"printer", "🖨\ufe0f"},
+	{"computer_mouse", "🖱\ufe0f"},
+	{"floppy_disk", "💾"},
+	{"cd", "💿"},
+	{"dvd", "📀"},
+	{"camera", "📷"},
+	{"camera_flash", "📸"},
+	{"video_camera", "📹"},
+	{"tv", "📺"},
+	{"radio", "📻"},
+	{"telephone_receiver", "📞"},
+	{"phone", "☎\ufe0f"},
+	{"telephone", "☎\ufe0f"},
+	{"pager", "📟"},
+	{"fax", "📠"},
+	{"battery", "🔋"},
+	{"electric_plug", "🔌"},
+	{"bulb", "💡"},
+	{"flashlight", "🔦"},
+	{"candle", "🕯\ufe0f"},
+	{"moneybag", "💰"},
+	{"dollar", "💵"},
+	{"euro", "💶"},
+	{"credit_card", "💳"},
+	{"gem", "💎"},
+	{"balance_scale", "⚖\ufe0f"},
+	{"wrench", "🔧"},
+	{"hammer", "🔨"},
+	{"hammer_and_wrench", "🛠\ufe0f"},
+	{"nut_and_bolt", "🔩"},
+	{"gear", "⚙\ufe0f"},
+	{"chains", "⛓\ufe0f"},
+	{"toolbox", "🧰"},
+	{"magnet", "🧲"},
+	{"alembic", "⚗\ufe0f"},
+	{"microscope", "🔬"},
+	{"telescope", "🔭"},
+	{"satellite", "📡"},
+	{"syringe", "💉"},
+	{"pill", "💊"},
+	{"dna", "🧬"},
+	{"test_tube", "🧪"},
+	{"door", "🚪"},
+	{"bed", "🛏\ufe0f"},
+	{"key", "🔑"},
+	{"old_key", "🗝\ufe0f"},
+	{"lock", "🔒"},
+	{"unlock", "🔓"},
+	{"closed_lock_with_key", "🔐"},
+	{"shield", "🛡\ufe0f"},
+	{"bell", "🔔"},
+	{"no_bell", "🔕"},
+	{"mega", "📣"},
+	{"loudspeaker", "📢"},
+	{"mag", "🔍"},
+	{"mag_right", "🔎"},
+	{"link", "🔗"},
+	{"paperclip", "📎"},
+	{"paperclips", "🖇\ufe0f"},
+	{"straight_ruler", "📏"},
+	{"triangular_ruler", "📐"},
+	{"scissors", "✂\ufe0f"},
+	{"pushpin", "📌"},
+	{"round_pushpin", "📍"},
+	{"bookmark", "🔖"},
+	{"label", "🏷\ufe0f"},
+	{"pencil2", "✏\ufe0f"},
+	{"pen", "🖊\ufe0f"},
+	{"fountain_pen", "🖋\ufe0f"},
+	{"crayon", "🖍\ufe0f"},
+	{"paintbrush", "🖌\ufe0f"},
+	{"memo", "📝"},
+	{"pencil", "📝"},
+	{"briefcase", "💼"},
+	{"file_folder", "📁"},
+	{"open_file_folder", "📂"},
+	{"card_index_dividers", "🗂\ufe0f"},
+	{"date", "📅"},
+	{"calendar", "📆"},
+	{"spiral_calendar", "🗓\ufe0f"},
+	{"card_index", "📇"},
+	{"chart_with_upwards_trend", "📈"},
+	{"chart_with_downwards_trend", "📉"},
+	{"bar_chart", "📊"},
+	{"clipboard", "📋"},
+	{"page_facing_up", "📄"},
+	{"page_with_curl", "📃"},
+	{"bookmark_tabs", "📑"},
+	{"newspaper", "📰"},
+	{"notebook", "📓"},
+	{"notebook_with_decorative_cover", "📔"},
+	{"ledger", "📒"},
+	{"closed_book", "📕"},
+	{"green_book", "📗"},
+	{"blue_book", "📘"},
+	{"orange_book", "📙"},
+	{"books", "📚"},
+	{"book", "📖"},
+	{"open_book", "📖"},
+	{"scroll", "📜"},
+	{"email", "📧"},
+	{"e-mail", "📧"},
+	{"envelope", "✉\ufe0f"},
+	{"incoming_envelope", "📨"},
+	{"envelope_with_arrow", "📩"},
+	{"outbox_tray", "📤"},
+	{"inbox_tray", "📥"},
+	{"package", "📦"},
+	{"mailbox", "📫"},
+	{"postbox", "📮"},
+	{"ballot_box", "🗳\ufe0f"},
+	{"wastebasket", "🗑\ufe0f"},
+	{"file_cabinet", "🗄\ufe0f"},
+	{"hourglass", "⌛"},
+	{"hourglass_flowing_sand", "⏳"},
+	{"alarm_clock", "⏰"},
+	{"stopwatch", "⏱\ufe0f"},
+	{"timer_clock", "⏲\ufe0f"},
+	{"clock3", "🕒"},
+	{"checkered_flag", "🏁"},
+	{"triangular_flag_on_post", "🚩"},
+	{"white_flag", "🏳\ufe0f"},
+	{"black_flag", "🏴"},
+	{"pirate_flag", "🏴\u200d☠\ufe0f"},
+	{"rainbow_flag", "🏳\ufe0f\u200d🌈"},
+	{"white_check_mark", "✅"},
+	{"heavy_check_mark", "✔\ufe0f"},
+	{"ballot_box_with_check", "☑\ufe0f"},
+	{"x", "❌"},
+	{"negative_squared_cross_mark", "❎"},
+	{"heavy_plus_sign", "➕"},
+	{"heavy_minus_sign", "➖"},
+	{"question", "❓"},
+	{"grey_question", "❔"},
+	{"exclamation", "❗"},
+	{"heavy_exclamation_mark", "❗"},
+	{"grey_exclamation", "❕"},
+	{"bangbang", "‼\ufe0f"},
+	{"warning", "⚠\ufe0f"},
+	{"no_entry", "⛔"},
+	{"no_entry_sign", "🚫"},
+	{"stop_sign", "🛑"},
+	{"information_source", "ℹ\ufe0f"},
+	{"recycle", "♻\ufe0f"},
+	{"arrow_right", "➡\ufe0f"},
+	{"arrow_left", "⬅\ufe0f"},
+	{"arrow_up", "⬆\ufe0f"},
+	{"arrow_down", "⬇\ufe0f"},
+	{"arrows_counterclockwise", "🔄"},
+	{"repeat", "🔁"},
+	{"new", "🆕"},
+	{"free", "🆓"},
+	{"up", "🆙"},
+	{"cool", "🆒"},
+	{"ok", "🆗"},
+	{"sos", "🆘"},
+	{"top", "🔝"},
+	{"soon", "🔜"},
+	{"red_circle", "🔴"},
+	{"orange_circle", "🟠"},
+	{"yellow_circle", "🟡"},
+	{"green_circle", "🟢"},
+	{"large_blue_circle", "🔵"},
+	{"purple_circle", "🟣"},
+	{"black_circle", "⚫"},
+	{"white_circle", "⚪"},
+	{"red_square", "🟥"},
+	{"green_square", "🟩"},
+	{"blue_square", "🟦"},
+	{"large_orange_diamond", "🔶"},
+	{"large_blue_diamond", "🔷"},
+	{"small_red_triangle", "🔺"},
+	{"copyright", "©\ufe0f"},
+	{"registered", "®\ufe0f"},
+	{"tm", "™\ufe0f"},
+	{"hash", "#\ufe0f\u20e3"},
+	{"zero", "0\ufe0f\u20e3"},
+	{"one", "1\ufe0f\u20e3"},
+	{"two", "2\ufe0f\u20e3"},
+	{"three", "3\ufe0f\u20e3"},
+	{"four", "4\ufe0f\u20e3"},
+	{"five", "5\ufe0f\u20e3"},
+	{"six", "6\ufe0f\u20e3"},
+	{"seven", "7\ufe0f\u20e3"},
+	{"eight", "8\ufe0f\u20e3"},
+	{"nine", "9\ufe0f\u20e3"},
+	{"keycap_ten", "🔟"},
+	{"abc", "🔤"},
+	{"1234", "🔢"},
+	{"symbols", "🔣"},
+	{"cyclone", "🌀"},
+	{"beginner", "🔰"},
+	{"trident", "🔱"},
+	{"name_badge", "📛"},
+	{"o", "⭕"},
+	{"infinity", "♾\ufe0f"},
+	{"peace_symbol", "☮\ufe0f"},
+	{"yin_yang", "☯\ufe0f"},
+	{"atom_symbol", "⚛\ufe0f"},
+	{"radioactive", "☢\ufe0f"},
+	{"biohazard", "☣\ufe0f"},
+	{"mute", "🔇"},
+	{"sound", "🔉"},
+	{"loud_sound", "🔊"},
+	{"lock_with_ink_pen", "🔏"},
+	{"crystal_ball", "🔮"},
+	{"nazar_amulet", "🧿"},
+	{"moyai", "🗿"},
+}
@@ -0,0 +1,72 @@
+// Package emoji converts between emoji shortcodes such as :rocket:, as used
+// by GitHub, Slack and markdown front-matter, and the literal emoji notion
+// stores in page icons and callouts.
+package emoji
+
+import (
+	"regexp"
+	"strings"
+)
+
+// variationSelector asks for the emoji presentation of a character. Notion
+// stores some emoji with it, e.g. "✉️", which are looked up without it.
+const variationSelector = "\ufe0f"
+
+var (
+	byCode  = make(map[string]string, len(codes))
+	byEmoji = make(map[string]string, len(codes))
+)
+
+func init() {
+	for _, c := range codes {
+		byCode[c.code] = c.emoji
+		e := strings.Replace(c.emoji, variationSelector, "", -1)
+		if _, ok := byEmoji[e]; !ok {
+			byEmoji[e] = c.code
+		}
+	}
+}
+
+// Lookup returns the emoji of shortcode, with or without its colons, e.g.
+// "🚀" for ":rocket:", and whether the shortcode is known.
+func Lookup(shortcode string) (string, bool) {
+	e, ok := byCode[strings.Trim(shortcode, ":")]
+	return e, ok
+}
+
+// Shortcode returns the shortcode of emoji, with colons, e.g. ":rocket:"
+// for "🚀", and whether there is one. Emoji with several shortcodes get the
+// most common one.
+func Shortcode(emoji string) (string, bool) {
+	code, ok := byEmoji[strings.Replace(emoji, variationSelector, "", -1)]
+	if !ok {
+		return "", false
+	}
+	return ":" + code + ":", true
+}
+
+var shortcodeRE = regexp.MustCompile(`:[a-z0-9_+-]+:`)
+
+// Replace returns s with the known shortcodes it contains replaced by their
+// emoji. Unknown shortcodes are kept.
+func Replace(s string) string {
+	return shortcodeRE.ReplaceAllStringFunc(s, func(code string) string {
+		if e, ok := Lookup(code); ok {
+			return e
+		}
+		return code
+	})
+}
+
+// Icon returns the page or callout icon for s: the emoji of s if it's a
+// known shortcode, and s unchanged otherwise, e.g. when it's an emoji or
+// the URL of an image.
+func Icon(s string) string {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, ":") && strings.HasSuffix(s, ":") {
+		if e, ok := Lookup(s); ok {
+			return e
+		}
+	}
+	return s
+}
@@ -0,0 +1,34 @@
+package emoji
+
+import "testing"
+
+func TestEmoji(t *testing.T) {
+	if e, ok := Lookup(":rocket:"); !ok || e != "🚀" {
+		t.Errorf("Lookup(:rocket:) = %q, %v", e, ok)
+	}
+	if e, ok := Lookup("file_folder"); !ok || e != "📁" {
+		t.Errorf("Lookup(file_folder) = %q, %v", e, ok)
+	}
+	for e, want := range map[string]string{
+		"👍":  ":+1:",
+		"✉️": ":envelope:", // with variation selector, as stored by notion
+		"✉":  ":envelope:",
+	} {
+		if code, ok := Shortcode(e); !ok || code != want {
+			t.Errorf("Shortcode(%q) = %q, %v, want %q", e, code, ok, want)
+		}
+	}
+	if got := Replace("ship it :rocket: :not_an_emoji: at 10:30"); got != "ship it 🚀 :not_an_emoji: at 10:30" {
+		t.Errorf("Replace = %q", got)
+	}
+	for s, want := range map[string]string{
+		":memo:":                    "📝",
+		"📁":                         "📁",
+		"https://example.com/a.png": "https://example.com/a.png",
+		":unknown:":                 ":unknown:",
+	} {
+		if got := Icon(s); got != want {
+			t.Errorf("Icon(%q) = %q, want %q", s, got, want)
+		}
+	}
+}
@@ -12,3 +12,16 @@ type Error struct {
 func (e *Error) Error() string {
 	return fmt.Sprintf("notion: %v %v '%.100s'", e.StatusCode, e.URL, e.Body)
 }
+
+// AccessError is returned for blocks the user can't read, e.g. pages of a
+// shared workspace that weren't shared with them.
+type AccessError struct {
+	ID string
+	// Role is the role of the user on the block, typically
+	// notiontypes.RoleNone.
+	Role string
+}
+
+func (e *AccessError) Error() string {
+	return fmt.Sprintf("notion: no access to block %v (role %q)", e.ID, e.Role)
+}
@@ -0,0 +1,132 @@
+package eventbus
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tmc/notion/watch"
+)
+
+var event = &watch.Event{
+	Type:   watch.PageEdited,
+	PageID: "4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e",
+	Title:  "Plans",
+	Time:   time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC),
+}
+
+// natsServer accepts one connection, speaks enough of the NATS protocol
+// for NATS and sends the publish messages it receives on the returned
+// channel. Publishing to subjects starting with "denied" fails.
+func natsServer(t *testing.T) (string, <-chan string) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	msgs := make(chan string, 10)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		fmt.Fprint(conn, "INFO {\"server_id\":\"test\"}\r\n")
+		r := bufio.NewReader(conn)
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			f := strings.Fields(line)
+			switch f[0] {
+			case "PING":
+				fmt.Fprint(conn, "PONG\r\n")
+			case "CONNECT":
+				if !strings.Contains(line, `"auth_token":"secret"`) {
+					fmt.Fprint(conn, "-ERR 'Authorization Violation'\r\n")
+					return
+				}
+			case "PUB":
+				var n int
+				fmt.Sscan(f[2], &n)
+				payload := make([]byte, n+2)
+				if _, err := io.ReadFull(r, payload); err != nil {
+					return
+				}
+				if strings.HasPrefix(f[1], "denied") {
+					fmt.Fprintf(conn, "-ERR 'Permissions Violation for Publish to %v'\r\n", f[1])
+					continue
+				}
+				msgs <- f[1] + " " + strings.TrimSpace(string(payload))
+			}
+		}
+	}()
+	return l.Addr().String(), msgs
+}
+
+func TestNATS(t *testing.T) {
+	addr, msgs := natsServer(t)
+	n := &NATS{URL: "nats://secret@" + addr}
+	defer n.Close()
+	if err := n.Send(event); err != nil {
+		t.Fatal(err)
+	}
+	b, _ := json.Marshal(event)
+	if got, want := <-msgs, "notion.page.edited "+string(b); got != want {
+		t.Errorf("got message %q, want %q", got, want)
+	}
+	n.Subject = "denied"
+	if err := n.Send(event); err == nil || !strings.Contains(err.Error(), "Permissions Violation") {
+		t.Errorf("got error %v, want permissions violation", err)
+	}
+
+	addr, _ = natsServer(t)
+	n = &NATS{URL: addr}
+	if err := n.Send(event); err == nil || !strings.Contains(err.Error(), "Authorization Violation") {
+		t.Errorf("got error %v, want authorization violation", err)
+	}
+}
+
+func TestKafka(t *testing.T) {
+	var got string
+	topic := "events"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/topics/"+topic || r.Header.Get("Content-Type") != "application/vnd.kafka.json.v2+json" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error_code":40401,"message":"Topic not found"}`)
+			return
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		got = string(b)
+		fmt.Fprint(w, `{"offsets":[{"partition":0,"offset":7}]}`)
+	}))
+	defer srv.Close()
+
+	k := &Kafka{URL: srv.URL + "/", Topic: "events"}
+	if err := k.Send(event); err != nil {
+		t.Fatal(err)
+	}
+	b, _ := json.Marshal(event)
+	if want := `{"records":[{"key":"` + event.PageID + `","value":` + string(b) + `}]}`; got != want {
+		t.Errorf("got request %s, want %s", got, want)
+	}
+	k.Topic = "missing"
+	if err := k.Send(event); err == nil || !strings.Contains(err.Error(), "Topic not found") {
+		t.Errorf("got error %v, want topic not found", err)
+	}
+	// names are escaped within the path
+	topic = "team%2Fevents"
+	k.Topic = "team/events"
+	if err := k.Send(event); err != nil {
+		t.Errorf("publishing to %v: %v", k.Topic, err)
+	}
+}
@@ -0,0 +1,87 @@
+package eventbus
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion/watch"
+)
+
+// Kafka publishes events as JSON records to a Kafka topic through a Kafka
+// REST Proxy (v2 API), such as Confluent's or Redpanda's. Records are keyed
+// by page id, so the events of a page stay in order within a partition.
+type Kafka struct {
+	// URL is the base URL of the REST proxy, e.g. "http://localhost:8082".
+	URL   string
+	Topic string
+	// Client defaults to a client timing out after 30 seconds.
+	// Authentication, if the proxy requires it, can be added by its
+	// Transport.
+	Client *http.Client
+}
+
+type kafkaRecord struct {
+	Key   string       `json:"key"`
+	Value *watch.Event `json:"value"`
+}
+
+type kafkaProduceRequest struct {
+	Records []kafkaRecord `json:"records"`
+}
+
+type kafkaProduceResponse struct {
+	Offsets []struct {
+		Partition int    `json:"partition"`
+		Offset    int64  `json:"offset"`
+		ErrorCode int    `json:"error_code"`
+		Error     string `json:"error"`
+	} `json:"offsets"`
+}
+
+var defaultClient = &http.Client{Timeout: 30 * time.Second}
+
+// Send publishes e.
+func (k *Kafka) Send(e *watch.Event) error {
+	body, err := json.Marshal(kafkaProduceRequest{Records: []kafkaRecord{{Key: e.PageID, Value: e}}})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", strings.TrimSuffix(k.URL, "/")+"/topics/"+url.PathEscape(k.Topic), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/vnd.kafka.json.v2+json")
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+	client := k.Client
+	if client == nil {
+		client = defaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "publishing to kafka")
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrap(err, "publishing to kafka")
+	}
+	if resp.StatusCode/100 != 2 {
+		return errors.Errorf("publishing to kafka topic %v: %v: %s", k.Topic, resp.Status, bytes.TrimSpace(b))
+	}
+	r := &kafkaProduceResponse{}
+	if err := json.Unmarshal(b, r); err != nil {
+		return errors.Wrap(err, "unmarshaling kafka produce response")
+	}
+	for _, o := range r.Offsets {
+		if o.Error != "" {
+			return errors.Errorf("publishing to kafka topic %v: %v", k.Topic, o.Error)
+		}
+	}
+	return nil
+}
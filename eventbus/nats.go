@@ -0,0 +1,185 @@
+// Package eventbus publishes watch events to message brokers.
+//
+// The publishers speak the brokers' wire or REST protocols directly and
+// only implement what publishing needs, so no client libraries are
+// required.
+package eventbus
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion/watch"
+)
+
+// DefaultSubject is the subject prefix of NATS events.
+const DefaultSubject = "notion"
+
+// NATS publishes events as JSON to a NATS server. Events are published to
+// the subject Subject followed by the event type, e.g.
+// "notion.page.created", so subscribers can pick events with wildcards like
+// "notion.row.>".
+//
+// Every publish is confirmed with a PING round trip, so errors such as
+// authorization violations are returned by Send. The connection is opened
+// on the first Send and reopened after errors.
+type NATS struct {
+	// URL is the address of the server, e.g. "nats://localhost:4222".
+	// Credentials in the URL are used for authentication: a user name
+	// alone is taken as a token.
+	URL string
+	// Subject defaults to DefaultSubject.
+	Subject string
+	// Timeout of connecting and of publishing, ten seconds by default.
+	Timeout time.Duration
+
+	mu   sync.Mutex
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+type natsConnect struct {
+	Verbose  bool   `json:"verbose"`
+	Pedantic bool   `json:"pedantic"`
+	Name     string `json:"name"`
+	Lang     string `json:"lang"`
+	Version  string `json:"version"`
+	User     string `json:"user,omitempty"`
+	Pass     string `json:"pass,omitempty"`
+	Token    string `json:"auth_token,omitempty"`
+}
+
+// Send publishes e.
+func (n *NATS) Send(e *watch.Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	subject := n.Subject
+	if subject == "" {
+		subject = DefaultSubject
+	}
+	subject += "." + string(e.Type)
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.conn == nil {
+		if err := n.connect(); err != nil {
+			return errors.Wrap(err, "connecting to nats")
+		}
+	}
+	n.conn.SetDeadline(time.Now().Add(n.timeout()))
+	_, err = fmt.Fprintf(n.conn, "PUB %s %d\r\n%s\r\nPING\r\n", subject, len(body), body)
+	if err == nil {
+		err = n.pong()
+	}
+	if err != nil {
+		n.close()
+		return errors.Wrapf(err, "publishing to nats subject %v", subject)
+	}
+	return nil
+}
+
+// Close closes the connection to the server.
+func (n *NATS) Close() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.close()
+}
+
+func (n *NATS) close() error {
+	if n.conn == nil {
+		return nil
+	}
+	err := n.conn.Close()
+	n.conn, n.r = nil, nil
+	return err
+}
+
+func (n *NATS) timeout() time.Duration {
+	if n.Timeout > 0 {
+		return n.Timeout
+	}
+	return 10 * time.Second
+}
+
+func (n *NATS) connect() error {
+	raw := n.URL
+	if !strings.Contains(raw, "://") {
+		raw = "nats://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "4222")
+	}
+	conn, err := net.DialTimeout("tcp", host, n.timeout())
+	if err != nil {
+		return err
+	}
+	n.conn, n.r = conn, bufio.NewReader(conn)
+	conn.SetDeadline(time.Now().Add(n.timeout()))
+	line, err := n.r.ReadString('\n')
+	if err != nil {
+		n.close()
+		return err
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		n.close()
+		return errors.Errorf("unexpected greeting %q", strings.TrimSpace(line))
+	}
+	connect := natsConnect{Name: "notion", Lang: "go", Version: "1"}
+	if u.User != nil {
+		if pass, ok := u.User.Password(); ok {
+			connect.User, connect.Pass = u.User.Username(), pass
+		} else {
+			connect.Token = u.User.Username()
+		}
+	}
+	b, err := json.Marshal(connect)
+	if err != nil {
+		n.close()
+		return err
+	}
+	if _, err := fmt.Fprintf(conn, "CONNECT %s\r\nPING\r\n", b); err != nil {
+		n.close()
+		return err
+	}
+	if err := n.pong(); err != nil {
+		n.close()
+		return err
+	}
+	return nil
+}
+
+// pong reads protocol messages until the server's PONG, answering its
+// PINGs and returning its errors.
+func (n *NATS) pong() error {
+	for {
+		line, err := n.r.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := n.conn.Write([]byte("PONG\r\n")); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return errors.Errorf("nats: %v", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package export
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+// Heading is a heading of an exported page.
+type Heading struct {
+	// Level is 1 for headers, 2 for sub headers and 3 for sub sub headers.
+	Level  int
+	Text   string
+	Anchor string
+	Block  *notiontypes.Block
+}
+
+// Headings returns the headings of the page in order.
+func (p *Page) Headings() []*Heading {
+	return p.headings
+}
+
+// Anchor returns the anchor (HTML id) of the heading block b, or the empty
+// string if b is not a heading.
+func (p *Page) Anchor(b *notiontypes.Block) string {
+	return p.anchors[b.ID]
+}
+
+func headingLevel(typ string) int {
+	switch typ {
+	case notiontypes.BlockHeader:
+		return 1
+	case notiontypes.BlockSubHeader:
+		return 2
+	case notiontypes.BlockSubSubHeader:
+		return 3
+	}
+	return 0
+}
+
+// indexHeadings assigns anchors to the headings within blocks.
+func (p *Page) indexHeadings(blocks []*notiontypes.Block, used map[string]int) {
+	for _, b := range blocks {
+		if b.IsPage() {
+			continue
+		}
+		if level := headingLevel(b.Type); level > 0 {
+			text := plainText(b.InlineContent)
+			anchor := headingAnchor(text)
+			// duplicates get numbered like GitHub does: a, a-1, a-2
+			if n, ok := used[anchor]; ok {
+				used[anchor] = n + 1
+				anchor += "-" + strconv.Itoa(n+1)
+			}
+			used[anchor] = 0
+			p.anchors[b.ID] = anchor
+			p.headings = append(p.headings, &Heading{Level: level, Text: text, Anchor: anchor, Block: b})
+		}
+		p.indexHeadings(b.Content, used)
+	}
+}
+
+// headingAnchor returns the anchor of a heading with the given text. It
+// matches the ids GitHub and Hugo generate for Markdown headings, so that
+// Markdown output needs no explicit ids.
+func headingAnchor(text string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(text)) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteByte('-')
+		}
+	}
+	if b.Len() == 0 {
+		return "section"
+	}
+	return b.String()
+}
@@ -0,0 +1,37 @@
+package export
+
+import (
+	"testing"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+func TestIndexHeadings(t *testing.T) {
+	heading := func(typ, text string) *notiontypes.Block {
+		return &notiontypes.Block{ID: text + typ, Type: typ, InlineContent: []*notiontypes.InlineBlock{{Text: text}}}
+	}
+	page := &Page{Block: &notiontypes.Block{}, anchors: make(map[string]string)}
+	page.Content = []*notiontypes.Block{
+		heading(notiontypes.BlockHeader, "Getting Started!"),
+		heading(notiontypes.BlockSubHeader, "Usage"),
+		{Type: notiontypes.BlockToggle, Content: []*notiontypes.Block{
+			heading(notiontypes.BlockSubSubHeader, "Usage"),
+		}},
+		heading(notiontypes.BlockSubHeader, "Grüße & co"),
+		heading(notiontypes.BlockSubHeader, "???"),
+	}
+	page.indexHeadings(page.Content, make(map[string]int))
+	want := []string{"getting-started", "usage", "usage-1", "grüße--co", "section"}
+	headings := page.Headings()
+	if len(headings) != len(want) {
+		t.Fatalf("got %d headings, want %d", len(headings), len(want))
+	}
+	for i, h := range headings {
+		if h.Anchor != want[i] {
+			t.Errorf("heading %q: got anchor %q, want %q", h.Text, h.Anchor, want[i])
+		}
+	}
+	if headings[2].Level != 3 {
+		t.Errorf("got level %d, want 3", headings[2].Level)
+	}
+}
@@ -0,0 +1,193 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// Anki writes flashcards as Anki text files, imported with File > Import
+// into Basic notes: tab-separated front, back, deck and tags, the sides
+// being HTML.
+//
+// As a Renderer, it writes a card per toggle of each page, the question
+// being the toggle's text and the answer its content; toggles within
+// answers aren't cards. As a TableWriter, it writes a card per row of a
+// flashcards database.
+//
+// Anki's .apkg packages are SQLite databases, which this package doesn't
+// write.
+type Anki struct {
+	// Deck is the deck of cards for which DeckProperty isn't set, the
+	// title of their page (or database) by default.
+	Deck string
+	// DeckProperty is the property of pages (or rows) holding the deck of
+	// their cards, e.g. a select property.
+	DeckProperty string
+	// TagsProperty is the property of pages (or rows) holding the tags of
+	// their cards, e.g. a multi-select property.
+	TagsProperty string
+	// QuestionProperty and AnswerProperty are the properties of rows
+	// holding the sides of their cards, by default the title and "Answer".
+	QuestionProperty string
+	AnswerProperty   string
+
+	w    *csv.Writer
+	name string
+	// indexes of the columns of the current collection, -1 if not set
+	question, answer       int
+	deckColumn, tagsColumn int
+}
+
+type flashcard struct {
+	// Front and Back are HTML.
+	Front, Back string
+	Deck        string
+	Tags        []string
+}
+
+// Ext returns ".txt".
+func (a *Anki) Ext() string {
+	return ".txt"
+}
+
+// Render writes the cards of the toggles of page to w.
+func (a *Anki) Render(w io.Writer, page *Page) error {
+	deck := a.Deck
+	if deck == "" {
+		deck = page.Title
+	}
+	var tags []string
+	for _, p := range page.PageProperties {
+		switch {
+		case p.Name == a.DeckProperty && a.DeckProperty != "":
+			if v := p.Text(); v != "" {
+				deck = v
+			}
+		case p.Name == a.TagsProperty && a.TagsProperty != "":
+			tags = p.Values()
+		}
+	}
+	cw := newAnkiWriter(w)
+	h := &HTML{}
+	for _, card := range toggleCards(h.renderer(new(bytes.Buffer), page), page.Content) {
+		card.Deck, card.Tags = deck, tags
+		if err := writeFlashcard(cw, card); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// toggleCards returns the cards of the toggles in blocks, not descending
+// into sub-pages.
+func toggleCards(r *htmlRenderer, blocks []*notiontypes.Block) []*flashcard {
+	var cards []*flashcard
+	for _, b := range blocks {
+		if b.IsPage() {
+			continue
+		}
+		if b.Type != notiontypes.BlockToggle {
+			cards = append(cards, toggleCards(r, b.Content)...)
+			continue
+		}
+		front := r.inline(b.InlineContent)
+		if front == "" || len(b.Content) == 0 {
+			continue
+		}
+		buf := new(bytes.Buffer)
+		r.sub(buf, nil).renderBlocks(b.Content)
+		cards = append(cards, &flashcard{Front: front, Back: strings.TrimSpace(buf.String())})
+	}
+	return cards
+}
+
+// Begin implements TableWriter.
+func (a *Anki) Begin(w io.Writer, name string, columns []*Column) error {
+	a.w, a.name = newAnkiWriter(w), name
+	a.question, a.answer, a.deckColumn, a.tagsColumn = -1, -1, -1, -1
+	answer := a.AnswerProperty
+	if answer == "" {
+		answer = "Answer"
+	}
+	for i, col := range columns {
+		switch {
+		case a.QuestionProperty == "" && col.Type == notiontypes.ColumnTypeTitle,
+			a.QuestionProperty != "" && col.Name == a.QuestionProperty:
+			a.question = i
+		case col.Name == answer:
+			a.answer = i
+		case a.DeckProperty != "" && col.Name == a.DeckProperty:
+			a.deckColumn = i
+		case a.TagsProperty != "" && col.Name == a.TagsProperty:
+			a.tagsColumn = i
+		}
+	}
+	if a.question < 0 {
+		return errors.Errorf("anki: no question property %q", a.QuestionProperty)
+	}
+	if a.answer < 0 {
+		return errors.Errorf("anki: no answer property %q", answer)
+	}
+	return nil
+}
+
+// WriteRow implements TableWriter. Rows without a question or an answer
+// are skipped.
+func (a *Anki) WriteRow(row *notiontypes.Block, values []*notiontypes.PageProperty) error {
+	card := &flashcard{
+		Front: ankiText(values[a.question]),
+		Back:  ankiText(values[a.answer]),
+		Deck:  a.Deck,
+	}
+	if card.Front == "" || card.Back == "" {
+		return nil
+	}
+	if card.Deck == "" {
+		card.Deck = a.name
+	}
+	if a.deckColumn >= 0 {
+		if deck := cellText(values[a.deckColumn]); deck != "" {
+			card.Deck = deck
+		}
+	}
+	if a.tagsColumn >= 0 && values[a.tagsColumn] != nil {
+		card.Tags = values[a.tagsColumn].Values()
+	}
+	return writeFlashcard(a.w, card)
+}
+
+// End implements TableWriter.
+func (a *Anki) End() error {
+	a.w.Flush()
+	return a.w.Error()
+}
+
+// newAnkiWriter writes the header telling Anki the layout of the file to w,
+// and returns a writer of the cards.
+func newAnkiWriter(w io.Writer) *csv.Writer {
+	io.WriteString(w, "#separator:tab\n#html:true\n#notetype:Basic\n#deck column:3\n#tags column:4\n")
+	cw := csv.NewWriter(w)
+	cw.Comma = '\t'
+	return cw
+}
+
+func writeFlashcard(w *csv.Writer, card *flashcard) error {
+	tags := make([]string, len(card.Tags))
+	for i, t := range card.Tags {
+		// Anki tags are separated by spaces
+		tags[i] = strings.Join(strings.Fields(t), "_")
+	}
+	return w.Write([]string{card.Front, card.Back, card.Deck, strings.Join(tags, " ")})
+}
+
+// ankiText returns the value of p as HTML.
+func ankiText(p *notiontypes.PageProperty) string {
+	return strings.Replace(html.EscapeString(cellText(p)), "\n", "<br>", -1)
+}
@@ -0,0 +1,51 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/notiontypes"
+)
+
+func TestAnki(t *testing.T) {
+	text := func(s string) []*notiontypes.InlineBlock {
+		return []*notiontypes.InlineBlock{{Text: s}}
+	}
+	root := &notiontypes.Block{ID: "aa8fc126-6770-4e83-ad6c-3968dcfc9b80", Type: notiontypes.BlockPage, Title: "Capitals", Content: []*notiontypes.Block{
+		{Type: notiontypes.BlockText, InlineContent: text("Some intro")},
+		{Type: notiontypes.BlockToggle, InlineContent: text("France?"), Content: []*notiontypes.Block{
+			{Type: notiontypes.BlockText, InlineContent: text("Paris")},
+			{Type: notiontypes.BlockToggle, InlineContent: text("Not a card"), Content: []*notiontypes.Block{
+				{Type: notiontypes.BlockText, InlineContent: text("Île-de-France")},
+			}},
+		}},
+		{Type: notiontypes.BlockColumnList, Content: []*notiontypes.Block{
+			{Type: notiontypes.BlockColumn, Content: []*notiontypes.Block{
+				{Type: notiontypes.BlockToggle, InlineContent: text("Peru?"), Content: []*notiontypes.Block{
+					{Type: notiontypes.BlockText, InlineContent: text("Lima")},
+				}},
+			}},
+		}},
+		{Type: notiontypes.BlockToggle, InlineContent: text("No answer")},
+	}}
+	props := []*notiontypes.PageProperty{
+		{Name: "Deck", Type: notiontypes.ColumnTypeSelect, Value: text("Geography")},
+		{Name: "Tags", Type: notiontypes.ColumnMultiSelect, Value: text("capitals, south america")},
+	}
+
+	a := &Anki{DeckProperty: "Deck", TagsProperty: "Tags"}
+	e := NewExporter(nil, t.TempDir(), WithRenderer(a))
+	e.reset()
+	e.add(&notion.Page{Block: root, PageProperties: props}, nil)
+	buf := new(bytes.Buffer)
+	if err := a.Render(buf, e.order[0]); err != nil {
+		t.Fatal(err)
+	}
+	want := "#separator:tab\n#html:true\n#notetype:Basic\n#deck column:3\n#tags column:4\n" +
+		"France?\t\"<p>Paris</p>\n<details><summary>Not a card</summary>\n\n<p>Île-de-France</p>\n</details>\"\tGeography\tcapitals south_america\n" +
+		"Peru?\t<p>Lima</p>\tGeography\tcapitals south_america\n"
+	if buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf, want)
+	}
+}
@@ -0,0 +1,322 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// Asset is a file, such as an image, referenced by an exported page.
+type Asset struct {
+	// URL is the original location of the asset.
+	URL string
+	// Block is the block referencing the asset.
+	Block *notiontypes.Block
+	// Page is the exported page containing Block.
+	Page *Page
+	// Srcset optionally lists alternative versions of an image asset.
+	// It is set by AssetPolicies that produce them.
+	Srcset []ImageSource
+}
+
+// Name returns a file name for the asset that is unique within an export.
+func (a *Asset) Name() string {
+	ext := ""
+	if u, err := url.Parse(a.URL); err == nil {
+		ext = path.Ext(u.Path)
+	}
+	return strings.Replace(a.Block.ID, "-", "", -1) + ext
+}
+
+// SignedURL returns a URL for the asset that is accessible without
+// authentication, signing files hosted by notion as necessary.
+func (a *Asset) SignedURL() (string, error) {
+	if !isNotionHosted(a.URL) {
+		return a.URL, nil
+	}
+	urls, err := a.Page.exporter.client.GetSignedFileURLs(a.Block.ID, a.URL)
+	if err != nil {
+		return "", errors.Wrap(err, "signing asset url")
+	}
+	return urls[0], nil
+}
+
+// defaultClient downloads and uploads assets when no client is given.
+var defaultClient = &http.Client{Timeout: 30 * time.Second}
+
+// Open downloads the asset using client, or a client timing out after 30
+// seconds if client is nil.
+func (a *Asset) Open(client *http.Client) (io.ReadCloser, error) {
+	if client == nil {
+		client = defaultClient
+	}
+	u, err := a.SignedURL()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Get(u)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("export: downloading %v: %v", a.URL, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// AssetPolicy decides how assets are referenced from exported pages.
+type AssetPolicy interface {
+	// Resolve returns the URL (or path relative to the page) by which the
+	// exported page refers to the asset.
+	Resolve(a *Asset) (string, error)
+}
+
+// Hotlink references assets at their original location, using signed URLs
+// for files hosted by notion. Signed URLs expire after a while, so Hotlink is
+// best suited for exports that are consumed right away.
+type Hotlink struct{}
+
+// Resolve returns the signed URL of the asset.
+func (Hotlink) Resolve(a *Asset) (string, error) {
+	return a.SignedURL()
+}
+
+// Download downloads assets into the export directory and references them by relative path.
+type Download struct {
+	// Dir is the directory, relative to the export directory, assets are stored in.
+	// It defaults to "assets".
+	Dir string
+	// URLPrefix, if set, makes pages reference assets by URLPrefix followed
+	// by their path within Dir instead of by relative path, e.g. "/images/"
+	// for static site generators that serve Dir at that URL.
+	URLPrefix string
+	// Client is used to download assets. It defaults to a client timing out
+	// after 30 seconds.
+	Client *http.Client
+	// Images, if set, processes downloaded images. Its variants are
+	// stored next to each other and listed in the image's Srcset.
+	Images ImageProcessor
+}
+
+// Resolve downloads the asset, unless it already exists, and returns its path relative to the page.
+// Images are downloaded and processed every time if d.Images is set.
+func (d *Download) Resolve(a *Asset) (string, error) {
+	dir := d.Dir
+	if dir == "" {
+		dir = "assets"
+	}
+	if d.Images != nil && a.Block.IsImage() {
+		return d.processImage(a, dir)
+	}
+	rel := path.Join(dir, a.Name())
+	dst := filepath.Join(a.Page.exporter.dir, filepath.FromSlash(rel))
+	if _, err := os.Stat(dst); os.IsNotExist(err) {
+		if err := d.download(a, dst); err != nil {
+			return "", err
+		}
+	}
+	return d.ref(a, dir, rel), nil
+}
+
+// ref returns the reference to the file rel within dir from the page of a.
+func (d *Download) ref(a *Asset, dir, rel string) string {
+	if d.URLPrefix != "" {
+		return d.URLPrefix + strings.TrimPrefix(rel, path.Clean(dir)+"/")
+	}
+	return a.Page.Rel(rel)
+}
+
+func (d *Download) processImage(a *Asset, dir string) (string, error) {
+	r, err := a.Open(d.Client)
+	if err != nil {
+		return "", err
+	}
+	data, err := ioutil.ReadAll(r)
+	r.Close()
+	if err != nil {
+		return "", err
+	}
+	variants, err := d.Images.Process(data)
+	if err != nil {
+		return "", errors.Wrap(err, "processing image")
+	}
+	if len(variants) == 0 {
+		return "", errors.New("processing image: no variants produced")
+	}
+	name := a.Name()
+	base := strings.TrimSuffix(name, path.Ext(name))
+	var refs []string
+	for _, v := range variants {
+		ext := v.Ext
+		if ext == "" {
+			ext = path.Ext(name)
+		}
+		rel := path.Join(dir, base+v.Suffix+ext)
+		dst := filepath.Join(a.Page.exporter.dir, filepath.FromSlash(rel))
+		if err := writeFile(dst, bytes.NewReader(v.Data)); err != nil {
+			return "", err
+		}
+		ref := d.ref(a, dir, rel)
+		refs = append(refs, ref)
+		a.Srcset = append(a.Srcset, ImageSource{URL: ref, Width: v.Width})
+	}
+	if len(a.Srcset) == 1 {
+		a.Srcset = nil
+	}
+	return refs[0], nil
+}
+
+func (d *Download) download(a *Asset, dst string) error {
+	r, err := a.Open(d.Client)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	return writeFile(dst, r)
+}
+
+// writeFile writes the contents of r to dst through a temporary file, so
+// that interrupted writes never leave a partial dst behind.
+func writeFile(dst string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(filepath.Dir(dst), ".download")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), dst)
+}
+
+// BlobStore stores blobs, such as assets, and makes them publicly available.
+type BlobStore interface {
+	// Put stores the contents of r under key and returns its public URL.
+	Put(key string, r io.Reader, contentType string) (string, error)
+}
+
+// Upload copies assets into a BlobStore, such as an S3 or GCS bucket, and
+// references them by the URL returned by the store.
+type Upload struct {
+	Store BlobStore
+	// Prefix is prepended to the key of every uploaded asset.
+	Prefix string
+	// Client is used to download assets. It defaults to a client timing out
+	// after 30 seconds.
+	Client *http.Client
+}
+
+// Resolve uploads the asset and returns its public URL.
+func (u *Upload) Resolve(a *Asset) (string, error) {
+	r, err := a.Open(u.Client)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+	name := a.Name()
+	return u.Store.Put(u.Prefix+name, r, mime.TypeByExtension(path.Ext(name)))
+}
+
+// HTTPBlobStore is a BlobStore that uploads blobs with HTTP PUT requests to
+// BaseURL + key. Combined with an authenticating http.Client it works with
+// S3, GCS and most other object stores.
+type HTTPBlobStore struct {
+	BaseURL string
+	// PublicURL, if set, is used instead of BaseURL for the returned URLs.
+	PublicURL string
+	// Client defaults to a client timing out after 30 seconds.
+	Client *http.Client
+}
+
+// Put uploads r to BaseURL + key.
+func (s *HTTPBlobStore) Put(key string, r io.Reader, contentType string) (string, error) {
+	client := s.Client
+	if client == nil {
+		client = defaultClient
+	}
+	req, err := http.NewRequest("PUT", s.BaseURL+key, r)
+	if err != nil {
+		return "", err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return "", fmt.Errorf("export: uploading %v: %v", key, resp.Status)
+	}
+	if s.PublicURL != "" {
+		return s.PublicURL + key, nil
+	}
+	return s.BaseURL + key, nil
+}
+
+// assetURL returns the location of the asset referenced by b, if any.
+func assetURL(b *notiontypes.Block) string {
+	switch b.Type {
+	case notiontypes.BlockImage:
+		if b.Source != "" {
+			return b.Source
+		}
+		if b.FormatImage != nil {
+			return b.FormatImage.DisplaySource
+		}
+	case notiontypes.BlockFile, notiontypes.BlockAudio, notiontypes.BlockPDF:
+		return b.Source
+	}
+	return ""
+}
+
+func isNotionHosted(u string) bool {
+	return strings.Contains(u, "secure.notion-static.com")
+}
+
+// resolveAssets resolves the assets referenced by the blocks of page.
+func (e *Exporter) resolveAssets(page *Page, blocks []*notiontypes.Block) error {
+	for _, b := range blocks {
+		if u := assetURL(b); u != "" {
+			a := &Asset{URL: u, Block: b, Page: page}
+			if strings.HasPrefix(u, "/") {
+				a.URL = "https://www.notion.so" + u
+			}
+			ref, err := e.assets.Resolve(a)
+			if err != nil {
+				return errors.Wrapf(err, "resolving asset of block %v", b.ID)
+			}
+			page.assets[b.ID] = ref
+			if len(a.Srcset) > 0 {
+				page.srcsets[b.ID] = a.Srcset
+			}
+		}
+		if !b.IsPage() {
+			if err := e.resolveAssets(page, b.Content); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package export
+
+import (
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// Column is a property of a collection exported by ExportCollection.
+type Column struct {
+	ID   string
+	Name string
+	// e.g. notiontypes.ColumnTypeNumber
+	Type string
+}
+
+// TableWriter writes the rows of a collection in a tabular format, such
+// as CSV.
+type TableWriter interface {
+	// Ext returns the file extension of the output, including the dot.
+	Ext() string
+	// Begin starts writing the collection name with the given columns to w.
+	Begin(w io.Writer, name string, columns []*Column) error
+	// WriteRow writes a row, given its values in the order of the columns.
+	WriteRow(row *notiontypes.Block, values []*notiontypes.PageProperty) error
+	// End finishes the output.
+	End() error
+}
+
+// CollectionOptions controls ExportCollection.
+type CollectionOptions struct {
+	// ViewID is the view whose order rows are exported in.
+	ViewID string
+	// Workers is the number of pages of rows loaded concurrently, 4 by
+	// default. Rows are written in order all the same.
+	Workers int
+	// PageSize is the number of rows per page, 500 by default.
+	PageSize int
+}
+
+// ExportCollection writes the rows of the collection collectionID to w
+// with tw, in the order of the view opts.ViewID.
+//
+// Pages of rows are loaded concurrently, from offsets computed from the
+// number of rows when the export starts. Rows added or removed during the
+// export may be missed or exported twice.
+func ExportCollection(c *notion.Client, collectionID string, w io.Writer, tw TableWriter, opts CollectionOptions) error {
+	if opts.Workers <= 0 {
+		opts.Workers = 4
+	}
+	if opts.PageSize <= 0 {
+		opts.PageSize = 500
+	}
+	collection, err := c.GetCollection(collectionID)
+	if err != nil {
+		return err
+	}
+	columns := CollectionColumns(collection)
+	if err := tw.Begin(w, collectionName(collection), columns); err != nil {
+		return err
+	}
+	write := func(row *notiontypes.Block) error {
+		values := make([]*notiontypes.PageProperty, len(columns))
+		for i, col := range columns {
+			values[i] = collection.Property(row, col.ID)
+		}
+		return tw.WriteRow(row, values)
+	}
+	if opts.Workers == 1 {
+		err = c.ForEachRow(collectionID, notion.RowQuery{
+			ViewID:       opts.ViewID,
+			QueryOptions: notion.QueryOptions{PageSize: opts.PageSize},
+		}, func(r notion.Row) error { return write(r.Block) })
+	} else {
+		err = loadPages(c, collectionID, opts, write)
+	}
+	if err != nil {
+		return err
+	}
+	return tw.End()
+}
+
+type rowPage struct {
+	rows []*notiontypes.Block
+	err  error
+}
+
+// loadPages loads the pages of rows of a collection with opts.Workers
+// concurrent requests and calls write for each row in order. At most
+// opts.Workers pages are loaded but not yet written at any time.
+func loadPages(c *notion.Client, collectionID string, opts CollectionOptions, write func(*notiontypes.Block) error) error {
+	total, err := c.CountRows(collectionID, opts.ViewID)
+	if err != nil {
+		return err
+	}
+	n := (total + opts.PageSize - 1) / opts.PageSize
+	pages := make([]chan rowPage, n)
+	for i := range pages {
+		pages[i] = make(chan rowPage, 1)
+	}
+	slots := make(chan struct{}, opts.Workers)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for i := range pages {
+			select {
+			case slots <- struct{}{}:
+			case <-done:
+				return
+			}
+			go func(i int) {
+				rows, err := c.QueryCollectionWithOptions(collectionID, opts.ViewID, notion.QueryOptions{
+					Offset:   i * opts.PageSize,
+					Limit:    opts.PageSize,
+					PageSize: opts.PageSize,
+				})
+				pages[i] <- rowPage{rows, err}
+			}(i)
+		}
+	}()
+	for i, page := range pages {
+		p := <-page
+		if p.err != nil {
+			return errors.Wrapf(p.err, "loading rows %d to %d", i*opts.PageSize, (i+1)*opts.PageSize)
+		}
+		for _, row := range p.rows {
+			if err := write(row); err != nil {
+				return err
+			}
+		}
+		<-slots
+	}
+	return nil
+}
+
+// CollectionColumns returns the columns of collection: its title, then its
+// properties in the order of its pages in notion, then by name.
+func CollectionColumns(collection *notiontypes.Collection) []*Column {
+	order := make(map[string]int)
+	if collection.Format != nil {
+		for i, p := range collection.Format.CollectionPageProperties {
+			order[p.Property] = i + 1
+		}
+	}
+	var columns []*Column
+	for id, info := range collection.CollectionSchema {
+		columns = append(columns, &Column{ID: id, Name: info.Name, Type: info.Type})
+	}
+	sort.Slice(columns, func(i, j int) bool {
+		a, b := columns[i], columns[j]
+		if ta, tb := a.Type == notiontypes.ColumnTypeTitle, b.Type == notiontypes.ColumnTypeTitle; ta != tb {
+			return ta
+		}
+		if oi, oj := order[a.ID], order[b.ID]; oi != oj {
+			return oj == 0 || oi != 0 && oi < oj
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.ID < b.ID
+	})
+	return columns
+}
+
+func collectionName(collection *notiontypes.Collection) string {
+	var b strings.Builder
+	for _, part := range collection.Name {
+		if len(part) > 0 {
+			b.WriteString(part[0])
+		}
+	}
+	return b.String()
+}
+
+// cellText returns the value of p as text: dates as 2006-01-02, followed
+// by the time if any, people as user ids and other values as shown in
+// notion.
+func cellText(p *notiontypes.PageProperty) string {
+	if p == nil {
+		return ""
+	}
+	switch p.Type {
+	case notiontypes.ColumnTypeDate:
+		if d := p.Date(); d != nil {
+			if d.StartTime != nil {
+				return d.StartDate + " " + *d.StartTime
+			}
+			return d.StartDate
+		}
+	case notiontypes.ColumnTypePerson, notiontypes.ColumnTypeCreatedBy, notiontypes.ColumnTypeLastEditedBy:
+		var ids []string
+		for _, i := range p.Value {
+			if i.UserID != "" {
+				ids = append(ids, i.UserID)
+			}
+		}
+		if len(ids) > 0 {
+			return strings.Join(ids, ", ")
+		}
+	}
+	return p.Text()
+}
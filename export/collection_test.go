@@ -0,0 +1,154 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tmc/notion/notiontest"
+	"github.com/tmc/notion/notiontypes"
+	"github.com/tmc/notion/xlsx"
+)
+
+// newCollectionServer serves the collection "db" with n rows.
+func newCollectionServer(n int) *notiontest.Server {
+	s := notiontest.NewServer()
+	s.AddRecord(notiontypes.TableCollection, "db", map[string]interface{}{
+		"name": [][]string{{"Tasks 2024"}},
+		"schema": map[string]interface{}{
+			"title": map[string]string{"name": "Name", "type": "title"},
+			"pt":    map[string]string{"name": "Points", "type": "number"},
+			"ok":    map[string]string{"name": "Done", "type": "checkbox"},
+			"tg":    map[string]string{"name": "Tags", "type": "multi_select"},
+		},
+	})
+	for i := 0; i < n; i++ {
+		props := map[string]interface{}{
+			"title": [][]string{{fmt.Sprintf("Task %d, 'quoted'", i)}},
+			"pt":    [][]string{{fmt.Sprint(i)}},
+		}
+		if i%2 == 0 {
+			props["ok"] = [][]string{{"Yes"}}
+			props["tg"] = [][]string{{"a,b"}}
+		}
+		s.AddBlock(&notiontypes.Block{
+			ID:          fmt.Sprintf("row%05d", i),
+			Type:        notiontypes.BlockPage,
+			ParentID:    "db",
+			ParentTable: notiontypes.TableCollection,
+			CreatedTime: int64(i),
+			Properties:  props,
+		})
+	}
+	return s
+}
+
+func TestExportCollection(t *testing.T) {
+	s := newCollectionServer(7)
+	defer s.Close()
+	c := s.Client()
+
+	for _, tc := range []struct {
+		tw   TableWriter
+		want []string
+	}{
+		{&CSV{}, []string{
+			"Name,Done,Points,Tags",
+			`"Task 0, 'quoted'",Yes,0,"a,b"`,
+			`"Task 1, 'quoted'",,1,`,
+		}},
+		{&JSONL{}, []string{
+			`{"Done":true,"Name":"Task 0, 'quoted'","Points":0,"Tags":["a","b"],"id":"row00000"}`,
+			`{"Done":false,"Name":"Task 1, 'quoted'","Points":1,"Tags":null,"id":"row00001"}`,
+		}},
+		{&SQLite{}, []string{
+			"BEGIN TRANSACTION;",
+			`CREATE TABLE "tasks_2024" ("id" TEXT PRIMARY KEY, "Name" TEXT, "Done" INTEGER, "Points" REAL, "Tags" TEXT);`,
+			`INSERT INTO "tasks_2024" VALUES ('row00000', 'Task 0, ''quoted''', 1, 0, 'a,b');`,
+		}},
+		{&Anki{AnswerProperty: "Points", TagsProperty: "Tags"}, []string{
+			"#separator:tab",
+			"#html:true",
+			"#notetype:Basic",
+			"#deck column:3",
+			"#tags column:4",
+			"Task 0, &#39;quoted&#39;\t0\tTasks 2024\ta b",
+			"Task 1, &#39;quoted&#39;\t1\tTasks 2024\t",
+		}},
+	} {
+		for _, workers := range []int{1, 3} {
+			var buf bytes.Buffer
+			err := ExportCollection(c, "db", &buf, tc.tw, CollectionOptions{Workers: workers, PageSize: 2})
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			for i, want := range tc.want {
+				if lines[i] != want {
+					t.Errorf("%T with %d workers: line %d is\n%s\nwant\n%s", tc.tw, workers, i, lines[i], want)
+				}
+			}
+			last := -1
+			for i := 0; i < 7; i++ {
+				at := strings.Index(buf.String(), fmt.Sprintf("Task %d,", i))
+				if at <= last || strings.Count(buf.String(), fmt.Sprintf("Task %d,", i)) != 1 {
+					t.Errorf("%T with %d workers: task %d missing or out of order:\n%s", tc.tw, workers, i, buf.String())
+				}
+				last = at
+			}
+		}
+	}
+}
+
+// BenchmarkExportCollection exports 10,000 rows with a simulated latency
+// of 100ms per request, in pages of 500 rows. 4 workers export about 2.5
+// times as fast as a single one; the rest of the time goes to the fake
+// server, which handles one request at a time:
+//
+//	BenchmarkExportCollection/serial     2  2968238912 ns/op
+//	BenchmarkExportCollection/parallel   2  1172995497 ns/op
+func BenchmarkExportCollection(b *testing.B) {
+	s := newCollectionServer(10000)
+	defer s.Close()
+	s.Latency = 100 * time.Millisecond
+	c := s.Client()
+	for name, workers := range map[string]int{"serial": 1, "parallel": 4} {
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if err := ExportCollection(c, "db", ioutil.Discard, &CSV{}, CollectionOptions{Workers: workers}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestExportXLSX(t *testing.T) {
+	s := newCollectionServer(3)
+	defer s.Close()
+
+	var buf bytes.Buffer
+	if err := ExportCollection(s.Client(), "db", &buf, &XLSX{}, CollectionOptions{PageSize: 2}); err != nil {
+		t.Fatal(err)
+	}
+	sheets, err := xlsx.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"Name", "Done", "Points", "Tags"},
+		{"Task 0, 'quoted'", "true", "0", "a, b"},
+		{"Task 1, 'quoted'", "false", "1"},
+		{"Task 2, 'quoted'", "true", "2", "a, b"},
+	}
+	if len(sheets) != 1 || sheets[0].Name != "Tasks 2024" {
+		t.Fatalf("got sheets %+v", sheets)
+	}
+	if !reflect.DeepEqual(sheets[0].Rows, want) {
+		t.Errorf("got rows %q, want %q", sheets[0].Rows, want)
+	}
+}
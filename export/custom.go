@@ -0,0 +1,74 @@
+package export
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+// RenderFunc renders blocks of a type in place of a renderer, see
+// Markdown.RegisterRenderer and HTML.RegisterRenderer. It writes b in the
+// renderer's output format to w, or returns false to have b rendered as by
+// default.
+type RenderFunc func(w io.Writer, b *notiontypes.Block, ctx *BlockContext) bool
+
+// BlockContext gives RenderFuncs access to the page being rendered and to
+// the renderer, e.g. to render the content of a block.
+type BlockContext struct {
+	// Page is the page being rendered.
+	Page *Page
+
+	r blockRenderer
+}
+
+// Text returns the text of b in the output format, e.g. with its bold
+// parts enclosed in ** for Markdown.
+func (c *BlockContext) Text(b *notiontypes.Block) string {
+	return c.r.text(b)
+}
+
+// Children returns the content of b in the output format.
+func (c *BlockContext) Children(b *notiontypes.Block) string {
+	buf := new(bytes.Buffer)
+	c.r.sub(buf, nil).renderBlocks(b.Content)
+	return buf.String()
+}
+
+// Default returns b in the output format as if no RenderFunc was
+// registered for its type. RenderFuncs are still used for its content.
+func (c *BlockContext) Default(b *notiontypes.Block) string {
+	buf := new(bytes.Buffer)
+	c.r.sub(buf, b).renderBlock(b)
+	return buf.String()
+}
+
+// blockRenderer is implemented by the renderers supporting RenderFuncs.
+type blockRenderer interface {
+	text(b *notiontypes.Block) string
+	// sub returns a renderer writing to buf, at the top level, that
+	// renders skip as if no RenderFunc was registered for its type.
+	sub(buf *bytes.Buffer, skip *notiontypes.Block) blockRenderer
+	renderBlocks(blocks []*notiontypes.Block)
+	renderBlock(b *notiontypes.Block)
+}
+
+// renderFuncs holds RenderFuncs by block type.
+type renderFuncs map[string]RenderFunc
+
+func (m *renderFuncs) register(blockType string, fn RenderFunc) {
+	if *m == nil {
+		*m = make(renderFuncs)
+	}
+	(*m)[blockType] = fn
+}
+
+// render renders b with the RenderFunc registered for its type, if any and
+// b isn't skip, and reports whether it did.
+func (m renderFuncs) render(w io.Writer, b, skip *notiontypes.Block, page *Page, r blockRenderer) bool {
+	fn, ok := m[b.Type]
+	if !ok || b == skip {
+		return false
+	}
+	return fn(w, b, &BlockContext{Page: page, r: r})
+}
@@ -0,0 +1,67 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/notiontypes"
+)
+
+func TestRegisterRenderer(t *testing.T) {
+	text := func(s string) []*notiontypes.InlineBlock {
+		return []*notiontypes.InlineBlock{{Text: s, AttrFlags: notiontypes.AttrBold}}
+	}
+	callout := func(icon, s string, content ...*notiontypes.Block) *notiontypes.Block {
+		return &notiontypes.Block{Type: notiontypes.BlockCallout, InlineContent: text(s), FormatCallout: &notiontypes.FormatCallout{PageIcon: icon}, Content: content}
+	}
+	page := &notiontypes.Block{ID: "4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e", Type: notiontypes.BlockPage, Title: "Notes", Content: []*notiontypes.Block{
+		{Type: notiontypes.BlockBulletedList, InlineContent: text("item"), Content: []*notiontypes.Block{
+			callout("⚠️", "careful", &notiontypes.Block{Type: notiontypes.BlockText, InlineContent: text("really")}),
+		}},
+		callout("💡", "plain"),
+	}}
+	// callouts with a warning icon become admonitions, others are rendered
+	// as by default
+	admonition := func(open, close string) RenderFunc {
+		return func(w io.Writer, b *notiontypes.Block, ctx *BlockContext) bool {
+			if b.FormatCallout == nil || b.FormatCallout.PageIcon != "⚠️" {
+				return false
+			}
+			fmt.Fprintf(w, "%s%s\n%s%s", open, ctx.Text(b), ctx.Children(b), close)
+			return true
+		}
+	}
+	md := &Markdown{}
+	md.RegisterRenderer(notiontypes.BlockCallout, admonition("!!! warning ", ""))
+	h := &HTML{}
+	h.RegisterRenderer(notiontypes.BlockCallout, admonition(`<div class="warning">`, "</div>\n"))
+	h.RegisterRenderer(notiontypes.BlockText, func(w io.Writer, b *notiontypes.Block, ctx *BlockContext) bool {
+		io.WriteString(w, ctx.Default(b)+"<!-- text -->\n")
+		return true
+	})
+	for _, tc := range []struct {
+		r    Renderer
+		want string
+	}{
+		{md, "# Notes\n\n- **item**\n\n  !!! warning **careful**\n  **really**\n\n**plain**\n"},
+		{h, "<ul>\n<li><strong>item</strong>\n<div class=\"warning\"><strong>careful</strong>\n<p><strong>really</strong></p>\n<!-- text -->\n</div>\n</li>\n</ul>\n<p><strong>plain</strong></p>\n"},
+	} {
+		e := NewExporter(nil, t.TempDir(), WithRenderer(tc.r))
+		e.reset()
+		e.add(&notion.Page{Block: page}, nil)
+		var buf bytes.Buffer
+		if err := tc.r.Render(&buf, e.order[0]); err != nil {
+			t.Fatal(err)
+		}
+		got := buf.String()
+		if _, ok := tc.r.(*HTML); ok {
+			got = got[bytes.Index(buf.Bytes(), []byte("</h1>\n"))+6 : bytes.Index(buf.Bytes(), []byte("</article>"))]
+		}
+		if got != tc.want {
+			t.Errorf("%T: got\n%s\nwant\n%s", tc.r, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package export
+
+import (
+	"html"
+	"strings"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+// DatabaseTable is the content of a database view embedded in a page, see
+// WithDatabaseTables.
+type DatabaseTable struct {
+	// Name is the name of the database.
+	Name string
+	// Columns are the properties the view shows, in its order.
+	Columns []*Column
+	// Rows are the rows the view shows, filtered and sorted as in notion.
+	Rows []*DatabaseRow
+}
+
+// DatabaseRow is a row of a DatabaseTable.
+type DatabaseRow struct {
+	ID string
+	// Values are in the order of the columns of the table.
+	Values []*notiontypes.PageProperty
+}
+
+// WithDatabaseTables renders the database views embedded in pages, e.g.
+// linked databases, as static tables of the rows and properties of their
+// first view, the titles of rows linking to their pages. Views that can't
+// be read are left out, as they are without this option.
+func WithDatabaseTables() Option {
+	return func(e *Exporter) {
+		e.databaseTables = true
+	}
+}
+
+// Database returns the table of the database view block b, loaded with
+// WithDatabaseTables, or nil.
+func (p *Page) Database(b *notiontypes.Block) *DatabaseTable {
+	return p.Databases[b.ID]
+}
+
+// loadDatabases loads the tables of the database views within blocks.
+func (e *Exporter) loadDatabases(page *Page, blocks []*notiontypes.Block) {
+	for _, b := range blocks {
+		if b.IsPage() {
+			continue
+		}
+		if b.Type == notiontypes.BlockCollectionView && b.CollectionID != "" && len(b.ViewIDs) > 0 {
+			if t, err := e.databaseTable(b.CollectionID, b.ViewIDs[0]); err == nil {
+				if page.Databases == nil {
+					page.Databases = make(map[string]*DatabaseTable)
+				}
+				page.Databases[b.ID] = t
+			}
+		}
+		e.loadDatabases(page, b.Content)
+	}
+}
+
+func (e *Exporter) databaseTable(collectionID, viewID string) (*DatabaseTable, error) {
+	res, err := e.client.QueryView(collectionID, viewID)
+	if err != nil {
+		return nil, err
+	}
+	t := &DatabaseTable{Name: collectionName(res.Collection), Columns: viewColumns(res.View, res.Collection)}
+	for _, row := range res.Rows {
+		var props []*notiontypes.PageProperty
+		visible := make(map[string]bool, len(t.Columns))
+		for _, col := range t.Columns {
+			if p := res.Collection.Property(row, col.ID); p != nil {
+				props = append(props, p)
+				visible[col.ID] = true
+			}
+		}
+		if e.redactor != nil {
+			var remove bool
+			if e.removed[row.ID] {
+				continue
+			}
+			// rows are tagged by properties the view may hide
+			for _, p := range res.Collection.PageProperties(row) {
+				if !visible[p.ID] {
+					props = append(props, p)
+				}
+			}
+			// redactions of rows are audited with their pages, if exported
+			if props, remove = e.redactor.redactPage(row, props, new([]*Redaction)); remove {
+				continue
+			}
+		}
+		byID := make(map[string]*notiontypes.PageProperty, len(props))
+		for _, p := range props {
+			byID[p.ID] = p
+		}
+		r := &DatabaseRow{ID: row.ID, Values: make([]*notiontypes.PageProperty, len(t.Columns))}
+		for i, col := range t.Columns {
+			r.Values[i] = byID[col.ID]
+			if col.Type == notiontypes.ColumnTypeTitle && e.redactor != nil && r.Values[i] != nil {
+				// titles are masked in the row block
+				r.Values[i] = &notiontypes.PageProperty{ID: col.ID, Name: col.Name, Type: col.Type, Value: []*notiontypes.InlineBlock{{Text: row.Title}}}
+			}
+		}
+		t.Rows = append(t.Rows, r)
+	}
+	return t, nil
+}
+
+// viewColumns returns the columns view shows: its visible properties, in
+// its order, after the title, or all of them if the view doesn't list
+// them.
+func viewColumns(view *notiontypes.CollectionView, collection *notiontypes.Collection) []*Column {
+	all := CollectionColumns(collection)
+	var props []*notiontypes.TableProperty
+	if f := view.Format; f != nil {
+		switch view.Type {
+		case notiontypes.ViewBoard:
+			props = f.BoardProperties
+		case notiontypes.ViewCalendar:
+			props = f.CalendarProperties
+		case notiontypes.ViewList:
+			props = f.ListProperties
+		case notiontypes.ViewGallery:
+			props = f.GalleryProperties
+		case notiontypes.ViewTimeline:
+			props = f.TimelineProperties
+		default:
+			props = f.TableProperties
+		}
+	}
+	if len(props) == 0 {
+		return all
+	}
+	byID := make(map[string]*Column, len(all))
+	var columns []*Column
+	for _, col := range all {
+		byID[col.ID] = col
+		if col.Type == notiontypes.ColumnTypeTitle {
+			columns = append(columns, col)
+		}
+	}
+	for _, p := range props {
+		if col, ok := byID[p.Property]; ok && p.Visible && col.Type != notiontypes.ColumnTypeTitle {
+			columns = append(columns, col)
+		}
+	}
+	return columns
+}
+
+func (r *htmlRenderer) database(t *DatabaseTable) {
+	r.w(`<table class="database">` + "\n")
+	if t.Name != "" {
+		r.w("<caption>" + html.EscapeString(t.Name) + "</caption>\n")
+	}
+	r.w("<thead>\n<tr>")
+	for _, col := range t.Columns {
+		r.w("<th>" + html.EscapeString(col.Name) + "</th>")
+	}
+	r.w("</tr>\n</thead>\n<tbody>\n")
+	for _, row := range t.Rows {
+		r.w("<tr>")
+		for i, v := range row.Values {
+			text := html.EscapeString(cellText(v))
+			if t.Columns[i].Type == notiontypes.ColumnTypeTitle {
+				text = r.link(text, r.page.PageURL(row.ID))
+			}
+			r.w("<td>" + text + "</td>")
+		}
+		r.w("</tr>\n")
+	}
+	r.w("</tbody>\n</table>\n")
+}
+
+func (r *markdownRenderer) database(t *DatabaseTable, indent string) {
+	if len(t.Columns) == 0 {
+		return
+	}
+	header := make([]string, len(t.Columns))
+	for i, col := range t.Columns {
+		header[i] = markdownTableEscaper.Replace(escapeMarkdown(col.Name))
+	}
+	r.line(indent, "| "+strings.Join(header, " | ")+" |")
+	r.line(indent, "|"+strings.Repeat(" --- |", len(header)))
+	for _, row := range t.Rows {
+		cells := make([]string, len(row.Values))
+		for i, v := range row.Values {
+			text := escapeMarkdown(cellText(v))
+			if t.Columns[i].Type == notiontypes.ColumnTypeTitle {
+				text = r.link(text, r.page.PageURL(row.ID))
+			}
+			cells[i] = markdownTableEscaper.Replace(text)
+		}
+		r.line(indent, "| "+strings.Join(cells, " | ")+" |")
+	}
+}
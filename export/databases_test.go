@@ -0,0 +1,102 @@
+package export
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/notiontypes"
+)
+
+func TestDatabaseTables(t *testing.T) {
+	s := newCollectionServer(3)
+	defer s.Close()
+	s.AddRecord(notiontypes.TableCollectionView, "v", map[string]interface{}{
+		"type": notiontypes.ViewTable,
+		"format": map[string]interface{}{
+			"table_properties": []map[string]interface{}{
+				{"property": "title", "visible": true},
+				{"property": "tg", "visible": true},
+				{"property": "ok", "visible": false},
+				{"property": "pt", "visible": true},
+			},
+		},
+	})
+	const rootID = "aa8fc126-6770-4e83-ad6c-3968dcfc9b80"
+	root := &notiontypes.Block{ID: rootID, Type: notiontypes.BlockPage, Title: "Wiki", Content: []*notiontypes.Block{
+		{ID: "linked", Type: notiontypes.BlockCollectionView, CollectionID: "db", ViewIDs: []string{"v"}},
+	}}
+	row := &notiontypes.Block{ID: "row00001", Type: notiontypes.BlockPage, Title: "Task 1, 'quoted'"}
+
+	md := &Markdown{}
+	e := NewExporter(s.Client(), t.TempDir(), WithRenderer(md), WithDatabaseTables())
+	e.reset()
+	e.add(&notion.Page{Block: root}, nil)
+	e.add(&notion.Page{Block: row}, []string{rootID})
+	if err := e.writeAll(); err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadFile(filepath.Join(e.dir, e.order[0].Path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "| Name | Tags | Points |\n" +
+		"| --- | --- | --- |\n" +
+		"| [Task 0, 'quoted'](https://www.notion.so/row00000) | a,b | 0 |\n" +
+		"| [Task 1, 'quoted'](task-1-quoted-row00001.md) |  | 1 |\n" +
+		"| [Task 2, 'quoted'](https://www.notion.so/row00002) | a,b | 2 |\n"
+	if !strings.HasSuffix(string(b), want) {
+		t.Errorf("Markdown: got\n%s\nwant\n%s", b, want)
+	}
+
+	buf := new(bytes.Buffer)
+	h := &HTML{}
+	h.renderer(buf, e.order[0]).blocks(root.Content)
+	for _, s := range []string{
+		`<table class="database">` + "\n<caption>Tasks 2024</caption>\n<thead>\n<tr><th>Name</th><th>Tags</th><th>Points</th></tr>",
+		`<tr><td><a href="task-1-quoted-row00001.md">Task 1, &#39;quoted&#39;</a></td><td></td><td>1</td></tr>`,
+	} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("HTML is missing %s:\n%s", s, buf)
+		}
+	}
+}
+
+func TestDatabaseTablesHiddenTag(t *testing.T) {
+	s := newCollectionServer(3)
+	defer s.Close()
+	// hides the Done checkbox tagging rows 0 and 2
+	s.AddRecord(notiontypes.TableCollectionView, "v", map[string]interface{}{
+		"type": notiontypes.ViewTable,
+		"format": map[string]interface{}{
+			"table_properties": []map[string]interface{}{
+				{"property": "title", "visible": true},
+				{"property": "ok", "visible": false},
+				{"property": "pt", "visible": true},
+			},
+		},
+	})
+	r, err := (&RedactionConfig{Rules: []*RedactionRule{{Name: "done", Tag: "Done", Action: RedactRemove}}}).Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := &notiontypes.Block{ID: "root", Type: notiontypes.BlockPage, Title: "Wiki", Content: []*notiontypes.Block{
+		{ID: "linked", Type: notiontypes.BlockCollectionView, CollectionID: "db", ViewIDs: []string{"v"}},
+	}}
+	e := NewExporter(s.Client(), t.TempDir(), WithRenderer(&Markdown{}), WithDatabaseTables(), WithRedactor(r))
+	e.reset()
+	e.add(&notion.Page{Block: root}, nil)
+	if err := e.writeAll(); err != nil {
+		t.Fatal(err)
+	}
+	var rows []string
+	for _, row := range e.order[0].Databases["linked"].Rows {
+		rows = append(rows, row.ID)
+	}
+	if want := []string{"row00001"}; strings.Join(rows, ",") != strings.Join(want, ",") {
+		t.Errorf("got rows %v, want %v", rows, want)
+	}
+}
@@ -0,0 +1,113 @@
+package export
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+// DiagramRenderer renders the source of diagrams to SVG.
+type DiagramRenderer interface {
+	// RenderDiagram returns the SVG of source, written in language, either
+	// "mermaid" or "plantuml".
+	RenderDiagram(language, source string) ([]byte, error)
+}
+
+// diagramLanguage returns the diagram language of the notion code language
+// (e.g. "Mermaid"), or the empty string if it's not one.
+func diagramLanguage(language string) string {
+	switch l := strings.ToLower(language); l {
+	case "mermaid", "plantuml":
+		return l
+	}
+	return ""
+}
+
+// Kroki renders diagrams with a Kroki server (https://kroki.io).
+type Kroki struct {
+	// URL is the URL of the server, https://kroki.io by default.
+	URL string
+	// Client defaults to a client timing out after 30 seconds.
+	Client *http.Client
+}
+
+var krokiClient = &http.Client{Timeout: 30 * time.Second}
+
+// RenderDiagram implements DiagramRenderer.
+func (k *Kroki) RenderDiagram(language, source string) ([]byte, error) {
+	client := k.Client
+	if client == nil {
+		client = krokiClient
+	}
+	base := k.URL
+	if base == "" {
+		base = "https://kroki.io"
+	}
+	resp, err := client.Post(strings.TrimSuffix(base, "/")+"/"+language+"/svg", "text/plain", strings.NewReader(source))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("export: rendering %v diagram: %v: %s", language, resp.Status, bytes.TrimSpace(body))
+	}
+	return body, nil
+}
+
+// DiagramCommands renders diagrams with local programs, given by diagram
+// language as the command line of a program reading the source of a
+// diagram on stdin and writing its SVG to stdout.
+type DiagramCommands map[string][]string
+
+// DefaultDiagramCommands runs mmdc (mermaid-cli) and plantuml.
+var DefaultDiagramCommands = DiagramCommands{
+	"mermaid":  {"mmdc", "--input", "-", "--output", "-", "--outputFormat", "svg", "--quiet"},
+	"plantuml": {"plantuml", "-tsvg", "-pipe"},
+}
+
+// RenderDiagram implements DiagramRenderer.
+func (d DiagramCommands) RenderDiagram(language, source string) ([]byte, error) {
+	args := d[language]
+	if len(args) == 0 {
+		return nil, fmt.Errorf("export: no command renders %v diagrams", language)
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(source)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("export: rendering %v diagram: %v: %s", language, err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// svgElement matches the svg element of SVG files.
+var svgElement = regexp.MustCompile(`<svg\b`)
+
+// diagram returns the image of the diagram of the code block b, or the
+// empty string if it isn't a diagram or fails to render. The SVG is
+// embedded as a data URL rather than inline, where its scripts and event
+// handlers would run in the page.
+func (r *htmlRenderer) diagram(b *notiontypes.Block) string {
+	language := diagramLanguage(b.CodeLanguage)
+	if r.diagrams == nil || language == "" {
+		return ""
+	}
+	svg, err := r.diagrams.RenderDiagram(language, b.Code)
+	if err != nil || !svgElement.Match(svg) {
+		return ""
+	}
+	return `<img src="data:image/svg+xml;base64,` + base64.StdEncoding.EncodeToString(svg) + `" alt="` + language + ` diagram">`
+}
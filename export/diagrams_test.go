@@ -0,0 +1,62 @@
+package export
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+func TestDiagrams(t *testing.T) {
+	const kroki = `<?xml version="1.0"?>` + "\n" + `<!DOCTYPE svg><svg xmlns="http://www.w3.org/2000/svg"><text>A</text></svg>` + "\n"
+	const script = `<svg onload="alert(1)"><script>alert(2)</script></svg>`
+	img := func(svg, language string) string {
+		return `<figure class="diagram"><img src="data:image/svg+xml;base64,` + base64.StdEncoding.EncodeToString([]byte(svg)) + `" alt="` + language + ` diagram"></figure>` + "\n"
+	}
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+string(body))
+		if strings.Contains(string(body), "syntax error") {
+			http.Error(w, "Error 400: syntax error", http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(kroki))
+	}))
+	defer srv.Close()
+
+	page := &Page{Block: &notiontypes.Block{Type: notiontypes.BlockPage}}
+	for _, tt := range []struct {
+		diagrams DiagramRenderer
+		block    *notiontypes.Block
+		want     string
+	}{
+		{&Kroki{URL: srv.URL + "/"}, &notiontypes.Block{Type: notiontypes.BlockCode, Code: "graph TD; A-->B", CodeLanguage: "Mermaid"},
+			img(kroki, "mermaid")},
+		{&Kroki{URL: srv.URL}, &notiontypes.Block{Type: notiontypes.BlockCode, Code: "syntax error", CodeLanguage: "Mermaid"},
+			`<pre><code class="language-mermaid">syntax error</code></pre>` + "\n"},
+		{&Kroki{URL: srv.URL}, &notiontypes.Block{Type: notiontypes.BlockCode, Code: "A -> B", CodeLanguage: "Go"},
+			`<pre><code class="language-go">A -&gt; B</code></pre>` + "\n"},
+		{DiagramCommands{"plantuml": {"cat"}}, &notiontypes.Block{Type: notiontypes.BlockCode, Code: "<svg><text>@startuml</text></svg>", CodeLanguage: "plantuml"},
+			img("<svg><text>@startuml</text></svg>", "plantuml")},
+		// never inlined, where its scripts would run
+		{DiagramCommands{"plantuml": {"cat"}}, &notiontypes.Block{Type: notiontypes.BlockCode, Code: script, CodeLanguage: "plantuml"},
+			img(script, "plantuml")},
+	} {
+		h := &HTML{Highlighter: PlainHighlighter{}, Diagrams: tt.diagrams}
+		r := h.renderer(new(bytes.Buffer), page)
+		r.block(tt.block)
+		if got := r.buf.String(); got != tt.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", tt.block.Code, got, tt.want)
+		}
+	}
+	want := []string{"POST /mermaid/svg graph TD; A-->B", "POST /mermaid/svg syntax error"}
+	if strings.Join(requests, "\n") != strings.Join(want, "\n") {
+		t.Errorf("requests: got %q, want %q", requests, want)
+	}
+}
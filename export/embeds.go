@@ -0,0 +1,68 @@
+package export
+
+import (
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+var embedNames = map[string]string{
+	notiontypes.BlockEmbed: "Embedded page",
+	notiontypes.BlockDrive: "Google Drive file",
+	notiontypes.BlockFigma: "Figma file",
+	notiontypes.BlockTweet: "Tweet",
+	notiontypes.BlockMaps:  "Google Maps",
+	notiontypes.BlockGist:  "Gist",
+}
+
+// embedTitle returns the text of the link card that replaces the embed b
+// when it can't be inlined.
+func embedTitle(b *notiontypes.Block) string {
+	switch b.Type {
+	case notiontypes.BlockAudio, notiontypes.BlockPDF:
+		if name := path.Base(b.Source); name != "." && name != "/" {
+			return name
+		}
+	case notiontypes.BlockDrive:
+		if b.FormatDrive != nil && b.FormatDrive.DriveProperties.Title != "" {
+			return b.FormatDrive.DriveProperties.Title
+		}
+	case notiontypes.BlockTweet:
+		if user := sourceUser(b); user != "" {
+			return "Tweet by @" + user
+		}
+	case notiontypes.BlockGist:
+		if user := sourceUser(b); user != "" {
+			return "Gist by " + user
+		}
+	}
+	if name, ok := embedNames[b.Type]; ok {
+		return name
+	}
+	return b.Source
+}
+
+// embedURL returns the url that can be put into an iframe to show b inline,
+// or the empty string if there is none.
+func embedURL(b *notiontypes.Block) string {
+	if b.FormatEmbed != nil {
+		return b.FormatEmbed.DisplaySource
+	}
+	return ""
+}
+
+// sourceUser returns the user name in the source url of a tweet or gist,
+// like someone in https://twitter.com/someone/status/1.
+func sourceUser(b *notiontypes.Block) string {
+	u, err := url.Parse(b.Source)
+	if err != nil {
+		return ""
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 2 || parts[0] == "" {
+		return ""
+	}
+	return parts[0]
+}
@@ -0,0 +1,436 @@
+// Package export writes notion pages to disk as Markdown or HTML files.
+package export
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// Renderer renders a page into a particular output format.
+type Renderer interface {
+	// Ext returns the file extension of rendered pages, including the dot.
+	Ext() string
+	// Render writes page to w.
+	Render(w io.Writer, page *Page) error
+}
+
+// Page is a page being exported.
+type Page struct {
+	*notiontypes.Block
+	// Path is the page's file path, relative to the export directory.
+	Path string
+	// Ancestors holds the ids of the page's ancestors, starting with the export root.
+	Ancestors []string
+	// PageProperties holds the properties of pages that are database rows.
+	PageProperties []*notiontypes.PageProperty
+	// FrontMatterParams holds additional front-matter entries, as YAML
+	// values by key, e.g. set by a page hook. See FrontMatter.
+	FrontMatterParams map[string]string
+	// Databases holds the tables of the database views on the page by
+	// block id, see WithDatabaseTables.
+	Databases map[string]*DatabaseTable
+
+	exporter *Exporter
+	// maps block ids to resolved asset references
+	assets  map[string]string
+	srcsets map[string][]ImageSource
+	// the properties of the page before redactions
+	crawledProps []*notiontypes.PageProperty
+	// maps ids of heading blocks to their anchors
+	anchors  map[string]string
+	headings []*Heading
+}
+
+// Link returns the path of the exported page id relative to p,
+// or the empty string if that page is not part of the export.
+func (p *Page) Link(id string) string {
+	target, ok := p.exporter.pages[id]
+	if !ok {
+		return ""
+	}
+	return p.Rel(target.Path)
+}
+
+// Rel returns target, a slash-separated path relative to the export
+// directory, as a path relative to p.
+func (p *Page) Rel(target string) string {
+	rel, err := filepath.Rel(filepath.Dir(filepath.FromSlash(p.Path)), filepath.FromSlash(target))
+	if err != nil {
+		return target
+	}
+	return filepath.ToSlash(rel)
+}
+
+// AssetURL returns the reference to the asset (e.g. image) of the block b
+// as resolved by the export's AssetPolicy, or the empty string if its
+// scheme isn't safe in browsers, e.g. javascript:.
+func (p *Page) AssetURL(b *notiontypes.Block) string {
+	if ref, ok := p.assets[b.ID]; ok {
+		return safeURL(ref)
+	}
+	if b.IsImage() {
+		return safeURL(imageURL(b))
+	}
+	return safeURL(b.Source)
+}
+
+// BlockLink returns the path (relative to p) of the exported page
+// containing the block id, or the empty string if it's not part of the export.
+func (p *Page) BlockLink(id string) string {
+	target, ok := p.exporter.blockPages[id]
+	if !ok {
+		return ""
+	}
+	return p.Rel(target.Path)
+}
+
+// RewriteLink returns the link to use for link, as rewritten by the
+// export's LinkRewriter. An empty result means the link should be removed.
+func (p *Page) RewriteLink(link string) string {
+	return p.exporter.links.Rewrite(p, link)
+}
+
+// PageURL returns the link to the page or block id, as rewritten by the
+// export's LinkRewriter. An empty result means the link should be removed.
+func (p *Page) PageURL(id string) string {
+	return p.exporter.links.resolve(p, id, "", notionURL(id))
+}
+
+// SyncedBlockLink returns the link that replaces the content of the synced
+// block copy b, or the empty string if the content should be transcluded.
+// See WithSyncedBlockLinks.
+func (p *Page) SyncedBlockLink(b *notiontypes.Block) string {
+	if !p.exporter.syncedLinks || b.OriginalID == "" {
+		return ""
+	}
+	return p.PageURL(b.OriginalID)
+}
+
+// Breadcrumbs returns the exported ancestors of p, starting with the export root.
+func (p *Page) Breadcrumbs() []*Page {
+	var res []*Page
+	for _, id := range p.Ancestors {
+		if a, ok := p.exporter.pages[id]; ok {
+			res = append(res, a)
+		}
+	}
+	return res
+}
+
+// Srcset returns the alternative versions of the image of block b, if the
+// export's AssetPolicy produced any.
+func (p *Page) Srcset(b *notiontypes.Block) []ImageSource {
+	return p.srcsets[b.ID]
+}
+
+// Option allows customization of Exporters.
+type Option func(*Exporter)
+
+// WithFilter restricts the exported pages and blocks to those selected by f.
+func WithFilter(f *notion.Filter) Option {
+	return func(e *Exporter) {
+		e.filter = f
+	}
+}
+
+// WithRenderer sets the Renderer used for pages. The default is Markdown.
+func WithRenderer(r Renderer) Option {
+	return func(e *Exporter) {
+		e.renderer = r
+	}
+}
+
+// WithAssetPolicy sets how images and files referenced by pages are
+// exported. The default is Hotlink.
+func WithAssetPolicy(p AssetPolicy) Option {
+	return func(e *Exporter) {
+		e.assets = p
+	}
+}
+
+// WithSyncedBlockLinks renders copies of synced blocks as links to the
+// original instead of repeating (transcluding) the original's content.
+func WithSyncedBlockLinks() Option {
+	return func(e *Exporter) {
+		e.syncedLinks = true
+	}
+}
+
+// WithLinkRewriter sets the LinkRewriter for links to notion pages. By
+// default a LinkRewriter with no options is used.
+func WithLinkRewriter(r *LinkRewriter) Option {
+	return func(e *Exporter) {
+		e.links = r
+	}
+}
+
+// WithPageHook calls fn for every exported page before any page is
+// written. fn may change the page's Path and FrontMatterParams.
+func WithPageHook(fn func(*Page)) Option {
+	return func(e *Exporter) {
+		e.hook = fn
+	}
+}
+
+// Exporter exports a page and its sub-pages into a directory.
+//
+// Output only depends on the exported pages: blocks are rendered in content
+// order and pages processed depth first in the order they appear on their
+// parent, so exporting unchanged pages again produces identical files.
+type Exporter struct {
+	client   *notion.Client
+	dir      string
+	filter   *notion.Filter
+	renderer Renderer
+	assets   AssetPolicy
+
+	links       *LinkRewriter
+	syncedLinks bool
+	hook        func(*Page)
+	previous    map[string]*ManifestPage
+	history     bool
+	// databaseTables loads the tables of embedded database views
+	databaseTables bool
+
+	pages map[string]*Page
+	order []*Page
+	// maps ids of blocks to the exported page they're on
+	blockPages map[string]*Page
+	manifest   *Manifest
+	crawl      *notion.CrawlResult
+
+	redactor   *Redactor
+	redactions []*Redaction
+	// holds the ids of the pages removed by redactions
+	removed map[string]bool
+}
+
+// NewExporter initializes a new Exporter that writes into dir.
+func NewExporter(client *notion.Client, dir string, opts ...Option) *Exporter {
+	e := &Exporter{
+		client:   client,
+		dir:      dir,
+		renderer: &Markdown{},
+		assets:   Hotlink{},
+		links:    &LinkRewriter{},
+	}
+	for _, o := range opts {
+		o(e)
+	}
+	return e
+}
+
+// Export crawls the page rootID and writes it and its sub-pages to the export directory.
+// Pages and blocks the client can't read are skipped, see CrawlResult.
+func (e *Exporter) Export(rootID string) error {
+	e.reset()
+	var err error
+	e.crawl, err = e.client.CrawlWithOptions(rootID, notion.CrawlOptions{Filter: e.filter}, func(p *notion.Page, ancestors []string) error {
+		e.add(p, ancestors)
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "crawling pages")
+	}
+	return e.writeAll()
+}
+
+// CrawlResult returns the summary of the crawl of the last Export, with the
+// pages and blocks it skipped.
+func (e *Exporter) CrawlResult() *notion.CrawlResult {
+	return e.crawl
+}
+
+// ExportPages writes the given pages, but not their sub-pages, to the
+// export directory.
+func (e *Exporter) ExportPages(ids ...string) error {
+	e.reset()
+	for _, id := range ids {
+		p, err := e.client.GetPage(id)
+		if err != nil {
+			return errors.Wrapf(err, "getting page %v", id)
+		}
+		e.filter.Prune(p.Block)
+		e.add(p, nil)
+	}
+	return e.writeAll()
+}
+
+// ExternalLinks returns the links to notion pages outside of the last export.
+func (e *Exporter) ExternalLinks() []ExternalLink {
+	return e.links.External()
+}
+
+func (e *Exporter) reset() {
+	e.links.reset()
+	e.pages = make(map[string]*Page)
+	e.blockPages = make(map[string]*Page)
+	e.order = nil
+	e.redactions = nil
+	e.removed = make(map[string]bool)
+}
+
+func (e *Exporter) add(p *notion.Page, ancestors []string) {
+	props := p.PageProperties
+	if e.redactor != nil {
+		for _, id := range ancestors {
+			if e.removed[id] {
+				e.removed[p.ID] = true
+				return
+			}
+		}
+		var remove bool
+		if props, remove = e.redactor.redactPage(p.Block, props, &e.redactions); remove {
+			e.removed[p.ID] = true
+			return
+		}
+	}
+	page := e.newPage(p.Block, ancestors, props)
+	page.crawledProps = p.PageProperties
+	page.Path = pageFileName(p.Block) + e.renderer.Ext()
+	e.pages[p.ID] = page
+	e.order = append(e.order, page)
+	e.indexBlocks(page, page.Content)
+}
+
+func (e *Exporter) newPage(b *notiontypes.Block, ancestors []string, props []*notiontypes.PageProperty) *Page {
+	page := &Page{
+		Block:          b,
+		Ancestors:      ancestors,
+		PageProperties: props,
+		exporter:       e,
+		assets:         make(map[string]string),
+		srcsets:        make(map[string][]ImageSource),
+		anchors:        make(map[string]string),
+	}
+	page.indexHeadings(page.Content, make(map[string]int))
+	return page
+}
+
+func (e *Exporter) writeAll() error {
+	if len(e.removed) > 0 {
+		for _, page := range e.order {
+			page.Content, page.ContentIDs = e.pruneRemoved(page.Content)
+		}
+	}
+	if e.databaseTables {
+		for _, page := range e.order {
+			e.loadDatabases(page, page.Content)
+		}
+	}
+	if e.hook != nil {
+		for _, page := range e.order {
+			e.hook(page)
+		}
+	}
+	if err := os.MkdirAll(e.dir, 0755); err != nil {
+		return err
+	}
+	entries, err := e.hashPages()
+	if err != nil {
+		return err
+	}
+	for _, page := range e.order {
+		if e.unchanged(entries[page.ID]) {
+			continue
+		}
+		if err := e.write(page); err != nil {
+			return errors.Wrapf(err, "exporting page %v", page.ID)
+		}
+	}
+	if e.history {
+		for _, page := range e.order {
+			if err := e.writeHistory(page); err != nil {
+				return errors.Wrapf(err, "exporting the history of page %v", page.ID)
+			}
+		}
+	}
+	return e.writeManifest(entries)
+}
+
+// pruneRemoved returns blocks without the sub-pages removed by redactions,
+// and their ids.
+func (e *Exporter) pruneRemoved(blocks []*notiontypes.Block) ([]*notiontypes.Block, []string) {
+	kept := blocks[:0:0]
+	var ids []string
+	for _, b := range blocks {
+		if b.IsPage() && e.removed[b.ID] {
+			continue
+		}
+		if !b.IsPage() {
+			b.Content, b.ContentIDs = e.pruneRemoved(b.Content)
+		}
+		kept = append(kept, b)
+		ids = append(ids, b.ID)
+	}
+	return kept, ids
+}
+
+func (e *Exporter) indexBlocks(page *Page, blocks []*notiontypes.Block) {
+	for _, b := range blocks {
+		if _, ok := e.blockPages[b.ID]; ok || b.IsPage() {
+			continue
+		}
+		e.blockPages[b.ID] = page
+		e.indexBlocks(page, b.Content)
+	}
+}
+
+func (e *Exporter) write(page *Page) error {
+	if err := e.resolveAssets(page, page.Content); err != nil {
+		return err
+	}
+	buf := new(bytes.Buffer)
+	if err := e.renderer.Render(buf, page); err != nil {
+		return err
+	}
+	path := filepath.Join(e.dir, filepath.FromSlash(page.Path))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// pageFileName returns the file name (without extension) for a page, made
+// of the slugified title and the page id so that names are unique.
+func pageFileName(b *notiontypes.Block) string {
+	return Slug(b.Title) + "-" + strings.Replace(b.ID, "-", "", -1)
+}
+
+// Slug converts s into a lower-case, URL and file-name friendly string.
+func Slug(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			dash = false
+			b.WriteRune(r)
+			continue
+		}
+		dash = true
+	}
+	if b.Len() == 0 {
+		return "untitled"
+	}
+	return b.String()
+}
+
+func plainText(inline []*notiontypes.InlineBlock) string {
+	var b strings.Builder
+	for _, i := range inline {
+		b.WriteString(i.Text)
+	}
+	return b.String()
+}
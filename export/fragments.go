@@ -0,0 +1,180 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// ErrRenderPage is returned by HTML.Update for changes that can't be
+// rendered as fragments, such as changes of the page's title.
+var ErrRenderPage = errors.New("export: the page must be rendered again")
+
+// HTMLFragment is the HTML of a block, as it appears in the output of
+// HTML.Render with BlockMarkers set: enclosed in the comments
+// <!--block:ID--> and <!--/block:ID-->.
+type HTMLFragment struct {
+	BlockID string `json:"block_id"`
+	HTML    string `json:"html"`
+}
+
+// Replace returns html with the previous version of f replaced by f, and
+// false if html doesn't contain f's block.
+func (f *HTMLFragment) Replace(html string) (string, bool) {
+	start, end := blockMarkers(f.BlockID)
+	i := strings.Index(html, start)
+	if i < 0 {
+		return html, false
+	}
+	j := strings.Index(html[i:], end)
+	if j < 0 {
+		return html, false
+	}
+	j += i + len(end)
+	return html[:i] + f.HTML + html[j:], true
+}
+
+func blockMarkers(id string) (start, end string) {
+	return "<!--block:" + id + "-->", "<!--/block:" + id + "-->"
+}
+
+// Page returns the exported page with the given id, or nil if it isn't
+// part of the last export.
+func (e *Exporter) Page(id string) *Page {
+	return e.pages[id]
+}
+
+// Update replaces the block of page with the id of b, and its content, by
+// b and returns the fragments that changed in the HTML of the page: that
+// of b, or of its parent if b moved into or out of a list, and those of
+// headings and tables of contents affected by changes of headings.
+// Fragments with the id of the page hold the page's whole content.
+//
+// Update is meant for previews that update pages as they're edited. The
+// page must have been rendered by h with BlockMarkers set, and be part of
+// the last export of its Exporter, whose AssetPolicy resolves the assets
+// of b. Changes of the page block itself can only be rendered as fragments
+// if its title and icon are unchanged; ErrRenderPage is returned otherwise.
+// Rows and columns must be updated as part of their table or column list.
+func (h *HTML) Update(page *Page, b *notiontypes.Block) ([]*HTMLFragment, error) {
+	if b.ID == page.ID {
+		return h.updatePage(page, b)
+	}
+	parent, i := findBlock(page.Block, b.ID)
+	if parent == nil {
+		return nil, errors.Errorf("export: block %v is not on page %v", b.ID, page.ID)
+	}
+	switch b.Type {
+	case notiontypes.BlockColumn, notiontypes.BlockTableRow:
+		return nil, errors.Errorf("export: update the %v containing block %v instead", parent.Type, b.ID)
+	}
+	old := parent.Content[i]
+	parent.Content[i] = b
+	if err := page.exporter.resolveAssets(page, []*notiontypes.Block{b}); err != nil {
+		return nil, err
+	}
+	page.exporter.indexBlocks(page, []*notiontypes.Block{b})
+	changed := []*notiontypes.Block{b}
+	if listTag(old.Type) != listTag(b.Type) {
+		changed[0] = parent
+	}
+	changed = append(changed, page.reindexHeadings()...)
+	return h.fragments(page, changed), nil
+}
+
+func (h *HTML) updatePage(page *Page, b *notiontypes.Block) ([]*HTMLFragment, error) {
+	icon := func(b *notiontypes.Block) string {
+		if b.FormatPage == nil {
+			return ""
+		}
+		return b.FormatPage.PageIcon
+	}
+	if b.Title != page.Title || icon(b) != icon(page.Block) {
+		return nil, ErrRenderPage
+	}
+	page.Block = b
+	if err := page.exporter.resolveAssets(page, b.Content); err != nil {
+		return nil, err
+	}
+	page.exporter.indexBlocks(page, b.Content)
+	page.reindexHeadings()
+	return h.fragments(page, []*notiontypes.Block{b}), nil
+}
+
+// reindexHeadings assigns the anchors of the headings of p again, and
+// returns the headings whose anchors changed and, if any heading changed,
+// the tables of contents.
+func (p *Page) reindexHeadings() []*notiontypes.Block {
+	prev, prevHeadings := p.anchors, p.headings
+	p.anchors, p.headings = make(map[string]string), nil
+	p.indexHeadings(p.Content, make(map[string]int))
+	var changed []*notiontypes.Block
+	same := len(prevHeadings) == len(p.headings)
+	for i, h := range p.headings {
+		if prev[h.Block.ID] != h.Anchor {
+			changed = append(changed, h.Block)
+		}
+		if same {
+			old := prevHeadings[i]
+			same = h.Level == old.Level && h.Text == old.Text && h.Anchor == old.Anchor
+		}
+	}
+	if same {
+		return changed
+	}
+	walkBlocks(p.Content, func(b *notiontypes.Block) {
+		if b.Type == notiontypes.BlockTableOfContents {
+			changed = append(changed, b)
+		}
+	})
+	return changed
+}
+
+// fragments renders the given blocks of page, skipping duplicates.
+func (h *HTML) fragments(page *Page, blocks []*notiontypes.Block) []*HTMLFragment {
+	var res []*HTMLFragment
+	seen := make(map[string]bool)
+	for _, b := range blocks {
+		if seen[b.ID] {
+			continue
+		}
+		seen[b.ID] = true
+		r := h.renderer(new(bytes.Buffer), page)
+		if b.ID == page.ID {
+			r.content(page.Content)
+		} else {
+			r.block(b)
+		}
+		res = append(res, &HTMLFragment{BlockID: b.ID, HTML: r.buf.String()})
+	}
+	return res
+}
+
+// findBlock returns the block whose content holds the block with the given
+// id within b, not counting sub-pages, and its index.
+func findBlock(b *notiontypes.Block, id string) (*notiontypes.Block, int) {
+	for i, c := range b.Content {
+		if c.ID == id {
+			return b, i
+		}
+		if c.IsPage() {
+			continue
+		}
+		if parent, j := findBlock(c, id); parent != nil {
+			return parent, j
+		}
+	}
+	return nil, 0
+}
+
+// walkBlocks calls fn for blocks and their content, not counting sub-pages.
+func walkBlocks(blocks []*notiontypes.Block, fn func(*notiontypes.Block)) {
+	for _, b := range blocks {
+		fn(b)
+		if !b.IsPage() {
+			walkBlocks(b.Content, fn)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/notiontypes"
+)
+
+func TestUpdate(t *testing.T) {
+	const pageID = "4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+	block := func(id, typ, text string) *notiontypes.Block {
+		return &notiontypes.Block{ID: id, Type: typ, InlineContent: []*notiontypes.InlineBlock{{Text: text}}}
+	}
+	page := &notiontypes.Block{ID: pageID, Type: notiontypes.BlockPage, Title: "Notes", Content: []*notiontypes.Block{
+		block("toc", notiontypes.BlockTableOfContents, ""),
+		block("h1", notiontypes.BlockHeader, "Intro"),
+		block("p1", notiontypes.BlockText, "one"),
+		block("li1", notiontypes.BlockBulletedList, "a"),
+		block("li2", notiontypes.BlockBulletedList, "b"),
+		block("h2", notiontypes.BlockHeader, "Usage"),
+	}}
+	page.Content[3].Content = []*notiontypes.Block{block("p2", notiontypes.BlockText, "nested")}
+	h := &HTML{Highlighter: PlainHighlighter{}, BlockMarkers: true}
+	e := NewExporter(nil, t.TempDir(), WithRenderer(h))
+	e.reset()
+	e.add(&notion.Page{Block: page}, nil)
+	p := e.Page(pageID)
+	render := func() string {
+		var buf bytes.Buffer
+		if err := h.Render(&buf, p); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+	html := render()
+
+	for _, tc := range []struct {
+		name  string
+		block *notiontypes.Block
+		want  []string
+	}{
+		{"text", block("p1", notiontypes.BlockText, "two"), []string{"p1"}},
+		{"nested", block("p2", notiontypes.BlockQuote, "quoted"), []string{"p2"}},
+		{"heading", block("h2", notiontypes.BlockHeader, "Intro"), []string{"h2", "toc"}},
+		{"list", block("li2", notiontypes.BlockNumberedList, "b"), []string{pageID}},
+		{"page", &notiontypes.Block{ID: pageID, Type: notiontypes.BlockPage, Title: "Notes", Content: page.Content[:3]}, []string{pageID}},
+	} {
+		fragments, err := h.Update(p, tc.block)
+		if err != nil {
+			t.Fatalf("%v: %v", tc.name, err)
+		}
+		var ids []string
+		for _, f := range fragments {
+			ids = append(ids, f.BlockID)
+			var ok bool
+			if html, ok = f.Replace(html); !ok {
+				t.Errorf("%v: fragment %v not found", tc.name, f.BlockID)
+			}
+		}
+		if len(ids) != len(tc.want) || ids[0] != tc.want[0] || len(ids) > 1 && ids[1] != tc.want[1] {
+			t.Errorf("%v: got fragments %q, want %q", tc.name, ids, tc.want)
+		}
+		if want := render(); html != want {
+			t.Errorf("%v: got\n%s\nwant\n%s", tc.name, html, want)
+		}
+	}
+
+	if _, err := h.Update(p, &notiontypes.Block{ID: pageID, Type: notiontypes.BlockPage, Title: "Renamed"}); err != ErrRenderPage {
+		t.Errorf("renaming the page: got %v, want ErrRenderPage", err)
+	}
+	if _, err := h.Update(p, block("nope", notiontypes.BlockText, "")); err == nil {
+		t.Error("updating a missing block: got no error")
+	}
+}
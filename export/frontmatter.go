@@ -0,0 +1,93 @@
+package export
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+// FrontMatter returns the YAML front-matter of page: its title, the
+// values of its PageProperties and its FrontMatterParams. keys maps
+// property names to front-matter keys, an empty key drops the property.
+// Properties without a mapping use the slug of their name. FrontMatter
+// returns the empty string for pages that are not database rows and have
+// no FrontMatterParams.
+func FrontMatter(page *Page, keys map[string]string) string {
+	if len(page.PageProperties) == 0 && len(page.FrontMatterParams) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("---\n")
+	if _, ok := page.FrontMatterParams["title"]; !ok {
+		b.WriteString("title: " + YAMLString(page.Title) + "\n")
+	}
+	for _, p := range page.PageProperties {
+		key := FrontMatterKey(p.Name, keys)
+		if key == "" {
+			continue
+		}
+		if _, ok := page.FrontMatterParams[key]; ok {
+			continue
+		}
+		if v := yamlValue(p); v != "" {
+			b.WriteString(key + ":" + v + "\n")
+		}
+	}
+	params := make([]string, 0, len(page.FrontMatterParams))
+	for key := range page.FrontMatterParams {
+		params = append(params, key)
+	}
+	sort.Strings(params)
+	for _, key := range params {
+		b.WriteString(key + ": " + page.FrontMatterParams[key] + "\n")
+	}
+	b.WriteString("---\n")
+	return b.String()
+}
+
+// FrontMatterKey returns the front-matter key of the property name, as
+// mapped by keys.
+func FrontMatterKey(name string, keys map[string]string) string {
+	if key, ok := keys[name]; ok {
+		return key
+	}
+	return Slug(name)
+}
+
+// yamlValue returns the value of p as YAML, including the separating space
+// or newline, or the empty string if p has no value.
+func yamlValue(p *notiontypes.PageProperty) string {
+	switch p.Type {
+	case notiontypes.ColumnMultiSelect:
+		var b strings.Builder
+		for _, v := range p.Values() {
+			b.WriteString("\n  - " + YAMLString(v))
+		}
+		return b.String()
+	case notiontypes.ColumnTypeCheckbox:
+		return " " + strconv.FormatBool(p.Checked())
+	case notiontypes.ColumnTypeNumber:
+		if _, err := strconv.ParseFloat(p.Text(), 64); err == nil {
+			return " " + p.Text()
+		}
+	case notiontypes.ColumnTypeDate:
+		if d := p.Date(); d != nil {
+			if d.StartTime != nil {
+				return " " + d.StartDate + "T" + *d.StartTime + ":00"
+			}
+			return " " + d.StartDate
+		}
+	}
+	if text := p.Text(); text != "" {
+		return " " + YAMLString(text)
+	}
+	return ""
+}
+
+// YAMLString quotes s as a YAML double-quoted scalar, which shares its
+// escape sequences with Go, e.g. for use in FrontMatterParams.
+func YAMLString(s string) string {
+	return strconv.Quote(s)
+}
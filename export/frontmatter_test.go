@@ -0,0 +1,37 @@
+package export
+
+import (
+	"testing"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+func TestFrontMatter(t *testing.T) {
+	start := "09:30"
+	page := &Page{
+		Block: &notiontypes.Block{Title: `Say "hi"`},
+		PageProperties: []*notiontypes.PageProperty{
+			{Name: "Tags", Type: notiontypes.ColumnMultiSelect, Value: []*notiontypes.InlineBlock{{Text: "go,notion"}}},
+			{Name: "Publish Date", Type: notiontypes.ColumnTypeDate, Value: []*notiontypes.InlineBlock{
+				{Text: notiontypes.InlineAt, Date: &notiontypes.Date{StartDate: "2019-03-01", StartTime: &start}},
+			}},
+			{Name: "Draft", Type: notiontypes.ColumnTypeCheckbox, Value: []*notiontypes.InlineBlock{{Text: "Yes"}}},
+			{Name: "Status", Type: notiontypes.ColumnTypeSelect, Value: []*notiontypes.InlineBlock{{Text: "Published"}}},
+			{Name: "Internal", Type: notiontypes.ColumnTypeText, Value: []*notiontypes.InlineBlock{{Text: "secret"}}},
+		},
+	}
+	got := FrontMatter(page, map[string]string{"Publish Date": "date", "Internal": ""})
+	want := `---
+title: "Say \"hi\""
+tags:
+  - "go"
+  - "notion"
+date: 2019-03-01T09:30:00
+draft: true
+status: "Published"
+---
+`
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,109 @@
+package export
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/corpus"
+	"github.com/tmc/notion/importer"
+	"github.com/tmc/notion/notiontypes"
+)
+
+var update = flag.Bool("update", false, "update the golden files of the corpus")
+
+func TestGolden(t *testing.T) {
+	cases, err := corpus.Cases()
+	if err != nil {
+		t.Fatal(err)
+	}
+	renderers := []Renderer{
+		&Markdown{},
+		// chroma's output changes between releases
+		&HTML{Highlighter: PlainHighlighter{}},
+		&Widget{Indent: "  "},
+	}
+	for _, c := range cases {
+		for _, r := range renderers {
+			t.Run(c.Name+r.Ext(), func(t *testing.T) {
+				got := exportCase(t, c, r)
+				if *update {
+					dst := filepath.FromSlash(corpus.GoldenPath("../corpus/testdata", c.Name, r.Ext()))
+					if err := ioutil.WriteFile(dst, got, 0644); err != nil {
+						t.Fatal(err)
+					}
+					return
+				}
+				want, err := c.Golden(r.Ext())
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(got, want) {
+					t.Errorf("output differs from golden file, run go test -update after verifying it:\n%s", got)
+				}
+			})
+		}
+	}
+}
+
+// exportCase exports the page of c with the renderer r and returns the output.
+func exportCase(t *testing.T, c *corpus.Case, r Renderer) []byte {
+	b, err := c.Page()
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := NewExporter(nil, t.TempDir(), WithRenderer(r))
+	e.reset()
+	e.add(&notion.Page{Block: b}, nil)
+	if err := e.writeAll(); err != nil {
+		t.Fatal(err)
+	}
+	out, err := ioutil.ReadFile(filepath.Join(e.dir, e.order[0].Path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestMarkdownRoundTrip(t *testing.T) {
+	src := "# Heading\n\n" +
+		"Some **bold**, *italic*, ~~struck~~ and `code` text with [a link](https://example.com).\n\n" +
+		"- one\n  - nested\n- two\n\n" +
+		"1. first\n1. second\n\n" +
+		"- [x] done\n- [ ] not done\n\n" +
+		"> quoted\n\n" +
+		"```go\nfmt.Println()\n```\n\n" +
+		"---\n\n" +
+		"| a | b |\n| --- | --- |\n| 1 | 2 |\n"
+	page := &notiontypes.Block{ID: "page", Type: notiontypes.BlockPage, Title: "Round trip", Content: importer.Markdown([]byte(src))}
+	e := NewExporter(nil, "", WithRenderer(&Markdown{}))
+	e.reset()
+	e.add(&notion.Page{Block: page}, nil)
+	buf := new(bytes.Buffer)
+	if err := e.renderer.Render(buf, e.order[0]); err != nil {
+		t.Fatal(err)
+	}
+	if want := "# Round trip\n\n" + src; buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf, want)
+	}
+}
+
+func TestReproducible(t *testing.T) {
+	cases, err := corpus.Cases()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range cases {
+		for _, r := range []Renderer{&Markdown{FrontMatter: true}, &HTML{Highlighter: PlainHighlighter{}}, &Widget{}} {
+			first := exportCase(t, c, r)
+			for i := 0; i < 5; i++ {
+				if out := exportCase(t, c, r); !bytes.Equal(out, first) {
+					t.Fatalf("%v%v: export %d differs from the first", c.Name, r.Ext(), i+2)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package export
+
+import (
+	"html"
+	"io"
+
+	"github.com/alecthomas/chroma"
+	chromahtml "github.com/alecthomas/chroma/formatters/html"
+	"github.com/alecthomas/chroma/lexers"
+	"github.com/alecthomas/chroma/styles"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// Highlighter renders code blocks as HTML.
+type Highlighter interface {
+	// Highlight writes code written in the notion code language (e.g. "C++") to w.
+	Highlight(w io.Writer, code, language string) error
+}
+
+// ChromaHighlighter highlights code server-side using chroma.
+// The output uses inline styles, so it needs no additional stylesheet.
+type ChromaHighlighter struct {
+	// Style is the name of the chroma style to use. It defaults to "github".
+	Style string
+}
+
+// Highlight writes code to w as highlighted HTML.
+func (h *ChromaHighlighter) Highlight(w io.Writer, code, language string) error {
+	lexer := lexers.Get(notiontypes.LinguistName(language))
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+	style := styles.Get(h.Style)
+	if h.Style == "" || style == nil {
+		style = styles.Get("github")
+	}
+	it, err := chroma.Coalesce(lexer).Tokenise(nil, code)
+	if err != nil {
+		return err
+	}
+	return chromahtml.New().Format(w, style, it)
+}
+
+// PlainHighlighter renders code without highlighting, tagging it with a
+// "language-" class so client-side highlighters can pick it up.
+type PlainHighlighter struct{}
+
+// Highlight writes code to w as a pre element.
+func (PlainHighlighter) Highlight(w io.Writer, code, language string) error {
+	class := ""
+	if name := notiontypes.LinguistName(language); name != "" {
+		class = ` class="language-` + html.EscapeString(name) + `"`
+	}
+	_, err := io.WriteString(w, "<pre><code"+class+">"+html.EscapeString(code)+"</code></pre>\n")
+	return err
+}
@@ -0,0 +1,98 @@
+package export
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// HistoryIndexFile is the name of the index of the versions of a page,
+// written into the page's history directory by exports WithHistory.
+const HistoryIndexFile = "index.json"
+
+// HistoryVersion describes an exported version of a page.
+type HistoryVersion struct {
+	SnapshotID string `json:"snapshot_id"`
+	Version    int64  `json:"version"`
+	// Time is when notion saved the version.
+	Time time.Time `json:"time"`
+	// Authors are the ids of the users whose edits the version saved.
+	Authors []string `json:"authors"`
+	// Path is the version's file path, relative to the export directory.
+	Path string `json:"path"`
+}
+
+// WithHistory also writes the versions of every exported page kept by
+// notion, e.g. for archival. The versions of the page at a/b.md are
+// written to a/b.history/<version>.md, along with a HistoryIndexFile
+// listing them most recent first. Versions are never rendered again once
+// written, so exporting into the same directory only adds new versions.
+//
+// Versions are rendered like the current pages, with links to the current
+// pages of the export.
+func WithHistory() Option {
+	return func(e *Exporter) {
+		e.history = true
+	}
+}
+
+// historyDir returns the directory of the versions of page, relative to
+// the export directory.
+func historyDir(page *Page) string {
+	return strings.TrimSuffix(page.Path, path.Ext(page.Path)) + ".history"
+}
+
+func (e *Exporter) writeHistory(page *Page) error {
+	snapshots, err := e.client.GetBlockHistory(page.ID)
+	if err != nil {
+		return err
+	}
+	dir := historyDir(page)
+	index := make([]*HistoryVersion, 0, len(snapshots))
+	for _, s := range snapshots {
+		v := &HistoryVersion{
+			SnapshotID: s.ID,
+			Version:    s.Version,
+			Time:       s.SavedOn().UTC(),
+			Authors:    make([]string, 0, len(s.Authors)),
+			Path:       path.Join(dir, strconv.FormatInt(s.Version, 10)+e.renderer.Ext()),
+		}
+		for _, a := range s.Authors {
+			v.Authors = append(v.Authors, a.ID)
+		}
+		index = append(index, v)
+		if _, err := os.Stat(filepath.Join(e.dir, filepath.FromSlash(v.Path))); err == nil {
+			continue
+		}
+		b, err := e.client.GetBlockVersion(page.ID, s.ID)
+		if err != nil {
+			return errors.Wrapf(err, "getting version %v", s.Version)
+		}
+		e.filter.Prune(b)
+		if e.redactor != nil {
+			var discard []*Redaction
+			e.redactor.redactPage(b, page.crawledProps, &discard)
+		}
+		version := e.newPage(b, page.Ancestors, page.PageProperties)
+		version.Path = v.Path
+		version.FrontMatterParams = page.FrontMatterParams
+		if err := e.write(version); err != nil {
+			return errors.Wrapf(err, "exporting version %v", s.Version)
+		}
+	}
+	if len(index) == 0 {
+		return nil
+	}
+	b, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(e.dir, filepath.FromSlash(dir), HistoryIndexFile), b, 0644)
+}
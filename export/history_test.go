@@ -0,0 +1,70 @@
+package export
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tmc/notion/notiontest"
+	"github.com/tmc/notion/notiontypes"
+)
+
+func TestWithHistory(t *testing.T) {
+	const (
+		pageID = "4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+		textID = "5b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+		userID = "6b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+	)
+	page := func(version int64, text string) *notiontypes.Block {
+		return &notiontypes.Block{
+			ID: pageID, Type: notiontypes.BlockPage, Version: version,
+			Properties: map[string]interface{}{"title": [][]string{{"Policy"}}},
+			Content: []*notiontypes.Block{{
+				ID: textID, Type: notiontypes.BlockText, Version: version,
+				Properties: map[string]interface{}{"title": [][]string{{text}}},
+			}},
+		}
+	}
+	srv := notiontest.NewServer()
+	defer srv.Close()
+	srv.AddBlock(page(3, "third"))
+	author := []*notiontypes.ActivityAuthor{{ID: userID, Table: "notion_user"}}
+	srv.AddSnapshot(&notiontypes.Snapshot{ID: "s1", Version: 1, Timestamp: 1500000000000, Authors: author}, page(1, "first"))
+	srv.AddSnapshot(&notiontypes.Snapshot{ID: "s2", Version: 2, LastVersion: 1, Timestamp: 1600000000000, Authors: author}, page(2, "second"))
+
+	dir := t.TempDir()
+	e := NewExporter(srv.Client(), dir, WithHistory())
+	if err := e.Export(pageID); err != nil {
+		t.Fatal(err)
+	}
+	hist := filepath.Join(dir, "policy-"+strings.Replace(pageID, "-", "", -1)+".history")
+	b, err := ioutil.ReadFile(filepath.Join(hist, HistoryIndexFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var index []*HistoryVersion
+	if err := json.Unmarshal(b, &index); err != nil {
+		t.Fatal(err)
+	}
+	if len(index) != 2 || index[0].Version != 2 || index[1].SnapshotID != "s1" ||
+		index[0].Time.Unix() != 1600000000 || len(index[0].Authors) != 1 || index[0].Authors[0] != userID {
+		t.Fatalf("got index %s", b)
+	}
+	for i, want := range []string{"second", "first"} {
+		b, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(index[i].Path)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(b), want) {
+			t.Errorf("version %v is %q, want it to contain %q", index[i].Version, b, want)
+		}
+	}
+
+	// versions already exported aren't fetched again
+	srv.Fail("getSnapshotContents", 1)
+	if err := e.Export(pageID); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,364 @@
+package export
+
+import (
+	"bytes"
+	"html"
+	"html/template"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/tmc/notion/collation"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// HTML renders pages as standalone HTML documents.
+type HTML struct {
+	// Highlighter renders code blocks. It defaults to a ChromaHighlighter.
+	Highlighter Highlighter
+	// Diagrams, if set, renders mermaid and plantuml code blocks as SVG
+	// diagrams, embedded as images in figures of class "diagram" so that
+	// scripts they may contain don't run. Code blocks whose diagrams fail
+	// to render are highlighted as code.
+	Diagrams DiagramRenderer
+	// Template lays out pages, see HTMLPage for its data. It executes the
+	// template named "page" if defined, the template itself otherwise. The
+	// default is NewHTMLTemplate().
+	Template *template.Template
+	// BlockMarkers encloses the HTML of every block, and the content of the
+	// page, in the comments <!--block:ID--> and <!--/block:ID-->, so that
+	// it can be replaced by the fragments of Update.
+	BlockMarkers bool
+	// Collator, if set, orders the pages of each level of the navigation
+	// tree of HTMLPage.Nav by title instead of in export order.
+	Collator *collation.Collator
+
+	custom renderFuncs
+}
+
+// RegisterRenderer makes h render blocks of type blockType with fn, e.g. to
+// render callouts with a particular icon as admonitions. It must not be
+// called while h renders pages.
+func (h *HTML) RegisterRenderer(blockType string, fn RenderFunc) {
+	h.custom.register(blockType, fn)
+}
+
+// Ext returns ".html".
+func (h *HTML) Ext() string {
+	return ".html"
+}
+
+// Render writes page to w as HTML.
+func (h *HTML) Render(w io.Writer, page *Page) error {
+	r := h.renderer(new(bytes.Buffer), page)
+	r.content(page.Content)
+	buf := new(bytes.Buffer)
+	if err := executeHTMLTemplate(buf, h, page, r.buf.String()); err != nil {
+		return err
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func (h *HTML) renderer(buf *bytes.Buffer, page *Page) *htmlRenderer {
+	r := &htmlRenderer{buf: buf, page: page, highlighter: h.Highlighter, diagrams: h.Diagrams, custom: h.custom, markers: h.BlockMarkers}
+	if r.highlighter == nil {
+		r.highlighter = &ChromaHighlighter{}
+	}
+	return r
+}
+
+type htmlRenderer struct {
+	buf         *bytes.Buffer
+	page        *Page
+	highlighter Highlighter
+	diagrams    DiagramRenderer
+	custom      renderFuncs
+	markers     bool
+	// skip is rendered as if it had no RenderFunc, see BlockContext.Default
+	skip *notiontypes.Block
+}
+
+func (r *htmlRenderer) text(b *notiontypes.Block) string {
+	return r.inline(b.InlineContent)
+}
+
+func (r *htmlRenderer) sub(buf *bytes.Buffer, skip *notiontypes.Block) blockRenderer {
+	return &htmlRenderer{buf: buf, page: r.page, highlighter: r.highlighter, diagrams: r.diagrams, custom: r.custom, markers: r.markers, skip: skip}
+}
+
+func (r *htmlRenderer) renderBlocks(blocks []*notiontypes.Block) {
+	r.blocks(blocks)
+}
+
+func (r *htmlRenderer) renderBlock(b *notiontypes.Block) {
+	r.block(b)
+}
+
+func (r *htmlRenderer) w(s string) {
+	r.buf.WriteString(s)
+}
+
+func (r *htmlRenderer) blocks(blocks []*notiontypes.Block) {
+	list := ""
+	for _, b := range blocks {
+		tag := listTag(b.Type)
+		if tag != list {
+			if list != "" {
+				r.w("</" + list + ">\n")
+			}
+			if tag != "" {
+				r.w("<" + tag + ">\n")
+			}
+			list = tag
+		}
+		r.block(b)
+	}
+	if list != "" {
+		r.w("</" + list + ">\n")
+	}
+}
+
+// content renders the blocks of the page.
+func (r *htmlRenderer) content(blocks []*notiontypes.Block) {
+	if !r.markers {
+		r.blocks(blocks)
+		return
+	}
+	start, end := blockMarkers(r.page.ID)
+	r.w(start + "\n")
+	r.blocks(blocks)
+	r.w(end)
+}
+
+func (r *htmlRenderer) block(b *notiontypes.Block) {
+	if r.markers && b.ID != "" {
+		start, end := blockMarkers(b.ID)
+		r.w(start)
+		defer r.w(end)
+	}
+	if r.custom.render(r.buf, b, r.skip, r.page, r) {
+		return
+	}
+	text := r.inline(b.InlineContent)
+	switch b.Type {
+	case notiontypes.BlockText:
+		r.w("<p>" + text + "</p>\n")
+		r.children(b)
+	case notiontypes.BlockHeader, notiontypes.BlockSubHeader, notiontypes.BlockSubSubHeader:
+		// the page title is the h1
+		tag := "h" + strconv.Itoa(headingLevel(b.Type)+1)
+		r.w("<" + tag + ` id="` + html.EscapeString(r.page.Anchor(b)) + `">` + text + "</" + tag + ">\n")
+	case notiontypes.BlockTableOfContents:
+		r.w(`<nav class="toc">` + "\n")
+		for _, h := range r.page.Headings() {
+			r.w(`<p class="toc-` + strconv.Itoa(h.Level) + `"><a href="#` + html.EscapeString(h.Anchor) + `">` + html.EscapeString(h.Text) + "</a></p>\n")
+		}
+		r.w("</nav>\n")
+	case notiontypes.BlockBulletedList, notiontypes.BlockNumberedList:
+		r.w("<li>" + text)
+		r.children(b)
+		r.w("</li>\n")
+	case notiontypes.BlockTodo:
+		checked := ""
+		if b.IsChecked {
+			checked = " checked"
+		}
+		r.w("<li><input type=\"checkbox\" disabled" + checked + "> " + text)
+		r.children(b)
+		r.w("</li>\n")
+	case notiontypes.BlockToggle:
+		r.w("<details><summary>" + text + "</summary>\n")
+		r.children(b)
+		r.w("</details>\n")
+	case notiontypes.BlockQuote:
+		r.w("<blockquote>" + text + "</blockquote>\n")
+	case notiontypes.BlockCode:
+		if img := r.diagram(b); img != "" {
+			r.w(`<figure class="diagram">` + img + "</figure>\n")
+			break
+		}
+		highlighted := new(bytes.Buffer)
+		if err := r.highlighter.Highlight(highlighted, b.Code, b.CodeLanguage); err != nil {
+			highlighted.Reset()
+			PlainHighlighter{}.Highlight(highlighted, b.Code, b.CodeLanguage)
+		}
+		r.buf.Write(highlighted.Bytes())
+	case notiontypes.BlockDivider:
+		r.w("<hr>\n")
+	case notiontypes.BlockImage:
+		attrs := ""
+		if sources := r.page.Srcset(b); len(sources) > 0 {
+			attrs = ` srcset="` + html.EscapeString(srcset(sources)) + `" sizes="100vw"`
+		}
+		r.w(`<img src="` + html.EscapeString(r.page.AssetURL(b)) + `"` + attrs + `>` + "\n")
+	case notiontypes.BlockBookmark:
+		title := b.Title
+		if title == "" {
+			title = b.Link
+		}
+		r.w("<p>" + r.link(html.EscapeString(title), b.Link) + "</p>\n")
+	case notiontypes.BlockPage:
+		r.w("<p>" + r.link(html.EscapeString(b.Title), r.page.PageURL(b.ID)) + "</p>\n")
+	case notiontypes.BlockBreadcrumb:
+		r.w(`<nav class="breadcrumb">`)
+		for _, a := range r.page.Breadcrumbs() {
+			r.w(`<a href="` + html.EscapeString(r.page.Rel(a.Path)) + `">` + html.EscapeString(a.Title) + "</a> / ")
+		}
+		r.w(html.EscapeString(r.page.Title) + "</nav>\n")
+	case notiontypes.BlockColumnList:
+		r.w(`<div class="columns" style="display: flex; gap: 1em">` + "\n")
+		for i, c := range b.Content {
+			r.w(`<div class="column" style="` + columnStyle(b.ColumnRatio(i)) + `">` + "\n")
+			r.blocks(c.Content)
+			r.w("</div>\n")
+		}
+		r.w("</div>\n")
+	case notiontypes.BlockColumn:
+		r.blocks(b.Content)
+	case notiontypes.BlockTable:
+		r.table(b)
+	case notiontypes.BlockSyncedBlock:
+		r.blocks(b.Content)
+	case notiontypes.BlockSyncedBlockCopy:
+		if link := r.page.SyncedBlockLink(b); link != "" {
+			r.w(`<p><a href="` + html.EscapeString(link) + `">Synced block</a></p>` + "\n")
+			break
+		}
+		r.blocks(b.Content)
+	case notiontypes.BlockFile:
+		r.w("<p>" + r.link(html.EscapeString(path.Base(b.Source)), r.page.AssetURL(b)) + "</p>\n")
+	case notiontypes.BlockAudio:
+		src := html.EscapeString(r.page.AssetURL(b))
+		r.w(`<audio controls src="` + src + `"><a href="` + src + `">` + html.EscapeString(embedTitle(b)) + "</a></audio>\n")
+	case notiontypes.BlockPDF:
+		src := html.EscapeString(r.page.AssetURL(b))
+		r.w(`<object data="` + src + `" type="application/pdf" width="100%" height="600">` +
+			`<a href="` + src + `">` + html.EscapeString(embedTitle(b)) + "</a></object>\n")
+	case notiontypes.BlockEmbed, notiontypes.BlockDrive, notiontypes.BlockFigma, notiontypes.BlockTweet,
+		notiontypes.BlockMaps, notiontypes.BlockGist:
+		r.embed(b)
+	case notiontypes.BlockCollectionView:
+		if t := r.page.Database(b); t != nil {
+			r.database(t)
+		}
+	case notiontypes.BlockVideo:
+		r.w("<p>" + r.link(html.EscapeString(b.Source), b.Source) + "</p>\n")
+	default:
+		if text != "" {
+			r.w("<p>" + text + "</p>\n")
+		}
+		r.children(b)
+	}
+}
+
+// embed renders b as an iframe if it has an embeddable url and as a link
+// card otherwise.
+func (r *htmlRenderer) embed(b *notiontypes.Block) {
+	title := html.EscapeString(embedTitle(b))
+	if u := safeURL(embedURL(b)); u != "" {
+		r.w(`<iframe src="` + html.EscapeString(u) + `" title="` + title + `" width="100%" height="450" frameborder="0" allowfullscreen></iframe>` + "\n")
+		return
+	}
+	r.w(`<p class="embed">` + r.link(title, b.Source) + "</p>\n")
+}
+
+func (r *htmlRenderer) table(b *notiontypes.Block) {
+	if len(b.Rows) == 0 {
+		return
+	}
+	format := b.FormatTable
+	if format == nil {
+		// tables whose rows are resolved from table_row blocks may have no
+		// format
+		format = &notiontypes.FormatTable{}
+	}
+	r.w("<table>\n")
+	for i, row := range b.Rows {
+		header := i == 0 && format.ColumnHeader
+		if header {
+			r.w("<thead>\n")
+		} else if i == 0 || i == 1 && format.ColumnHeader {
+			r.w("<tbody>\n")
+		}
+		r.w("<tr>")
+		for j, cell := range row {
+			tag := "td"
+			if header || j == 0 && format.RowHeader {
+				tag = "th"
+			}
+			r.w("<" + tag + ">" + r.inline(cell) + "</" + tag + ">")
+		}
+		r.w("</tr>\n")
+		if header {
+			r.w("</thead>\n")
+		}
+	}
+	if len(b.Rows) > 1 || len(b.Rows) == 1 && !format.ColumnHeader {
+		r.w("</tbody>\n")
+	}
+	r.w("</table>\n")
+}
+
+func (r *htmlRenderer) children(b *notiontypes.Block) {
+	if len(b.Content) == 0 {
+		return
+	}
+	r.w("\n")
+	r.blocks(b.Content)
+}
+
+// link returns an HTML link, or just text if url is empty or not a
+// safeURL.
+func (r *htmlRenderer) link(text, url string) string {
+	if url = safeURL(url); url == "" {
+		return text
+	}
+	return `<a href="` + html.EscapeString(url) + `">` + text + "</a>"
+}
+
+func (r *htmlRenderer) inline(inline []*notiontypes.InlineBlock) string {
+	var b strings.Builder
+	for _, i := range inline {
+		t := html.EscapeString(i.Text)
+		switch {
+		case i.Date != nil:
+			t = `<time>` + html.EscapeString(i.Date.StartDate) + `</time>`
+		case i.UserID != "":
+			t = `<span class="user">@` + html.EscapeString(i.UserID) + `</span>`
+		}
+		if i.AttrFlags&notiontypes.AttrCode != 0 {
+			t = "<code>" + t + "</code>"
+		}
+		if i.AttrFlags&notiontypes.AttrBold != 0 {
+			t = "<strong>" + t + "</strong>"
+		}
+		if i.AttrFlags&notiontypes.AttrItalic != 0 {
+			t = "<em>" + t + "</em>"
+		}
+		if i.AttrFlags&notiontypes.AttrStrikeThrought != 0 {
+			t = "<del>" + t + "</del>"
+		}
+		if i.Link != "" {
+			t = r.link(t, r.page.RewriteLink(i.Link))
+		}
+		b.WriteString(strings.Replace(t, "\n", "<br>", -1))
+	}
+	return b.String()
+}
+
+// columnStyle returns the CSS that sizes a column according to its ratio.
+func columnStyle(ratio float64) string {
+	return "flex: " + strconv.FormatFloat(ratio, 'f', -1, 64) + " 1 0; min-width: 0"
+}
+
+func listTag(typ string) string {
+	switch typ {
+	case notiontypes.BlockBulletedList, notiontypes.BlockTodo:
+		return "ul"
+	case notiontypes.BlockNumberedList:
+		return "ol"
+	}
+	return ""
+}
@@ -0,0 +1,37 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/notiontypes"
+)
+
+func TestHTMLTableWithoutFormat(t *testing.T) {
+	const pageID = "4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+	cell := func(text string) []*notiontypes.InlineBlock {
+		return []*notiontypes.InlineBlock{{Text: text}}
+	}
+	page := &notiontypes.Block{ID: pageID, Type: notiontypes.BlockPage, Title: "Notes", Content: []*notiontypes.Block{{
+		ID:   "table",
+		Type: notiontypes.BlockTable,
+		Rows: [][][]*notiontypes.InlineBlock{
+			{cell("a"), cell("b")},
+			{cell("c"), cell("d")},
+		},
+	}}}
+	h := &HTML{Highlighter: PlainHighlighter{}}
+	e := NewExporter(nil, t.TempDir(), WithRenderer(h))
+	e.reset()
+	e.add(&notion.Page{Block: page}, nil)
+
+	var buf bytes.Buffer
+	if err := h.Render(&buf, e.Page(pageID)); err != nil {
+		t.Fatal(err)
+	}
+	want := "<table>\n<tbody>\n<tr><td>a</td><td>b</td></tr>\n<tr><td>c</td><td>d</td></tr>\n</tbody>\n</table>\n"
+	if !bytes.Contains(buf.Bytes(), []byte(want)) {
+		t.Errorf("got %s, want it to contain %s", buf.String(), want)
+	}
+}
@@ -0,0 +1,170 @@
+package export
+
+import (
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/tmc/notion/collation"
+)
+
+// HTMLPage is the data passed to the templates of HTML.
+type HTMLPage struct {
+	// ID and Title are those of the page.
+	ID    string
+	Title string
+	// Icon is the emoji of the page, IconURL the URL of its icon image.
+	Icon    string
+	IconURL string
+	// Path is the page's file path, relative to the export directory.
+	Path string
+	// Root is the path of the export directory relative to the page, e.g.
+	// "." or "..", for links to files shared by all pages such as
+	// stylesheets.
+	Root string
+	// Content is the rendered content of the page, without its title.
+	Content template.HTML
+	// Breadcrumbs link to the exported ancestors of the page, starting with
+	// the export root.
+	Breadcrumbs []*HTMLLink
+	// Page is the page being rendered, e.g. for its PageProperties.
+	Page *Page
+
+	collator *collation.Collator
+}
+
+// HTMLLink is a link to an exported page, relative to the page rendered.
+type HTMLLink struct {
+	Title string
+	URL   string
+}
+
+// HTMLNavItem is a page in the navigation tree of an export.
+type HTMLNavItem struct {
+	HTMLLink
+	// Current is set for the page rendered, Open for it and its ancestors.
+	Current  bool
+	Open     bool
+	Children []*HTMLNavItem
+}
+
+// Nav returns the tree of the exported pages in export order, or ordered
+// by title with HTML.Collator. The export root, or the pages of
+// ExportPages, are at its top level.
+//
+// Incremental exports only render changed pages, so the navigation of
+// unchanged pages doesn't reflect added or removed pages.
+func (p *HTMLPage) Nav() []*HTMLNavItem {
+	e := p.Page.exporter
+	open := make(map[string]bool)
+	for _, id := range p.Page.Ancestors {
+		open[id] = true
+	}
+	items := make(map[string]*HTMLNavItem)
+	var roots []*HTMLNavItem
+	for _, page := range e.order {
+		item := &HTMLNavItem{
+			HTMLLink: HTMLLink{Title: page.Title, URL: p.Page.Rel(page.Path)},
+			Current:  page == p.Page,
+			Open:     page == p.Page || open[page.ID],
+		}
+		items[page.ID] = item
+		parent := (*HTMLNavItem)(nil)
+		for i := len(page.Ancestors) - 1; i >= 0 && parent == nil; i-- {
+			parent = items[page.Ancestors[i]]
+		}
+		if parent == nil {
+			roots = append(roots, item)
+			continue
+		}
+		parent.Children = append(parent.Children, item)
+	}
+	if p.collator != nil {
+		sortNav(p.collator, roots)
+	}
+	return roots
+}
+
+func sortNav(c *collation.Collator, items []*HTMLNavItem) {
+	c.Slice(items, func(i int) string { return items[i].Title })
+	for _, item := range items {
+		sortNav(c, item.Children)
+	}
+}
+
+// HTMLFuncs are the functions available to the templates of HTML created
+// with NewHTMLTemplate:
+//
+//   - slug returns its argument as by Slug.
+//   - date formats a time.Time with a layout, e.g.
+//     {{date .Page.UpdatedOn "2006-01-02"}}.
+var HTMLFuncs = template.FuncMap{
+	"slug": Slug,
+	"date": func(t time.Time, layout string) string {
+		return t.Format(layout)
+	},
+}
+
+// defaultHTMLLayout is the template of HTML pages. Its empty blocks are
+// meant to be redefined by themes.
+const defaultHTMLLayout = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+{{block "head" .}}{{end}}</head>
+<body>
+{{block "nav" .}}{{end}}<article>
+{{block "breadcrumbs" .}}{{end}}<h1>{{.Title}}</h1>
+{{.Content}}</article>
+{{block "footer" .}}{{end}}</body>
+</html>
+`
+
+// NewHTMLTemplate returns the default template of HTML, named "page", with
+// the functions HTMLFuncs. Parsing definitions of its (empty) templates
+// head, nav, breadcrumbs and footer into it adds to the page shell, e.g.
+//
+//	t := export.NewHTMLTemplate()
+//	template.Must(t.Parse(`{{define "head"}}<link rel="stylesheet" href="{{.Root}}/style.css">{{end}}`))
+//	renderer := &export.HTML{Template: t}
+//
+// while defining page replaces the shell altogether.
+func NewHTMLTemplate() *template.Template {
+	return template.Must(template.New("page").Funcs(HTMLFuncs).Parse(defaultHTMLLayout))
+}
+
+var defaultHTMLTemplate = NewHTMLTemplate()
+
+// executeHTMLTemplate writes page, whose rendered content is content, to w
+// with the template of h, or with the default template if h has none.
+func executeHTMLTemplate(w io.Writer, h *HTML, page *Page, content string) error {
+	t := h.Template
+	if t == nil {
+		t = defaultHTMLTemplate
+	}
+	data := &HTMLPage{
+		ID:       page.ID,
+		Title:    page.Title,
+		Path:     page.Path,
+		Root:     page.Rel("."),
+		Content:  template.HTML(content),
+		Page:     page,
+		collator: h.Collator,
+	}
+	if page.FormatPage != nil {
+		if icon := page.FormatPage.PageIcon; strings.Contains(icon, "/") {
+			data.IconURL = icon
+		} else {
+			data.Icon = icon
+		}
+	}
+	for _, a := range page.Breadcrumbs() {
+		data.Breadcrumbs = append(data.Breadcrumbs, &HTMLLink{Title: a.Title, URL: page.Rel(a.Path)})
+	}
+	if t.Lookup("page") != nil {
+		return t.ExecuteTemplate(w, "page", data)
+	}
+	return t.Execute(w, data)
+}
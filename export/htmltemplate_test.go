@@ -0,0 +1,81 @@
+package export
+
+import (
+	"bytes"
+	"html/template"
+	"testing"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/collation"
+	"github.com/tmc/notion/notiontypes"
+)
+
+func TestHTMLTemplate(t *testing.T) {
+	const (
+		rootID = "4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+		aID    = "5b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+		bID    = "6b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+		cID    = "7b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+	)
+	tmpl := NewHTMLTemplate()
+	template.Must(tmpl.Parse(`
+{{- define "head"}}<link rel="stylesheet" href="{{.Root}}/site.css">
+{{end}}
+{{- define "nav"}}<nav>{{template "items" .Nav}}</nav>
+{{end}}
+{{- define "items"}}<ul>{{range .}}<li{{if .Current}} class="current"{{end}}><a href="{{.URL}}">{{.Title}}</a>{{if .Open}}{{template "items" .Children}}{{end}}</li>{{end}}</ul>{{end}}
+{{- define "breadcrumbs"}}{{range .Breadcrumbs}}<a href="{{.URL}}">{{.Title}}</a> / {{end}}
+{{end}}`))
+	h := &HTML{Template: tmpl}
+	e := NewExporter(nil, t.TempDir(), WithRenderer(h))
+	e.reset()
+	page := func(id, title string) *notion.Page {
+		return &notion.Page{Block: &notiontypes.Block{ID: id, Type: notiontypes.BlockPage, Title: title}}
+	}
+	e.add(page(rootID, "Home"), nil)
+	e.add(page(aID, "A & B"), []string{rootID})
+	e.add(page(bID, "C"), []string{rootID})
+	e.hook = func(p *Page) {
+		if p.ID == aID {
+			p.Path = "sub/" + p.Path
+		}
+	}
+	e.hook(e.pages[aID])
+
+	var buf bytes.Buffer
+	if err := h.Render(&buf, e.pages[aID]); err != nil {
+		t.Fatal(err)
+	}
+	want := `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>A &amp; B</title>
+<link rel="stylesheet" href="../site.css">
+</head>
+<body>
+<nav><ul><li><a href="../home-4b1e8f5c9a0e4b6e8d3c1f2a3b4c5d6e.html">Home</a><ul><li class="current"><a href="a-b-5b1e8f5c9a0e4b6e8d3c1f2a3b4c5d6e.html">A &amp; B</a><ul></ul></li><li><a href="../c-6b1e8f5c9a0e4b6e8d3c1f2a3b4c5d6e.html">C</a></li></ul></li></ul></nav>
+<article>
+<a href="../home-4b1e8f5c9a0e4b6e8d3c1f2a3b4c5d6e.html">Home</a> / 
+<h1>A &amp; B</h1>
+</article>
+</body>
+</html>
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	c, err := collation.New("de")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.add(page(cID, "Ärger"), []string{rootID})
+	var titles []string
+	for _, item := range (&HTMLPage{Page: e.pages[aID], collator: c}).Nav()[0].Children {
+		titles = append(titles, item.Title)
+	}
+	if len(titles) != 3 || titles[0] != "A & B" || titles[1] != "Ärger" || titles[2] != "C" {
+		t.Errorf("got collated nav %q", titles)
+	}
+}
@@ -0,0 +1,207 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+
+	// register decoders for images commonly uploaded to notion
+	_ "image/gif"
+)
+
+// ImageSource is a version of an image asset, as listed in an HTML srcset.
+type ImageSource struct {
+	URL   string
+	Width int
+}
+
+// ImageVariant is an image produced by an ImageProcessor.
+type ImageVariant struct {
+	// Suffix is appended to the asset's base name to name the variant's file.
+	Suffix string
+	// Ext is the file extension matching the variant's encoding, including
+	// the dot, or empty to keep the extension of the asset.
+	Ext string
+	// Width is the variant's width in pixels.
+	Width int
+	Data  []byte
+}
+
+// ImageProcessor transforms images as they are downloaded during an export,
+// e.g. to resize them, change their encoding or strip metadata.
+type ImageProcessor interface {
+	// Process returns the variants to store for the image data. The first
+	// variant is the one referenced by the page, all variants are listed in
+	// the image's srcset.
+	Process(data []byte) ([]*ImageVariant, error)
+}
+
+// ImageEncoder encodes images into a particular format.
+type ImageEncoder interface {
+	// Ext returns the file extension of the format, including the dot.
+	Ext() string
+	Encode(w io.Writer, m image.Image) error
+}
+
+// JPEGEncoder encodes images as JPEG.
+type JPEGEncoder struct {
+	// Quality ranges from 1 to 100, it defaults to jpeg.DefaultQuality.
+	Quality int
+}
+
+// Ext returns ".jpg".
+func (e JPEGEncoder) Ext() string {
+	return ".jpg"
+}
+
+// Encode writes m to w as JPEG.
+func (e JPEGEncoder) Encode(w io.Writer, m image.Image) error {
+	q := e.Quality
+	if q == 0 {
+		q = jpeg.DefaultQuality
+	}
+	return jpeg.Encode(w, m, &jpeg.Options{Quality: q})
+}
+
+// PNGEncoder encodes images as PNG.
+type PNGEncoder struct{}
+
+// Ext returns ".png".
+func (PNGEncoder) Ext() string {
+	return ".png"
+}
+
+// Encode writes m to w as PNG.
+func (PNGEncoder) Encode(w io.Writer, m image.Image) error {
+	return png.Encode(w, m)
+}
+
+// Resizer is an ImageProcessor producing a full size version of an image
+// plus downscaled versions for each of Widths narrower than the original.
+//
+// Images are decoded and re-encoded, which strips metadata such as EXIF.
+// A WebP (or other) ImageEncoder may be supplied as Encoder; by default
+// PNG images stay PNG and everything else becomes JPEG. SVG and WebP
+// images can't be decoded and are kept as they are: WebP images keep their
+// metadata. Other images that fail to decode, e.g. truncated downloads,
+// are an error.
+type Resizer struct {
+	Widths  []int
+	Encoder ImageEncoder
+}
+
+// Process decodes data and returns the encoded variants.
+func (r *Resizer) Process(data []byte) ([]*ImageVariant, error) {
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if err == image.ErrFormat && (isSVG(data) || isWebP(data)) {
+		return []*ImageVariant{{Data: data}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	enc := r.Encoder
+	if enc == nil {
+		enc = JPEGEncoder{}
+		if format == "png" {
+			enc = PNGEncoder{}
+		}
+	}
+	full, err := encodeVariant(enc, src, "")
+	if err != nil {
+		return nil, err
+	}
+	variants := []*ImageVariant{full}
+	for _, w := range r.Widths {
+		if w <= 0 || w >= src.Bounds().Dx() {
+			continue
+		}
+		v, err := encodeVariant(enc, resize(src, w), "-"+strconv.Itoa(w)+"w")
+		if err != nil {
+			return nil, err
+		}
+		variants = append(variants, v)
+	}
+	return variants, nil
+}
+
+// isSVG reports whether data looks like an SVG document.
+func isSVG(data []byte) bool {
+	head := data
+	if len(head) > 512 {
+		head = head[:512]
+	}
+	head = bytes.TrimSpace(bytes.TrimPrefix(head, []byte("\xef\xbb\xbf")))
+	if !bytes.HasPrefix(head, []byte("<")) {
+		return false
+	}
+	return bytes.Contains(head, []byte("<svg"))
+}
+
+// isWebP reports whether data starts with the header of a WebP image.
+func isWebP(data []byte) bool {
+	return len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP"
+}
+
+func encodeVariant(enc ImageEncoder, m image.Image, suffix string) (*ImageVariant, error) {
+	buf := new(bytes.Buffer)
+	if err := enc.Encode(buf, m); err != nil {
+		return nil, err
+	}
+	return &ImageVariant{
+		Suffix: suffix,
+		Ext:    enc.Ext(),
+		Width:  m.Bounds().Dx(),
+		Data:   buf.Bytes(),
+	}, nil
+}
+
+// resize downscales src to the given width, keeping its aspect ratio, by
+// averaging the source pixels covered by each destination pixel.
+func resize(src image.Image, width int) image.Image {
+	sb := src.Bounds()
+	height := sb.Dy() * width / sb.Dx()
+	if height < 1 {
+		height = 1
+	}
+	rgba := image.NewRGBA(sb)
+	draw.Draw(rgba, sb, src, sb.Min, draw.Src)
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0, y1 := y*sb.Dy()/height, (y+1)*sb.Dy()/height
+		if y1 == y0 {
+			y1++
+		}
+		for x := 0; x < width; x++ {
+			x0, x1 := x*sb.Dx()/width, (x+1)*sb.Dx()/width
+			if x1 == x0 {
+				x1++
+			}
+			var r, g, b, a, n uint32
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					c := rgba.RGBAAt(sb.Min.X+sx, sb.Min.Y+sy)
+					r, g, b, a = r+uint32(c.R), g+uint32(c.G), b+uint32(c.B), a+uint32(c.A)
+					n++
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{uint8(r / n), uint8(g / n), uint8(b / n), uint8(a / n)})
+		}
+	}
+	return dst
+}
+
+// srcset formats sources as the value of an HTML srcset attribute.
+func srcset(sources []ImageSource) string {
+	var parts []string
+	for _, s := range sources {
+		parts = append(parts, fmt.Sprintf("%s %dw", s.URL, s.Width))
+	}
+	return strings.Join(parts, ", ")
+}
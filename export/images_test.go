@@ -0,0 +1,64 @@
+package export
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestResizer(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 400, 200))
+	for x := 0; x < 400; x++ {
+		src.Set(x, x/2, color.RGBA{255, 0, 0, 255})
+	}
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, src); err != nil {
+		t.Fatal(err)
+	}
+	variants, err := (&Resizer{Widths: []int{100, 800}}).Process(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(variants) != 2 {
+		t.Fatalf("got %d variants, want 2 (original and 100w)", len(variants))
+	}
+	if v := variants[1]; v.Width != 100 || v.Suffix != "-100w" || v.Ext != ".png" {
+		t.Errorf("unexpected variant %+v", v)
+	}
+	m, err := png.Decode(bytes.NewReader(variants[1].Data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := m.Bounds(); b.Dx() != 100 || b.Dy() != 50 {
+		t.Errorf("resized image is %v, want 100x50", b)
+	}
+}
+
+func TestResizerUndecodable(t *testing.T) {
+	r := &Resizer{Widths: []int{100}}
+	for _, data := range [][]byte{
+		[]byte(`<?xml version="1.0"?>
+<svg xmlns="http://www.w3.org/2000/svg"/>`),
+		[]byte("RIFF\x24\x00\x00\x00WEBPVP8 "),
+	} {
+		variants, err := r.Process(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(variants) != 1 || !bytes.Equal(variants[0].Data, data) || variants[0].Ext != "" {
+			t.Errorf("got variants %+v of %q, want the original", variants, data)
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, image.NewRGBA(image.Rect(0, 0, 10, 10))); err != nil {
+		t.Fatal(err)
+	}
+	for _, data := range [][]byte{buf.Bytes()[:buf.Len()/2], []byte("not an image")} {
+		if _, err := r.Process(data); err == nil {
+			t.Errorf("processed %q without error", data)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package export
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/tmc/notion"
+)
+
+// LinkRewriter rewrites links to notion pages within exported pages, so that
+// exports don't leak workspace URLs. Links to exported pages (and blocks on
+// them) become relative paths, links to other pages use PublicURLs if set
+// and are reported as external otherwise.
+type LinkRewriter struct {
+	// PublicURLs maps the ids of pages that are not part of the export to the
+	// URLs they're published at.
+	PublicURLs map[string]string
+	// BaseURL, if set, makes links to exported pages absolute by prefixing
+	// their Path with it instead of making them relative.
+	BaseURL string
+	// Unlink removes external links, keeping only their text.
+	Unlink bool
+
+	mu       sync.Mutex
+	external []ExternalLink
+}
+
+// ExternalLink is a link to a notion page outside of an export.
+type ExternalLink struct {
+	// Page is the id of the exported page containing the link.
+	Page string
+	// Target is the id of the linked page or block.
+	Target string
+	URL    string
+}
+
+// safeSchemes are the URL schemes exported links and sources may have.
+var safeSchemes = map[string]bool{"http": true, "https": true, "mailto": true, "tel": true, "ftp": true}
+
+// safeURL returns u, or the empty string if it has a scheme other than
+// http, https, mailto, tel or ftp, e.g. javascript: links, which would run
+// in the browsers of readers of the export. Relative URLs are safe.
+func safeURL(u string) string {
+	// browsers ignore whitespace and control characters in schemes
+	scheme := strings.Map(func(r rune) rune {
+		if r <= ' ' {
+			return -1
+		}
+		return r
+	}, u)
+	i := strings.IndexAny(scheme, ":/?#")
+	if i < 0 || scheme[i] != ':' {
+		return u
+	}
+	if !safeSchemes[strings.ToLower(scheme[:i])] {
+		return ""
+	}
+	return u
+}
+
+// External returns the links to notion pages outside the export found so far.
+func (r *LinkRewriter) External() []ExternalLink {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]ExternalLink(nil), r.external...)
+}
+
+func (r *LinkRewriter) reset() {
+	r.mu.Lock()
+	r.external = nil
+	r.mu.Unlock()
+}
+
+// Rewrite returns the link to use for link within page, or the empty
+// string if the link should be removed. Links not pointing to notion
+// pages are returned as is.
+func (r *LinkRewriter) Rewrite(page *Page, link string) string {
+	id, fragment, ok := parseNotionURL(link)
+	if !ok {
+		return link
+	}
+	return r.resolve(page, id, fragment, link)
+}
+
+// resolve returns the link to the page or block id, keeping fragment.
+// link is the original link, used for external targets.
+func (r *LinkRewriter) resolve(page *Page, id, fragment, link string) string {
+	e := page.exporter
+	target, ok := e.pages[id]
+	if !ok {
+		// links to headings point to their anchors
+		if target, ok = e.blockPages[id]; ok && target.anchors[id] != "" {
+			fragment = target.anchors[id]
+		}
+	}
+	suffix := ""
+	if fragment != "" {
+		suffix = "#" + fragment
+	}
+	if ok {
+		if target == page && fragment != "" {
+			return suffix
+		}
+		if r.BaseURL != "" {
+			return r.BaseURL + target.Path + suffix
+		}
+		return page.Rel(target.Path) + suffix
+	}
+	if u, ok := r.PublicURLs[id]; ok {
+		return u + suffix
+	}
+	r.mu.Lock()
+	r.external = append(r.external, ExternalLink{Page: page.ID, Target: id, URL: link})
+	r.mu.Unlock()
+	if r.Unlink {
+		return ""
+	}
+	return link
+}
+
+// parseNotionURL extracts the id of the page or block a link to notion
+// points to, and its fragment. It accepts notion.so and notion.site URLs
+// as well as the relative "/<id>" links of page mentions.
+func parseNotionURL(link string) (id, fragment string, ok bool) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return "", "", false
+	}
+	host := strings.TrimPrefix(u.Host, "www.")
+	if u.Host != "" && host != "notion.so" && !strings.HasSuffix(host, ".notion.site") {
+		return "", "", false
+	}
+	if u.Host == "" && (u.Scheme != "" || !strings.HasPrefix(u.Path, "/")) {
+		return "", "", false
+	}
+	// paths look like /<workspace>/<Title>-<id>, or /<id>; the id of a
+	// block within the page is in the fragment
+	segment := u.Path[strings.LastIndex(u.Path, "/")+1:]
+	if id = parseID(segment); id == "" {
+		if id = parseID(u.Query().Get("p")); id == "" {
+			return "", "", false
+		}
+	}
+	if block := parseID(u.Fragment); block != "" {
+		return block, "", true
+	}
+	return id, u.Fragment, true
+}
+
+// parseID returns the dashed form of the notion id at the end of s, or the
+// empty string if s doesn't end with one.
+func parseID(s string) string {
+	s = strings.Replace(s, "-", "", -1)
+	if len(s) < 32 {
+		return ""
+	}
+	id, err := notion.FormatID(s[len(s)-32:])
+	if err != nil {
+		return ""
+	}
+	return id
+}
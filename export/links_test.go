@@ -0,0 +1,79 @@
+package export
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/notiontypes"
+)
+
+func TestParseNotionURL(t *testing.T) {
+	tests := []struct {
+		link, id, fragment string
+		ok                 bool
+	}{
+		{"https://www.notion.so/Some-Page-aa8fc12667704e83ad6c3968dcfc9b82", "aa8fc126-6770-4e83-ad6c-3968dcfc9b82", "", true},
+		{"https://notion.so/team/aa8fc12667704e83ad6c3968dcfc9b82#intro", "aa8fc126-6770-4e83-ad6c-3968dcfc9b82", "intro", true},
+		{"https://www.notion.so/Page-aa8fc12667704e83ad6c3968dcfc9b82#bb8fc12667704e83ad6c3968dcfc9b82", "bb8fc126-6770-4e83-ad6c-3968dcfc9b82", "", true},
+		{"https://team.notion.site/aa8fc126-6770-4e83-ad6c-3968dcfc9b82", "aa8fc126-6770-4e83-ad6c-3968dcfc9b82", "", true},
+		{"/aa8fc12667704e83ad6c3968dcfc9b82", "aa8fc126-6770-4e83-ad6c-3968dcfc9b82", "", true},
+		{"https://www.notion.so/pricing", "", "", false},
+		{"https://example.com/aa8fc12667704e83ad6c3968dcfc9b82", "", "", false},
+		{"mailto:someone@example.com", "", "", false},
+	}
+	for _, tt := range tests {
+		id, fragment, ok := parseNotionURL(tt.link)
+		if id != tt.id || fragment != tt.fragment || ok != tt.ok {
+			t.Errorf("parseNotionURL(%q) = %q, %q, %v, want %q, %q, %v", tt.link, id, fragment, ok, tt.id, tt.fragment, tt.ok)
+		}
+	}
+}
+
+func TestSafeURL(t *testing.T) {
+	for u, want := range map[string]string{
+		"https://example.com/a?b#c":  "https://example.com/a?b#c",
+		"mailto:someone@example.com": "mailto:someone@example.com",
+		"../docs/page.html#intro":    "../docs/page.html#intro",
+		"page.html?q=a:b":            "page.html?q=a:b",
+		"javascript:alert(1)":        "",
+		" JavaScript:alert(1)":       "",
+		"java\tscript:alert(1)":      "",
+		"data:text/html,<script>":    "",
+		"vbscript:msgbox":            "",
+	} {
+		if got := safeURL(u); got != want {
+			t.Errorf("safeURL(%q) = %q, want %q", u, got, want)
+		}
+	}
+}
+
+func TestUnsafeLinks(t *testing.T) {
+	root := &notiontypes.Block{ID: "root", Type: notiontypes.BlockPage, Title: "Links", Content: []*notiontypes.Block{
+		{ID: "text", Type: notiontypes.BlockText, InlineContent: []*notiontypes.InlineBlock{
+			{Text: "click", Link: "javascript:alert(1)"},
+			{Text: " or "},
+			{Text: "read", Link: "https://example.com"},
+		}},
+		{ID: "bookmark", Type: notiontypes.BlockBookmark, Link: "javascript:alert(2)", Title: "Bookmark"},
+		{ID: "file", Type: notiontypes.BlockFile, Source: "javascript:alert(3)//report.pdf"},
+	}}
+	for _, r := range []Renderer{&HTML{}, &Markdown{}} {
+		dir := t.TempDir()
+		e := NewExporter(nil, dir, WithRenderer(r))
+		e.reset()
+		e.add(&notion.Page{Block: root}, nil)
+		if err := e.writeAll(); err != nil {
+			t.Fatal(err)
+		}
+		b, err := ioutil.ReadFile(filepath.Join(dir, e.order[0].Path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(b), "javascript") || !strings.Contains(string(b), "https://example.com") || !strings.Contains(string(b), "Bookmark") || !strings.Contains(string(b), "report.pdf") {
+			t.Errorf("%T: got\n%s", r, b)
+		}
+	}
+}
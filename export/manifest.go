@@ -0,0 +1,123 @@
+package export
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// ManifestFile is the name of the manifest written into the export
+// directory by every export.
+const ManifestFile = "manifest.json"
+
+// Manifest lists the pages of an export.
+type Manifest struct {
+	// Pages are in the order they were exported in.
+	Pages []*ManifestPage `json:"pages"`
+}
+
+// ManifestPage describes an exported page.
+type ManifestPage struct {
+	ID   string `json:"id"`
+	Path string `json:"path"`
+	// Version is the version of the page block. It doesn't change when only
+	// the page's content does.
+	Version int64 `json:"version"`
+	// Hash is the SHA-256 sum of everything the page was rendered from.
+	Hash string `json:"hash"`
+}
+
+// ReadManifest reads the manifest of an export from dir. It returns nil if
+// dir holds no manifest.
+func ReadManifest(dir string) (*Manifest, error) {
+	b, err := ioutil.ReadFile(filepath.Join(dir, ManifestFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	m := &Manifest{}
+	if err := json.Unmarshal(b, m); err != nil {
+		return nil, errors.Wrap(err, "reading export manifest")
+	}
+	return m, nil
+}
+
+// WithPreviousManifest skips writing pages that are unchanged since the
+// export m describes, as long as their files still exist. The previous
+// export must have used the same options and export directory.
+func WithPreviousManifest(m *Manifest) Option {
+	return func(e *Exporter) {
+		e.previous = make(map[string]*ManifestPage)
+		if m == nil {
+			return
+		}
+		for _, p := range m.Pages {
+			e.previous[p.ID] = p
+		}
+	}
+}
+
+// Manifest returns the manifest of the last export.
+func (e *Exporter) Manifest() *Manifest {
+	return e.manifest
+}
+
+// hashPages sets the manifest entries of all pages. As links between pages
+// are rendered from their paths, the paths of all pages are part of every
+// hash, so moving or adding a page changes all of them.
+func (e *Exporter) hashPages() (map[string]*ManifestPage, error) {
+	paths := make([]string, 0, len(e.order))
+	for _, page := range e.order {
+		paths = append(paths, page.ID+" "+page.Path)
+	}
+	sort.Strings(paths)
+	entries := make(map[string]*ManifestPage, len(e.order))
+	for _, page := range e.order {
+		b, err := json.Marshal(struct {
+			Page  *Page
+			Paths []string
+		}{page, paths})
+		if err != nil {
+			return nil, errors.Wrapf(err, "hashing page %v", page.ID)
+		}
+		sum := sha256.Sum256(b)
+		entries[page.ID] = &ManifestPage{
+			ID:      page.ID,
+			Path:    page.Path,
+			Version: page.Version,
+			Hash:    hex.EncodeToString(sum[:]),
+		}
+	}
+	return entries, nil
+}
+
+// unchanged reports whether entry matches the previous export and its
+// file still exists.
+func (e *Exporter) unchanged(entry *ManifestPage) bool {
+	old, ok := e.previous[entry.ID]
+	if !ok || *old != *entry {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(e.dir, filepath.FromSlash(entry.Path)))
+	return err == nil
+}
+
+func (e *Exporter) writeManifest(entries map[string]*ManifestPage) error {
+	e.manifest = &Manifest{}
+	for _, page := range e.order {
+		e.manifest.Pages = append(e.manifest.Pages, entries[page.ID])
+	}
+	b, err := json.MarshalIndent(e.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(e.dir, ManifestFile), b, 0644)
+}
@@ -0,0 +1,71 @@
+package export
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/corpus"
+)
+
+func TestIncrementalExport(t *testing.T) {
+	dir := t.TempDir()
+	export := func(pages ...*notion.Page) *Exporter {
+		m, err := ReadManifest(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e := NewExporter(nil, dir, WithPreviousManifest(m))
+		e.reset()
+		for _, p := range pages {
+			e.add(p, nil)
+		}
+		if err := e.writeAll(); err != nil {
+			t.Fatal(err)
+		}
+		return e
+	}
+	var pages []*notion.Page
+	for _, name := range []string{"text", "media"} {
+		c, err := corpus.Load(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := c.Page()
+		if err != nil {
+			t.Fatal(err)
+		}
+		pages = append(pages, &notion.Page{Block: b})
+	}
+	e := export(pages...)
+	m := e.Manifest()
+	if len(m.Pages) != 2 || m.Pages[0].Hash == "" || m.Pages[0].Path != e.order[0].Path {
+		t.Fatalf("got manifest %+v", m.Pages)
+	}
+
+	// mark the files, so that rewritten ones can be told apart
+	for _, p := range m.Pages {
+		if err := ioutil.WriteFile(filepath.Join(dir, p.Path), []byte("old"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pages[1].Content = pages[1].Content[1:]
+	e = export(pages...)
+	if b, _ := ioutil.ReadFile(filepath.Join(dir, m.Pages[0].Path)); string(b) != "old" {
+		t.Error("unchanged page was rewritten")
+	}
+	if b, _ := ioutil.ReadFile(filepath.Join(dir, m.Pages[1].Path)); string(b) == "old" {
+		t.Error("changed page was not rewritten")
+	}
+	if e.Manifest().Pages[1].Hash == m.Pages[1].Hash {
+		t.Error("hash of changed page didn't change")
+	}
+
+	os.Remove(filepath.Join(dir, m.Pages[0].Path))
+	export(pages...)
+	if _, err := os.Stat(filepath.Join(dir, m.Pages[0].Path)); err != nil {
+		t.Error("deleted page was not rewritten")
+	}
+}
@@ -0,0 +1,361 @@
+package export
+
+import (
+	"bytes"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+// Markdown renders pages as GitHub flavored Markdown.
+type Markdown struct {
+	// ColumnsAsHTML preserves the layout of column lists with HTML flexbox
+	// containers around the columns' Markdown. By default the columns are
+	// flattened in reading order.
+	ColumnsAsHTML bool
+	// FrontMatter emits YAML front-matter for pages that are database rows,
+	// see the FrontMatter function.
+	FrontMatter bool
+	// FrontMatterKeys maps property names to front-matter keys.
+	FrontMatterKeys map[string]string
+
+	custom renderFuncs
+}
+
+// RegisterRenderer makes m render blocks of type blockType with fn, e.g. to
+// render callouts with a particular icon as admonitions. Its output is
+// indented like the block. It must not be called while m renders pages.
+func (m *Markdown) RegisterRenderer(blockType string, fn RenderFunc) {
+	m.custom.register(blockType, fn)
+}
+
+// Ext returns ".md".
+func (m *Markdown) Ext() string {
+	return ".md"
+}
+
+// Render writes page to w as Markdown.
+func (m *Markdown) Render(w io.Writer, page *Page) error {
+	r := &markdownRenderer{buf: new(bytes.Buffer), page: page, columnsAsHTML: m.ColumnsAsHTML, custom: m.custom}
+	if m.FrontMatter {
+		if fm := FrontMatter(page, m.FrontMatterKeys); fm != "" {
+			r.buf.WriteString(fm + "\n")
+		}
+	}
+	r.line("", "# "+escapeMarkdown(page.Title))
+	r.buf.WriteString("\n")
+	r.blocks(page.Content, "")
+	_, err := w.Write(r.buf.Bytes())
+	return err
+}
+
+type markdownRenderer struct {
+	buf           *bytes.Buffer
+	page          *Page
+	columnsAsHTML bool
+	custom        renderFuncs
+	// obsidian is set when rendering Obsidian notes
+	obsidian *Obsidian
+	// titleLinks renders links to exported pages as [[Title]] references
+	titleLinks bool
+	// skip is rendered as if it had no RenderFunc, see BlockContext.Default
+	skip *notiontypes.Block
+}
+
+func (r *markdownRenderer) text(b *notiontypes.Block) string {
+	return r.inline(b.InlineContent)
+}
+
+func (r *markdownRenderer) sub(buf *bytes.Buffer, skip *notiontypes.Block) blockRenderer {
+	return &markdownRenderer{buf: buf, page: r.page, columnsAsHTML: r.columnsAsHTML, custom: r.custom, obsidian: r.obsidian, titleLinks: r.titleLinks, skip: skip}
+}
+
+func (r *markdownRenderer) renderBlocks(blocks []*notiontypes.Block) {
+	r.blocks(blocks, "")
+}
+
+func (r *markdownRenderer) renderBlock(b *notiontypes.Block) {
+	r.block(b, "")
+}
+
+func (r *markdownRenderer) blocks(blocks []*notiontypes.Block, indent string) {
+	prev := ""
+	for i, b := range blocks {
+		// items of the same list are not separated by blank lines
+		if i > 0 && !(isListItem(b.Type) && b.Type == prev) {
+			r.buf.WriteString("\n")
+		}
+		r.block(b, indent)
+		prev = b.Type
+	}
+}
+
+func (r *markdownRenderer) block(b *notiontypes.Block, indent string) {
+	if len(r.custom) > 0 {
+		buf := new(bytes.Buffer)
+		if r.custom.render(buf, b, r.skip, r.page, r) {
+			if buf.Len() > 0 {
+				r.lines(indent, "", "", strings.TrimRight(buf.String(), "\n"))
+			}
+			return
+		}
+	}
+	if r.obsidian != nil && r.obsidianBlock(b, indent) {
+		return
+	}
+	text := r.inline(b.InlineContent)
+	switch b.Type {
+	case notiontypes.BlockHeader:
+		r.line(indent, "# "+text)
+	case notiontypes.BlockSubHeader:
+		r.line(indent, "## "+text)
+	case notiontypes.BlockSubSubHeader:
+		r.line(indent, "### "+text)
+	case notiontypes.BlockBulletedList, notiontypes.BlockToggle:
+		r.lines(indent, "- ", "  ", text)
+		r.children(b, indent+"  ")
+	case notiontypes.BlockNumberedList:
+		r.lines(indent, "1. ", "   ", text)
+		r.children(b, indent+"   ")
+	case notiontypes.BlockTodo:
+		mark := "- [ ] "
+		if b.IsChecked {
+			mark = "- [x] "
+		}
+		r.lines(indent, mark, "  ", text)
+		r.children(b, indent+"  ")
+	case notiontypes.BlockQuote:
+		r.lines(indent, "> ", "> ", text)
+	case notiontypes.BlockCode:
+		r.line(indent, "```"+notiontypes.LinguistName(b.CodeLanguage))
+		r.lines(indent, "", "", b.Code)
+		r.line(indent, "```")
+	case notiontypes.BlockDivider:
+		r.line(indent, "---")
+	case notiontypes.BlockImage:
+		r.line(indent, "![]("+r.page.AssetURL(b)+")")
+	case notiontypes.BlockBookmark:
+		title := b.Title
+		if title == "" {
+			title = b.Link
+		}
+		r.line(indent, r.link(escapeMarkdown(title), b.Link))
+		if b.Description != "" {
+			r.buf.WriteString("\n")
+			r.lines(indent, "", "", escapeMarkdown(b.Description))
+		}
+	case notiontypes.BlockPage:
+		r.line(indent, r.link(escapeMarkdown(b.Title), r.page.PageURL(b.ID)))
+	case notiontypes.BlockTableOfContents:
+		for _, h := range r.page.Headings() {
+			r.line(indent+strings.Repeat("  ", h.Level-1), "- ["+escapeMarkdown(h.Text)+"](#"+h.Anchor+")")
+		}
+	case notiontypes.BlockBreadcrumb:
+		var links []string
+		for _, a := range r.page.Breadcrumbs() {
+			links = append(links, "["+escapeMarkdown(a.Title)+"]("+r.page.Rel(a.Path)+")")
+		}
+		links = append(links, escapeMarkdown(r.page.Title))
+		r.line(indent, strings.Join(links, " / "))
+	case notiontypes.BlockColumnList:
+		r.columns(b, indent)
+	case notiontypes.BlockColumn:
+		r.blocks(b.Content, indent)
+	case notiontypes.BlockTable:
+		r.table(b, indent)
+	case notiontypes.BlockSyncedBlock:
+		r.blocks(b.Content, indent)
+	case notiontypes.BlockSyncedBlockCopy:
+		if link := r.page.SyncedBlockLink(b); link != "" {
+			r.line(indent, "[Synced block]("+link+")")
+			break
+		}
+		r.blocks(b.Content, indent)
+	case notiontypes.BlockFile:
+		r.line(indent, r.link(escapeMarkdown(path.Base(b.Source)), r.page.AssetURL(b)))
+	case notiontypes.BlockAudio, notiontypes.BlockPDF, notiontypes.BlockEmbed, notiontypes.BlockDrive,
+		notiontypes.BlockFigma, notiontypes.BlockTweet, notiontypes.BlockMaps, notiontypes.BlockGist:
+		r.line(indent, r.link(escapeMarkdown(embedTitle(b)), r.page.AssetURL(b)))
+	case notiontypes.BlockCollectionView:
+		if t := r.page.Database(b); t != nil {
+			r.database(t, indent)
+		}
+	case notiontypes.BlockVideo:
+		r.line(indent, r.link(escapeMarkdown(b.Source), b.Source))
+	default:
+		if text != "" {
+			r.lines(indent, "", "", text)
+		}
+		r.children(b, indent)
+	}
+}
+
+// table renders a simple table. Markdown tables always have a header, so
+// the first row is used as one.
+func (r *markdownRenderer) table(b *notiontypes.Block, indent string) {
+	for i, row := range b.Rows {
+		cells := make([]string, len(row))
+		for j, cell := range row {
+			cells[j] = markdownTableEscaper.Replace(r.inline(cell))
+		}
+		r.line(indent, "| "+strings.Join(cells, " | ")+" |")
+		if i == 0 {
+			r.line(indent, "|"+strings.Repeat(" --- |", len(row)))
+		}
+	}
+}
+
+// columns renders the columns of a column list one after the other, or
+// side by side if columnsAsHTML is set. Markdown within HTML blocks must be
+// separated from the tags by blank lines.
+func (r *markdownRenderer) columns(b *notiontypes.Block, indent string) {
+	if !r.columnsAsHTML {
+		for i, c := range b.Content {
+			if i > 0 {
+				r.buf.WriteString("\n")
+			}
+			r.blocks(c.Content, indent)
+		}
+		return
+	}
+	r.line(indent, `<div style="display: flex; gap: 1em">`)
+	for i, c := range b.Content {
+		r.line(indent, `<div style="`+columnStyle(b.ColumnRatio(i))+`">`)
+		r.buf.WriteString("\n")
+		r.blocks(c.Content, indent)
+		r.buf.WriteString("\n")
+		r.line(indent, "</div>")
+	}
+	r.line(indent, "</div>")
+}
+
+func (r *markdownRenderer) children(b *notiontypes.Block, indent string) {
+	if len(b.Content) == 0 {
+		return
+	}
+	// a blank line before a nested list would make the list loose
+	if !isListItem(b.Type) || !isListItem(b.Content[0].Type) {
+		r.buf.WriteString("\n")
+	}
+	r.blocks(b.Content, indent)
+}
+
+// link returns a Markdown link, or just text if url is empty or not a
+// safeURL.
+func (r *markdownRenderer) link(text, url string) string {
+	if url = safeURL(url); url == "" {
+		return text
+	}
+	return "[" + text + "](" + url + ")"
+}
+
+func (r *markdownRenderer) line(indent, s string) {
+	r.buf.WriteString(indent)
+	r.buf.WriteString(s)
+	r.buf.WriteString("\n")
+}
+
+// lines writes a possibly multi-line string, prefixing the first line with
+// first and subsequent lines with rest.
+func (r *markdownRenderer) lines(indent, first, rest, s string) {
+	for i, l := range strings.Split(s, "\n") {
+		prefix := rest
+		if i == 0 {
+			prefix = first
+		}
+		r.line(indent, prefix+l)
+	}
+}
+
+func (r *markdownRenderer) inline(inline []*notiontypes.InlineBlock) string {
+	var b strings.Builder
+	for _, i := range inline {
+		t := escapeMarkdown(i.Text)
+		ref := ""
+		switch {
+		case i.Link == "":
+		case r.obsidian != nil:
+			ref = r.wikilink(i.Text, i.Link)
+		case r.titleLinks:
+			ref = r.titleLink(i.Text, i.Link)
+		}
+		switch {
+		case ref != "":
+			t = ref
+		case i.Date != nil:
+			t = i.Date.StartDate
+		case i.UserID != "":
+			t = "@" + i.UserID
+		}
+		if i.AttrFlags&notiontypes.AttrCode != 0 && ref == "" {
+			t = wrapMarkdown(i.Text, "`")
+		}
+		if i.AttrFlags&notiontypes.AttrBold != 0 {
+			t = wrapMarkdown(t, "**")
+		}
+		if i.AttrFlags&notiontypes.AttrItalic != 0 {
+			t = wrapMarkdown(t, "*")
+		}
+		if i.AttrFlags&notiontypes.AttrStrikeThrought != 0 {
+			t = wrapMarkdown(t, "~~")
+		}
+		if i.Link != "" && ref == "" {
+			t = r.link(t, r.page.RewriteLink(i.Link))
+		}
+		b.WriteString(t)
+	}
+	return b.String()
+}
+
+// wrapMarkdown surrounds s with marker, keeping surrounding whitespace
+// outside of the markers as Markdown requires.
+func wrapMarkdown(s, marker string) string {
+	trimmed := strings.TrimSpace(s)
+	if trimmed == "" {
+		return s
+	}
+	i := strings.Index(s, trimmed)
+	return s[:i] + marker + trimmed + marker + s[i+len(trimmed):]
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+	`*`, `\*`,
+	`_`, `\_`,
+	`[`, `\[`,
+	`]`, `\]`,
+)
+
+var markdownTableEscaper = strings.NewReplacer(
+	"|", `\|`,
+	"\n", "<br>",
+)
+
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+func isListItem(typ string) bool {
+	switch typ {
+	case notiontypes.BlockBulletedList, notiontypes.BlockNumberedList, notiontypes.BlockTodo, notiontypes.BlockToggle:
+		return true
+	}
+	return false
+}
+
+func imageURL(b *notiontypes.Block) string {
+	if b.ImageURL != "" {
+		return b.ImageURL
+	}
+	if b.FormatImage != nil && b.FormatImage.ImageURL != "" {
+		return b.FormatImage.ImageURL
+	}
+	return b.Source
+}
+
+func notionURL(id string) string {
+	return "https://www.notion.so/" + strings.Replace(id, "-", "", -1)
+}
@@ -0,0 +1,250 @@
+package export
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+// MindMapNode is a page or a heading in the structure of an export.
+type MindMapNode struct {
+	ID    string
+	Title string
+	// Level is 0 for pages, and the level of headings.
+	Level int
+	// URL is the notion URL of the page or heading.
+	URL string
+	// Path is the exported file of the page, relative to the export
+	// directory, followed by the anchor of headings.
+	Path       string
+	LastEdited time.Time
+	// Children are the headings and sub-pages of a page, the sub-pages
+	// under the headings they follow, and the lower level headings and
+	// sub-pages following headings.
+	Children []*MindMapNode
+}
+
+// MindMap returns the hierarchy of the pages of the last export and of
+// their headings, for mind-mapping tools: see WriteOPML and WriteGraphML.
+// Its roots are the pages whose parent wasn't exported, usually only the
+// root of the export.
+func (e *Exporter) MindMap() []*MindMapNode {
+	nodes := make(map[string]*MindMapNode, len(e.order))
+	for _, page := range e.order {
+		nodes[page.ID] = &MindMapNode{
+			ID:         page.ID,
+			Title:      page.Title,
+			URL:        notionURL(page.ID),
+			Path:       page.Path,
+			LastEdited: lastEdited(page.Block),
+		}
+	}
+	placed := make(map[string]bool)
+	for _, page := range e.order {
+		m := &mindMapper{page: page, nodes: nodes, placed: placed, stack: []*MindMapNode{nodes[page.ID]}}
+		m.blocks(page.Content)
+	}
+	var roots []*MindMapNode
+	for _, page := range e.order {
+		if placed[page.ID] {
+			continue
+		}
+		if parent := nodes[pageParent(page)]; parent != nil {
+			// e.g. database rows
+			parent.Children = append(parent.Children, nodes[page.ID])
+			continue
+		}
+		roots = append(roots, nodes[page.ID])
+	}
+	return roots
+}
+
+// pageParent returns the id of the parent page of page, if exported.
+func pageParent(page *Page) string {
+	if len(page.Ancestors) == 0 {
+		return ""
+	}
+	return page.Ancestors[len(page.Ancestors)-1]
+}
+
+type mindMapper struct {
+	page   *Page
+	nodes  map[string]*MindMapNode
+	placed map[string]bool
+	// stack holds the page and the headings the next blocks are under
+	stack []*MindMapNode
+}
+
+func (m *mindMapper) blocks(blocks []*notiontypes.Block) {
+	for _, b := range blocks {
+		top := m.stack[len(m.stack)-1]
+		if b.IsPage() {
+			// sub-pages, not links to other pages
+			if n, ok := m.nodes[b.ID]; ok && !m.placed[b.ID] && pageParent(m.page.exporter.pages[b.ID]) == m.page.ID {
+				top.Children = append(top.Children, n)
+				m.placed[b.ID] = true
+			}
+			continue
+		}
+		if level := headingLevel(b.Type); level > 0 {
+			for len(m.stack) > 1 && m.stack[len(m.stack)-1].Level >= level {
+				m.stack = m.stack[:len(m.stack)-1]
+			}
+			n := &MindMapNode{
+				ID:         b.ID,
+				Title:      plainText(b.InlineContent),
+				Level:      level,
+				URL:        notionURL(m.page.ID) + "#" + strings.Replace(b.ID, "-", "", -1),
+				Path:       m.page.Path + "#" + m.page.Anchor(b),
+				LastEdited: lastEdited(b),
+			}
+			parent := m.stack[len(m.stack)-1]
+			parent.Children = append(parent.Children, n)
+			m.stack = append(m.stack, n)
+		}
+		m.blocks(b.Content)
+	}
+}
+
+type opml struct {
+	XMLName      xml.Name       `xml:"opml"`
+	Version      string         `xml:"version,attr"`
+	Title        string         `xml:"head>title"`
+	DateModified string         `xml:"head>dateModified,omitempty"`
+	Outlines     []*opmlOutline `xml:"body>outline"`
+}
+
+type opmlOutline struct {
+	Text       string         `xml:"text,attr"`
+	Type       string         `xml:"type,attr"`
+	URL        string         `xml:"url,attr"`
+	Path       string         `xml:"path,attr,omitempty"`
+	LastEdited string         `xml:"lastEdited,attr,omitempty"`
+	Outlines   []*opmlOutline `xml:"outline"`
+}
+
+// WriteOPML writes nodes to w as an OPML 2.0 outline titled title. Outline
+// elements are links to the notion URLs of pages and headings, with the
+// path and the last edit time (RFC 3339) of nodes in the path and
+// lastEdited attributes.
+func WriteOPML(w io.Writer, title string, nodes []*MindMapNode) error {
+	doc := &opml{Version: "2.0", Title: title}
+	var latest time.Time
+	var outlines func([]*MindMapNode) []*opmlOutline
+	outlines = func(nodes []*MindMapNode) []*opmlOutline {
+		var res []*opmlOutline
+		for _, n := range nodes {
+			if n.LastEdited.After(latest) {
+				latest = n.LastEdited
+			}
+			res = append(res, &opmlOutline{
+				Text:       n.Title,
+				Type:       "link",
+				URL:        n.URL,
+				Path:       n.Path,
+				LastEdited: mindMapTime(n.LastEdited),
+				Outlines:   outlines(n.Children),
+			})
+		}
+		return res
+	}
+	doc.Outlines = outlines(nodes)
+	if !latest.IsZero() {
+		doc.DateModified = latest.Format(time.RFC1123Z)
+	}
+	return writeXML(w, doc)
+}
+
+type graphML struct {
+	XMLName xml.Name      `xml:"graphml"`
+	XMLNS   string        `xml:"xmlns,attr"`
+	Keys    []*graphMLKey `xml:"key"`
+	Graph   struct {
+		ID          string         `xml:"id,attr"`
+		EdgeDefault string         `xml:"edgedefault,attr"`
+		Nodes       []*graphMLNode `xml:"node"`
+		Edges       []*graphMLEdge `xml:"edge"`
+	} `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLNode struct {
+	ID   string         `xml:"id,attr"`
+	Data []*graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+type graphMLEdge struct {
+	Source string `xml:"source,attr"`
+	Target string `xml:"target,attr"`
+}
+
+// WriteGraphML writes nodes to w as a GraphML tree, with edges from parents
+// to children. Nodes have their block id as id, and label, kind (page or
+// heading), url, path and lastEdited (RFC 3339) data.
+func WriteGraphML(w io.Writer, nodes []*MindMapNode) error {
+	doc := &graphML{XMLNS: "http://graphml.graphdrawing.org/xmlns"}
+	for _, key := range []string{"label", "kind", "url", "path", "lastEdited"} {
+		doc.Keys = append(doc.Keys, &graphMLKey{ID: key, For: "node", Name: key, Type: "string"})
+	}
+	doc.Graph.ID, doc.Graph.EdgeDefault = "G", "directed"
+	var add func(parent string, nodes []*MindMapNode)
+	add = func(parent string, nodes []*MindMapNode) {
+		for _, n := range nodes {
+			kind := "page"
+			if n.Level > 0 {
+				kind = "heading"
+			}
+			node := &graphMLNode{ID: n.ID}
+			for _, d := range [][2]string{{"label", n.Title}, {"kind", kind}, {"url", n.URL}, {"path", n.Path}, {"lastEdited", mindMapTime(n.LastEdited)}} {
+				if d[1] != "" {
+					node.Data = append(node.Data, &graphMLData{Key: d[0], Value: d[1]})
+				}
+			}
+			doc.Graph.Nodes = append(doc.Graph.Nodes, node)
+			if parent != "" {
+				doc.Graph.Edges = append(doc.Graph.Edges, &graphMLEdge{Source: parent, Target: n.ID})
+			}
+			add(n.ID, n.Children)
+		}
+	}
+	add("", nodes)
+	return writeXML(w, doc)
+}
+
+// lastEdited returns the time b was last edited, if known.
+func lastEdited(b *notiontypes.Block) time.Time {
+	if b.LastEditedTime == 0 {
+		return time.Time{}
+	}
+	return b.UpdatedOn().UTC()
+}
+
+func mindMapTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func writeXML(w io.Writer, v interface{}) error {
+	b, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, xml.Header+string(b)+"\n")
+	return err
+}
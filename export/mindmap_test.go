@@ -0,0 +1,84 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/notiontypes"
+)
+
+func TestMindMap(t *testing.T) {
+	const (
+		rootID = "aa8fc126-6770-4e83-ad6c-3968dcfc9b80"
+		subID  = "aa8fc126-6770-4e83-ad6c-3968dcfc9b81"
+		rowID  = "aa8fc126-6770-4e83-ad6c-3968dcfc9b82"
+	)
+	heading := func(typ, id, s string) *notiontypes.Block {
+		return &notiontypes.Block{ID: id, Type: typ, InlineContent: []*notiontypes.InlineBlock{{Text: s}}, LastEditedTime: 1700000000000}
+	}
+	root := &notiontypes.Block{ID: rootID, Type: notiontypes.BlockPage, Title: "Home", LastEditedTime: 1700000000000, Content: []*notiontypes.Block{
+		heading(notiontypes.BlockHeader, "h1", "Plans"),
+		heading(notiontypes.BlockSubSubHeader, "h2", "Soon"),
+		{ID: subID, Type: notiontypes.BlockPage, Title: "Guide"},
+		heading(notiontypes.BlockSubHeader, "h3", "Later"),
+		heading(notiontypes.BlockHeader, "h4", "Notes"),
+	}}
+	sub := &notiontypes.Block{ID: subID, Type: notiontypes.BlockPage, Title: "Guide"}
+	row := &notiontypes.Block{ID: rowID, Type: notiontypes.BlockPage, Title: "Row"}
+
+	e := NewExporter(nil, t.TempDir())
+	e.reset()
+	e.add(&notion.Page{Block: root}, nil)
+	e.add(&notion.Page{Block: sub}, []string{rootID})
+	e.add(&notion.Page{Block: row}, []string{rootID})
+	roots := e.MindMap()
+
+	var tree func(nodes []*MindMapNode, indent string) string
+	tree = func(nodes []*MindMapNode, indent string) string {
+		s := ""
+		for _, n := range nodes {
+			s += indent + n.Title + " " + n.Path + "\n" + tree(n.Children, indent+"  ")
+		}
+		return s
+	}
+	want := "Home home-aa8fc12667704e83ad6c3968dcfc9b80.md\n" +
+		"  Plans home-aa8fc12667704e83ad6c3968dcfc9b80.md#plans\n" +
+		"    Soon home-aa8fc12667704e83ad6c3968dcfc9b80.md#soon\n" +
+		"      Guide guide-aa8fc12667704e83ad6c3968dcfc9b81.md\n" +
+		"    Later home-aa8fc12667704e83ad6c3968dcfc9b80.md#later\n" +
+		"  Notes home-aa8fc12667704e83ad6c3968dcfc9b80.md#notes\n" +
+		"  Row row-aa8fc12667704e83ad6c3968dcfc9b82.md\n"
+	if got := tree(roots, ""); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := WriteOPML(buf, "Home", roots); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		`<dateModified>Tue, 14 Nov 2023 22:13:20 +0000</dateModified>`,
+		`<outline text="Home" type="link" url="https://www.notion.so/aa8fc12667704e83ad6c3968dcfc9b80" path="home-aa8fc12667704e83ad6c3968dcfc9b80.md" lastEdited="2023-11-14T22:13:20Z">`,
+		`<outline text="Soon" type="link" url="https://www.notion.so/aa8fc12667704e83ad6c3968dcfc9b80#h2"`,
+	} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("OPML is missing %s:\n%s", s, buf)
+		}
+	}
+
+	buf.Reset()
+	if err := WriteGraphML(buf, roots); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		`<key id="label" for="node" attr.name="label" attr.type="string"></key>`,
+		`<data key="kind">heading</data>`,
+		`<edge source="h2" target="` + subID + `"></edge>`,
+	} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("GraphML is missing %s:\n%s", s, buf)
+		}
+	}
+}
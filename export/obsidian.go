@@ -0,0 +1,160 @@
+package export
+
+import (
+	"bytes"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+// ObsidianAttachments is the directory of the vault assets are downloaded
+// into for Obsidian exports, see Download.Dir.
+const ObsidianAttachments = "attachments"
+
+// Obsidian renders pages as the Markdown notes of an Obsidian vault. Links
+// between exported pages are wikilinks, downloaded assets are embedded
+// with ![[...]], callouts become Obsidian callouts and every note has
+// front-matter with the notion id and URL of its page (notion-id and
+// notion-url), so that vaults can be synced again, and its title as an
+// alias. Use Download with Dir set to ObsidianAttachments for assets.
+type Obsidian struct {
+	// FrontMatterKeys maps property names to front-matter keys.
+	FrontMatterKeys map[string]string
+	// CalloutTypes maps the icons of callouts to Obsidian callout types,
+	// e.g. "🔥" to "danger", adding to and overriding the default mapping
+	// of common icons. Callouts with other icons are notes.
+	CalloutTypes map[string]string
+
+	custom renderFuncs
+}
+
+// RegisterRenderer makes o render blocks of type blockType with fn, see
+// Markdown.RegisterRenderer.
+func (o *Obsidian) RegisterRenderer(blockType string, fn RenderFunc) {
+	o.custom.register(blockType, fn)
+}
+
+// Ext returns ".md".
+func (o *Obsidian) Ext() string {
+	return ".md"
+}
+
+// Render writes page to w as an Obsidian note.
+func (o *Obsidian) Render(w io.Writer, page *Page) error {
+	r := &markdownRenderer{buf: new(bytes.Buffer), page: page, custom: o.custom, obsidian: o}
+	note := *page
+	note.FrontMatterParams = map[string]string{
+		"notion-id":  YAMLString(page.ID),
+		"notion-url": YAMLString(notionURL(page.ID)),
+		"aliases":    "[" + YAMLString(page.Title) + "]",
+	}
+	for k, v := range page.FrontMatterParams {
+		note.FrontMatterParams[k] = v
+	}
+	r.buf.WriteString(FrontMatter(&note, o.FrontMatterKeys) + "\n")
+	r.line("", "# "+escapeMarkdown(page.Title))
+	r.buf.WriteString("\n")
+	r.blocks(page.Content, "")
+	_, err := w.Write(r.buf.Bytes())
+	return err
+}
+
+// obsidianCallouts maps common callout icons to Obsidian callout types.
+var obsidianCallouts = map[string]string{
+	"💡":  "tip",
+	"⚠️": "warning",
+	"⚠":  "warning",
+	"❗":  "important",
+	"❓":  "question",
+	"✅":  "success",
+	"❌":  "failure",
+	"🚨":  "danger",
+	"🐛":  "bug",
+	"📝":  "note",
+	"ℹ️": "info",
+	"💬":  "quote",
+	"📌":  "abstract",
+}
+
+func (o *Obsidian) calloutType(b *notiontypes.Block) string {
+	icon := ""
+	if b.FormatCallout != nil {
+		icon = b.FormatCallout.PageIcon
+	}
+	if t, ok := o.CalloutTypes[icon]; ok {
+		return t
+	}
+	if t, ok := obsidianCallouts[icon]; ok {
+		return t
+	}
+	return "note"
+}
+
+// obsidianBlock renders the blocks that differ in Obsidian notes, and
+// reports whether b is one of them.
+func (r *markdownRenderer) obsidianBlock(b *notiontypes.Block, indent string) bool {
+	switch b.Type {
+	case notiontypes.BlockPage:
+		if link := r.wikilink(b.Title, notionURL(b.ID)); link != "" {
+			r.line(indent, link)
+			return true
+		}
+	case notiontypes.BlockImage, notiontypes.BlockFile, notiontypes.BlockAudio, notiontypes.BlockPDF:
+		ref := r.page.AssetURL(b)
+		if u, err := url.Parse(ref); err == nil && u.Scheme == "" && ref != "" {
+			// downloaded into the vault
+			r.line(indent, "![["+path.Clean(path.Join(path.Dir(r.page.Path), ref))+"]]")
+			return true
+		}
+	case notiontypes.BlockCallout:
+		r.lines(indent, "> [!"+r.obsidian.calloutType(b)+"] ", "> ", r.inline(b.InlineContent))
+		if len(b.Content) > 0 {
+			buf := new(bytes.Buffer)
+			r.sub(buf, nil).renderBlocks(b.Content)
+			r.lines(indent, "> ", "> ", strings.TrimRight(buf.String(), "\n"))
+		}
+		return true
+	}
+	return false
+}
+
+// wikilink returns the wikilink to the exported page or heading link
+// points to, showing text, or the empty string if link points elsewhere.
+func (r *markdownRenderer) wikilink(text, link string) string {
+	id, _, ok := parseNotionURL(link)
+	if !ok {
+		return ""
+	}
+	e := r.page.exporter
+	heading := ""
+	target, ok := e.pages[id]
+	if !ok {
+		if target, ok = e.blockPages[id]; !ok {
+			return ""
+		}
+		for _, h := range target.headings {
+			if h.Block.ID == id {
+				heading = "#" + wikilinkText(h.Text)
+			}
+		}
+	}
+	name := strings.TrimSuffix(target.Path, path.Ext(target.Path))
+	if target == r.page && heading != "" {
+		name = ""
+	}
+	text = wikilinkText(text)
+	if text == "" || text == name {
+		return "[[" + name + heading + "]]"
+	}
+	return "[[" + name + heading + "|" + text + "]]"
+}
+
+var wikilinkEscaper = strings.NewReplacer("[", "", "]", "", "|", "", "#", "", "\n", " ")
+
+// wikilinkText removes the characters wikilinks can't contain from s.
+func wikilinkText(s string) string {
+	return strings.TrimSpace(wikilinkEscaper.Replace(s))
+}
@@ -0,0 +1,77 @@
+package export
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/notiontypes"
+)
+
+func TestObsidian(t *testing.T) {
+	const (
+		rootID    = "aa8fc126-6770-4e83-ad6c-3968dcfc9b80"
+		subID     = "aa8fc126-6770-4e83-ad6c-3968dcfc9b81"
+		headingID = "aa8fc126-6770-4e83-ad6c-3968dcfc9b82"
+		imageID   = "aa8fc126-6770-4e83-ad6c-3968dcfc9b83"
+	)
+	files := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("png"))
+	}))
+	defer files.Close()
+
+	text := func(s, link string) []*notiontypes.InlineBlock {
+		return []*notiontypes.InlineBlock{{Text: s, Link: link}}
+	}
+	root := &notiontypes.Block{ID: rootID, Type: notiontypes.BlockPage, Title: "Home", Content: []*notiontypes.Block{
+		{ID: "t", Type: notiontypes.BlockText, InlineContent: append(text("See ", ""), text("setup", "/aa8fc12667704e83ad6c3968dcfc9b82")...)},
+		{ID: "c", Type: notiontypes.BlockCallout, InlineContent: text("Careful", ""), FormatCallout: &notiontypes.FormatCallout{PageIcon: "⚠️"}, Content: []*notiontypes.Block{
+			{ID: "ct", Type: notiontypes.BlockText, InlineContent: text("really", "")},
+		}},
+		{ID: imageID, Type: notiontypes.BlockImage, Source: files.URL + "/logo.png"},
+		{ID: subID, Type: notiontypes.BlockPage, Title: "Guide"},
+	}}
+	sub := &notiontypes.Block{ID: subID, Type: notiontypes.BlockPage, Title: "Guide", Content: []*notiontypes.Block{
+		{ID: headingID, Type: notiontypes.BlockHeader, InlineContent: text("Setup", "")},
+	}}
+
+	dir := t.TempDir()
+	e := NewExporter(nil, dir, WithRenderer(&Obsidian{}), WithAssetPolicy(&Download{Dir: ObsidianAttachments}))
+	e.reset()
+	e.add(&notion.Page{Block: root}, nil)
+	e.add(&notion.Page{Block: sub}, []string{rootID})
+	if err := e.writeAll(); err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadFile(filepath.Join(dir, e.order[0].Path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `---
+title: "Home"
+aliases: ["Home"]
+notion-id: "aa8fc126-6770-4e83-ad6c-3968dcfc9b80"
+notion-url: "https://www.notion.so/aa8fc12667704e83ad6c3968dcfc9b80"
+---
+
+# Home
+
+See [[guide-aa8fc12667704e83ad6c3968dcfc9b81#Setup|setup]]
+
+> [!warning] Careful
+> really
+
+![[attachments/aa8fc12667704e83ad6c3968dcfc9b83.png]]
+
+[[guide-aa8fc12667704e83ad6c3968dcfc9b81|Guide]]
+`
+	if string(b) != want {
+		t.Errorf("got\n%s\nwant\n%s", b, want)
+	}
+	if _, err := ioutil.ReadFile(filepath.Join(dir, ObsidianAttachments, "aa8fc12667704e83ad6c3968dcfc9b83.png")); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,173 @@
+package export
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+// Outline renders pages as Logseq or Roam outlines, in which every block is
+// a bullet nested under its parent block, headings and quotes included.
+// Links to exported pages become [[Title]] page references, and todos
+// TODO or DONE markers. Pages start with title:: and notion-id:: properties,
+// so that page references resolve whatever the file names.
+type Outline struct {
+	// Roam writes todos as {{[[TODO]]}} and {{[[DONE]]}}, and numbered
+	// lists as plain bullets, instead of Logseq's syntax.
+	Roam bool
+}
+
+// Ext returns ".md".
+func (o *Outline) Ext() string {
+	return ".md"
+}
+
+// Render writes page to w as an outline.
+func (o *Outline) Render(w io.Writer, page *Page) error {
+	buf := new(bytes.Buffer)
+	r := &outlineRenderer{
+		Outline: o,
+		buf:     buf,
+		md:      &markdownRenderer{buf: buf, page: page, titleLinks: true},
+	}
+	buf.WriteString("title:: " + outlineTitle(page.Title) + "\n")
+	buf.WriteString("notion-id:: " + page.ID + "\n\n")
+	r.blocks(page.Content, "")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+type outlineRenderer struct {
+	*Outline
+	buf *bytes.Buffer
+	// md renders inline text
+	md *markdownRenderer
+}
+
+func (r *outlineRenderer) blocks(blocks []*notiontypes.Block, indent string) {
+	for _, b := range blocks {
+		r.block(b, indent)
+	}
+}
+
+func (r *outlineRenderer) block(b *notiontypes.Block, indent string) {
+	text := r.md.inline(b.InlineContent)
+	switch b.Type {
+	case notiontypes.BlockHeader:
+		r.bullet(indent, "# "+text)
+	case notiontypes.BlockSubHeader:
+		r.bullet(indent, "## "+text)
+	case notiontypes.BlockSubSubHeader:
+		r.bullet(indent, "### "+text)
+	case notiontypes.BlockTodo:
+		r.bullet(indent, r.todo(b.IsChecked)+" "+text)
+	case notiontypes.BlockNumberedList:
+		r.bullet(indent, text)
+		if !r.Roam {
+			r.continuation(indent, "logseq.order-list-type:: number")
+		}
+	case notiontypes.BlockQuote:
+		r.bullet(indent, "> "+strings.Replace(text, "\n", "\n> ", -1))
+	case notiontypes.BlockCallout:
+		icon := ""
+		if b.FormatCallout != nil && b.FormatCallout.PageIcon != "" {
+			icon = b.FormatCallout.PageIcon + " "
+		}
+		r.bullet(indent, "> "+icon+strings.Replace(text, "\n", "\n> ", -1))
+	case notiontypes.BlockCode:
+		r.bullet(indent, "```"+notiontypes.LinguistName(b.CodeLanguage)+"\n"+b.Code+"\n```")
+	case notiontypes.BlockDivider:
+		r.bullet(indent, "---")
+	case notiontypes.BlockImage:
+		r.bullet(indent, "![]("+r.md.page.AssetURL(b)+")")
+	case notiontypes.BlockBookmark:
+		title := b.Title
+		if title == "" {
+			title = b.Link
+		}
+		r.bullet(indent, "["+escapeMarkdown(title)+"]("+b.Link+")")
+	case notiontypes.BlockPage:
+		if link := r.md.titleLink(b.Title, notionURL(b.ID)); link != "" {
+			r.bullet(indent, link)
+		} else {
+			r.bullet(indent, r.md.link(escapeMarkdown(b.Title), r.md.page.PageURL(b.ID)))
+		}
+	case notiontypes.BlockTable:
+		buf := new(bytes.Buffer)
+		r.md.sub(buf, nil).renderBlock(b)
+		r.bullet(indent, strings.TrimRight(buf.String(), "\n"))
+	case notiontypes.BlockColumnList, notiontypes.BlockColumn, notiontypes.BlockSyncedBlock,
+		notiontypes.BlockSyncedBlockCopy, notiontypes.BlockTableOfContents, notiontypes.BlockBreadcrumb:
+		// outlines have no layout: content stays at the same level
+		r.blocks(b.Content, indent)
+		return
+	case notiontypes.BlockFile, notiontypes.BlockAudio, notiontypes.BlockPDF, notiontypes.BlockEmbed, notiontypes.BlockDrive,
+		notiontypes.BlockFigma, notiontypes.BlockTweet, notiontypes.BlockMaps, notiontypes.BlockGist, notiontypes.BlockVideo:
+		r.bullet(indent, "["+escapeMarkdown(embedTitle(b))+"]("+r.md.page.AssetURL(b)+")")
+	default:
+		if text == "" && len(b.Content) == 0 {
+			return
+		}
+		r.bullet(indent, text)
+	}
+	r.blocks(b.Content, indent+"\t")
+}
+
+func (r *outlineRenderer) todo(done bool) string {
+	switch {
+	case r.Roam && done:
+		return "{{[[DONE]]}}"
+	case r.Roam:
+		return "{{[[TODO]]}}"
+	case done:
+		return "DONE"
+	}
+	return "TODO"
+}
+
+// bullet writes a block, whose further lines are indented as its
+// continuation.
+func (r *outlineRenderer) bullet(indent, s string) {
+	for i, l := range strings.Split(s, "\n") {
+		if i == 0 {
+			r.buf.WriteString(indent + "- " + l + "\n")
+			continue
+		}
+		r.continuation(indent, l)
+	}
+}
+
+func (r *outlineRenderer) continuation(indent, s string) {
+	r.buf.WriteString(indent + "  " + s + "\n")
+}
+
+// titleLink returns the [[Title]] reference to the exported page link
+// points to, or to the page of the block it points to, as [text]([[Title]])
+// if text differs from the title. It returns the empty string if link
+// points elsewhere.
+func (r *markdownRenderer) titleLink(text, link string) string {
+	id, _, ok := parseNotionURL(link)
+	if !ok {
+		return ""
+	}
+	e := r.page.exporter
+	target, ok := e.pages[id]
+	if !ok {
+		if target, ok = e.blockPages[id]; !ok {
+			return ""
+		}
+	}
+	ref := "[[" + outlineTitle(target.Title) + "]]"
+	if text = strings.TrimSpace(text); text == "" || text == target.Title {
+		return ref
+	}
+	return "[" + escapeMarkdown(text) + "](" + ref + ")"
+}
+
+// outlineTitle returns title without the characters page references can't
+// contain.
+func outlineTitle(title string) string {
+	return strings.TrimSpace(strings.NewReplacer("[", "", "]", "", "\n", " ").Replace(title))
+}
@@ -0,0 +1,62 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/notiontypes"
+)
+
+func TestOutline(t *testing.T) {
+	const (
+		rootID = "aa8fc126-6770-4e83-ad6c-3968dcfc9b80"
+		subID  = "aa8fc126-6770-4e83-ad6c-3968dcfc9b81"
+	)
+	text := func(s, link string) []*notiontypes.InlineBlock {
+		return []*notiontypes.InlineBlock{{Text: s, Link: link}}
+	}
+	root := &notiontypes.Block{ID: rootID, Type: notiontypes.BlockPage, Title: "Home", Content: []*notiontypes.Block{
+		{Type: notiontypes.BlockHeader, InlineContent: text("Plans", "")},
+		{Type: notiontypes.BlockText, InlineContent: append(text("read ", ""), text("the guide", "/aa8fc12667704e83ad6c3968dcfc9b81")...), Content: []*notiontypes.Block{
+			{Type: notiontypes.BlockTodo, InlineContent: text("ship", ""), IsChecked: true},
+			{Type: notiontypes.BlockTodo, InlineContent: text("test", "")},
+		}},
+		{Type: notiontypes.BlockCode, Code: "x := 1\ny := 2", CodeLanguage: "Go"},
+		{Type: notiontypes.BlockText},
+		{ID: subID, Type: notiontypes.BlockPage, Title: "Guide"},
+	}}
+	sub := &notiontypes.Block{ID: subID, Type: notiontypes.BlockPage, Title: "Guide"}
+
+	for _, tt := range []struct {
+		outline *Outline
+		want    string
+	}{
+		{&Outline{}, "title:: Home\nnotion-id:: " + rootID + "\n\n" +
+			"- # Plans\n" +
+			"- read [the guide]([[Guide]])\n" +
+			"\t- DONE ship\n" +
+			"\t- TODO test\n" +
+			"- ```go\n  x := 1\n  y := 2\n  ```\n" +
+			"- [[Guide]]\n"},
+		{&Outline{Roam: true}, "title:: Home\nnotion-id:: " + rootID + "\n\n" +
+			"- # Plans\n" +
+			"- read [the guide]([[Guide]])\n" +
+			"\t- {{[[DONE]]}} ship\n" +
+			"\t- {{[[TODO]]}} test\n" +
+			"- ```go\n  x := 1\n  y := 2\n  ```\n" +
+			"- [[Guide]]\n"},
+	} {
+		e := NewExporter(nil, t.TempDir(), WithRenderer(tt.outline))
+		e.reset()
+		e.add(&notion.Page{Block: root}, nil)
+		e.add(&notion.Page{Block: sub}, []string{rootID})
+		buf := new(bytes.Buffer)
+		if err := tt.outline.Render(buf, e.order[0]); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("Roam %v: got\n%s\nwant\n%s", tt.outline.Roam, buf, tt.want)
+		}
+	}
+}
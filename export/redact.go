@@ -0,0 +1,283 @@
+package export
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// Mask replaces redacted text.
+const Mask = "[REDACTED]"
+
+// Redaction actions.
+const (
+	// RedactMask replaces the redacted text, property value or page
+	// content with Mask.
+	RedactMask = "mask"
+	// RedactRemove leaves the redacted block, property or page, with its
+	// sub-pages, out of the export.
+	RedactRemove = "remove"
+)
+
+// RedactionRule selects content to redact from an export. Exactly one of
+// Pattern, Property and Tag is set.
+type RedactionRule struct {
+	Name string `json:"name" yaml:"name"`
+	// Pattern is a regular expression matching the text of blocks, page
+	// titles and property values. Matches are masked, or the blocks
+	// containing them are removed; titles and properties are only masked.
+	Pattern string `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	// Property is the (case-insensitive) name of properties of database
+	// rows to redact, e.g. "Salary".
+	Property string `json:"property,omitempty" yaml:"property,omitempty"`
+	// Tag redacts the pages having it as the value of a select or
+	// multi-select property, or having a checked checkbox property of
+	// that name, e.g. "Confidential". Masked pages keep their place but
+	// lose their title, properties and content.
+	Tag string `json:"tag,omitempty" yaml:"tag,omitempty"`
+	// Action is RedactMask (the default) or RedactRemove.
+	Action string `json:"action,omitempty" yaml:"action,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// RedactionConfig is the set of redaction rules of an export, typically
+// read from a YAML file:
+//
+//	redactions:
+//	  - name: salaries
+//	    property: Salary
+//	  - name: confidential
+//	    tag: Confidential
+//	    action: remove
+//	  - name: api-keys
+//	    pattern: 'sk_live_[0-9a-zA-Z]+'
+type RedactionConfig struct {
+	Rules []*RedactionRule `json:"redactions" yaml:"redactions"`
+}
+
+// Compile validates the rules of c and returns the Redactor applying them.
+func (c *RedactionConfig) Compile() (*Redactor, error) {
+	r := &Redactor{}
+	for _, rule := range c.Rules {
+		rule := *rule
+		set := 0
+		for _, s := range []string{rule.Pattern, rule.Property, rule.Tag} {
+			if s != "" {
+				set++
+			}
+		}
+		if set != 1 {
+			return nil, errors.Errorf("export: redaction %q needs exactly one of pattern, property and tag", rule.Name)
+		}
+		switch rule.Action {
+		case "":
+			rule.Action = RedactMask
+		case RedactMask, RedactRemove:
+		default:
+			return nil, errors.Errorf("export: redaction %q has unknown action %q", rule.Name, rule.Action)
+		}
+		if rule.Pattern != "" {
+			var err error
+			if rule.pattern, err = regexp.Compile(rule.Pattern); err != nil {
+				return nil, errors.Wrapf(err, "redaction %q", rule.Name)
+			}
+		}
+		r.rules = append(r.rules, &rule)
+	}
+	return r, nil
+}
+
+// Redactor removes or masks content of exported pages according to
+// redaction rules, see WithRedactor.
+type Redactor struct {
+	rules []*RedactionRule
+}
+
+// Redaction is an entry of the audit list of an export with a Redactor. It
+// doesn't include the redacted content.
+type Redaction struct {
+	Rule   string `json:"rule"`
+	Action string `json:"action"`
+	PageID string `json:"page_id"`
+	// BlockID is set for redacted blocks.
+	BlockID string `json:"block_id,omitempty"`
+	// Property is set for redacted properties.
+	Property string `json:"property,omitempty"`
+	// Title is set for redacted page titles.
+	Title bool `json:"title,omitempty"`
+}
+
+// WithRedactor applies the rules of r to the exported pages before they're
+// rendered. The redactions made are listed by Exporter.Redactions.
+func WithRedactor(r *Redactor) Option {
+	return func(e *Exporter) {
+		e.redactor = r
+	}
+}
+
+// Redactions returns the audit list of the redactions made by the last
+// export, in page order.
+func (e *Exporter) Redactions() []*Redaction {
+	return e.redactions
+}
+
+// redactPage applies the rules of r to the page b with the given
+// properties, and returns the properties left and whether to remove the
+// page.
+func (r *Redactor) redactPage(b *notiontypes.Block, props []*notiontypes.PageProperty, audit *[]*Redaction) ([]*notiontypes.PageProperty, bool) {
+	log := func(rule *RedactionRule, red Redaction) {
+		red.Rule, red.Action, red.PageID = rule.Name, rule.Action, b.ID
+		*audit = append(*audit, &red)
+	}
+	props = append([]*notiontypes.PageProperty(nil), props...)
+	for _, rule := range r.rules {
+		if rule.Tag == "" || !tagged(props, rule.Tag) {
+			continue
+		}
+		log(rule, Redaction{})
+		if rule.Action == RedactRemove {
+			return nil, true
+		}
+		b.Title = Mask
+		b.Content = []*notiontypes.Block{{ID: b.ID + "-redacted", Type: notiontypes.BlockText, Alive: true, InlineContent: []*notiontypes.InlineBlock{{Text: Mask}}}}
+		b.ContentIDs = []string{b.Content[0].ID}
+		return nil, false
+	}
+
+	for _, rule := range r.rules {
+		if rule.Property != "" {
+			kept := props[:0:0]
+			for _, p := range props {
+				if !strings.EqualFold(p.Name, rule.Property) {
+					kept = append(kept, p)
+					continue
+				}
+				log(rule, Redaction{Property: p.Name})
+				if rule.Action == RedactMask {
+					kept = append(kept, &notiontypes.PageProperty{ID: p.ID, Name: p.Name, Type: p.Type, Value: []*notiontypes.InlineBlock{{Text: Mask}}})
+				}
+			}
+			props = kept
+		}
+		if rule.pattern == nil {
+			continue
+		}
+		if masked := rule.pattern.ReplaceAllString(b.Title, Mask); masked != b.Title {
+			b.Title = masked
+			log(rule, Redaction{Title: true})
+		}
+		for i, p := range props {
+			if rule.pattern.MatchString(p.Text()) {
+				props[i] = &notiontypes.PageProperty{ID: p.ID, Name: p.Name, Type: p.Type, Value: maskRuns(rule.pattern, p.Value)}
+				log(rule, Redaction{Property: p.Name})
+			}
+		}
+		b.Content, b.ContentIDs = redactBlocks(rule, b.Content, func(id string) { log(rule, Redaction{BlockID: id}) })
+	}
+	return props, false
+}
+
+// tagged reports whether props tag a page with tag.
+func tagged(props []*notiontypes.PageProperty, tag string) bool {
+	for _, p := range props {
+		switch p.Type {
+		case notiontypes.ColumnTypeSelect, notiontypes.ColumnMultiSelect:
+			for _, v := range strings.Split(p.Text(), ",") {
+				if strings.EqualFold(strings.TrimSpace(v), tag) {
+					return true
+				}
+			}
+		case notiontypes.ColumnTypeCheckbox:
+			if strings.EqualFold(p.Name, tag) && p.Checked() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// redactBlocks applies the pattern rule to blocks, not descending into
+// sub-pages, and returns the blocks kept and their ids.
+func redactBlocks(rule *RedactionRule, blocks []*notiontypes.Block, log func(id string)) ([]*notiontypes.Block, []string) {
+	if blocks == nil {
+		return nil, nil
+	}
+	kept := make([]*notiontypes.Block, 0, len(blocks))
+	ids := make([]string, 0, len(blocks))
+	for _, b := range blocks {
+		if b.IsPage() {
+			if masked := rule.pattern.ReplaceAllString(b.Title, Mask); masked != b.Title {
+				b.Title = masked
+				log(b.ID)
+			}
+		} else if blockMatches(rule.pattern, b) {
+			log(b.ID)
+			if rule.Action == RedactRemove {
+				continue
+			}
+			maskBlock(rule.pattern, b)
+		}
+		if !b.IsPage() {
+			b.Content, b.ContentIDs = redactBlocks(rule, b.Content, log)
+		}
+		kept = append(kept, b)
+		ids = append(ids, b.ID)
+	}
+	return kept, ids
+}
+
+// blockText returns the texts of b, without its content.
+func blockText(b *notiontypes.Block) []string {
+	texts := []string{plainText(b.InlineContent), b.Code, b.Link, b.Description}
+	for _, row := range b.Rows {
+		for _, cell := range row {
+			texts = append(texts, plainText(cell))
+		}
+	}
+	return texts
+}
+
+func blockMatches(re *regexp.Regexp, b *notiontypes.Block) bool {
+	for _, s := range blockText(b) {
+		if s != "" && re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+func maskBlock(re *regexp.Regexp, b *notiontypes.Block) {
+	b.InlineContent = maskRuns(re, b.InlineContent)
+	b.Code = re.ReplaceAllString(b.Code, Mask)
+	b.Link = re.ReplaceAllString(b.Link, Mask)
+	b.Description = re.ReplaceAllString(b.Description, Mask)
+	for _, row := range b.Rows {
+		for i, cell := range row {
+			row[i] = maskRuns(re, cell)
+		}
+	}
+}
+
+// maskRuns returns runs with the matches of re masked, within runs or, for
+// matches spanning runs, over all of them.
+func maskRuns(re *regexp.Regexp, runs []*notiontypes.InlineBlock) []*notiontypes.InlineBlock {
+	if !re.MatchString(plainText(runs)) {
+		return runs
+	}
+	res := make([]*notiontypes.InlineBlock, len(runs))
+	for i, r := range runs {
+		masked := *r
+		masked.Text = re.ReplaceAllString(r.Text, Mask)
+		if masked.Text != r.Text {
+			masked.Link, masked.UserID, masked.PageID, masked.Date = "", "", "", nil
+		}
+		res[i] = &masked
+	}
+	if re.MatchString(plainText(res)) {
+		return []*notiontypes.InlineBlock{{Text: re.ReplaceAllString(plainText(res), Mask)}}
+	}
+	return res
+}
@@ -0,0 +1,123 @@
+package export
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/notiontypes"
+)
+
+func TestRedactor(t *testing.T) {
+	config := &RedactionConfig{Rules: []*RedactionRule{
+		{Name: "salaries", Property: "salary"},
+		{Name: "confidential", Tag: "Confidential", Action: RedactRemove},
+		{Name: "keys", Pattern: `sk_live_\w+`},
+		{Name: "passwords", Pattern: `(?i)password`, Action: RedactRemove},
+	}}
+	r, err := config.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := func(id, s string) *notiontypes.Block {
+		return &notiontypes.Block{ID: id, Type: notiontypes.BlockText, InlineContent: []*notiontypes.InlineBlock{{Text: s}}}
+	}
+	root := &notiontypes.Block{ID: "root", Type: notiontypes.BlockPage, Title: "Team", Content: []*notiontypes.Block{
+		text("key", "use sk_live_abc123 to deploy"),
+		text("password", "The Password is hunter2"),
+		{ID: "alice", Type: notiontypes.BlockPage, Title: "Alice"},
+		{ID: "secret", Type: notiontypes.BlockPage, Title: "Secret plans"},
+	}}
+	alice := &notiontypes.Block{ID: "alice", Type: notiontypes.BlockPage, Title: "Alice"}
+	secret := &notiontypes.Block{ID: "secret", Type: notiontypes.BlockPage, Title: "Secret plans", Content: []*notiontypes.Block{
+		{ID: "sub", Type: notiontypes.BlockPage, Title: "Sub-plans"},
+	}}
+	sub := &notiontypes.Block{ID: "sub", Type: notiontypes.BlockPage, Title: "Sub-plans"}
+
+	dir := t.TempDir()
+	e := NewExporter(nil, dir, WithRedactor(r))
+	e.reset()
+	e.add(&notion.Page{Block: root}, nil)
+	e.add(&notion.Page{Block: alice, PageProperties: []*notiontypes.PageProperty{
+		{Name: "Salary", Type: notiontypes.ColumnTypeNumber, Value: []*notiontypes.InlineBlock{{Text: "100000"}}},
+		{Name: "Tags", Type: notiontypes.ColumnMultiSelect, Value: []*notiontypes.InlineBlock{{Text: "Engineering"}}},
+	}}, []string{"root"})
+	e.add(&notion.Page{Block: secret, PageProperties: []*notiontypes.PageProperty{
+		{Name: "Tags", Type: notiontypes.ColumnMultiSelect, Value: []*notiontypes.InlineBlock{{Text: "Engineering,confidential"}}},
+	}}, []string{"root"})
+	e.add(&notion.Page{Block: sub}, []string{"root", "secret"})
+	if err := e.writeAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(e.order) != 2 {
+		t.Fatalf("exported %d pages, want 2", len(e.order))
+	}
+	if got := e.order[1].PageProperties[0].Text(); got != Mask {
+		t.Errorf("salary = %q, want %q", got, Mask)
+	}
+	b, err := ioutil.ReadFile(filepath.Join(dir, e.order[0].Path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, leak := range []string{"sk_live", "hunter2", "Secret"} {
+		if strings.Contains(string(b), leak) {
+			t.Errorf("export contains %q:\n%s", leak, b)
+		}
+	}
+	if !strings.Contains(string(b), "REDACTED") {
+		t.Errorf("export lacks the masked key:\n%s", b)
+	}
+
+	var got [][2]string
+	for _, red := range e.Redactions() {
+		got = append(got, [2]string{red.Rule, red.BlockID + red.Property})
+	}
+	want := [][2]string{{"keys", "key"}, {"passwords", "password"}, {"salaries", "Salary"}, {"confidential", ""}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("redactions = %v, want %v", got, want)
+	}
+}
+
+func TestRedactColumns(t *testing.T) {
+	r, err := (&RedactionConfig{Rules: []*RedactionRule{
+		{Name: "passwords", Pattern: `(?i)password`, Action: RedactRemove},
+	}}).Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, renderer := range []Renderer{&HTML{}, &Markdown{}} {
+		root := &notiontypes.Block{ID: "root", Type: notiontypes.BlockPage, Title: "Team", Content: []*notiontypes.Block{
+			{ID: "columns", Type: notiontypes.BlockColumnList, Content: []*notiontypes.Block{
+				{ID: "left", Type: notiontypes.BlockColumn, Content: []*notiontypes.Block{
+					{ID: "password", Type: notiontypes.BlockText, InlineContent: []*notiontypes.InlineBlock{{Text: "password is hunter2"}}},
+				}},
+				{ID: "right", Type: notiontypes.BlockColumn, Content: []*notiontypes.Block{
+					{ID: "note", Type: notiontypes.BlockText, InlineContent: []*notiontypes.InlineBlock{{Text: "ask ops"}}},
+				}},
+			}},
+		}}
+		root.Content[0].Columns = []*notiontypes.Column{{Ratio: 0.5}, {Ratio: 0.5}}
+
+		dir := t.TempDir()
+		e := NewExporter(nil, dir, WithRedactor(r), WithRenderer(renderer))
+		e.reset()
+		e.add(&notion.Page{Block: root}, nil)
+		if err := e.writeAll(); err != nil {
+			t.Fatal(err)
+		}
+		b, err := ioutil.ReadFile(filepath.Join(dir, e.order[0].Path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(b), "hunter2") || !strings.Contains(string(b), "ask ops") {
+			t.Errorf("%T: got\n%s", renderer, b)
+		}
+		if len(e.Redactions()) != 1 || e.Redactions()[0].BlockID != "password" {
+			t.Errorf("%T: got redactions %v", renderer, e.Redactions())
+		}
+	}
+}
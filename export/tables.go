@@ -0,0 +1,236 @@
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tmc/notion/notiontypes"
+	"github.com/tmc/notion/xlsx"
+)
+
+// CSV writes collections as CSV files with a header row, like notion's
+// own export.
+type CSV struct {
+	w *csv.Writer
+}
+
+// Ext implements TableWriter.
+func (*CSV) Ext() string { return ".csv" }
+
+// Begin implements TableWriter.
+func (t *CSV) Begin(w io.Writer, name string, columns []*Column) error {
+	t.w = csv.NewWriter(w)
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = col.Name
+	}
+	return t.w.Write(header)
+}
+
+// WriteRow implements TableWriter.
+func (t *CSV) WriteRow(row *notiontypes.Block, values []*notiontypes.PageProperty) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		record[i] = cellText(v)
+	}
+	return t.w.Write(record)
+}
+
+// End implements TableWriter.
+func (t *CSV) End() error {
+	t.w.Flush()
+	return t.w.Error()
+}
+
+// JSONL writes collections as JSON Lines: an object per row with its id
+// and its values by property name. Numbers, checkboxes and multi-select
+// properties are JSON numbers, booleans and arrays, values that aren't
+// set are null.
+type JSONL struct {
+	w       *bufio.Writer
+	columns []*Column
+}
+
+// Ext implements TableWriter.
+func (*JSONL) Ext() string { return ".jsonl" }
+
+// Begin implements TableWriter.
+func (t *JSONL) Begin(w io.Writer, name string, columns []*Column) error {
+	t.w, t.columns = bufio.NewWriter(w), columns
+	return nil
+}
+
+// WriteRow implements TableWriter.
+func (t *JSONL) WriteRow(row *notiontypes.Block, values []*notiontypes.PageProperty) error {
+	obj := map[string]interface{}{"id": row.ID}
+	for i, v := range values {
+		obj[t.columns[i].Name] = jsonValue(v)
+	}
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	t.w.Write(b)
+	return t.w.WriteByte('\n')
+}
+
+// End implements TableWriter.
+func (t *JSONL) End() error {
+	return t.w.Flush()
+}
+
+func jsonValue(p *notiontypes.PageProperty) interface{} {
+	text := cellText(p)
+	if text == "" {
+		if p != nil && p.Type == notiontypes.ColumnTypeCheckbox {
+			return false
+		}
+		return nil
+	}
+	switch p.Type {
+	case notiontypes.ColumnTypeNumber:
+		if n, err := strconv.ParseFloat(text, 64); err == nil {
+			return n
+		}
+	case notiontypes.ColumnTypeCheckbox:
+		return p.Checked()
+	case notiontypes.ColumnMultiSelect:
+		return p.Values()
+	}
+	return text
+}
+
+// SQLite writes collections as SQL scripts creating and filling a table,
+// to be loaded with e.g. sqlite3 db.sqlite < export.sql. Rows have an id
+// column as primary key; numbers are REAL, checkboxes INTEGER (0 or 1) and
+// other values TEXT.
+type SQLite struct {
+	// Table is the name of the table, the slug of the collection's name
+	// by default.
+	Table string
+
+	w       *bufio.Writer
+	table   string
+	columns []*Column
+}
+
+// Ext implements TableWriter.
+func (*SQLite) Ext() string { return ".sql" }
+
+// Begin implements TableWriter.
+func (t *SQLite) Begin(w io.Writer, name string, columns []*Column) error {
+	t.w, t.columns, t.table = bufio.NewWriter(w), columns, t.Table
+	if t.table == "" {
+		t.table = strings.Replace(Slug(name), "-", "_", -1)
+	}
+	if t.table == "" {
+		t.table = "collection"
+	}
+	defs := []string{`"id" TEXT PRIMARY KEY`}
+	for _, col := range columns {
+		typ := "TEXT"
+		switch col.Type {
+		case notiontypes.ColumnTypeNumber:
+			typ = "REAL"
+		case notiontypes.ColumnTypeCheckbox:
+			typ = "INTEGER"
+		}
+		defs = append(defs, sqlName(col.Name)+" "+typ)
+	}
+	t.w.WriteString("BEGIN TRANSACTION;\n")
+	t.w.WriteString("CREATE TABLE " + sqlName(t.table) + " (" + strings.Join(defs, ", ") + ");\n")
+	return nil
+}
+
+// WriteRow implements TableWriter.
+func (t *SQLite) WriteRow(row *notiontypes.Block, values []*notiontypes.PageProperty) error {
+	sqlValues := []string{sqlString(row.ID)}
+	for _, v := range values {
+		switch x := jsonValue(v).(type) {
+		case nil:
+			sqlValues = append(sqlValues, "NULL")
+		case float64:
+			sqlValues = append(sqlValues, strconv.FormatFloat(x, 'g', -1, 64))
+		case bool:
+			if x {
+				sqlValues = append(sqlValues, "1")
+			} else {
+				sqlValues = append(sqlValues, "0")
+			}
+		default:
+			sqlValues = append(sqlValues, sqlString(cellText(v)))
+		}
+	}
+	_, err := t.w.WriteString("INSERT INTO " + sqlName(t.table) + " VALUES (" + strings.Join(sqlValues, ", ") + ");\n")
+	return err
+}
+
+// End implements TableWriter.
+func (t *SQLite) End() error {
+	t.w.WriteString("COMMIT;\n")
+	return t.w.Flush()
+}
+
+func sqlName(s string) string {
+	return `"` + strings.Replace(s, `"`, `""`, -1) + `"`
+}
+
+func sqlString(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
+
+// XLSX writes collections as Excel workbooks of one sheet, with a bold
+// header row. Numbers, checkboxes and dates are number, boolean and date
+// cells, other values text.
+type XLSX struct {
+	w       *xlsx.Writer
+	columns []*Column
+}
+
+// Ext implements TableWriter.
+func (*XLSX) Ext() string { return ".xlsx" }
+
+// Begin implements TableWriter.
+func (t *XLSX) Begin(w io.Writer, name string, columns []*Column) error {
+	t.w, t.columns = xlsx.NewWriter(w), columns
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = col.Name
+	}
+	return t.w.AddSheet(name, header)
+}
+
+// WriteRow implements TableWriter.
+func (t *XLSX) WriteRow(row *notiontypes.Block, values []*notiontypes.PageProperty) error {
+	cells := make([]interface{}, len(values))
+	for i, v := range values {
+		switch x := jsonValue(v).(type) {
+		case []string:
+			cells[i] = strings.Join(x, ", ")
+		case string:
+			cells[i] = x
+			if v.Type != notiontypes.ColumnTypeDate {
+				break
+			}
+			for _, layout := range []string{"2006-01-02 15:04", "2006-01-02"} {
+				if tm, err := time.Parse(layout, x); err == nil {
+					cells[i] = tm
+					break
+				}
+			}
+		default:
+			cells[i] = x
+		}
+	}
+	return t.w.WriteRow(cells)
+}
+
+// End implements TableWriter.
+func (t *XLSX) End() error {
+	return t.w.Close()
+}
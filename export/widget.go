@@ -0,0 +1,202 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+	"path"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+// WidgetVersion is the version of the schema of Widget output. It changes
+// only with incompatible changes; fields may be added within a version.
+const WidgetVersion = 1
+
+// Widget renders pages as compact JSON for front-end widgets. Unlike the
+// records of the API, the output only has typed, documented fields, see
+// WidgetPage and WidgetSchema.
+type Widget struct {
+	// Indent, if set, indents the JSON with it.
+	Indent string
+}
+
+// WidgetPage is the output of Widget.
+type WidgetPage struct {
+	// Version is WidgetVersion.
+	Version    int               `json:"version"`
+	ID         string            `json:"id"`
+	Title      string            `json:"title"`
+	Icon       string            `json:"icon,omitempty"`
+	Path       string            `json:"path"`
+	Properties []*WidgetProperty `json:"properties,omitempty"`
+	Blocks     []*WidgetBlock    `json:"blocks"`
+}
+
+// WidgetProperty is a property of a page that is a database row.
+type WidgetProperty struct {
+	Name string        `json:"name"`
+	Type string        `json:"type"`
+	Text []*WidgetText `json:"text"`
+}
+
+// WidgetBlock is a block. Type is the notion block type, e.g. "to_do";
+// the other fields are set as applicable to it.
+type WidgetBlock struct {
+	ID   string        `json:"id"`
+	Type string        `json:"type"`
+	Text []*WidgetText `json:"text,omitempty"`
+	// Level is the level of headings, 1 to 3.
+	Level   int  `json:"level,omitempty"`
+	Checked bool `json:"checked,omitempty"`
+	// Code and Language are set for code blocks.
+	Code     string `json:"code,omitempty"`
+	Language string `json:"language,omitempty"`
+	// URL is the link of bookmarks, sub-pages and embeds, and the source of
+	// images and files.
+	URL   string `json:"url,omitempty"`
+	Title string `json:"title,omitempty"`
+	// Rows are the cells of tables, whose first row is a header if Header
+	// is set.
+	Rows     [][][]*WidgetText `json:"rows,omitempty"`
+	Header   bool              `json:"header,omitempty"`
+	Columns  []*WidgetColumn   `json:"columns,omitempty"`
+	Children []*WidgetBlock    `json:"children,omitempty"`
+}
+
+// WidgetColumn is a column of a column list.
+type WidgetColumn struct {
+	// Ratio is the share of the width of the list taken by the column.
+	Ratio  float64        `json:"ratio"`
+	Blocks []*WidgetBlock `json:"blocks"`
+}
+
+// WidgetText is a run of text with the same formatting.
+type WidgetText struct {
+	Text          string `json:"text"`
+	Bold          bool   `json:"bold,omitempty"`
+	Italic        bool   `json:"italic,omitempty"`
+	Strikethrough bool   `json:"strikethrough,omitempty"`
+	Code          bool   `json:"code,omitempty"`
+	Link          string `json:"link,omitempty"`
+	// User is the id of a mentioned user.
+	User string `json:"user,omitempty"`
+	// Date is the start date (YYYY-MM-DD) of a date mention.
+	Date string `json:"date,omitempty"`
+}
+
+// Ext returns ".json".
+func (wd *Widget) Ext() string {
+	return ".json"
+}
+
+// Render writes page to w as a WidgetPage.
+func (wd *Widget) Render(w io.Writer, page *Page) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", wd.Indent)
+	return enc.Encode(WidgetPageOf(page))
+}
+
+// WidgetPageOf returns the widget representation of page.
+func WidgetPageOf(page *Page) *WidgetPage {
+	r := &widgetRenderer{page: page}
+	wp := &WidgetPage{
+		Version: WidgetVersion,
+		ID:      page.ID,
+		Title:   page.Title,
+		Path:    page.Path,
+		Blocks:  r.blocks(page.Content),
+	}
+	if page.FormatPage != nil {
+		wp.Icon = page.FormatPage.PageIcon
+	}
+	for _, p := range page.PageProperties {
+		wp.Properties = append(wp.Properties, &WidgetProperty{Name: p.Name, Type: p.Type, Text: r.text(p.Value)})
+	}
+	return wp
+}
+
+type widgetRenderer struct {
+	page *Page
+}
+
+func (r *widgetRenderer) blocks(blocks []*notiontypes.Block) []*WidgetBlock {
+	res := make([]*WidgetBlock, 0, len(blocks))
+	for _, b := range blocks {
+		if wb := r.block(b); wb != nil {
+			res = append(res, wb)
+		}
+	}
+	return res
+}
+
+func (r *widgetRenderer) block(b *notiontypes.Block) *WidgetBlock {
+	wb := &WidgetBlock{ID: b.ID, Type: b.Type, Text: r.text(b.InlineContent)}
+	switch b.Type {
+	case notiontypes.BlockHeader, notiontypes.BlockSubHeader, notiontypes.BlockSubSubHeader:
+		wb.Level = headingLevel(b.Type)
+	case notiontypes.BlockTodo:
+		wb.Checked = b.IsChecked
+	case notiontypes.BlockCode:
+		wb.Text, wb.Code, wb.Language = nil, b.Code, b.CodeLanguage
+	case notiontypes.BlockImage, notiontypes.BlockAudio, notiontypes.BlockPDF:
+		wb.URL = r.page.AssetURL(b)
+	case notiontypes.BlockFile:
+		wb.URL, wb.Title = r.page.AssetURL(b), path.Base(b.Source)
+	case notiontypes.BlockBookmark:
+		wb.URL, wb.Title = b.Link, b.Title
+	case notiontypes.BlockPage:
+		wb.Text, wb.URL, wb.Title = nil, r.page.PageURL(b.ID), b.Title
+		// sub-pages are exported on their own
+		return wb
+	case notiontypes.BlockEmbed, notiontypes.BlockDrive, notiontypes.BlockFigma, notiontypes.BlockTweet,
+		notiontypes.BlockMaps, notiontypes.BlockVideo, notiontypes.BlockGist:
+		wb.URL, wb.Title = b.Source, embedTitle(b)
+	case notiontypes.BlockColumnList:
+		for i, c := range b.Content {
+			wb.Columns = append(wb.Columns, &WidgetColumn{Ratio: b.ColumnRatio(i), Blocks: r.blocks(c.Content)})
+		}
+		return wb
+	case notiontypes.BlockTable:
+		for _, row := range b.Rows {
+			cells := make([][]*WidgetText, len(row))
+			for i, cell := range row {
+				cells[i] = r.text(cell)
+			}
+			wb.Rows = append(wb.Rows, cells)
+		}
+		wb.Header = b.FormatTable != nil && b.FormatTable.ColumnHeader
+		return wb
+	case notiontypes.BlockSyncedBlockCopy:
+		if link := r.page.SyncedBlockLink(b); link != "" {
+			wb.URL = link
+			return wb
+		}
+	}
+	if len(b.Content) > 0 {
+		wb.Children = r.blocks(b.Content)
+	}
+	return wb
+}
+
+func (r *widgetRenderer) text(inline []*notiontypes.InlineBlock) []*WidgetText {
+	var res []*WidgetText
+	for _, i := range inline {
+		t := &WidgetText{
+			Text:          i.Text,
+			Bold:          i.AttrFlags&notiontypes.AttrBold != 0,
+			Italic:        i.AttrFlags&notiontypes.AttrItalic != 0,
+			Strikethrough: i.AttrFlags&notiontypes.AttrStrikeThrought != 0,
+			Code:          i.AttrFlags&notiontypes.AttrCode != 0,
+			User:          i.UserID,
+		}
+		if i.Link != "" {
+			t.Link = r.page.RewriteLink(i.Link)
+		}
+		if i.Date != nil {
+			t.Date = i.Date.StartDate
+		}
+		res = append(res, t)
+	}
+	return res
+}
@@ -0,0 +1,43 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+)
+
+func TestWidgetSchema(t *testing.T) {
+	got, err := WidgetSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *update {
+		if err := ioutil.WriteFile("widget.schema.json", got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := ioutil.ReadFile("widget.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("widget.schema.json is out of date, run go test -update")
+	}
+
+	var schema struct {
+		Defs map[string]struct {
+			Required []string `json:"required"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(got, &schema); err != nil {
+		t.Fatal(err)
+	}
+	if req := schema.Defs["WidgetBlock"].Required; len(req) != 2 || req[0] != "id" || req[1] != "type" {
+		t.Errorf("got required block fields %q, want id and type", req)
+	}
+	if _, ok := schema.Defs["WidgetText"]; !ok {
+		t.Errorf("schema lacks WidgetText")
+	}
+}
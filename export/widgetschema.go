@@ -0,0 +1,17 @@
+package export
+
+import (
+	"strconv"
+
+	"github.com/tmc/notion/jsonschema"
+)
+
+// WidgetSchema returns the JSON Schema (draft 2020-12) of the output of
+// Widget, generated from the WidgetPage type. It is published as
+// widget.schema.json next to this package.
+func WidgetSchema() ([]byte, error) {
+	g := jsonschema.New()
+	root := g.Add(WidgetPage{})
+	g.SetProperty(root, "version", map[string]interface{}{"type": "integer", "const": WidgetVersion})
+	return g.JSON("Notion widget page, version "+strconv.Itoa(WidgetVersion), root)
+}
@@ -0,0 +1,44 @@
+package notion
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+type signedFileURLRequest struct {
+	URL              string `json:"url"`
+	PermissionRecord Record `json:"permissionRecord"`
+}
+
+type getSignedFileURLsRequest struct {
+	URLs []signedFileURLRequest `json:"urls"`
+}
+
+type getSignedFileURLsResponse struct {
+	SignedURLs []string `json:"signedUrls"`
+}
+
+// GetSignedFileURLs returns temporary, publicly accessible URLs for files
+// uploaded to the block blockID. The result has the same order as urls.
+func (c *Client) GetSignedFileURLs(blockID string, urls ...string) ([]string, error) {
+	req := getSignedFileURLsRequest{}
+	for _, u := range urls {
+		req.URLs = append(req.URLs, signedFileURLRequest{
+			URL:              u,
+			PermissionRecord: Record{Table: "block", ID: blockID},
+		})
+	}
+	b, err := c.post(req, "getSignedFileUrls")
+	if err != nil {
+		return nil, err
+	}
+	r := &getSignedFileURLsResponse{}
+	if err := json.Unmarshal(b, r); err != nil {
+		return nil, errors.Wrap(err, "unmarshaling getSignedFileUrlsResponse")
+	}
+	if len(r.SignedURLs) != len(urls) {
+		return nil, errors.Errorf("notion: got %d signed urls for %d files", len(r.SignedURLs), len(urls))
+	}
+	return r.SignedURLs, nil
+}
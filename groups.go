@@ -0,0 +1,157 @@
+package notion
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion/notiontypes"
+)
+
+type getSpaceValuesResponse struct {
+	Results []*notiontypes.SpaceWithRole `json:"results"`
+}
+
+// GetSpace returns the workspace with the given id, including its members'
+// permissions and groups.
+func (c *Client) GetSpace(spaceID string) (*notiontypes.Space, error) {
+	b, err := c.post(getRecordValuesRequest{
+		Requests: []Record{{ID: spaceID, Table: notiontypes.TableSpace}},
+	}, "getRecordValues")
+	if err != nil {
+		return nil, err
+	}
+	r := &getSpaceValuesResponse{}
+	if err := json.Unmarshal(b, r); err != nil {
+		return nil, errors.Wrap(err, "unmarshaling getRecordValuesResponse")
+	}
+	if len(r.Results) == 0 || r.Results[0].Value == nil {
+		return nil, errors.Errorf("notion: space %v not found", spaceID)
+	}
+	return r.Results[0].Value, nil
+}
+
+// ListMembers returns the members and guests of the workspace spaceID.
+func (c *Client) ListMembers(spaceID string) ([]*notiontypes.SpaceMember, error) {
+	sub, err := c.GetSubscriptionData(spaceID)
+	if err != nil {
+		return nil, err
+	}
+	return sub.Members, nil
+}
+
+// ListGroups returns the permission groups of the workspace spaceID.
+func (c *Client) ListGroups(spaceID string) ([]*notiontypes.PermissionGroup, error) {
+	s, err := c.GetSpace(spaceID)
+	if err != nil {
+		return nil, err
+	}
+	return s.PermissionGroups, nil
+}
+
+// CreateGroup adds a permission group with the given name and members to
+// the workspace spaceID.
+func (c *Client) CreateGroup(spaceID, name string, userIDs ...string) (*notiontypes.PermissionGroup, error) {
+	g := &notiontypes.PermissionGroup{ID: NewBlockID(), Name: name, UserIDs: append([]string{}, userIDs...)}
+	err := c.updateGroups(spaceID, func(s *notiontypes.Space) error {
+		if s.Group(name) != nil {
+			return errors.Errorf("notion: space %v already has a group %q", spaceID, name)
+		}
+		s.PermissionGroups = append(s.PermissionGroups, g)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// AddGroupMembers adds the users to the group with the given id or name of
+// the workspace spaceID. Users already in the group are left alone.
+func (c *Client) AddGroupMembers(spaceID, group string, userIDs ...string) error {
+	return c.updateGroups(spaceID, func(s *notiontypes.Space) error {
+		g := s.Group(group)
+		if g == nil {
+			return errors.Errorf("notion: space %v has no group %q", spaceID, group)
+		}
+		for _, id := range userIDs {
+			if !contains(g.UserIDs, id) {
+				g.UserIDs = append(g.UserIDs, id)
+			}
+		}
+		return nil
+	})
+}
+
+// RemoveGroupMembers removes the users from the group with the given id or
+// name of the workspace spaceID, or from all of its groups if group is
+// empty, e.g. when offboarding.
+func (c *Client) RemoveGroupMembers(spaceID, group string, userIDs ...string) error {
+	return c.updateGroups(spaceID, func(s *notiontypes.Space) error {
+		groups := s.PermissionGroups
+		if group != "" {
+			g := s.Group(group)
+			if g == nil {
+				return errors.Errorf("notion: space %v has no group %q", spaceID, group)
+			}
+			groups = []*notiontypes.PermissionGroup{g}
+		}
+		for _, g := range groups {
+			kept := g.UserIDs[:0]
+			for _, id := range g.UserIDs {
+				if !contains(userIDs, id) {
+					kept = append(kept, id)
+				}
+			}
+			g.UserIDs = kept
+		}
+		return nil
+	})
+}
+
+// updateGroups applies update to the groups of the workspace spaceID and
+// saves them. Since notion stores the groups in a single list, changes made
+// by others between reading and saving it are lost.
+func (c *Client) updateGroups(spaceID string, update func(s *notiontypes.Space) error) error {
+	s, err := c.GetSpace(spaceID)
+	if err != nil {
+		return err
+	}
+	if err := update(s); err != nil {
+		return err
+	}
+	groups := s.PermissionGroups
+	if groups == nil {
+		groups = []*notiontypes.PermissionGroup{}
+	}
+	return c.submitTransaction(&operation{
+		ID:      s.ID,
+		Table:   notiontypes.TableSpace,
+		Path:    []string{"permission_groups"},
+		Command: "set",
+		Args:    groups,
+	})
+}
+
+// SetPermission adds p to the permissions of the page pageID, replacing
+// the permission of the same user or group. A permission with
+// notiontypes.RoleNone removes it.
+func (c *Client) SetPermission(pageID string, p notiontypes.Permission) error {
+	pageID, err := FormatID(pageID)
+	if err != nil {
+		return err
+	}
+	return c.submitTransaction(&operation{
+		ID:      pageID,
+		Table:   notiontypes.TableBlock,
+		Path:    []string{"permissions"},
+		Command: "setPermissionItem",
+		Args:    p,
+	})
+}
+
+// ShareWithGroup gives the group groupID the role (e.g.
+// notiontypes.RoleEditor) on the page pageID and its sub-pages.
+// notiontypes.RoleNone revokes the group's access.
+func (c *Client) ShareWithGroup(pageID, groupID, role string) error {
+	return c.SetPermission(pageID, notiontypes.Permission{Type: notiontypes.PermissionTypeGroup, Role: role, GroupID: &groupID})
+}
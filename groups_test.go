@@ -0,0 +1,121 @@
+package notion_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tmc/notion/notiontest"
+	"github.com/tmc/notion/notiontypes"
+)
+
+func TestGroups(t *testing.T) {
+	const (
+		spaceID = "6b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+		pageID  = "7b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+		ada     = "4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+		bob     = "5b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+	)
+	s := notiontest.NewServer()
+	defer s.Close()
+	s.AddRecord(notiontypes.TableSpace, spaceID, map[string]interface{}{
+		"id":   spaceID,
+		"name": "Work",
+		"permission_groups": []map[string]interface{}{
+			{"id": "g1", "name": "Engineering", "user_ids": []string{ada}},
+		},
+	})
+	s.AddBlock(&notiontypes.Block{ID: pageID, Type: notiontypes.BlockPage})
+	c := s.Client()
+
+	sales, err := c.CreateGroup(spaceID, "Sales", bob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.CreateGroup(spaceID, "Sales"); err == nil {
+		t.Error("expected error creating a group twice")
+	}
+	if err := c.AddGroupMembers(spaceID, "Engineering", bob, ada); err != nil {
+		t.Fatal(err)
+	}
+	groups, err := c.ListGroups(spaceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 2 || !reflect.DeepEqual(groups[0].UserIDs, []string{ada, bob}) || groups[1].ID != sales.ID {
+		t.Errorf("unexpected groups after adding %+v %+v", groups[0], groups[1])
+	}
+	if err := c.RemoveGroupMembers(spaceID, "", bob); err != nil {
+		t.Fatal(err)
+	}
+	if groups, _ = c.ListGroups(spaceID); len(groups[0].UserIDs) != 1 || len(groups[1].UserIDs) != 0 {
+		t.Errorf("bob not removed from all groups: %+v %+v", groups[0], groups[1])
+	}
+	if err := c.AddGroupMembers(spaceID, "Marketing", bob); err == nil {
+		t.Error("expected error for unknown group")
+	}
+
+	if err := c.ShareWithGroup(pageID, "g1", notiontypes.RoleReader); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ShareWithGroup(pageID, "g1", notiontypes.RoleEditor); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ShareWithGroup(pageID, sales.ID, notiontypes.RoleReader); err != nil {
+		t.Fatal(err)
+	}
+	perms := s.Block(pageID).Permissions
+	if perms == nil || len(*perms) != 2 || (*perms)[0].Role != notiontypes.RoleEditor || *(*perms)[1].GroupID != sales.ID {
+		t.Fatalf("unexpected permissions %v", perms)
+	}
+	if err := c.ShareWithGroup(pageID, sales.ID, notiontypes.RoleNone); err != nil {
+		t.Fatal(err)
+	}
+	if perms := s.Block(pageID).Permissions; len(*perms) != 1 {
+		t.Errorf("group access not revoked: %v", perms)
+	}
+}
+
+func TestGuests(t *testing.T) {
+	const (
+		spaceID = "6b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+		pageID  = "7b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+		member  = "4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+		guest   = "5b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+	)
+	s := notiontest.NewServer()
+	defer s.Close()
+	s.AddRecord("notion_user", guest, map[string]interface{}{"id": guest, "email": "contractor@example.com"})
+	s.AddRecord("subscription", spaceID, map[string]interface{}{
+		"members": []map[string]interface{}{
+			{"userId": member, "role": "editor"},
+			{"userId": guest, "role": "editor", "guestPageIds": []string{pageID}},
+		},
+	})
+	s.AddRecord(notiontypes.TableBlock, pageID, map[string]interface{}{
+		"id": pageID, "type": "page", "properties": map[string]interface{}{"title": [][]string{{"Roadmap"}}},
+		"permissions": []map[string]interface{}{
+			{"type": "user_permission", "role": "editor", "user_id": member},
+			{"type": "user_permission", "role": "comment_only", "user_id": guest},
+		},
+	})
+	c := s.Client()
+
+	guests, err := c.ListGuests(spaceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(guests) != 1 || guests[0].User.Email != "contractor@example.com" || len(guests[0].Pages) != 1 {
+		t.Fatalf("unexpected guests %+v", guests)
+	}
+	if p := guests[0].Pages[0]; p.Title != "Roadmap" || p.Role != notiontypes.RoleCommenter {
+		t.Errorf("unexpected guest page %+v", p)
+	}
+
+	if err := c.RemoveGuest(guest, pageID); err != nil {
+		t.Fatal(err)
+	}
+	perms := *s.Block(pageID).Permissions
+	if len(perms) != 1 || *perms[0].UserID != member {
+		t.Errorf("guest access not removed: %v", perms)
+	}
+}
@@ -0,0 +1,93 @@
+package notion
+
+import (
+	"sort"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+// Guest is a user outside of a workspace that pages of it are shared with.
+type Guest struct {
+	UserID string
+	// User is nil if the user can't be read.
+	User  *notiontypes.User
+	Pages []*GuestPage
+}
+
+// GuestPage is a page shared with a guest.
+type GuestPage struct {
+	ID    string
+	Title string
+	// Role is the guest's role on the page, e.g. notiontypes.RoleEditor, or
+	// empty if the page can't be read.
+	Role string
+}
+
+// ListGuests returns the guests of the workspace spaceID with the pages
+// shared with them, by user id.
+func (c *Client) ListGuests(spaceID string) ([]*Guest, error) {
+	members, err := c.ListMembers(spaceID)
+	if err != nil {
+		return nil, err
+	}
+	var guests []*Guest
+	var userIDs, pageIDs []string
+	for _, m := range members {
+		if !m.IsGuest() {
+			continue
+		}
+		g := &Guest{UserID: m.UserID}
+		for _, id := range m.GuestPageIDs {
+			g.Pages = append(g.Pages, &GuestPage{ID: id})
+			pageIDs = append(pageIDs, id)
+		}
+		guests = append(guests, g)
+		userIDs = append(userIDs, m.UserID)
+	}
+	if len(guests) == 0 {
+		return nil, nil
+	}
+	users, err := c.GetUsers(userIDs...)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*notiontypes.User, len(users))
+	for _, u := range users {
+		byID[u.ID] = u
+	}
+	blocks, err := c.GetBlocks(pageIDs...)
+	if err != nil {
+		return nil, err
+	}
+	pages := make(map[string]*notiontypes.Block, len(blocks))
+	for _, b := range blocks {
+		if b != nil {
+			pages[b.ID] = b
+		}
+	}
+	for _, g := range guests {
+		g.User = byID[g.UserID]
+		for _, p := range g.Pages {
+			b, ok := pages[p.ID]
+			if !ok {
+				continue
+			}
+			p.Title = b.Title
+			if b.Permissions == nil {
+				continue
+			}
+			for _, perm := range *b.Permissions {
+				if perm.UserID != nil && *perm.UserID == g.UserID {
+					p.Role = perm.Role
+				}
+			}
+		}
+	}
+	sort.Slice(guests, func(i, j int) bool { return guests[i].UserID < guests[j].UserID })
+	return guests, nil
+}
+
+// RemoveGuest revokes the access of the user userID to the page pageID.
+func (c *Client) RemoveGuest(userID, pageID string) error {
+	return c.SetPermission(pageID, notiontypes.Permission{Type: notiontypes.PermissionTypeUser, Role: notiontypes.RoleNone, UserID: &userID})
+}
@@ -0,0 +1,105 @@
+package notion
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// ErrNoVersion is returned by ReconstructPageAt for times before the
+// oldest version of a page notion kept.
+var ErrNoVersion = errors.New("notion: no version of the page at that time")
+
+// snapshotsListSize is the number of snapshots requested, notion keeps
+// fewer than that.
+const snapshotsListSize = 1000
+
+type getSnapshotsListRequest struct {
+	BlockID string `json:"blockId"`
+	Size    int    `json:"size"`
+}
+
+type getSnapshotsListResponse struct {
+	Snapshots []*notiontypes.Snapshot `json:"snapshots"`
+}
+
+// GetBlockHistory returns the saved versions of the page blockID, most
+// recent first. How long versions are kept depends on the workspace's plan.
+func (c *Client) GetBlockHistory(blockID string) ([]*notiontypes.Snapshot, error) {
+	b, err := c.post(getSnapshotsListRequest{BlockID: blockID, Size: snapshotsListSize}, "getSnapshotsList")
+	if err != nil {
+		return nil, err
+	}
+	r := &getSnapshotsListResponse{}
+	if err := json.Unmarshal(b, r); err != nil {
+		return nil, errors.Wrap(err, "unmarshaling getSnapshotsListResponse")
+	}
+	return r.Snapshots, nil
+}
+
+type getSnapshotContentsRequest struct {
+	BlockID    string `json:"blockId"`
+	SnapshotID string `json:"snapshotId"`
+}
+
+type getSnapshotContentsResponse struct {
+	RecordMap notiontypes.RecordMap `json:"recordMap"`
+}
+
+// GetBlockVersion returns the page blockID, with its content, as saved by
+// the snapshot snapshotID of its history.
+func (c *Client) GetBlockVersion(blockID, snapshotID string) (*notiontypes.Block, error) {
+	b, err := c.post(getSnapshotContentsRequest{BlockID: blockID, SnapshotID: snapshotID}, "getSnapshotContents")
+	if err != nil {
+		return nil, err
+	}
+	r := &getSnapshotContentsResponse{}
+	if err := json.Unmarshal(b, r); err != nil {
+		return nil, errors.Wrap(err, "unmarshaling getSnapshotContentsResponse")
+	}
+	return c.parseBlockFromRecordMaps(blockID, []notiontypes.RecordMap{r.RecordMap}, notiontypes.ResolveOptions{})
+}
+
+// ReconstructPageAt returns the page pageID, with its content, as it was at
+// t: the current page if none of its blocks was edited after t, and the
+// most recent version of its history saved at or before t otherwise.
+//
+// Versions are saved by notion every few minutes while a page is edited,
+// so edits made shortly before t may be missing. ErrNoVersion is returned
+// if t predates the oldest version kept.
+func (c *Client) ReconstructPageAt(pageID string, t time.Time) (*notiontypes.Block, error) {
+	p, err := c.GetPage(pageID)
+	if err != nil {
+		return nil, err
+	}
+	if !lastEdited(p.Block).After(t) {
+		return p.Block, nil
+	}
+	snapshots, err := c.GetBlockHistory(pageID)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range snapshots {
+		if !s.SavedOn().After(t) {
+			return c.GetBlockVersion(pageID, s.ID)
+		}
+	}
+	return nil, ErrNoVersion
+}
+
+// lastEdited returns the time of the last edit of b or its content, not
+// counting sub-pages.
+func lastEdited(b *notiontypes.Block) time.Time {
+	last := time.Unix(b.LastEditedTime/1000, b.LastEditedTime%1000*int64(time.Millisecond))
+	for _, child := range b.Content {
+		if child.IsPage() {
+			continue
+		}
+		if t := lastEdited(child); t.After(last) {
+			last = t
+		}
+	}
+	return last
+}
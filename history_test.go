@@ -0,0 +1,62 @@
+package notion_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/notiontest"
+	"github.com/tmc/notion/notiontypes"
+)
+
+func TestReconstructPageAt(t *testing.T) {
+	const (
+		pageID = "4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+		textID = "5b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+	)
+	day := func(d int) time.Time {
+		return time.Date(2020, 1, d, 0, 0, 0, 0, time.UTC)
+	}
+	page := func(text string, edited time.Time) *notiontypes.Block {
+		ms := edited.UnixNano() / int64(time.Millisecond)
+		return &notiontypes.Block{
+			ID: pageID, Type: notiontypes.BlockPage, LastEditedTime: day(1).UnixNano() / int64(time.Millisecond),
+			Properties: map[string]interface{}{"title": [][]string{{"Plan"}}},
+			Content: []*notiontypes.Block{{
+				ID: textID, Type: notiontypes.BlockText, LastEditedTime: ms,
+				Properties: map[string]interface{}{"title": [][]string{{text}}},
+			}},
+		}
+	}
+	s := notiontest.NewServer()
+	defer s.Close()
+	s.AddBlock(page("current", day(20)))
+	for i, d := range []int{5, 10} {
+		v := int64(i + 1)
+		snap := &notiontypes.Snapshot{ID: fmt.Sprint("s", v), Version: v, Timestamp: day(d).UnixNano() / int64(time.Millisecond)}
+		s.AddSnapshot(snap, page(fmt.Sprint("version ", v), day(d)))
+	}
+	c := s.Client()
+
+	for _, tt := range []struct {
+		at   time.Time
+		want string
+	}{
+		{day(25), "current"},
+		{day(12), "version 2"},
+		{day(10), "version 2"},
+		{day(7), "version 1"},
+	} {
+		b, err := c.ReconstructPageAt(pageID, tt.at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := b.Content[0].InlineContent[0].Text; got != tt.want {
+			t.Errorf("page at %v says %q, want %q", tt.at, got, tt.want)
+		}
+	}
+	if _, err := c.ReconstructPageAt(pageID, day(2)); err != notion.ErrNoVersion {
+		t.Errorf("got error %v before the oldest version, want ErrNoVersion", err)
+	}
+}
@@ -0,0 +1,116 @@
+package notion
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// DefaultCoverPosition is the vertical position notion gives new covers.
+const DefaultCoverPosition = 0.5
+
+// SetPageIcon sets the icon of the page pageID to icon, an emoji such as
+// "📁" or the URL of an image, e.g. as returned by UploadFile. An empty icon
+// removes the page's icon.
+func (c *Client) SetPageIcon(pageID, icon string) error {
+	return c.updatePageFormat(pageID, map[string]interface{}{"page_icon": icon})
+}
+
+// SetPageCover sets the cover of the page pageID to the image at cover,
+// which is either a URL or one of notion's gallery images such as
+// "/images/page-cover/gradients_11.jpg". position is the part of the image
+// shown, from 0 (its top) to 1 (its bottom), see DefaultCoverPosition. An
+// empty cover removes the page's cover.
+func (c *Client) SetPageCover(pageID, cover string, position float64) error {
+	if position < 0 || position > 1 {
+		return errors.Errorf("cover position %v is not between 0 and 1", position)
+	}
+	return c.updatePageFormat(pageID, map[string]interface{}{
+		"page_cover":          cover,
+		"page_cover_position": position,
+	})
+}
+
+// UploadPageIcon uploads the image read from r, named name, and makes it
+// the icon of the page pageID.
+func (c *Client) UploadPageIcon(pageID, name string, r io.Reader) error {
+	url, err := c.UploadFile(name, mime.TypeByExtension(filepath.Ext(name)), r)
+	if err != nil {
+		return err
+	}
+	return c.SetPageIcon(pageID, url)
+}
+
+func (c *Client) updatePageFormat(pageID string, format map[string]interface{}) error {
+	pageID, err := FormatID(pageID)
+	if err != nil {
+		return err
+	}
+	return c.submitTransaction(&operation{
+		ID:      pageID,
+		Table:   notiontypes.TableBlock,
+		Path:    []string{"format"},
+		Command: "update",
+		Args:    format,
+	})
+}
+
+type getUploadFileURLRequest struct {
+	Bucket      string `json:"bucket"`
+	Name        string `json:"name"`
+	ContentType string `json:"contentType"`
+}
+
+type getUploadFileURLResponse struct {
+	URL          string `json:"url"`
+	SignedGetURL string `json:"signedGetUrl"`
+	SignedPutURL string `json:"signedPutUrl"`
+}
+
+// UploadFile stores the content of r in notion's file storage, as a file
+// named name of the given MIME type, and returns its URL. The URL can be
+// used as the source of blocks and as a page icon or cover; it's only
+// readable by members of the workspace.
+func (c *Client) UploadFile(name, contentType string, r io.Reader) (string, error) {
+	if c.public {
+		return "", ErrPublicClient
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	b, err := c.post(getUploadFileURLRequest{Bucket: "secure", Name: name, ContentType: contentType}, "getUploadFileUrl")
+	if err != nil {
+		return "", err
+	}
+	resp := &getUploadFileURLResponse{}
+	if err := json.Unmarshal(b, resp); err != nil {
+		return "", errors.Wrap(err, "unmarshaling getUploadFileUrlResponse")
+	}
+	// the signed URL must be sent the length of the file
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequest("PUT", resp.SignedPutURL, bytes.NewReader(data))
+	if err != nil {
+		return "", errors.Wrap(err, "creating upload request")
+	}
+	req = req.WithContext(c.context())
+	req.Header.Set("Content-Type", contentType)
+	put, err := c.client.Do(req)
+	if err != nil {
+		return "", errors.Wrapf(err, "uploading %v", name)
+	}
+	defer put.Body.Close()
+	if put.StatusCode/100 != 2 {
+		return "", errors.Errorf("uploading %v: %v", name, put.Status)
+	}
+	return resp.URL, nil
+}
@@ -0,0 +1,39 @@
+package notion_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tmc/notion/notiontest"
+	"github.com/tmc/notion/notiontypes"
+)
+
+func TestPageIconAndCover(t *testing.T) {
+	s := notiontest.NewServer()
+	defer s.Close()
+	pageID := "4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+	s.AddBlock(&notiontypes.Block{ID: pageID, Type: notiontypes.BlockPage, FormatRaw: []byte(`{"page_full_width":true}`)})
+	c := s.Client()
+
+	if err := c.SetPageIcon(pageID, "📁"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SetPageCover(pageID, "/images/page-cover/gradients_11.jpg", 0.2); err != nil {
+		t.Fatal(err)
+	}
+	f := s.Block(pageID).FormatPage
+	if f == nil || f.PageIcon != "📁" || f.PageCover != "/images/page-cover/gradients_11.jpg" || f.PageCoverPosition != 0.2 || !f.PageFullWidth {
+		t.Fatalf("got format %+v", f)
+	}
+	if err := c.SetPageCover(pageID, "cover.png", 2); err == nil {
+		t.Error("cover position out of range accepted")
+	}
+
+	if err := c.UploadPageIcon(pageID, "icon.png", strings.NewReader("png")); err != nil {
+		t.Fatal(err)
+	}
+	icon := s.Block(pageID).FormatPage.PageIcon
+	if !strings.HasSuffix(icon, "/icon.png") || string(s.File(icon)) != "png" {
+		t.Errorf("got icon %q with content %q", icon, s.File(icon))
+	}
+}
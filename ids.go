@@ -0,0 +1,101 @@
+package notion
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// NewBlockID returns a random (version 4) UUID in the dashed form notion uses for record ids.
+func NewBlockID() string {
+	var u [16]byte
+	if _, err := rand.Read(u[:]); err != nil {
+		panic(err)
+	}
+	return formatUUID(u, 4)
+}
+
+// DeterministicID returns the name based (version 5) UUID of name within the
+// id namespace. Blocks created with deterministic ids, e.g. of a page id and
+// a source url, can be recognized when the same content is imported again.
+func DeterministicID(namespace, name string) (string, error) {
+	ns, err := FormatID(namespace)
+	if err != nil {
+		return "", errors.Wrap(err, "invalid namespace")
+	}
+	b, _ := hex.DecodeString(strings.Replace(ns, "-", "", -1))
+	h := sha1.New()
+	h.Write(b)
+	h.Write([]byte(name))
+	var u [16]byte
+	copy(u[:], h.Sum(nil))
+	return formatUUID(u, 5), nil
+}
+
+// ValidID reports whether id is a notion id, with or without dashes.
+func ValidID(id string) bool {
+	_, err := FormatID(id)
+	return err == nil
+}
+
+// FormatID returns the dashed, lower case form of the notion id, which may be
+// given with or without dashes, as in page urls.
+func FormatID(id string) (string, error) {
+	s := strings.ToLower(id)
+	if len(s) == 36 {
+		if s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+			return "", fmt.Errorf("notion: invalid id %q", id)
+		}
+		s = strings.Replace(s, "-", "", -1)
+	}
+	if len(s) != 32 {
+		return "", fmt.Errorf("notion: invalid id %q", id)
+	}
+	for _, c := range s {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f') {
+			return "", fmt.Errorf("notion: invalid id %q", id)
+		}
+	}
+	return s[:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:], nil
+}
+
+// ParsePageURL returns the id of the page a notion URL, such as
+// https://www.notion.so/workspace/Title-<id> or
+// https://example.notion.site/Title-<id>, points to. Plain ids are
+// accepted as well.
+func ParsePageURL(s string) (string, error) {
+	if id, err := FormatID(s); err == nil {
+		return id, nil
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("notion: invalid page url %q", s)
+	}
+	host := strings.TrimPrefix(u.Host, "www.")
+	if host != "notion.so" && !strings.HasSuffix(host, ".notion.site") {
+		return "", fmt.Errorf("notion: %q is not a notion url", s)
+	}
+	// pages opened as a peek (?p=<id>) aren't the page of the path
+	for _, candidate := range []string{u.Query().Get("p"), u.Path[strings.LastIndex(u.Path, "/")+1:]} {
+		candidate = strings.Replace(candidate, "-", "", -1)
+		if len(candidate) < 32 {
+			continue
+		}
+		if id, err := FormatID(candidate[len(candidate)-32:]); err == nil {
+			return id, nil
+		}
+	}
+	return "", fmt.Errorf("notion: no page id in url %q", s)
+}
+
+// formatUUID sets the version and variant bits of u and returns it in dashed form.
+func formatUUID(u [16]byte, version byte) string {
+	u[6] = (u[6] & 0x0f) | version<<4
+	u[8] = (u[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:])
+}
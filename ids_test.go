@@ -0,0 +1,59 @@
+package notion
+
+import "testing"
+
+func TestDeterministicID(t *testing.T) {
+	// the example of RFC 4122 errata 1352, using the DNS namespace
+	id, err := DeterministicID("6ba7b810-9dad-11d1-80b4-00c04fd430c8", "www.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "2ed6657d-e927-568b-95e1-2665a8aea6a2"; id != want {
+		t.Errorf("got %v, want %v", id, want)
+	}
+	if _, err := DeterministicID("not an id", "x"); err == nil {
+		t.Error("expected error for invalid namespace")
+	}
+}
+
+func TestFormatID(t *testing.T) {
+	tests := []struct {
+		id, want string
+	}{
+		{"aa8fc12667704e83ad6c3968dcfc9b82", "aa8fc126-6770-4e83-ad6c-3968dcfc9b82"},
+		{"AA8FC126-6770-4E83-AD6C-3968DCFC9B82", "aa8fc126-6770-4e83-ad6c-3968dcfc9b82"},
+		{"aa8fc126-6770-4e83-ad6c-3968dcfc9b8", ""},
+		{"aa8fc1266-770-4e83-ad6c-3968dcfc9b82", ""},
+		{"xa8fc12667704e83ad6c3968dcfc9b82", ""},
+	}
+	for _, tt := range tests {
+		got, err := FormatID(tt.id)
+		if got != tt.want || (err != nil) != (tt.want == "") {
+			t.Errorf("FormatID(%q) = %q, %v, want %q", tt.id, got, err, tt.want)
+		}
+	}
+	if id := NewBlockID(); !ValidID(id) || id[14] != '4' {
+		t.Errorf("NewBlockID returned %v", id)
+	}
+}
+
+func TestParsePageURL(t *testing.T) {
+	const id = "aa8fc126-6770-4e83-ad6c-3968dcfc9b82"
+	tests := []struct {
+		url, want string
+	}{
+		{"aa8fc12667704e83ad6c3968dcfc9b82", id},
+		{"https://www.notion.so/acme/Roadmap-aa8fc12667704e83ad6c3968dcfc9b82", id},
+		{"https://acme.notion.site/Roadmap-aa8fc12667704e83ad6c3968dcfc9b82?pvs=4", id},
+		{"https://www.notion.so/acme/bb8fc12667704e83ad6c3968dcfc9b82?v=cc8fc12667704e83ad6c3968dcfc9b82&p=aa8fc12667704e83ad6c3968dcfc9b82", id},
+		{"https://example.com/Roadmap-aa8fc12667704e83ad6c3968dcfc9b82", ""},
+		{"https://www.notion.so/acme/Roadmap", ""},
+		{"Roadmap", ""},
+	}
+	for _, tt := range tests {
+		got, err := ParsePageURL(tt.url)
+		if got != tt.want || (err != nil) != (tt.want == "") {
+			t.Errorf("ParsePageURL(%q) = %q, %v, want %q", tt.url, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package importer
+
+import (
+	"encoding/json"
+	"html"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// LinkMetadata describes the page at a URL, as shown by bookmarks.
+type LinkMetadata struct {
+	Title       string
+	Description string
+	// IconURL is the page's favicon, ImageURL its preview image
+	// (og:image).
+	IconURL  string
+	ImageURL string
+}
+
+// Fetcher fetches the metadata of the page at a URL.
+type Fetcher interface {
+	Fetch(url string) (*LinkMetadata, error)
+}
+
+// maxPageSize bounds the part of pages read by HTTPFetcher.
+const maxPageSize = 1 << 20
+
+// HTTPFetcher is a Fetcher that reads the metadata of HTML pages over HTTP.
+// It prefers Open Graph tags, and falls back on the page's title, meta
+// description and first paragraph, and on /favicon.ico.
+type HTTPFetcher struct {
+	// Client defaults to http.DefaultClient.
+	Client *http.Client
+	// UserAgent defaults to that of Go's HTTP client.
+	UserAgent string
+}
+
+// Fetch implements Fetcher.
+func (f *HTTPFetcher) Fetch(u string) (*LinkMetadata, error) {
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/html")
+	if f.UserAgent != "" {
+		req.Header.Set("User-Agent", f.UserAgent)
+	}
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("fetching %v: %v", u, resp.Status)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "" && !strings.Contains(ct, "html") {
+		return nil, errors.Errorf("fetching %v: not an HTML page but %v", u, ct)
+	}
+	b, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxPageSize))
+	if err != nil {
+		return nil, err
+	}
+	return ParseLinkMetadata(resp.Request.URL, string(b)), nil
+}
+
+var (
+	htmlTag       = regexp.MustCompile(`(?is)<(meta|link)\s[^>]*>`)
+	htmlAttribute = regexp.MustCompile(`(?is)([a-z:-]+)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+	htmlTitle     = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	htmlParagraph = regexp.MustCompile(`(?is)<p[\s>].*?</p>`)
+	htmlElement   = regexp.MustCompile(`(?s)<[^>]*>`)
+	htmlSpace     = regexp.MustCompile(`\s+`)
+)
+
+// ParseLinkMetadata extracts the metadata of the HTML page src, whose URL
+// is base.
+func ParseLinkMetadata(base *url.URL, src string) *LinkMetadata {
+	m := &LinkMetadata{}
+	meta := make(map[string]string)
+	for _, tag := range htmlTag.FindAllStringSubmatch(src, -1) {
+		attrs := make(map[string]string)
+		for _, a := range htmlAttribute.FindAllStringSubmatch(tag[0], -1) {
+			attrs[strings.ToLower(a[1])] = html.UnescapeString(a[2] + a[3] + a[4])
+		}
+		if strings.EqualFold(tag[1], "link") {
+			rel := strings.Fields(strings.ToLower(attrs["rel"]))
+			if m.IconURL == "" && (contains(rel, "icon") || contains(rel, "apple-touch-icon")) {
+				m.IconURL = resolveURL(base, attrs["href"])
+			}
+			continue
+		}
+		key := strings.ToLower(attrs["property"] + attrs["name"])
+		if _, ok := meta[key]; !ok && key != "" {
+			meta[key] = strings.TrimSpace(attrs["content"])
+		}
+	}
+	m.Title = first(meta["og:title"], meta["twitter:title"])
+	if m.Title == "" {
+		if t := htmlTitle.FindStringSubmatch(src); t != nil {
+			m.Title = text(t[1])
+		}
+	}
+	m.Description = first(meta["og:description"], meta["description"], meta["twitter:description"])
+	if m.Description == "" {
+		// readability fallback: the first paragraph with some text
+		for _, p := range htmlParagraph.FindAllString(src, -1) {
+			if t := text(p); len(t) >= 40 {
+				m.Description = t
+				break
+			}
+		}
+	}
+	if image := first(meta["og:image"], meta["twitter:image"]); image != "" {
+		m.ImageURL = resolveURL(base, image)
+	}
+	if m.IconURL == "" && base != nil {
+		m.IconURL = resolveURL(base, "/favicon.ico")
+	}
+	return m
+}
+
+// text returns the text of an HTML fragment on a single line.
+func text(s string) string {
+	s = html.UnescapeString(htmlElement.ReplaceAllString(s, " "))
+	return strings.TrimSpace(htmlSpace.ReplaceAllString(s, " "))
+}
+
+func resolveURL(base *url.URL, ref string) string {
+	u, err := url.Parse(strings.TrimSpace(ref))
+	if err != nil || base == nil {
+		return ref
+	}
+	return base.ResolveReference(u).String()
+}
+
+func first(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// EnrichBookmark sets the title, description, icon and cover of the
+// bookmark b, e.g. created by Bookmark, from the metadata f fetches for
+// its link, like notion does when links are pasted as bookmarks. b is
+// left unchanged if fetching fails.
+func EnrichBookmark(b *notiontypes.Block, f Fetcher) error {
+	link := b.Link
+	if link == "" {
+		link, _ = firstText(b.Properties["link"])
+	}
+	if link == "" {
+		return errors.New("bookmark has no link")
+	}
+	m, err := f.Fetch(link)
+	if err != nil {
+		return err
+	}
+	if b.Properties == nil {
+		b.Properties = map[string]interface{}{}
+	}
+	b.Link = link
+	if m.Title != "" {
+		b.Title = m.Title
+		b.Properties["title"] = [][]string{{m.Title}}
+	}
+	if m.Description != "" {
+		b.Description = m.Description
+		b.Properties["description"] = [][]string{{m.Description}}
+	}
+	format := &notiontypes.FormatBookmark{BookmarkIcon: m.IconURL, BookmarkCover: m.ImageURL}
+	b.FormatBookmark = format
+	b.FormatRaw, err = json.Marshal(format)
+	return err
+}
+
+// firstText returns the text of a property holding a single plain string,
+// in either of its encodings.
+func firstText(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case [][]string:
+		if len(v) > 0 && len(v[0]) > 0 {
+			return v[0][0], true
+		}
+	case []interface{}:
+		runs, err := notiontypes.ParseInlineBlocks(v)
+		if err == nil && len(runs) > 0 {
+			return runs[0].Text, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,55 @@
+package importer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEnrichBookmark(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		switch r.URL.Path {
+		case "/og":
+			w.Write([]byte(`<html><head><title>Ignored</title>
+<meta property="og:title" content="Tom &amp; Jerry">
+<meta name='description' content='A cat and a mouse.'>
+<meta property="og:image" content="/img/cover.png">
+<link rel="shortcut icon" href="/static/icon.png">
+</head></html>`))
+		case "/plain":
+			w.Write([]byte(`<html><head><title> Plain
+ page </title></head><body><p>Short.</p><p>The first paragraph that is <b>long enough</b> to describe the page.</p></body></html>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	f := &HTTPFetcher{}
+
+	b := Bookmark(srv.URL + "/og")
+	if err := EnrichBookmark(b, f); err != nil {
+		t.Fatal(err)
+	}
+	if b.Title != "Tom & Jerry" || b.Description != "A cat and a mouse." ||
+		b.FormatBookmark.BookmarkIcon != srv.URL+"/static/icon.png" || b.FormatBookmark.BookmarkCover != srv.URL+"/img/cover.png" {
+		t.Errorf("got bookmark %+v, format %+v", b, b.FormatBookmark)
+	}
+	if title, _ := firstText(b.Properties["title"]); title != "Tom & Jerry" || string(b.FormatRaw) == "" {
+		t.Errorf("properties and format not set: %v %s", b.Properties, b.FormatRaw)
+	}
+
+	b = Bookmark(srv.URL + "/plain")
+	if err := EnrichBookmark(b, f); err != nil {
+		t.Fatal(err)
+	}
+	if b.Title != "Plain page" || b.Description != "The first paragraph that is long enough to describe the page." ||
+		b.FormatBookmark.BookmarkIcon != srv.URL+"/favicon.ico" || b.FormatBookmark.BookmarkCover != "" {
+		t.Errorf("got fallback bookmark %+v, format %+v", b, b.FormatBookmark)
+	}
+
+	b = Bookmark(srv.URL + "/missing")
+	if err := EnrichBookmark(b, f); err == nil || b.Title != "" {
+		t.Errorf("missing page: got error %v, title %q", err, b.Title)
+	}
+}
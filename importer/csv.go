@@ -0,0 +1,230 @@
+package importer
+
+import (
+	"encoding/csv"
+	"io"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion/notiontypes"
+	"github.com/tmc/notion/xlsx"
+)
+
+// SheetTable is tabular data to import as database rows, such as a CSV
+// file or a sheet of a workbook: a header naming the columns and rows of
+// cells.
+type SheetTable struct {
+	Name   string
+	Header []string
+	Rows   [][]string
+}
+
+// ReadCSV reads a CSV file whose first record is the header. Rows shorter
+// than the header are padded with empty cells.
+func ReadCSV(r io.Reader) (*SheetTable, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, errors.Wrap(err, "reading csv")
+	}
+	if len(records) == 0 {
+		return nil, errors.New("importer: csv has no header")
+	}
+	t := &SheetTable{Header: records[0]}
+	for _, rec := range records[1:] {
+		for len(rec) < len(t.Header) {
+			rec = append(rec, "")
+		}
+		t.Rows = append(t.Rows, rec[:len(t.Header)])
+	}
+	return t, nil
+}
+
+// InferOptions controls InferSchema.
+type InferOptions struct {
+	// SampleSize is the number of rows examined, all if zero.
+	SampleSize int
+	// MaxOptions is the number of distinct values up to which columns of
+	// repeated strings become select properties, 10 by default.
+	MaxOptions int
+}
+
+// dateLayouts are the date formats recognized in cells, with a time first.
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"01/02/2006",
+	"Jan 2, 2006",
+	"January 2, 2006",
+}
+
+// Schema returns the properties of a database for t, all text but the
+// first column, which is the title.
+func (t *SheetTable) Schema() []*notiontypes.CollectionColumnInfo {
+	schema := make([]*notiontypes.CollectionColumnInfo, len(t.Header))
+	for i, name := range t.Header {
+		schema[i] = &notiontypes.CollectionColumnInfo{Name: name, Type: notiontypes.ColumnTypeText}
+	}
+	if len(schema) > 0 {
+		schema[0].Type = notiontypes.ColumnTypeTitle
+	}
+	return schema
+}
+
+// InferSchema is like Schema, but types the other columns by the values
+// of their non-empty cells: checkboxes (true/false or yes/no), numbers,
+// dates, URLs, emails, selects for few repeated strings, and text
+// otherwise.
+func (t *SheetTable) InferSchema(opts InferOptions) []*notiontypes.CollectionColumnInfo {
+	if opts.MaxOptions <= 0 {
+		opts.MaxOptions = 10
+	}
+	rows := t.Rows
+	if opts.SampleSize > 0 && opts.SampleSize < len(rows) {
+		rows = rows[:opts.SampleSize]
+	}
+	schema := t.Schema()
+	for i := 1; i < len(schema); i++ {
+		var values []string
+		for _, row := range rows {
+			if v := strings.TrimSpace(row[i]); v != "" {
+				values = append(values, v)
+			}
+		}
+		schema[i].Type, schema[i].Options = inferType(values, opts.MaxOptions)
+	}
+	return schema
+}
+
+// inferType returns the property type of the non-empty values of a
+// column, with the options of selects.
+func inferType(values []string, maxOptions int) (string, []*notiontypes.CollectionColumnOption) {
+	if len(values) == 0 {
+		return notiontypes.ColumnTypeText, nil
+	}
+	for _, c := range []struct {
+		typ string
+		ok  func(string) bool
+	}{
+		{notiontypes.ColumnTypeCheckbox, func(v string) bool { _, ok := parseBool(v); return ok }},
+		{notiontypes.ColumnTypeNumber, func(v string) bool { _, err := strconv.ParseFloat(v, 64); return err == nil }},
+		{notiontypes.ColumnTypeDate, func(v string) bool { _, ok := parseCellDate(v); return ok }},
+		{notiontypes.ColumnTypeURL, func(v string) bool {
+			return (strings.HasPrefix(v, "http://") || strings.HasPrefix(v, "https://")) && !strings.ContainsAny(v, " \t")
+		}},
+		{notiontypes.ColumnTypeEmail, func(v string) bool { a, err := mail.ParseAddress(v); return err == nil && a.Address == v }},
+	} {
+		if all(values, c.ok) {
+			return c.typ, nil
+		}
+	}
+	var options []*notiontypes.CollectionColumnOption
+	seen := map[string]bool{}
+	for _, v := range values {
+		if seen[v] {
+			continue
+		}
+		// options hold short labels, not text
+		if strings.Contains(v, ",") || len(v) > 50 {
+			return notiontypes.ColumnTypeText, nil
+		}
+		seen[v] = true
+		options = append(options, &notiontypes.CollectionColumnOption{Value: v})
+	}
+	if len(options) > maxOptions || len(options) == len(values) {
+		return notiontypes.ColumnTypeText, nil
+	}
+	return notiontypes.ColumnTypeSelect, options
+}
+
+func all(values []string, ok func(string) bool) bool {
+	for _, v := range values {
+		if !ok(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func parseBool(s string) (bool, bool) {
+	switch strings.ToLower(s) {
+	case "true", "yes":
+		return true, true
+	case "false", "no":
+		return false, true
+	}
+	return false, false
+}
+
+// parseCellDate parses s in one of dateLayouts and returns it as
+// 2006-01-02, followed by a 15:04 time if s has one.
+func parseCellDate(s string) (string, bool) {
+	for i, layout := range dateLayouts {
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			continue
+		}
+		if i < 3 {
+			return t.Format("2006-01-02 15:04"), true
+		}
+		return t.Format("2006-01-02"), true
+	}
+	return "", false
+}
+
+// RowValues returns the cells of row i by column name, as expected by
+// notion's Client.CreateRow for the properties of schema: checkboxes as
+// Yes or No and dates as 2006-01-02. Cells that don't match the type of
+// their property are kept as is.
+func (t *SheetTable) RowValues(schema []*notiontypes.CollectionColumnInfo, i int) map[string]string {
+	values := make(map[string]string, len(schema))
+	for j, col := range schema {
+		v := strings.TrimSpace(t.Rows[i][j])
+		switch col.Type {
+		case notiontypes.ColumnTypeCheckbox:
+			if b, ok := parseBool(v); ok {
+				v = "No"
+				if b {
+					v = "Yes"
+				}
+			}
+		case notiontypes.ColumnTypeDate:
+			if d, ok := parseCellDate(v); ok {
+				v = d
+			}
+		}
+		values[col.Name] = v
+	}
+	return values
+}
+
+// ReadXLSX reads the sheets of an Excel workbook of the given size as
+// tables named after them, skipping empty sheets. The first row of each
+// sheet is its header.
+func ReadXLSX(r io.ReaderAt, size int64) ([]*SheetTable, error) {
+	sheets, err := xlsx.Read(r, size)
+	if err != nil {
+		return nil, err
+	}
+	var tables []*SheetTable
+	for _, s := range sheets {
+		if len(s.Rows) == 0 {
+			continue
+		}
+		t := &SheetTable{Name: s.Name, Header: s.Rows[0]}
+		for _, row := range s.Rows[1:] {
+			for len(row) < len(t.Header) {
+				row = append(row, "")
+			}
+			t.Rows = append(t.Rows, row[:len(t.Header)])
+		}
+		tables = append(tables, t)
+	}
+	return tables, nil
+}
@@ -0,0 +1,47 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+func TestInferSchema(t *testing.T) {
+	table, err := ReadCSV(strings.NewReader(`Name,Price,Done,Due,Status,Site,Contact,Notes,Empty
+Apples,1.5,yes,2020-03-01,todo,https://example.com,a@example.com,fresh,
+Pears,2,no,03/02/2020,done,https://example.org,b@example.com,ripe,
+Plums,-3,true,2020-03-03 10:30,todo,https://example.net,c@example.com,"sour, small"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table.Rows) != 3 || len(table.Rows[2]) != 9 {
+		t.Fatalf("got rows %q", table.Rows)
+	}
+	schema := table.InferSchema(InferOptions{})
+	want := []string{
+		notiontypes.ColumnTypeTitle, notiontypes.ColumnTypeNumber, notiontypes.ColumnTypeCheckbox,
+		notiontypes.ColumnTypeDate, notiontypes.ColumnTypeSelect, notiontypes.ColumnTypeURL,
+		notiontypes.ColumnTypeEmail, notiontypes.ColumnTypeText, notiontypes.ColumnTypeText,
+	}
+	for i, col := range schema {
+		if col.Type != want[i] {
+			t.Errorf("column %v: got type %v, want %v", col.Name, col.Type, want[i])
+		}
+	}
+	if o := schema[4].Options; len(o) != 2 || o[0].Value != "todo" || o[1].Value != "done" {
+		t.Errorf("got status options %v", o)
+	}
+	if s := table.Schema(); s[1].Type != notiontypes.ColumnTypeText {
+		t.Errorf("got uninferred price type %v", s[1].Type)
+	}
+
+	values := table.RowValues(schema, 1)
+	if values["Done"] != "No" || values["Due"] != "2020-03-02" || values["Name"] != "Pears" {
+		t.Errorf("got row values %v", values)
+	}
+	if d := table.RowValues(schema, 2)["Due"]; d != "2020-03-03 10:30" {
+		t.Errorf("got datetime %q", d)
+	}
+}
@@ -0,0 +1,103 @@
+package importer
+
+import (
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion/notiontypes"
+)
+
+var (
+	tweetID = regexp.MustCompile(`^[0-9]+$`)
+	gistID  = regexp.MustCompile(`^[0-9a-f]+$`)
+	ghUser  = regexp.MustCompile(`^[A-Za-z0-9-]+$`)
+)
+
+// TweetURL returns the canonical URL of the tweet at tweetURL, and false
+// if it isn't the URL of a tweet.
+func TweetURL(tweetURL string) (string, bool) {
+	u, err := url.Parse(tweetURL)
+	if err != nil {
+		return "", false
+	}
+	switch strings.TrimPrefix(strings.ToLower(u.Host), "www.") {
+	case "twitter.com", "mobile.twitter.com", "x.com":
+	default:
+		return "", false
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 3 || parts[1] != "status" || !tweetID.MatchString(parts[2]) {
+		return "", false
+	}
+	return "https://twitter.com/" + parts[0] + "/status/" + parts[2], true
+}
+
+// GistURL returns the canonical URL of the GitHub gist at gistURL, and
+// false if it isn't the URL of a gist.
+func GistURL(gistURL string) (string, bool) {
+	u, err := url.Parse(gistURL)
+	if err != nil || strings.ToLower(u.Host) != "gist.github.com" {
+		return "", false
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	switch {
+	case len(parts) == 1 && gistID.MatchString(parts[0]):
+		return "https://gist.github.com/" + parts[0], true
+	case len(parts) >= 2 && ghUser.MatchString(parts[0]) && gistID.MatchString(parts[1]):
+		return "https://gist.github.com/" + parts[0] + "/" + parts[1], true
+	}
+	return "", false
+}
+
+// Tweet returns a tweet block embedding the tweet at tweetURL.
+func Tweet(tweetURL string) (*notiontypes.Block, error) {
+	src, ok := TweetURL(tweetURL)
+	if !ok {
+		return nil, errors.Errorf("%v is not a tweet", tweetURL)
+	}
+	id := src[strings.LastIndex(src, "/")+1:]
+	return embedBlock(notiontypes.BlockTweet, src, "https://platform.twitter.com/embed/Tweet.html?id="+id)
+}
+
+// Gist returns a gist block embedding the GitHub gist at gistURL.
+func Gist(gistURL string) (*notiontypes.Block, error) {
+	src, ok := GistURL(gistURL)
+	if !ok {
+		return nil, errors.Errorf("%v is not a GitHub gist", gistURL)
+	}
+	return embedBlock(notiontypes.BlockGist, src, src+".pibb")
+}
+
+func embedBlock(typ, src, display string) (*notiontypes.Block, error) {
+	b := newBlock(typ, nil)
+	b.Properties["source"] = [][]string{{src}}
+	b.Source = src
+	b.FormatEmbed = &notiontypes.FormatEmbed{
+		DisplaySource:      display,
+		BlockFullWidth:     true,
+		BlockPreserveScale: true,
+	}
+	var err error
+	if b.FormatRaw, err = json.Marshal(b.FormatEmbed); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// embed returns a video, tweet or gist block for u, or nil if it's the URL
+// of neither.
+func embed(u string) *notiontypes.Block {
+	for _, fn := range []func(string) (*notiontypes.Block, error){
+		func(u string) (*notiontypes.Block, error) { return Video(u, nil) },
+		Tweet,
+		Gist,
+	} {
+		if b, err := fn(u); err == nil {
+			return b
+		}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package importer
+
+import (
+	"testing"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+func TestEmbeds(t *testing.T) {
+	b, err := Tweet("https://x.com/someone/status/1234?s=20")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Type != notiontypes.BlockTweet || b.Source != "https://twitter.com/someone/status/1234" ||
+		b.FormatEmbed.DisplaySource != "https://platform.twitter.com/embed/Tweet.html?id=1234" || len(b.FormatRaw) == 0 {
+		t.Errorf("got tweet %+v, format %+v", b, b.FormatEmbed)
+	}
+	b, err = Gist("https://gist.github.com/someone/0a1b2c#file-main-go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Type != notiontypes.BlockGist || b.Source != "https://gist.github.com/someone/0a1b2c" ||
+		b.FormatEmbed.DisplaySource != "https://gist.github.com/someone/0a1b2c.pibb" {
+		t.Errorf("got gist %+v, format %+v", b, b.FormatEmbed)
+	}
+	for _, u := range []string{"https://twitter.com/someone", "https://github.com/someone/0a1b2c"} {
+		if _, err := Tweet(u); err == nil {
+			t.Errorf("Tweet(%v) succeeded", u)
+		}
+		if _, err := Gist(u); err == nil {
+			t.Errorf("Gist(%v) succeeded", u)
+		}
+	}
+
+	blocks := Markdown([]byte("<https://twitter.com/someone/status/1>\n\nhttps://gist.github.com/someone/0a1b2c\n"))
+	if len(blocks) != 2 || blocks[0].Type != notiontypes.BlockTweet || blocks[1].Type != notiontypes.BlockGist {
+		t.Errorf("got markdown blocks %v", blocks)
+	}
+}
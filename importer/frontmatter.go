@@ -0,0 +1,81 @@
+package importer
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/tmc/notion/emoji"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// MarkdownPage is a page imported from markdown with YAML front-matter.
+type MarkdownPage struct {
+	// Title, Icon and Cover are the front-matter values of the keys
+	// title, icon and cover. Icon shortcodes such as :rocket: are
+	// converted into emoji.
+	Title string
+	Icon  string
+	Cover string
+	// Params holds all scalar front-matter values by key.
+	Params  map[string]string
+	Content []*notiontypes.Block
+}
+
+// MarkdownWithFrontMatter converts markdown source, which may start with
+// YAML front-matter between "---" lines, into a page. Only scalar
+// front-matter values are read; lists and nested values are skipped.
+func MarkdownWithFrontMatter(src []byte) *MarkdownPage {
+	params, body := splitFrontMatter(strings.Replace(string(src), "\r\n", "\n", -1))
+	return &MarkdownPage{
+		Title:   params["title"],
+		Icon:    emoji.Icon(params["icon"]),
+		Cover:   params["cover"],
+		Params:  params,
+		Content: Markdown([]byte(body)),
+	}
+}
+
+// splitFrontMatter returns the scalar values of the front-matter of src
+// and the rest of src.
+func splitFrontMatter(src string) (map[string]string, string) {
+	params := make(map[string]string)
+	if !strings.HasPrefix(src, "---\n") {
+		return params, src
+	}
+	end := strings.Index(src[4:], "\n---")
+	if end < 0 {
+		return params, src
+	}
+	front, body := src[4:4+end], src[4+end+4:]
+	body = strings.TrimPrefix(body, "\n")
+	for _, line := range strings.Split(front, "\n") {
+		if line == "" || line[0] == ' ' || line[0] == '\t' || line[0] == '#' || line[0] == '-' {
+			continue
+		}
+		i := strings.Index(line, ":")
+		if i < 0 {
+			continue
+		}
+		key, value := strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:])
+		if value == "" {
+			continue
+		}
+		params[key] = yamlScalar(value)
+	}
+	return params, body
+}
+
+// yamlScalar returns the value of a YAML scalar, unquoting quoted strings.
+func yamlScalar(s string) string {
+	switch s[0] {
+	case '"':
+		if v, err := strconv.Unquote(s); err == nil {
+			return v
+		}
+	case '\'':
+		if len(s) > 1 && s[len(s)-1] == '\'' {
+			return strings.Replace(s[1:len(s)-1], "''", "'", -1)
+		}
+	}
+	return s
+}
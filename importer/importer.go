@@ -0,0 +1,57 @@
+// Package importer converts external content into notion blocks that can be
+// created with Client.AppendBlocks or Client.CreatePage.
+package importer
+
+import (
+	"strings"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+// Text converts plain text into text blocks, one per paragraph.
+// Paragraphs are separated by blank lines.
+func Text(s string) []*notiontypes.Block {
+	var blocks []*notiontypes.Block
+	for _, p := range splitParagraphs(s) {
+		blocks = append(blocks, newBlock(notiontypes.BlockText, plain(p)))
+	}
+	return blocks
+}
+
+// Bookmark returns a bookmark block pointing at url.
+func Bookmark(url string) *notiontypes.Block {
+	b := newBlock(notiontypes.BlockBookmark, nil)
+	b.Properties["link"] = [][]string{{url}}
+	return b
+}
+
+func newBlock(typ string, text []*notiontypes.InlineBlock) *notiontypes.Block {
+	b := &notiontypes.Block{
+		Type:       typ,
+		Properties: map[string]interface{}{},
+	}
+	if len(text) > 0 {
+		b.Properties["title"] = notiontypes.EncodeInlineBlocks(text)
+		b.InlineContent = text
+	}
+	return b
+}
+
+func plain(s string) []*notiontypes.InlineBlock {
+	if s == "" {
+		return nil
+	}
+	return []*notiontypes.InlineBlock{{Text: s}}
+}
+
+func splitParagraphs(s string) []string {
+	var res []string
+	s = strings.Replace(s, "\r\n", "\n", -1)
+	for _, p := range strings.Split(s, "\n\n") {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			res = append(res, p)
+		}
+	}
+	return res
+}
@@ -0,0 +1,359 @@
+package importer
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+var (
+	mdHeading  = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	mdListItem = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+(.*)$`)
+	mdQuote    = regexp.MustCompile(`^\s{0,3}>\s?(.*)$`)
+	mdImage    = regexp.MustCompile(`^!\[([^\]]*)\]\((\S+?)(?:\s+"[^"]*")?\)$`)
+	mdDivider  = regexp.MustCompile(`^\s{0,3}([-*_])(\s*([-*_]))\s*([-*_]\s*)+$`)
+	mdURL      = regexp.MustCompile(`^<?(https?://[^\s<>]+)>?$`)
+)
+
+// Markdown converts markdown source into notion blocks.
+//
+// Headings, paragraphs, bulleted, numbered and todo lists (nested by
+// indentation), quotes, fenced code blocks, dividers, standalone images and
+// standalone links to YouTube or Vimeo videos, tweets and gists, as embeds,
+// are supported. Inline bold, italic, strikethrough, code and links are kept
+// as text attributes. Anything else is imported as plain text.
+func Markdown(src []byte) []*notiontypes.Block {
+	p := &mdParser{}
+	lines := strings.Split(strings.Replace(string(src), "\r\n", "\n", -1), "\n")
+	for _, line := range lines {
+		p.line(line)
+	}
+	if p.fence == nil {
+		// terminate pending tables
+		p.line("")
+	}
+	p.flush()
+	if p.fence != nil {
+		p.add(p.codeBlock())
+	}
+	return p.blocks
+}
+
+type listItem struct {
+	indent int
+	block  *notiontypes.Block
+}
+
+type mdParser struct {
+	blocks []*notiontypes.Block
+
+	// open list items, innermost last
+	lists []listItem
+
+	// pending paragraph or quote lines
+	para     []string
+	paraType string
+
+	// non-nil while inside a fenced code block
+	fence     []string
+	fenceLang string
+	fenceMark string
+
+	// rows of the current table, and a line that starts a table if it's
+	// followed by a separator line
+	table          [][]string
+	tableCandidate string
+
+	lastBlank bool
+}
+
+func (p *mdParser) line(line string) {
+	if p.fence != nil {
+		if strings.HasPrefix(strings.TrimSpace(line), p.fenceMark) {
+			p.add(p.codeBlock())
+			return
+		}
+		p.fence = append(p.fence, line)
+		return
+	}
+
+	if candidate := p.tableCandidate; candidate != "" {
+		p.tableCandidate = ""
+		if mdTableSeparator.MatchString(line) {
+			p.flush()
+			p.table = [][]string{splitTableRow(candidate)}
+			return
+		}
+		p.paraType = notiontypes.BlockText
+		p.para = append(p.para, strings.TrimSpace(candidate))
+	}
+	if p.table != nil {
+		if mdTableRow.MatchString(line) {
+			p.table = append(p.table, splitTableRow(line))
+			return
+		}
+		p.add(tableBlock(p.table))
+		p.table = nil
+	}
+	if mdTableRow.MatchString(line) && len(p.para) == 0 {
+		p.tableCandidate = line
+		p.lastBlank = false
+		return
+	}
+
+	trimmed := strings.TrimSpace(line)
+	blank := trimmed == ""
+	defer func() { p.lastBlank = blank }()
+
+	switch {
+	case blank:
+		p.flush()
+	case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+		p.flush()
+		p.lists = nil
+		p.fenceMark = trimmed[:3]
+		p.fenceLang = strings.TrimSpace(strings.TrimLeft(trimmed, trimmed[:1]))
+		p.fence = []string{}
+	case mdHeading.MatchString(trimmed):
+		m := mdHeading.FindStringSubmatch(trimmed)
+		p.flush()
+		typ := notiontypes.BlockHeader
+		switch len(m[1]) {
+		case 1:
+		case 2:
+			typ = notiontypes.BlockSubHeader
+		default:
+			typ = notiontypes.BlockSubSubHeader
+		}
+		p.add(newBlock(typ, parseInline(m[2])))
+	case mdDivider.MatchString(line) && len(p.para) == 0:
+		p.add(newBlock(notiontypes.BlockDivider, nil))
+	case mdListItem.MatchString(line):
+		m := mdListItem.FindStringSubmatch(line)
+		p.flush()
+		p.listItem(indentWidth(m[1]), m[2], m[3])
+	case mdQuote.MatchString(line):
+		text := mdQuote.FindStringSubmatch(line)[1]
+		if p.paraType != notiontypes.BlockQuote {
+			p.flush()
+			if indentWidth(line) == 0 {
+				p.lists = nil
+			}
+		}
+		p.paraType = notiontypes.BlockQuote
+		p.para = append(p.para, text)
+	case mdImage.MatchString(trimmed):
+		m := mdImage.FindStringSubmatch(trimmed)
+		p.flush()
+		b := newBlock(notiontypes.BlockImage, nil)
+		b.Properties["source"] = [][]string{{m[2]}}
+		if m[1] != "" {
+			b.Properties["caption"] = [][]string{{m[1]}}
+		}
+		p.add(b)
+	case len(p.para) == 0 && mdURL.MatchString(trimmed) && embed(mdURL.FindStringSubmatch(trimmed)[1]) != nil:
+		p.flush()
+		p.add(embed(mdURL.FindStringSubmatch(trimmed)[1]))
+	case len(p.lists) > 0 && !p.lastBlank && len(p.para) == 0:
+		// lazy continuation of the current list item
+		item := p.lists[len(p.lists)-1].block
+		setText(item, append(item.InlineContent, parseInline(" "+trimmed)...))
+	default:
+		if indentWidth(line) == 0 || p.lastBlank {
+			p.lists = nil
+		}
+		if p.paraType != notiontypes.BlockText {
+			p.flush()
+		}
+		p.paraType = notiontypes.BlockText
+		p.para = append(p.para, trimmed)
+	}
+}
+
+func (p *mdParser) listItem(indent int, marker, text string) {
+	typ := notiontypes.BlockBulletedList
+	if marker[0] >= '0' && marker[0] <= '9' {
+		typ = notiontypes.BlockNumberedList
+	}
+	checked := ""
+	if typ == notiontypes.BlockBulletedList && len(text) >= 3 && text[0] == '[' && text[2] == ']' {
+		switch text[1] {
+		case ' ':
+			checked = "No"
+		case 'x', 'X':
+			checked = "Yes"
+		}
+		if checked != "" {
+			typ = notiontypes.BlockTodo
+			text = strings.TrimSpace(text[3:])
+		}
+	}
+	b := newBlock(typ, parseInline(text))
+	if checked != "" {
+		b.Properties["checked"] = [][]string{{checked}}
+		b.IsChecked = checked == "Yes"
+	}
+
+	for len(p.lists) > 0 && p.lists[len(p.lists)-1].indent >= indent {
+		p.lists = p.lists[:len(p.lists)-1]
+	}
+	if len(p.lists) > 0 {
+		parent := p.lists[len(p.lists)-1].block
+		parent.Content = append(parent.Content, b)
+	} else {
+		p.blocks = append(p.blocks, b)
+	}
+	p.lists = append(p.lists, listItem{indent: indent, block: b})
+}
+
+// add appends a top-level block, closing any open lists.
+func (p *mdParser) add(b *notiontypes.Block) {
+	p.lists = nil
+	p.fence = nil
+	p.blocks = append(p.blocks, b)
+}
+
+// flush emits the pending paragraph or quote, if any.
+func (p *mdParser) flush() {
+	if len(p.para) == 0 {
+		return
+	}
+	sep := " "
+	if p.paraType == notiontypes.BlockQuote {
+		sep = "\n"
+	}
+	b := newBlock(p.paraType, parseInline(strings.Join(p.para, sep)))
+	p.para, p.paraType = nil, ""
+	if len(p.lists) > 0 {
+		// a paragraph indented under a list item belongs to that item
+		parent := p.lists[len(p.lists)-1].block
+		parent.Content = append(parent.Content, b)
+		return
+	}
+	p.blocks = append(p.blocks, b)
+}
+
+func (p *mdParser) codeBlock() *notiontypes.Block {
+	b := newBlock(notiontypes.BlockCode, nil)
+	code := strings.Join(p.fence, "\n")
+	b.Properties["title"] = [][]string{{code}}
+	b.Code = code
+	lang := notiontypes.CodeLanguage(p.fenceLang)
+	b.Properties["language"] = [][]string{{lang}}
+	b.CodeLanguage = lang
+	return b
+}
+
+func setText(b *notiontypes.Block, text []*notiontypes.InlineBlock) {
+	b.InlineContent = text
+	b.Properties["title"] = notiontypes.EncodeInlineBlocks(text)
+}
+
+func indentWidth(s string) int {
+	n := 0
+	for _, r := range s {
+		switch r {
+		case ' ':
+			n++
+		case '\t':
+			n += 4
+		default:
+			return n
+		}
+	}
+	return n
+}
+
+// parseInline splits markdown inline text into runs of identically formatted text.
+func parseInline(s string) []*notiontypes.InlineBlock {
+	var (
+		res   []*notiontypes.InlineBlock
+		flags notiontypes.AttrFlag
+		cur   []byte
+	)
+	flush := func() {
+		if len(cur) > 0 {
+			res = append(res, &notiontypes.InlineBlock{Text: string(cur), AttrFlags: flags})
+			cur = cur[:0]
+		}
+	}
+	// toggle flips flag if a marker of length n at i can open or close it.
+	toggle := func(i, n int, flag notiontypes.AttrFlag) bool {
+		if flags&flag != 0 {
+			if i == 0 || s[i-1] == ' ' {
+				return false
+			}
+		} else if i+n >= len(s) || s[i+n] == ' ' {
+			return false
+		}
+		flush()
+		flags ^= flag
+		return true
+	}
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && strings.IndexByte("\\`*_~[]()#!>-+.", s[i+1]) >= 0:
+			cur = append(cur, s[i+1])
+			i += 2
+			continue
+		case c == '`':
+			if end := strings.IndexByte(s[i+1:], '`'); end >= 0 {
+				flush()
+				res = append(res, &notiontypes.InlineBlock{Text: s[i+1 : i+1+end], AttrFlags: flags | notiontypes.AttrCode})
+				i += end + 2
+				continue
+			}
+		case strings.HasPrefix(s[i:], "**") || strings.HasPrefix(s[i:], "__"):
+			if (c == '*' || isBoundary(s, i, 2)) && toggle(i, 2, notiontypes.AttrBold) {
+				i += 2
+				continue
+			}
+		case strings.HasPrefix(s[i:], "~~"):
+			if toggle(i, 2, notiontypes.AttrStrikeThrought) {
+				i += 2
+				continue
+			}
+		case c == '*' || c == '_':
+			if (c == '*' || isBoundary(s, i, 1)) && toggle(i, 1, notiontypes.AttrItalic) {
+				i++
+				continue
+			}
+		case c == '[':
+			mid := strings.Index(s[i:], "](")
+			if mid < 0 {
+				break
+			}
+			end := strings.IndexByte(s[i+mid:], ')')
+			if end < 0 {
+				break
+			}
+			flush()
+			link := s[i+mid+2 : i+end+mid]
+			for _, r := range parseInline(s[i+1 : i+mid]) {
+				r.AttrFlags |= flags
+				r.Link = link
+				res = append(res, r)
+			}
+			i += mid + end + 1
+			continue
+		}
+		cur = append(cur, c)
+		i++
+	}
+	flush()
+	return res
+}
+
+// isBoundary reports whether the underscore marker of length n at i is not
+// inside a word, so snake_case identifiers are left alone.
+func isBoundary(s string, i, n int) bool {
+	before := i == 0 || !isWordByte(s[i-1])
+	after := i+n >= len(s) || !isWordByte(s[i+n])
+	return before || after
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
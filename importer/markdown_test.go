@@ -0,0 +1,97 @@
+package importer
+
+import (
+	"testing"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+func TestMarkdown(t *testing.T) {
+	src := "# Title\n\nSome **bold** and [a link](https://example.com).\n\n- one\n  - nested\n- [x] done\n\n```go\nfmt.Println()\n```\n---\n> quoted\n"
+	blocks := Markdown([]byte(src))
+	want := []string{
+		notiontypes.BlockHeader,
+		notiontypes.BlockText,
+		notiontypes.BlockBulletedList,
+		notiontypes.BlockTodo,
+		notiontypes.BlockCode,
+		notiontypes.BlockDivider,
+		notiontypes.BlockQuote,
+	}
+	if len(blocks) != len(want) {
+		t.Fatalf("got %d blocks, want %d", len(blocks), len(want))
+	}
+	for i, b := range blocks {
+		if b.Type != want[i] {
+			t.Errorf("block %d: got type %q, want %q", i, b.Type, want[i])
+		}
+	}
+	if n := len(blocks[2].Content); n != 1 {
+		t.Errorf("expected nested list item, got %d children", n)
+	}
+	if !blocks[3].IsChecked {
+		t.Errorf("expected todo to be checked")
+	}
+	if blocks[4].CodeLanguage != "Go" || blocks[4].Code != "fmt.Println()" {
+		t.Errorf("unexpected code block %q %q", blocks[4].CodeLanguage, blocks[4].Code)
+	}
+}
+
+func TestParseInline(t *testing.T) {
+	tests := []struct {
+		in    string
+		texts []string
+		flags []notiontypes.AttrFlag
+	}{
+		{"plain snake_case_name", []string{"plain snake_case_name"}, []notiontypes.AttrFlag{0}},
+		{"a **b** *c*", []string{"a ", "b", " ", "c"}, []notiontypes.AttrFlag{0, notiontypes.AttrBold, 0, notiontypes.AttrItalic}},
+		{"`x*y` ~~z~~", []string{"x*y", " ", "z"}, []notiontypes.AttrFlag{notiontypes.AttrCode, 0, notiontypes.AttrStrikeThrought}},
+		{"2 * 3", []string{"2 * 3"}, []notiontypes.AttrFlag{0}},
+	}
+	for _, tt := range tests {
+		got := parseInline(tt.in)
+		if len(got) != len(tt.texts) {
+			t.Errorf("parseInline(%q): got %d runs, want %d", tt.in, len(got), len(tt.texts))
+			continue
+		}
+		for i, r := range got {
+			if r.Text != tt.texts[i] || r.AttrFlags != tt.flags[i] {
+				t.Errorf("parseInline(%q)[%d] = %q/%v, want %q/%v", tt.in, i, r.Text, r.AttrFlags, tt.texts[i], tt.flags[i])
+			}
+		}
+	}
+}
+
+func TestMarkdownTable(t *testing.T) {
+	blocks := Markdown([]byte("| a | b \\| c |\n|---|:-:|\n| **1** | 2 |\n\nafter"))
+	if len(blocks) != 2 {
+		t.Fatalf("got %d blocks, want table and text", len(blocks))
+	}
+	table := blocks[0]
+	if table.Type != notiontypes.BlockTable || len(table.Content) != 2 || len(table.Rows) != 2 {
+		t.Fatalf("unexpected table %+v", table)
+	}
+	if got := table.Rows[0][1][0].Text; got != "b | c" {
+		t.Errorf("got header cell %q, want %q", got, "b | c")
+	}
+	if cell := table.Rows[1][0][0]; cell.Text != "1" || cell.AttrFlags != notiontypes.AttrBold {
+		t.Errorf("unexpected cell %+v", cell)
+	}
+	if _, ok := table.Content[1].Properties["col1"]; !ok {
+		t.Errorf("row is missing cell property: %v", table.Content[1].Properties)
+	}
+}
+
+func TestMarkdownWithFrontMatter(t *testing.T) {
+	src := "---\ntitle: \"Launch: plan\"\nicon: :rocket:\ntags:\n  - a\ncover: 'it''s.png'\n---\n# Goals\n"
+	p := MarkdownWithFrontMatter([]byte(src))
+	if p.Title != "Launch: plan" || p.Icon != "🚀" || p.Cover != "it's.png" || len(p.Params) != 3 {
+		t.Errorf("got page %+v", p)
+	}
+	if len(p.Content) != 1 || p.Content[0].Type != notiontypes.BlockHeader {
+		t.Errorf("got content %v", p.Content)
+	}
+	if p := MarkdownWithFrontMatter([]byte("text\n---\n")); len(p.Params) != 0 || len(p.Content) == 0 {
+		t.Errorf("markdown without front-matter: got %+v", p)
+	}
+}
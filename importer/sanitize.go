@@ -0,0 +1,346 @@
+package importer
+
+import (
+	"encoding/json"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+var (
+	// htmlUnsafe matches elements removed with their content, up to the
+	// end of the input if they aren't closed.
+	htmlUnsafe  = regexp.MustCompile(`(?is)<(?:script|style|iframe|object|embed|noscript|template|svg|math)\b.*?(?:</(?:script|style|iframe|object|embed|noscript|template|svg|math)\s*>|$)`)
+	htmlComment = regexp.MustCompile(`(?s)<!--.*?(?:-->|$)`)
+	htmlMarkup  = regexp.MustCompile(`</?[a-zA-Z][a-zA-Z0-9-]*(?:\s[^>]*)?/?>`)
+	mdFence     = regexp.MustCompile("^\\s{0,3}(```|~~~)")
+)
+
+// SanitizePolicy configures the sanitization of imported content, e.g.
+// clipped from untrusted web pages. The zero value strips HTML and unsafe
+// URLs but doesn't limit the size of the content.
+type SanitizePolicy struct {
+	// Schemes are the URL schemes allowed in links and in the sources of
+	// images, embeds and bookmarks, defaulting to http, https and mailto.
+	// Links with other schemes are removed, keeping their text, and so are
+	// blocks with other sources. Relative URLs are kept.
+	Schemes []string
+	// KeepHTML keeps HTML markup in text. Otherwise scripts, styles,
+	// frames, objects and comments are removed with their content, and
+	// other elements are replaced by their content.
+	KeepHTML bool
+	// MaxDepth is the maximum nesting depth of blocks, top-level blocks
+	// being at depth 1. The content of deeper blocks is moved up, after
+	// their ancestor at MaxDepth. 0 means no limit.
+	MaxDepth int
+	// MaxBlocks is the maximum number of blocks, counted in document
+	// order; further blocks are dropped. 0 means no limit.
+	MaxBlocks int
+}
+
+// SanitizeReport counts the changes made by a SanitizePolicy.
+type SanitizeReport struct {
+	// Elements is the number of HTML elements and comments removed.
+	Elements int `json:"elements"`
+	// URLs is the number of links and blocks removed for their URL.
+	URLs int `json:"urls"`
+	// Flattened is the number of blocks moved up for MaxDepth.
+	Flattened int `json:"flattened"`
+	// Dropped is the number of blocks dropped for MaxBlocks.
+	Dropped int `json:"dropped"`
+}
+
+// Changed reports whether any content was changed.
+func (r *SanitizeReport) Changed() bool {
+	return *r != SanitizeReport{}
+}
+
+// Markdown converts markdown source into sanitized notion blocks, see
+// Markdown. HTML outside of fenced code blocks is sanitized before
+// parsing, so elements spanning paragraphs are removed as a whole.
+func (p *SanitizePolicy) Markdown(src []byte) ([]*notiontypes.Block, *SanitizeReport) {
+	r := &SanitizeReport{}
+	if !p.KeepHTML {
+		src = []byte(p.stripMarkdownHTML(string(src), r))
+	}
+	blocks := p.sanitize(Markdown(src), r)
+	return blocks, r
+}
+
+// MarkdownWithFrontMatter converts markdown source into a sanitized page,
+// see MarkdownWithFrontMatter.
+func (p *SanitizePolicy) MarkdownWithFrontMatter(src []byte) (*MarkdownPage, *SanitizeReport) {
+	r := &SanitizeReport{}
+	if !p.KeepHTML {
+		src = []byte(p.stripMarkdownHTML(string(src), r))
+	}
+	page := MarkdownWithFrontMatter(src)
+	page.Content = p.sanitize(page.Content, r)
+	if !p.KeepHTML {
+		page.Title = p.stripHTML(page.Title, r)
+	}
+	if page.Cover != "" {
+		if cover, ok := p.cleanURL(page.Cover); ok {
+			page.Cover = cover
+		} else {
+			page.Cover = ""
+			r.URLs++
+		}
+	}
+	return page, r
+}
+
+// Sanitize sanitizes imported blocks in place and returns those left.
+func (p *SanitizePolicy) Sanitize(blocks []*notiontypes.Block) ([]*notiontypes.Block, *SanitizeReport) {
+	r := &SanitizeReport{}
+	blocks = p.sanitize(blocks, r)
+	return blocks, r
+}
+
+func (p *SanitizePolicy) sanitize(blocks []*notiontypes.Block, r *SanitizeReport) []*notiontypes.Block {
+	count := 0
+	return p.sanitizeBlocks(blocks, 1, &count, r)
+}
+
+// sanitizeBlocks sanitizes blocks at the given depth, count being the
+// number of blocks kept so far.
+func (p *SanitizePolicy) sanitizeBlocks(blocks []*notiontypes.Block, depth int, count *int, r *SanitizeReport) []*notiontypes.Block {
+	var kept []*notiontypes.Block
+	for i, b := range blocks {
+		if p.MaxBlocks > 0 && *count >= p.MaxBlocks {
+			for _, b := range blocks[i:] {
+				r.Dropped += countBlocks(b)
+			}
+			break
+		}
+		if !p.sanitizeBlock(b, r) {
+			r.URLs++
+			continue
+		}
+		*count++
+		kept = append(kept, b)
+		if len(b.Content) == 0 || b.Type == notiontypes.BlockTable {
+			continue
+		}
+		if p.MaxDepth > 0 && depth >= p.MaxDepth {
+			content := b.Content
+			b.Content = nil
+			for _, c := range content {
+				r.Flattened += countBlocks(c)
+			}
+			kept = append(kept, p.sanitizeBlocks(flattenBlocks(content), depth, count, r)...)
+			continue
+		}
+		b.Content = p.sanitizeBlocks(b.Content, depth+1, count, r)
+	}
+	return kept
+}
+
+// sanitizeBlock sanitizes the text and URLs of b, but not its content, and
+// reports whether to keep it.
+func (p *SanitizePolicy) sanitizeBlock(b *notiontypes.Block, r *SanitizeReport) bool {
+	for _, key := range []string{"source", "link"} {
+		u, ok := firstText(b.Properties[key])
+		if !ok || u == "" {
+			continue
+		}
+		clean, ok := p.cleanURL(u)
+		if !ok {
+			return false
+		}
+		b.Properties[key] = [][]string{{clean}}
+		if b.Source == u {
+			b.Source = clean
+		}
+		if b.Link == u {
+			b.Link = clean
+		}
+	}
+	if f := b.FormatBookmark; f != nil {
+		changed := false
+		for _, u := range []*string{&f.BookmarkIcon, &f.BookmarkCover} {
+			if *u == "" {
+				continue
+			}
+			if clean, ok := p.cleanURL(*u); ok {
+				*u = clean
+			} else {
+				*u = ""
+				r.URLs++
+				changed = true
+			}
+		}
+		if changed {
+			b.FormatRaw, _ = json.Marshal(f)
+		}
+	}
+	if b.InlineContent != nil {
+		setText(b, p.sanitizeText(b.InlineContent, r))
+	}
+	if b.Type == notiontypes.BlockTable && b.FormatTable != nil {
+		for _, row := range b.Rows {
+			for i, cell := range row {
+				row[i] = p.sanitizeText(cell, r)
+			}
+		}
+		b.Content = Table(b.Rows, b.FormatTable.ColumnHeader).Content
+	}
+	if !p.KeepHTML && b.Properties != nil {
+		if caption, ok := firstText(b.Properties["caption"]); ok {
+			b.Properties["caption"] = [][]string{{p.stripHTML(caption, r)}}
+		}
+		if b.Title != "" && b.InlineContent == nil {
+			b.Title = p.stripHTML(b.Title, r)
+		}
+		if b.Description != "" {
+			b.Description = p.stripHTML(b.Description, r)
+			b.Properties["description"] = [][]string{{b.Description}}
+		}
+	}
+	return true
+}
+
+// sanitizeText removes the HTML in text, except in code, and its unsafe
+// links.
+func (p *SanitizePolicy) sanitizeText(text []*notiontypes.InlineBlock, r *SanitizeReport) []*notiontypes.InlineBlock {
+	var res []*notiontypes.InlineBlock
+	for _, run := range text {
+		if run.Link != "" {
+			if clean, ok := p.cleanURL(run.Link); ok {
+				run.Link = clean
+			} else {
+				run.Link = ""
+				r.URLs++
+			}
+		}
+		if !p.KeepHTML && run.AttrFlags&notiontypes.AttrCode == 0 {
+			run.Text = p.stripHTML(run.Text, r)
+		}
+		if run.Text != "" {
+			res = append(res, run)
+		}
+	}
+	return res
+}
+
+// stripMarkdownHTML removes the unsafe elements and comments of markdown
+// source outside of fenced code blocks.
+func (p *SanitizePolicy) stripMarkdownHTML(src string, r *SanitizeReport) string {
+	src = strings.Replace(src, "\r\n", "\n", -1)
+	var (
+		out    []string
+		text   []string
+		fenced bool
+	)
+	flush := func() {
+		if len(text) > 0 {
+			s := strings.Join(text, "\n")
+			s = replaceCounting(htmlUnsafe, s, r)
+			s = replaceCounting(htmlComment, s, r)
+			out = append(out, s)
+			text = nil
+		}
+	}
+	for _, line := range strings.Split(src, "\n") {
+		if mdFence.MatchString(line) {
+			if !fenced {
+				flush()
+			}
+			fenced = !fenced
+		}
+		if fenced || mdFence.MatchString(line) {
+			out = append(out, line)
+			continue
+		}
+		text = append(text, line)
+	}
+	flush()
+	return strings.Join(out, "\n")
+}
+
+// stripHTML removes the HTML elements of s, keeping the content of those
+// that are safe.
+func (p *SanitizePolicy) stripHTML(s string, r *SanitizeReport) string {
+	if !strings.Contains(s, "<") {
+		return s
+	}
+	s = replaceCounting(htmlUnsafe, s, r)
+	s = replaceCounting(htmlComment, s, r)
+	return replaceCounting(htmlMarkup, s, r)
+}
+
+func replaceCounting(re *regexp.Regexp, s string, r *SanitizeReport) string {
+	return re.ReplaceAllStringFunc(s, func(string) string {
+		r.Elements++
+		return ""
+	})
+}
+
+// cleanURL returns the normalized u and whether its scheme is allowed.
+// Control characters and spaces, which browsers ignore in schemes, and
+// character references are decoded or removed first, and protocol-relative
+// URLs get the https scheme.
+func (p *SanitizePolicy) cleanURL(u string) (string, bool) {
+	u = strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, html.UnescapeString(u))
+	if strings.HasPrefix(u, "//") {
+		u = "https:" + u
+	}
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return "", false
+	}
+	if parsed.Scheme == "" {
+		if strings.Contains(strings.SplitN(u, "/", 2)[0], ":") {
+			// not a URL, e.g. a scheme url.Parse rejected
+			return "", false
+		}
+		return parsed.String(), true
+	}
+	schemes := p.Schemes
+	if schemes == nil {
+		schemes = []string{"http", "https", "mailto"}
+	}
+	for _, s := range schemes {
+		if strings.EqualFold(parsed.Scheme, s) {
+			parsed.Scheme = strings.ToLower(parsed.Scheme)
+			return parsed.String(), true
+		}
+	}
+	return "", false
+}
+
+// flattenBlocks returns blocks and their descendants in document order,
+// without their content.
+func flattenBlocks(blocks []*notiontypes.Block) []*notiontypes.Block {
+	var res []*notiontypes.Block
+	for _, b := range blocks {
+		content := b.Content
+		if b.Type != notiontypes.BlockTable {
+			b.Content = nil
+		}
+		res = append(res, b)
+		if b.Type != notiontypes.BlockTable {
+			res = append(res, flattenBlocks(content)...)
+		}
+	}
+	return res
+}
+
+// countBlocks returns the number of blocks in the tree of b.
+func countBlocks(b *notiontypes.Block) int {
+	n := 1
+	if b.Type != notiontypes.BlockTable {
+		for _, c := range b.Content {
+			n += countBlocks(c)
+		}
+	}
+	return n
+}
@@ -0,0 +1,67 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+func TestSanitizePolicy(t *testing.T) {
+	src := strings.Join([]string{
+		"Hello <b>world</b><script>alert(1)</script>",
+		"",
+		"<script>",
+		"",
+		"steal()",
+		"</script>",
+		"",
+		"[click](javascript:steal) [ok](HTTPS://example.com)",
+		"",
+		"![x](jav&#x09;ascript:alert(1))",
+		"",
+		"```html",
+		"<script>kept()</script>",
+		"```",
+		"",
+		"- 1",
+		"  - 2",
+		"    - 3",
+		"- 4",
+		"- 5",
+	}, "\n")
+	p := &SanitizePolicy{MaxDepth: 2, MaxBlocks: 6}
+	blocks, r := p.Markdown([]byte(src))
+
+	text := func(runs []*notiontypes.InlineBlock) string {
+		var s string
+		for _, r := range runs {
+			s += r.Text
+		}
+		return s
+	}
+	var texts []string
+	for _, b := range blocks {
+		texts = append(texts, b.Type+":"+text(b.InlineContent)+b.Code)
+		for _, c := range b.Content {
+			texts = append(texts, "  "+c.Type+":"+text(c.InlineContent))
+		}
+	}
+	want := []string{
+		notiontypes.BlockText + ":Hello world",
+		notiontypes.BlockText + ":click ok",
+		notiontypes.BlockCode + ":<script>kept()</script>",
+		notiontypes.BlockBulletedList + ":1",
+		"  " + notiontypes.BlockBulletedList + ":2",
+		"  " + notiontypes.BlockBulletedList + ":3",
+	}
+	if strings.Join(texts, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("got blocks\n%s\nwant\n%s", strings.Join(texts, "\n"), strings.Join(want, "\n"))
+	}
+	if links := blocks[1].InlineContent; links[0].Link != "" || links[len(links)-1].Link != "https://example.com" {
+		t.Errorf("got links %q and %q", links[0].Link, links[len(links)-1].Link)
+	}
+	if want := (SanitizeReport{Elements: 4, URLs: 2, Flattened: 1, Dropped: 2}); *r != want {
+		t.Errorf("got report %+v, want %+v", *r, want)
+	}
+}
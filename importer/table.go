@@ -0,0 +1,81 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+var (
+	mdTableRow       = regexp.MustCompile(`^\s*\|.*\|\s*$`)
+	mdTableSeparator = regexp.MustCompile(`^\s*\|?(\s*:?-+:?\s*\|)+\s*(:?-+:?\s*)?$`)
+)
+
+// Table returns a simple table block with the given rows of cells.
+// If header is set the first row is marked as the column header.
+func Table(rows [][][]*notiontypes.InlineBlock, header bool) *notiontypes.Block {
+	width := 0
+	for _, row := range rows {
+		if len(row) > width {
+			width = len(row)
+		}
+	}
+	columns := make([]string, width)
+	for i := range columns {
+		columns[i] = fmt.Sprintf("col%d", i)
+	}
+	format := &notiontypes.FormatTable{
+		ColumnOrder:  columns,
+		ColumnHeader: header,
+	}
+	b := newBlock(notiontypes.BlockTable, nil)
+	b.FormatTable = format
+	b.FormatRaw, _ = json.Marshal(format)
+	b.Rows = rows
+	for _, row := range rows {
+		r := newBlock(notiontypes.BlockTableRow, nil)
+		for i, cell := range row {
+			if len(cell) > 0 {
+				r.Properties[columns[i]] = notiontypes.EncodeInlineBlocks(cell)
+			}
+		}
+		b.Content = append(b.Content, r)
+	}
+	return b
+}
+
+// splitTableRow splits a markdown table row into its (trimmed) cells.
+func splitTableRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	line = strings.TrimSuffix(line, "|")
+	var cells []string
+	cur := []byte{}
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cur = append(cur, '|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(string(cur)))
+			cur = cur[:0]
+		default:
+			cur = append(cur, line[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(string(cur)))
+}
+
+func tableBlock(rows [][]string) *notiontypes.Block {
+	parsed := make([][][]*notiontypes.InlineBlock, len(rows))
+	for i, row := range rows {
+		parsed[i] = make([][]*notiontypes.InlineBlock, len(row))
+		for j, cell := range row {
+			parsed[i][j] = parseInline(strings.Replace(cell, "<br>", "\n", -1))
+		}
+	}
+	return Table(parsed, true)
+}
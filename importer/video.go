@@ -0,0 +1,151 @@
+package importer
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// DefaultVideoAspectRatio is the height to width ratio of videos whose
+// size is unknown, 16:9.
+const DefaultVideoAspectRatio = 0.5625
+
+// OEmbed is the oEmbed (https://oembed.com) metadata of a video.
+type OEmbed struct {
+	Type         string `json:"type"`
+	Title        string `json:"title"`
+	AuthorName   string `json:"author_name"`
+	ProviderName string `json:"provider_name"`
+	ThumbnailURL string `json:"thumbnail_url"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+}
+
+// OEmbedFetcher fetches the oEmbed metadata of a video URL.
+type OEmbedFetcher interface {
+	FetchOEmbed(videoURL string) (*OEmbed, error)
+}
+
+var (
+	youTubeID = regexp.MustCompile(`^[A-Za-z0-9_-]{11}$`)
+	vimeoID   = regexp.MustCompile(`^[0-9]+$`)
+)
+
+// VideoEmbedURL returns the URL of the player of the YouTube or Vimeo
+// video at videoURL, which can be put into an iframe, and false for URLs of
+// other sites.
+func VideoEmbedURL(videoURL string) (string, bool) {
+	u, err := url.Parse(videoURL)
+	if err != nil {
+		return "", false
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	id := ""
+	switch host {
+	case "youtube.com", "m.youtube.com", "youtube-nocookie.com":
+		switch {
+		case len(parts) == 1 && parts[0] == "watch":
+			id = u.Query().Get("v")
+		case len(parts) == 2 && (parts[0] == "embed" || parts[0] == "shorts" || parts[0] == "live"):
+			id = parts[1]
+		}
+	case "youtu.be":
+		id = parts[0]
+	case "vimeo.com", "player.vimeo.com":
+		if n := len(parts); n > 0 && vimeoID.MatchString(parts[n-1]) {
+			return "https://player.vimeo.com/video/" + parts[n-1], true
+		}
+		return "", false
+	default:
+		return "", false
+	}
+	if !youTubeID.MatchString(id) {
+		return "", false
+	}
+	return "https://www.youtube.com/embed/" + id, true
+}
+
+// oEmbedEndpoint returns the oEmbed endpoint for the video at videoURL.
+func oEmbedEndpoint(videoURL string) string {
+	endpoint := "https://www.youtube.com/oembed"
+	if embed, _ := VideoEmbedURL(videoURL); strings.Contains(embed, "vimeo.com") {
+		endpoint = "https://vimeo.com/api/oembed.json"
+	}
+	return endpoint + "?format=json&url=" + url.QueryEscape(videoURL)
+}
+
+// FetchOEmbed implements OEmbedFetcher for YouTube and Vimeo videos.
+func (f *HTTPFetcher) FetchOEmbed(videoURL string) (*OEmbed, error) {
+	if _, ok := VideoEmbedURL(videoURL); !ok {
+		return nil, errors.Errorf("%v is not a YouTube or Vimeo video", videoURL)
+	}
+	req, err := http.NewRequest("GET", oEmbedEndpoint(videoURL), nil)
+	if err != nil {
+		return nil, err
+	}
+	if f.UserAgent != "" {
+		req.Header.Set("User-Agent", f.UserAgent)
+	}
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("fetching oEmbed of %v: %v", videoURL, resp.Status)
+	}
+	b, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxPageSize))
+	if err != nil {
+		return nil, err
+	}
+	o := &OEmbed{}
+	if err := json.Unmarshal(b, o); err != nil {
+		return nil, errors.Wrap(err, "unmarshaling oEmbed")
+	}
+	return o, nil
+}
+
+// Video returns a video block playing the YouTube or Vimeo video at
+// videoURL. Its aspect ratio is read from the oEmbed metadata f fetches,
+// if f isn't nil, and is DefaultVideoAspectRatio otherwise. The block is
+// returned along with the error if fetching the metadata fails.
+func Video(videoURL string, f OEmbedFetcher) (*notiontypes.Block, error) {
+	embed, ok := VideoEmbedURL(videoURL)
+	if !ok {
+		return nil, errors.Errorf("%v is not a YouTube or Vimeo video", videoURL)
+	}
+	b := newBlock(notiontypes.BlockVideo, nil)
+	b.Properties["source"] = [][]string{{videoURL}}
+	b.Source = videoURL
+	format := &notiontypes.FormatVideo{
+		DisplaySource:      embed,
+		BlockAspectRatio:   DefaultVideoAspectRatio,
+		BlockPreserveScale: true,
+	}
+	var err error
+	if f != nil {
+		var o *OEmbed
+		if o, err = f.FetchOEmbed(videoURL); err == nil && o.Width > 0 && o.Height > 0 {
+			format.BlockAspectRatio = float64(o.Height) / float64(o.Width)
+			format.BlockWidth, format.BlockHeight = int64(o.Width), int64(o.Height)
+		}
+	}
+	b.FormatVideo = format
+	var merr error
+	if b.FormatRaw, merr = json.Marshal(format); merr != nil {
+		return nil, merr
+	}
+	return b, err
+}
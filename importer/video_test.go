@@ -0,0 +1,67 @@
+package importer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+func TestVideoEmbedURL(t *testing.T) {
+	for u, want := range map[string]string{
+		"https://www.youtube.com/watch?v=dQw4w9WgXcQ&t=42": "https://www.youtube.com/embed/dQw4w9WgXcQ",
+		"https://youtu.be/dQw4w9WgXcQ":                     "https://www.youtube.com/embed/dQw4w9WgXcQ",
+		"https://youtube.com/shorts/dQw4w9WgXcQ":           "https://www.youtube.com/embed/dQw4w9WgXcQ",
+		"https://vimeo.com/76979871":                       "https://player.vimeo.com/video/76979871",
+		"https://vimeo.com/channels/staffpicks/76979871":   "https://player.vimeo.com/video/76979871",
+		"https://www.youtube.com/watch?v=short":            "",
+		"https://example.com/watch?v=dQw4w9WgXcQ":          "",
+	} {
+		if got, _ := VideoEmbedURL(u); got != want {
+			t.Errorf("VideoEmbedURL(%v) = %q, want %q", u, got, want)
+		}
+	}
+}
+
+// rewriteTransport sends all requests to the server at target.
+type rewriteTransport struct{ target *url.URL }
+
+func (t rewriteTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r.URL.Scheme, r.URL.Host = t.target.Scheme, t.target.Host
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestVideo(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/oembed.json" || r.URL.Query().Get("url") != "https://vimeo.com/76979871" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"type":"video","title":"The New Vimeo Player","width":640,"height":360}`))
+	}))
+	defer srv.Close()
+	target, _ := url.Parse(srv.URL)
+	f := &HTTPFetcher{Client: &http.Client{Transport: rewriteTransport{target}}}
+
+	b, err := Video("https://vimeo.com/76979871", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Type != notiontypes.BlockVideo || b.FormatVideo.DisplaySource != "https://player.vimeo.com/video/76979871" ||
+		b.FormatVideo.BlockAspectRatio != 0.5625 || b.FormatVideo.BlockWidth != 640 || len(b.FormatRaw) == 0 {
+		t.Errorf("got video %+v, format %+v", b, b.FormatVideo)
+	}
+
+	// the block is usable when the metadata can't be fetched
+	b, err = Video("https://youtu.be/dQw4w9WgXcQ", f)
+	if err == nil || b == nil || b.FormatVideo.BlockAspectRatio != DefaultVideoAspectRatio {
+		t.Errorf("got video %+v, error %v", b, err)
+	}
+
+	blocks := Markdown([]byte("Watch this:\n\nhttps://youtu.be/dQw4w9WgXcQ\n\nhttps://example.com\n"))
+	if len(blocks) != 3 || blocks[1].Type != notiontypes.BlockVideo || blocks[2].Type != notiontypes.BlockText {
+		t.Errorf("got markdown blocks %v", blocks)
+	}
+}
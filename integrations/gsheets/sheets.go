@@ -0,0 +1,158 @@
+// Package gsheets syncs the rows of a Google Sheet with a notion database,
+// one way or both ways, matching rows by the value of a key column.
+package gsheets
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion/xlsx"
+)
+
+// DefaultBaseURL is the URL of the Google Sheets API.
+const DefaultBaseURL = "https://sheets.googleapis.com/v4/"
+
+// Sheets reads and writes the cells of a spreadsheet with the values
+// methods of the Google Sheets API v4. Ranges are in A1 notation, e.g.
+// "Tasks!A1:F".
+type Sheets struct {
+	// SpreadsheetID is the id in the URL of the spreadsheet.
+	SpreadsheetID string
+	// BaseURL defaults to DefaultBaseURL.
+	BaseURL string
+	// Client must authorize requests, e.g. an OAuth2 client for a service
+	// account the spreadsheet is shared with, with the scope
+	// https://www.googleapis.com/auth/spreadsheets.
+	Client *http.Client
+}
+
+type valueRange struct {
+	Range  string     `json:"range,omitempty"`
+	Values [][]string `json:"values"`
+}
+
+// Get returns the rows of rng as they are displayed, without the trailing
+// empty rows and cells.
+func (s *Sheets) Get(rng string) ([][]string, error) {
+	var v valueRange
+	err := s.do("GET", "values/"+url.PathEscape(rng)+"?valueRenderOption=FORMATTED_VALUE&dateTimeRenderOption=FORMATTED_STRING", nil, &v)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading %v", rng)
+	}
+	return v.Values, nil
+}
+
+// Update sets the cells of the given ranges, parsing values as if they
+// were typed in, so that e.g. numbers and dates keep their types.
+func (s *Sheets) Update(values map[string][]string) error {
+	req := struct {
+		ValueInputOption string        `json:"valueInputOption"`
+		Data             []*valueRange `json:"data"`
+	}{ValueInputOption: "USER_ENTERED"}
+	for rng, row := range values {
+		req.Data = append(req.Data, &valueRange{Range: rng, Values: [][]string{row}})
+	}
+	return errors.Wrap(s.do("POST", "values:batchUpdate", req, nil), "updating cells")
+}
+
+// Append adds rows after the table in rng.
+func (s *Sheets) Append(rng string, rows [][]string) error {
+	err := s.do("POST", "values/"+url.PathEscape(rng)+":append?valueInputOption=USER_ENTERED&insertDataOption=INSERT_ROWS", &valueRange{Values: rows}, nil)
+	return errors.Wrapf(err, "appending to %v", rng)
+}
+
+func (s *Sheets) do(method, path string, in, out interface{}) error {
+	base := s.BaseURL
+	if base == "" {
+		base = DefaultBaseURL
+	}
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(method, strings.TrimSuffix(base, "/")+"/spreadsheets/"+url.PathEscape(s.SpreadsheetID)+"/"+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode/100 != 2 {
+		var apiErr struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(b, &apiErr) == nil && apiErr.Error.Message != "" {
+			return errors.Errorf("%v: %v", resp.Status, apiErr.Error.Message)
+		}
+		return errors.Errorf("%v: %s", resp.Status, bytes.TrimSpace(b))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(b, out)
+}
+
+// cellRange is a parsed A1 range: the sheet and its top left cell.
+type cellRange struct {
+	sheet    string // as written, possibly quoted, with the "!"
+	col, row int    // zero-based
+}
+
+func parseRange(rng string) (*cellRange, error) {
+	r := &cellRange{}
+	if i := strings.LastIndex(rng, "!"); i >= 0 {
+		r.sheet, rng = rng[:i+1], rng[i+1:]
+	} else if !strings.ContainsAny(rng, "0123456789:") {
+		// a sheet name alone
+		return &cellRange{sheet: rng + "!"}, nil
+	}
+	start := strings.SplitN(rng, ":", 2)[0]
+	letters := strings.IndexFunc(start, func(c rune) bool { return c < 'A' || c > 'Z' })
+	if letters < 0 {
+		letters = len(start)
+	}
+	for _, c := range start[:letters] {
+		r.col = r.col*26 + int(c-'A'+1)
+	}
+	if r.col > 0 {
+		r.col--
+	}
+	if digits := start[letters:]; digits != "" {
+		n, err := strconv.Atoi(digits)
+		if err != nil || n < 1 {
+			return nil, errors.Errorf("gsheets: invalid range %q", rng)
+		}
+		r.row = n - 1
+	}
+	return r, nil
+}
+
+// cell returns the reference of the cell at the column and row offsets
+// from the top left cell of r.
+func (r *cellRange) cell(col, row int) string {
+	return r.sheet + xlsx.CellRef(r.col+col, r.row+row)
+}
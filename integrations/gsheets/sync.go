@@ -0,0 +1,379 @@
+package gsheets
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// Direction is the direction changes are synced in.
+type Direction string
+
+// Directions.
+const (
+	ToNotion Direction = "to-notion"
+	ToSheet  Direction = "to-sheet"
+	TwoWay   Direction = "two-way"
+)
+
+// ConflictPolicy decides which value wins when a cell was changed both in
+// the sheet and in notion since the last sync.
+type ConflictPolicy string
+
+// Conflict policies.
+const (
+	// PreferNotion overwrites the cell in the sheet.
+	PreferNotion ConflictPolicy = "notion"
+	// PreferSheet overwrites the property in notion.
+	PreferSheet ConflictPolicy = "sheet"
+	// Skip leaves both values and reports the conflict.
+	Skip ConflictPolicy = "skip"
+)
+
+// Config configures Sync.
+type Config struct {
+	// Range is the table in the sheet, whose first row is the header, e.g.
+	// "Tasks!A1:F" or just "Tasks".
+	Range string
+	// CollectionID is the database synced with the sheet. Columns are
+	// synced with the properties of the same (case-insensitive) name,
+	// other columns and properties are left alone.
+	CollectionID string
+	// Key is the column whose values identify rows. Rows with an empty key
+	// are left alone.
+	Key string
+	// Direction defaults to TwoWay.
+	Direction Direction
+	// Conflicts is the conflict policy of two-way syncs, Skip by default.
+	Conflicts ConflictPolicy
+	// ColumnConflicts overrides Conflicts for the columns it names.
+	ColumnConflicts map[string]ConflictPolicy `json:",omitempty"`
+}
+
+// State is what Sync remembers between syncs: the values of rows after the
+// last sync, to tell which side changed a cell. It's marshaled as JSON.
+type State struct {
+	Time time.Time `json:"time"`
+	// Rows are the values of rows by key, then by column.
+	Rows map[string]map[string]string `json:"rows"`
+}
+
+// Conflict is a cell changed on both sides.
+type Conflict struct {
+	Key, Column   string
+	Sheet, Notion string
+}
+
+// Result reports the changes made by Sync.
+type Result struct {
+	// RowsCreated and RowsUpdated count notion rows.
+	RowsCreated, RowsUpdated int
+	// RowsAppended and RowsChanged count sheet rows.
+	RowsAppended, RowsChanged int
+	// Conflicts are the conflicts left unresolved by the Skip policy.
+	Conflicts []*Conflict
+}
+
+// sheetRow is a row of the sheet, at data row index (after the header).
+type sheetRow struct {
+	index  int
+	values map[string]string
+}
+
+// Sync syncs the rows of the sheet table cfg.Range with the database
+// cfg.CollectionID, matching them by cfg.Key. Rows missing on one side
+// are added to it, depending on the direction, but deleted rows aren't
+// deleted from the other side.
+//
+// Values are compared as notion row text (see notion.Client.UpdateRow),
+// so that e.g. TRUE in the sheet equals a checked checkbox. With the
+// state of the previous sync, a two-way sync copies a value that changed
+// on one side only to the other; values that differ on both sides, or
+// that differ without a previous state, are resolved by the conflict
+// policy. state, if not nil, is read and then updated for the next sync.
+func Sync(c *notion.Client, s *Sheets, cfg Config, state *State) (*Result, error) {
+	if cfg.Direction == "" {
+		cfg.Direction = TwoWay
+	}
+	if cfg.Conflicts == "" {
+		cfg.Conflicts = Skip
+	}
+	for _, p := range append([]ConflictPolicy{cfg.Conflicts}, policies(cfg.ColumnConflicts)...) {
+		if p != PreferNotion && p != PreferSheet && p != Skip {
+			return nil, errors.Errorf("gsheets: unknown conflict policy %q", p)
+		}
+	}
+	if cfg.Direction != ToNotion && cfg.Direction != ToSheet && cfg.Direction != TwoWay {
+		return nil, errors.Errorf("gsheets: unknown direction %q", cfg.Direction)
+	}
+	rng, err := parseRange(cfg.Range)
+	if err != nil {
+		return nil, err
+	}
+	collection, err := c.GetCollection(cfg.CollectionID)
+	if err != nil {
+		return nil, err
+	}
+	values, err := s.Get(cfg.Range)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) == 0 {
+		return nil, errors.Errorf("gsheets: %v has no header", cfg.Range)
+	}
+	header := values[0]
+
+	// columns are the synced columns, by name, with their property types
+	columns := map[string]string{}
+	key := ""
+	for _, name := range header {
+		for _, col := range collection.CollectionSchema {
+			if strings.EqualFold(col.Name, name) {
+				columns[name] = col.Type
+			}
+		}
+		if strings.EqualFold(name, cfg.Key) {
+			key = name
+		}
+	}
+	if key == "" || columns[key] == "" {
+		return nil, errors.Errorf("gsheets: key %q isn't both a column and a property", cfg.Key)
+	}
+
+	sheet := map[string]*sheetRow{}
+	var sheetOrder []string
+	for i, cells := range values[1:] {
+		row := &sheetRow{index: i, values: map[string]string{}}
+		for j, name := range header {
+			if typ, ok := columns[name]; ok && j < len(cells) {
+				row.values[name] = fromSheet(cells[j], typ)
+			}
+		}
+		k := row.values[key]
+		if k == "" {
+			continue
+		}
+		if sheet[k] != nil {
+			return nil, errors.Errorf("gsheets: duplicate key %q in rows %d and %d", k, sheet[k].index+2, i+2)
+		}
+		sheet[k] = row
+		sheetOrder = append(sheetOrder, k)
+	}
+
+	type notionRow struct {
+		id     string
+		values map[string]string
+	}
+	rows := map[string]*notionRow{}
+	var order []string
+	err = c.ForEachRow(collection.ID, notion.RowQuery{}, func(r notion.Row) error {
+		row := &notionRow{id: r.ID, values: map[string]string{}}
+		for name := range columns {
+			row.values[name] = propertyText(r.Property(name))
+		}
+		k := row.values[key]
+		if k == "" {
+			return nil
+		}
+		if rows[k] != nil {
+			return errors.Errorf("gsheets: duplicate key %q in rows %v and %v", k, rows[k].id, r.ID)
+		}
+		rows[k] = row
+		order = append(order, k)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Result{}
+	synced := map[string]map[string]string{}
+	updates := map[string][]string{}
+	for _, k := range sheetOrder {
+		srow, nrow := sheet[k], rows[k]
+		if nrow == nil {
+			if cfg.Direction == ToSheet {
+				continue
+			}
+			if _, err := c.CreateRow(collection.ID, srow.values); err != nil {
+				return nil, errors.Wrapf(err, "creating row %q", k)
+			}
+			res.RowsCreated++
+			synced[k] = srow.values
+			continue
+		}
+		var base map[string]string
+		if state != nil {
+			base = state.Rows[k]
+		}
+		toNotion, toSheet := map[string]string{}, map[string]string{}
+		agreed := map[string]string{}
+		for name := range columns {
+			sv, nv := srow.values[name], nrow.values[name]
+			if sv == nv {
+				agreed[name] = sv
+				continue
+			}
+			winner := cfg.Conflicts
+			if p, ok := cfg.ColumnConflicts[name]; ok {
+				winner = p
+			}
+			b, known := base[name]
+			switch {
+			case cfg.Direction == ToNotion:
+				winner = PreferSheet
+			case cfg.Direction == ToSheet:
+				winner = PreferNotion
+			case known && b == sv:
+				winner = PreferNotion
+			case known && b == nv:
+				winner = PreferSheet
+			}
+			switch winner {
+			case PreferSheet:
+				toNotion[name], agreed[name] = sv, sv
+			case PreferNotion:
+				toSheet[name], agreed[name] = nv, nv
+			default:
+				res.Conflicts = append(res.Conflicts, &Conflict{Key: k, Column: name, Sheet: sv, Notion: nv})
+				if known {
+					agreed[name] = b
+				}
+			}
+		}
+		if len(toNotion) > 0 {
+			if err := c.UpdateRow(collection.ID, nrow.id, toNotion); err != nil {
+				return nil, errors.Wrapf(err, "updating row %q", k)
+			}
+			res.RowsUpdated++
+		}
+		for name, v := range toSheet {
+			for j, h := range header {
+				if h == name {
+					updates[rng.cell(j, srow.index+1)] = []string{toSheetValue(v, columns[name])}
+				}
+			}
+		}
+		if len(toSheet) > 0 {
+			res.RowsChanged++
+		}
+		synced[k] = agreed
+	}
+	if len(updates) > 0 {
+		if err := s.Update(updates); err != nil {
+			return nil, err
+		}
+	}
+
+	var appended [][]string
+	for _, k := range order {
+		if sheet[k] != nil || cfg.Direction == ToNotion {
+			continue
+		}
+		cells := make([]string, len(header))
+		for j, name := range header {
+			if typ, ok := columns[name]; ok {
+				cells[j] = toSheetValue(rows[k].values[name], typ)
+			}
+		}
+		appended = append(appended, cells)
+		synced[k] = rows[k].values
+	}
+	if len(appended) > 0 {
+		if err := s.Append(cfg.Range, appended); err != nil {
+			return nil, err
+		}
+		res.RowsAppended = len(appended)
+	}
+
+	if state != nil {
+		state.Time = time.Now().UTC()
+		state.Rows = synced
+	}
+	return res, nil
+}
+
+func policies(m map[string]ConflictPolicy) []ConflictPolicy {
+	var ps []ConflictPolicy
+	for _, p := range m {
+		ps = append(ps, p)
+	}
+	return ps
+}
+
+// propertyText returns the value of p as text in the format of
+// notion.Client.UpdateRow.
+func propertyText(p *notiontypes.PageProperty) string {
+	if p == nil {
+		return ""
+	}
+	switch p.Type {
+	case notiontypes.ColumnTypeCheckbox:
+		if p.Checked() {
+			return "Yes"
+		}
+		return "No"
+	case notiontypes.ColumnTypeDate:
+		if d := p.Date(); d != nil {
+			if d.StartTime != nil {
+				return d.StartDate + " " + *d.StartTime
+			}
+			return d.StartDate
+		}
+		return ""
+	case notiontypes.ColumnTypeNumber:
+		return fromSheet(p.Text(), p.Type)
+	}
+	return p.Text()
+}
+
+// sheetDateLayouts are the formats of dates read from sheets.
+var sheetDateLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"1/2/2006 15:04:05",
+	"1/2/2006 15:04",
+	"2006-01-02",
+	"1/2/2006",
+}
+
+// fromSheet converts the displayed value of a cell to the text of a
+// property of type typ.
+func fromSheet(v, typ string) string {
+	v = strings.TrimSpace(v)
+	switch typ {
+	case notiontypes.ColumnTypeCheckbox:
+		switch strings.ToLower(v) {
+		case "true", "yes", "1", "x", "✓":
+			return "Yes"
+		}
+		return "No"
+	case notiontypes.ColumnTypeNumber:
+		if n, err := strconv.ParseFloat(strings.Replace(v, ",", "", -1), 64); err == nil {
+			return strconv.FormatFloat(n, 'f', -1, 64)
+		}
+	case notiontypes.ColumnTypeDate:
+		for _, layout := range sheetDateLayouts {
+			if t, err := time.Parse(layout, v); err == nil {
+				if strings.Contains(layout, "15") {
+					return t.Format("2006-01-02 15:04")
+				}
+				return t.Format("2006-01-02")
+			}
+		}
+	}
+	return v
+}
+
+// toSheetValue converts the text of a property of type typ to a value
+// entered in a cell.
+func toSheetValue(v, typ string) string {
+	if typ == notiontypes.ColumnTypeCheckbox {
+		return strings.ToUpper(strconv.FormatBool(v == "Yes"))
+	}
+	return v
+}
@@ -0,0 +1,139 @@
+package gsheets
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/notiontest"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// fakeSheets serves the values of a single sheet whose range starts at A1.
+type fakeSheets struct {
+	mu   sync.Mutex
+	rows [][]string
+}
+
+func (f *fakeSheets) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var req struct {
+		Data   []*valueRange `json:"data"`
+		Values [][]string    `json:"values"`
+	}
+	json.NewDecoder(r.Body).Decode(&req)
+	switch {
+	case r.Method == "GET":
+		json.NewEncoder(w).Encode(&valueRange{Values: f.rows})
+	case strings.HasSuffix(r.URL.Path, "values:batchUpdate"):
+		for _, d := range req.Data {
+			ref, err := parseRange(d.Range)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			for len(f.rows) <= ref.row {
+				f.rows = append(f.rows, nil)
+			}
+			for len(f.rows[ref.row]) <= ref.col {
+				f.rows[ref.row] = append(f.rows[ref.row], "")
+			}
+			f.rows[ref.row][ref.col] = d.Values[0][0]
+		}
+	case strings.HasSuffix(r.URL.Path, ":append"):
+		f.rows = append(f.rows, req.Values...)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestSync(t *testing.T) {
+	s := notiontest.NewServer()
+	defer s.Close()
+	s.AddRecord(notiontypes.TableCollection, "db", map[string]interface{}{
+		"schema": map[string]interface{}{
+			"title": map[string]string{"name": "Name", "type": "title"},
+			"st":    map[string]string{"name": "Status", "type": "text"},
+			"ok":    map[string]string{"name": "Done", "type": "checkbox"},
+			"pt":    map[string]string{"name": "Points", "type": "number"},
+		},
+	})
+	for i, props := range []map[string]interface{}{
+		{"title": [][]string{{"a"}}, "st": [][]string{{"todo"}}, "ok": [][]string{{"Yes"}}, "pt": [][]string{{"1"}}},
+		{"title": [][]string{{"b"}}, "st": [][]string{{"doing"}}, "pt": [][]string{{"2"}}},
+		{"title": [][]string{{"n"}}, "st": [][]string{{"notion only"}}},
+	} {
+		s.AddBlock(&notiontypes.Block{
+			ID:          "4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6" + string("abc"[i]),
+			Type:        notiontypes.BlockPage,
+			ParentID:    "db",
+			ParentTable: notiontypes.TableCollection,
+			CreatedTime: int64(i),
+			Properties:  props,
+		})
+	}
+	c := s.Client()
+	fake := &fakeSheets{rows: [][]string{
+		{"Name", "Status", "Done", "Points", "Notes"},
+		{"a", "todo", "TRUE", "1.0"},
+		{"b", "done", "FALSE", "2", "x"},
+		{"s", "sheet only", "FALSE", "1,000"},
+	}}
+	hs := httptest.NewServer(fake)
+	defer hs.Close()
+	sheets := &Sheets{SpreadsheetID: "sheet", BaseURL: hs.URL}
+	cfg := Config{Range: "Tasks!A1:E", CollectionID: "db", Key: "name"}
+	state := &State{}
+
+	res, err := Sync(c, sheets, cfg, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Result{RowsCreated: 1, RowsAppended: 1, Conflicts: []*Conflict{{Key: "b", Column: "Status", Sheet: "done", Notion: "doing"}}}
+	if !reflect.DeepEqual(res, want) {
+		t.Errorf("got first result %+v, want %+v", res, want)
+	}
+	if got := fake.rows[4]; !reflect.DeepEqual(got, []string{"n", "notion only", "FALSE", "", ""}) {
+		t.Errorf("got appended row %q", got)
+	}
+	if _, ok := state.Rows["b"]["Status"]; ok || state.Rows["s"]["Points"] != "1000" {
+		t.Errorf("got state %v", state.Rows)
+	}
+
+	// a's points changed in notion only, b's conflict is now resolved
+	// in favor of the sheet
+	if err := c.UpdateRow("db", "4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6a", map[string]string{"Points": "5"}); err != nil {
+		t.Fatal(err)
+	}
+	cfg.ColumnConflicts = map[string]ConflictPolicy{"Status": PreferSheet}
+	res, err = Sync(c, sheets, cfg, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (&Result{RowsUpdated: 1, RowsChanged: 1}); !reflect.DeepEqual(res, want) {
+		t.Errorf("got second result %+v, want %+v", res, want)
+	}
+	if got := fake.rows[1][3]; got != "5" {
+		t.Errorf("got points %q in sheet, want 5", got)
+	}
+	var status string
+	err = c.ForEachRow("db", notion.RowQuery{}, func(r notion.Row) error {
+		if r.ID == "4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6b" {
+			status = r.Property("Status").Text()
+		}
+		return nil
+	})
+	if err != nil || status != "done" {
+		t.Errorf("got status %q in notion, %v", status, err)
+	}
+
+	if _, err := Sync(c, sheets, Config{Range: "Tasks", CollectionID: "db", Key: "Notes"}, nil); err == nil {
+		t.Error("synced with a key that isn't a property")
+	}
+}
@@ -0,0 +1,326 @@
+// Package jsonschema generates JSON Schemas (draft 2020-12) and TypeScript
+// definitions of Go types, following the rules of encoding/json: fields
+// are named by their json tags, omitempty fields are optional, embedded
+// structs are flattened and types with their own JSON encoding (e.g.
+// json.RawMessage) accept any value, except time.Time, which is a
+// date-time string.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Generator collects the definitions of struct types.
+type Generator struct {
+	defs  []*definition
+	names map[reflect.Type]string
+	taken map[string]bool
+}
+
+type definition struct {
+	name   string
+	fields []*field
+	// extra holds properties replaced by SetProperty
+	extra map[string]map[string]interface{}
+}
+
+type field struct {
+	name     string
+	typ      reflect.Type
+	required bool
+}
+
+// nullable reports whether f encodes as null if it's nil: omitempty
+// fields are left out instead.
+func (f *field) nullable() bool {
+	switch f.typ.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+		return f.required && !custom(f.typ)
+	}
+	return false
+}
+
+// orNull returns s allowing null as well.
+func orNull(s map[string]interface{}) map[string]interface{} {
+	switch t := s["type"].(type) {
+	case string:
+		s["type"] = []string{t, "null"}
+		return s
+	case nil:
+		if s["$ref"] != nil {
+			return map[string]interface{}{"anyOf": []interface{}{s, map[string]interface{}{"type": "null"}}}
+		}
+	}
+	// s accepts anything already
+	return s
+}
+
+// New returns an empty Generator.
+func New() *Generator {
+	return &Generator{names: make(map[reflect.Type]string), taken: make(map[string]bool)}
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textType      = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Add adds the definitions of the struct type of v, a struct or a pointer to
+// one, and of the struct types it refers to. It returns the name of v's
+// definition: the name of its type, prefixed with its package name if
+// another type has the same name.
+func (g *Generator) Add(v interface{}) string {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		panic("jsonschema: " + t.String() + " is not a struct")
+	}
+	return g.add(t)
+}
+
+func (g *Generator) add(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+	name := t.Name()
+	if name == "" || g.taken[name] {
+		pkg := t.PkgPath()
+		name = pkg[strings.LastIndex(pkg, "/")+1:] + "_" + name
+	}
+	g.names[t] = name
+	g.taken[name] = true
+	def := &definition{name: name}
+	g.defs = append(g.defs, def)
+	def.fields = g.fields(t)
+	return name
+}
+
+// fields returns the JSON fields of the struct type t.
+func (g *Generator) fields(t reflect.Type) []*field {
+	var fields []*field
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := strings.Split(f.Tag.Get("json"), ",")
+		if tag[0] == "-" && len(tag) == 1 {
+			continue
+		}
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && tag[0] == "" && ft.Kind() == reflect.Struct {
+			fields = append(fields, g.fields(ft)...)
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		name := tag[0]
+		if name == "" {
+			name = f.Name
+		}
+		omitempty := false
+		for _, opt := range tag[1:] {
+			omitempty = omitempty || opt == "omitempty"
+		}
+		fields = append(fields, &field{name: name, typ: f.Type, required: !omitempty})
+		g.walk(f.Type)
+	}
+	return fields
+}
+
+// walk adds the struct types t refers to.
+func (g *Generator) walk(t reflect.Type) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if custom(t) {
+		return
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		g.walk(t.Elem())
+	case reflect.Struct:
+		g.add(t)
+	}
+}
+
+// custom reports whether t has its own JSON encoding.
+func custom(t reflect.Type) bool {
+	return t.Implements(marshalerType) || reflect.PtrTo(t).Implements(marshalerType) ||
+		t.Implements(textType) || reflect.PtrTo(t).Implements(textType)
+}
+
+// SetProperty replaces the schema of the property name of the definition
+// def, e.g. to add a "const".
+func (g *Generator) SetProperty(def, name string, schema map[string]interface{}) {
+	for _, d := range g.defs {
+		if d.name == def {
+			if d.extra == nil {
+				d.extra = make(map[string]map[string]interface{})
+			}
+			d.extra[name] = schema
+			return
+		}
+	}
+	panic("jsonschema: no definition " + def)
+}
+
+// Schema returns the schema with all definitions under "$defs". If root is
+// not empty, the schema validates values of that definition.
+func (g *Generator) Schema(title, root string) map[string]interface{} {
+	defs := make(map[string]interface{}, len(g.defs))
+	for _, d := range g.defs {
+		properties := make(map[string]interface{}, len(d.fields))
+		required := []string{}
+		for _, f := range d.fields {
+			properties[f.name] = g.schema(f.typ)
+			if f.nullable() {
+				properties[f.name] = orNull(properties[f.name].(map[string]interface{}))
+			}
+			if s, ok := d.extra[f.name]; ok {
+				properties[f.name] = s
+			}
+			if f.required {
+				required = append(required, f.name)
+			}
+		}
+		defs[d.name] = map[string]interface{}{"type": "object", "properties": properties, "required": required}
+	}
+	s := map[string]interface{}{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"title":   title,
+		"$defs":   defs,
+	}
+	if root != "" {
+		s["$ref"] = "#/$defs/" + root
+	}
+	return s
+}
+
+// JSON returns the indented JSON of Schema(title, root).
+func (g *Generator) JSON(title, root string) ([]byte, error) {
+	b, err := json.MarshalIndent(g.Schema(title, root), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+func (g *Generator) schema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case custom(t):
+		return map[string]interface{}{}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// encoded as base64
+			return map[string]interface{}{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]interface{}{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		return map[string]interface{}{"$ref": "#/$defs/" + g.names[t]}
+	}
+	// interfaces and anything else
+	return map[string]interface{}{}
+}
+
+// TypeScript returns TypeScript interfaces of all definitions, in the
+// order they were added.
+func (g *Generator) TypeScript() []byte {
+	buf := new(bytes.Buffer)
+	for i, d := range g.defs {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		fmt.Fprintf(buf, "export interface %s {\n", d.name)
+		for _, f := range d.fields {
+			optional := "?"
+			if f.required {
+				optional = ""
+			}
+			typ := g.typeScript(f.typ)
+			if s, ok := d.extra[f.name]; ok {
+				if c, ok := s["const"]; ok {
+					b, _ := json.Marshal(c)
+					typ = string(b)
+				}
+			}
+			if f.nullable() && typ != "unknown" {
+				typ += " | null"
+			}
+			fmt.Fprintf(buf, "  %s%s: %s;\n", quoteName(f.name), optional, typ)
+		}
+		buf.WriteString("}\n")
+	}
+	return buf.Bytes()
+}
+
+func (g *Generator) typeScript(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch s := g.schema(t); {
+	case s["$ref"] != nil:
+		return g.names[t]
+	case s["type"] == "array":
+		return g.typeScript(t.Elem()) + "[]"
+	case s["type"] == "object":
+		return "{ [key: string]: " + g.typeScript(t.Elem()) + " }"
+	case s["type"] == "string":
+		return "string"
+	case s["type"] == "integer" || s["type"] == "number":
+		return "number"
+	case s["type"] == "boolean":
+		return "boolean"
+	}
+	return "unknown"
+}
+
+// quoteName quotes property names that aren't identifiers.
+func quoteName(name string) string {
+	for i, r := range name {
+		if !(r == '_' || r == '$' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || i > 0 && r >= '0' && r <= '9') {
+			b, _ := json.Marshal(name)
+			return string(b)
+		}
+	}
+	return name
+}
+
+// Names returns the names of the definitions, sorted.
+func (g *Generator) Names() []string {
+	names := make([]string, len(g.defs))
+	for i, d := range g.defs {
+		names[i] = d.name
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,79 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type base struct {
+	ID string `json:"id"`
+}
+
+type node struct {
+	base
+	Name     string            `json:"name,omitempty"`
+	Created  time.Time         `json:"created"`
+	Raw      json.RawMessage   `json:"raw"`
+	Tags     map[string]int    `json:"tags,omitempty"`
+	Children []*node           `json:"children"`
+	Parent   *node             `json:"parent,omitempty"`
+	Data     []byte            `json:"data,omitempty"`
+	Any      interface{}       `json:"any,omitempty"`
+	Skipped  string            `json:"-"`
+	hidden   string            //nolint
+	Dash     string            `json:"-,"`
+	Attrs    map[string]string `json:"attrs"`
+}
+
+func TestGenerator(t *testing.T) {
+	g := New()
+	if name := g.Add(&node{}); name != "node" {
+		t.Fatalf("got name %q", name)
+	}
+	s := g.Schema("test", "node")
+	def := s["$defs"].(map[string]interface{})["node"].(map[string]interface{})
+	props := def["properties"].(map[string]interface{})
+	var names []string
+	for name := range props {
+		names = append(names, name)
+	}
+	if len(names) != 11 || props["Skipped"] != nil || props["hidden"] != nil || props["-"] == nil {
+		t.Errorf("got properties %q", names)
+	}
+	if got := def["required"]; !reflect.DeepEqual(got, []string{"id", "created", "raw", "children", "-", "attrs"}) {
+		t.Errorf("got required %q", got)
+	}
+	want := map[string]string{
+		"created":  `{"format":"date-time","type":"string"}`,
+		"raw":      `{}`,
+		"children": `{"items":{"$ref":"#/$defs/node"},"type":["array","null"]}`,
+		"parent":   `{"$ref":"#/$defs/node"}`,
+		"tags":     `{"additionalProperties":{"type":"integer"},"type":"object"}`,
+		"data":     `{"contentEncoding":"base64","type":"string"}`,
+		"any":      `{}`,
+	}
+	for name, w := range want {
+		b, _ := json.Marshal(props[name])
+		if string(b) != w {
+			t.Errorf("%v has schema %s, want %s", name, b, w)
+		}
+	}
+
+	ts := string(g.TypeScript())
+	for _, line := range []string{
+		"  id: string;\n",
+		"  name?: string;\n",
+		"  children: node[] | null;\n",
+		"  parent?: node;\n",
+		"  tags?: { [key: string]: number };\n",
+		"  raw: unknown;\n",
+		`  "-": string;` + "\n",
+	} {
+		if !strings.Contains(ts, line) {
+			t.Errorf("TypeScript lacks %q:\n%s", line, ts)
+		}
+	}
+}
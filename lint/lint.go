@@ -0,0 +1,221 @@
+// Package lint checks the pages of a workspace against rules, such as
+// "every page has an icon" or "code blocks declare their language".
+//
+// Rules are either built from a Config, typically read from a YAML file,
+// or written in Go as a Rule with a custom Check.
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// Severities of rules.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Scopes of rules.
+const (
+	// ScopePages restricts a rule to pages that aren't database rows.
+	ScopePages = "pages"
+	// ScopeRows restricts a rule to database rows.
+	ScopeRows = "rows"
+)
+
+// Problem is something wrong with a page found by a Check.
+type Problem struct {
+	// BlockID is the id of the offending block, if not the page itself.
+	BlockID string
+	Message string
+}
+
+// Check returns the problems of page p.
+type Check func(p *notion.Page) []Problem
+
+// Rule is a named Check.
+type Rule struct {
+	Name string
+	// Severity is SeverityError (the default) or SeverityWarning.
+	Severity string
+	// Scope, if set, restricts the rule to ScopePages or ScopeRows.
+	Scope string
+	// Databases, if non-empty, restricts the rule to the rows of the
+	// databases (collections) with the given ids.
+	Databases []string
+	Check     Check
+}
+
+// applies reports whether r checks page p.
+func (r *Rule) applies(p *notion.Page) bool {
+	row := p.ParentTable == notiontypes.TableCollection
+	switch r.Scope {
+	case ScopePages:
+		if row {
+			return false
+		}
+	case ScopeRows:
+		if !row {
+			return false
+		}
+	}
+	if len(r.Databases) > 0 && !(row && contains(r.Databases, p.ParentID)) {
+		return false
+	}
+	return true
+}
+
+// Issue is a problem found by a rule.
+type Issue struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	PageID   string `json:"page_id"`
+	Page     string `json:"page"`
+	BlockID  string `json:"block_id,omitempty"`
+	Message  string `json:"message"`
+}
+
+// Report lists the issues found in a set of pages.
+type Report struct {
+	// Pages is the number of pages checked.
+	Pages int `json:"pages"`
+	// Issues are in crawl order, then in rule order.
+	Issues []*Issue `json:"issues"`
+	// Skipped are the pages and blocks that couldn't be read, and weren't
+	// checked.
+	Skipped []*notion.RestrictedBlock `json:"skipped,omitempty"`
+}
+
+// Errors returns the number of issues with SeverityError.
+func (r *Report) Errors() int {
+	n := 0
+	for _, i := range r.Issues {
+		if i.Severity == SeverityError {
+			n++
+		}
+	}
+	return n
+}
+
+// CheckPage checks p against rules, adding the issues found to r.
+func (r *Report) CheckPage(p *notion.Page, rules []*Rule) {
+	r.Pages++
+	for _, rule := range rules {
+		if !rule.applies(p) {
+			continue
+		}
+		severity := rule.Severity
+		if severity == "" {
+			severity = SeverityError
+		}
+		for _, problem := range rule.Check(p) {
+			r.Issues = append(r.Issues, &Issue{
+				Rule: rule.Name, Severity: severity,
+				PageID: p.ID, Page: p.Title, BlockID: problem.BlockID,
+				Message: problem.Message,
+			})
+		}
+	}
+}
+
+// Pages crawls the page rootID and its sub-pages, selected by filter, and
+// checks them against rules. Pages and blocks that can't be read are
+// skipped and listed in the report.
+func Pages(c *notion.Client, rootID string, rules []*Rule, filter *notion.Filter) (*Report, error) {
+	r := &Report{}
+	if err := r.crawl(c, rootID, rules, filter); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Database checks the rows of the database (collection) collectionID, and
+// their sub-pages, against rules.
+func Database(c *notion.Client, collectionID string, rules []*Rule, filter *notion.Filter) (*Report, error) {
+	rows, err := c.QueryCollection(collectionID, "")
+	if err != nil {
+		return nil, errors.Wrapf(err, "querying database %v", collectionID)
+	}
+	r := &Report{}
+	for _, row := range rows {
+		if err := r.crawl(c, row.ID, rules, filter); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func (r *Report) crawl(c *notion.Client, rootID string, rules []*Rule, filter *notion.Filter) error {
+	res, err := c.CrawlWithOptions(rootID, notion.CrawlOptions{Filter: filter}, func(p *notion.Page, ancestors []string) error {
+		r.CheckPage(p, rules)
+		return nil
+	})
+	if err != nil {
+		return errors.Wrapf(err, "crawling %v", rootID)
+	}
+	r.Skipped = append(r.Skipped, res.Skipped...)
+	return nil
+}
+
+// Merge adds the pages and issues of other to r.
+func (r *Report) Merge(other *Report) {
+	r.Pages += other.Pages
+	r.Issues = append(r.Issues, other.Issues...)
+	r.Skipped = append(r.Skipped, other.Skipped...)
+}
+
+// WriteText writes the issues one per line, followed by a summary.
+func (r *Report) WriteText(w io.Writer) error {
+	for _, i := range r.Issues {
+		title := i.Page
+		if title == "" {
+			title = "Untitled"
+		}
+		where := i.PageID
+		if i.BlockID != "" {
+			where += "#" + i.BlockID
+		}
+		fmt.Fprintf(w, "%s: %s (%s): %s [%s]\n", i.Severity, title, where, i.Message, i.Rule)
+	}
+	counts := make(map[string]int)
+	for _, i := range r.Issues {
+		counts[i.Severity]++
+	}
+	var summary []string
+	for s := range counts {
+		summary = append(summary, s)
+	}
+	sort.Strings(summary)
+	_, err := fmt.Fprintf(w, "%d pages checked, %d issues", r.Pages, len(r.Issues))
+	for _, s := range summary {
+		fmt.Fprintf(w, ", %d %ss", counts[s], s)
+	}
+	if len(r.Skipped) > 0 {
+		fmt.Fprintf(w, ", %d unreadable pages or blocks skipped", len(r.Skipped))
+	}
+	fmt.Fprintln(w)
+	return err
+}
+
+// WriteJSON writes the report as JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
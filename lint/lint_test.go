@@ -0,0 +1,115 @@
+package lint
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tmc/notion/notiontest"
+	"github.com/tmc/notion/notiontypes"
+)
+
+const (
+	pageID    = "4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+	h1ID      = "5b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+	h1bID     = "6b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+	codeID    = "7b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+	subPageID = "8b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+	dbID      = "9b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+	rowID     = "ab1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+	row2ID    = "bb1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+)
+
+func title(s string) map[string]interface{} {
+	return map[string]interface{}{"title": [][]string{{s}}}
+}
+
+func TestLint(t *testing.T) {
+	srv := notiontest.NewServer()
+	defer srv.Close()
+	srv.AddBlock(&notiontypes.Block{
+		ID: pageID, Type: notiontypes.BlockPage, Properties: title("Handbook"),
+		FormatRaw: []byte(`{"page_icon":"📘"}`),
+		Content: []*notiontypes.Block{
+			{ID: h1ID, Type: notiontypes.BlockHeader, Properties: title("One")},
+			{ID: h1bID, Type: notiontypes.BlockHeader, Properties: title("Two")},
+			{ID: codeID, Type: notiontypes.BlockCode, Properties: title("ls")},
+			{ID: subPageID, Type: notiontypes.BlockPage, Properties: title("Sub")},
+		},
+	})
+	srv.AddRecord(notiontypes.TableCollection, dbID, map[string]interface{}{
+		"schema": map[string]interface{}{
+			"title": map[string]string{"name": "Name", "type": "title"},
+			"ow":    map[string]string{"name": "Owner", "type": "text"},
+		},
+	})
+	for _, r := range []struct{ id, owner string }{{rowID, "Ann"}, {row2ID, ""}} {
+		props := title("Task " + r.id[:1])
+		if r.owner != "" {
+			props["ow"] = [][]string{{r.owner}}
+		}
+		srv.AddBlock(&notiontypes.Block{ID: r.id, Type: notiontypes.BlockPage, ParentID: dbID, ParentTable: notiontypes.TableCollection, Properties: props, FormatRaw: []byte(`{"page_icon":"✅"}`)})
+	}
+
+	cfg := &Config{Rules: []*RuleConfig{
+		{Check: "icon"},
+		{Name: "single-h1", Check: "max-blocks", Type: notiontypes.BlockHeader, Max: 1},
+		{Check: "code-language", Severity: SeverityWarning},
+		{Check: "required-property", Property: "Owner"},
+	}}
+	rules, err := cfg.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := srv.Client()
+	r, err := Pages(c, pageID, rules, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := Database(c, dbID, rules, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Merge(rows)
+
+	var got []string
+	for _, i := range r.Issues {
+		got = append(got, i.Rule+" "+i.Page+" "+i.BlockID)
+	}
+	want := []string{
+		"single-h1 Handbook " + h1bID,
+		"code-language Handbook " + codeID,
+		"icon Sub ",
+		"required-property Task b ",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got issues %q, want %q", got, want)
+	}
+	if r.Pages != 4 || r.Errors() != 3 {
+		t.Errorf("got %d pages and %d errors, want 4 and 3", r.Pages, r.Errors())
+	}
+	var buf bytes.Buffer
+	r.WriteText(&buf)
+	if !strings.HasSuffix(buf.String(), "4 pages checked, 4 issues, 3 errors, 1 warnings\n") {
+		t.Errorf("got text report\n%s", buf.String())
+	}
+}
+
+func TestConfigErrors(t *testing.T) {
+	for _, rc := range []*RuleConfig{
+		{Check: "nope"},
+		{Check: "max-blocks"},
+		{Check: "required-property"},
+		{Check: "title-pattern", Pattern: "("},
+		{Check: "icon", Severity: "fatal"},
+	} {
+		if _, err := rc.Rule(); err == nil {
+			t.Errorf("%+v: got no error", rc)
+		}
+	}
+	cfg := &Config{Rules: []*RuleConfig{{Check: "icon"}, {Check: "icon"}}}
+	if _, err := cfg.Compile(); err == nil {
+		t.Error("duplicate rules: got no error")
+	}
+}
@@ -0,0 +1,273 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// Config describes rules, e.g. in YAML:
+//
+//	rules:
+//	  - name: page-icon
+//	    check: icon
+//	  - name: single-h1
+//	    check: max-blocks
+//	    type: header
+//	    max: 1
+//	  - name: code-language
+//	    check: code-language
+//	    severity: warning
+//	  - name: row-owner
+//	    check: required-property
+//	    property: Owner
+type Config struct {
+	Rules []*RuleConfig `json:"rules" yaml:"rules"`
+}
+
+// RuleConfig configures one of the built-in checks:
+//
+//   - icon: the page has an icon.
+//   - cover: the page has a cover.
+//   - max-blocks: the page has at most Max blocks of Type.
+//   - min-blocks: the page has at least Min blocks of Type.
+//   - forbidden-block: the page has no block of Type.
+//   - code-language: code blocks declare a language, one of Languages if set.
+//   - required-property: database rows have a non-empty Property.
+//   - title-pattern: the title of the page matches Pattern.
+//
+// Blocks within sub-pages don't count towards those of a page.
+type RuleConfig struct {
+	// Name defaults to Check.
+	Name      string   `json:"name" yaml:"name"`
+	Check     string   `json:"check" yaml:"check"`
+	Severity  string   `json:"severity" yaml:"severity"`
+	Scope     string   `json:"scope" yaml:"scope"`
+	Databases []string `json:"databases" yaml:"databases"`
+	// Message replaces the message of the problems found.
+	Message   string   `json:"message" yaml:"message"`
+	Type      string   `json:"type" yaml:"type"`
+	Max       int      `json:"max" yaml:"max"`
+	Min       int      `json:"min" yaml:"min"`
+	Languages []string `json:"languages" yaml:"languages"`
+	Property  string   `json:"property" yaml:"property"`
+	Pattern   string   `json:"pattern" yaml:"pattern"`
+}
+
+// Compile returns the rules configured by c.
+func (c *Config) Compile() ([]*Rule, error) {
+	var rules []*Rule
+	names := make(map[string]bool)
+	for _, rc := range c.Rules {
+		r, err := rc.Rule()
+		if err != nil {
+			return nil, err
+		}
+		if names[r.Name] {
+			return nil, fmt.Errorf("lint: duplicate rule %q", r.Name)
+		}
+		names[r.Name] = true
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// Rule returns the rule configured by c.
+func (c *RuleConfig) Rule() (*Rule, error) {
+	name := c.Name
+	if name == "" {
+		name = c.Check
+	}
+	r := &Rule{Name: name, Severity: c.Severity, Scope: c.Scope, Databases: c.Databases}
+	switch c.Severity {
+	case "", SeverityError, SeverityWarning:
+	default:
+		return nil, fmt.Errorf("lint: rule %v: unknown severity %q", name, c.Severity)
+	}
+	switch c.Scope {
+	case "", ScopePages, ScopeRows:
+	default:
+		return nil, fmt.Errorf("lint: rule %v: unknown scope %q", name, c.Scope)
+	}
+	needType := func() error {
+		if c.Type == "" {
+			return fmt.Errorf("lint: rule %v: %v needs a block type", name, c.Check)
+		}
+		return nil
+	}
+	switch c.Check {
+	case "icon":
+		r.Check = HasIcon
+	case "cover":
+		r.Check = HasCover
+	case "max-blocks":
+		if err := needType(); err != nil {
+			return nil, err
+		}
+		r.Check = MaxBlocks(c.Type, c.Max)
+	case "min-blocks":
+		if err := needType(); err != nil {
+			return nil, err
+		}
+		r.Check = MinBlocks(c.Type, c.Min)
+	case "forbidden-block":
+		if err := needType(); err != nil {
+			return nil, err
+		}
+		r.Check = MaxBlocks(c.Type, 0)
+	case "code-language":
+		r.Check = CodeLanguage(c.Languages...)
+	case "required-property":
+		if c.Property == "" {
+			return nil, fmt.Errorf("lint: rule %v: required-property needs a property", name)
+		}
+		r.Check = RequiredProperty(c.Property)
+		if r.Scope == "" {
+			r.Scope = ScopeRows
+		}
+	case "title-pattern":
+		re, err := regexp.Compile(c.Pattern)
+		if err != nil {
+			return nil, errors.Wrapf(err, "lint: rule %v", name)
+		}
+		r.Check = TitlePattern(re)
+	default:
+		return nil, fmt.Errorf("lint: rule %v: unknown check %q", name, c.Check)
+	}
+	if c.Message != "" {
+		check := r.Check
+		r.Check = func(p *notion.Page) []Problem {
+			problems := check(p)
+			for i := range problems {
+				problems[i].Message = c.Message
+			}
+			return problems
+		}
+	}
+	return r, nil
+}
+
+// HasIcon reports pages without an icon.
+func HasIcon(p *notion.Page) []Problem {
+	if p.FormatPage == nil || p.FormatPage.PageIcon == "" {
+		return []Problem{{Message: "page has no icon"}}
+	}
+	return nil
+}
+
+// HasCover reports pages without a cover.
+func HasCover(p *notion.Page) []Problem {
+	if p.FormatPage == nil || p.FormatPage.PageCover == "" {
+		return []Problem{{Message: "page has no cover"}}
+	}
+	return nil
+}
+
+// MaxBlocks returns a Check that reports the blocks of type typ past the
+// first max.
+func MaxBlocks(typ string, max int) Check {
+	return func(p *notion.Page) []Problem {
+		var problems []Problem
+		n := 0
+		walk(p.Block, func(b *notiontypes.Block) {
+			if b.Type != typ {
+				return
+			}
+			if n++; n > max {
+				msg := fmt.Sprintf("more than %d %v blocks", max, typ)
+				if max == 0 {
+					msg = fmt.Sprintf("%v blocks are not allowed", typ)
+				}
+				problems = append(problems, Problem{BlockID: b.ID, Message: msg})
+			}
+		})
+		return problems
+	}
+}
+
+// MinBlocks returns a Check that reports pages with less than min blocks of
+// type typ.
+func MinBlocks(typ string, min int) Check {
+	return func(p *notion.Page) []Problem {
+		n := 0
+		walk(p.Block, func(b *notiontypes.Block) {
+			if b.Type == typ {
+				n++
+			}
+		})
+		if n < min {
+			return []Problem{{Message: fmt.Sprintf("%d %v blocks, want at least %d", n, typ, min)}}
+		}
+		return nil
+	}
+}
+
+// CodeLanguage returns a Check that reports code blocks without a language
+// or, if languages are given, with a language not among them (ignoring
+// case).
+func CodeLanguage(languages ...string) Check {
+	return func(p *notion.Page) []Problem {
+		var problems []Problem
+		walk(p.Block, func(b *notiontypes.Block) {
+			if !b.IsCode() {
+				return
+			}
+			lang := b.CodeLanguage
+			switch {
+			case lang == "":
+				problems = append(problems, Problem{BlockID: b.ID, Message: "code block has no language"})
+			case len(languages) > 0 && !containsFold(languages, lang):
+				problems = append(problems, Problem{BlockID: b.ID, Message: fmt.Sprintf("code block language %q is not allowed", lang)})
+			}
+		})
+		return problems
+	}
+}
+
+// RequiredProperty returns a Check that reports database rows whose property
+// name is empty.
+func RequiredProperty(name string) Check {
+	return func(p *notion.Page) []Problem {
+		for _, prop := range p.PageProperties {
+			if prop.Name == name && strings.TrimSpace(prop.Text()) != "" {
+				return nil
+			}
+		}
+		return []Problem{{Message: fmt.Sprintf("property %v is empty", name)}}
+	}
+}
+
+// TitlePattern returns a Check that reports pages whose title doesn't match
+// re.
+func TitlePattern(re *regexp.Regexp) Check {
+	return func(p *notion.Page) []Problem {
+		if !re.MatchString(p.Title) {
+			return []Problem{{Message: fmt.Sprintf("title doesn't match %v", re)}}
+		}
+		return nil
+	}
+}
+
+// walk calls fn for the blocks within page, but not within its sub-pages.
+func walk(page *notiontypes.Block, fn func(b *notiontypes.Block)) {
+	for _, b := range page.Content {
+		if b.IsPage() {
+			continue
+		}
+		fn(b)
+		walk(b, fn)
+	}
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -27,5 +27,5 @@ func (wl WrapLogrus) WithField(key string, value interface{}) Logger {
 
 // WithError attaches a key-value pair to a log line.
 func (wl WrapLogrus) WithError(err error) Logger {
-	return wl.WithError(err)
+	return &WrapLogrus{wl.FieldLogger.WithError(err)}
 }
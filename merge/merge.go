@@ -0,0 +1,118 @@
+// Package merge combines two versions of a page, such as accidentally
+// duplicated meeting notes that were edited separately, into one.
+package merge
+
+import (
+	"github.com/tmc/notion/diff"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// DefaultThreshold is the text similarity at which Merge considers blocks
+// of the two pages to be versions of the same block. See diff.Similarity.
+const DefaultThreshold = 0.5
+
+// Resolution selects the version(s) of a conflicting block to keep.
+type Resolution int
+
+const (
+	// KeepA keeps the block of the first page.
+	KeepA Resolution = iota
+	// KeepB keeps the block of the second page.
+	KeepB
+	// KeepBoth keeps both blocks, the one of the first page first.
+	KeepBoth
+)
+
+// Conflict is a block that is in both pages with different content.
+type Conflict struct {
+	A, B *notiontypes.Block
+	// Resolution is how the conflict is resolved in the merged page. It
+	// defaults to KeepA.
+	Resolution Resolution
+}
+
+// Result is a merge of two pages.
+type Result struct {
+	Conflicts []*Conflict
+
+	root      *diff.Node
+	conflicts map[*diff.Node]*Conflict
+}
+
+// Merge merges the content of the pages a and b, aligning their blocks by
+// id or, with a threshold below 1, by text similarity. Blocks that are only
+// in one of the pages are kept. Blocks of both pages that differ are
+// conflicts, to be resolved before calling Blocks.
+func Merge(a, b *notiontypes.Block, threshold float64) *Result {
+	r := &Result{
+		root:      diff.CompareSimilar(a, b, threshold),
+		conflicts: make(map[*diff.Node]*Conflict),
+	}
+	for _, c := range r.root.Children {
+		r.findConflicts(c)
+	}
+	return r
+}
+
+func (r *Result) findConflicts(n *diff.Node) {
+	if n.Op == diff.Edited {
+		c := &Conflict{A: n.Old, B: n.New}
+		r.conflicts[n] = c
+		r.Conflicts = append(r.Conflicts, c)
+	}
+	for _, c := range n.Children {
+		r.findConflicts(c)
+	}
+}
+
+// Blocks returns the content of the merged page, with conflicts resolved
+// as set in their Resolution. The blocks are copies without ids, so they
+// can be passed to notion.Client.CreatePage.
+func (r *Result) Blocks() []*notiontypes.Block {
+	var res []*notiontypes.Block
+	for _, c := range r.root.Children {
+		res = append(res, r.blocks(c)...)
+	}
+	return res
+}
+
+func (r *Result) blocks(n *diff.Node) []*notiontypes.Block {
+	switch n.Op {
+	case diff.Added:
+		return []*notiontypes.Block{copyTree(n.New)}
+	case diff.Removed:
+		return []*notiontypes.Block{copyTree(n.Old)}
+	}
+	var children []*notiontypes.Block
+	for _, c := range n.Children {
+		children = append(children, r.blocks(c)...)
+	}
+	c, ok := r.conflicts[n]
+	if !ok {
+		return []*notiontypes.Block{copyBlock(n.Old, children)}
+	}
+	switch c.Resolution {
+	case KeepB:
+		return []*notiontypes.Block{copyBlock(n.New, children)}
+	case KeepBoth:
+		return []*notiontypes.Block{copyBlock(n.Old, children), copyBlock(n.New, nil)}
+	}
+	return []*notiontypes.Block{copyBlock(n.Old, children)}
+}
+
+func copyTree(b *notiontypes.Block) *notiontypes.Block {
+	var children []*notiontypes.Block
+	for _, c := range b.Content {
+		children = append(children, copyTree(c))
+	}
+	return copyBlock(b, children)
+}
+
+func copyBlock(b *notiontypes.Block, children []*notiontypes.Block) *notiontypes.Block {
+	c := *b
+	c.ID = ""
+	c.ParentID = ""
+	c.ContentIDs = nil
+	c.Content = children
+	return &c
+}
@@ -0,0 +1,50 @@
+package merge
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tmc/notion/diff"
+	"github.com/tmc/notion/notiontypes"
+)
+
+func page(id string, texts ...string) *notiontypes.Block {
+	p := &notiontypes.Block{ID: id, Type: notiontypes.BlockPage, Title: "Meeting notes"}
+	for i, text := range texts {
+		p.Content = append(p.Content, &notiontypes.Block{
+			ID:            id + "-" + string(rune('a'+i)),
+			Type:          notiontypes.BlockText,
+			InlineContent: []*notiontypes.InlineBlock{{Text: text}},
+		})
+	}
+	return p
+}
+
+func texts(blocks []*notiontypes.Block) []string {
+	var res []string
+	for _, b := range blocks {
+		if b.ID != "" {
+			return []string{"block has id " + b.ID}
+		}
+		res = append(res, diff.Text(b))
+	}
+	return res
+}
+
+func TestMerge(t *testing.T) {
+	a := page("a", "Attendees: Ann, Bob", "Budget is approved", "Ann to send the agenda")
+	b := page("b", "Attendees: Ann, Bob, Cleo", "Budget is approved", "Cleo joins the team", "Bob books the room")
+	r := Merge(a, b, DefaultThreshold)
+	if len(r.Conflicts) != 1 || diff.Text(r.Conflicts[0].A) != "Attendees: Ann, Bob" {
+		t.Fatalf("got conflicts %+v", r.Conflicts)
+	}
+	want := []string{"Attendees: Ann, Bob", "Budget is approved", "Ann to send the agenda", "Cleo joins the team", "Bob books the room"}
+	if got := texts(r.Blocks()); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	r.Conflicts[0].Resolution = KeepB
+	want[0] = "Attendees: Ann, Bob, Cleo"
+	if got := texts(r.Blocks()); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
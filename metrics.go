@@ -0,0 +1,21 @@
+package notion
+
+import "time"
+
+// Metrics receives measurements from a Client, e.g. to export them to a
+// monitoring system. Implementations must be safe for concurrent use.
+type Metrics interface {
+	// APICall is called after every API call with the endpoint, the status
+	// code (0 if no response was received) and the duration of the call.
+	APICall(endpoint string, status int, d time.Duration)
+	// BreakerStateChanged is called when the client's circuit breaker
+	// changes its state.
+	BreakerStateChanged(state BreakerState)
+}
+
+// WithMetrics makes the client report measurements to m.
+func WithMetrics(m Metrics) ClientOption {
+	return func(c *Client) {
+		c.metrics = m
+	}
+}
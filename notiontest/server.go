@@ -0,0 +1,802 @@
+// Package notiontest provides a fake notion server for tests of code built
+// on the notion client.
+//
+// The server keeps records (blocks, collections, users, ...) in memory. It is
+// seeded with AddBlock, AddRecord or AddRecordMap, serves the endpoints used
+// by the client from them and applies submitted transactions to them, so that
+// tests can assert both on the operations sent and on the resulting records.
+package notiontest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// Operation is an operation of a submitted transaction.
+type Operation struct {
+	ID      string          `json:"id"`
+	Table   string          `json:"table"`
+	Path    []string        `json:"path"`
+	Command string          `json:"command"`
+	Args    json.RawMessage `json:"args"`
+}
+
+// Server is a fake notion API server.
+type Server struct {
+	*httptest.Server
+
+	// Token, if set, is the only token accepted by the server.
+	Token string
+	// Latency, if set, delays the responses to API requests, e.g. to
+	// measure the effect of concurrent requests.
+	Latency time.Duration
+
+	mu           sync.Mutex
+	records      map[string]map[string]map[string]interface{}
+	transactions [][]*Operation
+	failures     map[string]int
+	userID       string
+	snapshots    map[string][]*snapshot
+	files        map[string][]byte
+	roles        map[string]string
+}
+
+// snapshot is a version of a page, with the records of its content.
+type snapshot struct {
+	*notiontypes.Snapshot
+	records recordMap
+}
+
+// NewServer starts a server without records. It must be closed with Close.
+func NewServer() *Server {
+	s := &Server{
+		records:   make(map[string]map[string]map[string]interface{}),
+		failures:  make(map[string]int),
+		snapshots: make(map[string][]*snapshot),
+		files:     make(map[string][]byte),
+		roles:     make(map[string]string),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Client returns a client of the server, with opts applied after the ones
+// pointing it to the server.
+func (s *Server) Client(opts ...notion.ClientOption) *notion.Client {
+	opts = append([]notion.ClientOption{
+		notion.WithBaseURL(s.URL + "/api/v3/"),
+		notion.WithToken(s.Token),
+		notion.WithHTTPClient(s.Server.Client()),
+	}, opts...)
+	c, err := notion.NewClient(opts...)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// AddRecord stores value, which must marshal to a JSON object, as the
+// record id of table.
+func (s *Server) AddRecord(table, id string, value interface{}) error {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	var record map[string]interface{}
+	if err := json.Unmarshal(b, &record); err != nil {
+		return fmt.Errorf("notiontest: record %v is not an object: %v", id, err)
+	}
+	record["id"] = id
+	s.mu.Lock()
+	s.put(table, id, record)
+	s.mu.Unlock()
+	return nil
+}
+
+// AddRecordMap stores all records of a loadPageChunk style record map, such
+// as the fixtures of the corpus package.
+func (s *Server) AddRecordMap(recordMap []byte) error {
+	var rm map[string]map[string]struct {
+		Value map[string]interface{} `json:"value"`
+	}
+	if err := json.Unmarshal(recordMap, &rm); err != nil {
+		return fmt.Errorf("notiontest: invalid record map: %v", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for table, records := range rm {
+		for id, r := range records {
+			if r.Value != nil {
+				s.put(table, id, r.Value)
+			}
+		}
+	}
+	return nil
+}
+
+// AddBlock stores b and, recursively, its Content. Only the fields sent by
+// the notion API are stored, i.e. Properties and FormatRaw but not the fields
+// the client derives from them. The blocks must have an ID.
+func (s *Server) AddBlock(b *notiontypes.Block) {
+	record := map[string]interface{}{
+		"id":               b.ID,
+		"type":             b.Type,
+		"version":          b.Version,
+		"alive":            true,
+		"parent_id":        b.ParentID,
+		"parent_table":     b.ParentTable,
+		"created_time":     b.CreatedTime,
+		"created_by":       b.CreatedBy,
+		"last_edited_time": b.LastEditedTime,
+		"last_edited_by":   b.LastEditedBy,
+	}
+	if b.SpaceID != "" {
+		record["space_id"] = b.SpaceID
+	}
+	if b.CollectionID != "" {
+		record["collection_id"] = b.CollectionID
+	}
+	if len(b.ViewIDs) > 0 {
+		record["view_ids"] = b.ViewIDs
+	}
+	if len(b.Properties) > 0 {
+		record["properties"] = b.Properties
+	}
+	if len(b.FormatRaw) > 0 {
+		var format interface{}
+		json.Unmarshal(b.FormatRaw, &format)
+		record["format"] = format
+	}
+	if b.Permissions != nil {
+		record["permissions"] = *b.Permissions
+	}
+	var content []interface{}
+	for _, child := range b.Content {
+		if child.ParentID == "" {
+			child.ParentID, child.ParentTable = b.ID, notiontypes.TableBlock
+		}
+		s.AddBlock(child)
+		content = append(content, child.ID)
+	}
+	if content == nil {
+		for _, id := range b.ContentIDs {
+			content = append(content, id)
+		}
+	}
+	if content != nil {
+		record["content"] = content
+	}
+	// round trip through JSON so that records only hold JSON values
+	s.AddRecord(notiontypes.TableBlock, b.ID, record)
+}
+
+// AddSnapshot adds snap, a version of the page with the id of page, to the
+// history of the page. page and its content are the version's blocks; they
+// aren't stored as current records.
+func (s *Server) AddSnapshot(snap *notiontypes.Snapshot, page *notiontypes.Block) {
+	tmp := &Server{records: make(map[string]map[string]map[string]interface{})}
+	tmp.AddBlock(page)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.snapshots[page.ID] = append(s.snapshots[page.ID], &snapshot{
+		Snapshot: snap,
+		records:  pageChunk(tmp.records, page.ID, nil),
+	})
+}
+
+// SetRole sets the role of the user on the block id, notiontypes.RoleEditor
+// by default. As in notion, blocks with notiontypes.RoleNone are returned
+// without their value, and so without their content.
+func (s *Server) SetRole(id, role string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.roles[id] = role
+}
+
+// File returns the content of the file uploaded to url, as returned by
+// Client.UploadFile, or nil if no file was uploaded to it.
+func (s *Server) File(url string) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.files[strings.TrimPrefix(url, s.URL)]
+}
+
+// SetUser makes the notion_user record id the user the server's clients
+// are authenticated as, returned by loadUserContent and getSpaces with all
+// space records. The subscription data of a space is the record with the
+// space's id in the (fake) table "subscription".
+func (s *Server) SetUser(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.userID = id
+}
+
+// Record returns a copy of the record id of table, or nil if it doesn't exist.
+func (s *Server) Record(table, id string) map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.records[table][id]
+	if !ok {
+		return nil
+	}
+	var copied map[string]interface{}
+	b, _ := json.Marshal(r)
+	json.Unmarshal(b, &copied)
+	return copied
+}
+
+// Block returns the block id as currently stored, with its properties
+// parsed and its content resolved, or nil if it doesn't exist.
+func (s *Server) Block(id string) *notiontypes.Block {
+	s.mu.Lock()
+	b, _ := json.Marshal(s.records[notiontypes.TableBlock])
+	s.mu.Unlock()
+	var blocks map[string]*notiontypes.Block
+	json.Unmarshal(b, &blocks)
+	block := blocks[id]
+	if block == nil {
+		return nil
+	}
+	if err := notiontypes.ResolveBlock(block, blocks); err != nil {
+		return nil
+	}
+	return block
+}
+
+// Transactions returns the operations of the transactions submitted so far,
+// one slice per transaction.
+func (s *Server) Transactions() [][]*Operation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([][]*Operation(nil), s.transactions...)
+}
+
+// Operations returns the operations of all transactions submitted so far.
+func (s *Server) Operations() []*Operation {
+	var ops []*Operation
+	for _, t := range s.Transactions() {
+		ops = append(ops, t...)
+	}
+	return ops
+}
+
+// Fail makes the next n requests to endpoint, e.g. "loadPageChunk", fail
+// with status code 500.
+func (s *Server) Fail(endpoint string, n int) {
+	s.mu.Lock()
+	s.failures[endpoint] += n
+	s.mu.Unlock()
+}
+
+func (s *Server) put(table, id string, record map[string]interface{}) {
+	if s.records[table] == nil {
+		s.records[table] = make(map[string]map[string]interface{})
+	}
+	s.records[table][id] = record
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "PUT" && strings.HasPrefix(r.URL.Path, "/files/") {
+		s.putFile(w, r)
+		return
+	}
+	endpoint := strings.TrimPrefix(r.URL.Path, "/api/v3/")
+	if r.Method != "POST" || endpoint == r.URL.Path {
+		http.NotFound(w, r)
+		return
+	}
+	if s.Token != "" {
+		if c, err := r.Cookie("token"); err != nil || c.Value != s.Token {
+			http.Error(w, `{"errorId":"unauthorized","name":"UnauthorizedError"}`, http.StatusUnauthorized)
+			return
+		}
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	time.Sleep(s.Latency)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failures[endpoint] > 0 {
+		s.failures[endpoint]--
+		http.Error(w, `{"errorId":"fake","name":"InternalServerError"}`, http.StatusInternalServerError)
+		return
+	}
+	var resp interface{}
+	switch endpoint {
+	case "loadPageChunk":
+		resp, err = s.loadPageChunk(body)
+	case "getRecordValues":
+		resp, err = s.getRecordValues(body)
+	case "submitTransaction":
+		resp, err = s.submitTransaction(body)
+	case "queryCollection":
+		resp, err = s.queryCollection(body)
+	case "getSignedFileUrls":
+		resp, err = s.getSignedFileURLs(body)
+	case "getActivityLog":
+		resp, err = s.getActivityLog(body)
+	case "getSnapshotsList":
+		resp, err = s.getSnapshotsList(body)
+	case "getSnapshotContents":
+		resp, err = s.getSnapshotContents(body)
+	case "getUploadFileUrl":
+		resp, err = s.getUploadFileURL(body)
+	case "loadUserContent":
+		resp, err = s.loadUserContent()
+	case "getSpaces":
+		resp, err = s.getSpaces()
+	case "getSubscriptionData":
+		resp, err = s.getSubscriptionData(body)
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf(`{"errorId":"fake","name":"ValidationError","message":%q}`, err), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+type recordWithRole struct {
+	Role  string                 `json:"role"`
+	Value map[string]interface{} `json:"value,omitempty"`
+}
+
+type recordMap map[string]map[string]recordWithRole
+
+func (rm recordMap) add(table, id string, record map[string]interface{}) {
+	rm.addWithRole(table, id, notiontypes.RoleEditor, record)
+}
+
+func (rm recordMap) addWithRole(table, id, role string, record map[string]interface{}) {
+	if rm[table] == nil {
+		rm[table] = make(map[string]recordWithRole)
+	}
+	rm[table][id] = recordWithRole{Role: role, Value: record}
+}
+
+// loadPageChunk returns the page with all of its content, but not the
+// content of its sub-pages, in a single chunk.
+func (s *Server) loadPageChunk(body []byte) (interface{}, error) {
+	var req struct {
+		PageID string `json:"pageId"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"recordMap": pageChunk(s.records, req.PageID, s.roles),
+		"cursor":    map[string]interface{}{"stack": []interface{}{}},
+	}, nil
+}
+
+// pageChunk returns the block records of the page pageID and its content,
+// but not the content of its sub-pages, with the given roles.
+func pageChunk(records map[string]map[string]map[string]interface{}, pageID string, roles map[string]string) recordMap {
+	rm := recordMap{}
+	var walk func(id string, root bool)
+	walk = func(id string, root bool) {
+		b, ok := records[notiontypes.TableBlock][id]
+		if !ok || rm[notiontypes.TableBlock][id].Role != "" {
+			return
+		}
+		role := roles[id]
+		if role == notiontypes.RoleNone {
+			rm.addWithRole(notiontypes.TableBlock, id, role, nil)
+			return
+		}
+		if role == "" {
+			role = notiontypes.RoleEditor
+		}
+		rm.addWithRole(notiontypes.TableBlock, id, role, b)
+		if !root && b["type"] == notiontypes.BlockPage {
+			return
+		}
+		for _, child := range stringList(b["content"]) {
+			walk(child, false)
+		}
+	}
+	walk(pageID, true)
+	return rm
+}
+
+func (s *Server) getRecordValues(body []byte) (interface{}, error) {
+	var req struct {
+		Requests []notion.Record `json:"requests"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, err
+	}
+	results := make([]interface{}, len(req.Requests))
+	for i, r := range req.Requests {
+		results[i] = map[string]interface{}{}
+		if v, ok := s.records[r.Table][r.ID]; ok {
+			switch role := s.roles[r.ID]; role {
+			case "":
+				results[i] = recordWithRole{Role: notiontypes.RoleEditor, Value: v}
+			case notiontypes.RoleNone:
+				results[i] = recordWithRole{Role: role}
+			default:
+				results[i] = recordWithRole{Role: role, Value: v}
+			}
+		}
+	}
+	return map[string]interface{}{"results": results}, nil
+}
+
+func (s *Server) submitTransaction(body []byte) (interface{}, error) {
+	var req struct {
+		Operations []*Operation `json:"operations"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, err
+	}
+	for _, op := range req.Operations {
+		if err := s.apply(op); err != nil {
+			return nil, err
+		}
+	}
+	s.transactions = append(s.transactions, req.Operations)
+	return map[string]interface{}{}, nil
+}
+
+// apply applies op to the stored records.
+func (s *Server) apply(op *Operation) error {
+	var args interface{}
+	if len(op.Args) > 0 {
+		if err := json.Unmarshal(op.Args, &args); err != nil {
+			return err
+		}
+	}
+	record, ok := s.records[op.Table][op.ID]
+	if !ok {
+		record = map[string]interface{}{"id": op.ID}
+		s.put(op.Table, op.ID, record)
+	}
+	if len(op.Path) == 0 {
+		switch op.Command {
+		case "set":
+			m, ok := args.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("set of record %v: args are not an object", op.ID)
+			}
+			m["id"] = op.ID
+			s.put(op.Table, op.ID, m)
+		case "update":
+			m, _ := args.(map[string]interface{})
+			for k, v := range m {
+				record[k] = v
+			}
+		default:
+			return fmt.Errorf("unsupported command %q without path", op.Command)
+		}
+		return nil
+	}
+	parent := record
+	for _, key := range op.Path[:len(op.Path)-1] {
+		next, ok := parent[key].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			parent[key] = next
+		}
+		parent = next
+	}
+	key := op.Path[len(op.Path)-1]
+	switch op.Command {
+	case "set":
+		parent[key] = args
+	case "update":
+		m, ok := parent[key].(map[string]interface{})
+		if !ok {
+			m = make(map[string]interface{})
+			parent[key] = m
+		}
+		update, _ := args.(map[string]interface{})
+		for k, v := range update {
+			m[k] = v
+		}
+	case "setPermissionItem":
+		p, _ := args.(map[string]interface{})
+		list, _ := parent[key].([]interface{})
+		var kept []interface{}
+		for _, v := range list {
+			q, _ := v.(map[string]interface{})
+			if q["type"] != p["type"] || q["user_id"] != p["user_id"] || q["group_id"] != p["group_id"] {
+				kept = append(kept, v)
+			}
+		}
+		if p["role"] != notiontypes.RoleNone {
+			kept = append(kept, p)
+		}
+		parent[key] = kept
+	case "listAfter", "listBefore", "listRemove":
+		m, _ := args.(map[string]interface{})
+		id, _ := m["id"].(string)
+		list := remove(stringList(parent[key]), id)
+		switch op.Command {
+		case "listAfter":
+			i := len(list)
+			if after, _ := m["after"].(string); after != "" && index(list, after) >= 0 {
+				i = index(list, after) + 1
+			}
+			list = insert(list, id, i)
+		case "listBefore":
+			i := 0
+			if before, _ := m["before"].(string); before != "" && index(list, before) >= 0 {
+				i = index(list, before)
+			}
+			list = insert(list, id, i)
+		}
+		values := make([]interface{}, len(list))
+		for i, id := range list {
+			values[i] = id
+		}
+		parent[key] = values
+	default:
+		return fmt.Errorf("unsupported command %q", op.Command)
+	}
+	return nil
+}
+
+// queryCollection returns the live pages of the collection, ordered by
+// creation time, within the loader's offset and limit.
+func (s *Server) queryCollection(body []byte) (interface{}, error) {
+	var req struct {
+		CollectionID string `json:"collectionId"`
+		Loader       struct {
+			Limit  int `json:"limit"`
+			Offset int `json:"offset"`
+		} `json:"loader"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, err
+	}
+	rm := recordMap{}
+	var rows []map[string]interface{}
+	for _, b := range s.records[notiontypes.TableBlock] {
+		if b["parent_id"] == req.CollectionID && b["parent_table"] == notiontypes.TableCollection && b["alive"] != false {
+			rows = append(rows, b)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		ti, _ := rows[i]["created_time"].(float64)
+		tj, _ := rows[j]["created_time"].(float64)
+		if ti != tj {
+			return ti < tj
+		}
+		return rows[i]["id"].(string) < rows[j]["id"].(string)
+	})
+	total := len(rows)
+	if req.Loader.Offset < len(rows) {
+		rows = rows[req.Loader.Offset:]
+	} else {
+		rows = nil
+	}
+	if req.Loader.Limit > 0 && req.Loader.Limit < len(rows) {
+		rows = rows[:req.Loader.Limit]
+	}
+	ids := make([]string, len(rows))
+	for i, r := range rows {
+		ids[i] = r["id"].(string)
+		rm.add(notiontypes.TableBlock, ids[i], r)
+	}
+	if c, ok := s.records[notiontypes.TableCollection][req.CollectionID]; ok {
+		rm.add(notiontypes.TableCollection, req.CollectionID, c)
+	}
+	return map[string]interface{}{
+		"result":    map[string]interface{}{"blockIds": ids, "total": total},
+		"recordMap": rm,
+	}, nil
+}
+
+// getSignedFileURLs "signs" urls by adding a query parameter.
+func (s *Server) getSignedFileURLs(body []byte) (interface{}, error) {
+	var req struct {
+		URLs []struct {
+			URL string `json:"url"`
+		} `json:"urls"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, err
+	}
+	urls := make([]string, len(req.URLs))
+	for i, u := range req.URLs {
+		sep := "?"
+		if strings.Contains(u.URL, "?") {
+			sep = "&"
+		}
+		urls[i] = u.URL + sep + "signature=notiontest"
+	}
+	return map[string]interface{}{"signedUrls": urls}, nil
+}
+
+// getActivityLog returns all activity records of the space, most recent first.
+func (s *Server) getActivityLog(body []byte) (interface{}, error) {
+	var req struct {
+		SpaceID string `json:"spaceId"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, err
+	}
+	rm := recordMap{}
+	var activities []map[string]interface{}
+	for id, a := range s.records["activity"] {
+		if a["space_id"] == req.SpaceID {
+			rm.add("activity", id, a)
+			activities = append(activities, a)
+		}
+	}
+	sort.Slice(activities, func(i, j int) bool {
+		return fmt.Sprint(activities[i]["end_time"]) > fmt.Sprint(activities[j]["end_time"])
+	})
+	ids := make([]string, len(activities))
+	for i, a := range activities {
+		ids[i] = a["id"].(string)
+	}
+	return map[string]interface{}{"activityIds": ids, "recordMap": rm}, nil
+}
+
+// getSnapshotsList returns the snapshots of the page, most recent first.
+func (s *Server) getSnapshotsList(body []byte) (interface{}, error) {
+	var req struct {
+		BlockID string `json:"blockId"`
+		Size    int    `json:"size"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, err
+	}
+	snapshots := make([]*notiontypes.Snapshot, 0, len(s.snapshots[req.BlockID]))
+	for _, snap := range s.snapshots[req.BlockID] {
+		snapshots = append(snapshots, snap.Snapshot)
+	}
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[i].Timestamp > snapshots[j].Timestamp
+	})
+	if req.Size > 0 && len(snapshots) > req.Size {
+		snapshots = snapshots[:req.Size]
+	}
+	return map[string]interface{}{"snapshots": snapshots}, nil
+}
+
+func (s *Server) getSnapshotContents(body []byte) (interface{}, error) {
+	var req struct {
+		BlockID    string `json:"blockId"`
+		SnapshotID string `json:"snapshotId"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, err
+	}
+	for _, snap := range s.snapshots[req.BlockID] {
+		if snap.ID == req.SnapshotID {
+			return map[string]interface{}{"recordMap": snap.records}, nil
+		}
+	}
+	return nil, fmt.Errorf("no snapshot %v of block %v", req.SnapshotID, req.BlockID)
+}
+
+// getUploadFileURL returns URLs under /files/, to which files are PUT.
+func (s *Server) getUploadFileURL(body []byte) (interface{}, error) {
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%v/files/%d/%v", s.URL, len(s.files)+1, req.Name)
+	// reserve the path, so that the next upload gets another one
+	s.files[strings.TrimPrefix(url, s.URL)] = nil
+	return map[string]interface{}{
+		"url":          url,
+		"signedGetUrl": url + "?signature=notiontest",
+		"signedPutUrl": url + "?signature=notiontest",
+	}, nil
+}
+
+func (s *Server) putFile(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	time.Sleep(s.Latency)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.files[r.URL.Path]; !ok || r.URL.Query().Get("signature") == "" {
+		http.Error(w, "not a signed upload URL", http.StatusForbidden)
+		return
+	}
+	s.files[r.URL.Path] = body
+}
+
+func stringList(v interface{}) []string {
+	values, _ := v.([]interface{})
+	list := make([]string, 0, len(values))
+	for _, v := range values {
+		if s, ok := v.(string); ok {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+func index(list []string, s string) int {
+	for i, v := range list {
+		if v == s {
+			return i
+		}
+	}
+	return -1
+}
+
+func remove(list []string, s string) []string {
+	if i := index(list, s); i >= 0 {
+		return append(list[:i], list[i+1:]...)
+	}
+	return list
+}
+
+func insert(list []string, s string, i int) []string {
+	list = append(list, "")
+	copy(list[i+1:], list[i:])
+	list[i] = s
+	return list
+}
+
+func (s *Server) accountRecordMap() (recordMap, error) {
+	user, ok := s.records["notion_user"][s.userID]
+	if !ok {
+		return nil, fmt.Errorf("no user set")
+	}
+	rm := recordMap{}
+	rm.add("notion_user", s.userID, user)
+	for id, space := range s.records[notiontypes.TableSpace] {
+		rm.add(notiontypes.TableSpace, id, space)
+	}
+	return rm, nil
+}
+
+func (s *Server) loadUserContent() (interface{}, error) {
+	rm, err := s.accountRecordMap()
+	if err != nil {
+		return nil, err
+	}
+	rm.add("user_root", s.userID, map[string]interface{}{"id": s.userID})
+	return map[string]interface{}{"recordMap": rm}, nil
+}
+
+func (s *Server) getSpaces() (interface{}, error) {
+	rm, err := s.accountRecordMap()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{s.userID: rm}, nil
+}
+
+func (s *Server) getSubscriptionData(body []byte) (interface{}, error) {
+	var req struct {
+		SpaceID string `json:"spaceId"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, err
+	}
+	sub, ok := s.records["subscription"][req.SpaceID]
+	if !ok {
+		return nil, fmt.Errorf("space %v not found", req.SpaceID)
+	}
+	return sub, nil
+}
@@ -0,0 +1,67 @@
+package notiontest
+
+import (
+	"testing"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+const pageID = "4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+
+func TestServer(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.Token = "secret"
+	s.AddBlock(&notiontypes.Block{
+		ID:         pageID,
+		Type:       notiontypes.BlockPage,
+		Properties: map[string]interface{}{"title": [][]string{{"Home"}}},
+		Content: []*notiontypes.Block{
+			{ID: "text", Type: notiontypes.BlockText, Properties: map[string]interface{}{"title": [][]string{{"Hello"}}}},
+		},
+	})
+	c := s.Client()
+
+	page, err := c.GetPage(pageID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Title != "Home" || len(page.Content) != 1 || page.Content[0].InlineContent[0].Text != "Hello" {
+		t.Fatalf("unexpected page %q with %d blocks", page.Title, len(page.Content))
+	}
+
+	if err := c.AppendBlocks(pageID, &notiontypes.Block{Type: notiontypes.BlockDivider}); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(s.Transactions()); n != 1 {
+		t.Fatalf("got %d transactions, want 1", n)
+	}
+	ops := s.Operations()
+	if len(ops) != 2 || ops[0].Command != "set" || ops[1].Command != "listAfter" {
+		t.Fatalf("unexpected operations %+v", ops)
+	}
+	b := s.Block(pageID)
+	if len(b.Content) != 2 || b.Content[1].Type != notiontypes.BlockDivider {
+		t.Errorf("transaction not applied, page has %d blocks", len(b.Content))
+	}
+
+	s.Fail("loadPageChunk", 1)
+	if _, err := c.GetPage(pageID); err == nil {
+		t.Error("expected injected failure")
+	}
+	if _, err := s.Client().GetPage(pageID); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestServerRejectsToken(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.Token = "secret"
+	s.AddBlock(&notiontypes.Block{ID: pageID, Type: notiontypes.BlockPage})
+	c := s.Client()
+	s.Token = "other"
+	if _, err := c.GetPage(pageID); err == nil {
+		t.Error("expected error for wrong token")
+	}
+}
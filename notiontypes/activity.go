@@ -0,0 +1,66 @@
+package notiontypes
+
+import (
+	"strconv"
+	"time"
+)
+
+// ActivityWithRole holds a user's role associated with an activity and an activity.
+type ActivityWithRole struct {
+	Role  string    `json:"role,omitempty"`
+	Value *Activity `json:"value,omitempty"`
+}
+
+// Activity is an entry of the activity log of a page, a group of edits
+// made within a short time.
+type Activity struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"` // e.g. "block-edited"
+	ParentID    string `json:"parent_id"`
+	ParentTable string `json:"parent_table"`
+	SpaceID     string `json:"space_id"`
+	// the page the activity happened on
+	NavigableBlockID string `json:"navigable_block_id"`
+	// unix milliseconds, as strings
+	StartTime string          `json:"start_time"`
+	EndTime   string          `json:"end_time"`
+	Edits     []*ActivityEdit `json:"edits"`
+}
+
+// StartedOn returns the time of the first edit of the activity.
+func (a *Activity) StartedOn() time.Time {
+	return millisTime(a.StartTime)
+}
+
+// EndedOn returns the time of the last edit of the activity.
+func (a *Activity) EndedOn() time.Time {
+	return millisTime(a.EndTime)
+}
+
+// ActivityEdit is an edit of a block within an Activity.
+type ActivityEdit struct {
+	Type      string            `json:"type"` // e.g. "block-changed"
+	BlockID   string            `json:"block_id"`
+	SpaceID   string            `json:"space_id"`
+	Timestamp int64             `json:"timestamp"` // unix milliseconds
+	Authors   []*ActivityAuthor `json:"authors"`
+}
+
+// EditedOn returns the time of the edit.
+func (e *ActivityEdit) EditedOn() time.Time {
+	return time.Unix(e.Timestamp/1000, e.Timestamp%1000*int64(time.Millisecond))
+}
+
+// ActivityAuthor is the author of an ActivityEdit.
+type ActivityAuthor struct {
+	ID    string `json:"id"`
+	Table string `json:"table"` // e.g. "notion_user"
+}
+
+func millisTime(s string) time.Time {
+	ms, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(ms/1000, ms%1000*int64(time.Millisecond))
+}
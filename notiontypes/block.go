@@ -44,9 +44,11 @@ type Block struct {
 	Alive bool `json:"alive"`
 	// List of block ids for that make up content of this block
 	// Use Content to get corresponding block (they are in the same order)
-	ContentIDs   []string `json:"content,omitempty"`
-	CopiedFrom   string   `json:"copied_from,omitempty"`
-	CollectionID string   `json:"collection_id,omitempty"` // for BlockCollectionView
+	ContentIDs []string `json:"content,omitempty"`
+	CopiedFrom string   `json:"copied_from,omitempty"`
+	// for BlockCollectionView, also set from the collection pointer of
+	// linked databases
+	CollectionID string `json:"collection_id,omitempty"`
 	// ID of the user who created this block
 	CreatedBy   string `json:"created_by"`
 	CreatedTime int64  `json:"created_time"`
@@ -68,6 +70,8 @@ type Block struct {
 	// ID of parent Block
 	ParentID    string `json:"parent_id"`
 	ParentTable string `json:"parent_table"`
+	// ID of the workspace the block is in
+	SpaceID string `json:"space_id,omitempty"`
 	// not always available
 	Permissions *[]Permission          `json:"permissions,omitempty"`
 	Properties  map[string]interface{} `json:"properties,omitempty"`
@@ -81,15 +85,31 @@ type Block struct {
 
 	// maps ContentIDs array
 	Content []*Block `json:"content_resolved,omitempty"`
+	// MissingIDs are the ids of content that couldn't be resolved, when
+	// resolved with ResolveOptions.Partial. ContentIDs still lists them,
+	// Content doesn't. For BlockSyncedBlockCopy, it's the id of a missing
+	// original.
+	MissingIDs []string `json:"missing_ids,omitempty"`
 	// this is for some types like TypePage, TypeText, TypeHeader etc.
 	InlineContent []*InlineBlock `json:"inline_content,omitempty"`
 
-	// for BlockPage
+	// for BlockPage and BlockBookmark
 	Title string `json:"title,omitempty"`
 
 	// For BlockTodo, a checked state
 	IsChecked bool `json:"is_checked,omitempty"`
 
+	// for BlockSyncedBlockCopy, the id of the BlockSyncedBlock it copies.
+	// Content blocks are shared with the original if it could be resolved.
+	OriginalID string `json:"original_id,omitempty"`
+
+	// for BlockColumnList, the layout of its BlockColumn children
+	Columns []*Column `json:"columns,omitempty"`
+
+	// for BlockTable, the text of the cells by row and column,
+	// resolved from the BlockTableRow children
+	Rows [][][]*InlineBlock `json:"rows,omitempty"`
+
 	// for BlockBookmark
 	Description string `json:"description,omitempty"`
 	Link        string `json:"link,omitempty"`
@@ -98,7 +118,8 @@ type Block struct {
 	// for BlockGist it's the url for the gist
 	// fot BlockImage it's url of the image, but use ImageURL instead
 	// because Source is sometimes not accessible
-	// for BlockFile it's url of the file
+	// for BlockFile, BlockAudio and BlockPDF it's url of the file
+	// for embeds (e.g. BlockFigma) it's the url of the embedded page
 	Source string `json:"source,omitempty"`
 
 	// for BlockFile
@@ -120,8 +141,11 @@ type Block struct {
 	FormatImage    *FormatImage    `json:"format_image,omitempty"`
 	FormatColumn   *FormatColumn   `json:"format_column,omitempty"`
 	FormatText     *FormatText     `json:"format_text,omitempty"`
+	FormatCallout  *FormatCallout  `json:"format_callout,omitempty"`
 	FormatTable    *FormatTable    `json:"format_table,omitempty"`
 	FormatVideo    *FormatVideo    `json:"format_video,omitempty"`
+	FormatEmbed    *FormatEmbed    `json:"format_embed,omitempty"`
+	FormatDrive    *FormatDrive    `json:"format_drive,omitempty"`
 }
 
 // CollectionViewInfo describes a particular view of the collection
@@ -161,6 +185,24 @@ func (b *Block) IsImage() bool {
 	return b.Type == BlockImage
 }
 
+// IsEmbed returns true if block embeds a file or a web page
+func (b *Block) IsEmbed() bool {
+	switch b.Type {
+	case BlockAudio, BlockPDF, BlockEmbed, BlockDrive, BlockFigma, BlockTweet, BlockMaps:
+		return true
+	}
+	return false
+}
+
+// IsLinkedDatabase returns true if block is a linked database: a view of
+// collection, which is owned by another block.
+func (b *Block) IsLinkedDatabase(collection *Collection) bool {
+	if b.Type != BlockCollectionView && b.Type != BlockCollectionViewPage {
+		return false
+	}
+	return collection != nil && collection.ID == b.CollectionID && collection.ParentID != b.ID
+}
+
 // IsCode returns true if block represents a code block
 func (b *Block) IsCode() bool {
 	return b.Type == BlockCode
@@ -185,7 +227,8 @@ type FormatPage struct {
 
 // FormatBookmark describes format for BlockBookmark
 type FormatBookmark struct {
-	BookmarkIcon string `json:"bookmark_icon"`
+	BookmarkIcon  string `json:"bookmark_icon"`
+	BookmarkCover string `json:"bookmark_cover,omitempty"`
 }
 
 // FormatImage describes format for BlockImage
@@ -213,16 +256,54 @@ type FormatVideo struct {
 	BlockPreserveScale bool    `json:"block_preserve_scale"`
 }
 
+// FormatEmbed describes format for embeds like BlockPDF, BlockFigma or BlockGist
+type FormatEmbed struct {
+	BlockWidth         float64 `json:"block_width"`
+	BlockHeight        float64 `json:"block_height"`
+	BlockFullWidth     bool    `json:"block_full_width"`
+	BlockPageWidth     bool    `json:"block_page_width"`
+	BlockAspectRatio   float64 `json:"block_aspect_ratio"`
+	BlockPreserveScale bool    `json:"block_preserve_scale"`
+	// the url that can be put into an iframe, may differ from Source
+	DisplaySource string `json:"display_source,omitempty"`
+}
+
+// FormatDrive describes format for BlockDrive
+type FormatDrive struct {
+	DriveProperties struct {
+		Title        string `json:"title"`
+		URL          string `json:"url"`
+		Icon         string `json:"icon"`
+		Thumbnail    string `json:"thumbnail"`
+		FileID       string `json:"file_id"`
+		UserName     string `json:"user_name"`
+		ModifiedTime int64  `json:"modified_time"`
+	} `json:"drive_properties"`
+}
+
 // FormatText describes format for TypeText
 // TODO: possibly more?
 type FormatText struct {
 	BlockColor *string `json:"block_color,omitempty"`
 }
 
+// FormatCallout describes format for BlockCallout
+type FormatCallout struct {
+	// emoji or url, like FormatPage.PageIcon
+	PageIcon   string  `json:"page_icon"`
+	BlockColor *string `json:"block_color,omitempty"`
+}
+
 // FormatTable describes format for TypeTable
 type FormatTable struct {
 	TableWrap       bool             `json:"table_wrap"`
 	TableProperties []*TableProperty `json:"table_properties"`
+
+	// for simple tables, ids of the columns (keys of the rows' Properties) in display order
+	ColumnOrder []string `json:"table_block_column_order,omitempty"`
+	// for simple tables, whether the first row and column are headers
+	ColumnHeader bool `json:"table_block_column_header,omitempty"`
+	RowHeader    bool `json:"table_block_row_header,omitempty"`
 }
 
 // TableProperty describes property of a table
@@ -232,6 +313,61 @@ type TableProperty struct {
 	Property string `json:"property"`
 }
 
+// FormatCollectionView describes format for BlockCollectionView and
+// BlockCollectionViewPage. Linked databases point at their collection
+// through it.
+type FormatCollectionView struct {
+	Pointer *RecordPointer `json:"collection_pointer,omitempty"`
+}
+
+// RecordPointer points at a record of another table
+type RecordPointer struct {
+	ID      string `json:"id"`
+	Table   string `json:"table"`
+	SpaceID string `json:"spaceId,omitempty"`
+}
+
+// FormatSyncedBlockCopy describes format for BlockSyncedBlockCopy
+type FormatSyncedBlockCopy struct {
+	Pointer struct {
+		ID      string `json:"id"`
+		Table   string `json:"table"`
+		SpaceID string `json:"spaceId"`
+	} `json:"transclusion_reference_pointer"`
+}
+
+// Column is a column of a BlockColumnList. Its content is that of the
+// BlockColumn at the same index in the list's Content, which is read when
+// rendering so that later changes to it, e.g. by Filter.Prune, show.
+type Column struct {
+	// Ratio is the share of the list's width taken by the column, the
+	// ratios of all columns add up to 1
+	Ratio float64 `json:"ratio"`
+}
+
+// CopyContent sets the content of the synced block copy b to that of
+// original. The blocks are shared, the slices holding them aren't, so that
+// removing blocks from the content of one copy, e.g. with Filter.Prune,
+// doesn't change the others.
+func (b *Block) CopyContent(original *Block) {
+	b.Content = nil
+	if original.Content != nil {
+		b.Content = append(make([]*Block, 0, len(original.Content)), original.Content...)
+	}
+	b.ContentIDs = append([]string(nil), original.ContentIDs...)
+	b.MissingIDs = append([]string(nil), original.MissingIDs...)
+}
+
+// ColumnRatio returns the share of the width of the column list b taken
+// by its i-th column, b.Content[i]. Columns share the width evenly if
+// columns were added or removed since b was resolved.
+func (b *Block) ColumnRatio(i int) float64 {
+	if len(b.Columns) == len(b.Content) {
+		return b.Columns[i].Ratio
+	}
+	return 1 / float64(len(b.Content))
+}
+
 // FormatColumn describes format for TypeColumn
 type FormatColumn struct {
 	ColumnRation float64 `json:"column_ratio"` // e.g. 0.5 for half-sized column
@@ -239,7 +375,8 @@ type FormatColumn struct {
 
 // Permission describes user permissions
 type Permission struct {
-	Role   string  `json:"role"`
-	Type   string  `json:"type"`
-	UserID *string `json:"user_id,omitempty"`
+	Role    string  `json:"role"`
+	Type    string  `json:"type"`
+	UserID  *string `json:"user_id,omitempty"`
+	GroupID *string `json:"group_id,omitempty"`
 }
@@ -0,0 +1,134 @@
+package notiontypes
+
+import (
+	"context"
+	"fmt"
+)
+
+// BlockGetter fetches blocks by id, without resolving their content.
+// It is implemented by *notion.Client.
+type BlockGetter interface {
+	GetBlocksContext(ctx context.Context, ids ...string) ([]*Block, error)
+}
+
+// Children returns the content of b, fetching it with g the first time it's
+// needed, so that trees of blocks can be walked lazily. The fetched
+// children's own content is fetched by their Children method in turn.
+//
+// Children is not safe for concurrent use on the same block.
+func (b *Block) Children(ctx context.Context, g BlockGetter) ([]*Block, error) {
+	if b.Content != nil {
+		return b.Content, nil
+	}
+	source := b
+	if b.Type == BlockSyncedBlockCopy && b.OriginalID != "" {
+		// copies share the content of the original
+		original, err := g.GetBlocksContext(ctx, b.OriginalID)
+		if err != nil {
+			return nil, err
+		}
+		if original[0] == nil {
+			return nil, fmt.Errorf("notion: original %v of synced block %v not found", b.OriginalID, b.ID)
+		}
+		source = original[0]
+	}
+	if len(source.ContentIDs) == 0 {
+		return nil, nil
+	}
+	blocks, err := g.GetBlocksContext(ctx, source.ContentIDs...)
+	if err != nil {
+		return nil, err
+	}
+	content := make([]*Block, 0, len(blocks))
+	ids := make([]string, 0, len(blocks))
+	for _, child := range blocks {
+		// like ResolveBlock, skip blocks that can't be fetched
+		if child != nil {
+			content = append(content, child)
+			ids = append(ids, child.ID)
+		}
+	}
+	b.Content, b.ContentIDs = content, ids
+	switch b.Type {
+	case BlockTable:
+		resolveTableRows(b)
+	case BlockColumnList:
+		// the columns' content is needed for the layout
+		for _, col := range content {
+			if _, err := col.Children(ctx, g); err != nil {
+				return nil, err
+			}
+		}
+		resolveColumns(b)
+	}
+	return b.Content, nil
+}
+
+// ResolveMissing fetches the MissingIDs of b, left by a partial
+// ResolveBlock, with g and adds the blocks found to its Content, in the
+// order of ContentIDs. Their own content is fetched by their Children
+// method. Like Children, it drops the blocks that still can't be fetched.
+func (b *Block) ResolveMissing(ctx context.Context, g BlockGetter) error {
+	if len(b.MissingIDs) == 0 {
+		return nil
+	}
+	if b.Type == BlockSyncedBlockCopy && b.Content == nil {
+		// the original is missing
+		b.MissingIDs = nil
+		_, err := b.Children(ctx, g)
+		return err
+	}
+	fetched, err := g.GetBlocksContext(ctx, b.MissingIDs...)
+	if err != nil {
+		return err
+	}
+	byID := make(map[string]*Block, len(b.ContentIDs))
+	for _, child := range append(b.Content, fetched...) {
+		if child != nil {
+			byID[child.ID] = child
+		}
+	}
+	content := make([]*Block, 0, len(b.ContentIDs))
+	ids := make([]string, 0, len(b.ContentIDs))
+	for _, id := range b.ContentIDs {
+		if child := byID[id]; child != nil {
+			content = append(content, child)
+			ids = append(ids, id)
+		}
+	}
+	b.Content, b.ContentIDs, b.MissingIDs = content, ids, nil
+	switch b.Type {
+	case BlockTable:
+		resolveTableRows(b)
+	case BlockColumnList:
+		for _, col := range content {
+			if _, err := col.Children(ctx, g); err != nil {
+				return err
+			}
+		}
+		resolveColumns(b)
+	}
+	return nil
+}
+
+// MissingContent returns the blocks within b, including b, with
+// MissingIDs.
+func MissingContent(b *Block) []*Block {
+	var blocks []*Block
+	seen := map[*Block]bool{}
+	var walk func(b *Block)
+	walk = func(b *Block) {
+		if seen[b] {
+			return
+		}
+		seen[b] = true
+		if len(b.MissingIDs) > 0 {
+			blocks = append(blocks, b)
+		}
+		for _, child := range b.Content {
+			walk(child)
+		}
+	}
+	walk(b)
+	return blocks
+}
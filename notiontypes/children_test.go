@@ -0,0 +1,43 @@
+package notiontypes
+
+import (
+	"context"
+	"testing"
+)
+
+type mapGetter struct {
+	blocks map[string]*Block
+	calls  int
+}
+
+func (g *mapGetter) GetBlocksContext(ctx context.Context, ids ...string) ([]*Block, error) {
+	g.calls++
+	res := make([]*Block, len(ids))
+	for i, id := range ids {
+		res[i] = g.blocks[id]
+	}
+	return res, nil
+}
+
+func TestChildren(t *testing.T) {
+	g := &mapGetter{blocks: map[string]*Block{
+		"a": {ID: "a", Type: BlockText},
+		"c": {ID: "c", Type: BlockText},
+	}}
+	page := &Block{ID: "page", Type: BlockPage, ContentIDs: []string{"a", "b", "c"}}
+	for i := 0; i < 2; i++ {
+		children, err := page.Children(context.Background(), g)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(children) != 2 || children[0].ID != "a" || children[1].ID != "c" {
+			t.Fatalf("unexpected children %v", children)
+		}
+	}
+	if g.calls != 1 {
+		t.Errorf("got %d fetches, want 1", g.calls)
+	}
+	if children, err := g.blocks["a"].Children(context.Background(), g); err != nil || children != nil || g.calls != 1 {
+		t.Errorf("leaf block: got %v, %v after %d fetches", children, err, g.calls)
+	}
+}
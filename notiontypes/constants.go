@@ -31,6 +31,10 @@ const (
 	PermissionTypeUser = "user_permission"
 	// PermissionTypePublic describes permissions for public
 	PermissionTypePublic = "public_permission"
+	// PermissionTypeSpace describes permissions for all members of a workspace
+	PermissionTypeSpace = "space_permission"
+	// PermissionTypeGroup describes permissions for a group of users
+	PermissionTypeGroup = "group_permission"
 )
 
 const (
@@ -58,8 +62,12 @@ const (
 	BlockHeader = "header"
 	// BlockSubHeader is a header block
 	BlockSubHeader = "sub_header"
+	// BlockSubSubHeader is a third level header block
+	BlockSubSubHeader = "sub_sub_header"
 	// BlockQuote is a quote block
 	BlockQuote = "quote"
+	// BlockCallout is a text block highlighted with an icon
+	BlockCallout = "callout"
 	// BlockComment is a comment block
 	BlockComment = "comment"
 	// BlockCode is a code block
@@ -71,22 +79,62 @@ const (
 	BlockColumn = "column"
 	// BlockTable is a table block
 	BlockTable = "table"
+	// BlockTableRow is a row of a (simple) BlockTable
+	BlockTableRow = "table_row"
 	// BlockCollectionView is a collection view block
 	BlockCollectionView = "collection_view"
+	// BlockCollectionViewPage is a full page collection view block
+	BlockCollectionViewPage = "collection_view_page"
 	// BlockVideo is youtube video embed
 	BlockVideo = "video"
 	// BlockFile is an embedded file
 	BlockFile = "file"
+	// BlockAudio is an audio file
+	BlockAudio = "audio"
+	// BlockPDF is an embedded pdf file
+	BlockPDF = "pdf"
+	// BlockEmbed is a generic embed of a web page
+	BlockEmbed = "embed"
+	// BlockDrive is a Google Drive file embed
+	BlockDrive = "drive"
+	// BlockFigma is a Figma file embed
+	BlockFigma = "figma"
+	// BlockTweet is a tweet embed
+	BlockTweet = "tweet"
+	// BlockMaps is a Google Maps embed
+	BlockMaps = "maps"
+	// BlockTableOfContents lists the headings of the page it's on
+	BlockTableOfContents = "table_of_contents"
+	// BlockBreadcrumb shows the path to the page it's on
+	BlockBreadcrumb = "breadcrumb"
+	// BlockSyncedBlock is the original of a synced block, its content is
+	// shown wherever it's referenced by a BlockSyncedBlockCopy
+	BlockSyncedBlock = "transclusion_container"
+	// BlockSyncedBlockCopy is a copy of a synced block, see OriginalID
+	BlockSyncedBlockCopy = "transclusion_reference"
 )
 
 // for CollectionColumnInfo.Type
 const (
 	// ColumnMultiSelect is multi-select column
-	ColumnMultiSelect = "multi_select"
-	ColumnTypeNumber  = "number"
-	ColumnTypeTitle   = "title"
-	// TODO: text, select, date, person, Files&Media, checkbox, URL, Email, phone
-	// formula, relaion, created time, created by, last edited time, last edited by
+	ColumnMultiSelect        = "multi_select"
+	ColumnTypeNumber         = "number"
+	ColumnTypeTitle          = "title"
+	ColumnTypeText           = "text"
+	ColumnTypeSelect         = "select"
+	ColumnTypeDate           = "date"
+	ColumnTypePerson         = "person"
+	ColumnTypeFile           = "file"
+	ColumnTypeCheckbox       = "checkbox"
+	ColumnTypeURL            = "url"
+	ColumnTypeEmail          = "email"
+	ColumnTypePhoneNumber    = "phone_number"
+	ColumnTypeFormula        = "formula"
+	ColumnTypeRelation       = "relation"
+	ColumnTypeCreatedTime    = "created_time"
+	ColumnTypeCreatedBy      = "created_by"
+	ColumnTypeLastEditedTime = "last_edited_time"
+	ColumnTypeLastEditedBy   = "last_edited_by"
 )
 
 const (
@@ -94,6 +142,14 @@ const (
 	TableSpace = "space"
 	// TableBlock represents a Notion block
 	TableBlock = "block"
+	// TableCollection represents a Notion collection (database)
+	TableCollection = "collection"
+	// TableCollectionView represents a view of a Notion collection
+	TableCollectionView = "collection_view"
+	// TableDiscussion represents a thread of comments on a block
+	TableDiscussion = "discussion"
+	// TableComment represents a comment of a discussion
+	TableComment = "comment"
 )
 
 const (
@@ -101,6 +157,12 @@ const (
 	RoleReader = "reader"
 	// RoleEditor represents an editor
 	RoleEditor = "editor"
+	// RoleCommenter represents a user who can read and comment
+	RoleCommenter = "comment_only"
+	// RoleReadAndWrite represents a user who can edit content but not share
+	RoleReadAndWrite = "read_and_write"
+	// RoleNone represents the lack of access
+	RoleNone = "none"
 )
 
 const (
@@ -0,0 +1,26 @@
+//go:build ignore
+// +build ignore
+
+// gen_schema writes the JSON Schema and TypeScript definitions of the
+// package, see Schema.
+package main
+
+import (
+	"io/ioutil"
+	"log"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+func main() {
+	schema, err := notiontypes.Schema()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile("notiontypes.schema.json", schema, 0644); err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile("notiontypes.d.ts", notiontypes.TypeScript(), 0644); err != nil {
+		log.Fatal(err)
+	}
+}
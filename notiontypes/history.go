@@ -0,0 +1,23 @@
+package notiontypes
+
+import "time"
+
+// Snapshot is a saved version of a page, as listed in the page's history.
+type Snapshot struct {
+	ID string `json:"id"`
+	// Version is the version of the page block as of the snapshot, and
+	// LastVersion that of the previous snapshot.
+	Version     int64  `json:"version"`
+	LastVersion int64  `json:"last_version"`
+	ParentID    string `json:"parent_id"`
+	ParentTable string `json:"parent_table"`
+	// Timestamp is the time of the snapshot in unix milliseconds.
+	Timestamp int64 `json:"timestamp"`
+	// Authors are the users whose edits the snapshot saved.
+	Authors []*ActivityAuthor `json:"authors"`
+}
+
+// SavedOn returns the time of the snapshot.
+func (s *Snapshot) SavedOn() time.Time {
+	return time.Unix(s.Timestamp/1000, s.Timestamp%1000*int64(time.Millisecond))
+}
@@ -59,12 +59,60 @@ type InlineBlock struct {
 	// only one of those is set on a given InlineBlock
 	Link   string `json:"Link,omitempty"`   // represents link attribute
 	UserID string `json:"UserID,omitempty"` // represents user attribute
+	PageID string `json:"PageID,omitempty"` // represents page mention attribute, e.g. in relations
 	Date   *Date  `json:"Date,omitempty"`   // represents date attribute
 }
 
 // IsPlain returns true if this InlineBlock is plain text i.e. has no attributes
 func (b *InlineBlock) IsPlain() bool {
-	return b.AttrFlags == 0 && b.Link == "" && b.UserID == "" && b.Date == nil
+	return b.AttrFlags == 0 && b.Link == "" && b.UserID == "" && b.PageID == "" && b.Date == nil
+}
+
+// EncodeInlineBlocks converts inline blocks into the nested array format
+// notion uses for text properties (e.g. "title"). It is the inverse of the
+// parsing done when resolving a block.
+func EncodeInlineBlocks(blocks []*InlineBlock) []interface{} {
+	res := make([]interface{}, 0, len(blocks))
+	for _, b := range blocks {
+		var attrs []interface{}
+		if b.AttrFlags&AttrBold != 0 {
+			attrs = append(attrs, []interface{}{"b"})
+		}
+		if b.AttrFlags&AttrItalic != 0 {
+			attrs = append(attrs, []interface{}{"i"})
+		}
+		if b.AttrFlags&AttrStrikeThrought != 0 {
+			attrs = append(attrs, []interface{}{"s"})
+		}
+		if b.AttrFlags&AttrCode != 0 {
+			attrs = append(attrs, []interface{}{"c"})
+		}
+		if b.Link != "" {
+			attrs = append(attrs, []interface{}{"a", b.Link})
+		}
+		if b.UserID != "" {
+			attrs = append(attrs, []interface{}{"u", b.UserID})
+		}
+		if b.PageID != "" {
+			attrs = append(attrs, []interface{}{"p", b.PageID})
+		}
+		if b.Date != nil {
+			attrs = append(attrs, []interface{}{"d", b.Date})
+		}
+		if len(attrs) == 0 {
+			res = append(res, []interface{}{b.Text})
+			continue
+		}
+		res = append(res, []interface{}{b.Text, attrs})
+	}
+	return res
+}
+
+// ParseInlineBlocks parses text properties in the nested array format
+// notion uses, e.g. as decoded from JSON. It is the inverse of
+// EncodeInlineBlocks.
+func ParseInlineBlocks(raw interface{}) ([]*InlineBlock, error) {
+	return parseInlineBlocks(raw)
 }
 
 func parseAttribute(b *InlineBlock, a []interface{}) error {
@@ -92,32 +140,36 @@ func parseAttribute(b *InlineBlock, a []interface{}) error {
 		return nil
 	}
 
-	if len(a) != 2 {
+	// page mentions may be followed by the id of the page's space
+	if len(a) != 2 && !(s == "p" && len(a) == 3) {
 		return fmt.Errorf("len(a) is %d and should be 2", len(a))
 	}
 
 	switch s {
-	case "a", "u":
+	case "a", "u", "p":
 		v, ok := a[1].(string)
 		if !ok {
-			return fmt.Errorf("value for 'a' attribute is not string. Type: %T, value: %#v", v, v)
+			return fmt.Errorf("value for '%s' attribute is not string. Type: %T, value: %#v", s, a[1], a[1])
 		}
 		if s == "a" {
 			b.Link = v
 		} else if s == "u" {
 			b.UserID = v
+		} else {
+			b.PageID = v
 		}
 	case "d":
-		v := a[1].(map[string]interface{})
-		js, err := json.MarshalIndent(v, "", "  ")
+		v, ok := a[1].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("value for 'd' attribute is not a map. Type: %T, value: %#v", a[1], a[1])
+		}
+		js, err := json.Marshal(v)
 		if err != nil {
-			panic(err.Error())
+			return err
 		}
-		//dbg("date in js:\n%s\n", string(js))
 		var d Date
-		err = json.Unmarshal(js, &d)
-		if err != nil {
-			panic(err.Error())
+		if err := json.Unmarshal(js, &d); err != nil {
+			return fmt.Errorf("invalid value for 'd' attribute: %v", err)
 		}
 		b.Date = &d
 	default:
@@ -165,11 +217,11 @@ func parseInlineBlock(a []interface{}) (*InlineBlock, error) {
 	res := &InlineBlock{
 		Text: s,
 	}
-	a, ok = a[1].([]interface{})
+	attrs, ok := a[1].([]interface{})
 	if !ok {
 		return nil, fmt.Errorf("a[1] is not []interface{}. a[1] type: %T, value: '%#v'", a[1], a[1])
 	}
-	err := parseAttributes(res, a)
+	err := parseAttributes(res, attrs)
 	if err != nil {
 		return nil, err
 	}
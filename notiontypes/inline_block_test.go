@@ -0,0 +1,50 @@
+package notiontypes
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// FuzzParseInlineBlocks checks that parsing never panics and that parsed
+// blocks survive encoding, as when sent back to notion, unchanged.
+func FuzzParseInlineBlocks(f *testing.F) {
+	for _, seed := range []string{
+		`[["plain"]]`,
+		`[["bold and italic", [["b"], ["i"]]], [" "], ["code", [["c"], ["s"]]]]`,
+		`[["link", [["a", "https://example.com"]]]]`,
+		`[["‣", [["u", "aa8fc126-6770-4e83-ad6c-3968dcfc9b82"]]]]`,
+		`[["‣", [["d", {"type": "date", "start_date": "2019-03-01", "start_time": "09:00"}]]]]`,
+		`[["x", [["d", "not a date"]]]]`,
+		`[["x", [["a"]]]]`,
+		`[]`,
+		`[[1]]`,
+		`[["", ""]]`,
+	} {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var raw interface{}
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return
+		}
+		blocks, err := parseInlineBlocks(raw)
+		if err != nil {
+			return
+		}
+		encoded, err := json.Marshal(EncodeInlineBlocks(blocks))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(encoded, &raw); err != nil {
+			t.Fatal(err)
+		}
+		again, err := parseInlineBlocks(raw)
+		if err != nil {
+			t.Fatalf("parsing encoded %s: %v", encoded, err)
+		}
+		if !reflect.DeepEqual(blocks, again) {
+			t.Errorf("round trip of %s changed blocks: %s", data, encoded)
+		}
+	})
+}
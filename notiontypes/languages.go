@@ -0,0 +1,152 @@
+package notiontypes
+
+import "strings"
+
+// codeLanguages maps the code block languages offered by notion to the
+// names linguist (and with it GitHub, chroma and most Markdown tooling)
+// uses for fenced code blocks.
+var codeLanguages = map[string]string{
+	"ABAP":          "abap",
+	"Arduino":       "arduino",
+	"Bash":          "bash",
+	"BASIC":         "basic",
+	"C":             "c",
+	"Clojure":       "clojure",
+	"CoffeeScript":  "coffeescript",
+	"C++":           "cpp",
+	"C#":            "csharp",
+	"CSS":           "css",
+	"Dart":          "dart",
+	"Diff":          "diff",
+	"Docker":        "dockerfile",
+	"Elixir":        "elixir",
+	"Elm":           "elm",
+	"Erlang":        "erlang",
+	"Flow":          "javascript",
+	"Fortran":       "fortran",
+	"F#":            "fsharp",
+	"Gherkin":       "gherkin",
+	"GLSL":          "glsl",
+	"Go":            "go",
+	"GraphQL":       "graphql",
+	"Groovy":        "groovy",
+	"Haskell":       "haskell",
+	"HTML":          "html",
+	"Java":          "java",
+	"Java/C/C++/C#": "java",
+	"JavaScript":    "javascript",
+	"JSON":          "json",
+	"Julia":         "julia",
+	"Kotlin":        "kotlin",
+	"LaTeX":         "latex",
+	"Less":          "less",
+	"Lisp":          "common-lisp",
+	"LiveScript":    "livescript",
+	"Lua":           "lua",
+	"Makefile":      "makefile",
+	"Markdown":      "markdown",
+	"Markup":        "html",
+	"MATLAB":        "matlab",
+	"Mermaid":       "mermaid",
+	"Nix":           "nix",
+	"Objective-C":   "objective-c",
+	"OCaml":         "ocaml",
+	"Pascal":        "pascal",
+	"Perl":          "perl",
+	"PHP":           "php",
+	"Plain Text":    "",
+	"PowerShell":    "powershell",
+	"Prolog":        "prolog",
+	"Protobuf":      "protobuf",
+	"Python":        "python",
+	"R":             "r",
+	"Reason":        "reason",
+	"Ruby":          "ruby",
+	"Rust":          "rust",
+	"Sass":          "sass",
+	"Scala":         "scala",
+	"Scheme":        "scheme",
+	"SCSS":          "scss",
+	"Shell":         "shell",
+	"SQL":           "sql",
+	"Swift":         "swift",
+	"TypeScript":    "typescript",
+	"VB.Net":        "vbnet",
+	"Verilog":       "verilog",
+	"VHDL":          "vhdl",
+	"Visual Basic":  "vb",
+	"WebAssembly":   "wasm",
+	"XML":           "xml",
+	"YAML":          "yaml",
+}
+
+// codeLanguageAliases maps common fenced code block names to notion code
+// languages. It also settles names shared by several notion languages.
+var codeLanguageAliases = map[string]string{
+	"javascript":  "JavaScript",
+	"html":        "HTML",
+	"java":        "Java",
+	"sh":          "Shell",
+	"zsh":         "Shell",
+	"console":     "Shell",
+	"js":          "JavaScript",
+	"jsx":         "JavaScript",
+	"ts":          "TypeScript",
+	"tsx":         "TypeScript",
+	"py":          "Python",
+	"rb":          "Ruby",
+	"rs":          "Rust",
+	"golang":      "Go",
+	"c++":         "C++",
+	"cs":          "C#",
+	"c#":          "C#",
+	"f#":          "F#",
+	"yml":         "YAML",
+	"md":          "Markdown",
+	"tex":         "LaTeX",
+	"objc":        "Objective-C",
+	"proto":       "Protobuf",
+	"text":        "Plain Text",
+	"plaintext":   "Plain Text",
+	"txt":         "Plain Text",
+	"ps1":         "PowerShell",
+	"make":        "Makefile",
+	"kt":          "Kotlin",
+	"hs":          "Haskell",
+	"clj":         "Clojure",
+	"ex":          "Elixir",
+	"erl":         "Erlang",
+	"docker":      "Docker",
+	"lisp":        "Lisp",
+	"vb.net":      "VB.Net",
+	"webassembly": "WebAssembly",
+}
+
+// LinguistName returns the fenced code block name (as used by linguist)
+// for a notion code language, e.g. "cpp" for "C++". Unknown languages are
+// lower-cased; "Plain Text" yields the empty string.
+func LinguistName(language string) string {
+	if name, ok := codeLanguages[language]; ok {
+		return name
+	}
+	return strings.ToLower(language)
+}
+
+// CodeLanguage returns the notion code language for a fenced code block
+// name, e.g. "C++" for "cpp". Unknown names are returned unchanged and the
+// empty string yields "Plain Text".
+func CodeLanguage(name string) string {
+	if name == "" {
+		return "Plain Text"
+	}
+	lower := strings.ToLower(name)
+	if lang, ok := codeLanguageAliases[lower]; ok {
+		return lang
+	}
+	for lang, linguist := range codeLanguages {
+		if linguist == lower || strings.ToLower(lang) == lower {
+			return lang
+		}
+	}
+	return name
+}
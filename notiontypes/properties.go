@@ -0,0 +1,123 @@
+package notiontypes
+
+import (
+	"sort"
+	"strings"
+)
+
+// PageProperty is the value of a property of a page that is a row of a
+// collection, typed according to the collection's schema.
+type PageProperty struct {
+	// ID is the key of the property in the schema and Block.Properties
+	ID string
+	// Name and Type (e.g. ColumnTypeSelect) come from the schema
+	Name  string
+	Type  string
+	Value []*InlineBlock
+}
+
+// Text returns the value as plain text.
+func (p *PageProperty) Text() string {
+	var b strings.Builder
+	for _, i := range p.Value {
+		b.WriteString(i.Text)
+	}
+	return b.String()
+}
+
+// Values returns the selected options of ColumnMultiSelect properties, or
+// the value as a single element for other types.
+func (p *PageProperty) Values() []string {
+	text := p.Text()
+	if text == "" {
+		return nil
+	}
+	if p.Type != ColumnMultiSelect {
+		return []string{text}
+	}
+	values := strings.Split(text, ",")
+	for i, v := range values {
+		values[i] = strings.TrimSpace(v)
+	}
+	return values
+}
+
+// PageIDs returns the ids of the pages of ColumnTypeRelation properties.
+func (p *PageProperty) PageIDs() []string {
+	var ids []string
+	for _, i := range p.Value {
+		if i.PageID != "" {
+			ids = append(ids, i.PageID)
+		}
+	}
+	return ids
+}
+
+// Checked returns the value of ColumnTypeCheckbox properties.
+func (p *PageProperty) Checked() bool {
+	return strings.EqualFold(p.Text(), "Yes")
+}
+
+// Date returns the value of ColumnTypeDate properties, or nil.
+func (p *PageProperty) Date() *Date {
+	for _, i := range p.Value {
+		if i.Date != nil {
+			return i.Date
+		}
+	}
+	return nil
+}
+
+// Property returns the property id, including the title, of the page b,
+// a row of c, or nil if c has no such property. Properties that aren't
+// set have no Value.
+func (c *Collection) Property(b *Block, id string) *PageProperty {
+	info, ok := c.CollectionSchema[id]
+	if !ok {
+		return nil
+	}
+	p := &PageProperty{ID: id, Name: info.Name, Type: info.Type}
+	if v, ok := b.Properties[id]; ok {
+		p.Value, _ = parseInlineBlocks(v)
+	}
+	return p
+}
+
+// PageProperties returns the properties the page b, a row of c, has set.
+// The title is not included. Properties are ordered as on the page in
+// notion, then by name and id.
+func (c *Collection) PageProperties(b *Block) []*PageProperty {
+	order := make(map[string]int)
+	if c.Format != nil {
+		for i, p := range c.Format.CollectionPageProperties {
+			order[p.Property] = i + 1
+		}
+	}
+	var res []*PageProperty
+	for id, info := range c.CollectionSchema {
+		if info.Type == ColumnTypeTitle {
+			continue
+		}
+		v, ok := b.Properties[id]
+		if !ok {
+			continue
+		}
+		value, err := parseInlineBlocks(v)
+		if err != nil {
+			continue
+		}
+		res = append(res, &PageProperty{ID: id, Name: info.Name, Type: info.Type, Value: value})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		oi, oj := order[res[i].ID], order[res[j].ID]
+		if oi != oj {
+			// properties without a position go last
+			return oj == 0 || oi != 0 && oi < oj
+		}
+		if res[i].Name != res[j].Name {
+			return res[i].Name < res[j].Name
+		}
+		return res[i].ID < res[j].ID
+	})
+	return res
+}
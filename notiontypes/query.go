@@ -0,0 +1,301 @@
+package notiontypes
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// for CollectionView.Type
+const (
+	ViewTable    = "table"
+	ViewBoard    = "board"
+	ViewCalendar = "calendar"
+	ViewList     = "list"
+	ViewGallery  = "gallery"
+	ViewTimeline = "timeline"
+)
+
+// for QuerySort.Direction
+const (
+	SortAscending  = "ascending"
+	SortDescending = "descending"
+)
+
+// CollectionQuery is the saved query of a collection view, stored as its
+// query2.
+type CollectionQuery struct {
+	Filter       *QueryFilter        `json:"filter,omitempty"`
+	Sort         []*QuerySort        `json:"sort,omitempty"`
+	Aggregations []*QueryAggregation `json:"aggregations,omitempty"`
+}
+
+// for FilterValue.Type
+const (
+	FilterExact    = "exact"
+	FilterRelative = "relative"
+)
+
+// QueryFilter is either a group, whose Filters are combined with Operator,
+// or a condition on the property Property.
+//
+// In query2, the conditions of a group are {"property": ..., "filter": ...}
+// and nested groups are wrapped as {"filter": group}.
+type QueryFilter struct {
+	// for groups, "and" or "or"
+	Operator string
+	Filters  []*QueryFilter
+
+	// for conditions, the id of the property
+	Property string
+	Filter   *FilterCondition
+}
+
+// IsGroup returns true if f is a group of filters.
+func (f *QueryFilter) IsGroup() bool {
+	return f.Filter == nil
+}
+
+type queryFilterJSON struct {
+	Operator string             `json:"operator,omitempty"`
+	Filters  []*queryFilterJSON `json:"filters,omitempty"`
+	Property string             `json:"property,omitempty"`
+	// a condition, or a nested group
+	Filter json.RawMessage `json:"filter,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (f *QueryFilter) MarshalJSON() ([]byte, error) {
+	j, err := f.toJSON()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(j)
+}
+
+func (f *QueryFilter) toJSON() (interface{}, error) {
+	if !f.IsGroup() {
+		return &struct {
+			Property string           `json:"property"`
+			Filter   *FilterCondition `json:"filter"`
+		}{f.Property, f.Filter}, nil
+	}
+	filters := []interface{}{}
+	for _, sub := range f.Filters {
+		j, err := sub.toJSON()
+		if err != nil {
+			return nil, err
+		}
+		if sub.IsGroup() {
+			j = map[string]interface{}{"filter": j}
+		}
+		filters = append(filters, j)
+	}
+	return &struct {
+		Operator string        `json:"operator"`
+		Filters  []interface{} `json:"filters"`
+	}{f.Operator, filters}, nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (f *QueryFilter) UnmarshalJSON(b []byte) error {
+	var j queryFilterJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	return f.fromJSON(&j)
+}
+
+func (f *QueryFilter) fromJSON(j *queryFilterJSON) error {
+	*f = QueryFilter{Operator: j.Operator, Property: j.Property}
+	if j.Property == "" && len(j.Filter) > 0 {
+		// a nested group
+		return json.Unmarshal(j.Filter, f)
+	}
+	if len(j.Filter) > 0 {
+		f.Filter = &FilterCondition{}
+		return json.Unmarshal(j.Filter, f.Filter)
+	}
+	f.Filters = make([]*QueryFilter, len(j.Filters))
+	for i, sub := range j.Filters {
+		f.Filters[i] = &QueryFilter{}
+		if err := f.Filters[i].fromJSON(sub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FilterCondition is the test a property must pass, like
+// {"operator": "enum_is", "value": {"type": "exact", "value": "Done"}}.
+type FilterCondition struct {
+	// e.g. "string_contains", "enum_is", "checkbox_is", "date_is_before",
+	// "is_empty"
+	Operator string       `json:"operator"`
+	Value    *FilterValue `json:"value,omitempty"`
+}
+
+// FilterValue is the operand of a FilterCondition. Exact values set one
+// of Text, Number, Checked, Date or UserID; relative dates set Text, e.g.
+// "today" or "one_week_ago". Values of other shapes are kept in Raw.
+type FilterValue struct {
+	// FilterExact or FilterRelative
+	Type    string
+	Text    string
+	Number  *float64
+	Checked *bool
+	Date    *Date
+	UserID  string
+	Raw     json.RawMessage
+}
+
+type filterValueJSON struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+type userPointer struct {
+	Table string `json:"table"`
+	ID    string `json:"id"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (v *FilterValue) MarshalJSON() ([]byte, error) {
+	var value interface{} = v.Text
+	switch {
+	case len(v.Raw) > 0:
+		value = v.Raw
+	case v.Number != nil:
+		value = *v.Number
+	case v.Checked != nil:
+		value = *v.Checked
+	case v.Date != nil:
+		// dates in filters have no format
+		value = &struct {
+			*Date
+			DateFormat string `json:"date_format,omitempty"`
+		}{v.Date, v.Date.DateFormat}
+	case v.UserID != "":
+		value = &userPointer{Table: "notion_user", ID: v.UserID}
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&filterValueJSON{Type: v.Type, Value: b})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (v *FilterValue) UnmarshalJSON(b []byte) error {
+	var j filterValueJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	*v = FilterValue{Type: j.Type}
+	if len(j.Value) == 0 {
+		return nil
+	}
+	switch j.Value[0] {
+	case '"':
+		return json.Unmarshal(j.Value, &v.Text)
+	case 't', 'f':
+		return json.Unmarshal(j.Value, &v.Checked)
+	case '{':
+		var probe struct {
+			Type      string `json:"type"`
+			StartDate string `json:"start_date"`
+			Table     string `json:"table"`
+			ID        string `json:"id"`
+		}
+		json.Unmarshal(j.Value, &probe)
+		switch {
+		case probe.StartDate != "":
+			v.Date = &Date{}
+			return json.Unmarshal(j.Value, v.Date)
+		case probe.Table == "notion_user" && probe.ID != "":
+			v.UserID = probe.ID
+			return nil
+		}
+	case 'n':
+		return nil
+	default:
+		if n, err := strconv.ParseFloat(string(j.Value), 64); err == nil {
+			v.Number = &n
+			return nil
+		}
+	}
+	v.Raw = append(json.RawMessage(nil), j.Value...)
+	return nil
+}
+
+// ParseCollectionQuery parses a query2 and checks that it's well formed.
+func ParseCollectionQuery(b []byte) (*CollectionQuery, error) {
+	q := &CollectionQuery{}
+	if err := json.Unmarshal(b, q); err != nil {
+		return nil, err
+	}
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// Validate checks that q is well formed: groups combine filters with "and"
+// or "or", conditions and sorts name a property, and sorts have a
+// direction.
+func (q *CollectionQuery) Validate() error {
+	if q.Filter != nil {
+		if err := q.Filter.validate(); err != nil {
+			return err
+		}
+	}
+	for _, s := range q.Sort {
+		if s.Property == "" {
+			return fmt.Errorf("sort without a property")
+		}
+		if s.Direction != SortAscending && s.Direction != SortDescending {
+			return fmt.Errorf("invalid direction %q of the sort by %v", s.Direction, s.Property)
+		}
+	}
+	for _, a := range q.Aggregations {
+		if a.Property == "" || a.Aggregator == "" {
+			return fmt.Errorf("aggregation needs a property and an aggregator")
+		}
+	}
+	return nil
+}
+
+func (f *QueryFilter) validate() error {
+	if !f.IsGroup() {
+		if f.Property == "" {
+			return fmt.Errorf("filter without a property")
+		}
+		if f.Filter.Operator == "" {
+			return fmt.Errorf("filter of %v without an operator", f.Property)
+		}
+		return nil
+	}
+	if f.Operator != "and" && f.Operator != "or" {
+		return fmt.Errorf("invalid operator %q of a filter group", f.Operator)
+	}
+	for _, sub := range f.Filters {
+		if err := sub.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// QuerySort sorts rows by the property with the id Property.
+type QuerySort struct {
+	Property  string `json:"property"`
+	Direction string `json:"direction"`
+}
+
+// QueryAggregation summarizes the values of the property with the id
+// Property, shown at the bottom of table views.
+type QueryAggregation struct {
+	Property string `json:"property"`
+	// e.g. "count", "sum", "percent_checked"
+	Aggregator string `json:"aggregator"`
+}
@@ -0,0 +1,72 @@
+package notiontypes
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestCollectionQueryJSON(t *testing.T) {
+	for _, src := range []string{
+		`{}`,
+		`{"sort":[{"property":"title","direction":"ascending"},{"property":"du","direction":"descending"}]}`,
+		`{"aggregations":[{"property":"title","aggregator":"count"},{"property":"pt","aggregator":"sum"}]}`,
+		`{"filter":{"operator":"and","filters":[]}}`,
+		`{"filter":{"operator":"and","filters":[{"property":"st","filter":{"operator":"enum_is","value":{"type":"exact","value":"Done"}}}]}}`,
+		`{"filter":{"operator":"or","filters":[{"property":"pt","filter":{"operator":"number_greater_than","value":{"type":"exact","value":2.5}}},{"property":"ok","filter":{"operator":"checkbox_is","value":{"type":"exact","value":false}}}]}}`,
+		`{"filter":{"operator":"and","filters":[{"property":"du","filter":{"operator":"date_is_before","value":{"type":"exact","value":{"type":"date","start_date":"2024-05-01"}}}}]}}`,
+		`{"filter":{"operator":"and","filters":[{"property":"du","filter":{"operator":"date_is_on_or_after","value":{"type":"relative","value":"one_week_ago"}}}]}}`,
+		`{"filter":{"operator":"and","filters":[{"property":"ow","filter":{"operator":"person_contains","value":{"type":"exact","value":{"table":"notion_user","id":"4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"}}}}]}}`,
+		`{"filter":{"operator":"and","filters":[{"property":"nt","filter":{"operator":"is_empty"}}]}}`,
+		`{"filter":{"operator":"and","filters":[{"property":"rl","filter":{"operator":"relation_contains","value":{"type":"exact","value":["a","b"]}}}]}}`,
+		`{"filter":{"operator":"and","filters":[{"property":"st","filter":{"operator":"enum_is","value":{"type":"exact","value":"Done"}}},{"filter":{"operator":"or","filters":[{"property":"title","filter":{"operator":"string_contains","value":{"type":"exact","value":"bug"}}},{"filter":{"operator":"and","filters":[{"property":"pt","filter":{"operator":"number_equals","value":{"type":"exact","value":0}}}]}}]}}]},"sort":[{"property":"pt","direction":"descending"}]}`,
+	} {
+		q, err := ParseCollectionQuery([]byte(src))
+		if err != nil {
+			t.Errorf("%s: %v", src, err)
+			continue
+		}
+		b, err := json.Marshal(q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got, want interface{}
+		json.Unmarshal(b, &got)
+		json.Unmarshal([]byte(src), &want)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("round trip of\n%s\ngave\n%s", src, b)
+		}
+	}
+}
+
+func TestCollectionQueryTyped(t *testing.T) {
+	q, err := ParseCollectionQuery([]byte(`{"filter":{"operator":"and","filters":[
+		{"property":"pt","filter":{"operator":"number_equals","value":{"type":"exact","value":3}}},
+		{"property":"ok","filter":{"operator":"checkbox_is","value":{"type":"exact","value":true}}},
+		{"property":"du","filter":{"operator":"date_is","value":{"type":"exact","value":{"type":"date","start_date":"2024-05-01"}}}},
+		{"property":"ow","filter":{"operator":"person_contains","value":{"type":"exact","value":{"table":"notion_user","id":"u"}}}},
+		{"filter":{"operator":"or","filters":[]}}
+	]}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := q.Filter.Filters
+	if len(f) != 5 || *f[0].Filter.Value.Number != 3 || !*f[1].Filter.Value.Checked ||
+		f[2].Filter.Value.Date.StartDate != "2024-05-01" || f[3].Filter.Value.UserID != "u" ||
+		!f[4].IsGroup() || f[4].Operator != "or" {
+		t.Errorf("unexpected filters %+v", f)
+	}
+
+	for _, src := range []string{
+		`{"filter":{"operator":"xor","filters":[]}}`,
+		`{"filter":{"operator":"and","filters":[{"property":"st","filter":{"value":{"type":"exact","value":"a"}}}]}}`,
+		`{"sort":[{"property":"st","direction":"up"}]}`,
+		`{"sort":[{"direction":"ascending"}]}`,
+		`{"aggregations":[{"property":"st"}]}`,
+		`{"filter":[]}`,
+	} {
+		if _, err := ParseCollectionQuery([]byte(src)); err == nil {
+			t.Errorf("expected error for %s", src)
+		}
+	}
+}
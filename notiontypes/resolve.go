@@ -33,17 +33,94 @@ import (
 	"strings"
 )
 
-// ResolveBlock populates a block.
-func ResolveBlock(block *Block, idToBlock map[string]*Block) error {
-	err := parseProperties(block)
-	if err != nil {
+// ParseBlock populates the fields of block derived from its properties and
+// format, without resolving its content.
+func ParseBlock(block *Block) error {
+	if err := parseProperties(block); err != nil {
 		return err
 	}
-	err = parseFormat(block)
-	if err != nil {
+	return parseFormat(block)
+}
+
+// DefaultMaxDepth is the deepest nesting of content resolved by
+// ResolveBlock.
+const DefaultMaxDepth = 256
+
+// ResolveOptions configures ResolveBlockWithOptions.
+type ResolveOptions struct {
+	// MaxDepth is the deepest nesting of content resolved, DefaultMaxDepth
+	// if zero.
+	MaxDepth int
+	// Partial keeps the ids of content missing from idToBlock, e.g.
+	// because it can't be read or was deleted, in ContentIDs, and lists
+	// them in the MissingIDs of their parent, instead of dropping them. The
+	// caller can then fetch them, see Block.ResolveMissing.
+	Partial bool
+}
+
+// ResolveError is returned for content that contains itself, e.g. a synced
+// block containing a copy of itself or a corrupted parent, or that nests
+// deeper than the max depth.
+type ResolveError struct {
+	// Chain holds the ids of the blocks from the resolved block to the
+	// offending one. For cycles, the last id is that of a block earlier in
+	// the chain.
+	Chain []string
+	// MaxDepth is the exceeded depth, zero for cycles.
+	MaxDepth int
+}
+
+func (e *ResolveError) Error() string {
+	chain := strings.Join(e.Chain, " > ")
+	if e.MaxDepth > 0 {
+		return fmt.Sprintf("notiontypes: content nests deeper than %d blocks: %s", e.MaxDepth, chain)
+	}
+	return fmt.Sprintf("notiontypes: block %s contains itself: %s", e.Chain[len(e.Chain)-1], chain)
+}
+
+// ResolveBlock populates a block, and its content from idToBlock, with the
+// default options.
+func ResolveBlock(block *Block, idToBlock map[string]*Block) error {
+	return ResolveBlockWithOptions(block, idToBlock, ResolveOptions{})
+}
+
+// ResolveBlockWithOptions populates a block, and its content from
+// idToBlock. It returns a *ResolveError for content that contains itself or
+// nests deeper than opts.MaxDepth.
+func ResolveBlockWithOptions(block *Block, idToBlock map[string]*Block, opts ResolveOptions) error {
+	if opts.MaxDepth <= 0 {
+		opts.MaxDepth = DefaultMaxDepth
+	}
+	return resolveBlock(block, idToBlock, opts, nil)
+}
+
+// resolveBlock resolves block, the content of the blocks chain.
+func resolveBlock(block *Block, idToBlock map[string]*Block, opts ResolveOptions, chain []string) error {
+	for _, id := range chain {
+		if id == block.ID {
+			return &ResolveError{Chain: append(chain[:len(chain):len(chain)], block.ID)}
+		}
+	}
+	if len(chain) >= opts.MaxDepth {
+		return &ResolveError{Chain: append(chain[:len(chain):len(chain)], block.ID), MaxDepth: opts.MaxDepth}
+	}
+	chain = append(chain[:len(chain):len(chain)], block.ID)
+	if err := ParseBlock(block); err != nil {
 		return err
 	}
 
+	if block.Type == BlockSyncedBlockCopy && block.Content == nil {
+		// share the content blocks of the original, which resolves only once
+		if original := idToBlock[block.OriginalID]; original != nil {
+			if err := resolveError(resolveBlock(original, idToBlock, opts, chain)); err != nil {
+				return err
+			}
+			block.CopyContent(original)
+		} else if opts.Partial && block.OriginalID != "" {
+			block.MissingIDs = []string{block.OriginalID}
+		}
+		return nil
+	}
 	if block.Content != nil || len(block.ContentIDs) == 0 {
 		return nil
 	}
@@ -59,7 +136,25 @@ func ResolveBlock(block *Block, idToBlock map[string]*Block) error {
 			continue
 		}
 		block.Content[i] = resolved
-		ResolveBlock(resolved, idToBlock)
+		// content that fails to parse is kept, content that contains itself
+		// isn't
+		if err := resolveError(resolveBlock(resolved, idToBlock, opts, chain)); err != nil {
+			block.Content = nil
+			return err
+		}
+	}
+	if opts.Partial && len(notResolved) > 0 {
+		block.MissingIDs = nil
+		content := block.Content[:0]
+		for i, b := range block.Content {
+			if b == nil {
+				block.MissingIDs = append(block.MissingIDs, block.ContentIDs[i])
+			} else {
+				content = append(content, b)
+			}
+		}
+		block.Content = content
+		notResolved = nil
 	}
 	// remove blocks that are not resolved
 	for idx, toRemove := range notResolved {
@@ -73,9 +168,80 @@ func ResolveBlock(block *Block, idToBlock map[string]*Block) error {
 			block.Content = append(a[:i], a[i+1:]...)
 		}
 	}
+	switch block.Type {
+	case BlockTable:
+		resolveTableRows(block)
+	case BlockColumnList:
+		resolveColumns(block)
+	}
 	return nil
 }
 
+// resolveError returns err if it's a *ResolveError, nil otherwise.
+func resolveError(err error) error {
+	if _, ok := err.(*ResolveError); ok {
+		return err
+	}
+	return nil
+}
+
+// resolveColumns fills block.Columns with the ratios of its BlockColumn
+// children. Columns without a ratio share the width left over by the
+// others.
+func resolveColumns(block *Block) {
+	block.Columns = nil
+	total, unset := 0.0, 0
+	for _, col := range block.Content {
+		c := &Column{}
+		if col.FormatColumn != nil && col.FormatColumn.ColumnRation > 0 {
+			c.Ratio = col.FormatColumn.ColumnRation
+			total += c.Ratio
+		} else {
+			unset++
+		}
+		block.Columns = append(block.Columns, c)
+	}
+	if unset > 0 {
+		rest := (1 - total) / float64(unset)
+		if rest <= 0 {
+			rest = 1 / float64(len(block.Columns))
+		}
+		total = 0
+		for _, c := range block.Columns {
+			if c.Ratio == 0 {
+				c.Ratio = rest
+			}
+			total += c.Ratio
+		}
+	}
+	for _, c := range block.Columns {
+		c.Ratio /= total
+	}
+}
+
+// resolveTableRows fills block.Rows from the cells of its BlockTableRow
+// children, which are keyed by column id.
+func resolveTableRows(block *Block) {
+	if block.FormatTable == nil || len(block.FormatTable.ColumnOrder) == 0 {
+		return
+	}
+	columns := block.FormatTable.ColumnOrder
+	block.Rows = nil
+	for _, row := range block.Content {
+		if row.Type != BlockTableRow {
+			continue
+		}
+		cells := make([][]*InlineBlock, len(columns))
+		for i, col := range columns {
+			if v, ok := row.Properties[col]; ok {
+				// empty cells fail to parse and stay empty
+				cells[i], _ = parseInlineBlocks(v)
+			}
+		}
+		block.Rows = append(block.Rows, cells)
+	}
+}
+
 func getFirstInline(inline []*InlineBlock) string {
 	if len(inline) == 0 {
 		return ""
@@ -109,7 +275,7 @@ func parseProperties(block *Block) error {
 	props := block.Properties
 
 	if title, ok := props["title"]; ok {
-		if block.Type == BlockPage {
+		if block.Type == BlockPage || block.Type == BlockBookmark {
 			block.Title, err = getFirstInlineBlock(title)
 		} else if block.Type == BlockCode {
 			block.Code, err = getFirstInlineBlock(title)
@@ -134,9 +300,9 @@ func parseProperties(block *Block) error {
 	// for BlockBookmark
 	getProp(block, "link", &block.Link)
 
-	// for BlockBookmark, BlockImage, BlockGist, BlockFile
+	// for BlockBookmark, BlockImage, BlockGist, BlockFile and embeds
 	// don't over-write if was already set from "source" json field
-	if block.Source != "" {
+	if block.Source == "" {
 		getProp(block, "source", &block.Source)
 	}
 
@@ -221,12 +387,45 @@ func parseFormat(block *Block) error {
 		if err == nil {
 			block.FormatText = &format
 		}
+	case BlockCallout:
+		var format FormatCallout
+		err = json.Unmarshal(block.FormatRaw, &format)
+		if err == nil {
+			block.FormatCallout = &format
+		}
 	case BlockVideo:
 		var format FormatVideo
 		err = json.Unmarshal(block.FormatRaw, &format)
 		if err == nil {
 			block.FormatVideo = &format
 		}
+	case BlockAudio, BlockPDF, BlockEmbed, BlockFigma, BlockTweet, BlockMaps, BlockGist:
+		var format FormatEmbed
+		err = json.Unmarshal(block.FormatRaw, &format)
+		if err == nil {
+			block.FormatEmbed = &format
+		}
+	case BlockDrive:
+		var format FormatDrive
+		err = json.Unmarshal(block.FormatRaw, &format)
+		if err == nil {
+			block.FormatDrive = &format
+			if block.Source == "" {
+				block.Source = format.DriveProperties.URL
+			}
+		}
+	case BlockCollectionView, BlockCollectionViewPage:
+		var format FormatCollectionView
+		err = json.Unmarshal(block.FormatRaw, &format)
+		if err == nil && format.Pointer != nil && block.CollectionID == "" {
+			block.CollectionID = format.Pointer.ID
+		}
+	case BlockSyncedBlockCopy:
+		var format FormatSyncedBlockCopy
+		err = json.Unmarshal(block.FormatRaw, &format)
+		if err == nil {
+			block.OriginalID = format.Pointer.ID
+		}
 	}
 
 	if err != nil {
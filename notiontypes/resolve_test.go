@@ -0,0 +1,158 @@
+package notiontypes
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestResolveBlockCycles(t *testing.T) {
+	tests := []struct {
+		name     string
+		blocks   []*Block
+		maxDepth int
+		want     *ResolveError
+	}{
+		{
+			name: "shared",
+			blocks: []*Block{
+				{ID: "page", Type: BlockPage, ContentIDs: []string{"a", "b", "a"}},
+				{ID: "a", Type: BlockText},
+				{ID: "b", Type: BlockToggle, ContentIDs: []string{"a"}},
+			},
+		},
+		{
+			name: "cycle",
+			blocks: []*Block{
+				{ID: "page", Type: BlockPage, ContentIDs: []string{"a"}},
+				{ID: "a", Type: BlockToggle, ContentIDs: []string{"b"}},
+				{ID: "b", Type: BlockToggle, ContentIDs: []string{"a"}},
+			},
+			want: &ResolveError{Chain: []string{"page", "a", "b", "a"}},
+		},
+		{
+			name: "synced copy of itself",
+			blocks: []*Block{
+				{ID: "page", Type: BlockPage, ContentIDs: []string{"s"}},
+				{ID: "s", Type: BlockSyncedBlock, ContentIDs: []string{"c"}},
+				{ID: "c", Type: BlockSyncedBlockCopy, OriginalID: "s"},
+			},
+			want: &ResolveError{Chain: []string{"page", "s", "c", "s"}},
+		},
+		{
+			name: "too deep",
+			blocks: []*Block{
+				{ID: "page", Type: BlockPage, ContentIDs: []string{"a"}},
+				{ID: "a", Type: BlockToggle, ContentIDs: []string{"b"}},
+				{ID: "b", Type: BlockToggle, ContentIDs: []string{"c"}},
+				{ID: "c", Type: BlockText},
+			},
+			maxDepth: 3,
+			want:     &ResolveError{Chain: []string{"page", "a", "b", "c"}, MaxDepth: 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blocks := map[string]*Block{}
+			for _, b := range tt.blocks {
+				blocks[b.ID] = b
+			}
+			err := ResolveBlockWithOptions(blocks["page"], blocks, ResolveOptions{MaxDepth: tt.maxDepth})
+			if tt.want == nil {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			got, ok := err.(*ResolveError)
+			if !ok || !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got error %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveBlockPartial(t *testing.T) {
+	blocks := map[string]*Block{
+		"page": {ID: "page", Type: BlockPage, ContentIDs: []string{"a", "b", "c", "d"}},
+		"a":    {ID: "a", Type: BlockText},
+		"c":    {ID: "c", Type: BlockText},
+	}
+	page := blocks["page"]
+	if err := ResolveBlockWithOptions(page, blocks, ResolveOptions{Partial: true}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"b", "d"}; !reflect.DeepEqual(page.MissingIDs, want) {
+		t.Fatalf("MissingIDs = %v, want %v", page.MissingIDs, want)
+	}
+	if len(page.Content) != 2 || len(page.ContentIDs) != 4 {
+		t.Fatalf("got %d blocks of content %v", len(page.Content), page.ContentIDs)
+	}
+	if got := MissingContent(page); len(got) != 1 || got[0] != page {
+		t.Errorf("MissingContent = %v", got)
+	}
+
+	g := &mapGetter{blocks: map[string]*Block{"b": {ID: "b", Type: BlockText}}}
+	if err := page.ResolveMissing(context.Background(), g); err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, b := range page.Content {
+		ids = append(ids, b.ID)
+	}
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(ids, want) || !reflect.DeepEqual(page.ContentIDs, want) {
+		t.Errorf("resolved content %v, ids %v, want %v", ids, page.ContentIDs, want)
+	}
+	if page.MissingIDs != nil {
+		t.Errorf("MissingIDs = %v after ResolveMissing", page.MissingIDs)
+	}
+}
+
+func TestResolveColumns(t *testing.T) {
+	blocks := map[string]*Block{
+		"list":  {ID: "list", Type: BlockColumnList, ContentIDs: []string{"left", "right"}},
+		"left":  {ID: "left", Type: BlockColumn, ContentIDs: []string{"a"}, FormatColumn: &FormatColumn{ColumnRation: 0.25}},
+		"right": {ID: "right", Type: BlockColumn, ContentIDs: []string{"b", "c"}},
+		"a":     {ID: "a", Type: BlockText},
+		"b":     {ID: "b", Type: BlockText},
+		"c":     {ID: "c", Type: BlockText},
+	}
+	list := blocks["list"]
+	if err := ResolveBlock(list, blocks); err != nil {
+		t.Fatal(err)
+	}
+	if got := []float64{list.ColumnRatio(0), list.ColumnRatio(1)}; !reflect.DeepEqual(got, []float64{0.25, 0.75}) {
+		t.Errorf("got ratios %v, want [0.25 0.75]", got)
+	}
+	// e.g. pruned
+	list.Content = list.Content[1:]
+	if got := list.ColumnRatio(0); got != 1 {
+		t.Errorf("got ratio %v of the remaining column, want 1", got)
+	}
+}
+
+func TestResolveSyncedBlockCopies(t *testing.T) {
+	blocks := map[string]*Block{
+		"page":     {ID: "page", Type: BlockPage, ContentIDs: []string{"original", "copy"}},
+		"original": {ID: "original", Type: BlockSyncedBlock, ContentIDs: []string{"a", "b"}},
+		"copy":     {ID: "copy", Type: BlockSyncedBlockCopy, OriginalID: "original"},
+		"a":        {ID: "a", Type: BlockText},
+		"b":        {ID: "b", Type: BlockText},
+	}
+	if err := ResolveBlock(blocks["page"], blocks); err != nil {
+		t.Fatal(err)
+	}
+	copied := blocks["copy"]
+	if len(copied.Content) != 2 || copied.Content[0] != blocks["a"] {
+		t.Fatalf("got copy content %v", copied.Content)
+	}
+	// as Filter.Prune does
+	copied.Content = append(copied.Content[:0], copied.Content[1])
+	var ids []string
+	for _, b := range blocks["original"].Content {
+		ids = append(ids, b.ID)
+	}
+	if !reflect.DeepEqual(ids, []string{"a", "b"}) {
+		t.Errorf("pruning the copy changed the original to %v", ids)
+	}
+}
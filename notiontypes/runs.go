@@ -0,0 +1,108 @@
+package notiontypes
+
+import "unicode/utf8"
+
+// The functions below edit text made of InlineBlocks (runs) by offsets
+// counted in runes, so that runs are never split inside a multi-byte
+// character. They don't modify the runs passed to them, but the runs they
+// return may share runs, and their Dates, with them.
+
+// TextLength returns the length of the text of runs in runes.
+func TextLength(runs []*InlineBlock) int {
+	n := 0
+	for _, r := range runs {
+		n += utf8.RuneCountInString(r.Text)
+	}
+	return n
+}
+
+// SplitInlineBlocks splits runs at offset, splitting the run containing
+// offset into two runs with the same attributes. Offsets past the end of
+// the text split it at its end.
+func SplitInlineBlocks(runs []*InlineBlock, offset int) (before, after []*InlineBlock) {
+	for i, r := range runs {
+		n := utf8.RuneCountInString(r.Text)
+		switch {
+		case offset <= 0:
+			return runs[:i:i], runs[i:]
+		case offset < n:
+			split := runeOffset(r.Text, offset)
+			left, right := *r, *r
+			left.Text, right.Text = r.Text[:split], r.Text[split:]
+			before = append(append([]*InlineBlock(nil), runs[:i]...), &left)
+			after = append([]*InlineBlock{&right}, runs[i+1:]...)
+			return before, after
+		}
+		offset -= n
+	}
+	return runs, nil
+}
+
+// runeOffset returns the byte offset of the n-th rune of s.
+func runeOffset(s string, n int) int {
+	for i := range s {
+		if n == 0 {
+			return i
+		}
+		n--
+	}
+	return len(s)
+}
+
+// ReplaceRange returns runs with the text between the rune offsets start
+// and end replaced by insert, e.g. to replace a match of a search or to
+// insert a mention at a cursor (start == end).
+func ReplaceRange(runs []*InlineBlock, start, end int, insert ...*InlineBlock) []*InlineBlock {
+	if end < start {
+		end = start
+	}
+	before, rest := SplitInlineBlocks(runs, start)
+	_, after := SplitInlineBlocks(rest, end-start)
+	res := make([]*InlineBlock, 0, len(before)+len(insert)+len(after))
+	res = append(append(append(res, before...), insert...), after...)
+	return MergeInlineBlocks(res)
+}
+
+// FormatRange returns runs with fn applied to copies of the runs between
+// the rune offsets start and end, e.g. to make characters 5 to 12 bold:
+//
+//	runs = FormatRange(runs, 5, 12, func(r *InlineBlock) { r.AttrFlags |= AttrBold })
+func FormatRange(runs []*InlineBlock, start, end int, fn func(*InlineBlock)) []*InlineBlock {
+	if end <= start {
+		return runs
+	}
+	before, rest := SplitInlineBlocks(runs, start)
+	middle, after := SplitInlineBlocks(rest, end-start)
+	formatted := make([]*InlineBlock, len(middle))
+	for i, r := range middle {
+		c := *r
+		fn(&c)
+		formatted[i] = &c
+	}
+	return ReplaceRange(append(before[:len(before):len(before)], after...), start, start, formatted...)
+}
+
+// MergeInlineBlocks returns runs with empty runs dropped and adjacent runs
+// with the same attributes merged. Mentions of users and dates are never
+// merged.
+func MergeInlineBlocks(runs []*InlineBlock) []*InlineBlock {
+	res := make([]*InlineBlock, 0, len(runs))
+	for _, r := range runs {
+		if r.Text == "" {
+			continue
+		}
+		if n := len(res); n > 0 && sameAttributes(res[n-1], r) {
+			merged := *res[n-1]
+			merged.Text += r.Text
+			res[n-1] = &merged
+			continue
+		}
+		res = append(res, r)
+	}
+	return res
+}
+
+func sameAttributes(a, b *InlineBlock) bool {
+	return a.AttrFlags == b.AttrFlags && a.Link == b.Link &&
+		a.UserID == "" && b.UserID == "" && a.PageID == "" && b.PageID == "" && a.Date == nil && b.Date == nil
+}
@@ -0,0 +1,51 @@
+package notiontypes
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFormatRange(t *testing.T) {
+	runs := []*InlineBlock{
+		{Text: "Grüße, "},
+		{Text: "wörld", Link: "https://example.com"},
+		{Text: InlineAt, UserID: "aa8fc126-6770-4e83-ad6c-3968dcfc9b82"},
+	}
+	if n := TextLength(runs); n != 13 {
+		t.Errorf("TextLength = %d, want 13", n)
+	}
+	got := FormatRange(runs, 2, 9, func(r *InlineBlock) { r.AttrFlags |= AttrBold })
+	want := []*InlineBlock{
+		{Text: "Gr"},
+		{Text: "üße, ", AttrFlags: AttrBold},
+		{Text: "wö", AttrFlags: AttrBold, Link: "https://example.com"},
+		{Text: "rld", Link: "https://example.com"},
+		runs[2],
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FormatRange = %s, want %s", texts(got), texts(want))
+	}
+	if runs[0].Text != "Grüße, " || runs[1].AttrFlags != 0 {
+		t.Error("FormatRange modified its argument")
+	}
+
+	got = FormatRange(got, 0, 6, func(r *InlineBlock) { r.AttrFlags &^= AttrBold })
+	if got[0].Text != "Grüße," || got[1].Text != " " || got[1].AttrFlags != AttrBold {
+		t.Errorf("removing bold: got %s", texts(got))
+	}
+
+	mention := &InlineBlock{Text: InlineAt, UserID: "bb8fc126-6770-4e83-ad6c-3968dcfc9b82"}
+	got = ReplaceRange(runs, 7, 12, &InlineBlock{Text: "hi "}, mention)
+	want = []*InlineBlock{{Text: "Grüße, hi "}, mention, runs[2]}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReplaceRange = %s, want %s", texts(got), texts(want))
+	}
+}
+
+func texts(runs []*InlineBlock) []string {
+	var res []string
+	for _, r := range runs {
+		res = append(res, r.Text)
+	}
+	return res
+}
@@ -0,0 +1,39 @@
+package notiontypes
+
+import (
+	"github.com/tmc/notion/jsonschema"
+)
+
+//go:generate go run gen_schema.go
+
+// schemaTypes are the types described by Schema and TypeScript: the
+// records of the API and the values resolved from them, as found in
+// exports and backups.
+var schemaTypes = []interface{}{
+	RecordMap{},
+	Block{},
+	InlineBlock{},
+	PageProperty{},
+	Subscription{},
+}
+
+func schemaGenerator() *jsonschema.Generator {
+	g := jsonschema.New()
+	for _, v := range schemaTypes {
+		g.Add(v)
+	}
+	return g
+}
+
+// Schema returns a JSON Schema (draft 2020-12) with the definitions of the
+// types of this package under "$defs", e.g. "#/$defs/Block". It is
+// published as notiontypes.schema.json next to this package.
+func Schema() ([]byte, error) {
+	return schemaGenerator().JSON("notiontypes", "")
+}
+
+// TypeScript returns TypeScript definitions of the types of Schema. They
+// are published as notiontypes.d.ts next to this package.
+func TypeScript() []byte {
+	return append([]byte("// Code generated by gen_schema.go. DO NOT EDIT.\n\n"), schemaGenerator().TypeScript()...)
+}
@@ -0,0 +1,44 @@
+package notiontypes
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestSchema(t *testing.T) {
+	schema, err := Schema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for file, got := range map[string][]byte{"notiontypes.schema.json": schema, "notiontypes.d.ts": TypeScript()} {
+		want, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%v is out of date, run go generate", file)
+		}
+	}
+
+	var s struct {
+		Defs map[string]struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(schema, &s); err != nil {
+		t.Fatal(err)
+	}
+	block := s.Defs["Block"].Properties
+	if got := string(block["content_resolved"]); !strings.Contains(got, `"#/$defs/Block"`) {
+		t.Errorf("Block.content_resolved has schema %s", got)
+	}
+	if got := string(block["format"]); got != "{}" {
+		t.Errorf("raw Block.format has schema %s, want any", got)
+	}
+	if !strings.Contains(string(TypeScript()), "export interface Block {\n  alive: boolean;\n") {
+		t.Error("TypeScript lacks Block interface")
+	}
+}
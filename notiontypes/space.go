@@ -8,10 +8,90 @@ type SpaceWithRole struct {
 
 // Space is a notion.so workspace.
 type Space struct {
-	ID          string        `json:"id"`
-	Version     float64       `json:"version"`
-	Name        string        `json:"name"`
-	BetaEnabled bool          `json:"beta_enabled"`
+	ID          string  `json:"id"`
+	Version     float64 `json:"version"`
+	Name        string  `json:"name"`
+	Icon        string  `json:"icon,omitempty"`
+	Domain      string  `json:"domain,omitempty"`
+	BetaEnabled bool    `json:"beta_enabled"`
+	// PlanType is e.g. "personal" or "team".
+	PlanType string `json:"plan_type,omitempty"`
+	// Permissions are the roles of the members of the workspace.
 	Permissions *[]Permission `json:"permissions,omitempty"`
-	Pages       []string      `json:"pages,omitempty"`
+	// PermissionGroups are the groups of members pages can be shared with.
+	PermissionGroups []*PermissionGroup `json:"permission_groups,omitempty"`
+	Pages            []string           `json:"pages,omitempty"`
+
+	InviteLinkEnabled   bool `json:"invite_link_enabled,omitempty"`
+	DisablePublicAccess bool `json:"disable_public_access,omitempty"`
+	DisableGuests       bool `json:"disable_guests,omitempty"`
+	DisableMoveToSpace  bool `json:"disable_move_to_space,omitempty"`
+	DisableExport       bool `json:"disable_export,omitempty"`
+
+	CreatedByID string `json:"created_by_id,omitempty"`
+	CreatedTime int64  `json:"created_time,omitempty"`
+}
+
+// Members returns the ids and roles of the users that are members of the
+// workspace, by user id.
+func (s *Space) Members() map[string]string {
+	members := make(map[string]string)
+	if s.Permissions == nil {
+		return members
+	}
+	for _, p := range *s.Permissions {
+		if p.UserID != nil {
+			members[*p.UserID] = p.Role
+		}
+	}
+	return members
+}
+
+// PermissionGroup is a group of members of a workspace.
+type PermissionGroup struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Icon    string   `json:"icon,omitempty"`
+	UserIDs []string `json:"user_ids"`
+}
+
+// Group returns the group of s with the given id or name, or nil.
+func (s *Space) Group(idOrName string) *PermissionGroup {
+	for _, g := range s.PermissionGroups {
+		if g.ID == idOrName || g.Name == idOrName {
+			return g
+		}
+	}
+	return nil
+}
+
+// Subscription describes the plan of a workspace.
+type Subscription struct {
+	// Type is the plan, e.g. "personal", "team" or "enterprise".
+	Type    string         `json:"type"`
+	Members []*SpaceMember `json:"members"`
+	// JoinedMemberIDs are the ids of members that accepted their invitation.
+	JoinedMemberIDs []string `json:"joinedMemberIds,omitempty"`
+	// BlockUsage is the number of blocks of the workspace, which is limited
+	// on free plans.
+	BlockUsage      int64 `json:"blockUsage"`
+	HasPaidNonzero  bool  `json:"hasPaidNonzero"`
+	IsDelinquent    bool  `json:"isDelinquent"`
+	CreditEnabled   bool  `json:"creditEnabled"`
+	AvailableCredit int64 `json:"availableCredit"`
+	// BillingEmail is only visible to admins.
+	BillingEmail string `json:"billingEmail,omitempty"`
+}
+
+// SpaceMember is a member or guest of a workspace.
+type SpaceMember struct {
+	UserID string `json:"userId"`
+	Role   string `json:"role"`
+	// GuestPageIDs are the pages shared with guests.
+	GuestPageIDs []string `json:"guestPageIds,omitempty"`
+}
+
+// IsGuest reports whether m is a guest rather than a member.
+func (m *SpaceMember) IsGuest() bool {
+	return len(m.GuestPageIDs) > 0
 }
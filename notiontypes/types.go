@@ -26,6 +26,12 @@
 
 package notiontypes
 
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
 // RecordMap contains a collections of blocks, a space, users, and collections.
 type RecordMap struct {
 	Blocks          map[string]*BlockWithRole          `json:"block"`
@@ -33,6 +39,7 @@ type RecordMap struct {
 	Users           map[string]*UserWithRole           `json:"notion_user"`
 	Collections     map[string]*CollectionWithRole     `json:"collection"`
 	CollectionViews map[string]*CollectionViewWithRole `json:"collection_view"`
+	Activities      map[string]*ActivityWithRole       `json:"activity,omitempty"`
 }
 
 // CollectionViewWithRole describes a role and a collection view
@@ -51,14 +58,40 @@ type CollectionView struct {
 	ParentID    string                `json:"parent_id"`
 	ParentTable string                `json:"parent_table"`
 	Query       *CollectionViewQuery  `json:"query"`
-	Type        string                `json:"type"`
-	Version     int                   `json:"version"`
+	Query2      *CollectionQuery      `json:"query2,omitempty"`
+	// ViewTable, ViewBoard etc.
+	Type    string `json:"type"`
+	Version int    `json:"version"`
 }
 
 // CollectionViewFormat describes a fomrat of a collection view
 type CollectionViewFormat struct {
 	TableProperties []*TableProperty `json:"table_properties"`
 	TableWrap       bool             `json:"table_wrap"`
+
+	// the properties shown by the other types of views
+	BoardProperties    []*TableProperty `json:"board_properties,omitempty"`
+	CalendarProperties []*TableProperty `json:"calendar_properties,omitempty"`
+	ListProperties     []*TableProperty `json:"list_properties,omitempty"`
+	GalleryProperties  []*TableProperty `json:"gallery_properties,omitempty"`
+	TimelineProperties []*TableProperty `json:"timeline_properties,omitempty"`
+
+	// for ViewBoard, the property whose values are the columns
+	BoardColumnsBy *ViewGroupBy `json:"board_columns_by,omitempty"`
+	// for other views, the property grouping rows, if any
+	CollectionGroupBy *ViewGroupBy `json:"collection_group_by,omitempty"`
+	// for ViewCalendar and ViewTimeline, the id of the date property
+	// placing rows
+	CalendarBy string `json:"calendar_by,omitempty"`
+	TimelineBy string `json:"timeline_by,omitempty"`
+}
+
+// ViewGroupBy describes how a view groups rows, e.g. into the columns of
+// a board
+type ViewGroupBy struct {
+	// the type of the property, e.g. "select"
+	Type     string `json:"type"`
+	Property string `json:"property"`
 }
 
 // CollectionViewQuery describes a query
@@ -109,6 +142,8 @@ type CollectionColumnInfo struct {
 	Name    string                    `json:"name"`
 	Options []*CollectionColumnOption `json:"options"`
 	Type    string                    `json:"type"`
+	// CollectionID is the collection of the pages of relation properties.
+	CollectionID string `json:"collection_id,omitempty"`
 }
 
 // CollectionColumnOption describes options for a collection column
@@ -138,6 +173,15 @@ type User struct {
 	Version                   int    `json:"version"`
 }
 
+// Name returns the full name of the user, or their email if they have no name.
+func (u *User) Name() string {
+	name := strings.TrimSpace(u.GivenName + " " + u.FamilyName)
+	if name == "" {
+		return u.Email
+	}
+	return name
+}
+
 // Date describes a date
 type Date struct {
 	// "MMM DD, YYYY", "MM/DD/YYYY", "DD/MM/YYYY", "YYYY/MM/DD", "relative"
@@ -161,3 +205,48 @@ type Reminder struct {
 	Unit  string `json:"unit"` // e.g. "day"
 	Value int64  `json:"value"`
 }
+
+// Start returns the start of d. Dates without a time zone are in loc, and
+// dates without a time start at midnight.
+func (d *Date) Start(loc *time.Location) (time.Time, error) {
+	if d.TimeZone != nil && *d.TimeZone != "" {
+		if tz, err := time.LoadLocation(*d.TimeZone); err == nil {
+			loc = tz
+		}
+	}
+	if d.StartTime != nil && *d.StartTime != "" {
+		return time.ParseInLocation("2006-01-02 15:04", d.StartDate+" "+*d.StartTime, loc)
+	}
+	return time.ParseInLocation("2006-01-02", d.StartDate, loc)
+}
+
+// RemindAt returns when notion reminds of d, and false if d has no
+// reminder. Reminders in days or weeks are at the reminder's time of day,
+// others are relative to the start of d.
+func (d *Date) RemindAt(loc *time.Location) (time.Time, bool, error) {
+	if d.Reminder == nil {
+		return time.Time{}, false, nil
+	}
+	start, err := d.Start(loc)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	r := d.Reminder
+	n := int(r.Value)
+	switch r.Unit {
+	case "minute":
+		return start.Add(-time.Duration(n) * time.Minute), true, nil
+	case "hour":
+		return start.Add(-time.Duration(n) * time.Hour), true, nil
+	case "day", "week":
+		if r.Unit == "week" {
+			n *= 7
+		}
+		day := start.AddDate(0, 0, -n)
+		if t, err := time.Parse("15:04", r.Time); err == nil {
+			day = time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, day.Location())
+		}
+		return day, true, nil
+	}
+	return time.Time{}, false, fmt.Errorf("notiontypes: unknown reminder unit %q", r.Unit)
+}
@@ -2,6 +2,8 @@ package notion
 
 import (
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
@@ -40,6 +42,9 @@ func WithLogger(logger Logger) ClientOption {
 }
 
 // WithDebugLogging attaches a debug-level logger to the client.
+//
+// The request and response bodies logged at debug level have tokens and
+// email addresses masked, see also WithRedactedProperties and WithLogBodyLimit.
 func WithDebugLogging() ClientOption {
 	return func(c *Client) {
 		logger := logrus.New()
@@ -47,3 +52,52 @@ func WithDebugLogging() ClientOption {
 		c.logger = &WrapLogrus{logger}
 	}
 }
+
+// Clock tells the time.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// WithClock sets the clock used for the timestamps of created records, so
+// that tests and replay tools can produce deterministic transactions.
+func WithClock(clock Clock) ClientOption {
+	return func(c *Client) {
+		c.clock = clock
+	}
+}
+
+// WithIDGenerator sets the function generating the ids of created records.
+// It defaults to NewBlockID.
+func WithIDGenerator(fn func() string) ClientOption {
+	return func(c *Client) {
+		c.newID = fn
+	}
+}
+
+// WithRedactedProperties masks the values of the given JSON keys, such as the
+// ids of sensitive database properties, in logged bodies. Keys are matched
+// case-insensitively.
+func WithRedactedProperties(names ...string) ClientOption {
+	return func(c *Client) {
+		if c.redactedProperties == nil {
+			c.redactedProperties = make(map[string]bool)
+		}
+		for _, n := range names {
+			c.redactedProperties[strings.ToLower(n)] = true
+		}
+	}
+}
+
+// WithLogBodyLimit truncates logged bodies to n bytes. Zero, the default,
+// logs bodies in full.
+func WithLogBodyLimit(n int) ClientOption {
+	return func(c *Client) {
+		c.logBodyLimit = n
+	}
+}
@@ -0,0 +1,215 @@
+package notion
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// Reasons of orphans.
+const (
+	// OrphanUnlisted is a block whose parent is alive but doesn't list it
+	// in its content.
+	OrphanUnlisted = "unlisted"
+	// OrphanDeadParent is a block whose parent was deleted or doesn't
+	// exist. Blocks of pages in the trash aren't orphans: they come back
+	// with their page when it's restored.
+	OrphanDeadParent = "dead-parent"
+)
+
+// Repairs of orphans, see RepairOrphans.
+const (
+	// RepairReattach adds unlisted blocks at the end of the content of
+	// their parent, and archives the blocks of dead parents.
+	RepairReattach = "reattach"
+	// RepairArchive archives (deletes) orphans.
+	RepairArchive = "archive"
+)
+
+// Orphan is a live block that can't be reached from its parent, usually a
+// sign of a failed or partial edit.
+type Orphan struct {
+	Block  *notiontypes.Block `json:"block"`
+	Reason string             `json:"reason"`
+	// Parent is nil if it doesn't exist.
+	Parent *notiontypes.Block `json:"parent,omitempty"`
+}
+
+// OrphanOptions configures FindOrphans.
+type OrphanOptions struct {
+	// Filter selects the crawled pages.
+	Filter *Filter
+	// Since limits the blocks checked to those edited since, according to
+	// the activity log. The zero value checks the whole log.
+	Since time.Time
+	// Candidates are the ids of further blocks to check, e.g. the blocks
+	// of a backup.
+	Candidates []string
+}
+
+// FindOrphans crawls the page rootID and its sub-pages, and returns the
+// blocks edited on them, according to the activity log, or given as
+// candidates, that their parent no longer lists or whose parent is dead.
+// Blocks are checked against their parents, so that orphans whose parent
+// is itself an orphan are found as well. Pages and blocks that can't be
+// read are skipped with a warning, and so are their children.
+func (c *Client) FindOrphans(rootID string, opts OrphanOptions) ([]*Orphan, error) {
+	// listed holds the blocks reachable from the root
+	listed := map[string]bool{}
+	var spaceID string
+	r, err := c.CrawlWithOptions(rootID, CrawlOptions{Filter: opts.Filter}, func(p *Page, ancestors []string) error {
+		if spaceID == "" {
+			spaceID = p.SpaceID
+		}
+		var walk func(b *notiontypes.Block)
+		walk = func(b *notiontypes.Block) {
+			listed[b.ID] = true
+			for _, child := range b.Content {
+				walk(child)
+			}
+		}
+		walk(p.Block)
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "crawling %v", rootID)
+	}
+	c.warnSkipped(r)
+	// the blocks of unreadable parents can't be checked
+	skipped := map[string]bool{}
+	for _, b := range r.Skipped {
+		skipped[b.ID] = true
+	}
+
+	var ids []string
+	seen := map[string]bool{}
+	add := func(id string) {
+		if id != "" && !seen[id] && !listed[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if spaceID != "" {
+		activities, err := c.GetActivityLog(spaceID, rootID, opts.Since)
+		if err != nil {
+			return nil, errors.Wrap(err, "getting activity log")
+		}
+		for _, a := range activities {
+			for _, e := range a.Edits {
+				add(e.BlockID)
+			}
+		}
+	}
+	for _, id := range opts.Candidates {
+		if id, err := FormatID(id); err == nil {
+			add(id)
+		}
+	}
+
+	blocks := map[string]*notiontypes.Block{}
+	if err := c.loadBlocks(blocks, ids); err != nil {
+		return nil, err
+	}
+	var parents []string
+	for _, id := range ids {
+		if b := blocks[id]; b != nil && b.ParentTable == notiontypes.TableBlock {
+			if _, ok := blocks[b.ParentID]; !ok && !seen[b.ParentID] {
+				seen[b.ParentID] = true
+				parents = append(parents, b.ParentID)
+			}
+		}
+	}
+	if err := c.loadBlocks(blocks, parents); err != nil {
+		return nil, err
+	}
+
+	var orphans []*Orphan
+	for _, id := range ids {
+		b := blocks[id]
+		if b == nil || !b.Alive || b.ParentTable != notiontypes.TableBlock || skipped[b.ID] || skipped[b.ParentID] {
+			continue
+		}
+		parent := blocks[b.ParentID]
+		switch {
+		case parent != nil && !parent.Alive && parent.IsPage():
+			// in the trash
+		case parent == nil || !parent.Alive:
+			orphans = append(orphans, &Orphan{Block: b, Reason: OrphanDeadParent, Parent: parent})
+		case !contains(parent.ContentIDs, b.ID):
+			orphans = append(orphans, &Orphan{Block: b, Reason: OrphanUnlisted, Parent: parent})
+		}
+	}
+	return orphans, nil
+}
+
+// loadBlocks adds the records of the blocks ids, loaded in batches, to
+// blocks, with nil for the blocks that don't exist.
+func (c *Client) loadBlocks(blocks map[string]*notiontypes.Block, ids []string) error {
+	const batch = 100
+	for len(ids) > 0 {
+		n := len(ids)
+		if n > batch {
+			n = batch
+		}
+		records := make([]Record, n)
+		for i, id := range ids[:n] {
+			records[i] = Record{Table: notiontypes.TableBlock, ID: id}
+			blocks[id] = nil
+		}
+		results, err := c.GetRecordValues(records...)
+		if err != nil {
+			return errors.Wrap(err, "getting blocks")
+		}
+		for _, r := range results {
+			if r.Value == nil {
+				continue
+			}
+			if err := notiontypes.ParseBlock(r.Value); err != nil {
+				return errors.Wrapf(err, "parsing block %v", r.Value.ID)
+			}
+			blocks[r.Value.ID] = r.Value
+		}
+		ids = ids[n:]
+	}
+	return nil
+}
+
+// RepairOrphans repairs orphans found by FindOrphans with the given repair,
+// RepairReattach or RepairArchive, in a single transaction.
+func (c *Client) RepairOrphans(orphans []*Orphan, repair string) error {
+	if repair != RepairReattach && repair != RepairArchive {
+		return errors.Errorf("notion: unknown repair %q", repair)
+	}
+	now := c.clock.Now().UnixNano() / int64(time.Millisecond)
+	var ops []*operation
+	for _, o := range orphans {
+		if repair == RepairReattach && o.Reason == OrphanUnlisted {
+			ops = append(ops, &operation{
+				ID:      o.Parent.ID,
+				Table:   notiontypes.TableBlock,
+				Path:    []string{"content"},
+				Command: "listAfter",
+				Args:    map[string]string{"id": o.Block.ID},
+			}, &operation{
+				ID:      o.Parent.ID,
+				Table:   notiontypes.TableBlock,
+				Path:    []string{"last_edited_time"},
+				Command: "set",
+				Args:    now,
+			})
+			continue
+		}
+		ops = append(ops, &operation{
+			ID:      o.Block.ID,
+			Table:   notiontypes.TableBlock,
+			Path:    []string{},
+			Command: "update",
+			Args:    map[string]interface{}{"alive": false, "last_edited_time": now},
+		})
+	}
+	if len(ops) == 0 {
+		return nil
+	}
+	return c.submitTransaction(ops...)
+}
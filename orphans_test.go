@@ -0,0 +1,104 @@
+package notion_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/notiontest"
+	"github.com/tmc/notion/notiontypes"
+)
+
+func TestFindOrphans(t *testing.T) {
+	s := notiontest.NewServer()
+	defer s.Close()
+	const (
+		root     = "0b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d60"
+		listed   = "0b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d61"
+		unlisted = "0b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d62"
+		deleted  = "0b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d63"
+		dead     = "0b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d64"
+		stranded = "0b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d65"
+		trashed  = "0b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d66"
+		inTrash  = "0b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d67"
+	)
+	s.AddBlock(&notiontypes.Block{ID: root, Type: notiontypes.BlockPage, SpaceID: "space", ContentIDs: []string{listed}})
+	s.AddBlock(&notiontypes.Block{ID: listed, Type: notiontypes.BlockText, ParentID: root, ParentTable: notiontypes.TableBlock})
+	s.AddBlock(&notiontypes.Block{ID: unlisted, Type: notiontypes.BlockText, ParentID: root, ParentTable: notiontypes.TableBlock})
+	s.AddRecord(notiontypes.TableBlock, deleted, map[string]interface{}{
+		"type": notiontypes.BlockText, "alive": false, "parent_id": root, "parent_table": notiontypes.TableBlock,
+	})
+	s.AddRecord(notiontypes.TableBlock, dead, map[string]interface{}{
+		"type": notiontypes.BlockToggle, "alive": false, "content": []string{stranded},
+	})
+	s.AddBlock(&notiontypes.Block{ID: stranded, Type: notiontypes.BlockText, ParentID: dead, ParentTable: notiontypes.TableBlock})
+	// restored with its page
+	s.AddRecord(notiontypes.TableBlock, trashed, map[string]interface{}{
+		"type": notiontypes.BlockPage, "alive": false, "content": []string{inTrash},
+	})
+	s.AddBlock(&notiontypes.Block{ID: inTrash, Type: notiontypes.BlockText, ParentID: trashed, ParentTable: notiontypes.TableBlock})
+	s.AddRecord("activity", "a1", map[string]interface{}{
+		"space_id": "space", "navigable_block_id": root, "end_time": "1",
+		"edits": []interface{}{
+			map[string]interface{}{"block_id": listed},
+			map[string]interface{}{"block_id": unlisted},
+			map[string]interface{}{"block_id": deleted},
+		},
+	})
+	c := s.Client()
+
+	orphans, err := c.FindOrphans(root, notion.OrphanOptions{Candidates: []string{stranded, inTrash}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got [][2]string
+	for _, o := range orphans {
+		got = append(got, [2]string{o.Block.ID, o.Reason})
+	}
+	want := [][2]string{{unlisted, notion.OrphanUnlisted}, {stranded, notion.OrphanDeadParent}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("FindOrphans = %v, want %v", got, want)
+	}
+
+	if err := c.RepairOrphans(orphans, notion.RepairReattach); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(s.Transactions()); n != 1 {
+		t.Errorf("repaired in %d transactions, want 1", n)
+	}
+	if ids := s.Block(root).ContentIDs; !reflect.DeepEqual(ids, []string{listed, unlisted}) {
+		t.Errorf("root content = %v, want %v", ids, []string{listed, unlisted})
+	}
+	if s.Block(stranded).Alive {
+		t.Error("block of a dead parent wasn't archived")
+	}
+	if !s.Block(inTrash).Alive {
+		t.Error("block of a page in the trash was archived")
+	}
+	if orphans, err := c.FindOrphans(root, notion.OrphanOptions{Candidates: []string{stranded, inTrash}}); err != nil || len(orphans) != 0 {
+		t.Errorf("FindOrphans after repair = %v, %v", orphans, err)
+	}
+}
+
+func TestFindOrphansUnreadable(t *testing.T) {
+	s := notiontest.NewServer()
+	defer s.Close()
+	const (
+		root    = "0b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d60"
+		private = "0b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d61"
+		shared  = "0b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d62"
+	)
+	s.AddBlock(&notiontypes.Block{ID: root, Type: notiontypes.BlockPage, SpaceID: "space", ContentIDs: []string{private}})
+	s.AddBlock(&notiontypes.Block{ID: private, Type: notiontypes.BlockPage, ParentID: root, ParentTable: notiontypes.TableBlock, ContentIDs: []string{shared}})
+	s.AddBlock(&notiontypes.Block{ID: shared, Type: notiontypes.BlockText, ParentID: private, ParentTable: notiontypes.TableBlock})
+	s.SetRole(private, notiontypes.RoleNone)
+	s.SetRole(shared, notiontypes.RoleReader)
+
+	orphans, err := s.Client().FindOrphans(root, notion.OrphanOptions{Candidates: []string{shared}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orphans) != 0 {
+		t.Errorf("got orphans %+v in a page that can't be read", orphans[0])
+	}
+}
@@ -0,0 +1,86 @@
+// Package pagination holds the cursors notion's API pages through records
+// with, e.g. the blocks of large pages returned by loadPageChunk.
+//
+// A Cursor is opaque to most callers: start with Start, pass the cursor
+// returned with each chunk to the next request, and stop once it's Done.
+// Cursors can be serialized with Encode and Decode to resume loading later,
+// e.g. in another process.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// StackPosition is a position within the content of a record: the entry
+// Index of the content of the record ID of Table (usually "block").
+type StackPosition struct {
+	ID    string  `json:"id,omitempty"`
+	Index float64 `json:"index,omitempty"`
+	Table string  `json:"table,omitempty"`
+}
+
+// Cursor is the position up to which records have been loaded. Its Stack
+// holds the positions still to be visited, each a path from the page down
+// to the block whose content loading continues in. An empty stack is both
+// the start and, when returned by the API, the end of a listing.
+type Cursor struct {
+	Stack [][]StackPosition `json:"stack"`
+}
+
+// Start returns the cursor of the first chunk.
+func Start() Cursor {
+	return Cursor{Stack: [][]StackPosition{}}
+}
+
+// Done reports whether c, as returned with a chunk, marks the last chunk.
+func (c Cursor) Done() bool {
+	return len(c.Stack) == 0
+}
+
+// MarshalJSON encodes c, with an empty stack rather than null for the zero
+// Cursor as the API requires.
+func (c Cursor) MarshalJSON() ([]byte, error) {
+	type cursor Cursor
+	if c.Stack == nil {
+		c.Stack = [][]StackPosition{}
+	}
+	return json.Marshal(cursor(c))
+}
+
+// Encode returns c as an opaque URL-safe token.
+func (c Cursor) Encode() (string, error) {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// String returns the token of c, or "" if it can't be encoded.
+func (c Cursor) String() string {
+	s, _ := c.Encode()
+	return s
+}
+
+// Decode returns the cursor of a token returned by Encode. The empty token
+// decodes to the start.
+func Decode(token string) (Cursor, error) {
+	if token == "" {
+		return Start(), nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return Cursor{}, errors.Wrap(err, "pagination: invalid cursor")
+	}
+	var c Cursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return Cursor{}, errors.Wrap(err, "pagination: invalid cursor")
+	}
+	if c.Stack == nil {
+		c.Stack = [][]StackPosition{}
+	}
+	return c, nil
+}
@@ -0,0 +1,43 @@
+package pagination
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestCursor(t *testing.T) {
+	b, err := json.Marshal(Cursor{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"stack":[]}` {
+		t.Errorf("zero cursor encodes as %s", b)
+	}
+	if !Start().Done() {
+		t.Error("start cursor isn't done")
+	}
+
+	c := Cursor{Stack: [][]StackPosition{{
+		{ID: "4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e", Index: 50, Table: "block"},
+	}}}
+	token, err := c.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Decode(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, c) || got.Done() {
+		t.Errorf("decoded %+v, want %+v", got, c)
+	}
+	if got, err := Decode(""); err != nil || !reflect.DeepEqual(got, Start()) {
+		t.Errorf("empty token decodes to %+v, %v", got, err)
+	}
+	for _, token := range []string{"not a cursor", "bm90IGpzb24"} {
+		if _, err := Decode(token); err == nil {
+			t.Errorf("Decode(%q) succeeded", token)
+		}
+	}
+}
@@ -0,0 +1,314 @@
+// Package preview serves the HTML export of a page and its sub-pages, and
+// updates the pages open in browsers as they're edited in notion.
+//
+// Changes are pushed over a WebSocket as the HTML fragments of the changed
+// blocks (see export.HTML.Update), or as a request to reload the page if
+// they can't be rendered as fragments, e.g. when pages are added.
+package preview
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/export"
+	"github.com/tmc/notion/notiontypes"
+	"github.com/tmc/notion/watch"
+)
+
+// SocketPath is the path of the WebSocket of a Server.
+const SocketPath = "/_preview"
+
+// Message is sent to browsers over the WebSocket.
+type Message struct {
+	// Fragments replace the HTML of blocks with the same ids.
+	Fragments []*export.HTMLFragment `json:"fragments,omitempty"`
+	// Reload asks for the page to be loaded again.
+	Reload bool `json:"reload,omitempty"`
+}
+
+// Server is an http.Handler serving the export of a page. It's a
+// watch.Sink, which updates the export on the events of a watch.Watcher
+// of the page.
+type Server struct {
+	client *notion.Client
+	rootID string
+	dir    string
+	html   *export.HTML
+
+	// exportMu serializes the exports and updates of the files, which
+	// fetch pages, so that they don't hold up requests
+	exportMu sync.Mutex
+
+	mu       sync.Mutex // guards exporter and clients
+	exporter *export.Exporter
+	clients  map[*wsConn]bool
+}
+
+// NewServer returns a Server for the page rootID and its sub-pages, which
+// are exported into dir with h. BlockMarkers is set on h.
+func NewServer(c *notion.Client, rootID, dir string, h *export.HTML) *Server {
+	h.BlockMarkers = true
+	return &Server{client: c, rootID: rootID, dir: dir, html: h, clients: make(map[*wsConn]bool)}
+}
+
+// Export exports the pages again and asks browsers to reload them.
+func (s *Server) Export() error {
+	s.exportMu.Lock()
+	defer s.exportMu.Unlock()
+	if err := s.export(); err != nil {
+		return err
+	}
+	s.broadcast(&Message{Reload: true})
+	return nil
+}
+
+func (s *Server) export() error {
+	e := export.NewExporter(s.client, s.dir, export.WithRenderer(s.html))
+	if err := e.Export(s.rootID); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.exporter = e
+	s.mu.Unlock()
+	return nil
+}
+
+// Send updates the export according to e. Edits of exported pages are
+// sent to browsers as fragments, other changes export all pages again.
+func (s *Server) Send(e *watch.Event) error {
+	s.exportMu.Lock()
+	defer s.exportMu.Unlock()
+	s.mu.Lock()
+	exporter := s.exporter
+	s.mu.Unlock()
+	if exporter != nil && e.Type == watch.PageEdited {
+		if page := exporter.Page(e.PageID); page != nil {
+			fragments, err := s.update(page)
+			if err == nil {
+				if len(fragments) > 0 {
+					s.broadcast(&Message{Fragments: fragments})
+				}
+				return nil
+			}
+			if err != export.ErrRenderPage {
+				return err
+			}
+		}
+	}
+	if err := s.export(); err != nil {
+		return err
+	}
+	s.broadcast(&Message{Reload: true})
+	return nil
+}
+
+// update fetches page again, updates its file with the fragments of the
+// blocks that changed and returns them.
+func (s *Server) update(page *export.Page) ([]*export.HTMLFragment, error) {
+	p, err := s.client.GetPage(page.ID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting page %v", page.ID)
+	}
+	versions := make(map[string]int64)
+	walk(page.Block, func(b *notiontypes.Block) bool {
+		versions[b.ID] = b.Version
+		return true
+	})
+	var changed []*notiontypes.Block
+	walk(p.Block, func(b *notiontypes.Block) bool {
+		if v, ok := versions[b.ID]; ok && v == b.Version {
+			return true
+		}
+		// the fragment of b holds its content
+		changed = append(changed, b)
+		return false
+	})
+	var fragments []*export.HTMLFragment
+	for _, b := range changed {
+		f, err := s.html.Update(page, b)
+		if err != nil {
+			return nil, err
+		}
+		fragments = append(fragments, f...)
+	}
+	path := filepath.Join(s.dir, filepath.FromSlash(page.Path))
+	html, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	doc := string(html)
+	for _, f := range fragments {
+		var ok bool
+		if doc, ok = f.Replace(doc); !ok {
+			return nil, export.ErrRenderPage
+		}
+	}
+	return fragments, ioutil.WriteFile(path, []byte(doc), 0644)
+}
+
+// walk calls fn for b and, as long as fn returns true, its content, not
+// counting sub-pages.
+func walk(b *notiontypes.Block, fn func(*notiontypes.Block) bool) {
+	if !fn(b) {
+		return
+	}
+	for _, c := range b.Content {
+		if c.IsPage() {
+			fn(c)
+			continue
+		}
+		walk(c, fn)
+	}
+}
+
+// broadcast sends m to all clients at once, dropping those that fail to
+// take it in time.
+func (s *Server) broadcast(m *Message) {
+	b, err := json.Marshal(m)
+	if err != nil {
+		log.Printf("preview: encoding message: %v", err)
+		return
+	}
+	s.mu.Lock()
+	clients := make([]*wsConn, 0, len(s.clients))
+	for c := range s.clients {
+		clients = append(clients, c)
+	}
+	s.mu.Unlock()
+	var wg sync.WaitGroup
+	for _, c := range clients {
+		wg.Add(1)
+		go func(c *wsConn) {
+			defer wg.Done()
+			if err := c.writeFrame(opText, b); err != nil {
+				c.conn.Close()
+				s.mu.Lock()
+				delete(s.clients, c)
+				s.mu.Unlock()
+			}
+		}(c)
+	}
+	wg.Wait()
+}
+
+// ServeHTTP serves the exported pages, with a script that applies the
+// messages of the WebSocket at SocketPath. The root path redirects to the
+// page the export started from.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == SocketPath {
+		s.serveSocket(w, r)
+		return
+	}
+	s.mu.Lock()
+	exporter := s.exporter
+	s.mu.Unlock()
+	if exporter == nil {
+		http.Error(w, "the pages haven't been exported yet", http.StatusServiceUnavailable)
+		return
+	}
+	if r.URL.Path == "/" {
+		if root := exporter.Page(s.rootID); root != nil {
+			http.Redirect(w, r, "/"+root.Path, http.StatusFound)
+			return
+		}
+	}
+	name := path.Clean(r.URL.Path)
+	if path.Ext(name) != ".html" {
+		http.ServeFile(w, r, filepath.Join(s.dir, filepath.FromSlash(name)))
+		return
+	}
+	html, err := ioutil.ReadFile(filepath.Join(s.dir, filepath.FromSlash(name)))
+	if os.IsNotExist(err) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	script := []byte("<script>" + previewScript + "</script>\n")
+	if i := bytes.LastIndex(html, []byte("</body>")); i >= 0 {
+		html = append(html[:i:i], append(script, html[i:]...)...)
+	} else {
+		html = append(html, script...)
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(html)
+}
+
+func (s *Server) serveSocket(w http.ResponseWriter, r *http.Request) {
+	c, key, err := upgrade(w, r)
+	if err != nil {
+		return
+	}
+	// broadcasts wait for the handshake to be written
+	c.mu.Lock()
+	s.mu.Lock()
+	s.clients[c] = true
+	s.mu.Unlock()
+	err = c.accept(key)
+	c.mu.Unlock()
+	if err == nil {
+		c.serve()
+	}
+	s.mu.Lock()
+	delete(s.clients, c)
+	s.mu.Unlock()
+	c.conn.Close()
+}
+
+// previewScript replaces the HTML between the markers of the blocks of
+// fragments, and reconnects when the server restarts.
+var previewScript = strings.TrimSpace(`
+(function() {
+	function markers(id) {
+		var w = document.createTreeWalker(document.body, NodeFilter.SHOW_COMMENT), start = null, n;
+		while ((n = w.nextNode())) {
+			if (n.data === "block:" + id) {
+				start = n;
+			} else if (start && n.data === "/block:" + id) {
+				return [start, n];
+			}
+		}
+		return null;
+	}
+	function apply(f) {
+		var m = markers(f.block_id);
+		if (!m) {
+			return;
+		}
+		var range = document.createRange();
+		range.setStartBefore(m[0]);
+		range.setEndAfter(m[1]);
+		var html = range.createContextualFragment(f.html);
+		range.deleteContents();
+		range.insertNode(html);
+	}
+	function connect() {
+		var ws = new WebSocket((location.protocol === "https:" ? "wss://" : "ws://") + location.host + "` + SocketPath + `");
+		ws.onmessage = function(e) {
+			var m = JSON.parse(e.data);
+			if (m.reload) {
+				location.reload();
+				return;
+			}
+			(m.fragments || []).forEach(apply);
+		};
+		ws.onclose = function() {
+			setTimeout(connect, 1000);
+		};
+	}
+	connect();
+})();
+`)
@@ -0,0 +1,141 @@
+package preview
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tmc/notion/export"
+	"github.com/tmc/notion/notiontest"
+	"github.com/tmc/notion/notiontypes"
+	"github.com/tmc/notion/watch"
+)
+
+const (
+	pageID = "4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+	textID = "5b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+)
+
+func page(title string, pageVersion int64, text string, version int64) *notiontypes.Block {
+	return &notiontypes.Block{
+		ID: pageID, Type: notiontypes.BlockPage, Version: pageVersion,
+		Properties: map[string]interface{}{"title": [][]string{{title}}},
+		Content: []*notiontypes.Block{{
+			ID: textID, Type: notiontypes.BlockText, Version: version,
+			Properties: map[string]interface{}{"title": [][]string{{text}}},
+		}},
+	}
+}
+
+// dial opens a WebSocket to the preview server at addr.
+func dial(t *testing.T, addr string) *wsConn {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := "GET " + SocketPath + " HTTP/1.1\r\nHost: " + addr + "\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n\r\n"
+	if _, err := conn.Write([]byte(req)); err != nil {
+		t.Fatal(err)
+	}
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the example of RFC 6455
+	if resp.StatusCode != http.StatusSwitchingProtocols || resp.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("got handshake response %v %v", resp.Status, resp.Header)
+	}
+	return &wsConn{conn: conn, r: r, mask: true}
+}
+
+func receive(t *testing.T, c *wsConn) *Message {
+	op, payload, err := c.readFrame()
+	if err != nil || op != opText {
+		t.Fatalf("got frame %v, %v", op, err)
+	}
+	m := &Message{}
+	if err := json.Unmarshal(payload, m); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestServer(t *testing.T) {
+	srv := notiontest.NewServer()
+	defer srv.Close()
+	srv.AddBlock(page("Draft", 1, "hello", 1))
+	s := NewServer(srv.Client(), pageID, t.TempDir(), &export.HTML{Highlighter: export.PlainHighlighter{}})
+	if err := s.Export(); err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), "<p>hello</p>") || !strings.Contains(string(body), "new WebSocket(") {
+		t.Fatalf("got page\n%s", body)
+	}
+
+	c := dial(t, strings.TrimPrefix(ts.URL, "http://"))
+	defer c.conn.Close()
+	srv.AddBlock(page("Draft", 1, "hello, world", 2))
+	if err := s.Send(&watch.Event{Type: watch.PageEdited, PageID: pageID}); err != nil {
+		t.Fatal(err)
+	}
+	m := receive(t, c)
+	if len(m.Fragments) != 1 || m.Fragments[0].BlockID != textID || !strings.Contains(m.Fragments[0].HTML, "<p>hello, world</p>") {
+		t.Errorf("got message %+v", m)
+	}
+
+	srv.AddBlock(page("Final", 2, "hello, world", 2))
+	if err := s.Send(&watch.Event{Type: watch.PageEdited, PageID: pageID}); err != nil {
+		t.Fatal(err)
+	}
+	if m := receive(t, c); !m.Reload {
+		t.Errorf("renaming the page: got message %+v, want a reload", m)
+	}
+
+	if err := c.writeFrame(opPing, []byte("hi")); err != nil {
+		t.Fatal(err)
+	}
+	if op, payload, err := c.readFrame(); op != opPong || string(payload) != "hi" || err != nil {
+		t.Errorf("got frame %v %q %v, want pong", op, payload, err)
+	}
+}
+
+func TestBroadcastDropsStalledClients(t *testing.T) {
+	defer func(d time.Duration) { writeTimeout = d }(writeTimeout)
+	writeTimeout = 50 * time.Millisecond
+	s := NewServer(nil, pageID, t.TempDir(), &export.HTML{})
+	// nothing reads from the other end of the pipe
+	conn, stalled := net.Pipe()
+	defer stalled.Close()
+	s.clients[&wsConn{conn: conn}] = true
+
+	done := make(chan struct{})
+	go func() {
+		s.broadcast(&Message{Reload: true})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("broadcast blocked on a stalled client")
+	}
+	if len(s.clients) != 0 {
+		t.Errorf("stalled client wasn't dropped")
+	}
+}
@@ -0,0 +1,176 @@
+package preview
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// The server side of the WebSocket protocol (RFC 6455), as far as needed
+// to push messages to browsers.
+
+// WebSocket opcodes.
+const (
+	opText  = 0x1
+	opClose = 0x8
+	opPing  = 0x9
+	opPong  = 0xa
+)
+
+// maxFrameSize bounds the frames read from clients, which only send
+// control frames.
+const maxFrameSize = 1 << 20
+
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// writeTimeout bounds the writes to clients, so that stalled browsers are
+// dropped instead of holding up the others.
+var writeTimeout = 10 * time.Second
+
+type wsConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+	// mask is set for client connections, whose frames must be masked
+	mask bool
+
+	mu sync.Mutex // serializes writes
+}
+
+// upgrade takes over the connection of a WebSocket handshake request. The
+// handshake is completed by accept.
+func upgrade(w http.ResponseWriter, r *http.Request) (*wsConn, string, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") || key == "" {
+		http.Error(w, "expected a WebSocket handshake", http.StatusBadRequest)
+		return nil, "", errors.New("not a WebSocket handshake")
+	}
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "can't upgrade the connection", http.StatusInternalServerError)
+		return nil, "", errors.New("response writer can't be hijacked")
+	}
+	conn, rw, err := hj.Hijack()
+	if err != nil {
+		return nil, "", err
+	}
+	return &wsConn{conn: conn, r: rw.Reader}, key, nil
+}
+
+// accept completes the handshake of the request with the given key. The
+// caller holds c.mu, so that no frame is written before the handshake.
+func (c *wsConn) accept(key string) error {
+	h := sha1.New()
+	io.WriteString(h, key+websocketGUID)
+	resp := "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(h.Sum(nil)) + "\r\n\r\n"
+	c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	_, err := io.WriteString(c.conn, resp)
+	return err
+}
+
+// writeFrame writes a single, final frame, failing if it takes longer than
+// writeTimeout.
+func (c *wsConn) writeFrame(op byte, payload []byte) error {
+	header := []byte{0x80 | op, 0}
+	switch n := len(payload); {
+	case n < 126:
+		header[1] = byte(n)
+	case n <= 0xffff:
+		header[1] = 126
+		header = append(header, 0, 0)
+		binary.BigEndian.PutUint16(header[2:], uint16(n))
+	default:
+		header[1] = 127
+		header = append(header, make([]byte, 8)...)
+		binary.BigEndian.PutUint64(header[2:], uint64(n))
+	}
+	if c.mask {
+		header[1] |= 0x80
+		key := []byte{0x12, 0x34, 0x56, 0x78}
+		header = append(header, key...)
+		masked := make([]byte, len(payload))
+		for i, b := range payload {
+			masked[i] = b ^ key[i%4]
+		}
+		payload = masked
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if _, err := c.conn.Write(header); err != nil {
+		return err
+	}
+	_, err := c.conn.Write(payload)
+	return err
+}
+
+// readFrame reads a frame and returns its opcode and unmasked payload.
+func (c *wsConn) readFrame() (byte, []byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(c.r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	op, masked, n := header[0]&0xf, header[1]&0x80 != 0, uint64(header[1]&0x7f)
+	switch n {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.r, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		n = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.r, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		n = binary.BigEndian.Uint64(ext[:])
+	}
+	if n > maxFrameSize {
+		return 0, nil, errors.Errorf("websocket frame of %d bytes is too large", n)
+	}
+	var key [4]byte
+	if masked {
+		if _, err := io.ReadFull(c.r, key[:]); err != nil {
+			return 0, nil, err
+		}
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(c.r, payload); err != nil {
+		return 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= key[i%4]
+		}
+	}
+	return op, payload, nil
+}
+
+// serve answers pings and returns when the connection is closed.
+func (c *wsConn) serve() error {
+	defer c.conn.Close()
+	for {
+		op, payload, err := c.readFrame()
+		if err != nil {
+			return err
+		}
+		switch op {
+		case opPing:
+			if err := c.writeFrame(opPong, payload); err != nil {
+				return err
+			}
+		case opClose:
+			c.writeFrame(opClose, payload)
+			return nil
+		}
+	}
+}
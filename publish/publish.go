@@ -0,0 +1,434 @@
+// Package publish publishes notion databases as the content of static sites.
+package publish
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/collation"
+	"github.com/tmc/notion/export"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// Generator is a static site generator, which determines where posts and
+// assets are written.
+type Generator int
+
+const (
+	// Hugo writes posts to content/<section>/<slug>.md and assets to static/notion.
+	Hugo Generator = iota
+	// Jekyll writes posts to _posts/<date>-<slug>.md, drafts to
+	// _drafts/<slug>.md and assets to assets/notion.
+	Jekyll
+)
+
+// stateFile records the posts written by the last Publish, relative to Site.Dir.
+const stateFile = ".notion-publish.json"
+
+// Site publishes the rows of a blog database as the posts of a static site.
+type Site struct {
+	Client *notion.Client
+	// Dir is the root directory of the site.
+	Dir       string
+	Generator Generator
+	// Section is the Hugo content section posts are written to. It defaults to "posts".
+	Section string
+	// SlugProperty names the property holding the slugs of posts. It
+	// defaults to "Slug". Posts without a slug use the slug of their title.
+	SlugProperty string
+	// StatusProperty names the property holding the status of posts. It
+	// defaults to "Status". Posts whose status is not PublishedStatus
+	// ("Published" by default) are drafts, except those whose status is
+	// "Archived", which are removed from the site.
+	StatusProperty  string
+	PublishedStatus string
+	// Statuses, if set, maps the statuses of posts to their state instead
+	// of PublishedStatus and "Archived". Posts with other statuses are
+	// drafts.
+	Statuses map[string]PostStatus
+	// PublishDateProperty names a date property holding the date of posts,
+	// instead of the creation date of their row. Published posts with a
+	// date in the future stay drafts until then, so that Publish run
+	// regularly publishes them on schedule.
+	PublishDateProperty string
+	// PublishedAtProperty names a date property set to the time posts are
+	// first written as published, unless it's already set.
+	PublishedAtProperty string
+	// Location is the time zone of publish dates without one, UTC by
+	// default.
+	Location *time.Location
+	// FrontMatterKeys maps property names to front-matter keys, see export.FrontMatter.
+	FrontMatterKeys map[string]string
+	// Force rewrites all posts, not only those edited since the last Publish.
+	Force bool
+	// DatabaseTables renders the database views embedded in posts as
+	// tables of their rows, see export.WithDatabaseTables. Posts aren't
+	// rewritten when only the rows change, unless Force is set.
+	DatabaseTables bool
+	// Index, if set, makes Publish write an index of all posts ordered by
+	// title with it, for list pages, to data/notion/<section>.json for Hugo
+	// (.Site.Data.notion.<section>) or _data/notion_posts.json for Jekyll
+	// (site.data.notion_posts).
+	Index *collation.Collator
+
+	// now returns the current time, time.Now by default
+	now func() time.Time
+}
+
+// PostStatus is the state of a post in the publishing workflow.
+type PostStatus int
+
+const (
+	// Draft posts are written as drafts.
+	Draft PostStatus = iota
+	// Published posts are written as published.
+	Published
+	// Archived posts are removed from the site.
+	Archived
+)
+
+// ParseStatus parses the name of a PostStatus: draft, published or
+// archived.
+func ParseStatus(name string) (PostStatus, error) {
+	switch strings.ToLower(name) {
+	case "draft":
+		return Draft, nil
+	case "published":
+		return Published, nil
+	case "archived":
+		return Archived, nil
+	}
+	return Draft, errors.Errorf("publish: unknown post status %q", name)
+}
+
+// IndexEntry is a post in the index of a Site.
+type IndexEntry struct {
+	Title string    `json:"title"`
+	Slug  string    `json:"slug"`
+	Path  string    `json:"path"`
+	Draft bool      `json:"draft"`
+	Date  time.Time `json:"date"`
+}
+
+// Result summarizes a Publish.
+type Result struct {
+	// Written and Removed hold the paths of posts, relative to Site.Dir.
+	Written   []string
+	Removed   []string
+	Unchanged int
+	// Scheduled holds the paths of the published posts written as drafts
+	// until their publish date.
+	Scheduled []string
+	// Stamped holds the ids of the rows whose PublishedAtProperty was set.
+	Stamped []string
+}
+
+type postState struct {
+	Path           string `json:"path"`
+	LastEditedTime int64  `json:"last_edited_time"`
+	// Draft changes when scheduled posts are due
+	Draft bool `json:"draft,omitempty"`
+}
+
+type post struct {
+	path      string
+	slug      string
+	draft     bool
+	archived  bool
+	scheduled bool
+	// stamp is set for published posts whose PublishedAtProperty is unset
+	stamp bool
+	date  time.Time
+}
+
+// Publish writes the posts of the database databaseID that were edited
+// since the last Publish and removes those no longer in the database.
+// It fails without writing anything if two posts have the same path, e.g.
+// rows with the same slug, or the same title and no slug.
+func (s *Site) Publish(databaseID string) (*Result, error) {
+	db, err := s.Client.GetBlock(databaseID)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting database")
+	}
+	if db.CollectionID == "" || len(db.ViewIDs) == 0 {
+		return nil, errors.Errorf("publish: %v is not a database", databaseID)
+	}
+	collection, err := s.Client.GetCollection(db.CollectionID)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting database")
+	}
+	rows, err := s.Client.QueryCollection(db.CollectionID, db.ViewIDs[0])
+	if err != nil {
+		return nil, errors.Wrap(err, "querying database")
+	}
+	state, err := s.loadState()
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Result{}
+	next := make(map[string]*postState, len(rows))
+	// inUse holds the rows posts are published for by path
+	inUse := make(map[string]string, len(rows))
+	posts := make(map[string]*post)
+	var changed []string
+	index := make([]*IndexEntry, 0, len(rows))
+	for _, row := range rows {
+		p := s.post(row, collection.PageProperties(row))
+		if p.archived {
+			continue
+		}
+		if other, ok := inUse[p.path]; ok {
+			return nil, errors.Errorf("publish: rows %v and %v are both published to %v, give them different slugs", other, row.ID, p.path)
+		}
+		inUse[p.path] = row.ID
+		if p.scheduled {
+			res.Scheduled = append(res.Scheduled, p.path)
+		}
+		index = append(index, &IndexEntry{Title: row.Title, Slug: p.slug, Path: p.path, Draft: p.draft, Date: p.date})
+		next[row.ID] = &postState{Path: p.path, LastEditedTime: row.LastEditedTime, Draft: p.draft}
+		if old := state[row.ID]; !s.Force && old != nil && *old == *next[row.ID] && s.exists(p.path) {
+			res.Unchanged++
+			continue
+		}
+		posts[row.ID] = p
+		changed = append(changed, row.ID)
+	}
+	// remove deleted posts and those whose path changed before writing, as
+	// a new post may take over an old path
+	for _, old := range state {
+		if _, ok := inUse[old.Path]; ok {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.Dir, filepath.FromSlash(old.Path))); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		res.Removed = append(res.Removed, old.Path)
+	}
+	if len(changed) > 0 {
+		if err := s.export(posts, changed); err != nil {
+			return nil, err
+		}
+		for _, id := range changed {
+			res.Written = append(res.Written, posts[id].path)
+		}
+		for _, id := range changed {
+			if !posts[id].stamp {
+				continue
+			}
+			if err := s.stamp(db.CollectionID, id, next[id]); err != nil {
+				return nil, errors.Wrapf(err, "setting %v of %v", s.PublishedAtProperty, id)
+			}
+			res.Stamped = append(res.Stamped, id)
+		}
+	}
+	if s.Index != nil {
+		if err := s.writeIndex(index); err != nil {
+			return nil, err
+		}
+	}
+	return res, s.saveState(next)
+}
+
+// indexPath returns the path of the index relative to Dir.
+func (s *Site) indexPath() string {
+	if s.Generator == Jekyll {
+		return path.Join("_data", "notion_posts.json")
+	}
+	section := s.Section
+	if section == "" {
+		section = "posts"
+	}
+	return path.Join("data", "notion", section+".json")
+}
+
+func (s *Site) writeIndex(index []*IndexEntry) error {
+	s.Index.Slice(index, func(i int) string { return index[i].Title })
+	b, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+	name := filepath.Join(s.Dir, filepath.FromSlash(s.indexPath()))
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return err
+	}
+	return errors.Wrap(ioutil.WriteFile(name, b, 0644), "writing post index")
+}
+
+func (s *Site) export(posts map[string]*post, ids []string) error {
+	assets := &export.Download{Dir: "static/notion", URLPrefix: "/notion/"}
+	if s.Generator == Jekyll {
+		assets = &export.Download{Dir: "assets/notion", URLPrefix: "/assets/notion/"}
+	}
+	opts := []export.Option{
+		export.WithRenderer(&export.Markdown{FrontMatter: true, FrontMatterKeys: s.FrontMatterKeys}),
+		export.WithAssetPolicy(assets),
+		export.WithPageHook(func(page *export.Page) {
+			p := posts[page.ID]
+			page.Path = p.path
+			page.FrontMatterParams = map[string]string{
+				"slug": export.YAMLString(p.slug),
+			}
+			if s.Generator == Hugo {
+				page.FrontMatterParams["draft"] = strconv.FormatBool(p.draft)
+			}
+			hasDate := false
+			for _, prop := range page.PageProperties {
+				hasDate = hasDate || export.FrontMatterKey(prop.Name, s.FrontMatterKeys) == "date"
+			}
+			if !hasDate {
+				page.FrontMatterParams["date"] = p.date.Format(time.RFC3339)
+			}
+		}),
+	}
+	if s.DatabaseTables {
+		opts = append(opts, export.WithDatabaseTables())
+	}
+	e := export.NewExporter(s.Client, s.Dir, opts...)
+	return errors.Wrap(e.ExportPages(ids...), "exporting posts")
+}
+
+// stamp sets the PublishedAtProperty of the row id to the current time, and
+// the edit time of its state to that of the change, so that the post isn't
+// rewritten for it.
+func (s *Site) stamp(collectionID, id string, state *postState) error {
+	now := s.clock().In(s.location())
+	err := s.Client.UpdateRow(collectionID, id, map[string]string{s.PublishedAtProperty: now.Format("2006-01-02 15:04")})
+	if err != nil {
+		return err
+	}
+	row, err := s.Client.GetBlock(id)
+	if err != nil {
+		return err
+	}
+	state.LastEditedTime = row.LastEditedTime
+	return nil
+}
+
+func (s *Site) clock() time.Time {
+	if s.now != nil {
+		return s.now()
+	}
+	return time.Now()
+}
+
+func (s *Site) location() *time.Location {
+	if s.Location != nil {
+		return s.Location
+	}
+	return time.UTC
+}
+
+// status returns the state of posts with the given status.
+func (s *Site) status(status string) PostStatus {
+	if s.Statuses != nil {
+		return s.Statuses[status]
+	}
+	published := s.PublishedStatus
+	if published == "" {
+		published = "Published"
+	}
+	switch status {
+	case published:
+		return Published
+	case "Archived":
+		return Archived
+	}
+	return Draft
+}
+
+// post determines where and how the database row is published.
+func (s *Site) post(row *notiontypes.Block, props []*notiontypes.PageProperty) *post {
+	p := &post{date: row.CreatedOn().UTC()}
+	slugProperty := s.SlugProperty
+	if slugProperty == "" {
+		slugProperty = "Slug"
+	}
+	statusProperty := s.StatusProperty
+	if statusProperty == "" {
+		statusProperty = "Status"
+	}
+	status := ""
+	publishedAt := false
+	for _, prop := range props {
+		switch prop.Name {
+		case slugProperty:
+			p.slug = export.Slug(prop.Text())
+		case statusProperty:
+			status = prop.Text()
+		case s.PublishDateProperty:
+			if d := prop.Date(); d != nil {
+				if t, err := d.Start(s.location()); err == nil {
+					p.date = t.UTC()
+				}
+			}
+		case s.PublishedAtProperty:
+			publishedAt = prop.Text() != ""
+		}
+	}
+	if p.slug == "" || p.slug == "untitled" {
+		p.slug = export.Slug(row.Title)
+	}
+	switch s.status(status) {
+	case Published:
+		p.scheduled = s.PublishDateProperty != "" && p.date.After(s.clock())
+		p.draft = p.scheduled
+		p.stamp = !p.draft && s.PublishedAtProperty != "" && !publishedAt
+	case Archived:
+		p.archived = true
+	default:
+		p.draft = true
+	}
+
+	switch s.Generator {
+	case Jekyll:
+		if p.draft {
+			p.path = path.Join("_drafts", p.slug+".md")
+		} else {
+			p.path = path.Join("_posts", p.date.Format("2006-01-02")+"-"+p.slug+".md")
+		}
+	default:
+		section := s.Section
+		if section == "" {
+			section = "posts"
+		}
+		p.path = path.Join("content", section, p.slug+".md")
+	}
+	return p
+}
+
+func (s *Site) exists(rel string) bool {
+	_, err := os.Stat(filepath.Join(s.Dir, filepath.FromSlash(rel)))
+	return err == nil
+}
+
+func (s *Site) loadState() (map[string]*postState, error) {
+	state := make(map[string]*postState)
+	b, err := ioutil.ReadFile(filepath.Join(s.Dir, stateFile))
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &state); err != nil {
+		return nil, errors.Wrap(err, "reading publish state")
+	}
+	return state, nil
+}
+
+func (s *Site) saveState(state map[string]*postState) error {
+	b, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(s.Dir, stateFile), b, 0644)
+}
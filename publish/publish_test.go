@@ -0,0 +1,174 @@
+package publish
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tmc/notion/collation"
+	"github.com/tmc/notion/notiontest"
+	"github.com/tmc/notion/notiontypes"
+)
+
+func TestPost(t *testing.T) {
+	created := time.Date(2019, 3, 1, 12, 0, 0, 0, time.UTC)
+	row := &notiontypes.Block{Title: "Hello, World", CreatedTime: created.Unix() * 1000}
+	status := func(s string) []*notiontypes.PageProperty {
+		return []*notiontypes.PageProperty{
+			{Name: "Status", Type: notiontypes.ColumnTypeSelect, Value: []*notiontypes.InlineBlock{{Text: s}}},
+		}
+	}
+	tests := []struct {
+		site  *Site
+		props []*notiontypes.PageProperty
+		path  string
+		draft bool
+	}{
+		{&Site{}, status("Published"), "content/posts/hello-world.md", false},
+		{&Site{}, status("Idea"), "content/posts/hello-world.md", true},
+		{&Site{Section: "blog"}, []*notiontypes.PageProperty{
+			{Name: "Slug", Value: []*notiontypes.InlineBlock{{Text: "Hi There"}}},
+		}, "content/blog/hi-there.md", true},
+		{&Site{Generator: Jekyll}, status("Published"), "_posts/2019-03-01-hello-world.md", false},
+		{&Site{Generator: Jekyll}, nil, "_drafts/hello-world.md", true},
+	}
+	for _, tt := range tests {
+		p := tt.site.post(row, tt.props)
+		if p.path != tt.path || p.draft != tt.draft {
+			t.Errorf("got %v (draft %v), want %v (draft %v)", p.path, p.draft, tt.path, tt.draft)
+		}
+	}
+}
+
+func TestWriteIndex(t *testing.T) {
+	c, err := collation.New("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Site{Dir: t.TempDir(), Section: "blog", Index: c}
+	index := []*IndexEntry{{Title: "Zoo"}, {Title: "Élan"}, {Title: "apples"}}
+	if err := s.writeIndex(index); err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadFile(filepath.Join(s.Dir, "data", "notion", "blog.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []*IndexEntry
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || got[0].Title != "apples" || got[1].Title != "Élan" || got[2].Title != "Zoo" {
+		t.Errorf("got index %s", b)
+	}
+}
+
+func TestPublishWorkflow(t *testing.T) {
+	s := notiontest.NewServer()
+	defer s.Close()
+	s.AddRecord(notiontypes.TableCollection, "db", map[string]interface{}{
+		"schema": map[string]interface{}{
+			"title": map[string]string{"name": "Name", "type": "title"},
+			"st":    map[string]string{"name": "Status", "type": "select"},
+			"pd":    map[string]string{"name": "Publish date", "type": "date"},
+			"pa":    map[string]string{"name": "Published at", "type": "date"},
+		},
+	})
+	const dbID = "aa8fc126-6770-4e83-ad6c-3968dcfc9b80"
+	s.AddBlock(&notiontypes.Block{ID: dbID, Type: notiontypes.BlockCollectionViewPage, CollectionID: "db", ViewIDs: []string{"v"}})
+	date := func(d string) interface{} {
+		return []interface{}{[]interface{}{"‣", []interface{}{[]interface{}{"d", map[string]string{"type": "date", "start_date": d}}}}}
+	}
+	for i, props := range []map[string]interface{}{
+		{"title": [][]string{{"Now"}}, "st": [][]string{{"Live"}}},
+		{"title": [][]string{{"Later"}}, "st": [][]string{{"Live"}}, "pd": date("2030-01-01")},
+		{"title": [][]string{{"Old"}}, "st": [][]string{{"Gone"}}},
+		{"title": [][]string{{"Idea"}}, "st": [][]string{{"Idea"}}},
+	} {
+		s.AddBlock(&notiontypes.Block{
+			ID:          fmt.Sprintf("aa8fc126-6770-4e83-ad6c-3968dcfc9b8%d", i+1),
+			Type:        notiontypes.BlockPage,
+			ParentID:    "db",
+			ParentTable: notiontypes.TableCollection,
+			CreatedTime: int64(i),
+			Properties:  props,
+		})
+	}
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	site := &Site{
+		Client:              s.Client(),
+		Dir:                 t.TempDir(),
+		Generator:           Jekyll,
+		Statuses:            map[string]PostStatus{"Live": Published, "Gone": Archived},
+		PublishDateProperty: "Publish date",
+		PublishedAtProperty: "Published at",
+		now:                 func() time.Time { return now },
+	}
+	res, err := site.Publish(dbID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Result{
+		Written:   []string{"_posts/1970-01-01-now.md", "_drafts/later.md", "_drafts/idea.md"},
+		Scheduled: []string{"_drafts/later.md"},
+		Stamped:   []string{"aa8fc126-6770-4e83-ad6c-3968dcfc9b81"},
+	}
+	if !reflect.DeepEqual(res, want) {
+		t.Errorf("got first result %+v, want %+v", res, want)
+	}
+	if d := s.Block("aa8fc126-6770-4e83-ad6c-3968dcfc9b81").Properties["pa"]; !strings.Contains(fmt.Sprint(d), "start_date:2024-05-01 start_time:12:00") {
+		t.Errorf("got published at %v", d)
+	}
+
+	// the scheduled post is due, stamping didn't change the first one
+	now = time.Date(2030, 1, 2, 0, 0, 0, 0, time.UTC)
+	res, err = site.Publish(dbID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = &Result{
+		Written:   []string{"_posts/2030-01-01-later.md"},
+		Removed:   []string{"_drafts/later.md"},
+		Unchanged: 2,
+		Stamped:   []string{"aa8fc126-6770-4e83-ad6c-3968dcfc9b82"},
+	}
+	if !reflect.DeepEqual(res, want) {
+		t.Errorf("got second result %+v, want %+v", res, want)
+	}
+}
+
+func TestPublishDuplicatePaths(t *testing.T) {
+	s := notiontest.NewServer()
+	defer s.Close()
+	s.AddRecord(notiontypes.TableCollection, "db", map[string]interface{}{
+		"schema": map[string]interface{}{
+			"title": map[string]string{"name": "Name", "type": "title"},
+		},
+	})
+	const dbID = "aa8fc126-6770-4e83-ad6c-3968dcfc9b80"
+	s.AddBlock(&notiontypes.Block{ID: dbID, Type: notiontypes.BlockCollectionViewPage, CollectionID: "db", ViewIDs: []string{"v"}})
+	ids := []string{"aa8fc126-6770-4e83-ad6c-3968dcfc9b81", "aa8fc126-6770-4e83-ad6c-3968dcfc9b82"}
+	for _, id := range ids {
+		s.AddBlock(&notiontypes.Block{
+			ID:          id,
+			Type:        notiontypes.BlockPage,
+			ParentID:    "db",
+			ParentTable: notiontypes.TableCollection,
+			Properties:  map[string]interface{}{"title": [][]string{{"Hello"}}},
+		})
+	}
+	site := &Site{Client: s.Client(), Dir: t.TempDir()}
+	_, err := site.Publish(dbID)
+	if err == nil || !strings.Contains(err.Error(), ids[0]) || !strings.Contains(err.Error(), ids[1]) {
+		t.Fatalf("got error %v, want one naming both rows", err)
+	}
+	if site.exists("content/posts/hello.md") {
+		t.Error("wrote a post despite the conflict")
+	}
+}
@@ -0,0 +1,283 @@
+// Package query builds the filters of collection queries and views in a
+// readable way:
+//
+//	f := query.Where("Status").Is("Done").And(query.Where("Due").Before(time.Now()))
+//	filter, err := f.Compile(collection)
+//
+// Properties are named as in the collection, case-insensitively. Compile
+// picks the operators notion uses for their types, e.g. enum_is for select
+// properties and string_is for text.
+package query
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// Filter is a condition on a property, or a group of filters.
+type Filter struct {
+	// for groups
+	operator string
+	filters  []*Filter
+
+	// for conditions
+	property string
+	test     string
+	value    interface{}
+}
+
+// Property is a property of the rows to test, see Where.
+type Property struct {
+	name string
+}
+
+// Where starts a condition on the property name.
+func Where(name string) *Property {
+	return &Property{name: name}
+}
+
+func (p *Property) filter(test string, value interface{}) *Filter {
+	return &Filter{property: p.name, test: test, value: value}
+}
+
+// Is matches rows whose property equals v: a string for text and select
+// properties, a number, a bool for checkboxes, a time.Time for dates or a
+// user id for people.
+func (p *Property) Is(v interface{}) *Filter { return p.filter("is", v) }
+
+// IsNot matches rows whose property doesn't equal v, see Is.
+func (p *Property) IsNot(v interface{}) *Filter { return p.filter("is_not", v) }
+
+// Contains matches text containing s, multi-select properties with the
+// option s and people or relations including the id s.
+func (p *Property) Contains(s string) *Filter { return p.filter("contains", s) }
+
+// DoesNotContain is the opposite of Contains.
+func (p *Property) DoesNotContain(s string) *Filter { return p.filter("does_not_contain", s) }
+
+// StartsWith matches text starting with s.
+func (p *Property) StartsWith(s string) *Filter { return p.filter("starts_with", s) }
+
+// EndsWith matches text ending with s.
+func (p *Property) EndsWith(s string) *Filter { return p.filter("ends_with", s) }
+
+// GreaterThan matches numbers greater than n.
+func (p *Property) GreaterThan(n float64) *Filter { return p.filter("greater_than", n) }
+
+// LessThan matches numbers less than n.
+func (p *Property) LessThan(n float64) *Filter { return p.filter("less_than", n) }
+
+// AtLeast matches numbers greater than or equal to n.
+func (p *Property) AtLeast(n float64) *Filter { return p.filter("greater_than_or_equal_to", n) }
+
+// AtMost matches numbers less than or equal to n.
+func (p *Property) AtMost(n float64) *Filter { return p.filter("less_than_or_equal_to", n) }
+
+// Before matches dates before the day of t.
+func (p *Property) Before(t time.Time) *Filter { return p.filter("before", t) }
+
+// After matches dates after the day of t.
+func (p *Property) After(t time.Time) *Filter { return p.filter("after", t) }
+
+// OnOrBefore matches dates on or before the day of t.
+func (p *Property) OnOrBefore(t time.Time) *Filter { return p.filter("on_or_before", t) }
+
+// OnOrAfter matches dates on or after the day of t.
+func (p *Property) OnOrAfter(t time.Time) *Filter { return p.filter("on_or_after", t) }
+
+// IsEmpty matches rows without a value.
+func (p *Property) IsEmpty() *Filter { return p.filter("is_empty", nil) }
+
+// IsNotEmpty matches rows with a value.
+func (p *Property) IsNotEmpty() *Filter { return p.filter("is_not_empty", nil) }
+
+// All matches rows matching all filters.
+func All(filters ...*Filter) *Filter {
+	return &Filter{operator: "and", filters: filters}
+}
+
+// Any matches rows matching any of filters.
+func Any(filters ...*Filter) *Filter {
+	return &Filter{operator: "or", filters: filters}
+}
+
+// And matches rows matching f and others.
+func (f *Filter) And(others ...*Filter) *Filter {
+	return f.combine("and", others)
+}
+
+// Or matches rows matching f or any of others.
+func (f *Filter) Or(others ...*Filter) *Filter {
+	return f.combine("or", others)
+}
+
+func (f *Filter) combine(operator string, others []*Filter) *Filter {
+	if f.operator == operator {
+		return &Filter{operator: operator, filters: append(f.filters[:len(f.filters):len(f.filters)], others...)}
+	}
+	return &Filter{operator: operator, filters: append([]*Filter{f}, others...)}
+}
+
+// operators maps the tests of conditions to notion's operators by type
+// of property.
+var operators = map[string]map[string]string{
+	"text": {
+		"is":               "string_is",
+		"is_not":           "string_is_not",
+		"contains":         "string_contains",
+		"does_not_contain": "string_does_not_contain",
+		"starts_with":      "string_starts_with",
+		"ends_with":        "string_ends_with",
+	},
+	notiontypes.ColumnTypeNumber: {
+		"is":                       "number_equals",
+		"is_not":                   "number_does_not_equal",
+		"greater_than":             "number_greater_than",
+		"less_than":                "number_less_than",
+		"greater_than_or_equal_to": "number_greater_than_or_equal_to",
+		"less_than_or_equal_to":    "number_less_than_or_equal_to",
+	},
+	notiontypes.ColumnTypeSelect: {
+		"is":     "enum_is",
+		"is_not": "enum_is_not",
+	},
+	notiontypes.ColumnMultiSelect: {
+		"is":               "enum_contains",
+		"contains":         "enum_contains",
+		"does_not_contain": "enum_does_not_contain",
+	},
+	notiontypes.ColumnTypeCheckbox: {
+		"is":     "checkbox_is",
+		"is_not": "checkbox_is_not",
+	},
+	notiontypes.ColumnTypeDate: {
+		"is":           "date_is",
+		"before":       "date_is_before",
+		"after":        "date_is_after",
+		"on_or_before": "date_is_on_or_before",
+		"on_or_after":  "date_is_on_or_after",
+	},
+	notiontypes.ColumnTypePerson: {
+		"is":               "person_contains",
+		"contains":         "person_contains",
+		"does_not_contain": "person_does_not_contain",
+	},
+	notiontypes.ColumnTypeRelation: {
+		"contains":         "relation_contains",
+		"does_not_contain": "relation_does_not_contain",
+	},
+}
+
+// kind returns the key of operators for properties of type typ.
+func kind(typ string) string {
+	switch typ {
+	case notiontypes.ColumnTypeTitle, notiontypes.ColumnTypeText, notiontypes.ColumnTypeURL,
+		notiontypes.ColumnTypeEmail, notiontypes.ColumnTypePhoneNumber:
+		return "text"
+	case notiontypes.ColumnTypeCreatedTime, notiontypes.ColumnTypeLastEditedTime:
+		return notiontypes.ColumnTypeDate
+	case notiontypes.ColumnTypeCreatedBy, notiontypes.ColumnTypeLastEditedBy:
+		return notiontypes.ColumnTypePerson
+	}
+	return typ
+}
+
+// Compile returns f as the filter of a query or view of collection,
+// referring to properties by id. Notion's filters are groups, so a single
+// condition is returned in an "and" group.
+func (f *Filter) Compile(collection *notiontypes.Collection) (*notiontypes.QueryFilter, error) {
+	if f.test != "" {
+		f = All(f)
+	}
+	return f.compile(collection)
+}
+
+func (f *Filter) compile(collection *notiontypes.Collection) (*notiontypes.QueryFilter, error) {
+	if f.test == "" {
+		group := &notiontypes.QueryFilter{Operator: f.operator}
+		for _, sub := range f.filters {
+			c, err := sub.compile(collection)
+			if err != nil {
+				return nil, err
+			}
+			group.Filters = append(group.Filters, c)
+		}
+		return group, nil
+	}
+	id, info := property(collection, f.property)
+	if info == nil {
+		return nil, errors.Errorf("query: collection %v has no property %q", collection.ID, f.property)
+	}
+	cond := &notiontypes.FilterCondition{Operator: f.test}
+	if f.test != "is_empty" && f.test != "is_not_empty" {
+		op, ok := operators[kind(info.Type)][f.test]
+		if !ok {
+			return nil, errors.Errorf("query: can't test if the %v property %q %v", info.Type, info.Name, strings.Replace(f.test, "_", " ", -1))
+		}
+		cond.Operator = op
+		v, err := value(f.value, kind(info.Type))
+		if err != nil {
+			return nil, errors.Wrapf(err, "query: property %q", info.Name)
+		}
+		cond.Value = v
+	}
+	return &notiontypes.QueryFilter{Property: id, Filter: cond}, nil
+}
+
+// value returns v as the value of a condition on a property of kind k.
+func value(v interface{}, k string) (*notiontypes.FilterValue, error) {
+	fv := &notiontypes.FilterValue{Type: notiontypes.FilterExact}
+	switch v := v.(type) {
+	case string:
+		if k == notiontypes.ColumnTypePerson {
+			fv.UserID = v
+		} else {
+			fv.Text = v
+		}
+	case bool:
+		fv.Checked = &v
+	case time.Time:
+		fv.Date = &notiontypes.Date{Type: "date", StartDate: v.Format("2006-01-02")}
+	case int:
+		n := float64(v)
+		fv.Number = &n
+	case int64:
+		n := float64(v)
+		fv.Number = &n
+	case float64:
+		fv.Number = &v
+	default:
+		return nil, errors.Errorf("unsupported value %v of type %T", v, v)
+	}
+	// other kinds of properties are tested against strings
+	_, ok := v.(string)
+	switch k {
+	case notiontypes.ColumnTypeNumber:
+		ok = fv.Number != nil
+	case notiontypes.ColumnTypeCheckbox:
+		ok = fv.Checked != nil
+	case notiontypes.ColumnTypeDate:
+		ok = fv.Date != nil
+	}
+	if !ok {
+		return nil, errors.Errorf("value %v of type %T doesn't match a %v property", v, v, k)
+	}
+	return fv, nil
+}
+
+// property returns the id and description of the property of collection
+// with the given (case-insensitive) name or id.
+func property(collection *notiontypes.Collection, name string) (string, *notiontypes.CollectionColumnInfo) {
+	if info, ok := collection.CollectionSchema[name]; ok {
+		return name, info
+	}
+	for id, info := range collection.CollectionSchema {
+		if strings.EqualFold(info.Name, name) {
+			return id, info
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,74 @@
+package query
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+func TestCompile(t *testing.T) {
+	collection := &notiontypes.Collection{
+		ID: "db",
+		CollectionSchema: map[string]*notiontypes.CollectionColumnInfo{
+			"title": {Name: "Name", Type: notiontypes.ColumnTypeTitle},
+			"st":    {Name: "Status", Type: notiontypes.ColumnTypeSelect},
+			"du":    {Name: "Due", Type: notiontypes.ColumnTypeDate},
+			"pt":    {Name: "Points", Type: notiontypes.ColumnTypeNumber},
+			"tg":    {Name: "Tags", Type: notiontypes.ColumnMultiSelect},
+		},
+	}
+	due := time.Date(2024, 5, 1, 15, 0, 0, 0, time.UTC)
+	for _, c := range []struct {
+		f    *Filter
+		want string
+	}{
+		{
+			Where("Status").Is("Done").And(Where("due").Before(due)),
+			`{"operator":"and","filters":[` +
+				`{"property":"st","filter":{"operator":"enum_is","value":{"type":"exact","value":"Done"}}},` +
+				`{"property":"du","filter":{"operator":"date_is_before","value":{"type":"exact","value":{"start_date":"2024-05-01","type":"date"}}}}]}`,
+		},
+		{
+			Where("Points").GreaterThan(2).And(Where("Name").Contains("bug")).And(Where("Tags").IsEmpty()),
+			`{"operator":"and","filters":[` +
+				`{"property":"pt","filter":{"operator":"number_greater_than","value":{"type":"exact","value":2}}},` +
+				`{"property":"title","filter":{"operator":"string_contains","value":{"type":"exact","value":"bug"}}},` +
+				`{"property":"tg","filter":{"operator":"is_empty"}}]}`,
+		},
+		{
+			Where("Tags").Contains("ops").Or(All(Where("Status").IsNot("Done"), Where("Points").Is(3))),
+			`{"operator":"or","filters":[` +
+				`{"property":"tg","filter":{"operator":"enum_contains","value":{"type":"exact","value":"ops"}}},` +
+				`{"filter":{"operator":"and","filters":[` +
+				`{"property":"st","filter":{"operator":"enum_is_not","value":{"type":"exact","value":"Done"}}},` +
+				`{"property":"pt","filter":{"operator":"number_equals","value":{"type":"exact","value":3}}}]}}]}`,
+		},
+		{
+			Where("Status").IsNotEmpty(),
+			`{"operator":"and","filters":[{"property":"st","filter":{"operator":"is_not_empty"}}]}`,
+		},
+	} {
+		got, err := c.f.Compile(collection)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := json.Marshal(got)
+		if string(b) != c.want {
+			t.Errorf("got\n%s\nwant\n%s", b, c.want)
+		}
+	}
+
+	for _, f := range []*Filter{
+		Where("Missing").Is("x"),
+		Where("Status").Before(due),
+		Where("Points").Is("three"),
+		Where("Name").Is(true),
+		Where("Due").Is(struct{}{}),
+	} {
+		if _, err := f.Compile(collection); err == nil {
+			t.Errorf("expected error for %+v", f)
+		}
+	}
+}
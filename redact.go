@@ -0,0 +1,84 @@
+package notion
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const redacted = "[REDACTED]"
+
+var (
+	logTokenPattern = regexp.MustCompile(`(?i)(token(?:_v2)?["']?\s*[:=]\s*["']?)[^"',;&\s]+`)
+	logEmailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+)
+
+// logBody is a request or response body as logged by a Client. It is
+// redacted only when formatted, i.e. if the log level is enabled.
+type logBody struct {
+	c    *Client
+	body []byte
+}
+
+func (c *Client) logBody(body []byte) logBody {
+	return logBody{c, body}
+}
+
+func (b logBody) String() string {
+	return b.c.redact(b.body)
+}
+
+// MarshalText makes JSON formatters log the body as a string.
+func (b logBody) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// redact masks the client's token, other tokens, email addresses and the
+// values of redacted properties in body, and truncates the result to the
+// log body limit.
+func (c *Client) redact(body []byte) string {
+	s := string(body)
+	if len(c.redactedProperties) > 0 {
+		var v interface{}
+		if err := json.Unmarshal(body, &v); err == nil {
+			if b, err := json.Marshal(c.redactProperties(v)); err == nil {
+				s = string(b)
+			}
+		}
+	}
+	if c.token != "" {
+		s = strings.Replace(s, c.token, redacted, -1)
+	}
+	s = logTokenPattern.ReplaceAllString(s, "${1}"+redacted)
+	s = logEmailPattern.ReplaceAllString(s, redacted)
+	if n := c.logBodyLimit; n > 0 && len(s) > n {
+		// don't split a character
+		for n > 0 && !utf8.RuneStart(s[n]) {
+			n--
+		}
+		s = fmt.Sprintf("%s... (%d bytes truncated)", s[:n], len(s)-n)
+	}
+	return s
+}
+
+// redactProperties replaces the values of redacted properties within the
+// decoded JSON value v.
+func (c *Client) redactProperties(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, value := range v {
+			if c.redactedProperties[strings.ToLower(k)] {
+				v[k] = redacted
+				continue
+			}
+			v[k] = c.redactProperties(value)
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = c.redactProperties(value)
+		}
+	}
+	return v
+}
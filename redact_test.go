@@ -0,0 +1,26 @@
+package notion
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	c, _ := NewClient(WithToken("s3cr3t"), WithRedactedProperties("Salary"))
+	body := `{"token":"abc","user":{"email":"jane@example.com","salary":[["100k"]]},"cookie":"token_v2=def; s3cr3t"}`
+	got := c.redact([]byte(body))
+	for _, secret := range []string{"abc", "jane@example.com", "100k", "def", "s3cr3t"} {
+		if strings.Contains(got, secret) {
+			t.Errorf("%q not redacted in %s", secret, got)
+		}
+	}
+
+	c, _ = NewClient(WithLogBodyLimit(10))
+	if got, want := c.redact([]byte("0123456789abcdef")), "0123456789... (6 bytes truncated)"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	// "é" is 2 bytes, the limit falls within the fifth one
+	if got, want := c.redact([]byte("aéééééé")), "aéééé... (4 bytes truncated)"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,172 @@
+package notion
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// Reminder is a date on a page: a date mentioned in its text, or the value
+// of a date property of a database row.
+type Reminder struct {
+	// PageID and Page are the id and title of the page or row the date is on.
+	PageID string
+	Page   string
+	// BlockID is the id of the block mentioning the date, or empty for
+	// properties.
+	BlockID string
+	// Property is the name of the date property, or empty for mentions.
+	Property string
+	// Text is the text of the block mentioning the date, or the title of
+	// the row.
+	Text string
+	Date *notiontypes.Date
+	// Start is when the date starts.
+	Start time.Time
+	// RemindAt is when notion reminds of the date, or the zero time if it
+	// has no reminder.
+	RemindAt time.Time
+}
+
+// Due returns the time of the reminder of r, or the start of its date if it
+// has no reminder.
+func (r *Reminder) Due() time.Time {
+	if !r.RemindAt.IsZero() {
+		return r.RemindAt
+	}
+	return r.Start
+}
+
+// ReminderOptions configures Reminders.
+type ReminderOptions struct {
+	// From and To select the reminders due from From up to, but excluding,
+	// To. A zero To selects all reminders due after From.
+	From, To time.Time
+	// OnlyReminders skips dates without a reminder.
+	OnlyReminders bool
+	// Location is the time zone of dates without one. It defaults to
+	// time.Local.
+	Location *time.Location
+	// Filter selects the pages searched.
+	Filter *Filter
+}
+
+// Reminders returns the dates mentioned in the page rootID and its
+// sub-pages, and the date properties of the rows of databases on these
+// pages, that are due within the range of opts, sorted by when they are due.
+// Pages and blocks that can't be read are skipped with a warning.
+func (c *Client) Reminders(rootID string, opts ReminderOptions) ([]*Reminder, error) {
+	if opts.Location == nil {
+		opts.Location = time.Local
+	}
+	s := &reminderScan{c: c, opts: opts, collections: make(map[string]bool)}
+	r, err := c.CrawlWithOptions(rootID, CrawlOptions{Filter: opts.Filter}, func(p *Page, ancestors []string) error {
+		s.mentions(p.Block, p.Block.Content)
+		return s.databases(p.Block.Content)
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.warnSkipped(r)
+	sort.SliceStable(s.res, func(i, j int) bool {
+		return s.res[i].Due().Before(s.res[j].Due())
+	})
+	return s.res, nil
+}
+
+type reminderScan struct {
+	c    *Client
+	opts ReminderOptions
+	// ids of the collections scanned so far
+	collections map[string]bool
+	res         []*Reminder
+}
+
+func (s *reminderScan) add(r *Reminder) {
+	var err error
+	if r.Start, err = r.Date.Start(s.opts.Location); err != nil {
+		s.c.logger.WithError(err).WithField("pageID", r.PageID).Warnln("skipping invalid date")
+		return
+	}
+	remindAt, ok, err := r.Date.RemindAt(s.opts.Location)
+	if err != nil {
+		s.c.logger.WithError(err).WithField("pageID", r.PageID).Warnln("skipping invalid reminder")
+		return
+	}
+	if ok {
+		r.RemindAt = remindAt
+	} else if s.opts.OnlyReminders {
+		return
+	}
+	due := r.Due()
+	if due.Before(s.opts.From) || !s.opts.To.IsZero() && !due.Before(s.opts.To) {
+		return
+	}
+	s.res = append(s.res, r)
+}
+
+func (s *reminderScan) mentions(page *notiontypes.Block, blocks []*notiontypes.Block) {
+	for _, b := range blocks {
+		if b.IsPage() {
+			continue
+		}
+		for _, i := range b.InlineContent {
+			if i.Date != nil {
+				s.add(&Reminder{PageID: page.ID, Page: page.Title, BlockID: b.ID, Text: inlineText(b.InlineContent), Date: i.Date})
+			}
+		}
+		s.mentions(page, b.Content)
+	}
+}
+
+func (s *reminderScan) databases(blocks []*notiontypes.Block) error {
+	for _, b := range blocks {
+		if b.IsPage() {
+			continue
+		}
+		if b.CollectionID != "" && !s.collections[b.CollectionID] {
+			s.collections[b.CollectionID] = true
+			if err := s.rows(b); err != nil {
+				return err
+			}
+		}
+		if err := s.databases(b.Content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *reminderScan) rows(view *notiontypes.Block) error {
+	collection, err := s.c.GetCollection(view.CollectionID)
+	if err != nil {
+		return errors.Wrapf(err, "getting database %v", view.CollectionID)
+	}
+	viewID := ""
+	if len(view.ViewIDs) > 0 {
+		viewID = view.ViewIDs[0]
+	}
+	rows, err := s.c.QueryCollection(view.CollectionID, viewID)
+	if err != nil {
+		return errors.Wrapf(err, "querying database %v", view.CollectionID)
+	}
+	for _, row := range rows {
+		for _, p := range collection.PageProperties(row) {
+			if d := p.Date(); d != nil {
+				s.add(&Reminder{PageID: row.ID, Page: row.Title, Property: p.Name, Text: row.Title, Date: d})
+			}
+		}
+	}
+	return nil
+}
+
+func inlineText(inline []*notiontypes.InlineBlock) string {
+	var b strings.Builder
+	for _, i := range inline {
+		b.WriteString(i.Text)
+	}
+	return b.String()
+}
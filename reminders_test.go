@@ -0,0 +1,85 @@
+package notion_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/notiontest"
+	"github.com/tmc/notion/notiontypes"
+)
+
+func TestReminders(t *testing.T) {
+	s := notiontest.NewServer()
+	defer s.Close()
+	const (
+		pageID = "4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+		dbID   = "5b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+	)
+	mention := func(date map[string]interface{}) []interface{} {
+		return []interface{}{"‣", []interface{}{[]interface{}{"d", date}}}
+	}
+	s.AddRecord(notiontypes.TableCollection, dbID, map[string]interface{}{
+		"schema": map[string]interface{}{
+			"title": map[string]string{"name": "Name", "type": "title"},
+			"due":   map[string]string{"name": "Due", "type": "date"},
+		},
+	})
+	s.AddBlock(&notiontypes.Block{
+		ID: "6b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e", Type: notiontypes.BlockPage, ParentID: dbID, ParentTable: notiontypes.TableCollection,
+		Properties: map[string]interface{}{
+			"title": [][]string{{"Ship it"}},
+			"due":   []interface{}{mention(map[string]interface{}{"type": "date", "start_date": "2019-03-02"})},
+		},
+	})
+	s.AddBlock(&notiontypes.Block{
+		ID:         pageID,
+		Type:       notiontypes.BlockPage,
+		Properties: map[string]interface{}{"title": [][]string{{"Plans"}}},
+		Content: []*notiontypes.Block{
+			{
+				ID:   "7b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e",
+				Type: notiontypes.BlockText,
+				Properties: map[string]interface{}{"title": []interface{}{
+					[]interface{}{"Call Bob "},
+					mention(map[string]interface{}{
+						"type": "datetime", "start_date": "2019-03-01", "start_time": "15:00",
+						"reminder": map[string]interface{}{"unit": "minute", "value": 30},
+					}),
+				}},
+			},
+			{
+				ID:   "8b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e",
+				Type: notiontypes.BlockText,
+				Properties: map[string]interface{}{"title": []interface{}{
+					[]interface{}{"Long gone "},
+					mention(map[string]interface{}{"type": "date", "start_date": "2018-01-01"}),
+				}},
+			},
+			{ID: "9b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e", Type: notiontypes.BlockCollectionView, CollectionID: dbID, ViewIDs: []string{"view"}},
+		},
+	})
+	c := s.Client()
+	from := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+	rs, err := c.Reminders(pageID, notion.ReminderOptions{From: from, To: from.AddDate(0, 0, 7), Location: time.UTC})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rs) != 2 {
+		t.Fatalf("got %d reminders, want 2", len(rs))
+	}
+	if r := rs[0]; r.Text != "Call Bob ‣" || !r.RemindAt.Equal(time.Date(2019, 3, 1, 14, 30, 0, 0, time.UTC)) {
+		t.Errorf("got reminder %+v", r)
+	}
+	if r := rs[1]; r.Property != "Due" || r.Page != "Ship it" || !r.Start.Equal(time.Date(2019, 3, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("got reminder %+v", r)
+	}
+
+	rs, err = c.Reminders(pageID, notion.ReminderOptions{From: from, OnlyReminders: true, Location: time.UTC})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rs) != 1 {
+		t.Errorf("got %d reminders, want only the one with a reminder", len(rs))
+	}
+}
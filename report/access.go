@@ -0,0 +1,139 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// AccessEntry is the access of a user, group, the workspace or the
+// public to a page.
+type AccessEntry struct {
+	PageID string
+	Page   string
+	// Type is the permission type, e.g. notiontypes.PermissionTypeUser.
+	Type string
+	// PrincipalID is the id of the user or group, if any, and Principal
+	// its name.
+	PrincipalID string
+	Principal   string
+	Role        string
+	// Inherited is set if the permission is inherited from an ancestor.
+	Inherited bool
+}
+
+// Public reports whether the entry grants access to anyone with the link.
+func (e *AccessEntry) Public() bool {
+	return e.Type == notiontypes.PermissionTypePublic && e.Role != notiontypes.RoleNone
+}
+
+// AccessReport lists who can access the pages of a subtree.
+type AccessReport struct {
+	GeneratedAt time.Time
+	// Entries are in crawl order.
+	Entries []*AccessEntry
+	// Skipped are the pages and blocks that couldn't be read, whose access
+	// isn't reviewed.
+	Skipped []*notion.RestrictedBlock
+}
+
+// Access crawls the page rootID and its sub-pages, selected by filter, and
+// lists the permissions of each page. Pages without permissions of their
+// own inherit those of their parent. Pages and blocks that can't be read
+// are skipped and listed in the report.
+func Access(c *notion.Client, rootID string, filter *notion.Filter) (*AccessReport, error) {
+	r := &AccessReport{GeneratedAt: time.Now()}
+	effective := make(map[string][]notiontypes.Permission)
+	users := make(map[string]bool)
+	res, err := c.CrawlWithOptions(rootID, notion.CrawlOptions{Filter: filter}, func(p *notion.Page, ancestors []string) error {
+		perms, inherited := []notiontypes.Permission(nil), false
+		if p.Permissions != nil && len(*p.Permissions) > 0 {
+			perms = *p.Permissions
+		} else if len(ancestors) > 0 {
+			perms, inherited = effective[ancestors[len(ancestors)-1]], true
+		}
+		effective[p.ID] = perms
+		for _, perm := range perms {
+			e := &AccessEntry{PageID: p.ID, Page: p.Title, Type: perm.Type, Role: perm.Role, Inherited: inherited}
+			switch {
+			case perm.UserID != nil:
+				e.PrincipalID = *perm.UserID
+				users[e.PrincipalID] = true
+			case perm.GroupID != nil:
+				e.PrincipalID = *perm.GroupID
+				e.Principal = e.PrincipalID
+			case perm.Type == notiontypes.PermissionTypePublic:
+				e.Principal = "public"
+			case perm.Type == notiontypes.PermissionTypeSpace:
+				e.Principal = "workspace"
+			}
+			r.Entries = append(r.Entries, e)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "crawling pages")
+	}
+	r.Skipped = res.Skipped
+	names, err := userNames(c, users)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting users")
+	}
+	for _, e := range r.Entries {
+		if e.Type == notiontypes.PermissionTypeUser {
+			e.Principal = names[e.PrincipalID]
+		}
+	}
+	return r, nil
+}
+
+// PublicPages returns the entries of pages shared with anyone with the link.
+func (r *AccessReport) PublicPages() []*AccessEntry {
+	var res []*AccessEntry
+	for _, e := range r.Entries {
+		if e.Public() {
+			res = append(res, e)
+		}
+	}
+	return res
+}
+
+// WriteCSV writes the report as CSV with one row per entry.
+func (r *AccessReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"page_id", "page", "type", "principal_id", "principal", "role", "inherited", "public"})
+	for _, e := range r.Entries {
+		cw.Write([]string{e.PageID, e.Page, e.Type, e.PrincipalID, e.Principal, e.Role, strconv.FormatBool(e.Inherited), strconv.FormatBool(e.Public())})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteMarkdown writes the report as Markdown, listing publicly shared
+// pages first.
+func (r *AccessReport) WriteMarkdown(w io.Writer) error {
+	fmt.Fprintf(w, "# Access review\n\nGenerated %v.\n", r.GeneratedAt.Format("2006-01-02"))
+	if public := r.PublicPages(); len(public) > 0 {
+		fmt.Fprintf(w, "\n## Publicly shared pages\n\n")
+		for _, e := range public {
+			fmt.Fprintf(w, "- [%s](%s) (%s)\n", markdownCell(e.Page), pageURL(e.PageID), e.Role)
+		}
+	}
+	fmt.Fprintf(w, "\n## Permissions\n\n| Page | Who | Role | Inherited |\n| --- | --- | --- | --- |\n")
+	for _, e := range r.Entries {
+		inherited := ""
+		if e.Inherited {
+			inherited = "yes"
+		}
+		fmt.Fprintf(w, "| [%s](%s) | %s | %s | %s |\n", markdownCell(e.Page), pageURL(e.PageID), markdownCell(e.Principal), e.Role, inherited)
+	}
+	writeSkipped(w, r.Skipped)
+	_, err := fmt.Fprintln(w)
+	return err
+}
@@ -0,0 +1,137 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// ActivityEntry counts the edits of a user on a page.
+type ActivityEntry struct {
+	UserID   string    `json:"user_id"`
+	User     string    `json:"user"`
+	PageID   string    `json:"page_id"`
+	Page     string    `json:"page"`
+	Edits    int       `json:"edits"`
+	LastEdit time.Time `json:"last_edit"`
+}
+
+// ActivityReport aggregates the edits made between From and To per user
+// and page.
+type ActivityReport struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+	// Entries are sorted by number of edits, most first.
+	Entries []*ActivityEntry `json:"entries"`
+}
+
+// Activity aggregates the edits on the page rootID and its sub-pages made
+// between from and to, using the activity log of the workspace.
+func Activity(c *notion.Client, rootID string, from, to time.Time) (*ActivityReport, error) {
+	root, err := c.GetRecordValues(notion.Record{Table: notiontypes.TableBlock, ID: rootID})
+	if err != nil {
+		return nil, errors.Wrap(err, "getting root page")
+	}
+	if len(root) == 0 || root[0].Value == nil {
+		return nil, errors.Errorf("report: page %v not found", rootID)
+	}
+	activities, err := c.GetActivityLog(root[0].Value.SpaceID, rootID, from)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting activity log")
+	}
+
+	type key struct{ user, page string }
+	entries := make(map[key]*ActivityEntry)
+	r := &ActivityReport{From: from, To: to}
+	for _, a := range activities {
+		for _, e := range a.Edits {
+			t := e.EditedOn()
+			if t.Before(from) || !t.Before(to) {
+				continue
+			}
+			for _, author := range e.Authors {
+				if author.Table != "notion_user" {
+					continue
+				}
+				k := key{author.ID, a.NavigableBlockID}
+				entry, ok := entries[k]
+				if !ok {
+					entry = &ActivityEntry{UserID: author.ID, PageID: a.NavigableBlockID}
+					entries[k] = entry
+					r.Entries = append(r.Entries, entry)
+				}
+				entry.Edits++
+				if t.After(entry.LastEdit) {
+					entry.LastEdit = t
+				}
+			}
+		}
+	}
+	if err := r.resolveNames(c); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(r.Entries, func(i, j int) bool {
+		a, b := r.Entries[i], r.Entries[j]
+		if a.Edits != b.Edits {
+			return a.Edits > b.Edits
+		}
+		if a.User != b.User {
+			return a.User < b.User
+		}
+		if a.Page != b.Page {
+			return a.Page < b.Page
+		}
+		if a.UserID != b.UserID {
+			return a.UserID < b.UserID
+		}
+		return a.PageID < b.PageID
+	})
+	return r, nil
+}
+
+func (r *ActivityReport) resolveNames(c *notion.Client) error {
+	users := make(map[string]bool)
+	pages := make(map[string]bool)
+	for _, e := range r.Entries {
+		users[e.UserID] = true
+		pages[e.PageID] = true
+	}
+	names, err := userNames(c, users)
+	if err != nil {
+		return errors.Wrap(err, "getting users")
+	}
+	titles, err := pageTitles(c, pages)
+	if err != nil {
+		return errors.Wrap(err, "getting pages")
+	}
+	for _, e := range r.Entries {
+		e.User = names[e.UserID]
+		e.Page = titles[e.PageID]
+	}
+	return nil
+}
+
+// WriteJSON writes the report as JSON.
+func (r *ActivityReport) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteCSV writes the report as CSV with one row per entry.
+func (r *ActivityReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"user", "user_id", "page", "page_id", "edits", "last_edit"})
+	for _, e := range r.Entries {
+		cw.Write([]string{e.User, e.UserID, e.Page, e.PageID, strconv.Itoa(e.Edits), e.LastEdit.UTC().Format(time.RFC3339)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,325 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// JoinSpec describes a report combining the rows of a collection with the
+// rows of the collections they're related to, e.g. tasks with their
+// projects, which notion can't export.
+type JoinSpec struct {
+	// CollectionID is the collection whose rows are reported.
+	CollectionID string `json:"collection_id"`
+	// Columns are the names of the reported properties of the rows, all
+	// of them, title first, if empty.
+	Columns []string `json:"columns,omitempty"`
+	// Joins are followed in order.
+	Joins []*Join `json:"joins"`
+}
+
+// Join follows a relation property to the related rows. Like an SQL join,
+// a row related to several rows is reported once for each of them.
+type Join struct {
+	// Relation is the name of the relation property followed.
+	Relation string `json:"relation"`
+	// From is the Name of an earlier join whose rows have the relation,
+	// e.g. to report the clients of the projects of tasks. By default the
+	// relation is a property of the rows of JoinSpec.CollectionID.
+	From string `json:"from,omitempty"`
+	// Name prefixes the columns of the related rows, as in
+	// "Project.Status". It defaults to the name of the relation.
+	Name string `json:"name,omitempty"`
+	// Columns are the names of the reported properties of the related
+	// rows, all of them if empty.
+	Columns []string `json:"columns,omitempty"`
+	// Inner drops the rows without related rows, which are otherwise
+	// reported with empty related columns.
+	Inner bool `json:"inner,omitempty"`
+}
+
+// JoinReport is the result of JoinRows: one row per combination of joined
+// rows, with a column per reported property.
+type JoinReport struct {
+	GeneratedAt time.Time  `json:"generated_at"`
+	Columns     []string   `json:"columns"`
+	Rows        [][]string `json:"rows"`
+}
+
+// joined is a row of the report under construction: the joined rows by
+// join name, "" for the row of the base collection.
+type joined map[string]*notiontypes.Block
+
+// joinSource is the collection and reported properties of a join.
+type joinSource struct {
+	name       string
+	collection *notiontypes.Collection
+	columns    []string // property ids
+}
+
+// JoinRows generates the report described by spec. Property values are
+// reported as text, with dates as 2006-01-02 or 2006-01-02 15:04 and
+// relations as the titles of the related pages.
+func JoinRows(c *notion.Client, spec *JoinSpec) (*JoinReport, error) {
+	base, err := c.GetCollection(spec.CollectionID)
+	if err != nil {
+		return nil, err
+	}
+	baseRows, err := c.QueryCollection(base.ID, "")
+	if err != nil {
+		return nil, err
+	}
+	src := &joinSource{collection: base}
+	if src.columns, err = columnIDs(base, spec.Columns); err != nil {
+		return nil, err
+	}
+	sources := []*joinSource{src}
+	byName := map[string]*joinSource{"": src}
+	pages := map[string]*notiontypes.Block{}
+	for _, b := range baseRows {
+		pages[b.ID] = b
+	}
+	rows := make([]joined, len(baseRows))
+	for i, b := range baseRows {
+		rows[i] = joined{"": b}
+	}
+
+	for _, j := range spec.Joins {
+		from, ok := byName[j.From]
+		if !ok {
+			return nil, errors.Errorf("report: join %q follows unknown join %q", j.Relation, j.From)
+		}
+		relID, info := schemaProperty(from.collection, j.Relation)
+		if info == nil || info.Type != notiontypes.ColumnTypeRelation {
+			return nil, errors.Errorf("report: %q isn't a relation property of collection %v", j.Relation, from.collection.ID)
+		}
+		name := j.Name
+		if name == "" {
+			name = info.Name
+		}
+		if _, ok := byName[name]; ok {
+			return nil, errors.Errorf("report: duplicate join %q", name)
+		}
+
+		var missing []string
+		seen := map[string]bool{}
+		for _, row := range rows {
+			if b := row[j.From]; b != nil {
+				for _, id := range from.collection.Property(b, relID).PageIDs() {
+					if pages[id] == nil && !seen[id] {
+						seen[id] = true
+						missing = append(missing, id)
+					}
+				}
+			}
+		}
+		if err := loadPages(c, pages, missing); err != nil {
+			return nil, err
+		}
+
+		target := &joinSource{name: name}
+		collectionID := info.CollectionID
+		var expanded []joined
+		for _, row := range rows {
+			var related []*notiontypes.Block
+			if b := row[j.From]; b != nil {
+				for _, id := range from.collection.Property(b, relID).PageIDs() {
+					if p := pages[id]; p != nil && p.Alive {
+						related = append(related, p)
+						if collectionID == "" && p.ParentTable == notiontypes.TableCollection {
+							collectionID = p.ParentID
+						}
+					}
+				}
+			}
+			if len(related) == 0 && !j.Inner {
+				related = []*notiontypes.Block{nil}
+			}
+			for _, p := range related {
+				next := joined{name: p}
+				for k, v := range row {
+					next[k] = v
+				}
+				expanded = append(expanded, next)
+			}
+		}
+		rows = expanded
+		if collectionID != "" {
+			if target.collection, err = c.GetCollection(collectionID); err != nil {
+				return nil, err
+			}
+			if target.columns, err = columnIDs(target.collection, j.Columns); err != nil {
+				return nil, err
+			}
+		}
+		sources = append(sources, target)
+		byName[name] = target
+	}
+
+	r := &JoinReport{GeneratedAt: time.Now()}
+	for _, s := range sources {
+		for _, id := range s.columns {
+			col := s.collection.CollectionSchema[id].Name
+			if s.name != "" {
+				col = s.name + "." + col
+			}
+			r.Columns = append(r.Columns, col)
+		}
+	}
+	for _, row := range rows {
+		var cells []string
+		for _, s := range sources {
+			b := row[s.name]
+			for _, id := range s.columns {
+				if b == nil {
+					cells = append(cells, "")
+					continue
+				}
+				cells = append(cells, joinText(s.collection.Property(b, id), pages))
+			}
+		}
+		r.Rows = append(r.Rows, cells)
+	}
+	return r, nil
+}
+
+// loadPages adds the pages ids, loaded in batches, to pages.
+func loadPages(c *notion.Client, pages map[string]*notiontypes.Block, ids []string) error {
+	const batch = 100
+	for len(ids) > 0 {
+		n := len(ids)
+		if n > batch {
+			n = batch
+		}
+		records := make([]notion.Record, n)
+		for i, id := range ids[:n] {
+			records[i] = notion.Record{Table: notiontypes.TableBlock, ID: id}
+		}
+		results, err := c.GetRecordValues(records...)
+		if err != nil {
+			return errors.Wrap(err, "getting related pages")
+		}
+		for _, r := range results {
+			if r.Value == nil {
+				continue
+			}
+			if err := notiontypes.ParseBlock(r.Value); err != nil {
+				return errors.Wrapf(err, "parsing page %v", r.Value.ID)
+			}
+			pages[r.Value.ID] = r.Value
+		}
+		ids = ids[n:]
+	}
+	return nil
+}
+
+// columnIDs returns the ids of the properties of collection named names,
+// or of all its properties, title first then by name.
+func columnIDs(collection *notiontypes.Collection, names []string) ([]string, error) {
+	var ids []string
+	if len(names) == 0 {
+		for id := range collection.CollectionSchema {
+			ids = append(ids, id)
+		}
+		s := collection.CollectionSchema
+		sort.Slice(ids, func(i, j int) bool {
+			a, b := s[ids[i]], s[ids[j]]
+			if (a.Type == notiontypes.ColumnTypeTitle) != (b.Type == notiontypes.ColumnTypeTitle) {
+				return a.Type == notiontypes.ColumnTypeTitle
+			}
+			return a.Name < b.Name
+		})
+		return ids, nil
+	}
+	for _, name := range names {
+		id, info := schemaProperty(collection, name)
+		if info == nil {
+			return nil, errors.Errorf("report: collection %v has no property %q", collection.ID, name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func schemaProperty(collection *notiontypes.Collection, name string) (string, *notiontypes.CollectionColumnInfo) {
+	for id, info := range collection.CollectionSchema {
+		if strings.EqualFold(info.Name, name) {
+			return id, info
+		}
+	}
+	return "", nil
+}
+
+// joinText returns the value of p as text, with relations as the titles
+// of the related pages in pages, or their ids if they aren't loaded.
+func joinText(p *notiontypes.PageProperty, pages map[string]*notiontypes.Block) string {
+	if p == nil {
+		return ""
+	}
+	switch p.Type {
+	case notiontypes.ColumnTypeDate:
+		if d := p.Date(); d != nil {
+			if d.StartTime != nil {
+				return d.StartDate + " " + *d.StartTime
+			}
+			return d.StartDate
+		}
+	case notiontypes.ColumnTypeRelation:
+		var titles []string
+		for _, id := range p.PageIDs() {
+			if b := pages[id]; b != nil {
+				titles = append(titles, b.Title)
+			} else {
+				titles = append(titles, id)
+			}
+		}
+		return strings.Join(titles, ", ")
+	case notiontypes.ColumnTypePerson:
+		var ids []string
+		for _, i := range p.Value {
+			if i.UserID != "" {
+				ids = append(ids, i.UserID)
+			}
+		}
+		return strings.Join(ids, ", ")
+	}
+	return p.Text()
+}
+
+// WriteCSV writes the report as CSV with a header row.
+func (r *JoinReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write(r.Columns)
+	for _, row := range r.Rows {
+		cw.Write(row)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON writes the report as JSON, with the rows as objects keyed by
+// column.
+func (r *JoinReport) WriteJSON(w io.Writer) error {
+	rows := make([]map[string]string, len(r.Rows))
+	for i, row := range r.Rows {
+		rows[i] = make(map[string]string, len(row))
+		for j, v := range row {
+			rows[i][r.Columns[j]] = v
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		GeneratedAt time.Time           `json:"generated_at"`
+		Columns     []string            `json:"columns"`
+		Rows        []map[string]string `json:"rows"`
+	}{r.GeneratedAt, r.Columns, rows})
+}
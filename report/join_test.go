@@ -0,0 +1,112 @@
+package report
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tmc/notion/notiontest"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// relation returns the value of a relation property to the pages ids.
+func relation(ids ...string) []interface{} {
+	var v []interface{}
+	for i, id := range ids {
+		if i > 0 {
+			v = append(v, []interface{}{","})
+		}
+		v = append(v, []interface{}{notiontypes.InlineAt, []interface{}{[]interface{}{"p", id, "space"}}})
+	}
+	return v
+}
+
+func TestJoinRows(t *testing.T) {
+	s := notiontest.NewServer()
+	defer s.Close()
+	s.AddRecord(notiontypes.TableCollection, "tasks", map[string]interface{}{
+		"schema": map[string]interface{}{
+			"title": map[string]string{"name": "Name", "type": "title"},
+			"pj":    map[string]string{"name": "Project", "type": "relation", "collection_id": "projects"},
+		},
+	})
+	s.AddRecord(notiontypes.TableCollection, "projects", map[string]interface{}{
+		"schema": map[string]interface{}{
+			"title": map[string]string{"name": "Name", "type": "title"},
+			"st":    map[string]string{"name": "Status", "type": "select"},
+			"cl":    map[string]string{"name": "Client", "type": "relation"},
+		},
+	})
+	s.AddRecord(notiontypes.TableCollection, "clients", map[string]interface{}{
+		"schema": map[string]interface{}{
+			"title": map[string]string{"name": "Name", "type": "title"},
+		},
+	})
+	for i, r := range []struct {
+		id, collection string
+		props          map[string]interface{}
+	}{
+		{"t1", "tasks", map[string]interface{}{"title": [][]string{{"Design"}}, "pj": relation("p1")}},
+		{"t2", "tasks", map[string]interface{}{"title": [][]string{{"Build"}}, "pj": relation("p1", "p2")}},
+		{"t3", "tasks", map[string]interface{}{"title": [][]string{{"Triage"}}}},
+		{"p1", "projects", map[string]interface{}{"title": [][]string{{"Website"}}, "st": [][]string{{"Active"}}, "cl": relation("c1")}},
+		{"p2", "projects", map[string]interface{}{"title": [][]string{{"App"}}, "st": [][]string{{"Done"}}}},
+		{"c1", "clients", map[string]interface{}{"title": [][]string{{"ACME"}}}},
+	} {
+		s.AddBlock(&notiontypes.Block{
+			ID:          r.id,
+			Type:        notiontypes.BlockPage,
+			ParentID:    r.collection,
+			ParentTable: notiontypes.TableCollection,
+			CreatedTime: int64(i),
+			Properties:  r.props,
+		})
+	}
+	c := s.Client()
+
+	spec := &JoinSpec{
+		CollectionID: "tasks",
+		Joins: []*Join{
+			{Relation: "project", Columns: []string{"Name", "Status"}},
+			{Relation: "Client", From: "Project"},
+		},
+	}
+	r, err := JoinRows(c, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := r.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `Name,Project,Project.Name,Project.Status,Client.Name
+Design,Website,Website,Active,ACME
+Build,"Website, App",Website,Active,ACME
+Build,"Website, App",App,Done,
+Triage,,,,
+`
+	if buf.String() != want {
+		t.Errorf("got report\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	spec.Joins[0].Inner = true
+	if r, err = JoinRows(c, spec); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Rows) != 3 || r.Rows[2][0] != "Build" {
+		t.Errorf("got inner join rows %q", r.Rows)
+	}
+	buf.Reset()
+	if err := r.WriteJSON(&buf); err != nil || !strings.Contains(buf.String(), `"Client.Name": "ACME"`) {
+		t.Errorf("got json %s, %v", buf.String(), err)
+	}
+
+	if _, err := JoinRows(c, &JoinSpec{CollectionID: "tasks", Joins: []*Join{{Relation: "Name"}}}); err == nil {
+		t.Error("joined on a title")
+	}
+	runs, err := notiontypes.ParseInlineBlocks(relation("p1", "p2"))
+	if ids := (&notiontypes.PageProperty{Value: runs}).PageIDs(); err != nil || !reflect.DeepEqual(ids, []string{"p1", "p2"}) {
+		t.Errorf("got page ids %v, %v", ids, err)
+	}
+}
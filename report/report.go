@@ -0,0 +1,100 @@
+// Package report generates reports about the pages of a workspace.
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// pageURL returns the notion.so URL of the page id.
+func pageURL(id string) string {
+	return "https://www.notion.so/" + strings.Replace(id, "-", "", -1)
+}
+
+// userNames resolves the ids of users to their names, falling back to
+// the id for users that can't be read.
+func userNames(c *notion.Client, ids map[string]bool) (map[string]string, error) {
+	names := make(map[string]string, len(ids))
+	var list []string
+	for id := range ids {
+		names[id] = id
+		if id != "" {
+			list = append(list, id)
+		}
+	}
+	if len(list) == 0 {
+		return names, nil
+	}
+	users, err := c.GetUsers(list...)
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range users {
+		if name := u.Name(); name != "" {
+			names[u.ID] = name
+		}
+	}
+	return names, nil
+}
+
+// pageTitles resolves the ids of pages to their titles, falling back to
+// the id for pages that can't be read.
+func pageTitles(c *notion.Client, ids map[string]bool) (map[string]string, error) {
+	titles := make(map[string]string, len(ids))
+	var records []notion.Record
+	for id := range ids {
+		titles[id] = id
+		records = append(records, notion.Record{Table: notiontypes.TableBlock, ID: id})
+	}
+	if len(records) == 0 {
+		return titles, nil
+	}
+	blocks, err := c.GetRecordValues(records...)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range blocks {
+		if b.Value == nil {
+			continue
+		}
+		if err := notiontypes.ResolveBlock(b.Value, nil); err == nil && b.Value.Title != "" {
+			titles[b.Value.ID] = b.Value.Title
+		}
+	}
+	return titles, nil
+}
+
+// lastEdit returns the most recently edited block among b and its
+// content, not descending into sub-pages.
+func lastEdit(b *notiontypes.Block) *notiontypes.Block {
+	last := b
+	for _, child := range b.Content {
+		if child.IsPage() {
+			continue
+		}
+		if l := lastEdit(child); l.LastEditedTime > last.LastEditedTime {
+			last = l
+		}
+	}
+	return last
+}
+
+// markdownCell escapes s for use in a Markdown table cell.
+func markdownCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
+
+// writeSkipped lists the pages and blocks skipped by a report as Markdown.
+func writeSkipped(w io.Writer, skipped []*notion.RestrictedBlock) {
+	if len(skipped) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n## Pages that couldn't be read\n\n")
+	for _, b := range skipped {
+		fmt.Fprintf(w, "- [%s](%s)\n", b.ID, pageURL(b.ID))
+	}
+}
@@ -0,0 +1,126 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion"
+)
+
+// StalePage is a page that hasn't been edited for a while.
+type StalePage struct {
+	ID    string
+	Title string
+	URL   string
+	// LastEdited is the time of the last edit of the page or its content.
+	LastEdited time.Time
+}
+
+// Owner groups the stale pages last edited by a user.
+type Owner struct {
+	UserID string
+	Name   string
+	Pages  []*StalePage
+}
+
+// StaleReport lists the pages that weren't edited within Threshold of
+// GeneratedAt, grouped by the user who edited them last.
+type StaleReport struct {
+	Threshold   time.Duration
+	GeneratedAt time.Time
+	// Owners are sorted by name, their pages from least recently edited.
+	Owners []*Owner
+	// Skipped are the pages and blocks that couldn't be read.
+	Skipped []*notion.RestrictedBlock
+}
+
+// Stale crawls the page rootID and its sub-pages, selected by filter, for
+// pages that haven't been edited within threshold. Pages and blocks that
+// can't be read are skipped and listed in the report.
+func Stale(c *notion.Client, rootID string, threshold time.Duration, filter *notion.Filter) (*StaleReport, error) {
+	r := &StaleReport{Threshold: threshold, GeneratedAt: time.Now()}
+	cutoff := r.GeneratedAt.Add(-threshold)
+	byOwner := make(map[string]*Owner)
+	res, err := c.CrawlWithOptions(rootID, notion.CrawlOptions{Filter: filter}, func(p *notion.Page, ancestors []string) error {
+		last := lastEdit(p.Block)
+		edited := last.UpdatedOn()
+		if !edited.Before(cutoff) {
+			return nil
+		}
+		o, ok := byOwner[last.LastEditedBy]
+		if !ok {
+			o = &Owner{UserID: last.LastEditedBy}
+			byOwner[last.LastEditedBy] = o
+			r.Owners = append(r.Owners, o)
+		}
+		o.Pages = append(o.Pages, &StalePage{ID: p.ID, Title: p.Title, URL: pageURL(p.ID), LastEdited: edited})
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "crawling pages")
+	}
+	r.Skipped = res.Skipped
+	ids := make(map[string]bool, len(byOwner))
+	for id := range byOwner {
+		ids[id] = true
+	}
+	names, err := userNames(c, ids)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting users")
+	}
+	for _, o := range r.Owners {
+		o.Name = names[o.UserID]
+		sort.SliceStable(o.Pages, func(i, j int) bool {
+			if !o.Pages[i].LastEdited.Equal(o.Pages[j].LastEdited) {
+				return o.Pages[i].LastEdited.Before(o.Pages[j].LastEdited)
+			}
+			return o.Pages[i].ID < o.Pages[j].ID
+		})
+	}
+	sort.SliceStable(r.Owners, func(i, j int) bool {
+		if r.Owners[i].Name != r.Owners[j].Name {
+			return r.Owners[i].Name < r.Owners[j].Name
+		}
+		return r.Owners[i].UserID < r.Owners[j].UserID
+	})
+	return r, nil
+}
+
+// WriteCSV writes the report as CSV with one row per page.
+func (r *StaleReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"owner", "owner_id", "page_id", "title", "url", "last_edited", "days_since_edit"})
+	for _, o := range r.Owners {
+		for _, p := range o.Pages {
+			cw.Write([]string{o.Name, o.UserID, p.ID, p.Title, p.URL, p.LastEdited.UTC().Format(time.RFC3339), fmt.Sprint(r.daysSince(p))})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteMarkdown writes the report as Markdown with a table per owner.
+func (r *StaleReport) WriteMarkdown(w io.Writer) error {
+	fmt.Fprintf(w, "# Stale pages\n\nPages not edited for %d days as of %v.\n", int(r.Threshold.Hours()/24), r.GeneratedAt.Format("2006-01-02"))
+	for _, o := range r.Owners {
+		fmt.Fprintf(w, "\n## %s\n\n| Page | Last edited | Days |\n| --- | --- | --- |\n", markdownCell(o.Name))
+		for _, p := range o.Pages {
+			title := p.Title
+			if title == "" {
+				title = "Untitled"
+			}
+			fmt.Fprintf(w, "| [%s](%s) | %s | %d |\n", markdownCell(title), p.URL, p.LastEdited.Format("2006-01-02"), r.daysSince(p))
+		}
+	}
+	writeSkipped(w, r.Skipped)
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
+func (r *StaleReport) daysSince(p *StalePage) int {
+	return int(r.GeneratedAt.Sub(p.LastEdited).Hours() / 24)
+}
@@ -0,0 +1,231 @@
+// Package review runs a content approval workflow on the rows of a
+// database: rows tagged for review get a comment mentioning their
+// reviewers, and approved rows trigger publishing. A Workflow is the Sink
+// of a watch.Watcher of the database.
+package review
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/notiontypes"
+	"github.com/tmc/notion/watch"
+)
+
+// Workflow reacts to the events of the rows of a database. A row enters
+// review when its status property is set to ReviewStatus, and is approved
+// when its status is set to ApprovedStatus or its ApprovedProperty checkbox
+// is checked.
+//
+// Events are delivered at least once, and the watcher sends all events of a
+// poll again when one fails, so a Workflow remembers the version of the
+// rows it handled each event for and ignores events it already handled.
+// A comment may still be posted twice if the process stops while sending.
+//
+// Transient errors, such as network errors and rate limiting, are returned
+// from Send so that the watcher retries the poll. Other errors, e.g. missing
+// permissions or a failing publish, would fail every retry: they are
+// reported to OnError and the event is skipped.
+type Workflow struct {
+	Client *notion.Client
+	// StatusProperty is the select or multi-select property holding the
+	// status of rows, "Status" by default.
+	StatusProperty string
+	// ReviewStatus is the status of rows needing review, "Needs review" by
+	// default.
+	ReviewStatus string
+	// ReviewerProperty is the person property holding the reviewers of
+	// rows, "Reviewer" by default. Rows without reviewers still get a
+	// comment.
+	ReviewerProperty string
+	// Message is the text of review comments, after the mentions of the
+	// reviewers.
+	Message string
+	// ApprovedStatus is the status of approved rows, "Approved" by default.
+	ApprovedStatus string
+	// ApprovedProperty is an optional checkbox property approving rows.
+	ApprovedProperty string
+	// PublishStatus, if set, is the status approved rows are set to before
+	// Approve is called, e.g. the status the publishing pipeline publishes.
+	PublishStatus string
+	// Approve is called with the event approving a row, e.g. to publish
+	// its database.
+	Approve func(e *watch.Event) error
+	// OnError is called with the permanent errors of events, which are
+	// skipped. By default, errors are logged with the log package.
+	OnError func(e *watch.Event, err error)
+
+	// handled holds the version of the row each event was last handled
+	// for, by event type, row and property.
+	handled map[string]int64
+}
+
+// DefaultMessage is the text of review comments by default.
+const DefaultMessage = "This page is ready for your review."
+
+// Send implements watch.Sink.
+func (w *Workflow) Send(e *watch.Event) error {
+	if e.DatabaseID == "" {
+		return nil
+	}
+	key := fmt.Sprintf("%v %v %v", e.Type, e.PageID, strings.ToLower(e.Property))
+	if v, ok := w.handled[key]; ok && v >= e.Version {
+		return nil
+	}
+	if err := w.send(e); err != nil {
+		if transient(err) {
+			return err
+		}
+		if w.OnError != nil {
+			w.OnError(e, err)
+		} else {
+			log.Printf("review: %v of %v: %v", e.Type, e.PageID, err)
+		}
+	}
+	if w.handled == nil {
+		w.handled = make(map[string]int64)
+	}
+	w.handled[key] = e.Version
+	return nil
+}
+
+// transient reports whether err may succeed on another attempt.
+func transient(err error) bool {
+	switch err := errors.Cause(err).(type) {
+	case *notion.Error:
+		return err.StatusCode == http.StatusTooManyRequests || err.StatusCode >= 500
+	case net.Error:
+		return true
+	}
+	return errors.Cause(err) == notion.ErrCircuitOpen
+}
+
+func (w *Workflow) send(e *watch.Event) error {
+	switch e.Type {
+	case watch.PageCreated:
+		// rows may be created in review
+		row, collection, err := w.row(e)
+		if err != nil {
+			return err
+		}
+		if p := property(collection, row, w.statusProperty()); p != nil && hasValue(p.Text(), w.reviewStatus()) {
+			return w.requestReview(e.PageID, collection, row)
+		}
+	case watch.RowPropertyChanged:
+		switch {
+		case strings.EqualFold(e.Property, w.statusProperty()):
+			if entered(e, w.reviewStatus()) {
+				row, collection, err := w.row(e)
+				if err != nil {
+					return err
+				}
+				return w.requestReview(e.PageID, collection, row)
+			}
+			if entered(e, w.approvedStatus()) {
+				return w.approve(e)
+			}
+		case w.ApprovedProperty != "" && strings.EqualFold(e.Property, w.ApprovedProperty):
+			if strings.EqualFold(e.New, "Yes") && !strings.EqualFold(e.Old, "Yes") {
+				return w.approve(e)
+			}
+		}
+	}
+	return nil
+}
+
+// requestReview comments on row, mentioning its reviewers.
+func (w *Workflow) requestReview(id string, collection *notiontypes.Collection, row *notiontypes.Block) error {
+	var reviewers []string
+	if p := property(collection, row, w.reviewerProperty()); p != nil {
+		for _, v := range p.Value {
+			if v.UserID != "" {
+				reviewers = append(reviewers, v.UserID)
+			}
+		}
+	}
+	message := w.Message
+	if message == "" {
+		message = DefaultMessage
+	}
+	if _, err := w.Client.AddComment(id, message, reviewers...); err != nil {
+		return errors.Wrapf(err, "review: commenting on %v", id)
+	}
+	return nil
+}
+
+func (w *Workflow) approve(e *watch.Event) error {
+	if w.PublishStatus != "" {
+		if err := w.Client.UpdateRow(e.DatabaseID, e.PageID, map[string]string{w.statusProperty(): w.PublishStatus}); err != nil {
+			return errors.Wrapf(err, "review: setting status of %v", e.PageID)
+		}
+	}
+	if w.Approve == nil {
+		return nil
+	}
+	return w.Approve(e)
+}
+
+func (w *Workflow) row(e *watch.Event) (*notiontypes.Block, *notiontypes.Collection, error) {
+	row, err := w.Client.GetBlock(e.PageID)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "review: getting %v", e.PageID)
+	}
+	collection, err := w.Client.GetCollection(e.DatabaseID)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "review: getting database %v", e.DatabaseID)
+	}
+	return row, collection, nil
+}
+
+func (w *Workflow) statusProperty() string {
+	return orDefault(w.StatusProperty, "Status")
+}
+
+func (w *Workflow) reviewStatus() string {
+	return orDefault(w.ReviewStatus, "Needs review")
+}
+
+func (w *Workflow) reviewerProperty() string {
+	return orDefault(w.ReviewerProperty, "Reviewer")
+}
+
+func (w *Workflow) approvedStatus() string {
+	return orDefault(w.ApprovedStatus, "Approved")
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+// property returns the property of row named name, or nil.
+func property(collection *notiontypes.Collection, row *notiontypes.Block, name string) *notiontypes.PageProperty {
+	for id, col := range collection.CollectionSchema {
+		if strings.EqualFold(col.Name, name) {
+			return collection.Property(row, id)
+		}
+	}
+	return nil
+}
+
+// entered reports whether the property changed by e got the value status,
+// among the options of multi-select properties.
+func entered(e *watch.Event, status string) bool {
+	return hasValue(e.New, status) && !hasValue(e.Old, status)
+}
+
+func hasValue(text, value string) bool {
+	for _, v := range strings.Split(text, ",") {
+		if strings.EqualFold(strings.TrimSpace(v), value) {
+			return true
+		}
+	}
+	return false
+}
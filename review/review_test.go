@@ -0,0 +1,166 @@
+package review
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/tmc/notion/notiontest"
+	"github.com/tmc/notion/notiontypes"
+	"github.com/tmc/notion/watch"
+)
+
+func TestWorkflow(t *testing.T) {
+	const (
+		dbID     = "7b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+		rowID    = "8b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+		userID   = "9b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+		approved = "ap"
+	)
+	srv := notiontest.NewServer()
+	defer srv.Close()
+	srv.AddRecord(notiontypes.TableCollection, dbID, map[string]interface{}{
+		"schema": map[string]interface{}{
+			"title":  map[string]string{"name": "Name", "type": "title"},
+			"st":     map[string]string{"name": "Status", "type": "select"},
+			"rv":     map[string]string{"name": "Reviewer", "type": "person"},
+			approved: map[string]string{"name": "Approved", "type": "checkbox"},
+		},
+	})
+	version := int64(0)
+	row := func(status, checked string) *notiontypes.Block {
+		version++
+		return &notiontypes.Block{
+			ID: rowID, Version: version, Type: notiontypes.BlockPage, ParentID: dbID, ParentTable: notiontypes.TableCollection,
+			Properties: map[string]interface{}{
+				"title":  [][]string{{"Launch post"}},
+				"st":     [][]string{{status}},
+				"rv":     []interface{}{[]interface{}{"‣", []interface{}{[]interface{}{"u", userID}}}},
+				approved: [][]string{{checked}},
+			},
+		}
+	}
+	srv.AddBlock(row("Draft", "No"))
+	c := srv.Client()
+	w := watch.New(c, watch.WithDatabases(dbID))
+	var approvals []string
+	wf := &Workflow{
+		Client:           c,
+		ApprovedProperty: "Approved",
+		PublishStatus:    "Published",
+		Approve: func(e *watch.Event) error {
+			approvals = append(approvals, e.PageID)
+			return nil
+		},
+	}
+	poll := func() {
+		t.Helper()
+		events, err := w.Poll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, e := range events {
+			if err := wf.Send(e); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	poll()
+
+	srv.AddBlock(row("Needs review", "No"))
+	poll()
+	discussions := srv.Block(rowID).DiscussionIDs
+	if len(discussions) != 1 {
+		t.Fatalf("got discussions %v, want 1", discussions)
+	}
+	comments := srv.Record(notiontypes.TableDiscussion, discussions[0])["comments"].([]interface{})
+	text, err := notiontypes.ParseInlineBlocks(srv.Record(notiontypes.TableComment, comments[0].(string))["text"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(text) != 3 || text[0].UserID != userID || text[2].Text != DefaultMessage {
+		t.Errorf("got comment %#v", text)
+	}
+	if len(approvals) != 0 {
+		t.Errorf("approved %v before approval", approvals)
+	}
+
+	srv.AddBlock(row("Needs review", "Yes"))
+	poll()
+	if len(approvals) != 1 || approvals[0] != rowID {
+		t.Fatalf("got approvals %v", approvals)
+	}
+	status, err := notiontypes.ParseInlineBlocks(srv.Block(rowID).Properties["st"])
+	if err != nil || len(status) != 1 || status[0].Text != "Published" {
+		t.Errorf("got status %v, want Published", status)
+	}
+	poll()
+	if len(approvals) != 1 {
+		t.Errorf("got approvals %v after publishing", approvals)
+	}
+}
+
+func TestWorkflowRedelivery(t *testing.T) {
+	const (
+		dbID  = "7b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+		rowID = "8b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+	)
+	srv := notiontest.NewServer()
+	defer srv.Close()
+	srv.AddRecord(notiontypes.TableCollection, dbID, map[string]interface{}{
+		"schema": map[string]interface{}{
+			"title": map[string]string{"name": "Name", "type": "title"},
+			"st":    map[string]string{"name": "Status", "type": "select"},
+		},
+	})
+	row := func(version int64, status string) *notiontypes.Block {
+		return &notiontypes.Block{
+			ID: rowID, Version: version, Type: notiontypes.BlockPage, ParentID: dbID, ParentTable: notiontypes.TableCollection,
+			Properties: map[string]interface{}{
+				"title": [][]string{{"Launch post"}},
+				"st":    [][]string{{status}},
+			},
+		}
+	}
+	srv.AddBlock(row(1, "Draft"))
+	c := srv.Client()
+	w := watch.New(c, watch.WithDatabases(dbID))
+	var failed []string
+	wf := &Workflow{
+		Client: c,
+		Approve: func(e *watch.Event) error {
+			return errors.New("bad slug")
+		},
+		OnError: func(e *watch.Event, err error) {
+			failed = append(failed, e.PageID)
+		},
+	}
+	// send sends the events of a poll twice, as the watcher does when a
+	// later event of the poll fails
+	send := func() {
+		t.Helper()
+		events, err := w.Poll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 2; i++ {
+			for _, e := range events {
+				if err := wf.Send(e); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+	}
+	send()
+
+	srv.AddBlock(row(2, "Needs review"))
+	send()
+	if discussions := srv.Block(rowID).DiscussionIDs; len(discussions) != 1 {
+		t.Errorf("got discussions %v, want 1", discussions)
+	}
+
+	srv.AddBlock(row(3, "Approved"))
+	send()
+	if len(failed) != 1 || failed[0] != rowID {
+		t.Errorf("got failed approvals %v, want one of %v", failed, rowID)
+	}
+}
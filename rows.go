@@ -0,0 +1,195 @@
+package notion
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// CreateRow adds a row to the database collectionID, with the property
+// values given as in UpdateRow, and returns the id of the row.
+func (c *Client) CreateRow(collectionID string, values map[string]string) (string, error) {
+	collection, err := c.GetCollection(collectionID)
+	if err != nil {
+		return "", err
+	}
+	properties := make(map[string]interface{}, len(values))
+	for name, text := range values {
+		id, value, err := propertyValue(collection, name, text)
+		if err != nil {
+			return "", err
+		}
+		if value != nil {
+			properties[id] = value
+		}
+	}
+	id, err := FormatID(c.newID())
+	if err != nil {
+		return "", err
+	}
+	now := c.clock.Now().UnixNano() / int64(time.Millisecond)
+	args := map[string]interface{}{
+		"id":               id,
+		"type":             notiontypes.BlockPage,
+		"version":          1,
+		"alive":            true,
+		"parent_id":        collectionID,
+		"parent_table":     notiontypes.TableCollection,
+		"created_time":     now,
+		"last_edited_time": now,
+		"properties":       properties,
+	}
+	err = c.submitTransaction(&operation{
+		ID:      id,
+		Table:   notiontypes.TableBlock,
+		Path:    []string{},
+		Command: "set",
+		Args:    args,
+	})
+	if err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// UpdateRow sets properties of the row rowID of the database collectionID.
+// values holds the new values by (case-insensitive) property name, as text:
+// numbers in decimal, checkboxes as Yes or No, multiple options separated
+// by commas, relations as page ids separated by commas and dates as
+// 2006-01-02, optionally followed by a 15:04 time. Empty values clear
+// properties.
+func (c *Client) UpdateRow(collectionID, rowID string, values map[string]string) error {
+	rowID, err := FormatID(rowID)
+	if err != nil {
+		return err
+	}
+	collection, err := c.GetCollection(collectionID)
+	if err != nil {
+		return err
+	}
+	var ops []*operation
+	for name, text := range values {
+		id, value, err := propertyValue(collection, name, text)
+		if err != nil {
+			return err
+		}
+		if value == nil {
+			value = []interface{}{}
+		}
+		ops = append(ops, &operation{
+			ID:      rowID,
+			Table:   notiontypes.TableBlock,
+			Path:    []string{"properties", id},
+			Command: "set",
+			Args:    value,
+		})
+	}
+	ops = append(ops, &operation{
+		ID:      rowID,
+		Table:   notiontypes.TableBlock,
+		Path:    []string{"last_edited_time"},
+		Command: "set",
+		Args:    c.clock.Now().UnixNano() / int64(time.Millisecond),
+	})
+	return c.submitTransaction(ops...)
+}
+
+// propertyValue returns the id of the property name of collection and the
+// value to store for text, or nil if text is empty.
+func propertyValue(collection *notiontypes.Collection, name, text string) (string, interface{}, error) {
+	for id, col := range collection.CollectionSchema {
+		if !strings.EqualFold(col.Name, name) {
+			continue
+		}
+		if text == "" {
+			return id, nil, nil
+		}
+		if col.Type == notiontypes.ColumnTypeRelation {
+			value, err := relationValue(text)
+			if err != nil {
+				return "", nil, errors.Wrapf(err, "property %q", name)
+			}
+			return id, value, nil
+		}
+		if col.Type != notiontypes.ColumnTypeDate {
+			return id, [][]string{{text}}, nil
+		}
+		d, err := parseDate(text)
+		if err != nil {
+			return "", nil, errors.Wrapf(err, "property %q", name)
+		}
+		return id, []interface{}{[]interface{}{notiontypes.InlineAt, []interface{}{[]interface{}{"d", d}}}}, nil
+	}
+	return "", nil, errors.Errorf("notion: collection %v has no property %q", collection.ID, name)
+}
+
+// relationValue returns the value of a relation to the comma separated
+// page ids.
+func relationValue(text string) ([]interface{}, error) {
+	var value []interface{}
+	for i, id := range strings.Split(text, ",") {
+		id, err := FormatID(strings.TrimSpace(id))
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			value = append(value, []interface{}{","})
+		}
+		value = append(value, []interface{}{notiontypes.InlineAt, []interface{}{[]interface{}{"p", id}}})
+	}
+	return value, nil
+}
+
+func parseDate(s string) (*notiontypes.Date, error) {
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return &notiontypes.Date{Type: "date", StartDate: t.Format("2006-01-02")}, nil
+	}
+	t, err := time.Parse("2006-01-02 15:04", s)
+	if err != nil {
+		return nil, errors.Errorf("notion: invalid date %q, want 2006-01-02 or 2006-01-02 15:04", s)
+	}
+	start := t.Format("15:04")
+	return &notiontypes.Date{Type: "datetime", StartDate: t.Format("2006-01-02"), StartTime: &start}, nil
+}
+
+// Row is a row of a collection, see ForEachRow.
+type Row struct {
+	*notiontypes.Block
+	Collection *notiontypes.Collection
+}
+
+// Property returns the property of r with the given (case-insensitive)
+// name or id, or nil if the collection has none.
+func (r Row) Property(name string) *notiontypes.PageProperty {
+	id, _, err := schemaProperty(r.Collection, name)
+	if err != nil {
+		return nil
+	}
+	return r.Collection.Property(r.Block, id)
+}
+
+// RowQuery selects the rows visited by ForEachRow.
+type RowQuery struct {
+	// ViewID is the view whose order rows are visited in.
+	ViewID string
+	QueryOptions
+}
+
+// ForEachRow calls fn for each row of the collection collectionID selected
+// by query, as rows are loaded a page at a time, so that only a page of
+// rows is in memory. It stops at, and returns, the first error of fn.
+func (c *Client) ForEachRow(collectionID string, query RowQuery, fn func(Row) error) error {
+	collection, err := c.GetCollection(collectionID)
+	if err != nil {
+		return err
+	}
+	it := c.IterateCollection(collectionID, query.ViewID, query.QueryOptions)
+	for it.Next() {
+		if err := fn(Row{Block: it.Row(), Collection: collection}); err != nil {
+			return err
+		}
+	}
+	return it.Err()
+}
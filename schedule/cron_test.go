@@ -0,0 +1,37 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	// a Friday
+	from := time.Date(2019, 3, 1, 9, 30, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		expr string
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2019, 3, 1, 9, 45, 0, 0, time.UTC)},
+		{"0 9 * * 1-5", time.Date(2019, 3, 4, 9, 0, 0, 0, time.UTC)},
+		{"30 9 * * *", time.Date(2019, 3, 2, 9, 30, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2019, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2019, 3, 3, 0, 0, 0, 0, time.UTC)},
+		{"0 12 15 * 5", time.Date(2019, 3, 1, 12, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2020, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 8 * * 7", time.Date(2019, 3, 3, 8, 0, 0, 0, time.UTC)},
+	} {
+		c, err := Parse(tt.expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := c.Next(from); !got.Equal(tt.want) {
+			t.Errorf("%q: got %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+	for _, expr := range []string{"* * * *", "60 * * * *", "* * * * 8", "*/0 * * * *", "5-1 * * * *"} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("%q: expected error", expr)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package serve
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/tmc/notion/export"
+)
+
+// User is an authenticated user of a Server.
+type User struct {
+	Name string
+	// Groups are matched against the allowlists of the Server.
+	Groups []string
+}
+
+// Authenticator identifies the users of a Server. Authenticators that are
+// also http.Handlers, like OIDC, are served the requests of CallbackPath.
+type Authenticator interface {
+	// User returns the user making r, or nil if r isn't authenticated.
+	User(r *http.Request) *User
+	// Challenge asks the client of r to authenticate, e.g. by redirecting
+	// it to a login page.
+	Challenge(w http.ResponseWriter, r *http.Request)
+}
+
+// CallbackPath is the path of the requests served by Authenticators, e.g.
+// the redirect URL of OIDC.
+const CallbackPath = "/_auth/callback"
+
+// BasicAuth authenticates users with HTTP basic authentication, against a
+// static list of users.
+type BasicAuth struct {
+	// Realm is shown by browsers asking for credentials.
+	Realm string
+	// Users holds the passwords of users by name.
+	Users map[string]string
+	// Groups holds the groups of users by name.
+	Groups map[string][]string
+}
+
+// User implements Authenticator.
+func (a *BasicAuth) User(r *http.Request) *User {
+	name, password, ok := r.BasicAuth()
+	if !ok {
+		return nil
+	}
+	want, ok := a.Users[name]
+	// compares digests, whose length doesn't depend on the passwords
+	got, sum := sha256.Sum256([]byte(password)), sha256.Sum256([]byte(want))
+	if subtle.ConstantTimeCompare(got[:], sum[:]) != 1 || !ok {
+		return nil
+	}
+	return &User{Name: name, Groups: a.Groups[name]}
+}
+
+// Challenge implements Authenticator.
+func (a *BasicAuth) Challenge(w http.ResponseWriter, r *http.Request) {
+	realm := a.Realm
+	if realm == "" {
+		realm = "notion"
+	}
+	w.Header().Set("WWW-Authenticate", `Basic realm="`+strings.Replace(realm, `"`, "", -1)+`", charset="UTF-8"`)
+	http.Error(w, "unauthorized", http.StatusUnauthorized)
+}
+
+// userAllowed reports whether user may get page.
+func (s *Server) userAllowed(user *User, page *export.Page) bool {
+	if s.Allow == nil {
+		return true
+	}
+	ids := append([]string{page.ID}, page.Ancestors...)
+	for _, g := range user.Groups {
+		for _, allowed := range s.Allow[g] {
+			for _, id := range ids {
+				if compactID(id) == compactID(allowed) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func compactID(id string) string {
+	return strings.ToLower(strings.Replace(id, "-", "", -1))
+}
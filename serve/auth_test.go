@@ -0,0 +1,218 @@
+package serve
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBasicAuth(t *testing.T) {
+	s, ts := newServer(t)
+	s.Auth = &BasicAuth{
+		Users:  map[string]string{"ann": "pw1", "bob": "pw2"},
+		Groups: map[string][]string{"ann": {"hr"}, "bob": {"eng"}},
+	}
+	s.Allow = map[string][]string{"hr": {pageID}, "eng": {subPageID}}
+	root := ts.URL + "/" + s.exporter.Page(pageID).Path
+	sub := ts.URL + "/" + s.exporter.Page(subPageID).Path
+	for _, tt := range []struct {
+		user, password, url string
+		want                int
+	}{
+		{"", "", root, http.StatusUnauthorized},
+		{"ann", "wrong", root, http.StatusUnauthorized},
+		{"ann", "pw1", root, http.StatusOK},
+		// allowed with its parent
+		{"ann", "pw1", sub, http.StatusOK},
+		{"bob", "pw2", sub, http.StatusOK},
+		{"bob", "pw2", root, http.StatusForbidden},
+		// the redirect to the root page and missing pages don't tell
+		// which pages exist
+		{"", "", ts.URL + "/", http.StatusUnauthorized},
+		{"", "", ts.URL + "/missing.html", http.StatusUnauthorized},
+		{"ann", "pw1", ts.URL + "/", http.StatusOK},
+		{"bob", "pw2", ts.URL + "/", http.StatusForbidden},
+		{"bob", "pw2", ts.URL + "/missing.html", http.StatusForbidden},
+	} {
+		req, _ := http.NewRequest("GET", tt.url, nil)
+		if tt.user != "" {
+			req.SetBasicAuth(tt.user, tt.password)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.want {
+			t.Errorf("%v:%v %v: got %v, want %v", tt.user, tt.password, tt.url, resp.StatusCode, tt.want)
+		}
+	}
+}
+
+// provider is an OpenID Connect provider signing in everyone as a member
+// of groups.
+func provider(t *testing.T, groups ...string) *httptest.Server {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ts *httptest.Server
+	var nonce string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 ts.URL,
+			"authorization_endpoint": ts.URL + "/authorize",
+			"token_endpoint":         ts.URL + "/token",
+			"jwks_uri":               ts.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		nonce = q.Get("nonce")
+		http.Redirect(w, r, q.Get("redirect_uri")+"?"+url.Values{"code": {"c0de"}, "state": {q.Get("state")}}.Encode(), http.StatusFound)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if id, secret, _ := r.BasicAuth(); id != "client" || secret != "secret" || r.FormValue("code") != "c0de" {
+			http.Error(w, "invalid_grant", http.StatusBadRequest)
+			return
+		}
+		enc := func(v interface{}) string {
+			b, _ := json.Marshal(v)
+			return base64.RawURLEncoding.EncodeToString(b)
+		}
+		signed := enc(map[string]string{"alg": "RS256", "kid": "k1"}) + "." + enc(map[string]interface{}{
+			"iss": ts.URL, "aud": "client", "sub": "1", "email": "ann@example.com",
+			"iat": time.Now().Unix(), "nbf": time.Now().Unix(), "exp": time.Now().Add(time.Hour).Unix(),
+			"nonce": nonce, "groups": groups,
+		})
+		digest := sha256.Sum256([]byte(signed))
+		sig, _ := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		json.NewEncoder(w).Encode(map[string]string{"id_token": signed + "." + base64.RawURLEncoding.EncodeToString(sig)})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA", "kid": "k1",
+			"n": base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	ts = httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestOIDC(t *testing.T) {
+	s, ts := newServer(t)
+	idp := provider(t, "eng")
+	s.Auth = &OIDC{
+		Issuer:       idp.URL,
+		ClientID:     "client",
+		ClientSecret: "secret",
+		RedirectURL:  ts.URL + CallbackPath,
+		Key:          []byte("session key"),
+	}
+	s.Allow = map[string][]string{"eng": {subPageID}}
+	jar, _ := cookiejar.New(nil)
+	c := &http.Client{Jar: jar}
+	sub := ts.URL + "/" + s.exporter.Page(subPageID).Path
+	if code, body := get(t, c, sub); code != http.StatusOK {
+		t.Fatalf("signing in: got %v\n%s", code, body)
+	}
+	if user := s.Auth.User(&http.Request{Header: http.Header{"Cookie": {jarCookies(jar, ts.URL)}}}); user == nil || user.Name != "ann@example.com" {
+		t.Errorf("got user %+v", user)
+	}
+	if code, _ := get(t, c, ts.URL+"/"+s.exporter.Page(pageID).Path); code != http.StatusForbidden {
+		t.Errorf("got %v for a page the group isn't allowed, want 403", code)
+	}
+
+	// a session signed with another key
+	jar2, _ := cookiejar.New(nil)
+	u, _ := url.Parse(ts.URL)
+	for _, cookie := range jar.Cookies(u) {
+		cookie.Value = "x" + cookie.Value
+		jar2.SetCookies(u, []*http.Cookie{cookie})
+	}
+	noRedirect := &http.Client{Jar: jar2, CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	if code, _ := get(t, noRedirect, sub); code != http.StatusFound {
+		t.Errorf("got %v with a forged session, want a redirect to sign in", code)
+	}
+}
+
+func TestOIDCTokenTimes(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		// offset of the clock of the server to that of the provider
+		offset time.Duration
+		want   int
+	}{
+		{"in sync", 0, http.StatusOK},
+		{"behind within the skew", -30 * time.Second, http.StatusOK},
+		{"behind", -10 * time.Minute, http.StatusForbidden},
+		{"ahead of the expiry", 2 * time.Hour, http.StatusForbidden},
+	} {
+		s, ts := newServer(t)
+		idp := provider(t, "eng")
+		s.Auth = &OIDC{
+			Issuer:       idp.URL,
+			ClientID:     "client",
+			ClientSecret: "secret",
+			RedirectURL:  ts.URL + CallbackPath,
+			Key:          []byte("session key"),
+			now:          func() time.Time { return time.Now().Add(tt.offset) },
+		}
+		jar, _ := cookiejar.New(nil)
+		c := &http.Client{Jar: jar}
+		if code, body := get(t, c, ts.URL+"/"+s.exporter.Page(subPageID).Path); code != tt.want {
+			t.Errorf("%v: got %v, want %v\n%s", tt.name, code, tt.want, body)
+		}
+	}
+}
+
+func jarCookies(jar http.CookieJar, rawurl string) string {
+	u, _ := url.Parse(rawurl)
+	var s string
+	for _, c := range jar.Cookies(u) {
+		s += c.Name + "=" + c.Value + "; "
+	}
+	return s
+}
+
+func TestAllowOtherFiles(t *testing.T) {
+	s, ts := newServer(t)
+	s.Auth = &BasicAuth{Users: map[string]string{"bob": "pw"}, Groups: map[string][]string{"bob": {"eng"}}}
+	s.Allow = map[string][]string{"eng": {subPageID}}
+	// e.g. the root page exported under its previous title
+	if err := ioutil.WriteFile(filepath.Join(s.exportDir, "Old-Handbook.html"), []byte("Handbook"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(s.exportDir, "drafts"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"Old-Handbook.html", "drafts", "drafts/", "missing.html"} {
+		req, _ := http.NewRequest("GET", ts.URL+"/"+name, nil)
+		req.SetBasicAuth("bob", "pw")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		// answered as pages bob may not get, rather than as missing
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("%v: got %v, want 403", name, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,413 @@
+package serve
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// OIDC authenticates users with the authorization code flow of an OpenID
+// Connect provider, e.g. Okta, Keycloak or Google Workspace. Their groups
+// come from a claim of their ID token. The provider must sign ID tokens
+// with RS256.
+//
+// Authenticated users get a session cookie signed with Key, which must be
+// set. OIDC serves the redirect URL of the flow, whose path must be
+// CallbackPath.
+type OIDC struct {
+	// Issuer is the URL of the provider, whose configuration is discovered
+	// at Issuer/.well-known/openid-configuration.
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is the URL of CallbackPath on the server.
+	RedirectURL string
+	// Scopes are requested in addition to "openid", by default "profile",
+	// "email" and "groups".
+	Scopes []string
+	// GroupsClaim is the claim listing the groups of users, "groups" by
+	// default.
+	GroupsClaim string
+	// Key signs session cookies.
+	Key []byte
+	// Session is how long users stay signed in, 12 hours by default.
+	Session time.Duration
+	// Client defaults to http.DefaultClient.
+	Client *http.Client
+
+	now func() time.Time
+
+	mu       sync.Mutex
+	provider *oidcProvider
+	keys     map[string]*rsa.PublicKey
+}
+
+type oidcProvider struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+const (
+	sessionCookie = "notion_session"
+	// stateCookie holds the state, nonce and original URL of logins
+	stateCookie = "notion_oidc_state"
+)
+
+// User implements Authenticator, returning the user of a valid session.
+func (o *OIDC) User(r *http.Request) *User {
+	c, err := r.Cookie(sessionCookie)
+	if err != nil {
+		return nil
+	}
+	var session struct {
+		User    *User `json:"user"`
+		Expires int64 `json:"expires"`
+	}
+	if !o.open(c.Value, &session) || session.User == nil || o.clock().Unix() >= session.Expires {
+		return nil
+	}
+	return session.User
+}
+
+// Challenge implements Authenticator, redirecting browsers to the provider
+// to sign in, and back to the URL of r once they have.
+func (o *OIDC) Challenge(w http.ResponseWriter, r *http.Request) {
+	p, err := o.discover()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	state, nonce := randomString(), randomString()
+	http.SetCookie(w, &http.Cookie{
+		Name:     stateCookie,
+		Value:    o.seal([]string{state, nonce, r.URL.RequestURI()}),
+		Path:     CallbackPath,
+		MaxAge:   600,
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(o.RedirectURL, "https:"),
+		SameSite: http.SameSiteLaxMode,
+	})
+	scopes := o.Scopes
+	if scopes == nil {
+		scopes = []string{"profile", "email", "groups"}
+	}
+	q := url.Values{
+		"response_type": {"code"},
+		"client_id":     {o.ClientID},
+		"redirect_uri":  {o.RedirectURL},
+		"scope":         {strings.Join(append([]string{"openid"}, scopes...), " ")},
+		"state":         {state},
+		"nonce":         {nonce},
+	}
+	sep := "?"
+	if strings.Contains(p.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	http.Redirect(w, r, p.AuthorizationEndpoint+sep+q.Encode(), http.StatusFound)
+}
+
+// ServeHTTP serves the redirect URL, exchanging the code of the provider
+// for an ID token and starting a session.
+func (o *OIDC) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c, err := r.Cookie(stateCookie)
+	var state []string
+	if err != nil || !o.open(c.Value, &state) || len(state) != 3 || r.URL.Query().Get("state") != state[0] {
+		http.Error(w, "invalid login state, please try again", http.StatusBadRequest)
+		return
+	}
+	if msg := r.URL.Query().Get("error"); msg != "" {
+		http.Error(w, "signing in: "+msg, http.StatusForbidden)
+		return
+	}
+	user, err := o.exchange(r.URL.Query().Get("code"), state[1])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	session := o.Session
+	if session == 0 {
+		session = 12 * time.Hour
+	}
+	expires := o.clock().Add(session)
+	http.SetCookie(w, &http.Cookie{
+		Name: sessionCookie,
+		Value: o.seal(map[string]interface{}{
+			"user":    user,
+			"expires": expires.Unix(),
+		}),
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(o.RedirectURL, "https:"),
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.SetCookie(w, &http.Cookie{Name: stateCookie, Path: CallbackPath, MaxAge: -1})
+	next := state[2]
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") {
+		next = "/"
+	}
+	http.Redirect(w, r, next, http.StatusFound)
+}
+
+// exchange exchanges code for an ID token and returns its user.
+func (o *OIDC) exchange(code, nonce string) (*User, error) {
+	p, err := o.discover()
+	if err != nil {
+		return nil, err
+	}
+	form := url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {code},
+		"redirect_uri": {o.RedirectURL},
+	}
+	req, err := http.NewRequest("POST", p.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(o.ClientID), url.QueryEscape(o.ClientSecret))
+	var token struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := o.getJSON(req, &token); err != nil {
+		return nil, errors.Wrap(err, "oidc: exchanging code")
+	}
+	claims, err := o.verify(token.IDToken)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := claims["nonce"].(string); n != nonce {
+		return nil, errors.New("oidc: ID token nonce mismatch")
+	}
+	user := &User{}
+	for _, claim := range []string{"email", "preferred_username", "sub"} {
+		if v, _ := claims[claim].(string); v != "" {
+			user.Name = v
+			break
+		}
+	}
+	groupsClaim := o.GroupsClaim
+	if groupsClaim == "" {
+		groupsClaim = "groups"
+	}
+	switch groups := claims[groupsClaim].(type) {
+	case string:
+		user.Groups = []string{groups}
+	case []interface{}:
+		for _, g := range groups {
+			if s, ok := g.(string); ok {
+				user.Groups = append(user.Groups, s)
+			}
+		}
+	}
+	return user, nil
+}
+
+// clockSkew is the difference allowed between the clocks of the provider
+// and the server when checking the times of ID tokens.
+const clockSkew = time.Minute
+
+// verify checks the signature, issuer, audience and times of the ID token
+// and returns its claims.
+func (o *OIDC) verify(token string) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("oidc: malformed ID token")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, errors.Wrap(err, "oidc: decoding ID token")
+	}
+	if header.Alg != "RS256" {
+		return nil, errors.Errorf("oidc: unsupported ID token algorithm %q", header.Alg)
+	}
+	key, err := o.key(header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.Wrap(err, "oidc: decoding ID token signature")
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig); err != nil {
+		return nil, errors.New("oidc: invalid ID token signature")
+	}
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, errors.Wrap(err, "oidc: decoding ID token")
+	}
+	p, _ := o.discover()
+	if iss, _ := claims["iss"].(string); iss != p.Issuer {
+		return nil, errors.Errorf("oidc: ID token issued by %q", iss)
+	}
+	var audience bool
+	switch aud := claims["aud"].(type) {
+	case string:
+		audience = aud == o.ClientID
+	case []interface{}:
+		for _, a := range aud {
+			audience = audience || a == o.ClientID
+		}
+	}
+	if !audience {
+		return nil, errors.New("oidc: ID token not issued for this client")
+	}
+	now, skew := o.clock().Unix(), int64(clockSkew/time.Second)
+	if exp, _ := claims["exp"].(float64); int64(exp) <= now-skew {
+		return nil, errors.New("oidc: ID token expired")
+	}
+	if iat, ok := claims["iat"].(float64); !ok || int64(iat) > now+skew {
+		return nil, errors.New("oidc: ID token not issued yet")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && int64(nbf) > now+skew {
+		return nil, errors.New("oidc: ID token not valid yet")
+	}
+	return claims, nil
+}
+
+// discover fetches the configuration of the provider, once it succeeds.
+func (o *OIDC) discover() (*oidcProvider, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.provider != nil {
+		return o.provider, nil
+	}
+	req, err := http.NewRequest("GET", strings.TrimSuffix(o.Issuer, "/")+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+	p := &oidcProvider{}
+	if err := o.getJSON(req, p); err != nil {
+		return nil, errors.Wrap(err, "oidc: discovering provider")
+	}
+	o.provider = p
+	return p, nil
+}
+
+// key returns the public key kid of the provider, fetching its keys again
+// if it's unknown, as providers rotate them.
+func (o *OIDC) key(kid string) (*rsa.PublicKey, error) {
+	p, err := o.discover()
+	if err != nil {
+		return nil, err
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if k, ok := o.keys[kid]; ok {
+		return k, nil
+	}
+	req, err := http.NewRequest("GET", p.JWKSURI, nil)
+	if err != nil {
+		return nil, err
+	}
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := o.getJSON(req, &set); err != nil {
+		return nil, errors.Wrap(err, "oidc: fetching keys")
+	}
+	o.keys = make(map[string]*rsa.PublicKey)
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		n, err1 := base64.RawURLEncoding.DecodeString(k.N)
+		e, err2 := base64.RawURLEncoding.DecodeString(k.E)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		o.keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+	if k, ok := o.keys[kid]; ok {
+		return k, nil
+	}
+	return nil, errors.Errorf("oidc: unknown ID token key %q", kid)
+}
+
+func (o *OIDC) getJSON(req *http.Request, v interface{}) error {
+	client := o.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("%v: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return json.Unmarshal(body, v)
+}
+
+// seal encodes v as JSON signed with Key.
+func (o *OIDC) seal(v interface{}) string {
+	b, _ := json.Marshal(v)
+	payload := base64.RawURLEncoding.EncodeToString(b)
+	return payload + "." + o.sign(payload)
+}
+
+// open decodes a value sealed with Key into v, reporting whether it's
+// valid.
+func (o *OIDC) open(sealed string, v interface{}) bool {
+	i := strings.LastIndex(sealed, ".")
+	if len(o.Key) == 0 || i < 0 || !hmac.Equal([]byte(o.sign(sealed[:i])), []byte(sealed[i+1:])) {
+		return false
+	}
+	return decodeSegment(sealed[:i], v) == nil
+}
+
+func (o *OIDC) sign(payload string) string {
+	mac := hmac.New(sha256.New, o.Key)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (o *OIDC) clock() time.Time {
+	if o.now != nil {
+		return o.now()
+	}
+	return time.Now()
+}
+
+func decodeSegment(s string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func randomString() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
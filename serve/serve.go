@@ -0,0 +1,209 @@
+// Package serve serves the HTML export of a page and its sub-pages over
+// HTTP, exported again as they're edited in notion, optionally only to
+// authenticated users (see BasicAuth and OIDC), with allowlists of pages by
+// group, and through signed share links that expire (see Shares), so that
+// internal pages can be shared outside the workspace without making them
+// public.
+package serve
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/export"
+	"github.com/tmc/notion/watch"
+)
+
+// Server is an http.Handler serving the export of a page. It's a
+// watch.Sink, which exports the pages again on the events of a
+// watch.Watcher of the page.
+//
+// Without Auth or Shares, pages are served to everyone. Otherwise they're
+// served to the users Auth authenticates, as allowed by Allow, and
+// through the share links of Shares; other clients are challenged to
+// authenticate if Auth is set, and forbidden otherwise. Pages that don't
+// exist are answered the same way, so that clients can't tell them from
+// those they may not get.
+type Server struct {
+	// Auth, if set, restricts the server to authenticated users.
+	Auth Authenticator
+	// Allow, if set, restricts users to the pages, with their sub-pages,
+	// whose ids are listed for any of their groups.
+	Allow map[string][]string
+	// Shares, if set, serves the pages of valid share links.
+	Shares *Shares
+
+	client *notion.Client
+	rootID string
+	dir    string
+	html   *export.HTML
+
+	// serializes exports
+	exportMu sync.Mutex
+
+	mu       sync.RWMutex
+	exporter *export.Exporter
+	// directory of the current export, within dir
+	exportDir string
+	// pages by path
+	pages map[string]*export.Page
+}
+
+// NewServer returns a Server for the page rootID and its sub-pages, which
+// are exported into new directories within dir with h.
+func NewServer(c *notion.Client, rootID, dir string, h *export.HTML) *Server {
+	return &Server{client: c, rootID: rootID, dir: dir, html: h}
+}
+
+// Export exports the pages again, into a new directory that replaces the
+// current export once it's complete, so that the pages are still served
+// meanwhile.
+func (s *Server) Export() error {
+	s.exportMu.Lock()
+	defer s.exportMu.Unlock()
+	dir, err := ioutil.TempDir(s.dir, "export")
+	if err != nil {
+		return err
+	}
+	e := export.NewExporter(s.client, dir, export.WithRenderer(s.html))
+	if err := e.Export(s.rootID); err != nil {
+		os.RemoveAll(dir)
+		return err
+	}
+	pages := make(map[string]*export.Page)
+	for _, p := range e.Manifest().Pages {
+		pages[p.Path] = e.Page(p.ID)
+	}
+
+	s.mu.Lock()
+	old := s.exportDir
+	s.exporter, s.exportDir, s.pages = e, dir, pages
+	s.mu.Unlock()
+	// requests serving files of the old export hold s.mu
+	if old != "" {
+		os.RemoveAll(old)
+	}
+	return nil
+}
+
+// Send implements watch.Sink, exporting the pages again.
+func (s *Server) Send(e *watch.Event) error {
+	return s.Export()
+}
+
+// ServeHTTP serves the exported pages, and share links at SharePath. The
+// root path redirects to the page the export started from.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == SharePath && s.Shares != nil {
+		s.serveShare(w, r)
+		return
+	}
+	if h, ok := s.Auth.(http.Handler); ok && r.URL.Path == CallbackPath {
+		h.ServeHTTP(w, r)
+		return
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.exporter == nil {
+		http.Error(w, "the pages haven't been exported yet", http.StatusServiceUnavailable)
+		return
+	}
+	if r.URL.Path == "/" {
+		if root := s.exporter.Page(s.rootID); root != nil {
+			// the path of the root page tells its title
+			if s.authorize(w, r, root) {
+				http.Redirect(w, r, "/"+root.Path, http.StatusFound)
+			}
+			return
+		}
+	}
+	name := strings.TrimPrefix(path.Clean(r.URL.Path), "/")
+	// only the pages of the export are served, not directories or files
+	// left over by previous exports
+	page := s.pages[name]
+	if page == nil {
+		s.notFound(w, r)
+		return
+	}
+	if !s.authorize(w, r, page) {
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	http.ServeFile(w, r, filepath.Join(s.exportDir, filepath.FromSlash(name)))
+}
+
+// authorize reports whether r may get page, and responds to r if it may
+// not.
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request, page *export.Page) bool {
+	if s.Auth == nil && s.Shares == nil {
+		return true
+	}
+	var user *User
+	if s.Auth != nil {
+		if user = s.Auth.User(r); user != nil && s.userAllowed(user, page) {
+			return true
+		}
+	}
+	if s.Shares != nil {
+		shared := s.Shares.shared(r)
+		// share links only give access to their page
+		if page != nil && shared[page.ID] {
+			return true
+		}
+	}
+	s.deny(w, r, user)
+	return false
+}
+
+// notFound responds to r for a page that isn't exported. Unless r may get
+// any page, it's answered as if the page existed and r may not get it, so
+// that the paths of pages, which tell their titles, can't be guessed.
+func (s *Server) notFound(w http.ResponseWriter, r *http.Request) {
+	if s.Auth == nil && s.Shares == nil {
+		http.NotFound(w, r)
+		return
+	}
+	var user *User
+	if s.Auth != nil {
+		if user = s.Auth.User(r); user != nil && s.Allow == nil {
+			http.NotFound(w, r)
+			return
+		}
+	}
+	s.deny(w, r, user)
+}
+
+// deny responds to r, of the authenticated user if not nil, that it may not
+// get the page it requested.
+func (s *Server) deny(w http.ResponseWriter, r *http.Request, user *User) {
+	if s.Auth != nil && user == nil {
+		s.Auth.Challenge(w, r)
+		return
+	}
+	http.Error(w, "forbidden", http.StatusForbidden)
+}
+
+func (s *Server) serveShare(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	cookie, err := s.Shares.open(q.Get("page"), q.Get("expires"), q.Get("token"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	http.SetCookie(w, cookie)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.exporter != nil {
+		if page := s.exporter.Page(q.Get("page")); page != nil {
+			http.Redirect(w, r, "/"+page.Path, http.StatusFound)
+			return
+		}
+	}
+	http.NotFound(w, r)
+}
@@ -0,0 +1,133 @@
+package serve
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tmc/notion/export"
+	"github.com/tmc/notion/notiontest"
+	"github.com/tmc/notion/notiontypes"
+)
+
+const (
+	pageID    = "4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+	subPageID = "5b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+)
+
+func newServer(t *testing.T) (*Server, *httptest.Server) {
+	t.Helper()
+	srv := notiontest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.AddBlock(&notiontypes.Block{
+		ID: pageID, Type: notiontypes.BlockPage,
+		Properties: map[string]interface{}{"title": [][]string{{"Handbook"}}},
+		Content: []*notiontypes.Block{{
+			ID: subPageID, Type: notiontypes.BlockPage,
+			Properties: map[string]interface{}{"title": [][]string{{"Salaries"}}},
+		}},
+	})
+	s := NewServer(srv.Client(), pageID, t.TempDir(), &export.HTML{Highlighter: export.PlainHighlighter{}})
+	if err := s.Export(); err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+	return s, ts
+}
+
+func get(t *testing.T, c *http.Client, url string) (int, string) {
+	t.Helper()
+	resp, err := c.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
+
+func TestShares(t *testing.T) {
+	s, ts := newServer(t)
+	// the cookie jar drops expired cookies
+	now := time.Now().Truncate(time.Second)
+	s.Shares = &Shares{Key: []byte("secret"), now: func() time.Time { return now }}
+	jar, _ := cookiejar.New(nil)
+	c := &http.Client{Jar: jar}
+	root := s.exporter.Page(pageID).Path
+	sub := s.exporter.Page(subPageID).Path
+
+	if code, _ := get(t, c, ts.URL+"/"+root); code != http.StatusForbidden {
+		t.Errorf("got %v without a share link, want 403", code)
+	}
+	link, err := s.Shares.URL(ts.URL, strings.Replace(subPageID, "-", "", -1), now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code, _ := get(t, c, strings.Replace(link, "token=", "token=x", 1)); code != http.StatusForbidden {
+		t.Errorf("got %v for a tampered link, want 403", code)
+	}
+	if code, body := get(t, c, link); code != http.StatusOK || !strings.Contains(body, "Salaries") {
+		t.Fatalf("got %v for the share link:\n%s", code, body)
+	}
+	if code, _ := get(t, c, ts.URL+"/"+sub); code != http.StatusOK {
+		t.Errorf("got %v for the shared page with its cookie, want 200", code)
+	}
+	if code, _ := get(t, c, ts.URL+"/"+root); code != http.StatusForbidden {
+		t.Errorf("got %v for a page that isn't shared, want 403", code)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if code, _ := get(t, c, ts.URL+"/"+sub); code != http.StatusForbidden {
+		t.Errorf("got %v after the link expired, want 403", code)
+	}
+	if code, body := get(t, c, link); code != http.StatusForbidden || !strings.Contains(body, ErrShareExpired.Error()) {
+		t.Errorf("got %v %q for an expired link", code, body)
+	}
+}
+
+func TestSharesOtherFiles(t *testing.T) {
+	s, ts := newServer(t)
+	s.Shares = &Shares{Key: []byte("secret")}
+	jar, _ := cookiejar.New(nil)
+	c := &http.Client{Jar: jar}
+	link, err := s.Shares.URL(ts.URL, subPageID, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code, _ := get(t, c, link); code != http.StatusOK {
+		t.Fatalf("got %v for the share link", code)
+	}
+	// e.g. a page exported under its previous title
+	if err := ioutil.WriteFile(filepath.Join(s.exportDir, "stale.html"), []byte("Salaries"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{export.ManifestFile, "stale.html"} {
+		if code, _ := get(t, c, ts.URL+"/"+name); code == http.StatusOK {
+			t.Errorf("got %v for %v with a share cookie", code, name)
+		}
+	}
+}
+
+func TestExportReplacesDir(t *testing.T) {
+	s, ts := newServer(t)
+	old := s.exportDir
+	if err := s.Export(); err != nil {
+		t.Fatal(err)
+	}
+	if s.exportDir == old {
+		t.Fatal("exported into the same directory again")
+	}
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Errorf("the previous export is still there: %v", err)
+	}
+	if code, body := get(t, http.DefaultClient, ts.URL+"/"+s.exporter.Page(pageID).Path); code != http.StatusOK || !strings.Contains(body, "Handbook") {
+		t.Errorf("got %v after exporting again:\n%s", code, body)
+	}
+}
@@ -0,0 +1,118 @@
+package serve
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion"
+)
+
+// SharePath is the path of share links.
+const SharePath = "/_share"
+
+// shareCookie prefixes the names of the cookies of opened share links,
+// followed by the compact id of their page.
+const shareCookie = "notion_share_"
+
+// Errors of invalid share links.
+var (
+	ErrShareExpired = errors.New("share link expired")
+	ErrShareInvalid = errors.New("invalid share link")
+)
+
+// Shares signs and checks share links: URLs giving access to a page, not
+// its sub-pages, until they expire. Links carry the page id, the expiry
+// time and an HMAC-SHA256 of both in their query string. Opening a link
+// sets a cookie holding the same, so that the page and the files of the
+// export it uses can then be loaded without the query string.
+//
+// Links can't be revoked before they expire, other than by changing Key,
+// which revokes all of them.
+type Shares struct {
+	Key []byte
+
+	now func() time.Time
+}
+
+// URL returns the share link of the page pageID, on the server at base,
+// expiring at expires.
+func (s *Shares) URL(base, pageID string, expires time.Time) (string, error) {
+	id, err := notion.FormatID(pageID)
+	if err != nil {
+		return "", err
+	}
+	exp := strconv.FormatInt(expires.Unix(), 10)
+	q := url.Values{"page": {id}, "expires": {exp}, "token": {s.sign(id, exp)}}
+	return strings.TrimSuffix(base, "/") + SharePath + "?" + q.Encode(), nil
+}
+
+func (s *Shares) sign(pageID, expires string) string {
+	mac := hmac.New(sha256.New, s.Key)
+	mac.Write([]byte(pageID + "\n" + expires))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// verify returns the expiry time of the share of pageID signed with token.
+func (s *Shares) verify(pageID, expires, token string) (time.Time, error) {
+	if len(s.Key) == 0 || !hmac.Equal([]byte(s.sign(pageID, expires)), []byte(token)) {
+		return time.Time{}, ErrShareInvalid
+	}
+	sec, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return time.Time{}, ErrShareInvalid
+	}
+	t := time.Unix(sec, 0)
+	if !s.clock().Before(t) {
+		return time.Time{}, ErrShareExpired
+	}
+	return t, nil
+}
+
+// open checks a share link and returns the cookie giving access to its
+// page.
+func (s *Shares) open(pageID, expires, token string) (*http.Cookie, error) {
+	t, err := s.verify(pageID, expires, token)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Cookie{
+		Name:     shareCookie + strings.Replace(pageID, "-", "", -1),
+		Value:    pageID + "." + expires + "." + token,
+		Path:     "/",
+		Expires:  t,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}, nil
+}
+
+// shared returns the ids of the pages of the valid share cookies of r.
+func (s *Shares) shared(r *http.Request) map[string]bool {
+	ids := make(map[string]bool)
+	for _, c := range r.Cookies() {
+		if !strings.HasPrefix(c.Name, shareCookie) {
+			continue
+		}
+		parts := strings.Split(c.Value, ".")
+		if len(parts) != 3 {
+			continue
+		}
+		if _, err := s.verify(parts[0], parts[1], parts[2]); err == nil {
+			ids[parts[0]] = true
+		}
+	}
+	return ids
+}
+
+func (s *Shares) clock() time.Time {
+	if s.now != nil {
+		return s.now()
+	}
+	return time.Now()
+}
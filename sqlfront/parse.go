@@ -0,0 +1,481 @@
+package sqlfront
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// Select is a parsed SELECT statement:
+//
+//	SELECT * | column, ... FROM collection
+//	[WHERE condition]
+//	[ORDER BY column [ASC | DESC], ...]
+//	[LIMIT n [OFFSET m]]
+type Select struct {
+	// Columns are the selected columns, all of them if empty.
+	Columns []string
+	From    string
+	Where   Expr
+	OrderBy []*Order
+	// Limit is -1 without a LIMIT clause.
+	Limit  int
+	Offset int
+}
+
+// Order is a column of an ORDER BY clause.
+type Order struct {
+	Column string
+	Desc   bool
+}
+
+// Expr is an expression of a WHERE clause: a *Column, *Literal, *Binary,
+// *Not, *Like, *IsNull or *In.
+type Expr interface {
+	expr()
+}
+
+// Column is a reference to a column, that is a property.
+type Column struct {
+	Name string
+}
+
+// Literal is a string, number (float64), boolean or NULL (nil) value.
+type Literal struct {
+	Value interface{}
+}
+
+// Binary is a comparison (=, !=, <, <=, > or >=) or a logical AND or OR.
+type Binary struct {
+	Op          string
+	Left, Right Expr
+}
+
+// Not negates X.
+type Not struct {
+	X Expr
+}
+
+// Like matches X against a pattern where % matches any text and _ any
+// character, ignoring case.
+type Like struct {
+	X       Expr
+	Pattern string
+	Not     bool
+}
+
+// IsNull tests if X is NULL, that is an empty property.
+type IsNull struct {
+	X   Expr
+	Not bool
+}
+
+// In tests if X is one of Values.
+type In struct {
+	X      Expr
+	Values []*Literal
+	Not    bool
+}
+
+func (*Column) expr()  {}
+func (*Literal) expr() {}
+func (*Binary) expr()  {}
+func (*Not) expr()     {}
+func (*Like) expr()    {}
+func (*IsNull) expr()  {}
+func (*In) expr()      {}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokQuotedIdent
+	tokString
+	tokNumber
+	tokSymbol
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// lex splits sql into tokens.
+func lex(sql string) ([]token, error) {
+	var toks []token
+	for i := 0; i < len(sql); {
+		c := rune(sql[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '\'' || c == '"' || c == '`':
+			var b strings.Builder
+			j := i + 1
+			for ; ; j++ {
+				if j >= len(sql) {
+					return nil, errors.Errorf("sqlfront: unterminated %c at %d", c, i)
+				}
+				if rune(sql[j]) == c {
+					if j+1 < len(sql) && rune(sql[j+1]) == c {
+						b.WriteByte(sql[j])
+						j++
+						continue
+					}
+					break
+				}
+				b.WriteByte(sql[j])
+			}
+			kind := tokQuotedIdent
+			if c == '\'' {
+				kind = tokString
+			}
+			toks = append(toks, token{kind, b.String(), i})
+			i = j + 1
+		case c >= '0' && c <= '9' || c == '.' && i+1 < len(sql) && sql[i+1] >= '0' && sql[i+1] <= '9':
+			j := i
+			for j < len(sql) && (sql[j] >= '0' && sql[j] <= '9' || sql[j] == '.' || sql[j] == 'e' || sql[j] == 'E') {
+				j++
+			}
+			toks = append(toks, token{tokNumber, sql[i:j], i})
+			i = j
+		case c == '_' || unicode.IsLetter(c) || c >= 0x80:
+			j := i
+			for j < len(sql) && (sql[j] == '_' || sql[j] >= 0x80 || unicode.IsLetter(rune(sql[j])) || unicode.IsDigit(rune(sql[j]))) {
+				j++
+			}
+			toks = append(toks, token{tokIdent, sql[i:j], i})
+			i = j
+		default:
+			sym := string(c)
+			if i+1 < len(sql) {
+				switch two := sql[i : i+2]; two {
+				case "<=", ">=", "<>", "!=":
+					sym = two
+				}
+			}
+			if !strings.Contains("=<>!(),*;-", sym[:1]) || sym == "!" {
+				return nil, errors.Errorf("sqlfront: unexpected %q at %d", sym, i)
+			}
+			toks = append(toks, token{tokSymbol, sym, i})
+			i += len(sym)
+		}
+	}
+	return append(toks, token{tokEOF, "", len(sql)}), nil
+}
+
+type parser struct {
+	toks []token
+	pos  int
+}
+
+// Parse parses a SELECT statement. Keywords are case-insensitive; column
+// and collection names that aren't plain words are quoted with double
+// quotes or backticks, and strings with single quotes.
+func Parse(sql string) (*Select, error) {
+	toks, err := lex(sql)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks}
+	s, err := p.parseSelect()
+	if err != nil {
+		return nil, errors.Wrap(err, "sqlfront")
+	}
+	return s, nil
+}
+
+func (p *parser) peek() token { return p.toks[p.pos] }
+
+func (p *parser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// keyword reports whether the next token is one of the keywords, and
+// consumes it if so.
+func (p *parser) keyword(words ...string) bool {
+	t := p.peek()
+	if t.kind != tokIdent {
+		return false
+	}
+	for _, w := range words {
+		if strings.EqualFold(t.text, w) {
+			p.pos++
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) symbol(s string) bool {
+	if t := p.peek(); t.kind == tokSymbol && t.text == s {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	t := p.peek()
+	near := "end of statement"
+	if t.kind != tokEOF {
+		near = strconv.Quote(t.text)
+	}
+	return errors.Errorf(format+" at %d, near %s", append(args, t.pos, near)...)
+}
+
+func (p *parser) expect(word string) error {
+	if !p.keyword(word) {
+		return p.errorf("expected %s", word)
+	}
+	return nil
+}
+
+func (p *parser) name() (string, error) {
+	t := p.peek()
+	if t.kind != tokIdent && t.kind != tokQuotedIdent || t.kind == tokIdent && reserved[strings.ToUpper(t.text)] {
+		return "", p.errorf("expected a name")
+	}
+	p.pos++
+	return t.text, nil
+}
+
+var reserved = map[string]bool{
+	"SELECT": true, "FROM": true, "WHERE": true, "ORDER": true, "BY": true, "LIMIT": true, "OFFSET": true,
+	"AND": true, "OR": true, "NOT": true, "LIKE": true, "IS": true, "IN": true, "NULL": true,
+	"TRUE": true, "FALSE": true, "ASC": true, "DESC": true,
+}
+
+func (p *parser) parseSelect() (*Select, error) {
+	if err := p.expect("SELECT"); err != nil {
+		return nil, err
+	}
+	s := &Select{Limit: -1}
+	if !p.symbol("*") {
+		for {
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			s.Columns = append(s.Columns, name)
+			if !p.symbol(",") {
+				break
+			}
+		}
+	}
+	if err := p.expect("FROM"); err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind == tokString {
+		p.pos++
+		s.From = t.text
+	} else {
+		from, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		s.From = from
+	}
+	if p.keyword("WHERE") {
+		where, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		s.Where = where
+	}
+	if p.keyword("ORDER") {
+		if err := p.expect("BY"); err != nil {
+			return nil, err
+		}
+		for {
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			o := &Order{Column: name}
+			if p.keyword("DESC") {
+				o.Desc = true
+			} else {
+				p.keyword("ASC")
+			}
+			s.OrderBy = append(s.OrderBy, o)
+			if !p.symbol(",") {
+				break
+			}
+		}
+	}
+	if p.keyword("LIMIT") {
+		n, err := p.count()
+		if err != nil {
+			return nil, err
+		}
+		s.Limit = n
+		if p.keyword("OFFSET") {
+			if s.Offset, err = p.count(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	p.symbol(";")
+	if p.peek().kind != tokEOF {
+		return nil, p.errorf("unexpected input")
+	}
+	return s, nil
+}
+
+func (p *parser) count() (int, error) {
+	t := p.peek()
+	n, err := strconv.Atoi(t.text)
+	if t.kind != tokNumber || err != nil || n < 0 {
+		return 0, p.errorf("expected a count")
+	}
+	p.pos++
+	return n, nil
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	x, err := p.parseAnd()
+	for err == nil && p.keyword("OR") {
+		var y Expr
+		if y, err = p.parseAnd(); err == nil {
+			x = &Binary{Op: "OR", Left: x, Right: y}
+		}
+	}
+	return x, err
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	x, err := p.parseNot()
+	for err == nil && p.keyword("AND") {
+		var y Expr
+		if y, err = p.parseNot(); err == nil {
+			x = &Binary{Op: "AND", Left: x, Right: y}
+		}
+	}
+	return x, err
+}
+
+func (p *parser) parseNot() (Expr, error) {
+	if p.keyword("NOT") {
+		x, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{X: x}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (Expr, error) {
+	x, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind == tokSymbol {
+		switch op := t.text; op {
+		case "=", "!=", "<>", "<", "<=", ">", ">=":
+			p.pos++
+			if op == "<>" {
+				op = "!="
+			}
+			y, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			return &Binary{Op: op, Left: x, Right: y}, nil
+		}
+	}
+	if p.keyword("IS") {
+		not := p.keyword("NOT")
+		if err := p.expect("NULL"); err != nil {
+			return nil, err
+		}
+		return &IsNull{X: x, Not: not}, nil
+	}
+	not := p.keyword("NOT")
+	switch {
+	case p.keyword("LIKE"):
+		t := p.next()
+		if t.kind != tokString {
+			return nil, p.errorf("expected a pattern")
+		}
+		return &Like{X: x, Pattern: t.text, Not: not}, nil
+	case p.keyword("IN"):
+		if !p.symbol("(") {
+			return nil, p.errorf("expected (")
+		}
+		in := &In{X: x, Not: not}
+		for {
+			v, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			lit, ok := v.(*Literal)
+			if !ok {
+				return nil, p.errorf("expected a value")
+			}
+			in.Values = append(in.Values, lit)
+			if !p.symbol(",") {
+				break
+			}
+		}
+		if !p.symbol(")") {
+			return nil, p.errorf("expected )")
+		}
+		return in, nil
+	case not:
+		return nil, p.errorf("expected LIKE or IN")
+	}
+	return x, nil
+}
+
+func (p *parser) parseOperand() (Expr, error) {
+	t := p.peek()
+	switch {
+	case t.kind == tokString:
+		p.pos++
+		return &Literal{Value: t.text}, nil
+	case t.kind == tokNumber, t.kind == tokSymbol && t.text == "-":
+		p.pos++
+		text := t.text
+		if t.kind == tokSymbol {
+			if n := p.next(); n.kind == tokNumber {
+				text = "-" + n.text
+			} else {
+				return nil, p.errorf("expected a number")
+			}
+		}
+		n, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, errors.Errorf("invalid number %q at %d", text, t.pos)
+		}
+		return &Literal{Value: n}, nil
+	case p.keyword("TRUE"):
+		return &Literal{Value: true}, nil
+	case p.keyword("FALSE"):
+		return &Literal{Value: false}, nil
+	case p.keyword("NULL"):
+		return &Literal{}, nil
+	case p.symbol("("):
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.symbol(")") {
+			return nil, p.errorf("expected )")
+		}
+		return x, nil
+	}
+	name, err := p.name()
+	if err != nil {
+		return nil, p.errorf("expected a column or value")
+	}
+	return &Column{Name: name}, nil
+}
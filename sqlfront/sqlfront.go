@@ -0,0 +1,611 @@
+// Package sqlfront runs read-only SQL SELECT statements over notion
+// databases, for ad hoc analysis:
+//
+//	db := &sqlfront.DB{Client: c, Collections: map[string]string{"tasks": id}}
+//	res, err := db.Query(`SELECT Name, Points FROM tasks WHERE Status = 'Done' ORDER BY Points DESC LIMIT 10`)
+//
+// Columns are the properties of the collection, named case-insensitively,
+// and id, the id of rows, unless a property has that name. Values are
+// float64 for numbers, bool for checkboxes and strings for other
+// properties, with dates as 2006-01-02 or 2006-01-02 15:04 so that they
+// compare as dates. Empty properties are NULL.
+//
+// The conditions of the WHERE clause that notion's filters can express
+// are sent with the query, so that fewer rows are loaded; all conditions
+// are then evaluated on the loaded rows, which are sorted and limited.
+package sqlfront
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/notiontypes"
+	"github.com/tmc/notion/query"
+)
+
+// DB runs queries over the collections of a client.
+type DB struct {
+	Client *notion.Client
+	// Collections maps the names used in FROM clauses to the ids of
+	// collections, case-insensitively. Other names are taken as ids.
+	Collections map[string]string
+}
+
+// Result is the result of a query.
+type Result struct {
+	Columns []string
+	// Rows hold values in the order of the columns.
+	Rows [][]interface{}
+}
+
+// Query parses and runs the SELECT statement sql.
+func (db *DB) Query(sql string) (*Result, error) {
+	s, err := Parse(sql)
+	if err != nil {
+		return nil, err
+	}
+	return db.Run(s)
+}
+
+// Run runs the SELECT statement s.
+func (db *DB) Run(s *Select) (*Result, error) {
+	id := s.From
+	for name, cid := range db.Collections {
+		if strings.EqualFold(name, s.From) {
+			id = cid
+		}
+	}
+	collection, err := db.Client.GetCollection(id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "sqlfront: collection %q", s.From)
+	}
+	t := newTable(collection)
+	columns := s.Columns
+	if len(columns) == 0 {
+		columns = t.names
+	}
+	var refs []string
+	refs = append(refs, columns...)
+	for _, o := range s.OrderBy {
+		refs = append(refs, o.Column)
+	}
+	refs = append(refs, columnRefs(s.Where)...)
+	for _, name := range refs {
+		if _, err := t.column(name); err != nil {
+			return nil, err
+		}
+	}
+	q, err := Compile(s, collection)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []map[string]interface{}
+	it := db.Client.IterateCollection(collection.ID, "", notion.QueryOptions{Query: q})
+	for it.Next() {
+		row := t.values(it.Row())
+		if s.Where != nil {
+			ok, err := t.eval(s.Where, row)
+			if err != nil {
+				return nil, err
+			}
+			if ok != true {
+				continue
+			}
+		}
+		rows = append(rows, row)
+	}
+	if it.Err() != nil {
+		return nil, it.Err()
+	}
+	if len(s.OrderBy) > 0 {
+		sort.SliceStable(rows, func(i, j int) bool {
+			for _, o := range s.OrderBy {
+				key, _ := t.column(o.Column)
+				c := compareNullsFirst(rows[i][key], rows[j][key])
+				if c != 0 {
+					return c < 0 != o.Desc
+				}
+			}
+			return false
+		})
+	}
+	if s.Offset >= len(rows) {
+		rows = nil
+	} else {
+		rows = rows[s.Offset:]
+	}
+	if s.Limit >= 0 && s.Limit < len(rows) {
+		rows = rows[:s.Limit]
+	}
+	res := &Result{Columns: make([]string, len(columns))}
+	keys := make([]string, len(columns))
+	for i, name := range columns {
+		keys[i], _ = t.column(name)
+		res.Columns[i] = t.display[keys[i]]
+	}
+	for _, row := range rows {
+		values := make([]interface{}, len(keys))
+		for i, k := range keys {
+			values[i] = row[k]
+		}
+		res.Rows = append(res.Rows, values)
+	}
+	return res, nil
+}
+
+// Compile returns the query sent to notion for s: the conditions of its
+// WHERE clause that notion's filters can express, and its order. It
+// returns nil if there are neither.
+func Compile(s *Select, collection *notiontypes.Collection) (*notiontypes.CollectionQuery, error) {
+	var filters []*query.Filter
+	for _, x := range conjuncts(s.Where) {
+		f := pushdown(x, collection)
+		if f == nil {
+			continue
+		}
+		// the builder knows which operators properties support
+		if _, err := f.Compile(collection); err == nil {
+			filters = append(filters, f)
+		}
+	}
+	q := &notiontypes.CollectionQuery{}
+	if len(filters) > 0 {
+		filter, err := query.All(filters...).Compile(collection)
+		if err != nil {
+			return nil, err
+		}
+		q.Filter = filter
+	}
+	t := newTable(collection)
+	for _, o := range s.OrderBy {
+		key, err := t.column(o.Column)
+		if err != nil {
+			return nil, err
+		}
+		if key == idColumn {
+			break
+		}
+		dir := notiontypes.SortAscending
+		if o.Desc {
+			dir = notiontypes.SortDescending
+		}
+		q.Sort = append(q.Sort, &notiontypes.QuerySort{Property: key, Direction: dir})
+	}
+	if q.Filter == nil && q.Sort == nil {
+		return nil, nil
+	}
+	return q, nil
+}
+
+// conjuncts returns the conditions x is the AND of.
+func conjuncts(x Expr) []Expr {
+	if b, ok := x.(*Binary); ok && b.Op == "AND" {
+		return append(conjuncts(b.Left), conjuncts(b.Right)...)
+	}
+	if x == nil {
+		return nil
+	}
+	return []Expr{x}
+}
+
+// pushdown returns x as a notion filter, or nil if it can't be expressed.
+func pushdown(x Expr, collection *notiontypes.Collection) *query.Filter {
+	switch x := x.(type) {
+	case *Binary:
+		if x.Op == "AND" || x.Op == "OR" {
+			l, r := pushdown(x.Left, collection), pushdown(x.Right, collection)
+			if l == nil || r == nil {
+				return nil
+			}
+			if x.Op == "AND" {
+				return l.And(r)
+			}
+			return l.Or(r)
+		}
+		col, lit, op := comparison(x)
+		if col == nil || lit.Value == nil {
+			return nil
+		}
+		p := query.Where(col.Name)
+		typ := propertyType(collection, col.Name)
+		v := lit.Value
+		if typ == notiontypes.ColumnTypeDate {
+			s, _ := v.(string)
+			t, err := time.Parse("2006-01-02", s)
+			if err != nil {
+				return nil
+			}
+			v = t
+			switch op {
+			case "<":
+				return p.Before(t)
+			case ">":
+				return p.After(t)
+			case "<=":
+				return p.OnOrBefore(t)
+			case ">=":
+				return p.OnOrAfter(t)
+			}
+		}
+		n, isNumber := v.(float64)
+		switch op {
+		case "=":
+			return p.Is(v)
+		case "!=":
+			return p.IsNot(v)
+		case "<":
+			if isNumber {
+				return p.LessThan(n)
+			}
+		case ">":
+			if isNumber {
+				return p.GreaterThan(n)
+			}
+		case "<=":
+			if isNumber {
+				return p.AtMost(n)
+			}
+		case ">=":
+			if isNumber {
+				return p.AtLeast(n)
+			}
+		}
+	case *Like:
+		col, ok := x.X.(*Column)
+		if !ok {
+			return nil
+		}
+		p := query.Where(col.Name)
+		inner := strings.Trim(x.Pattern, "%")
+		if inner == "" || strings.ContainsAny(inner, "%_") {
+			return nil
+		}
+		prefix, suffix := strings.HasPrefix(x.Pattern, "%"), strings.HasSuffix(x.Pattern, "%")
+		switch {
+		case x.Not && prefix && suffix:
+			return p.DoesNotContain(inner)
+		case x.Not:
+		case prefix && suffix:
+			return p.Contains(inner)
+		case suffix:
+			return p.StartsWith(inner)
+		case prefix:
+			return p.EndsWith(inner)
+		}
+	case *IsNull:
+		if col, ok := x.X.(*Column); ok {
+			if x.Not {
+				return query.Where(col.Name).IsNotEmpty()
+			}
+			return query.Where(col.Name).IsEmpty()
+		}
+	case *In:
+		col, ok := x.X.(*Column)
+		if !ok || x.Not {
+			return nil
+		}
+		var alts []*query.Filter
+		for _, v := range x.Values {
+			f := pushdown(&Binary{Op: "=", Left: col, Right: v}, collection)
+			if f == nil {
+				return nil
+			}
+			alts = append(alts, f)
+		}
+		return query.Any(alts...)
+	}
+	return nil
+}
+
+// comparison returns the column and value compared by b, with the
+// operator as if the column was on the left.
+func comparison(b *Binary) (*Column, *Literal, string) {
+	if col, ok := b.Left.(*Column); ok {
+		lit, _ := b.Right.(*Literal)
+		if lit == nil {
+			return nil, nil, ""
+		}
+		return col, lit, b.Op
+	}
+	col, ok := b.Right.(*Column)
+	lit, _ := b.Left.(*Literal)
+	if !ok || lit == nil {
+		return nil, nil, ""
+	}
+	flipped := map[string]string{"<": ">", ">": "<", "<=": ">=", ">=": "<="}[b.Op]
+	if flipped == "" {
+		flipped = b.Op
+	}
+	return col, lit, flipped
+}
+
+func propertyType(collection *notiontypes.Collection, name string) string {
+	for _, col := range collection.CollectionSchema {
+		if strings.EqualFold(col.Name, name) {
+			return col.Type
+		}
+	}
+	return ""
+}
+
+func columnRefs(x Expr) []string {
+	switch x := x.(type) {
+	case *Column:
+		return []string{x.Name}
+	case *Binary:
+		return append(columnRefs(x.Left), columnRefs(x.Right)...)
+	case *Not:
+		return columnRefs(x.X)
+	case *Like:
+		return columnRefs(x.X)
+	case *IsNull:
+		return columnRefs(x.X)
+	case *In:
+		return columnRefs(x.X)
+	}
+	return nil
+}
+
+// idColumn is the key of the id column in rows.
+const idColumn = ""
+
+// table maps the columns of a collection to the values of its rows.
+type table struct {
+	collection *notiontypes.Collection
+	// names are the names of the columns, title first then by name
+	names []string
+	// keys are property ids, or idColumn, by lower case name
+	keys    map[string]string
+	display map[string]string
+}
+
+func newTable(collection *notiontypes.Collection) *table {
+	t := &table{collection: collection, keys: map[string]string{}, display: map[string]string{idColumn: "id"}}
+	var ids []string
+	for id := range collection.CollectionSchema {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, b := collection.CollectionSchema[ids[i]], collection.CollectionSchema[ids[j]]
+		if (a.Type == notiontypes.ColumnTypeTitle) != (b.Type == notiontypes.ColumnTypeTitle) {
+			return a.Type == notiontypes.ColumnTypeTitle
+		}
+		return a.Name < b.Name
+	})
+	t.keys["id"] = idColumn
+	for _, id := range ids {
+		name := collection.CollectionSchema[id].Name
+		t.names = append(t.names, name)
+		t.keys[strings.ToLower(name)] = id
+		t.display[id] = name
+	}
+	return t
+}
+
+func (t *table) column(name string) (string, error) {
+	key, ok := t.keys[strings.ToLower(name)]
+	if !ok {
+		return "", errors.Errorf("sqlfront: no column %q", name)
+	}
+	return key, nil
+}
+
+// values returns the values of the columns of row by key.
+func (t *table) values(row *notiontypes.Block) map[string]interface{} {
+	values := map[string]interface{}{idColumn: row.ID}
+	for id, info := range t.collection.CollectionSchema {
+		p := t.collection.Property(row, id)
+		var v interface{}
+		switch info.Type {
+		case notiontypes.ColumnTypeCheckbox:
+			v = p != nil && p.Checked()
+		case notiontypes.ColumnTypeNumber:
+			if p != nil {
+				if n, err := strconv.ParseFloat(p.Text(), 64); err == nil {
+					v = n
+				}
+			}
+		case notiontypes.ColumnTypeDate:
+			if d := dateOf(p); d != nil {
+				v = d.StartDate
+				if d.StartTime != nil {
+					v = d.StartDate + " " + *d.StartTime
+				}
+			}
+		default:
+			if p != nil && p.Text() != "" {
+				v = p.Text()
+			}
+		}
+		values[id] = v
+	}
+	return values
+}
+
+func dateOf(p *notiontypes.PageProperty) *notiontypes.Date {
+	if p == nil {
+		return nil
+	}
+	return p.Date()
+}
+
+// eval evaluates x on row, returning nil for NULL (unknown).
+func (t *table) eval(x Expr, row map[string]interface{}) (interface{}, error) {
+	switch x := x.(type) {
+	case *Literal:
+		return x.Value, nil
+	case *Column:
+		key, err := t.column(x.Name)
+		if err != nil {
+			return nil, err
+		}
+		return row[key], nil
+	case *Not:
+		v, err := t.eval(x.X, row)
+		if b, ok := v.(bool); ok {
+			return !b, err
+		}
+		return nil, err
+	case *IsNull:
+		v, err := t.eval(x.X, row)
+		return (v == nil) != x.Not, err
+	case *Like:
+		v, err := t.eval(x.X, row)
+		if err != nil || v == nil {
+			return nil, err
+		}
+		return likeRegexp(x.Pattern).MatchString(toString(v)) != x.Not, nil
+	case *In:
+		v, err := t.eval(x.X, row)
+		if err != nil || v == nil {
+			return nil, err
+		}
+		for _, lit := range x.Values {
+			if c, ok := compare(v, lit.Value); ok && c == 0 {
+				return !x.Not, nil
+			}
+		}
+		return x.Not, nil
+	case *Binary:
+		l, err := t.eval(x.Left, row)
+		if err != nil {
+			return nil, err
+		}
+		if x.Op == "AND" || x.Op == "OR" {
+			// three-valued logic: FALSE AND NULL is FALSE, TRUE OR NULL TRUE
+			short := x.Op == "OR"
+			if l == short {
+				return short, nil
+			}
+			r, err := t.eval(x.Right, row)
+			if err != nil || r == short {
+				return r, err
+			}
+			if l == nil || r == nil {
+				return nil, nil
+			}
+			return !short, nil
+		}
+		r, err := t.eval(x.Right, row)
+		if err != nil || l == nil || r == nil {
+			return nil, err
+		}
+		c, ok := compare(l, r)
+		if !ok {
+			return nil, errors.Errorf("sqlfront: can't compare %v and %v", l, r)
+		}
+		switch x.Op {
+		case "=":
+			return c == 0, nil
+		case "!=":
+			return c != 0, nil
+		case "<":
+			return c < 0, nil
+		case "<=":
+			return c <= 0, nil
+		case ">":
+			return c > 0, nil
+		case ">=":
+			return c >= 0, nil
+		}
+	}
+	return nil, errors.Errorf("sqlfront: unsupported expression %T", x)
+}
+
+// compare compares non-NULL values, converting strings to numbers or
+// booleans to compare them with numbers or booleans.
+func compare(a, b interface{}) (int, bool) {
+	switch a := a.(type) {
+	case float64:
+		n, ok := b.(float64)
+		if s, isString := b.(string); isString {
+			var err error
+			n, err = strconv.ParseFloat(s, 64)
+			ok = err == nil
+		}
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case a < n:
+			return -1, true
+		case a > n:
+			return 1, true
+		}
+		return 0, true
+	case bool:
+		v, ok := b.(bool)
+		if s, isString := b.(string); isString {
+			v, ok = strings.EqualFold(s, "true") || strings.EqualFold(s, "yes"), true
+		}
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case a == v:
+			return 0, true
+		case !a:
+			return -1, true
+		}
+		return 1, true
+	case string:
+		if s, ok := b.(string); ok {
+			return strings.Compare(a, s), true
+		}
+		c, ok := compare(b, a)
+		return -c, ok
+	}
+	return 0, false
+}
+
+// compareNullsFirst orders values for ORDER BY, with NULLs first.
+func compareNullsFirst(a, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	if c, ok := compare(a, b); ok {
+		return c
+	}
+	return strings.Compare(toString(a), toString(b))
+}
+
+func toString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return ""
+}
+
+// likeRegexp returns the regular expression of a LIKE pattern.
+func likeRegexp(pattern string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("(?is)^")
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			b.WriteString(".*")
+		case '_':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
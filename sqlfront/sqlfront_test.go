@@ -0,0 +1,113 @@
+package sqlfront
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/tmc/notion/notiontest"
+	"github.com/tmc/notion/notiontypes"
+)
+
+func TestQuery(t *testing.T) {
+	s := notiontest.NewServer()
+	defer s.Close()
+	s.AddRecord(notiontypes.TableCollection, "db", map[string]interface{}{
+		"schema": map[string]interface{}{
+			"title": map[string]string{"name": "Name", "type": "title"},
+			"st":    map[string]string{"name": "Status", "type": "select"},
+			"pt":    map[string]string{"name": "Points", "type": "number"},
+			"ok":    map[string]string{"name": "Done", "type": "checkbox"},
+		},
+	})
+	for i, r := range []struct {
+		name, status, points string
+		done                 bool
+	}{
+		{"Write docs", "Done", "3", true},
+		{"Fix bug", "Doing", "5", false},
+		{"Ship it", "Done", "8", true},
+		{"Plan", "", "", false},
+	} {
+		props := map[string]interface{}{"title": [][]string{{r.name}}}
+		if r.status != "" {
+			props["st"] = [][]string{{r.status}}
+			props["pt"] = [][]string{{r.points}}
+		}
+		if r.done {
+			props["ok"] = [][]string{{"Yes"}}
+		}
+		s.AddBlock(&notiontypes.Block{
+			ID:          fmt.Sprintf("row%d", i),
+			Type:        notiontypes.BlockPage,
+			ParentID:    "db",
+			ParentTable: notiontypes.TableCollection,
+			CreatedTime: int64(i),
+			Properties:  props,
+		})
+	}
+	db := &DB{Client: s.Client(), Collections: map[string]string{"Tasks": "db"}}
+
+	for _, tt := range []struct {
+		sql  string
+		want [][]interface{}
+	}{
+		{`SELECT name, points FROM tasks WHERE status = 'Done' ORDER BY points DESC`,
+			[][]interface{}{{"Ship it", 8.0}, {"Write docs", 3.0}}},
+		{`select Name from "tasks" where Points >= 4 and not (Name like 's%' or Done) limit 5`,
+			[][]interface{}{{"Fix bug"}}},
+		{`SELECT id, Done FROM Tasks WHERE Points IS NULL OR Status IN ('Doing') ORDER BY id`,
+			[][]interface{}{{"row1", false}, {"row3", false}}},
+		{`SELECT Name FROM Tasks ORDER BY Points LIMIT 2 OFFSET 1;`,
+			[][]interface{}{{"Write docs"}, {"Fix bug"}}},
+	} {
+		res, err := db.Query(tt.sql)
+		if err != nil {
+			t.Errorf("%s: %v", tt.sql, err)
+			continue
+		}
+		if !reflect.DeepEqual(res.Rows, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.sql, res.Rows, tt.want)
+		}
+	}
+	res, err := db.Query(`SELECT * FROM tasks LIMIT 1`)
+	if err != nil || !reflect.DeepEqual(res.Columns, []string{"Name", "Done", "Points", "Status"}) {
+		t.Errorf("got columns %v, %v", res, err)
+	}
+
+	// only the conditions notion can evaluate are sent with the query
+	sel, err := Parse(`SELECT * FROM tasks WHERE Status = 'Done' AND Points > 2 AND Name LIKE '%i_e%' AND NOT Done ORDER BY Points`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	collection, err := db.Client.GetCollection("db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := Compile(sel, collection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := json.Marshal(q)
+	want := `{"filter":{"operator":"and","filters":[` +
+		`{"property":"st","filter":{"operator":"enum_is","value":{"type":"exact","value":"Done"}}},` +
+		`{"property":"pt","filter":{"operator":"number_greater_than","value":{"type":"exact","value":2}}}]},` +
+		`"sort":[{"property":"pt","direction":"ascending"}]}`
+	if string(b) != want {
+		t.Errorf("got query\n%s\nwant\n%s", b, want)
+	}
+
+	for _, sql := range []string{
+		`SELECT FROM tasks`,
+		`SELECT Name FROM tasks WHERE`,
+		`SELECT Name FROM tasks WHERE Name = 'unterminated`,
+		`SELECT Name FROM tasks LIMIT -1`,
+		`SELECT Name FROM tasks; DELETE FROM tasks`,
+		`SELECT Nope FROM tasks`,
+	} {
+		if _, err := db.Query(sql); err == nil {
+			t.Errorf("%s: no error", sql)
+		}
+	}
+}
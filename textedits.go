@@ -0,0 +1,67 @@
+package notion
+
+import (
+	"github.com/pkg/errors"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// titlePath is the path of the text of blocks.
+var titlePath = []string{"properties", "title"}
+
+// InsertText inserts text, unformatted, at offset into the text of the
+// block blockID, keeping the formatting of the rest of the text. Offsets
+// count characters (runes), not bytes or the UTF-16 code units of notion's
+// editor, so that emoji count as one character.
+//
+// InsertText is a helper, not an atomic edit: notion has no documented
+// command editing text at an offset, so it reads the block's text, edits
+// it and writes it all back with a "set" of its properties.title, as
+// UpdateBlock does. Edits made to the block in between are overwritten.
+func (c *Client) InsertText(blockID string, offset int, text string) error {
+	if offset < 0 {
+		return errors.Errorf("negative offset %d", offset)
+	}
+	return c.editText(blockID, offset, offset, &notiontypes.InlineBlock{Text: text})
+}
+
+// DeleteText deletes length characters (runes) at offset from the text of
+// the block blockID. Like InsertText, it rewrites the whole text and isn't
+// atomic.
+func (c *Client) DeleteText(blockID string, offset, length int) error {
+	if offset < 0 || length < 0 {
+		return errors.Errorf("invalid range of %d characters at %d", length, offset)
+	}
+	return c.editText(blockID, offset, offset+length)
+}
+
+// editText replaces the text between the rune offsets start and end of the
+// block blockID with insert.
+func (c *Client) editText(blockID string, start, end int, insert ...*notiontypes.InlineBlock) error {
+	blockID, err := FormatID(blockID)
+	if err != nil {
+		return err
+	}
+	blocks, err := c.GetBlocks(blockID)
+	if err != nil {
+		return err
+	}
+	if blocks[0] == nil {
+		return errors.Errorf("block %v not found", blockID)
+	}
+	var runs []*notiontypes.InlineBlock
+	if title := blocks[0].Properties["title"]; title != nil {
+		if runs, err = notiontypes.ParseInlineBlocks(title); err != nil {
+			return errors.Wrapf(err, "parsing text of %v", blockID)
+		}
+	}
+	if n := notiontypes.TextLength(runs); end > n {
+		return errors.Errorf("range %d-%d is out of the text of %d characters of %v", start, end, n, blockID)
+	}
+	return c.submitTransaction(&operation{
+		ID:      blockID,
+		Table:   notiontypes.TableBlock,
+		Path:    titlePath,
+		Command: "set",
+		Args:    notiontypes.EncodeInlineBlocks(notiontypes.ReplaceRange(runs, start, end, insert...)),
+	})
+}
@@ -0,0 +1,57 @@
+package notion_test
+
+import (
+	"testing"
+
+	"github.com/tmc/notion/notiontest"
+	"github.com/tmc/notion/notiontypes"
+)
+
+func TestInsertDeleteText(t *testing.T) {
+	s := notiontest.NewServer()
+	defer s.Close()
+	const blockID = "4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+	s.AddBlock(&notiontypes.Block{ID: blockID, Type: notiontypes.BlockText, Properties: map[string]interface{}{
+		"title": []interface{}{[]interface{}{"Grüße "}, []interface{}{"world", []interface{}{[]interface{}{"b"}}}},
+	}})
+	c := s.Client()
+
+	if err := c.InsertText(blockID, 6, "big "); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DeleteText(blockID, 0, 2); err != nil {
+		t.Fatal(err)
+	}
+	ops := s.Operations()
+	if len(ops) != 2 || ops[0].Command != "set" || ops[1].Command != "set" {
+		t.Fatalf("got operations %+v", ops)
+	}
+	text := s.Block(blockID).InlineContent
+	if len(text) != 2 || text[0].Text != "üße big " || text[1].Text != "world" || text[1].AttrFlags != notiontypes.AttrBold {
+		t.Errorf("got text %+v", text)
+	}
+	if err := c.DeleteText(blockID, 10, 10); err == nil {
+		t.Error("deleting past the end of the text succeeded")
+	}
+}
+
+func TestInsertTextAfterEmoji(t *testing.T) {
+	s := notiontest.NewServer()
+	defer s.Close()
+	const blockID = "4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+	// 🚀 is one rune, but two UTF-16 code units
+	s.AddBlock(&notiontypes.Block{ID: blockID, Type: notiontypes.BlockText, Properties: map[string]interface{}{
+		"title": []interface{}{[]interface{}{"🚀 launch"}},
+	}})
+	c := s.Client()
+
+	if err := c.InsertText(blockID, 2, "big "); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DeleteText(blockID, 0, 1); err != nil {
+		t.Fatal(err)
+	}
+	if text := s.Block(blockID).InlineContent; len(text) != 1 || text[0].Text != " big launch" {
+		t.Errorf("got text %+v", text)
+	}
+}
@@ -0,0 +1,63 @@
+package notion
+
+import (
+	"context"
+	"encoding/json"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/tmc/notion"
+
+// WithTracerProvider makes the client record an OpenTelemetry span for every
+// API call, with the endpoint, status code, response size and the number of
+// records returned as attributes. Use Client.WithContext to parent the spans.
+func WithTracerProvider(tp trace.TracerProvider) ClientOption {
+	return func(c *Client) {
+		c.tracer = tp.Tracer(tracerName)
+	}
+}
+
+// startSpan starts the span of a call to endpoint, if the client has a tracer.
+func (c *Client) startSpan(method, endpoint string) (context.Context, trace.Span) {
+	ctx := c.context()
+	if c.tracer == nil {
+		return ctx, nil
+	}
+	return c.tracer.Start(ctx, "notion."+endpoint,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.method", method),
+			attribute.String("notion.endpoint", endpoint),
+		),
+	)
+}
+
+// endSpan records the outcome of an API call in span and ends it.
+func endSpan(span trace.Span, status int, body []byte, err error) {
+	defer span.End()
+	if status != 0 {
+		span.SetAttributes(attribute.Int("http.status_code", status))
+	}
+	span.SetAttributes(attribute.Int("http.response_content_length", len(body)))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return
+	}
+	var counts struct {
+		RecordMap map[string]map[string]json.RawMessage `json:"recordMap"`
+		Results   []json.RawMessage                     `json:"results"`
+	}
+	if json.Unmarshal(body, &counts) != nil {
+		return
+	}
+	for table, records := range counts.RecordMap {
+		span.SetAttributes(attribute.Int("notion.records."+table, len(records)))
+	}
+	if counts.Results != nil {
+		span.SetAttributes(attribute.Int("notion.results", len(counts.Results)))
+	}
+}
@@ -1,20 +1,27 @@
 package notion
 
-import "github.com/tmc/notion/notiontypes"
+import (
+	"github.com/tmc/notion/notiontypes"
+	"github.com/tmc/notion/pagination"
+)
 
 // Page is a notion.so page.
 type Page struct {
 	*notiontypes.Block
+	// PageProperties holds the typed properties of pages that are rows of a
+	// collection (database).
+	PageProperties []*notiontypes.PageProperty
+	// Role is the role of the user on the page, e.g.
+	// notiontypes.RoleEditor.
+	Role string
 }
 
 // StackPosition refers to a position within a list of entities (usually blocks).
-type StackPosition struct {
-	ID    string  `json:"id,omitempty"`
-	Index float64 `json:"index,omitempty"`
-	Table string  `json:"table,omitempty"`
-}
+//
+// Deprecated: use pagination.StackPosition.
+type StackPosition = pagination.StackPosition
 
 // Cursor is used for pagination of entities.
-type Cursor struct {
-	Stack [][]StackPosition `json:"stack"`
-}
+//
+// Deprecated: use pagination.Cursor.
+type Cursor = pagination.Cursor
@@ -0,0 +1,36 @@
+package notion
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion/notiontypes"
+)
+
+type getUserValuesResponse struct {
+	Results []*notiontypes.UserWithRole `json:"results"`
+}
+
+// GetUsers returns the users with the given ids. Users that can't be read
+// are left out.
+func (c *Client) GetUsers(userIDs ...string) ([]*notiontypes.User, error) {
+	req := getRecordValuesRequest{}
+	for _, id := range userIDs {
+		req.Requests = append(req.Requests, Record{ID: id, Table: "notion_user"})
+	}
+	b, err := c.post(req, "getRecordValues")
+	if err != nil {
+		return nil, err
+	}
+	r := &getUserValuesResponse{}
+	if err := json.Unmarshal(b, r); err != nil {
+		return nil, errors.Wrap(err, "unmarshaling getRecordValuesResponse")
+	}
+	users := make([]*notiontypes.User, 0, len(r.Results))
+	for _, u := range r.Results {
+		if u.Value != nil {
+			users = append(users, u.Value)
+		}
+	}
+	return users, nil
+}
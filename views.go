@@ -0,0 +1,298 @@
+package notion
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// ViewConfig describes a view created by CreateCollectionView. Properties
+// are referred to by (case-insensitive) name or id.
+type ViewConfig struct {
+	// Name defaults to the type of the view, e.g. "Board view".
+	Name string
+	// Properties, if not empty, are the only properties shown, in order.
+	Properties []string
+	// Filter can be built with package query.
+	Filter *notiontypes.QueryFilter
+	Sort   []*notiontypes.QuerySort
+	// GroupBy is the select property whose options are the columns of a
+	// board view.
+	GroupBy string
+	// DateBy is the date property placing the rows of a calendar or
+	// timeline view.
+	DateBy string
+}
+
+// CreateCollectionView adds a view of type viewType (notiontypes.ViewTable,
+// ViewBoard etc.) to the database collectionID and returns the id of the
+// view. The view is shown after the existing ones.
+func (c *Client) CreateCollectionView(collectionID, viewType string, config ViewConfig) (string, error) {
+	collection, err := c.GetCollection(collectionID)
+	if err != nil {
+		return "", err
+	}
+	if collection.ParentTable != notiontypes.TableBlock {
+		return "", errors.Errorf("notion: collection %v is not shown by a block", collectionID)
+	}
+	view, err := newCollectionView(collection, viewType, config)
+	if err != nil {
+		return "", err
+	}
+	if view.ID, err = FormatID(c.newID()); err != nil {
+		return "", err
+	}
+	return view.ID, c.submitTransaction(&operation{
+		ID:      view.ID,
+		Table:   notiontypes.TableCollectionView,
+		Path:    []string{},
+		Command: "set",
+		Args:    view,
+	}, &operation{
+		ID:      collection.ParentID,
+		Table:   notiontypes.TableBlock,
+		Path:    []string{"view_ids"},
+		Command: "listAfter",
+		Args:    map[string]string{"id": view.ID},
+	})
+}
+
+// newCollectionView returns the record of a view of collection, without id.
+func newCollectionView(collection *notiontypes.Collection, viewType string, config ViewConfig) (*notiontypes.CollectionView, error) {
+	props, err := viewProperties(collection, config.Properties)
+	if err != nil {
+		return nil, err
+	}
+	format := &notiontypes.CollectionViewFormat{}
+	switch viewType {
+	case notiontypes.ViewTable:
+		format.TableProperties = props
+	case notiontypes.ViewBoard:
+		format.BoardProperties = props
+		if config.GroupBy == "" {
+			return nil, errors.New("notion: board views need a GroupBy property")
+		}
+		id, col, err := schemaProperty(collection, config.GroupBy)
+		if err != nil {
+			return nil, err
+		}
+		if col.Type != notiontypes.ColumnTypeSelect && col.Type != notiontypes.ColumnMultiSelect {
+			return nil, errors.Errorf("notion: can't group a board by the %v property %q", col.Type, col.Name)
+		}
+		format.BoardColumnsBy = &notiontypes.ViewGroupBy{Type: col.Type, Property: id}
+	case notiontypes.ViewCalendar, notiontypes.ViewTimeline:
+		if config.DateBy == "" {
+			return nil, errors.Errorf("notion: %v views need a DateBy property", viewType)
+		}
+		id, col, err := schemaProperty(collection, config.DateBy)
+		if err != nil {
+			return nil, err
+		}
+		switch col.Type {
+		case notiontypes.ColumnTypeDate, notiontypes.ColumnTypeCreatedTime, notiontypes.ColumnTypeLastEditedTime:
+		default:
+			return nil, errors.Errorf("notion: can't place rows by the %v property %q", col.Type, col.Name)
+		}
+		if viewType == notiontypes.ViewCalendar {
+			format.CalendarProperties, format.CalendarBy = props, id
+		} else {
+			format.TimelineProperties, format.TimelineBy = props, id
+		}
+	case notiontypes.ViewList:
+		format.ListProperties = props
+	case notiontypes.ViewGallery:
+		format.GalleryProperties = props
+	default:
+		return nil, errors.Errorf("notion: unknown view type %q", viewType)
+	}
+	query := &notiontypes.CollectionQuery{}
+	if config.Filter != nil {
+		if query.Filter, err = resolveFilter(collection, config.Filter); err != nil {
+			return nil, err
+		}
+	}
+	for _, s := range config.Sort {
+		id, _, err := schemaProperty(collection, s.Property)
+		if err != nil {
+			return nil, err
+		}
+		dir := s.Direction
+		if dir == "" {
+			dir = notiontypes.SortAscending
+		}
+		query.Sort = append(query.Sort, &notiontypes.QuerySort{Property: id, Direction: dir})
+	}
+	name := config.Name
+	if name == "" {
+		name = strings.ToUpper(viewType[:1]) + viewType[1:] + " view"
+	}
+	return &notiontypes.CollectionView{
+		Alive:       true,
+		Format:      format,
+		Name:        name,
+		ParentID:    collection.ParentID,
+		ParentTable: notiontypes.TableBlock,
+		Query2:      query,
+		Type:        viewType,
+		Version:     1,
+	}, nil
+}
+
+// viewProperties returns the properties shown by a view, all those of
+// collection if names is empty.
+func viewProperties(collection *notiontypes.Collection, names []string) ([]*notiontypes.TableProperty, error) {
+	if len(names) == 0 {
+		var props []*notiontypes.TableProperty
+		for _, id := range sortedSchemaIDs(collection) {
+			props = append(props, &notiontypes.TableProperty{Property: id, Visible: true})
+		}
+		return props, nil
+	}
+	shown := map[string]bool{}
+	var props []*notiontypes.TableProperty
+	for _, name := range names {
+		id, _, err := schemaProperty(collection, name)
+		if err != nil {
+			return nil, err
+		}
+		shown[id] = true
+		props = append(props, &notiontypes.TableProperty{Property: id, Visible: true})
+	}
+	for _, id := range sortedSchemaIDs(collection) {
+		if !shown[id] {
+			props = append(props, &notiontypes.TableProperty{Property: id})
+		}
+	}
+	return props, nil
+}
+
+// sortedSchemaIDs returns the ids of the properties of collection, the
+// title first and the others by name.
+func sortedSchemaIDs(collection *notiontypes.Collection) []string {
+	var ids []string
+	for id := range collection.CollectionSchema {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		ti := collection.CollectionSchema[ids[i]].Type == notiontypes.ColumnTypeTitle
+		tj := collection.CollectionSchema[ids[j]].Type == notiontypes.ColumnTypeTitle
+		if ti != tj {
+			return ti
+		}
+		ni, nj := collection.CollectionSchema[ids[i]].Name, collection.CollectionSchema[ids[j]].Name
+		if ni != nj {
+			return ni < nj
+		}
+		return ids[i] < ids[j]
+	})
+	return ids
+}
+
+// schemaProperty returns the id and description of the property of
+// collection with the given (case-insensitive) name or id.
+func schemaProperty(collection *notiontypes.Collection, name string) (string, *notiontypes.CollectionColumnInfo, error) {
+	if col, ok := collection.CollectionSchema[name]; ok {
+		return name, col, nil
+	}
+	for id, col := range collection.CollectionSchema {
+		if strings.EqualFold(col.Name, name) {
+			return id, col, nil
+		}
+	}
+	return "", nil, errors.Errorf("notion: collection %v has no property %q", collection.ID, name)
+}
+
+// resolveFilter returns a copy of f referring to properties by id.
+func resolveFilter(collection *notiontypes.Collection, f *notiontypes.QueryFilter) (*notiontypes.QueryFilter, error) {
+	res := *f
+	if f.Property != "" {
+		id, _, err := schemaProperty(collection, f.Property)
+		if err != nil {
+			return nil, err
+		}
+		res.Property = id
+	}
+	res.Filters = nil
+	for _, sub := range f.Filters {
+		r, err := resolveFilter(collection, sub)
+		if err != nil {
+			return nil, err
+		}
+		res.Filters = append(res.Filters, r)
+	}
+	return &res, nil
+}
+
+// GetDatabase returns the collection view block blockID and the collection
+// it shows. For linked databases, see Block.IsLinkedDatabase, that's the
+// collection of another block.
+func (c *Client) GetDatabase(blockID string) (*notiontypes.Block, *notiontypes.Collection, error) {
+	blocks, err := c.GetBlocks(blockID)
+	if err != nil {
+		return nil, nil, err
+	}
+	b := blocks[0]
+	if b == nil {
+		return nil, nil, errors.Errorf("notion: block %v not found", blockID)
+	}
+	if b.CollectionID == "" {
+		return nil, nil, errors.Errorf("notion: block %v is not a database", blockID)
+	}
+	collection, err := c.GetCollection(b.CollectionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return b, collection, nil
+}
+
+// CreateLinkedDatabase adds a linked database to the end of the page
+// pageID: a block showing the database collectionID, owned elsewhere,
+// through a new view of type viewType. It returns the id of the block.
+func (c *Client) CreateLinkedDatabase(pageID, collectionID, viewType string, config ViewConfig) (string, error) {
+	pageID, err := FormatID(pageID)
+	if err != nil {
+		return "", err
+	}
+	collection, err := c.GetCollection(collectionID)
+	if err != nil {
+		return "", err
+	}
+	view, err := newCollectionView(collection, viewType, config)
+	if err != nil {
+		return "", err
+	}
+	if view.ID, err = FormatID(c.newID()); err != nil {
+		return "", err
+	}
+	format := &notiontypes.FormatCollectionView{
+		Pointer: &notiontypes.RecordPointer{ID: collection.ID, Table: notiontypes.TableCollection},
+	}
+	block := &notiontypes.Block{Type: notiontypes.BlockCollectionView}
+	if block.FormatRaw, err = json.Marshal(format); err != nil {
+		return "", err
+	}
+	now := c.clock.Now().UnixNano() / int64(time.Millisecond)
+	ops, err := c.createBlockOps(block, pageID, now)
+	if err != nil {
+		return "", err
+	}
+	args := ops[0].Args.(map[string]interface{})
+	args["collection_id"] = collection.ID
+	args["view_ids"] = []string{view.ID}
+	view.ParentID = block.ID
+	ops = append(ops, &operation{
+		ID:      view.ID,
+		Table:   notiontypes.TableCollectionView,
+		Path:    []string{},
+		Command: "set",
+		Args:    view,
+	})
+	if err := c.submitTransaction(ops...); err != nil {
+		return "", err
+	}
+	return block.ID, nil
+}
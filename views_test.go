@@ -0,0 +1,130 @@
+package notion_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/notiontest"
+	"github.com/tmc/notion/notiontypes"
+)
+
+func TestCreateCollectionView(t *testing.T) {
+	s := notiontest.NewServer()
+	defer s.Close()
+	const blockID = "4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+	s.AddRecord(notiontypes.TableCollection, "db", map[string]interface{}{
+		"parent_id":    blockID,
+		"parent_table": notiontypes.TableBlock,
+		"schema": map[string]interface{}{
+			"title": map[string]string{"name": "Name", "type": "title"},
+			"st":    map[string]string{"name": "Status", "type": "select"},
+			"du":    map[string]string{"name": "Due", "type": "date"},
+		},
+	})
+	s.AddBlock(&notiontypes.Block{ID: blockID, Type: notiontypes.BlockCollectionView, CollectionID: "db", ViewIDs: []string{"table"}})
+	c := s.Client()
+
+	id, err := c.CreateCollectionView("db", notiontypes.ViewBoard, notion.ViewConfig{
+		GroupBy:    "status",
+		Properties: []string{"Due"},
+		Filter: &notiontypes.QueryFilter{Operator: "and", Filters: []*notiontypes.QueryFilter{{
+			Property: "Status",
+			Filter: &notiontypes.FilterCondition{
+				Operator: "enum_is_not",
+				Value:    &notiontypes.FilterValue{Type: notiontypes.FilterExact, Text: "Done"},
+			},
+		}}},
+		Sort: []*notiontypes.QuerySort{{Property: "Due", Direction: notiontypes.SortDescending}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Block(blockID).ViewIDs; len(got) != 2 || got[1] != id {
+		t.Errorf("got view ids %v, want table and %v", got, id)
+	}
+	b, _ := json.Marshal(s.Record(notiontypes.TableCollectionView, id))
+	var view notiontypes.CollectionView
+	if err := json.Unmarshal(b, &view); err != nil {
+		t.Fatal(err)
+	}
+	if view.Type != notiontypes.ViewBoard || view.Name != "Board view" || view.ParentID != blockID {
+		t.Errorf("got view %+v", view)
+	}
+	if by := view.Format.BoardColumnsBy; by == nil || by.Property != "st" || by.Type != "select" {
+		t.Errorf("got board columns by %+v", by)
+	}
+	if props := view.Format.BoardProperties; len(props) != 3 || props[0].Property != "du" || !props[0].Visible || props[1].Visible {
+		t.Errorf("got properties %v", props)
+	}
+	if q := view.Query2; q.Filter.Filters[0].Property != "st" || q.Sort[0].Property != "du" {
+		t.Errorf("got query %+v", q)
+	}
+
+	for typ, config := range map[string]notion.ViewConfig{
+		notiontypes.ViewBoard:    {GroupBy: "Due"},
+		notiontypes.ViewCalendar: {},
+		"spreadsheet":            {},
+		notiontypes.ViewTable:    {Properties: []string{"Missing"}},
+	} {
+		if _, err := c.CreateCollectionView("db", typ, config); err == nil {
+			t.Errorf("expected error for %v view %+v", typ, config)
+		}
+	}
+}
+
+func TestLinkedDatabase(t *testing.T) {
+	s := notiontest.NewServer()
+	defer s.Close()
+	const (
+		sourceID = "4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+		pageID   = "5b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+		legacyID = "6b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+	)
+	s.AddRecord(notiontypes.TableCollection, "db", map[string]interface{}{
+		"id":           "db",
+		"parent_id":    sourceID,
+		"parent_table": notiontypes.TableBlock,
+		"schema": map[string]interface{}{
+			"title": map[string]string{"name": "Name", "type": "title"},
+			"du":    map[string]string{"name": "Due", "type": "date"},
+		},
+	})
+	s.AddBlock(&notiontypes.Block{ID: sourceID, Type: notiontypes.BlockCollectionViewPage, CollectionID: "db", ViewIDs: []string{"table"}})
+	s.AddBlock(&notiontypes.Block{ID: pageID, Type: notiontypes.BlockPage})
+	// linked databases may only point at their collection from their format
+	s.AddBlock(&notiontypes.Block{
+		ID:        legacyID,
+		Type:      notiontypes.BlockCollectionView,
+		FormatRaw: []byte(`{"collection_pointer": {"id": "db", "table": "collection"}}`),
+	})
+	c := s.Client()
+
+	id, err := c.CreateLinkedDatabase(pageID, "db", notiontypes.ViewCalendar, notion.ViewConfig{DateBy: "Due"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Block(pageID).ContentIDs; len(got) != 1 || got[0] != id {
+		t.Errorf("got page content %v, want %v", got, id)
+	}
+	for _, blockID := range []string{id, legacyID, sourceID} {
+		b, collection, err := c.GetDatabase(blockID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if collection.ID != "db" || b.IsLinkedDatabase(collection) != (blockID != sourceID) {
+			t.Errorf("%v: got collection %v, linked %v", blockID, collection.ID, b.IsLinkedDatabase(collection))
+		}
+	}
+	b := s.Block(id)
+	view := s.Record(notiontypes.TableCollectionView, b.ViewIDs[0])
+	if view["parent_id"] != id || view["type"] != notiontypes.ViewCalendar {
+		t.Errorf("got view %v", view)
+	}
+	if got := s.Block(sourceID).ViewIDs; len(got) != 1 {
+		t.Errorf("source database got views %v", got)
+	}
+	if _, _, err := c.GetDatabase(pageID); err == nil {
+		t.Error("expected error for a page")
+	}
+}
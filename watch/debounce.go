@@ -0,0 +1,143 @@
+package watch
+
+import (
+	"sort"
+	"time"
+)
+
+// Debounce configures how a Watcher coalesces bursts of changes to a page,
+// such as the many versions saved while someone types, into single events.
+//
+// The events of a page are held until the page has gone unchanged for
+// Quiet, or once the burst reaches MaxWait or MaxEvents. Held events of
+// the same kind (and property, for row.property_changed events) are
+// merged: the merged event has the latest title, version and editor, the
+// union of the changed block types, and the first old and last new value
+// of a property. A page that is created and then edited is reported as
+// created, one that is edited and then deleted as deleted, and one that is
+// created and deleted within a burst not at all. Changes of a property
+// that end with its old value are dropped.
+//
+// Since the watcher notices changes when it polls, bursts are measured in
+// polls: quiet periods shorter than the interval end at the next poll.
+type Debounce struct {
+	Quiet time.Duration
+	// MaxWait, if not zero, reports a burst of changes at most this long
+	// after its first change was noticed, even if the page keeps changing.
+	MaxWait time.Duration
+	// MaxEvents, if not zero, reports a burst once that many changes are
+	// coalesced.
+	MaxEvents int
+}
+
+// WithDebounce coalesces bursts of changes to a page as configured by d.
+func WithDebounce(d Debounce) Option {
+	return func(w *Watcher) {
+		w.debounce = &d
+	}
+}
+
+// burst holds the events of a page waiting to be reported.
+type burst struct {
+	PageID string    `json:"page_id"`
+	First  time.Time `json:"first"`
+	Last   time.Time `json:"last"`
+	Count  int       `json:"count"`
+	Events []*Event  `json:"events"`
+}
+
+func (b *burst) clone() *burst {
+	c := *b
+	c.Events = make([]*Event, len(b.Events))
+	for i, e := range b.Events {
+		ec := *e
+		c.Events[i] = &ec
+	}
+	return &c
+}
+
+// add merges e into b.
+func (b *burst) add(e *Event) {
+	b.Count++
+	if e.Type == PageDeleted {
+		if len(b.Events) > 0 && b.Events[0].Type == PageCreated {
+			// never reported, so nothing to report
+			b.Events = nil
+			return
+		}
+		b.Events = []*Event{e}
+		return
+	}
+	for _, held := range b.Events {
+		if held.Type == PageCreated || held.Type == e.Type && held.Property == e.Property {
+			merge(held, e)
+			return
+		}
+	}
+	ec := *e
+	b.Events = append(b.Events, &ec)
+}
+
+// merge merges the later event e of the same page into held.
+func merge(held, e *Event) {
+	held.Title, held.Version, held.Time, held.EditedBy = e.Title, e.Version, e.Time, e.EditedBy
+	if held.Type == RowPropertyChanged {
+		held.New = e.New
+	}
+	seen := make(map[string]bool)
+	for _, t := range held.BlockTypes {
+		seen[t] = true
+	}
+	for _, t := range e.BlockTypes {
+		if !seen[t] {
+			held.BlockTypes = append(held.BlockTypes, t)
+		}
+	}
+	if len(e.BlockTypes) > 0 {
+		sort.Strings(held.BlockTypes)
+	}
+}
+
+func (d *Debounce) ready(b *burst, now time.Time) bool {
+	return now.Sub(b.Last) >= d.Quiet ||
+		d.MaxWait > 0 && now.Sub(b.First) >= d.MaxWait ||
+		d.MaxEvents > 0 && b.Count >= d.MaxEvents
+}
+
+// coalesce adds the events of a poll at now to the bursts of pending, and
+// returns the events of the bursts that are ready and the bursts still
+// pending. pending is not modified.
+func (d *Debounce) coalesce(pending []*burst, events []*Event, now time.Time) ([]*Event, []*burst) {
+	bursts := make(map[string]*burst, len(pending))
+	order := make([]*burst, 0, len(pending))
+	for _, b := range pending {
+		b = b.clone()
+		bursts[b.PageID] = b
+		order = append(order, b)
+	}
+	for _, e := range events {
+		b, ok := bursts[e.PageID]
+		if !ok {
+			b = &burst{PageID: e.PageID, First: now}
+			bursts[e.PageID] = b
+			order = append(order, b)
+		}
+		b.Last = now
+		b.add(e)
+	}
+	var ready []*Event
+	var next []*burst
+	for _, b := range order {
+		if !d.ready(b, now) {
+			next = append(next, b)
+			continue
+		}
+		for _, e := range b.Events {
+			if e.Type == RowPropertyChanged && e.Old == e.New {
+				continue
+			}
+			ready = append(ready, e)
+		}
+	}
+	return ready, next
+}
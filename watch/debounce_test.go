@@ -0,0 +1,69 @@
+package watch
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDebounce(t *testing.T) {
+	t0 := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	at := func(s int) time.Time { return t0.Add(time.Duration(s) * time.Second) }
+	edit := func(id string, version int64, types ...string) *Event {
+		return &Event{Type: PageEdited, PageID: id, Title: "Page " + id[:1], Version: version, BlockTypes: types}
+	}
+	status := func(old, new string) *Event {
+		return &Event{Type: RowPropertyChanged, PageID: rowID, Title: "Task", Property: "Status", Old: old, New: new}
+	}
+	d := &Debounce{Quiet: 30 * time.Second, MaxWait: 5 * time.Minute, MaxEvents: 4}
+	polls := []struct {
+		at     int
+		events []*Event
+		want   []string
+	}{
+		{0, []*Event{edit(pageID, 2, "text"), status("Todo", "Doing")}, nil},
+		{10, []*Event{edit(pageID, 3, "to_do")}, nil},
+		{20, []*Event{edit(pageID, 4, "text"), status("Doing", "Done"), {Type: PageCreated, PageID: subPageID, Title: "Sub"}}, nil},
+		// the row is quiet and the page reaches MaxEvents
+		{50, []*Event{edit(pageID, 5, "text"), {Type: PageEdited, PageID: subPageID, Title: "Sub 2"}}, []string{
+			"page.edited Page 4",
+			"row.property_changed Task Status: Todo -> Done",
+		}},
+		// the sub-page is created and deleted within a burst
+		{60, []*Event{{Type: PageDeleted, PageID: subPageID}}, nil},
+		{100, nil, nil},
+		// a property changed back is dropped
+		{110, []*Event{status("Done", "Todo"), edit(textID, 2)}, nil},
+		{120, []*Event{status("Todo", "Done"), edit(textID, 3)}, nil},
+		{140, []*Event{edit(textID, 4)}, nil},
+		{170, nil, []string{"page.edited Page 5"}},
+	}
+	var pending []*burst
+	var edited *Event
+	for _, p := range polls {
+		var events []*Event
+		events, pending = d.coalesce(pending, p.events, at(p.at))
+		if got := summary(events); !reflect.DeepEqual(got, p.want) {
+			t.Errorf("poll at %ds reported %q, want %q", p.at, got, p.want)
+		}
+		if p.at == 50 {
+			edited = events[0]
+		}
+	}
+	if edited.Version != 5 || !reflect.DeepEqual(edited.BlockTypes, []string{"text", "to_do"}) {
+		t.Errorf("coalesced edit has version %d and block types %q", edited.Version, edited.BlockTypes)
+	}
+	if len(pending) != 0 {
+		t.Errorf("%d bursts still pending", len(pending))
+	}
+
+	// MaxWait bounds bursts of a page that keeps changing
+	pending = nil
+	for s := 0; s <= 300; s += 20 {
+		var events []*Event
+		events, pending = (&Debounce{Quiet: time.Minute, MaxWait: 5 * time.Minute}).coalesce(pending, []*Event{edit(pageID, int64(s))}, at(s))
+		if s < 300 && events != nil || s == 300 && len(events) != 1 {
+			t.Fatalf("poll at %ds reported %q", s, summary(events))
+		}
+	}
+}
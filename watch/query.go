@@ -0,0 +1,329 @@
+package watch
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// Query selects events with a small filter language. A query is a list of
+// terms that all have to match, e.g.
+//
+//	type:row.property_changed property:Status new:Done
+//
+// Terms are key:pattern, matched as a case insensitive glob pattern in
+// which * matches any text and ? any character, or key~regexp, matched as a regular expression. Patterns
+// containing spaces or parentheses are quoted like Go strings. The keys are
+//
+//	type      the event type, e.g. page.edited or row.*
+//	page      the id of the page or row
+//	database  the id of the database of rows
+//	user      the id of the user who last edited the page
+//	title     the title of the page or row
+//	property  the name of the property of row.property_changed events
+//	old, new  its old and new value
+//	block     the type of any of the blocks changed by page.edited events
+//
+// Terms combine with "and" (implied between terms), "or" and "not", and
+// are grouped with parentheses:
+//
+//	(type:page.created or block:to_do) and not user:8b1e*
+type Query struct {
+	src  string
+	root queryNode
+}
+
+type queryNode interface {
+	match(e *Event) bool
+}
+
+type queryAnd []queryNode
+type queryOr []queryNode
+type queryNot struct{ queryNode }
+
+func (q queryAnd) match(e *Event) bool {
+	for _, n := range q {
+		if !n.match(e) {
+			return false
+		}
+	}
+	return true
+}
+
+func (q queryOr) match(e *Event) bool {
+	for _, n := range q {
+		if n.match(e) {
+			return true
+		}
+	}
+	return false
+}
+
+func (q queryNot) match(e *Event) bool {
+	return !q.queryNode.match(e)
+}
+
+type queryTerm struct {
+	values  func(e *Event) []string
+	matches func(s string) bool
+}
+
+func (t *queryTerm) match(e *Event) bool {
+	for _, v := range t.values(e) {
+		if t.matches(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// queryKeys returns the values of the keys of queries of an event.
+var queryKeys = map[string]func(e *Event) []string{
+	"type":     func(e *Event) []string { return []string{string(e.Type)} },
+	"page":     func(e *Event) []string { return ids(e.PageID) },
+	"database": func(e *Event) []string { return ids(e.DatabaseID) },
+	"user":     func(e *Event) []string { return ids(e.EditedBy) },
+	"title":    func(e *Event) []string { return []string{e.Title} },
+	"property": func(e *Event) []string { return nonEmpty(e.Property) },
+	"old":      func(e *Event) []string { return propertyValue(e, e.Old) },
+	"new":      func(e *Event) []string { return propertyValue(e, e.New) },
+	"block":    func(e *Event) []string { return e.BlockTypes },
+}
+
+// ids returns id with and without dashes, so that either form matches.
+func ids(id string) []string {
+	if id == "" {
+		return nil
+	}
+	return []string{id, strings.Replace(id, "-", "", -1)}
+}
+
+func nonEmpty(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return []string{s}
+}
+
+func propertyValue(e *Event, v string) []string {
+	if e.Type != RowPropertyChanged {
+		return nil
+	}
+	return []string{v}
+}
+
+// ParseQuery parses a query. An empty query matches all events.
+func ParseQuery(s string) (*Query, error) {
+	p := &queryParser{src: s}
+	if err := p.lex(); err != nil {
+		return nil, err
+	}
+	q := &Query{src: s}
+	if len(p.tokens) == 0 {
+		return q, nil
+	}
+	root, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, p.errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	q.root = root
+	return q, nil
+}
+
+// Match reports whether q selects e. A nil query matches all events.
+func (q *Query) Match(e *Event) bool {
+	return q == nil || q.root == nil || q.root.match(e)
+}
+
+// String returns the source of q.
+func (q *Query) String() string {
+	return q.src
+}
+
+// WithQuery only reports the events selected by q.
+func WithQuery(q *Query) Option {
+	return func(w *Watcher) {
+		w.query = q
+	}
+}
+
+type queryToken struct {
+	// kind is one of ( ) : ~ for punctuation, or w for words and quoted
+	// strings
+	kind byte
+	text string
+}
+
+type queryParser struct {
+	src    string
+	tokens []queryToken
+	pos    int
+}
+
+func (p *queryParser) errorf(format string, args ...interface{}) error {
+	return errors.Errorf("watch: invalid query %q: %v", p.src, errors.Errorf(format, args...))
+}
+
+func (p *queryParser) lex() error {
+	s := p.src
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(' || c == ')' || c == ':' || c == '~':
+			p.tokens = append(p.tokens, queryToken{kind: c, text: string(c)})
+			i++
+		case c == '"':
+			prefix, err := strconv.QuotedPrefix(s[i:])
+			if err != nil {
+				return p.errorf("unterminated string at %d", i)
+			}
+			text, err := strconv.Unquote(prefix)
+			if err != nil {
+				return p.errorf("%v", err)
+			}
+			p.tokens = append(p.tokens, queryToken{kind: 'w', text: text})
+			i += len(prefix)
+		default:
+			j := strings.IndexFunc(s[i:], func(r rune) bool {
+				return unicode.IsSpace(r) || strings.ContainsRune(`():~"`, r)
+			})
+			if j < 0 {
+				j = len(s) - i
+			}
+			p.tokens = append(p.tokens, queryToken{kind: 'w', text: s[i : i+j]})
+			i += j
+		}
+	}
+	return nil
+}
+
+// keyword reports whether the next token is the keyword kw.
+func (p *queryParser) keyword(kw string) bool {
+	if p.pos >= len(p.tokens) {
+		return false
+	}
+	t := p.tokens[p.pos]
+	// keywords are followed by whitespace or parentheses, not by an
+	// operator: "not:x" is a term
+	next := p.pos + 1
+	if next < len(p.tokens) && (p.tokens[next].kind == ':' || p.tokens[next].kind == '~') {
+		return false
+	}
+	if t.kind == 'w' && strings.EqualFold(t.text, kw) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *queryParser) or() (queryNode, error) {
+	var or queryOr
+	for {
+		n, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		or = append(or, n)
+		if !p.keyword("or") {
+			break
+		}
+	}
+	if len(or) == 1 {
+		return or[0], nil
+	}
+	return or, nil
+}
+
+func (p *queryParser) and() (queryNode, error) {
+	var and queryAnd
+	for {
+		n, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		and = append(and, n)
+		if p.keyword("and") {
+			continue
+		}
+		if p.pos >= len(p.tokens) || p.tokens[p.pos].kind == ')' {
+			break
+		}
+		if t := p.tokens[p.pos]; t.kind == 'w' && strings.EqualFold(t.text, "or") {
+			break
+		}
+	}
+	if len(and) == 1 {
+		return and[0], nil
+	}
+	return and, nil
+}
+
+func (p *queryParser) not() (queryNode, error) {
+	if p.keyword("not") {
+		n, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		return queryNot{n}, nil
+	}
+	if p.pos >= len(p.tokens) {
+		return nil, p.errorf("unexpected end")
+	}
+	t := p.tokens[p.pos]
+	if t.kind == '(' {
+		p.pos++
+		n, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != ')' {
+			return nil, p.errorf("missing )")
+		}
+		p.pos++
+		return n, nil
+	}
+	return p.term()
+}
+
+func (p *queryParser) term() (queryNode, error) {
+	if p.pos+3 > len(p.tokens) {
+		return nil, p.errorf("expected key:pattern at %q", p.tokens[p.pos].text)
+	}
+	key, op, value := p.tokens[p.pos], p.tokens[p.pos+1], p.tokens[p.pos+2]
+	if key.kind != 'w' || (op.kind != ':' && op.kind != '~') || value.kind != 'w' {
+		return nil, p.errorf("expected key:pattern at %q", key.text)
+	}
+	p.pos += 3
+	values, ok := queryKeys[strings.ToLower(key.text)]
+	if !ok {
+		return nil, p.errorf("unknown key %q", key.text)
+	}
+	t := &queryTerm{values: values}
+	if op.kind == '~' {
+		re, err := regexp.Compile(value.text)
+		if err != nil {
+			return nil, p.errorf("%v", err)
+		}
+		t.matches = re.MatchString
+		return t, nil
+	}
+	t.matches = glob(value.text).MatchString
+	return t, nil
+}
+
+// glob returns a case insensitive regular expression matching the glob
+// pattern, in which * matches any text and ? any character.
+func glob(pattern string) *regexp.Regexp {
+	re := regexp.QuoteMeta(pattern)
+	re = strings.Replace(re, `\*`, ".*", -1)
+	re = strings.Replace(re, `\?`, ".", -1)
+	return regexp.MustCompile("(?is)^" + re + "$")
+}
@@ -0,0 +1,61 @@
+package watch
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestQuery(t *testing.T) {
+	status := &Event{Type: RowPropertyChanged, PageID: rowID, Title: "Ship it", DatabaseID: dbID, EditedBy: pageID, Property: "Status", Old: "Todo", New: "Done"}
+	edit := &Event{Type: PageEdited, PageID: pageID, Title: "Q1/Q2 plans", EditedBy: rowID, BlockTypes: []string{"text", "to_do"}}
+	created := &Event{Type: PageCreated, PageID: subPageID, Title: "Sub"}
+	events := []*Event{status, edit, created}
+	tests := []struct {
+		query string
+		want  []*Event
+	}{
+		{"", events},
+		{"type:row.*", []*Event{status}},
+		{"TYPE:PAGE.EDITED", []*Event{edit}},
+		{"property:status new:done", []*Event{status}},
+		{"new:Todo", nil},
+		{"old:todo and new:done", []*Event{status}},
+		{"block:to_do", []*Event{edit}},
+		{"title:*plans", []*Event{edit}},
+		{`title:"q1/q2 *"`, []*Event{edit}},
+		{"title~^S", []*Event{status, created}},
+		{"not title~^S", []*Event{edit}},
+		{"type:page.created or block:to_do", []*Event{edit, created}},
+		{"(type:page.created or block:to_do) and not user:" + strings.Replace(rowID, "-", "", -1), []*Event{created}},
+		{"database:" + dbID + " or page:" + subPageID, []*Event{status, created}},
+		{"user:4b1e*", []*Event{status}},
+	}
+	for _, tt := range tests {
+		q, err := ParseQuery(tt.query)
+		if err != nil {
+			t.Errorf("ParseQuery(%q): %v", tt.query, err)
+			continue
+		}
+		var got []*Event
+		for _, e := range events {
+			if q.Match(e) {
+				got = append(got, e)
+			}
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%q matched %d events, want %d", tt.query, len(got), len(tt.want))
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%q matched %v, want %v", tt.query, got[i].Title, tt.want[i].Title)
+			}
+		}
+	}
+
+	for _, s := range []string{"type", "type:", "color:red", "(type:page.edited", "type:page.edited)", "title~(", `title:"open`, "and", "type:x or"} {
+		if _, err := ParseQuery(s); err == nil {
+			t.Errorf("ParseQuery(%q) succeeded, want error", s)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package watch
+
+import (
+	"database/sql"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Store persists the state of a Watcher, so that a restarted watcher
+// reports the changes made while it was down instead of starting over.
+type Store interface {
+	// Load returns the saved state, or nil if there is none.
+	Load() ([]byte, error)
+	Save(state []byte) error
+}
+
+// WithStore loads the state of the watcher from s before the first poll,
+// and saves it to s after every poll.
+func WithStore(s Store) Option {
+	return func(w *Watcher) {
+		w.store = s
+	}
+}
+
+// stateVersion is the version of the format of saved states.
+const stateVersion = 1
+
+type savedState struct {
+	Version int                   `json:"version"`
+	Time    time.Time             `json:"time"`
+	Pages   map[string]*pageState `json:"pages"`
+	Pending []*burst              `json:"pending,omitempty"`
+}
+
+func (w *Watcher) load() error {
+	b, err := w.store.Load()
+	if err != nil || b == nil {
+		return err
+	}
+	s := &savedState{}
+	if err := json.Unmarshal(b, s); err != nil {
+		return errors.Wrap(err, "reading watch state")
+	}
+	if s.Version != stateVersion {
+		return errors.Errorf("watch: unsupported state version %d", s.Version)
+	}
+	if s.Pages == nil {
+		s.Pages = make(map[string]*pageState)
+	}
+	w.state, w.pending = s.Pages, s.Pending
+	return nil
+}
+
+func (w *Watcher) save() error {
+	b, err := json.Marshal(&savedState{Version: stateVersion, Time: w.now().UTC(), Pages: w.state, Pending: w.pending})
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(w.store.Save(b), "saving watch state")
+}
+
+// FileStore is a Store that keeps the state in a file, with the given name.
+type FileStore string
+
+// Load implements Store.
+func (f FileStore) Load() ([]byte, error) {
+	b, err := ioutil.ReadFile(string(f))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return b, err
+}
+
+// Save writes the state through a temporary file, so that it's never left
+// half written.
+func (f FileStore) Save(state []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(string(f)), ".notion-watch")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(state); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), string(f))
+}
+
+// SQLStore is a Store that keeps the state in the row Key of a table
+// watch_state of DB, which is created if needed. The statements work with
+// SQLite (e.g. with the driver of github.com/mattn/go-sqlite3 or
+// modernc.org/sqlite) and PostgreSQL. Several watchers can share the table
+// with different keys.
+type SQLStore struct {
+	DB  *sql.DB
+	Key string
+}
+
+const createStateTable = `CREATE TABLE IF NOT EXISTS watch_state (
+	key TEXT PRIMARY KEY,
+	state TEXT NOT NULL,
+	updated TIMESTAMP NOT NULL
+)`
+
+// Load implements Store.
+func (s *SQLStore) Load() ([]byte, error) {
+	if _, err := s.DB.Exec(createStateTable); err != nil {
+		return nil, err
+	}
+	var state string
+	err := s.DB.QueryRow(`SELECT state FROM watch_state WHERE key = $1`, s.Key).Scan(&state)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []byte(state), nil
+}
+
+// Save implements Store.
+func (s *SQLStore) Save(state []byte) error {
+	if _, err := s.DB.Exec(createStateTable); err != nil {
+		return err
+	}
+	_, err := s.DB.Exec(`INSERT INTO watch_state (key, state, updated) VALUES ($1, $2, $3)
+		ON CONFLICT (key) DO UPDATE SET state = excluded.state, updated = excluded.updated`,
+		s.Key, string(state), time.Now().UTC())
+	return err
+}
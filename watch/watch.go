@@ -0,0 +1,499 @@
+// Package watch polls notion pages and databases and reports their changes
+// as events.
+package watch
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// EventType is the kind of change an Event describes.
+type EventType string
+
+// Event types. Edits to the properties of database rows are reported as
+// RowPropertyChanged events, one per property, other edits as PageEdited.
+const (
+	PageCreated        EventType = "page.created"
+	PageEdited         EventType = "page.edited"
+	PageDeleted        EventType = "page.deleted"
+	RowPropertyChanged EventType = "row.property_changed"
+)
+
+// Event is a change to a page or database row.
+type Event struct {
+	Type EventType `json:"type"`
+	// PageID and Title identify the page or row.
+	PageID string `json:"page_id"`
+	Title  string `json:"title,omitempty"`
+	// DatabaseID is the id of the database of rows.
+	DatabaseID string `json:"database_id,omitempty"`
+	// Version is the version of the page block.
+	Version int64 `json:"version,omitempty"`
+	// Time is the time of the last edit, or when the deletion was noticed.
+	Time time.Time `json:"time"`
+	// EditedBy is the id of the user who last edited the page.
+	EditedBy string `json:"edited_by,omitempty"`
+	// Property, Old and New describe the change of RowPropertyChanged events.
+	Property string `json:"property,omitempty"`
+	Old      string `json:"old,omitempty"`
+	New      string `json:"new,omitempty"`
+	// BlockTypes are the sorted types of the blocks added, edited or
+	// removed by PageEdited events, e.g. "to_do".
+	BlockTypes []string `json:"block_types,omitempty"`
+}
+
+// pageState is what a Watcher remembers of a page or row.
+type pageState struct {
+	Title    string `json:"title"`
+	Database string `json:"database,omitempty"`
+	// Parent is the id of the crawled page the page is a sub-page of.
+	Parent string `json:"parent,omitempty"`
+	// Unreadable is set once the page, or one of its ancestors, can no
+	// longer be read; the rest of the state is that of the last poll it
+	// could.
+	Unreadable bool `json:"unreadable,omitempty"`
+	// Fingerprint changes whenever the page or its content does.
+	Fingerprint string `json:"fingerprint"`
+	// Properties holds the values of the properties of rows by name.
+	Properties map[string]string `json:"properties,omitempty"`
+	// Blocks holds the blocks of the page, and the page itself, by id.
+	Blocks map[string]blockState `json:"blocks,omitempty"`
+
+	version  int64
+	edited   time.Time
+	editedBy string
+}
+
+type blockState struct {
+	Type    string `json:"type"`
+	Version int64  `json:"version"`
+}
+
+// Option configures a Watcher.
+type Option func(*Watcher)
+
+// WithPages watches the pages with the given ids and their sub-pages.
+func WithPages(ids ...string) Option {
+	return func(w *Watcher) {
+		w.pages = append(w.pages, ids...)
+	}
+}
+
+// WithDatabases watches the rows of the databases (collections) with the
+// given ids.
+func WithDatabases(ids ...string) Option {
+	return func(w *Watcher) {
+		w.databases = append(w.databases, ids...)
+	}
+}
+
+// WithFilter restricts the watched pages to those selected by f.
+func WithFilter(f *notion.Filter) Option {
+	return func(w *Watcher) {
+		w.filter = f
+	}
+}
+
+// DefaultInterval is how often Run polls by default.
+const DefaultInterval = time.Minute
+
+// WithInterval sets how often Run polls, DefaultInterval by default.
+func WithInterval(d time.Duration) Option {
+	return func(w *Watcher) {
+		w.interval = d
+	}
+}
+
+// WithErrorHandler sets the function Run reports polling and sink errors
+// to, instead of logging them with the log package.
+func WithErrorHandler(f func(err error)) Option {
+	return func(w *Watcher) {
+		w.onError = f
+	}
+}
+
+// maxBackoff is how many intervals Run waits at most after failures.
+const maxBackoff = 16
+
+// Watcher polls pages and databases for changes.
+type Watcher struct {
+	client    *notion.Client
+	pages     []string
+	databases []string
+	filter    *notion.Filter
+	interval  time.Duration
+	now       func() time.Time
+	store     Store
+	query     *Query
+	debounce  *Debounce
+	onError   func(err error)
+
+	// state of the pages as of the last poll, nil before the first one
+	state map[string]*pageState
+	// loaded is set once the state has been loaded from store
+	loaded bool
+	// pending holds the events being debounced
+	pending []*burst
+}
+
+// New returns a Watcher that uses c to poll.
+func New(c *notion.Client, opts ...Option) *Watcher {
+	w := &Watcher{client: c, interval: DefaultInterval, now: time.Now, onError: func(err error) {
+		log.Printf("watch: %v", err)
+	}}
+	for _, o := range opts {
+		o(w)
+	}
+	return w
+}
+
+// Sink receives the events of a Watcher, e.g. to publish them to a message
+// broker or webhook.
+type Sink interface {
+	Send(e *Event) error
+}
+
+// SinkFunc adapts a function to a Sink.
+type SinkFunc func(e *Event) error
+
+// Send calls f(e).
+func (f SinkFunc) Send(e *Event) error {
+	return f(e)
+}
+
+// Run polls every interval until ctx is done, sending every event to sink,
+// and returns ctx.Err(). Polling errors and errors returned by the sink are
+// reported to the watcher's error handler (see WithErrorHandler) and the
+// poll is retried, waiting twice as long after every consecutive failure,
+// up to 16 intervals.
+//
+// The state of the watcher only advances, and is only saved to its Store,
+// once all events of a poll have been sent. Events are thus delivered at
+// least once: if the sink fails or the process stops while sending, the
+// events of that poll are sent again by the next one.
+func (w *Watcher) Run(ctx context.Context, sink Sink) error {
+	backoff := w.interval
+	for {
+		wait := w.interval
+		if err := w.runOnce(sink); err != nil {
+			w.onError(err)
+			wait = backoff
+			if backoff < maxBackoff*w.interval {
+				backoff *= 2
+			}
+		} else {
+			backoff = w.interval
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// RunFunc is like Run with a function as sink.
+func (w *Watcher) RunFunc(ctx context.Context, f func(*Event) error) error {
+	return w.Run(ctx, SinkFunc(f))
+}
+
+// runOnce polls and sends the events of the poll to sink.
+func (w *Watcher) runOnce(sink Sink) error {
+	events, next, pending, err := w.poll()
+	if err != nil {
+		return err
+	}
+	for _, e := range events {
+		if err := sink.Send(e); err != nil {
+			return errors.Wrapf(err, "sending %v of %v", e.Type, e.PageID)
+		}
+	}
+	return w.commit(next, pending)
+}
+
+// Poll fetches the watched pages and rows and returns their changes since
+// the last poll, or since the state saved in the watcher's Store. Without
+// a previous state, the first poll only records the pages' state and
+// returns no events.
+func (w *Watcher) Poll() ([]*Event, error) {
+	events, next, pending, err := w.poll()
+	if err != nil {
+		return nil, err
+	}
+	return events, w.commit(next, pending)
+}
+
+// poll returns the events of a poll, the new state and the events still
+// being debounced, without making them the watcher's.
+func (w *Watcher) poll() ([]*Event, map[string]*pageState, []*burst, error) {
+	if w.store != nil && !w.loaded {
+		if err := w.load(); err != nil {
+			return nil, nil, nil, err
+		}
+		w.loaded = true
+	}
+	next := make(map[string]*pageState)
+	var order []string
+	add := func(id string, s *pageState) {
+		if _, ok := next[id]; !ok {
+			order = append(order, id)
+		}
+		next[id] = s
+	}
+	// skipped holds the pages and blocks that couldn't be read
+	skipped := make(map[string]bool)
+	for _, root := range w.pages {
+		r, err := w.client.CrawlWithOptions(root, notion.CrawlOptions{Filter: w.filter}, func(p *notion.Page, ancestors []string) error {
+			s := pageStateOf(p.Block)
+			if len(ancestors) > 0 {
+				s.Parent = ancestors[len(ancestors)-1]
+			}
+			add(p.ID, s)
+			return nil
+		})
+		if err != nil {
+			return nil, nil, nil, errors.Wrapf(err, "polling page %v", root)
+		}
+		for _, b := range r.Skipped {
+			skipped[b.ID] = true
+		}
+	}
+	for _, db := range w.databases {
+		if err := w.pollDatabase(db, add); err != nil {
+			return nil, nil, nil, errors.Wrapf(err, "polling database %v", db)
+		}
+	}
+	prev := w.state
+	if prev == nil {
+		return nil, next, w.pending, nil
+	}
+	var events []*Event
+	for _, id := range order {
+		events = append(events, w.changes(id, prev[id], next[id])...)
+	}
+	var deleted []string
+	for id, s := range prev {
+		if _, ok := next[id]; ok {
+			continue
+		}
+		if unreadable(id, prev, skipped) {
+			// not deleted, keep the last state that could be read
+			if !s.Unreadable && skipped[id] {
+				w.onError(errors.Errorf("page %v (%q) can't be read anymore, ignoring its changes", id, s.Title))
+			}
+			kept := *s
+			kept.Unreadable = true
+			next[id] = &kept
+			continue
+		}
+		deleted = append(deleted, id)
+	}
+	sort.Strings(deleted)
+	for _, id := range deleted {
+		s := prev[id]
+		events = append(events, &Event{Type: PageDeleted, PageID: id, Title: s.Title, DatabaseID: s.Database, Time: w.now()})
+	}
+	pending := w.pending
+	if w.debounce != nil {
+		events, pending = w.debounce.coalesce(pending, events, w.now())
+	}
+	if w.query != nil {
+		selected := events[:0]
+		for _, e := range events {
+			if w.query.Match(e) {
+				selected = append(selected, e)
+			}
+		}
+		events = selected
+	}
+	return events, next, pending, nil
+}
+
+// unreadable reports whether the page id, or one of its ancestors, was
+// skipped as it couldn't be read.
+func unreadable(id string, state map[string]*pageState, skipped map[string]bool) bool {
+	for i := 0; id != "" && i <= len(state); i++ {
+		if skipped[id] {
+			return true
+		}
+		s := state[id]
+		if s == nil {
+			return false
+		}
+		id = s.Parent
+	}
+	return false
+}
+
+// commit makes next and pending the state of the watcher and saves it.
+func (w *Watcher) commit(next map[string]*pageState, pending []*burst) error {
+	w.state, w.pending = next, pending
+	if w.store == nil {
+		return nil
+	}
+	return w.save()
+}
+
+func (w *Watcher) pollDatabase(id string, add func(string, *pageState)) error {
+	collection, err := w.client.GetCollection(id)
+	if err != nil {
+		return err
+	}
+	rows, err := w.client.QueryCollection(id, "")
+	if err != nil {
+		return err
+	}
+	title := "title"
+	for _, col := range collection.CollectionSchema {
+		if col.Type == notiontypes.ColumnTypeTitle {
+			title = col.Name
+		}
+	}
+	for _, row := range rows {
+		s := pageStateOf(row)
+		s.Database = id
+		s.Properties = map[string]string{title: row.Title}
+		for _, p := range collection.PageProperties(row) {
+			s.Properties[p.Name] = p.Text()
+		}
+		add(row.ID, s)
+	}
+	return nil
+}
+
+// changes returns the events of the change of a page from prev to next.
+func (w *Watcher) changes(id string, prev, next *pageState) []*Event {
+	event := func(t EventType) *Event {
+		return &Event{Type: t, PageID: id, Title: next.Title, DatabaseID: next.Database, Version: next.version, Time: next.edited, EditedBy: next.editedBy}
+	}
+	if prev == nil {
+		return []*Event{event(PageCreated)}
+	}
+	if prev.Fingerprint == next.Fingerprint {
+		return nil
+	}
+	var names []string
+	for name := range next.Properties {
+		if next.Properties[name] != prev.Properties[name] {
+			names = append(names, name)
+		}
+	}
+	for name := range prev.Properties {
+		if _, ok := next.Properties[name]; !ok && prev.Properties[name] != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		e := event(PageEdited)
+		e.BlockTypes = changedTypes(prev.Blocks, next.Blocks)
+		return []*Event{e}
+	}
+	sort.Strings(names)
+	var events []*Event
+	for _, name := range names {
+		e := event(RowPropertyChanged)
+		e.Property, e.Old, e.New = name, prev.Properties[name], next.Properties[name]
+		events = append(events, e)
+	}
+	return events
+}
+
+// changedTypes returns the types of the blocks that differ between prev and
+// next.
+func changedTypes(prev, next map[string]blockState) []string {
+	if prev == nil {
+		// saved by a version that didn't record blocks
+		return nil
+	}
+	seen := make(map[string]bool)
+	for id, b := range next {
+		if prev[id] != b {
+			seen[b.Type] = true
+		}
+	}
+	for id, b := range prev {
+		if _, ok := next[id]; !ok {
+			seen[b.Type] = true
+		}
+	}
+	types := make([]string, 0, len(seen))
+	for t := range seen {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// contentOf returns the content of b in order, with blocks holding only
+// the id of the blocks missing from it as they can't be read, so that pages
+// whose sub-pages can no longer be read don't appear edited.
+func contentOf(b *notiontypes.Block) []*notiontypes.Block {
+	if len(b.MissingIDs) == 0 {
+		return b.Content
+	}
+	missing := make(map[string]bool, len(b.MissingIDs))
+	for _, id := range b.MissingIDs {
+		missing[id] = true
+	}
+	byID := make(map[string]*notiontypes.Block, len(b.Content))
+	for _, c := range b.Content {
+		byID[c.ID] = c
+	}
+	content := make([]*notiontypes.Block, 0, len(b.Content)+len(b.MissingIDs))
+	for _, id := range b.ContentIDs {
+		switch {
+		case byID[id] != nil:
+			content = append(content, byID[id])
+			delete(byID, id)
+		case missing[id]:
+			content = append(content, &notiontypes.Block{ID: id})
+			delete(missing, id)
+		}
+	}
+	for _, c := range b.Content {
+		if byID[c.ID] != nil {
+			content = append(content, c)
+		}
+	}
+	for _, id := range b.MissingIDs {
+		if missing[id] {
+			content = append(content, &notiontypes.Block{ID: id})
+		}
+	}
+	return content
+}
+
+// pageStateOf returns the state of the page b, whose content (but not that
+// of its sub-pages) is resolved.
+func pageStateOf(b *notiontypes.Block) *pageState {
+	s := &pageState{Title: b.Title, Blocks: make(map[string]blockState), version: b.Version}
+	h := sha256.New()
+	var walk func(b *notiontypes.Block)
+	walk = func(b *notiontypes.Block) {
+		h.Write([]byte(b.ID + "@" + strconv.FormatInt(b.Version, 10) + "\n"))
+		s.Blocks[b.ID] = blockState{Type: b.Type, Version: b.Version}
+		if t := b.UpdatedOn(); t.After(s.edited) {
+			s.edited, s.editedBy = t, b.LastEditedBy
+		}
+		for _, c := range contentOf(b) {
+			if c.IsPage() || c.Type == "" {
+				// only the link to the sub-page is part of this page
+				h.Write([]byte(c.ID + "\n"))
+				continue
+			}
+			walk(c)
+		}
+	}
+	walk(b)
+	s.Fingerprint = hex.EncodeToString(h.Sum(nil))
+	return s
+}
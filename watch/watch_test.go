@@ -0,0 +1,204 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tmc/notion/notiontest"
+	"github.com/tmc/notion/notiontypes"
+)
+
+const (
+	pageID    = "4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+	textID    = "5b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+	subPageID = "6b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+	dbID      = "7b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+	rowID     = "8b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+	row2ID    = "9b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+)
+
+func page(version int64, content ...*notiontypes.Block) *notiontypes.Block {
+	return &notiontypes.Block{
+		ID: pageID, Type: notiontypes.BlockPage, Version: 1,
+		Properties: map[string]interface{}{"title": [][]string{{"Plans"}}},
+		Content:    append([]*notiontypes.Block{{ID: textID, Type: notiontypes.BlockText, Version: version}}, content...),
+	}
+}
+
+func row(id, status string, version int64) *notiontypes.Block {
+	return &notiontypes.Block{
+		ID: id, Type: notiontypes.BlockPage, Version: version, ParentID: dbID, ParentTable: notiontypes.TableCollection,
+		Properties: map[string]interface{}{"title": [][]string{{"Task " + id[:1]}}, "st": [][]string{{status}}},
+	}
+}
+
+func summary(events []*Event) []string {
+	var res []string
+	for _, e := range events {
+		s := string(e.Type) + " " + e.Title
+		if e.Property != "" {
+			s += " " + e.Property + ": " + e.Old + " -> " + e.New
+		}
+		res = append(res, s)
+	}
+	return res
+}
+
+func TestPoll(t *testing.T) {
+	srv := notiontest.NewServer()
+	defer srv.Close()
+	srv.AddRecord(notiontypes.TableCollection, dbID, map[string]interface{}{
+		"schema": map[string]interface{}{
+			"title": map[string]string{"name": "Name", "type": "title"},
+			"st":    map[string]string{"name": "Status", "type": "select"},
+		},
+	})
+	srv.AddBlock(page(1))
+	srv.AddBlock(row(rowID, "Todo", 1))
+	w := New(srv.Client(), WithPages(pageID), WithDatabases(dbID))
+	if events, err := w.Poll(); err != nil || events != nil {
+		t.Fatalf("first poll returned %v, %v", events, err)
+	}
+
+	srv.AddBlock(page(2, &notiontypes.Block{ID: subPageID, Type: notiontypes.BlockPage, Properties: map[string]interface{}{"title": [][]string{{"Sub"}}}}))
+	srv.AddBlock(row(rowID, "Done", 2))
+	srv.AddBlock(row(row2ID, "Todo", 1))
+	events, err := w.Poll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"page.edited Plans",
+		"page.created Sub",
+		"row.property_changed Task 8 Status: Todo -> Done",
+		"page.created Task 9",
+	}
+	if got := summary(events); !reflect.DeepEqual(got, want) {
+		t.Errorf("got events %q, want %q", got, want)
+	}
+	if got := events[0].BlockTypes; !reflect.DeepEqual(got, []string{notiontypes.BlockText}) {
+		t.Errorf("got changed block types %q, want text", got)
+	}
+
+	srv.AddRecord(notiontypes.TableBlock, row2ID, map[string]interface{}{"alive": false, "parent_id": dbID, "parent_table": notiontypes.TableCollection})
+	events, err = w.Poll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := summary(events); !reflect.DeepEqual(got, []string{"page.deleted Task 9"}) {
+		t.Errorf("got events %q", got)
+	}
+}
+
+func TestPollUnreadable(t *testing.T) {
+	const subSubPageID = "ab1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+	srv := notiontest.NewServer()
+	defer srv.Close()
+	srv.AddBlock(page(1, &notiontypes.Block{
+		ID: subPageID, Type: notiontypes.BlockPage, Properties: map[string]interface{}{"title": [][]string{{"Sub"}}},
+		Content: []*notiontypes.Block{{ID: subSubPageID, Type: notiontypes.BlockPage, Properties: map[string]interface{}{"title": [][]string{{"Sub sub"}}}}},
+	}))
+	var errs []error
+	w := New(srv.Client(), WithPages(pageID), WithErrorHandler(func(err error) { errs = append(errs, err) }))
+	if _, err := w.Poll(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the sub-page, and so its own sub-page, are no longer shared
+	srv.SetRole(subPageID, notiontypes.RoleNone)
+	for i := 0; i < 2; i++ {
+		events, err := w.Poll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(events) != 0 {
+			t.Errorf("got events %q for unreadable pages", summary(events))
+		}
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), subPageID) {
+		t.Errorf("got errors %v, want one about %v", errs, subPageID)
+	}
+}
+
+func TestStore(t *testing.T) {
+	srv := notiontest.NewServer()
+	defer srv.Close()
+	srv.AddBlock(page(1))
+	store := FileStore(filepath.Join(t.TempDir(), "state.json"))
+	w := New(srv.Client(), WithPages(pageID), WithStore(store))
+	if _, err := w.Poll(); err != nil {
+		t.Fatal(err)
+	}
+
+	// a change made while no watcher runs is reported by the next one
+	srv.AddBlock(page(2))
+	ctx, cancel := context.WithCancel(context.Background())
+	failing := SinkFunc(func(e *Event) error { return errors.New("sink down") })
+	var errs []error
+	w = New(srv.Client(), WithPages(pageID), WithStore(store), WithErrorHandler(func(err error) {
+		errs = append(errs, err)
+		cancel()
+	}))
+	if err := w.Run(ctx, failing); err != context.Canceled {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 || !strings.HasSuffix(errs[0].Error(), "sink down") {
+		t.Fatalf("got errors %v, want the sink's", errs)
+	}
+
+	// and not lost when it couldn't be sent
+	var got []*Event
+	ctx, cancel = context.WithCancel(context.Background())
+	record := func(e *Event) error {
+		got = append(got, e)
+		cancel()
+		return nil
+	}
+	w = New(srv.Client(), WithPages(pageID), WithStore(store))
+	if err := w.RunFunc(ctx, record); err != context.Canceled {
+		t.Fatal(err)
+	}
+	if s := summary(got); !reflect.DeepEqual(s, []string{"page.edited Plans"}) {
+		t.Errorf("got events %q", s)
+	}
+
+	w = New(srv.Client(), WithPages(pageID), WithStore(store))
+	if events, err := w.Poll(); err != nil || events != nil {
+		t.Errorf("got %v, %v after restart, want no events", events, err)
+	}
+}
+
+func TestRunRetries(t *testing.T) {
+	srv := notiontest.NewServer()
+	defer srv.Close()
+	srv.AddBlock(page(1))
+	w := New(srv.Client(), WithPages(pageID), WithInterval(time.Millisecond), WithErrorHandler(func(err error) {}))
+	if _, err := w.Poll(); err != nil {
+		t.Fatal(err)
+	}
+	srv.AddBlock(page(2))
+
+	var got []*Event
+	failures := 3
+	ctx, cancel := context.WithCancel(context.Background())
+	sink := SinkFunc(func(e *Event) error {
+		if failures > 0 {
+			failures--
+			return errors.New("sink down")
+		}
+		got = append(got, e)
+		cancel()
+		return nil
+	})
+	if err := w.Run(ctx, sink); err != context.Canceled {
+		t.Fatal(err)
+	}
+	if s := summary(got); !reflect.DeepEqual(s, []string{"page.edited Plans"}) {
+		t.Errorf("got events %q after retrying", s)
+	}
+}
@@ -0,0 +1,243 @@
+// Package webhook connects notion to webhooks of other services.
+package webhook
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/notiontypes"
+	"github.com/tmc/notion/query"
+)
+
+// maxPayloadSize limits the size of incoming payloads.
+const maxPayloadSize = 1 << 20
+
+// Mapping maps the JSON payloads of an incoming webhook to rows of a
+// database.
+type Mapping struct {
+	// Path is the URL path the webhook posts to, e.g. /stripe.
+	Path string `json:"path"`
+	// Database is the id of the database (collection) of the rows.
+	Database string `json:"database"`
+	// Properties maps property names to the paths of their values in
+	// payloads, e.g. "data.object.id" or "items.0.name". See Lookup.
+	Properties map[string]string `json:"properties"`
+	// Key, if set, names the property that identifies rows: payloads
+	// update the row with the same value of Key, if there is one, instead
+	// of creating a new row.
+	Key string `json:"key,omitempty"`
+	// Token, if set, must be passed in the token query parameter or as a
+	// bearer token in the Authorization header.
+	Token string `json:"token,omitempty"`
+}
+
+// Inbound is an http.Handler that creates or updates database rows for
+// the webhooks posted to the paths of its mappings.
+type Inbound struct {
+	client   *notion.Client
+	mappings map[string]*Mapping
+	// serializes requests, so that concurrent payloads with the same key
+	// don't create two rows
+	mu sync.Mutex
+}
+
+// NewInbound returns an Inbound for the given mappings.
+func NewInbound(c *notion.Client, mappings ...*Mapping) (*Inbound, error) {
+	h := &Inbound{client: c, mappings: make(map[string]*Mapping)}
+	for _, m := range mappings {
+		if _, ok := h.mappings[m.Path]; ok {
+			return nil, errors.Errorf("webhook: duplicate path %q", m.Path)
+		}
+		if m.Database == "" || len(m.Properties) == 0 {
+			return nil, errors.Errorf("webhook: mapping of %q needs a database and properties", m.Path)
+		}
+		if _, ok := m.Properties[m.Key]; m.Key != "" && !ok {
+			return nil, errors.Errorf("webhook: key %q of %q is not a mapped property", m.Key, m.Path)
+		}
+		h.mappings[m.Path] = m
+	}
+	return h, nil
+}
+
+type inboundResponse struct {
+	ID      string `json:"id"`
+	Created bool   `json:"created"`
+}
+
+func (h *Inbound) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m, ok := h.mappings[r.URL.Path]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if m.Token != "" && !equal(r.URL.Query().Get("token"), m.Token) && !equal(r.Header.Get("Authorization"), "Bearer "+m.Token) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxPayloadSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	var payload interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		http.Error(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	values := make(map[string]string, len(m.Properties))
+	for name, path := range m.Properties {
+		if v, ok := Lookup(payload, path); ok {
+			values[name] = Text(v)
+		}
+	}
+	h.mu.Lock()
+	resp, err := h.apply(m, values)
+	h.mu.Unlock()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (h *Inbound) apply(m *Mapping, values map[string]string) (*inboundResponse, error) {
+	if key := values[m.Key]; m.Key != "" && key != "" {
+		id, err := h.findRow(m.Database, m.Key, key)
+		if err != nil {
+			return nil, err
+		}
+		if id != "" {
+			return &inboundResponse{ID: id}, h.client.UpdateRow(m.Database, id, values)
+		}
+	}
+	id, err := h.client.CreateRow(m.Database, values)
+	return &inboundResponse{ID: id, Created: true}, err
+}
+
+// findRow returns the id of the row of database whose property name has
+// the given value, or the empty string if there is none. Text and select
+// properties are filtered on notion's side, other rows are all loaded.
+func (h *Inbound) findRow(database, name, value string) (string, error) {
+	collection, err := h.client.GetCollection(database)
+	if err != nil {
+		return "", err
+	}
+	opts := notion.QueryOptions{Properties: []string{name}}
+	isTitle := false
+	for _, col := range collection.CollectionSchema {
+		if !strings.EqualFold(col.Name, name) {
+			continue
+		}
+		isTitle = col.Type == notiontypes.ColumnTypeTitle
+		if !filterable(col.Type) {
+			break
+		}
+		// notion may match case-insensitively, rows are checked below
+		filter, err := query.Where(col.Name).Is(value).Compile(collection)
+		if err != nil {
+			return "", err
+		}
+		opts.Query = &notiontypes.CollectionQuery{Filter: filter}
+		break
+	}
+	rows, err := h.client.QueryCollectionWithOptions(database, "", opts)
+	if err != nil {
+		return "", err
+	}
+	for _, row := range rows {
+		if isTitle {
+			if row.Title == value {
+				return row.ID, nil
+			}
+			continue
+		}
+		for _, p := range collection.PageProperties(row) {
+			if strings.EqualFold(p.Name, name) && p.Text() == value {
+				return row.ID, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// filterable reports whether rows can be queried for the text of their
+// properties of type typ.
+func filterable(typ string) bool {
+	switch typ {
+	case notiontypes.ColumnTypeTitle, notiontypes.ColumnTypeText, notiontypes.ColumnTypeURL,
+		notiontypes.ColumnTypeEmail, notiontypes.ColumnTypePhoneNumber, notiontypes.ColumnTypeSelect:
+		return true
+	}
+	return false
+}
+
+// equal compares the secrets a and b in constant time.
+func equal(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// Lookup returns the value at path in the JSON value v, as decoded by
+// encoding/json. Paths are dot-separated object keys and array indexes,
+// optionally starting with "$.", e.g. "data.items.0.name".
+func Lookup(v interface{}, path string) (interface{}, bool) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if path == "" {
+		return v, true
+	}
+	for _, key := range strings.Split(path, ".") {
+		switch node := v.(type) {
+		case map[string]interface{}:
+			var ok bool
+			if v, ok = node[key]; !ok {
+				return nil, false
+			}
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			v = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return v, v != nil
+}
+
+// Text formats the JSON value v as the text of a property value: booleans
+// as Yes or No, arrays as comma separated lists and objects as JSON.
+func Text(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		if v {
+			return "Yes"
+		}
+		return "No"
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = Text(item)
+		}
+		return strings.Join(items, ",")
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
@@ -0,0 +1,78 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tmc/notion/notiontest"
+	"github.com/tmc/notion/notiontypes"
+)
+
+func TestInbound(t *testing.T) {
+	srv := notiontest.NewServer()
+	defer srv.Close()
+	const dbID = "4b1e8f5c-9a0e-4b6e-8d3c-1f2a3b4c5d6e"
+	srv.AddRecord(notiontypes.TableCollection, dbID, map[string]interface{}{
+		"schema": map[string]interface{}{
+			"title": map[string]string{"name": "Order", "type": "title"},
+			"amt":   map[string]string{"name": "Amount", "type": "number"},
+			"paid":  map[string]string{"name": "Paid", "type": "checkbox"},
+			"on":    map[string]string{"name": "Date", "type": "date"},
+		},
+	})
+	h, err := NewInbound(srv.Client(), &Mapping{
+		Path:     "/orders",
+		Database: dbID,
+		Key:      "Order",
+		Token:    "secret",
+		Properties: map[string]string{
+			"Order":  "$.data.id",
+			"Amount": "data.amount",
+			"Paid":   "data.paid",
+			"Date":   "data.items.0.date",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	post := func(body string) (*inboundResponse, int) {
+		req := httptest.NewRequest("POST", "/orders?token=secret", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		resp := &inboundResponse{}
+		json.Unmarshal(w.Body.Bytes(), resp)
+		return resp, w.Code
+	}
+
+	created, code := post(`{"data": {"id": "o-1", "amount": 12.5, "paid": false, "items": [{"date": "2019-03-01"}]}}`)
+	if code != http.StatusOK || !created.Created {
+		t.Fatalf("got %d %+v", code, created)
+	}
+	updated, code := post(`{"data": {"id": "o-1", "paid": true}}`)
+	if code != http.StatusOK || updated.Created || updated.ID != created.ID {
+		t.Fatalf("got %d %+v, want update of %v", code, updated, created.ID)
+	}
+	row := srv.Block(created.ID)
+	if row == nil || row.Title != "o-1" || row.ParentID != dbID {
+		t.Fatalf("got row %+v", row)
+	}
+	want := map[string]string{"amt": "12.5", "paid": "Yes"}
+	for id, text := range want {
+		if v, _ := json.Marshal(row.Properties[id]); string(v) != `[["`+text+`"]]` {
+			t.Errorf("property %v is %s, want %v", id, v, text)
+		}
+	}
+	if v, _ := json.Marshal(row.Properties["on"]); !strings.Contains(string(v), `"start_date":"2019-03-01"`) {
+		t.Errorf("date property is %s", v)
+	}
+
+	req := httptest.NewRequest("POST", "/orders", strings.NewReader(`{}`))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("got status %d without token", w.Code)
+	}
+}
@@ -0,0 +1,120 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/watch"
+)
+
+// Headers of the requests of Outbound.
+const (
+	SignatureHeader = "X-Notion-Signature"
+	EventHeader     = "X-Notion-Event"
+	DeliveryHeader  = "X-Notion-Delivery"
+)
+
+// Outbound posts watch events as JSON to webhook URLs.
+//
+// If Secret is set, requests are signed like GitHub's webhooks: the
+// SignatureHeader holds "sha256=" followed by the hex encoded HMAC-SHA256 of
+// the body. See Verify.
+type Outbound struct {
+	URLs   []string
+	Secret []byte
+	// Client defaults to a client timing out after 30 seconds.
+	Client *http.Client
+	// Retries is the number of times failed deliveries are retried, with
+	// exponential backoff starting at Backoff (one second by default).
+	// Network errors and 5xx and 429 responses are retried.
+	Retries int
+	Backoff time.Duration
+}
+
+var defaultClient = &http.Client{Timeout: 30 * time.Second}
+
+// Send delivers e to all URLs, and returns an error if any delivery
+// failed for good.
+func (o *Outbound) Send(e *watch.Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	delivery := notion.NewBlockID()
+	var failed error
+	for _, url := range o.URLs {
+		if err := o.deliver(url, string(e.Type), delivery, body); err != nil && failed == nil {
+			failed = err
+		}
+	}
+	return failed
+}
+
+func (o *Outbound) deliver(url, event, delivery string, body []byte) error {
+	backoff := o.Backoff
+	if backoff == 0 {
+		backoff = time.Second
+	}
+	for attempt := 0; ; attempt++ {
+		retry, err := o.post(url, event, delivery, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= o.Retries {
+			return fmt.Errorf("webhook: delivering %v to %v: %v", event, url, err)
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// post posts body to url and reports whether a failure may be retried.
+func (o *Outbound) post(url, event, delivery string, body []byte) (bool, error) {
+	client := o.Client
+	if client == nil {
+		client = defaultClient
+	}
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, event)
+	req.Header.Set(DeliveryHeader, delivery)
+	if len(o.Secret) > 0 {
+		req.Header.Set(SignatureHeader, Sign(o.Secret, body))
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode/100 == 2 {
+		return false, nil
+	}
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("%v", resp.Status)
+}
+
+// Sign returns the signature of body with secret, as sent in the
+// SignatureHeader.
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is the signature of body with secret.
+func Verify(secret, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
@@ -0,0 +1,55 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tmc/notion/watch"
+)
+
+func TestOutbound(t *testing.T) {
+	secret := []byte("s3cret")
+	var (
+		mu       sync.Mutex
+		attempts int
+		got      *watch.Event
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if attempts == 1 {
+			http.Error(w, "try again", http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		if !Verify(secret, body, r.Header.Get(SignatureHeader)) {
+			http.Error(w, "bad signature", http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get(EventHeader) != string(watch.PageEdited) || r.Header.Get(DeliveryHeader) == "" {
+			t.Errorf("got headers %v", r.Header)
+		}
+		got = &watch.Event{}
+		json.Unmarshal(body, got)
+	}))
+	defer srv.Close()
+
+	o := &Outbound{URLs: []string{srv.URL}, Secret: secret, Retries: 2, Backoff: time.Millisecond}
+	if err := o.Send(&watch.Event{Type: watch.PageEdited, PageID: "page"}); err != nil {
+		t.Fatal(err)
+	}
+	if attempts != 2 || got == nil || got.PageID != "page" {
+		t.Errorf("got %d attempts, event %+v", attempts, got)
+	}
+
+	o.Secret = []byte("wrong")
+	if err := o.Send(&watch.Event{Type: watch.PageEdited, PageID: "page"}); err == nil {
+		t.Error("expected error for rejected delivery")
+	}
+}
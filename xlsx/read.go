@@ -0,0 +1,244 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Sheet is a sheet of a workbook read by Read.
+type Sheet struct {
+	Name string
+	// Rows are the non-empty rows of the sheet, with their cells as text:
+	// numbers in decimal, booleans as true or false and dates as
+	// 2006-01-02, followed by a 15:04 time if they have one. Rows are as
+	// long as their last non-empty cell.
+	Rows [][]string
+}
+
+// Read reads the sheets of the workbook r of the given size, in order.
+func Read(r io.ReaderAt, size int64) ([]*Sheet, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, errors.Wrap(err, "xlsx")
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[strings.TrimPrefix(f.Name, "/")] = f
+	}
+	var workbook struct {
+		Pr struct {
+			Date1904 bool `xml:"date1904,attr"`
+		} `xml:"workbookPr"`
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+			ID   string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := decode(files, "xl/workbook.xml", &workbook); err != nil {
+		return nil, err
+	}
+	var rels struct {
+		Rels []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := decode(files, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return nil, err
+	}
+	targets := map[string]string{}
+	for _, rel := range rels.Rels {
+		if strings.HasPrefix(rel.Target, "/") {
+			targets[rel.ID] = strings.TrimPrefix(rel.Target, "/")
+		} else {
+			targets[rel.ID] = path.Join("xl", rel.Target)
+		}
+	}
+	var strs []string
+	if files["xl/sharedStrings.xml"] != nil {
+		var sst struct {
+			Items []richText `xml:"si"`
+		}
+		if err := decode(files, "xl/sharedStrings.xml", &sst); err != nil {
+			return nil, err
+		}
+		for _, si := range sst.Items {
+			strs = append(strs, si.String())
+		}
+	}
+	dates := map[int]bool{}
+	if files["xl/styles.xml"] != nil {
+		if dates, err = dateStyles(files); err != nil {
+			return nil, err
+		}
+	}
+	base := epoch
+	if workbook.Pr.Date1904 {
+		base = time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	var sheets []*Sheet
+	for _, s := range workbook.Sheets {
+		var ws struct {
+			Rows []struct {
+				Cells []struct {
+					Ref    string   `xml:"r,attr"`
+					Type   string   `xml:"t,attr"`
+					Style  int      `xml:"s,attr"`
+					Value  string   `xml:"v"`
+					Inline richText `xml:"is"`
+				} `xml:"c"`
+			} `xml:"sheetData>row"`
+		}
+		if err := decode(files, targets[s.ID], &ws); err != nil {
+			return nil, errors.Wrapf(err, "sheet %q", s.Name)
+		}
+		sheet := &Sheet{Name: s.Name}
+		for _, row := range ws.Rows {
+			var cells []string
+			for i, c := range row.Cells {
+				col := i
+				if c.Ref != "" {
+					if col, err = refColumn(c.Ref); err != nil {
+						return nil, errors.Wrapf(err, "sheet %q", s.Name)
+					}
+				}
+				var v string
+				switch c.Type {
+				case "s":
+					n, err := strconv.Atoi(c.Value)
+					if err != nil || n < 0 || n >= len(strs) {
+						return nil, errors.Errorf("xlsx: sheet %q: cell %v: invalid shared string %q", s.Name, c.Ref, c.Value)
+					}
+					v = strs[n]
+				case "inlineStr":
+					v = c.Inline.String()
+				case "b":
+					v = strconv.FormatBool(c.Value == "1")
+				case "", "n":
+					v = c.Value
+					if n, err := strconv.ParseFloat(c.Value, 64); err == nil && dates[c.Style] {
+						v = formatSerial(base, n)
+					}
+				default:
+					v = c.Value
+				}
+				if v == "" {
+					continue
+				}
+				for len(cells) <= col {
+					cells = append(cells, "")
+				}
+				cells[col] = v
+			}
+			if len(cells) > 0 {
+				sheet.Rows = append(sheet.Rows, cells)
+			}
+		}
+		sheets = append(sheets, sheet)
+	}
+	return sheets, nil
+}
+
+// richText is a string item, either plain or made of formatted runs.
+type richText struct {
+	Text string   `xml:"t"`
+	Runs []string `xml:"r>t"`
+}
+
+func (t richText) String() string {
+	return t.Text + strings.Join(t.Runs, "")
+}
+
+func decode(files map[string]*zip.File, name string, v interface{}) error {
+	f := files[name]
+	if f == nil {
+		return errors.Errorf("xlsx: missing %v", name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return errors.Wrap(err, "xlsx")
+	}
+	defer rc.Close()
+	return errors.Wrapf(xml.NewDecoder(rc).Decode(v), "xlsx: %v", name)
+}
+
+// dateStyles returns the indexes of the cell formats that show numbers as
+// dates.
+func dateStyles(files map[string]*zip.File) (map[int]bool, error) {
+	var styles struct {
+		NumFmts []struct {
+			ID   int    `xml:"numFmtId,attr"`
+			Code string `xml:"formatCode,attr"`
+		} `xml:"numFmts>numFmt"`
+		Xfs []struct {
+			NumFmtID int `xml:"numFmtId,attr"`
+		} `xml:"cellXfs>xf"`
+	}
+	if err := decode(files, "xl/styles.xml", &styles); err != nil {
+		return nil, err
+	}
+	custom := map[int]bool{}
+	for _, f := range styles.NumFmts {
+		custom[f.ID] = isDateFormat(f.Code)
+	}
+	dates := map[int]bool{}
+	for i, xf := range styles.Xfs {
+		id := xf.NumFmtID
+		if id >= 14 && id <= 22 || id >= 45 && id <= 47 || custom[id] {
+			dates[i] = true
+		}
+	}
+	return dates, nil
+}
+
+// isDateFormat reports whether the number format code shows dates or
+// times, ignoring quoted literals, escaped characters and [bracketed]
+// colors and conditions.
+func isDateFormat(code string) bool {
+	quoted, bracket := false, false
+	for i := 0; i < len(code); i++ {
+		switch ch := code[i]; {
+		case ch == '"':
+			quoted = !quoted
+		case quoted:
+		case ch == '\\':
+			i++
+		case ch == '[':
+			bracket = true
+		case ch == ']':
+			bracket = false
+		case bracket:
+		case strings.IndexByte("ymdhsYMDHS", ch) >= 0:
+			return true
+		}
+	}
+	return false
+}
+
+func formatSerial(base time.Time, n float64) string {
+	t := base.Add(time.Duration(n*24*float64(time.Hour) + 0.5*float64(time.Second))).Truncate(time.Second)
+	if n == float64(int64(n)) {
+		return t.Format("2006-01-02")
+	}
+	return t.Format("2006-01-02 15:04")
+}
+
+// refColumn returns the zero-based column of the A1 reference ref.
+func refColumn(ref string) (int, error) {
+	col := 0
+	i := 0
+	for ; i < len(ref) && ref[i] >= 'A' && ref[i] <= 'Z'; i++ {
+		col = col*26 + int(ref[i]-'A'+1)
+	}
+	if i == 0 {
+		return 0, errors.Errorf("xlsx: invalid cell reference %q", ref)
+	}
+	return col - 1, nil
+}
@@ -0,0 +1,251 @@
+// Package xlsx reads and writes the cells of Excel workbooks (.xlsx, Office
+// Open XML spreadsheets) without formulas, charts or most formatting: only
+// what's needed to exchange tables of numbers, booleans, dates and text.
+package xlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Style indexes in styles.xml.
+const (
+	styleDefault = iota
+	styleHeader
+	styleDate
+	styleDateTime
+)
+
+// MaxSheetName is the longest sheet name Excel accepts.
+const MaxSheetName = 31
+
+// epoch is day 0 of the 1900 date system, accounting for the 29 February
+// 1900 that Excel counts but that didn't exist.
+var epoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// Writer writes a workbook, a sheet at a time and a row at a time.
+type Writer struct {
+	zw     *zip.Writer
+	sheet  io.Writer
+	names  []string
+	row    int
+	closed bool
+}
+
+// NewWriter returns a Writer writing a workbook to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{zw: zip.NewWriter(w)}
+}
+
+// AddSheet starts a sheet, ending the previous one. If header isn't empty
+// it is written as the first row, in bold on a gray background, and stays
+// visible when scrolling.
+func (w *Writer) AddSheet(name string, header []string) error {
+	if err := w.endSheet(); err != nil {
+		return err
+	}
+	name = SheetName(name)
+	for _, n := range w.names {
+		if strings.EqualFold(n, name) {
+			return errors.Errorf("xlsx: duplicate sheet %q", name)
+		}
+	}
+	w.names = append(w.names, name)
+	f, err := w.zw.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", len(w.names)))
+	if err != nil {
+		return err
+	}
+	w.sheet, w.row = f, 0
+	io.WriteString(f, xml.Header+`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	if len(header) > 0 {
+		io.WriteString(f, `<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews><cols>`)
+		for i, h := range header {
+			width := math.Max(10, float64(len([]rune(h)))+4)
+			fmt.Fprintf(f, `<col min="%d" max="%d" width="%g" customWidth="1"/>`, i+1, i+1, width)
+		}
+		io.WriteString(f, `</cols>`)
+	}
+	_, err = io.WriteString(f, `<sheetData>`)
+	if err != nil || len(header) == 0 {
+		return err
+	}
+	cells := make([]interface{}, len(header))
+	for i, h := range header {
+		cells[i] = h
+	}
+	return w.writeRow(cells, styleHeader)
+}
+
+// WriteRow writes a row of the current sheet. Cells are nil (empty),
+// strings, float64, int, bool or time.Time, which are shown as dates, or
+// as dates and times if they have a time of day.
+func (w *Writer) WriteRow(cells []interface{}) error {
+	if w.sheet == nil {
+		return errors.New("xlsx: WriteRow before AddSheet")
+	}
+	return w.writeRow(cells, styleDefault)
+}
+
+func (w *Writer) writeRow(cells []interface{}, style int) error {
+	w.row++
+	var b strings.Builder
+	fmt.Fprintf(&b, `<row r="%d">`, w.row)
+	for i, cell := range cells {
+		ref := CellRef(i, w.row-1)
+		s := ""
+		if style != styleDefault {
+			s = fmt.Sprintf(` s="%d"`, style)
+		}
+		switch v := cell.(type) {
+		case nil:
+		case string:
+			if v != "" {
+				fmt.Fprintf(&b, `<c r="%s" t="inlineStr"%s><is><t xml:space="preserve">`, ref, s)
+				xml.EscapeText(&b, []byte(v))
+				b.WriteString(`</t></is></c>`)
+			}
+		case float64:
+			if !math.IsNaN(v) && !math.IsInf(v, 0) {
+				fmt.Fprintf(&b, `<c r="%s"%s><v>%s</v></c>`, ref, s, strconv.FormatFloat(v, 'g', -1, 64))
+			}
+		case int:
+			fmt.Fprintf(&b, `<c r="%s"%s><v>%d</v></c>`, ref, s, v)
+		case bool:
+			n := 0
+			if v {
+				n = 1
+			}
+			fmt.Fprintf(&b, `<c r="%s" t="b"%s><v>%d</v></c>`, ref, s, n)
+		case time.Time:
+			style := styleDateTime
+			if h, m, sec := v.Clock(); h == 0 && m == 0 && sec == 0 {
+				style = styleDate
+			}
+			fmt.Fprintf(&b, `<c r="%s" s="%d"><v>%s</v></c>`, ref, style, strconv.FormatFloat(serial(v), 'g', -1, 64))
+		default:
+			return errors.Errorf("xlsx: unsupported cell value %T", cell)
+		}
+	}
+	b.WriteString(`</row>`)
+	_, err := io.WriteString(w.sheet, b.String())
+	return err
+}
+
+func (w *Writer) endSheet() error {
+	if w.sheet == nil {
+		return nil
+	}
+	_, err := io.WriteString(w.sheet, `</sheetData></worksheet>`)
+	w.sheet = nil
+	return err
+}
+
+// Close ends the last sheet and writes the rest of the workbook. It
+// doesn't close the underlying writer.
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if err := w.endSheet(); err != nil {
+		return err
+	}
+	if len(w.names) == 0 {
+		return errors.New("xlsx: workbook has no sheets")
+	}
+	var types, sheets, rels strings.Builder
+	for i, name := range w.names {
+		fmt.Fprintf(&types, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+		fmt.Fprintf(&sheets, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escape(name), i+1, i+1)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i+1, i+1)
+	}
+	fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, len(w.names)+1)
+	for _, part := range []struct{ name, content string }{
+		{"[Content_Types].xml", `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+			types.String() + `</Types>`},
+		{"_rels/.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets>` + sheets.String() + `</sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			rels.String() + `</Relationships>`},
+		{"xl/styles.xml", stylesXML},
+	} {
+		f, err := w.zw.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, xml.Header+part.content); err != nil {
+			return err
+		}
+	}
+	return w.zw.Close()
+}
+
+// stylesXML defines the cell formats: default, header (bold on gray),
+// date and date with time.
+const stylesXML = `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<numFmts count="1"><numFmt numFmtId="164" formatCode="yyyy-mm-dd hh:mm"/></numFmts>` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="3"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill>` +
+	`<fill><patternFill patternType="solid"><fgColor rgb="FFD9D9D9"/><bgColor indexed="64"/></patternFill></fill></fills>` +
+	`<borders count="2"><border><left/><right/><top/><bottom/><diagonal/></border>` +
+	`<border><left/><right/><top/><bottom style="thin"><color auto="1"/></bottom><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="4"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="2" borderId="1" xfId="0" applyFont="1" applyFill="1" applyBorder="1"/>` +
+	`<xf numFmtId="14" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/></cellXfs>` +
+	`</styleSheet>`
+
+// SheetName returns name shortened to MaxSheetName characters and without
+// the characters Excel forbids in sheet names.
+func SheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, strings.Trim(name, "'"))
+	if r := []rune(name); len(r) > MaxSheetName {
+		name = string(r[:MaxSheetName])
+	}
+	if name == "" {
+		name = "Sheet"
+	}
+	return name
+}
+
+// CellRef returns the A1 reference of the cell in the zero-based column
+// col and row row, e.g. CellRef(27, 2) is "AB3".
+func CellRef(col, row int) string {
+	var name []byte
+	for col++; col > 0; col = (col - 1) / 26 {
+		name = append([]byte{byte('A' + (col-1)%26)}, name...)
+	}
+	return string(name) + strconv.Itoa(row+1)
+}
+
+func serial(t time.Time) float64 {
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+	return t.Sub(epoch).Hours() / 24
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,58 @@
+package xlsx
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestReadWrite(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	if err := w.AddSheet("Tasks: 2024/Q1", []string{"Name", "Points", "Done", "Due"}); err != nil {
+		t.Fatal(err)
+	}
+	rows := [][]interface{}{
+		{"Write <docs> & tests", 1.5, true, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"Ship", 3, false, time.Date(2024, 3, 2, 14, 30, 0, 0, time.UTC)},
+		{nil, nil, nil, nil, "extra"},
+	}
+	for _, row := range rows {
+		if err := w.WriteRow(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.AddSheet("Empty", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	sheets, err := Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sheets) != 2 || sheets[0].Name != "Tasks_ 2024_Q1" || sheets[1].Name != "Empty" || len(sheets[1].Rows) != 0 {
+		t.Fatalf("got sheets %+v", sheets)
+	}
+	want := [][]string{
+		{"Name", "Points", "Done", "Due"},
+		{"Write <docs> & tests", "1.5", "true", "2024-03-01"},
+		{"Ship", "3", "false", "2024-03-02 14:30"},
+		{"", "", "", "", "extra"},
+	}
+	if !reflect.DeepEqual(sheets[0].Rows, want) {
+		t.Errorf("got rows %q, want %q", sheets[0].Rows, want)
+	}
+
+	if ref := CellRef(27, 2); ref != "AB3" {
+		t.Errorf("got ref %v, want AB3", ref)
+	}
+	for code, want := range map[string]bool{"yyyy-mm-dd": true, "[h]:mm": true, "0.00": false, `"day"0`: false, "[Red]0.0": false} {
+		if got := isDateFormat(code); got != want {
+			t.Errorf("isDateFormat(%q) = %v, want %v", code, got, want)
+		}
+	}
+}